@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	pb "github.com/farhapartex/search-proxy/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:50051", "gRPC server address")
+	query := flag.String("query", "golang concurrency", "search query")
+	platforms := flag.String("platforms", "", "comma-separated platforms (default: all)")
+	maxResults := flag.Int("max-results", 5, "max_results per platform")
+	timeout := flag.Duration("timeout", 2*time.Second, "deadline for each call")
+	pages := flag.Int("pages", 3, "follow next_page_token for at most this many pages")
+	smoke := flag.Bool("smoke", false, "exit non-zero if any example fails")
+	flag.Parse()
+
+	conn, err := grpc.NewClient(*addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Fatalf("Failed to connect to %s: %v", *addr, err)
+	}
+	defer conn.Close()
+	client := pb.NewSearchServiceClient(conn)
+
+	req := &pb.SearchRequest{
+		Query:      *query,
+		MaxResults: int32(*maxResults),
+		Platforms:  splitList(*platforms),
+	}
+
+	// There is no streaming search RPC yet; add its example here once the
+	// service exposes one
+	examples := []struct {
+		name string
+		run  func() error
+	}{
+		{"health check", func() error { return healthCheck(client, *timeout) }},
+		{"list platforms", func() error { return listPlatforms(client, *timeout) }},
+		{"unary search", func() error { return search(client, req, *timeout, *pages) }},
+		{"deadline handling", func() error { return searchWithTinyDeadline(client, req) }},
+		{"invalid request", func() error { return searchInvalid(client, *timeout) }},
+	}
+
+	failed := 0
+	for _, example := range examples {
+		fmt.Printf("=== %s\n", example.name)
+		if err := example.run(); err != nil {
+			fmt.Printf("--- FAIL: %v\n\n", err)
+			failed++
+			continue
+		}
+		fmt.Printf("--- OK\n\n")
+	}
+
+	if *smoke && failed > 0 {
+		fmt.Printf("%d of %d examples failed\n", failed, len(examples))
+		os.Exit(1)
+	}
+}
+
+// healthCheck calls HealthCheck and prints the reported status
+func healthCheck(client pb.SearchServiceClient, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	resp, err := client.HealthCheck(ctx, &pb.HealthCheckRequest{Service: "exampleclient"})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("status=%s version=%s\n", resp.Status, resp.Version)
+	if resp.Status != "healthy" {
+		return fmt.Errorf("server reports %q", resp.Status)
+	}
+	return nil
+}
+
+// listPlatforms prints the searchable platforms and how to present them
+func listPlatforms(client pb.SearchServiceClient, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	resp, err := client.ListPlatforms(ctx, &pb.ListPlatformsRequest{})
+	if err != nil {
+		return err
+	}
+
+	for _, platform := range resp.Platforms {
+		fmt.Printf("  %-14s %-16s %s  %s\n", platform.Name, platform.DisplayName, platform.BrandColor, platform.Description)
+	}
+	if len(resp.Platforms) == 0 {
+		return fmt.Errorf("no platforms registered")
+	}
+	return nil
+}
+
+// search runs a unary search, attaching request metadata and reading the
+// response headers, then follows continuation tokens for oversized results
+func search(client pb.SearchServiceClient, req *pb.SearchRequest, timeout time.Duration, maxPages int) error {
+	for page := 1; page <= maxPages; page++ {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		ctx = metadata.AppendToOutgoingContext(ctx, "x-client-name", "exampleclient")
+
+		var header, trailer metadata.MD
+		start := time.Now()
+		resp, err := client.FederatedSearch(ctx, req, grpc.Header(&header), grpc.Trailer(&trailer))
+		cancel()
+		if err != nil {
+			return err
+		}
+
+		printResponse(page, resp, time.Since(start))
+		if len(header) > 0 {
+			fmt.Printf("  response headers: %v\n", header)
+		}
+		if len(trailer) > 0 {
+			fmt.Printf("  response trailers: %v\n", trailer)
+		}
+
+		if resp.NextPageToken == "" {
+			return nil
+		}
+		// Later pages are fetched with the same request, so page sizes and
+		// filters stay the same
+		req = proto.Clone(req).(*pb.SearchRequest)
+		req.PageToken = resp.NextPageToken
+	}
+	return nil
+}
+
+// searchWithTinyDeadline shows that a too-short deadline yields either
+// partial results or DeadlineExceeded, never a hang
+func searchWithTinyDeadline(client pb.SearchServiceClient, req *pb.SearchRequest) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	resp, err := client.FederatedSearch(ctx, req)
+	switch status.Code(err) {
+	case codes.OK:
+		fmt.Printf("answered within 50ms: %d results, timed out: %v\n", resp.TotalCount, resp.PlatformsTimeout)
+		return nil
+	case codes.DeadlineExceeded:
+		fmt.Println("deadline exceeded, as expected for a 50ms budget")
+		return nil
+	default:
+		return err
+	}
+}
+
+// searchInvalid checks that bad input is rejected with InvalidArgument
+func searchInvalid(client pb.SearchServiceClient, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	_, err := client.FederatedSearch(ctx, &pb.SearchRequest{Query: "go", MaxResults: -1})
+	if status.Code(err) != codes.InvalidArgument {
+		return fmt.Errorf("got %v, want InvalidArgument", err)
+	}
+	fmt.Printf("rejected: %s\n", status.Convert(err).Message())
+	return nil
+}
+
+func printResponse(page int, resp *pb.SearchResponse, elapsed time.Duration) {
+	fmt.Printf("page %d: %d results in %v (server: %dms)\n",
+		page, resp.TotalCount, elapsed.Round(time.Millisecond), resp.GetMetadata().GetResponseTimeMs())
+	fmt.Printf("  success=%v timeout=%v error=%v\n", resp.PlatformsSuccess, resp.PlatformsTimeout, resp.PlatformsError)
+
+	if md := resp.GetMetadata(); md != nil {
+		if md.Approximate {
+			fmt.Printf("  approximate answer from %q (similarity %.2f)\n", md.ApproximateQuery, md.Similarity)
+		}
+		if len(md.PlatformsFromIndex) > 0 {
+			fmt.Printf("  served from local index: %v\n", md.PlatformsFromIndex)
+		}
+		for platform, query := range md.ShortenedQueries {
+			fmt.Printf("  query shortened for %s: %q\n", platform, query)
+		}
+		if md.ResultsDropped > 0 || md.SnippetsTruncated > 0 {
+			fmt.Printf("  dropped=%d snippets_truncated=%d\n", md.ResultsDropped, md.SnippetsTruncated)
+		}
+	}
+
+	for _, result := range resp.Results {
+		fmt.Printf("  [%s] %s\n    %s\n", result.Platform, result.Title, result.Url)
+	}
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/farhapartex/search-proxy/internal/fakeupstream"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:8089", "listen address")
+	platforms := flag.String("platforms", "github,stackoverflow,reddit,hackernews,dockerhub,devto", "comma-separated platforms to serve")
+	latency := flag.Duration("latency", 50*time.Millisecond, "base response latency")
+	jitter := flag.Duration("jitter", 20*time.Millisecond, "random extra latency, up to this much")
+	failureRate := flag.Float64("failure-rate", 0, "fraction of requests answered with a 500 (0-1)")
+	rateLimitRate := flag.Float64("rate-limit-rate", 0, "fraction of requests answered as rate limited (0-1)")
+	results := flag.Int("results", 10, "results per response, capped at the requested page size")
+	seed := flag.Uint64("seed", 1, "seed for random latency and failures")
+	flag.Parse()
+
+	behavior := fakeupstream.Behavior{
+		Latency:       *latency,
+		Jitter:        *jitter,
+		FailureRate:   *failureRate,
+		RateLimitRate: *rateLimitRate,
+		Results:       *results,
+	}
+
+	// The platforms' search paths don't overlap, so one listener serves all
+	mux := http.NewServeMux()
+	for i, platform := range strings.Split(*platforms, ",") {
+		platform = strings.TrimSpace(platform)
+		handler, err := fakeupstream.NewHandler(platform, *seed+uint64(i))
+		if err != nil {
+			log.Fatalf("Failed to create fake %s: %v", platform, err)
+		}
+		handler.Set(behavior)
+		for _, path := range handler.Paths() {
+			mux.Handle(path, handler)
+		}
+		if platform == "reddit" {
+			mux.HandleFunc(fakeupstream.RedditTokenPath, fakeupstream.RedditToken)
+		}
+		log.Printf("Serving fake %s at http://%s%s", platform, *addr, strings.Join(handler.Paths(), ", "))
+	}
+
+	server := &http.Server{Addr: *addr, Handler: mux}
+
+	go func() {
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+		<-sigChan
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(ctx)
+	}()
+
+	log.Printf("Fake upstream listening on %s (latency %v + up to %v jitter, failure rate %.2f, rate-limit rate %.2f)",
+		*addr, *latency, *jitter, *failureRate, *rateLimitRate)
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		log.Fatalf("Failed to serve: %v", err)
+	}
+}
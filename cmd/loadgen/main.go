@@ -0,0 +1,242 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/farhapartex/search-proxy/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+var defaultQueries = []string{
+	"golang",
+	"react performance optimization",
+	"docker compose networking",
+	"rust async",
+	"postgres index",
+	"kubernetes ingress",
+}
+
+// sample is the outcome of a single FederatedSearch call
+type sample struct {
+	latency  time.Duration
+	code     string
+	results  int32
+	timeouts int
+	errors   int
+}
+
+func main() {
+	addr := flag.String("addr", "localhost:50051", "gRPC server address")
+	qps := flag.Float64("qps", 10, "target requests per second")
+	duration := flag.Duration("duration", 30*time.Second, "how long to generate load")
+	concurrency := flag.Int("concurrency", 50, "maximum in-flight requests")
+	queries := flag.String("queries", "", "comma-separated queries to mix (default: built-in set)")
+	queryFile := flag.String("query-file", "", "file with one query per line, overrides -queries")
+	platformSets := flag.String("platforms", "", "platform sets separated by ';', each a comma-separated list (empty set = all platforms)")
+	maxResults := flag.Int("max-results", 10, "max_results per request")
+	timeout := flag.Duration("timeout", 5*time.Second, "client-side deadline per request")
+	flag.Parse()
+
+	if *qps <= 0 || *concurrency <= 0 {
+		log.Fatalf("-qps and -concurrency must be positive")
+	}
+
+	queryMix, err := loadQueries(*queries, *queryFile)
+	if err != nil {
+		log.Fatalf("Failed to load queries: %v", err)
+	}
+	platformMix := parsePlatformSets(*platformSets)
+
+	conn, err := grpc.NewClient(*addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Fatalf("Failed to connect to %s: %v", *addr, err)
+	}
+	defer conn.Close()
+	client := pb.NewSearchServiceClient(conn)
+
+	log.Printf("Generating load against %s: %.1f QPS for %v (%d queries, %d platform sets)",
+		*addr, *qps, *duration, len(queryMix), len(platformMix))
+
+	var (
+		mu      sync.Mutex
+		samples []sample
+		wg      sync.WaitGroup
+		dropped int
+	)
+	inflight := make(chan struct{}, *concurrency)
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / *qps))
+	defer ticker.Stop()
+	deadline := time.After(*duration)
+	start := time.Now()
+
+loop:
+	for {
+		select {
+		case <-deadline:
+			break loop
+		case <-ticker.C:
+		}
+
+		select {
+		case inflight <- struct{}{}:
+		default:
+			// The server can't keep up with the target rate; record it rather
+			// than letting the generator itself become the bottleneck
+			dropped++
+			continue
+		}
+
+		req := &pb.SearchRequest{
+			Query:      queryMix[rand.Intn(len(queryMix))],
+			MaxResults: int32(*maxResults),
+			Platforms:  platformMix[rand.Intn(len(platformMix))],
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-inflight }()
+
+			s := runOne(client, req, *timeout)
+			mu.Lock()
+			samples = append(samples, s)
+			mu.Unlock()
+		}()
+	}
+
+	wg.Wait()
+	report(samples, dropped, time.Since(start))
+}
+
+func runOne(client pb.SearchServiceClient, req *pb.SearchRequest, timeout time.Duration) sample {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	resp, err := client.FederatedSearch(ctx, req)
+	s := sample{latency: time.Since(start), code: status.Code(err).String()}
+	if err == nil {
+		s.results = resp.TotalCount
+		s.timeouts = len(resp.PlatformsTimeout)
+		s.errors = len(resp.PlatformsError)
+	}
+	return s
+}
+
+func loadQueries(queries, queryFile string) ([]string, error) {
+	if queryFile != "" {
+		f, err := os.Open(queryFile)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		var lines []string
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			if line := strings.TrimSpace(scanner.Text()); line != "" {
+				lines = append(lines, line)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		if len(lines) == 0 {
+			return nil, fmt.Errorf("%s contains no queries", queryFile)
+		}
+		return lines, nil
+	}
+
+	if queries == "" {
+		return defaultQueries, nil
+	}
+	return splitList(queries, ","), nil
+}
+
+func parsePlatformSets(value string) [][]string {
+	if value == "" {
+		return [][]string{nil}
+	}
+
+	var sets [][]string
+	for _, set := range strings.Split(value, ";") {
+		sets = append(sets, splitList(set, ","))
+	}
+	return sets
+}
+
+func splitList(value, sep string) []string {
+	var items []string
+	for _, item := range strings.Split(value, sep) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func report(samples []sample, dropped int, elapsed time.Duration) {
+	if len(samples) == 0 {
+		fmt.Println("No requests completed")
+		return
+	}
+
+	latencies := make([]time.Duration, len(samples))
+	codes := make(map[string]int)
+	var results int64
+	var platformTimeouts, platformErrors int
+	for i, s := range samples {
+		latencies[i] = s.latency
+		codes[s.code]++
+		results += int64(s.results)
+		platformTimeouts += s.timeouts
+		platformErrors += s.errors
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	fmt.Printf("\nRequests:          %d in %v (%.1f req/s achieved)\n",
+		len(samples), elapsed.Round(time.Millisecond), float64(len(samples))/elapsed.Seconds())
+	fmt.Printf("Dropped (backlog): %d\n", dropped)
+	fmt.Printf("Avg results:       %.1f\n", float64(results)/float64(len(samples)))
+	fmt.Printf("Platform timeouts: %d\n", platformTimeouts)
+	fmt.Printf("Platform errors:   %d\n", platformErrors)
+
+	fmt.Println("\nLatency:")
+	for _, p := range []float64{50, 90, 95, 99, 100} {
+		fmt.Printf("  p%-4v %v\n", p, percentile(latencies, p).Round(time.Microsecond))
+	}
+
+	fmt.Println("\nStatus codes:")
+	names := make([]string, 0, len(codes))
+	for name := range codes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("  %-18s %d\n", name, codes[name])
+	}
+}
+
+// percentile returns the p-th percentile of sorted using nearest-rank
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(float64(len(sorted))*p/100+0.5) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
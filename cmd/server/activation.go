@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// listenFDsStart is the first file descriptor systemd passes
+const listenFDsStart = 3
+
+// activatedListeners returns the sockets passed by systemd socket activation
+// (LISTEN_FDS), keyed "http" for the one named http with
+// FileDescriptorName= and "grpc" for the first other one. It returns nil
+// when the process wasn't socket activated.
+func activatedListeners() (map[string]net.Listener, error) {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || count <= 0 {
+		return nil, nil
+	}
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+
+	// Children must not think the sockets were meant for them
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+
+	listeners := make(map[string]net.Listener, count)
+	for i := 0; i < count; i++ {
+		name := ""
+		if i < len(names) {
+			name = names[i]
+		}
+
+		file := os.NewFile(uintptr(listenFDsStart+i), name)
+		lis, err := net.FileListener(file)
+		file.Close()
+		if err != nil {
+			for _, l := range listeners {
+				l.Close()
+			}
+			return nil, fmt.Errorf("failed to use socket %d passed by systemd: %w", listenFDsStart+i, err)
+		}
+
+		key := "grpc"
+		if name == "http" {
+			key = "http"
+		}
+		if _, taken := listeners[key]; taken {
+			log.Printf("WARNING: Ignoring extra socket %q passed by systemd", name)
+			lis.Close()
+			continue
+		}
+		listeners[key] = lis
+	}
+	return listeners, nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestActivatedListeners(t *testing.T) {
+	if os.Getenv("ACTIVATION_TEST_CHILD") == "1" {
+		listeners, err := activatedListeners()
+		if err != nil {
+			t.Fatalf("activatedListeners() error = %v", err)
+		}
+		fmt.Printf("grpc=%s http=%s\n", listeners["grpc"].Addr(), listeners["http"].Addr())
+		return
+	}
+
+	var files []*os.File
+	var want []string
+	for _, name := range []string{"grpc", "http"} {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer lis.Close()
+		file, err := lis.(*net.TCPListener).File()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+		files = append(files, file)
+		want = append(want, name+"="+lis.Addr().String())
+	}
+
+	// LISTEN_PID must name the process that receives the sockets, which is
+	// only known once it runs
+	cmd := exec.Command("sh", "-c", `LISTEN_PID=$$ exec "$0" "$@"`, os.Args[0], "-test.run=^TestActivatedListeners$")
+	cmd.Env = append(os.Environ(), "ACTIVATION_TEST_CHILD=1", "LISTEN_FDS=2", "LISTEN_FDNAMES=search-proxy.socket:http")
+	cmd.ExtraFiles = files
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("child failed: %v\n%s", err, out)
+	}
+	if got := string(out); !strings.Contains(got, strings.Join(want, " ")) {
+		t.Errorf("child output = %q, want %q", got, strings.Join(want, " "))
+	}
+}
+
+func TestActivatedListenersIgnoresOtherProcesses(t *testing.T) {
+	t.Setenv("LISTEN_PID", "1")
+	t.Setenv("LISTEN_FDS", "1")
+
+	if listeners, err := activatedListeners(); listeners != nil || err != nil {
+		t.Errorf("activatedListeners() = %v, %v; want nothing for another process's sockets", listeners, err)
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/soheilhy/cmux"
+	"google.golang.org/grpc"
+
+	"github.com/farhapartex/search-proxy/internal/config"
+)
+
+// listeners serves the gRPC API and the HTTP endpoints, either on separate
+// ports or, when both are configured with the same port, on one port split
+// by cmux. gRPC may additionally be served on a Unix domain socket.
+type listeners struct {
+	grpcSrv *grpc.Server
+	httpSrv *http.Server
+	mux     cmux.CMux
+	grpcLis net.Listener
+	httpLis net.Listener
+	unixLis net.Listener
+}
+
+// listen opens the configured ports, or uses the sockets systemd passed when
+// socket activated. HTTP is disabled when HTTPPort is empty and systemd
+// passed no http socket.
+func listen(cfg config.ServerConfig, grpcSrv *grpc.Server, httpHandler http.Handler) (*listeners, error) {
+	activated, err := activatedListeners()
+	if err != nil {
+		return nil, err
+	}
+	l := &listeners{grpcSrv: grpcSrv}
+	fail := func(err error) (*listeners, error) {
+		l.close()
+		return nil, err
+	}
+
+	if lis, ok := activated["grpc"]; ok {
+		l.grpcLis = lis
+		log.Printf("Serving gRPC on socket %s passed by systemd", lis.Addr())
+	} else if l.grpcLis, err = listenTCP(cfg.GRPCPort); err != nil {
+		return fail(err)
+	}
+
+	if cfg.UnixSocket != "" {
+		if l.unixLis, err = listenUnix(cfg.UnixSocket); err != nil {
+			return fail(err)
+		}
+		log.Printf("Serving gRPC on Unix socket %s", cfg.UnixSocket)
+	}
+
+	switch lis, ok := activated["http"]; {
+	case ok:
+		l.httpLis = lis
+		log.Printf("Serving HTTP on socket %s passed by systemd", lis.Addr())
+	case cfg.HTTPPort == "":
+		return l, nil
+	case cfg.HTTPPort == cfg.GRPCPort:
+		// gRPC clients are told apart by their content type; grpc-go waits
+		// for the server's SETTINGS frame before sending headers, so the
+		// matcher has to send it
+		l.mux = cmux.New(l.grpcLis)
+		l.grpcLis = l.mux.MatchWithWriters(cmux.HTTP2MatchHeaderFieldSendSettings("content-type", "application/grpc"))
+		l.httpLis = l.mux.Match(cmux.Any())
+		log.Printf("Serving gRPC and HTTP on shared port %s", cfg.GRPCPort)
+	default:
+		if l.httpLis, err = listenTCP(cfg.HTTPPort); err != nil {
+			return fail(err)
+		}
+		log.Printf("Serving HTTP on port %s", cfg.HTTPPort)
+	}
+
+	l.httpSrv = &http.Server{Handler: httpHandler, ReadHeaderTimeout: 10 * time.Second}
+	return l, nil
+}
+
+// listenTCP listens on port on all interfaces
+func listenTCP(port string) (net.Listener, error) {
+	addr := fmt.Sprintf(":%s", port)
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	return lis, nil
+}
+
+// close releases the listeners opened so far, for when startup fails
+func (l *listeners) close() {
+	for _, lis := range []net.Listener{l.grpcLis, l.httpLis, l.unixLis} {
+		if lis != nil {
+			lis.Close()
+		}
+	}
+}
+
+// listenUnix listens on a Unix domain socket, replacing a socket file left
+// behind by an earlier run that didn't shut down cleanly
+func listenUnix(path string) (net.Listener, error) {
+	if info, err := os.Stat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("failed to listen on %s: file exists and is not a socket", path)
+		}
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to listen on %s: socket is in use", path)
+		}
+		os.Remove(path)
+	}
+
+	lis, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", path, err)
+	}
+	return lis, nil
+}
+
+// serve blocks until the gRPC server stops
+func (l *listeners) serve() error {
+	if l.unixLis != nil {
+		go func() {
+			if err := l.grpcSrv.Serve(l.unixLis); err != nil {
+				log.Printf("WARNING: gRPC server on Unix socket stopped: %v", err)
+			}
+		}()
+	}
+	if l.httpSrv != nil {
+		go func() {
+			err := l.httpSrv.Serve(l.httpLis)
+			if err != nil && !errors.Is(err, http.ErrServerClosed) && !errors.Is(err, cmux.ErrListenerClosed) {
+				log.Printf("WARNING: HTTP server stopped: %v", err)
+			}
+		}()
+	}
+	if l.mux != nil {
+		go func() {
+			err := l.mux.Serve()
+			if err != nil && !errors.Is(err, net.ErrClosed) && !errors.Is(err, cmux.ErrServerClosed) {
+				log.Printf("WARNING: Port multiplexer stopped: %v", err)
+			}
+		}()
+	}
+	return l.grpcSrv.Serve(l.grpcLis)
+}
+
+// stop lets open requests finish until ctx ends, then closes everything
+func (l *listeners) stop(ctx context.Context) {
+	stopped := make(chan struct{})
+	go func() {
+		l.grpcSrv.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		l.grpcSrv.Stop()
+		log.Println("Drain timeout reached, stopping forcefully")
+	}
+
+	if l.httpSrv != nil {
+		if err := l.httpSrv.Shutdown(ctx); err != nil {
+			l.httpSrv.Close()
+		}
+	}
+	if l.mux != nil {
+		l.mux.Close()
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/farhapartex/search-proxy/internal/config"
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+type healthyServer struct {
+	pb.UnimplementedSearchServiceServer
+}
+
+func (healthyServer) HealthCheck(context.Context, *pb.HealthCheckRequest) (*pb.HealthCheckResponse, error) {
+	return &pb.HealthCheckResponse{Status: "healthy"}, nil
+}
+
+func TestListenSharesOnePort(t *testing.T) {
+	grpcSrv := grpc.NewServer()
+	pb.RegisterSearchServiceServer(grpcSrv, healthyServer{})
+	httpHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	})
+
+	// Port 0 for both means one shared, randomly chosen port
+	servers, err := listen(config.ServerConfig{GRPCPort: "0", HTTPPort: "0"}, grpcSrv, httpHandler)
+	if err != nil {
+		t.Fatalf("listen() error = %v", err)
+	}
+	go servers.serve()
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		servers.stop(ctx)
+	}()
+	addr := servers.grpcLis.Addr().String()
+
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("grpc.NewClient() error = %v", err)
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	health, err := pb.NewSearchServiceClient(conn).HealthCheck(ctx, &pb.HealthCheckRequest{})
+	if err != nil || health.Status != "healthy" {
+		t.Errorf("HealthCheck() = %v, %v; want healthy", health, err)
+	}
+
+	resp, err := http.Get("http://" + addr + "/healthz")
+	if err != nil {
+		t.Fatalf("GET /healthz error = %v", err)
+	}
+	defer resp.Body.Close()
+	if body, _ := io.ReadAll(resp.Body); string(body) != "ok" {
+		t.Errorf("GET /healthz body = %q, want ok", body)
+	}
+}
+
+func TestListenServesUnixSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "grpc.sock")
+
+	// A socket file left behind by a crashed run must not block startup
+	stale, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("creating stale socket: %v", err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	grpcSrv := grpc.NewServer()
+	pb.RegisterSearchServiceServer(grpcSrv, healthyServer{})
+	servers, err := listen(config.ServerConfig{GRPCPort: "0", UnixSocket: socket}, grpcSrv, nil)
+	if err != nil {
+		t.Fatalf("listen() error = %v", err)
+	}
+	go servers.serve()
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		servers.stop(ctx)
+	}()
+
+	conn, err := grpc.NewClient("unix://"+socket, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("grpc.NewClient() error = %v", err)
+	}
+	defer conn.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	health, err := pb.NewSearchServiceClient(conn).HealthCheck(ctx, &pb.HealthCheckRequest{})
+	if err != nil || health.Status != "healthy" {
+		t.Errorf("HealthCheck() over Unix socket = %v, %v; want healthy", health, err)
+	}
+}
@@ -1,57 +1,153 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"log"
-	"net"
 	"os"
-	"os/signal"
-	"syscall"
 
 	"github.com/farhapartex/search-proxy/internal/config"
 	grpcServer "github.com/farhapartex/search-proxy/internal/grpc"
+	"github.com/farhapartex/search-proxy/internal/httpapi"
+	"github.com/farhapartex/search-proxy/internal/logging"
+	"github.com/farhapartex/search-proxy/internal/tracing"
+	"github.com/farhapartex/search-proxy/internal/version"
 	pb "github.com/farhapartex/search-proxy/proto"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/reflection"
 )
 
 func main() {
-	cfg, err := config.Load()
+	showVersion := flag.Bool("version", false, "print version information and exit")
+	configPath := flag.String("config", "", "YAML config file; environment variables override it (default: $CONFIG_FILE)")
+	flag.Parse()
+
+	build := version.Get()
+	if *showVersion {
+		fmt.Println(build)
+		return
+	}
+	log.Printf("Starting %s", build)
+
+	// Lines are filtered by level from here on; AdminService can change it
+	logging.Install(os.Stderr)
+
+	cfg, err := config.Load(*configPath)
 	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
+		log.Fatalf("ERROR: Failed to load configuration: %v", err)
 	}
+	level, _ := logging.ParseLevel(cfg.Logging.Level)
+	logging.SetLevel(level)
 
 	log.Printf("Configuration loaded successfully")
 	log.Printf("Server will listen on port: %s", cfg.Server.GRPCPort)
 	log.Printf("Server timeout: %v", cfg.Server.ServerTimeout)
 	log.Printf("Per-API timeout: %v", cfg.Server.PerAPITimeout)
 
-	address := fmt.Sprintf(":%s", cfg.Server.GRPCPort)
-	lis, err := net.Listen("tcp", address)
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		log.Fatalf("ERROR: Failed to set up tracing: %v", err)
+	}
+	if cfg.Tracing.Enabled {
+		log.Printf("Tracing enabled, exporting to %s", cfg.Tracing.Endpoint)
+	}
+
+	searchServer, err := grpcServer.NewServer(cfg)
 	if err != nil {
-		log.Fatalf("Failed to listen on %s: %v", address, err)
+		log.Fatalf("ERROR: Failed to create search server: %v", err)
 	}
 
-	grpcSrv := grpc.NewServer(
-		grpc.MaxConcurrentStreams(1000),
-	)
+	opts := serverOptions(cfg.GRPC)
+	if cfg.TLS.Enabled() {
+		// Applies to every gRPC listener, the Unix socket included
+		certs, err := newCertReloader(cfg.TLS)
+		if err != nil {
+			log.Fatalf("ERROR: %v", err)
+		}
+		opts = append(opts, grpc.Creds(credentials.NewTLS(certs.serverConfig())))
+		if cfg.TLS.ClientCAFile != "" {
+			log.Printf("Serving gRPC over mutual TLS, client certificates verified against %s", cfg.TLS.ClientCAFile)
+		} else {
+			log.Printf("Serving gRPC over TLS")
+		}
+	}
 
-	searchServer := grpcServer.NewServer(cfg)
+	// The request ID comes first so every interceptor's log lines carry it,
+	// then recovery so a panic anywhere below still gets logged and counted
+	// as INTERNAL. The admin and alerts services each sit behind their own
+	// token; search calls pass those interceptors untouched.
+	rpcMetrics := searchServer.RPCMetrics()
+	grpcSrv := grpc.NewServer(append(opts,
+		grpc.ChainUnaryInterceptor(
+			grpcServer.RequestIDUnaryInterceptor,
+			grpcServer.RecoveryUnaryInterceptor,
+			grpcServer.LoggingUnaryInterceptor,
+			rpcMetrics.UnaryInterceptor(),
+			grpcServer.AdminAuthInterceptor(cfg.Admin),
+			grpcServer.AlertsAuthInterceptor(cfg.Alerts),
+		),
+		grpc.ChainStreamInterceptor(
+			grpcServer.RequestIDStreamInterceptor,
+			grpcServer.RecoveryStreamInterceptor,
+			grpcServer.LoggingStreamInterceptor,
+			rpcMetrics.StreamInterceptor(),
+		))...)
 	pb.RegisterSearchServiceServer(grpcSrv, searchServer)
+	pb.RegisterAdminServiceServer(grpcSrv, grpcServer.NewAdminServer(searchServer))
+	if cfg.Alerts.Enabled {
+		alertServer, err := grpcServer.NewAlertServer(searchServer)
+		if err != nil {
+			log.Fatalf("ERROR: Failed to start saved searches: %v", err)
+		}
+		pb.RegisterAlertServiceServer(grpcSrv, alertServer)
+		log.Printf("Saved searches enabled, stored in %s", cfg.Alerts.DBPath)
+		if cfg.Alerts.Token == "" {
+			log.Println("WARNING: ALERTS_TOKEN is not set; every AlertService call will be refused")
+		}
+	}
 
 	reflection.Register(grpcSrv)
 
-	go func() {
-		sigChan := make(chan os.Signal, 1)
-		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
-		<-sigChan
+	servers, err := listen(cfg.Server, grpcSrv, httpapi.NewHandler(searchServer, cfg.Server.MaxMultiSearchQueries))
+	if err != nil {
+		log.Fatalf("ERROR: %v", err)
+	}
+
+	stopped := shutdownOnSignal(cfg.Server.DrainTimeout, searchServer, servers, shutdownTracing)
+	if err := servers.serve(); err != nil {
+		log.Fatalf("ERROR: Failed to serve: %v", err)
+	}
+	<-stopped
+}
 
-		log.Println("Received shutdown signal, gracefully stopping server...")
-		grpcSrv.GracefulStop()
-		log.Println("Server stopped")
-	}()
+// serverOptions translates the gRPC section of the config into server options.
+// Zero durations keep gRPC's defaults (infinite idle time and age).
+func serverOptions(cfg config.GRPCConfig) []grpc.ServerOption {
+	params := keepalive.ServerParameters{
+		Time:    cfg.KeepaliveTime,
+		Timeout: cfg.KeepaliveTimeout,
+	}
+	if cfg.MaxConnectionIdle > 0 {
+		params.MaxConnectionIdle = cfg.MaxConnectionIdle
+	}
+	if cfg.MaxConnectionAge > 0 {
+		params.MaxConnectionAge = cfg.MaxConnectionAge
+	}
+	if cfg.MaxConnectionAgeGrace > 0 {
+		params.MaxConnectionAgeGrace = cfg.MaxConnectionAgeGrace
+	}
 
-	if err := grpcSrv.Serve(lis); err != nil {
-		log.Fatalf("Failed to serve: %v", err)
+	return []grpc.ServerOption{
+		grpc.MaxConcurrentStreams(cfg.MaxConcurrentStreams),
+		grpc.MaxRecvMsgSize(cfg.MaxRecvMsgSize),
+		grpc.MaxSendMsgSize(cfg.MaxSendMsgSize),
+		grpc.KeepaliveParams(params),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             cfg.KeepaliveMinTime,
+			PermitWithoutStream: cfg.KeepalivePermitWithoutStream,
+		}),
 	}
 }
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	grpcServer "github.com/farhapartex/search-proxy/internal/grpc"
+)
+
+// shutdownOnSignal waits for SIGINT or SIGTERM, then shuts down in order
+// within drainTimeout: stop admitting searches and wait for in-flight ones,
+// cancelling any still running at the deadline; stop the listeners; drop
+// upstream connections and close the event publisher, store and index;
+// flush traces. The returned channel is closed once
+// it is done, so main exits only after the last step.
+func shutdownOnSignal(drainTimeout time.Duration, searchServer *grpcServer.Server, servers *listeners, flushTracing func(context.Context) error) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+		<-sigChan
+
+		log.Printf("Received shutdown signal, draining for up to %v...", drainTimeout)
+		ctx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+		defer cancel()
+		if err := searchServer.Drain(ctx); err != nil {
+			log.Printf("WARNING: Drain incomplete, cancelled the remaining searches: %v", err)
+		}
+
+		servers.stop(ctx)
+		searchServer.Close()
+		if err := flushTracing(ctx); err != nil {
+			log.Printf("WARNING: Failed to flush traces: %v", err)
+		}
+		log.Println("Server stopped")
+	}()
+	return done
+}
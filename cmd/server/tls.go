@@ -0,0 +1,117 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/farhapartex/search-proxy/internal/config"
+)
+
+// certReloader hands each TLS handshake the certificate, key and client CAs
+// read from the configured files, reading them again when they change so a
+// rotated certificate applies without a restart. A reload that fails keeps
+// the files read last.
+type certReloader struct {
+	cfg config.TLSConfig
+	now func() time.Time
+
+	mu      sync.Mutex
+	current *tls.Config
+	// modTimes are the modification times of the files read last
+	modTimes []time.Time
+	checked  time.Time
+}
+
+// newCertReloader reads the configured files, failing if they don't make a
+// usable certificate
+func newCertReloader(cfg config.TLSConfig) (*certReloader, error) {
+	r := &certReloader{cfg: cfg, now: time.Now}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// serverConfig returns the TLS config for the gRPC credentials; each
+// handshake gets the latest certificate through GetConfigForClient
+func (r *certReloader) serverConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return r.config(), nil
+		},
+	}
+}
+
+// config returns the current TLS config, first reloading the files when
+// the reload interval has passed and they changed since
+func (r *certReloader) config() *tls.Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cfg.ReloadInterval > 0 && r.now().Sub(r.checked) >= r.cfg.ReloadInterval {
+		if !slices.EqualFunc(r.fileModTimes(), r.modTimes, time.Time.Equal) {
+			if err := r.load(); err != nil {
+				log.Printf("WARNING: Failed to reload TLS certificate, keeping the previous one: %v", err)
+			} else {
+				log.Printf("TLS certificate reloaded from %s", r.cfg.CertFile)
+			}
+		}
+		r.checked = r.now()
+	}
+	return r.current
+}
+
+// load reads the files into a new TLS config. The caller holds mu, except
+// when constructing.
+func (r *certReloader) load() error {
+	modTimes := r.fileModTimes()
+	cert, err := tls.LoadX509KeyPair(r.cfg.CertFile, r.cfg.KeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if r.cfg.ClientCAFile != "" {
+		pem, err := os.ReadFile(r.cfg.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read client CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("failed to load client CA file %s: no PEM certificates", r.cfg.ClientCAFile)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	r.current = tlsConfig
+	r.modTimes = modTimes
+	r.checked = r.now()
+	return nil
+}
+
+// fileModTimes returns the modification times of the configured files, zero
+// for a file that can't be read
+func (r *certReloader) fileModTimes() []time.Time {
+	paths := []string{r.cfg.CertFile, r.cfg.KeyFile, r.cfg.ClientCAFile}
+	modTimes := make([]time.Time, len(paths))
+	for i, path := range paths {
+		if path == "" {
+			continue
+		}
+		if info, err := os.Stat(path); err == nil {
+			modTimes[i] = info.ModTime()
+		}
+	}
+	return modTimes
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/farhapartex/search-proxy/internal/config"
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+// testCert is a certificate and its key, PEM encoded
+type testCert struct {
+	cert, key []byte
+	parsed    *x509.Certificate
+	signer    *ecdsa.PrivateKey
+}
+
+// newTestCert issues a certificate for localhost signed by parent, or self
+// signed as a CA when parent is nil
+func newTestCert(t *testing.T, name string, parent *testCert) *testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	issuer, signer := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+	} else {
+		issuer, signer = parent.parsed, parent.signer
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, issuer, &key.PublicKey, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, _ := x509.ParseCertificate(der)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCert{
+		cert:   pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		key:    pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+		parsed: parsed,
+		signer: key,
+	}
+}
+
+// write saves the certificate and key as cert.pem and key.pem in dir
+func (c *testCert) write(t *testing.T, dir string) (certFile, keyFile string) {
+	t.Helper()
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, c.cert, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, c.key, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestMutualTLSRequiresClientCertificate(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "test CA", nil)
+	caFile := filepath.Join(dir, "ca.pem")
+	os.WriteFile(caFile, ca.cert, 0o600)
+	certFile, keyFile := newTestCert(t, "server", ca).write(t, dir)
+
+	certs, err := newCertReloader(config.TLSConfig{CertFile: certFile, KeyFile: keyFile, ClientCAFile: caFile})
+	if err != nil {
+		t.Fatalf("newCertReloader() error = %v", err)
+	}
+	grpcSrv := grpc.NewServer(grpc.Creds(credentials.NewTLS(certs.serverConfig())))
+	pb.RegisterSearchServiceServer(grpcSrv, healthyServer{})
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go grpcSrv.Serve(lis)
+	defer grpcSrv.Stop()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.parsed)
+	client := newTestCert(t, "client", ca)
+	clientCert, _ := tls.X509KeyPair(client.cert, client.key)
+
+	healthCheck := func(clientConfig *tls.Config) error {
+		conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(credentials.NewTLS(clientConfig)))
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_, err = pb.NewSearchServiceClient(conn).HealthCheck(ctx, &pb.HealthCheckRequest{})
+		return err
+	}
+
+	if err := healthCheck(&tls.Config{RootCAs: roots, Certificates: []tls.Certificate{clientCert}}); err != nil {
+		t.Errorf("HealthCheck() with a client certificate error = %v", err)
+	}
+	if err := healthCheck(&tls.Config{RootCAs: roots}); err == nil {
+		t.Error("HealthCheck() without a client certificate succeeded, want it rejected")
+	}
+}
+
+func TestCertReloaderPicksUpRotatedCertificate(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "test CA", nil)
+	first := newTestCert(t, "first", ca)
+	certFile, keyFile := first.write(t, dir)
+
+	certs, err := newCertReloader(config.TLSConfig{CertFile: certFile, KeyFile: keyFile, ReloadInterval: time.Minute})
+	if err != nil {
+		t.Fatalf("newCertReloader() error = %v", err)
+	}
+	now := time.Now()
+	certs.now = func() time.Time { return now }
+	served := func() []byte {
+		return certs.config().Certificates[0].Certificate[0]
+	}
+
+	// Files changing on disk, as a rotation would; the later modification
+	// time is what marks them changed
+	rotate := func(c *testCert, cert []byte) {
+		os.WriteFile(certFile, cert, 0o600)
+		os.WriteFile(keyFile, c.key, 0o600)
+		later := now.Add(time.Hour)
+		os.Chtimes(certFile, later, later)
+		os.Chtimes(keyFile, later, later)
+	}
+
+	second := newTestCert(t, "second", ca)
+	rotate(second, second.cert)
+	if !bytes.Equal(served(), first.parsed.Raw) {
+		t.Error("certificate reloaded before the reload interval passed")
+	}
+	now = now.Add(time.Minute)
+	if !bytes.Equal(served(), second.parsed.Raw) {
+		t.Error("certificate not reloaded after the files changed")
+	}
+
+	// A half-written rotation keeps the certificate that works
+	third := newTestCert(t, "third", ca)
+	rotate(third, []byte("not a certificate"))
+	os.Chtimes(certFile, now.Add(2*time.Hour), now.Add(2*time.Hour))
+	now = now.Add(time.Minute)
+	if !bytes.Equal(served(), second.parsed.Raw) {
+		t.Error("broken files replaced the working certificate")
+	}
+}
+
+func TestNewCertReloaderRejectsBadFiles(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := newTestCert(t, "server", nil).write(t, dir)
+	notPEM := filepath.Join(dir, "ca.pem")
+	os.WriteFile(notPEM, []byte("garbage"), 0o600)
+
+	for name, cfg := range map[string]config.TLSConfig{
+		"missing cert":  {CertFile: filepath.Join(dir, "missing.pem"), KeyFile: keyFile},
+		"bad client CA": {CertFile: certFile, KeyFile: keyFile, ClientCAFile: notPEM},
+	} {
+		if _, err := newCertReloader(cfg); err == nil {
+			t.Errorf("%s: newCertReloader() succeeded, want an error", name)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+// SearchFunc runs one search the way FederatedSearch would
+type SearchFunc func(ctx context.Context, req *pb.SearchRequest) (*pb.SearchResponse, error)
+
+// Scheduler re-runs saved searches when they are due. Each run's results
+// are compared with the previous run's by URL, and those not seen before
+// are posted to the saved search's webhook. The first run only records the
+// results to compare against.
+type Scheduler struct {
+	store        *Store
+	search       SearchFunc
+	client       *http.Client
+	pollInterval time.Duration
+	now          func() time.Time
+
+	cancel   context.CancelFunc
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// WebhookPayload is the JSON body posted to a saved search's webhook
+type WebhookPayload struct {
+	SavedSearchID int64  `json:"saved_search_id"`
+	Name          string `json:"name"`
+	Query         string `json:"query"`
+	RanAt         int64  `json:"ran_at"`
+	// Results are the new results, each encoded as a search Result message
+	Results []json.RawMessage `json:"results"`
+}
+
+// NewScheduler creates a scheduler for the saved searches in store; client
+// posts the webhooks
+func NewScheduler(store *Store, search SearchFunc, client *http.Client, pollInterval time.Duration) *Scheduler {
+	return &Scheduler{
+		store:        store,
+		search:       search,
+		client:       client,
+		pollInterval: pollInterval,
+		now:          time.Now,
+		done:         make(chan struct{}),
+	}
+}
+
+// Start runs due saved searches every poll interval until Stop
+func (s *Scheduler) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	go func() {
+		defer close(s.done)
+		defer func() {
+			if p := recover(); p != nil {
+				log.Printf("ERROR: Panic in saved search scheduler: %v\n%s", p, debug.Stack())
+			}
+		}()
+		ticker := time.NewTicker(s.pollInterval)
+		defer ticker.Stop()
+		for {
+			s.runDue(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop cancels any runs in progress and waits for the scheduler to exit
+func (s *Scheduler) Stop() {
+	s.stopOnce.Do(func() {
+		if s.cancel != nil {
+			s.cancel()
+			<-s.done
+		}
+	})
+}
+
+// runDue runs every saved search that is due, one at a time
+func (s *Scheduler) runDue(ctx context.Context) {
+	due, err := s.store.Due(ctx, s.now())
+	if err != nil {
+		log.Printf("WARNING: Failed to load due saved searches: %v", err)
+		return
+	}
+	for _, saved := range due {
+		if ctx.Err() != nil {
+			return
+		}
+		s.run(ctx, saved)
+	}
+}
+
+// run runs one saved search and schedules its next run. A failed search or
+// webhook keeps the previous results, so the same new results are posted
+// on the next run. A panic fails the run like any other error, rather than
+// stopping the scheduler.
+func (s *Scheduler) run(ctx context.Context, saved *SavedSearch) {
+	ranAt := s.now()
+	nextRun := ranAt.Add(s.pollInterval)
+	if schedule, err := ParseSchedule(saved.Schedule); err == nil {
+		nextRun = schedule.Next(ranAt)
+	} else {
+		log.Printf("WARNING: Saved search %d: %v", saved.ID, err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			log.Printf("ERROR: Panic running saved search %d: %v\n%s", saved.ID, p, debug.Stack())
+			if err := s.store.Reschedule(context.WithoutCancel(ctx), saved.ID, nextRun); err != nil {
+				log.Printf("WARNING: Saved search %d: %v", saved.ID, err)
+			}
+		}
+	}()
+
+	if err := s.runSearch(ctx, saved, ranAt, nextRun); err != nil {
+		// Stopped mid-run: leave it due, to run once the server is back
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("WARNING: Saved search %d failed: %v", saved.ID, err)
+		if err := s.store.Reschedule(ctx, saved.ID, nextRun); err != nil {
+			log.Printf("WARNING: Saved search %d: %v", saved.ID, err)
+		}
+	}
+}
+
+func (s *Scheduler) runSearch(ctx context.Context, saved *SavedSearch, ranAt, nextRun time.Time) error {
+	resp, err := s.search(ctx, saved.Request)
+	if err != nil {
+		return err
+	}
+	previous, err := s.store.ResultURLs(ctx, saved.ID)
+	if err != nil {
+		return err
+	}
+
+	var urls []string
+	var fresh []*pb.Result
+	for _, result := range resp.Results {
+		if result.Url == "" {
+			continue
+		}
+		urls = append(urls, result.Url)
+		if !previous[result.Url] {
+			fresh = append(fresh, result)
+		}
+	}
+
+	if !saved.LastRunAt.IsZero() && len(fresh) > 0 {
+		if err := s.post(ctx, saved, ranAt, fresh); err != nil {
+			return err
+		}
+		log.Printf("Saved search %d: posted %d new results", saved.ID, len(fresh))
+	}
+	return s.store.RecordRun(context.WithoutCancel(ctx), saved.ID, urls, ranAt, nextRun)
+}
+
+// post sends the new results to the saved search's webhook
+func (s *Scheduler) post(ctx context.Context, saved *SavedSearch, ranAt time.Time, results []*pb.Result) error {
+	payload := WebhookPayload{
+		SavedSearchID: saved.ID,
+		Name:          saved.Name,
+		Query:         saved.Request.Query,
+		RanAt:         ranAt.Unix(),
+	}
+	for _, result := range results {
+		encoded, err := protojson.Marshal(result)
+		if err != nil {
+			return fmt.Errorf("failed to encode result: %w", err)
+		}
+		payload.Results = append(payload.Results, encoded)
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, saved.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status=%d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,231 @@
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+func openTestStore(t *testing.T) *Store {
+	t.Helper()
+	store, err := Open(context.Background(), filepath.Join(t.TempDir(), "alerts.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+// webhook records the payloads posted to it, answering with status
+type webhook struct {
+	*httptest.Server
+	mu       sync.Mutex
+	status   int
+	payloads []WebhookPayload
+}
+
+func newWebhook(t *testing.T) *webhook {
+	w := &webhook{status: http.StatusNoContent}
+	w.Server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var payload WebhookPayload
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("webhook body: %v", err)
+		}
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		w.payloads = append(w.payloads, payload)
+		rw.WriteHeader(w.status)
+	}))
+	t.Cleanup(w.Close)
+	return w
+}
+
+func resultsFor(urls ...string) *pb.SearchResponse {
+	resp := &pb.SearchResponse{}
+	for _, url := range urls {
+		resp.Results = append(resp.Results, &pb.Result{Title: url, Url: url, Platform: "github"})
+	}
+	return resp
+}
+
+func TestSchedulerPostsOnlyNewResults(t *testing.T) {
+	store := openTestStore(t)
+	hook := newWebhook(t)
+	ctx := context.Background()
+
+	now := time.Date(2024, 5, 15, 10, 0, 0, 0, time.UTC)
+	saved := &SavedSearch{
+		Name:       "go releases",
+		Request:    &pb.SearchRequest{Query: "go release"},
+		Schedule:   "@hourly",
+		WebhookURL: hook.URL,
+		CreatedAt:  now,
+		NextRunAt:  now,
+	}
+	if err := store.Create(ctx, saved); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	responses := []*pb.SearchResponse{
+		resultsFor("https://a", "https://b"),
+		resultsFor("https://b", "https://c", "https://d"),
+		resultsFor("https://b", "https://c", "https://d"),
+	}
+	var searches []string
+	search := func(ctx context.Context, req *pb.SearchRequest) (*pb.SearchResponse, error) {
+		searches = append(searches, req.Query)
+		resp := responses[0]
+		responses = responses[1:]
+		return resp, nil
+	}
+	scheduler := NewScheduler(store, search, http.DefaultClient, time.Minute)
+	scheduler.now = func() time.Time { return now }
+
+	for range 3 {
+		scheduler.runDue(ctx)
+		// Not due again until the next hour
+		scheduler.runDue(ctx)
+		now = now.Add(time.Hour)
+	}
+
+	if len(searches) != 3 {
+		t.Fatalf("searched %d times, want once an hour", len(searches))
+	}
+	// The first run is the baseline, the third finds nothing new
+	if len(hook.payloads) != 1 {
+		t.Fatalf("webhook got %d payloads, want 1", len(hook.payloads))
+	}
+	payload := hook.payloads[0]
+	if payload.SavedSearchID != saved.ID || payload.Name != "go releases" || payload.Query != "go release" || len(payload.Results) != 2 {
+		t.Errorf("payload = %+v, want the two new results of saved search %d", payload, saved.ID)
+	}
+	var first pb.Result
+	if err := json.Unmarshal(payload.Results[0], &first); err != nil || first.Url != "https://c" {
+		t.Errorf("payload.Results[0] = %s, want https://c", payload.Results[0])
+	}
+
+	list, _ := store.List(ctx)
+	if len(list) != 1 || !list[0].NextRunAt.Equal(time.Date(2024, 5, 15, 13, 0, 0, 0, time.UTC)) {
+		t.Errorf("saved searches = %+v, want the next run scheduled at 13:00", list)
+	}
+}
+
+func TestSchedulerRetriesNewResultsAfterFailedWebhook(t *testing.T) {
+	store := openTestStore(t)
+	hook := newWebhook(t)
+	hook.status = http.StatusBadGateway
+	ctx := context.Background()
+
+	now := time.Date(2024, 5, 15, 10, 0, 0, 0, time.UTC)
+	saved := &SavedSearch{
+		Request:    &pb.SearchRequest{Query: "go"},
+		Schedule:   "* * * * *",
+		WebhookURL: hook.URL,
+		CreatedAt:  now,
+		LastRunAt:  now.Add(-time.Hour),
+		NextRunAt:  now,
+	}
+	if err := store.Create(ctx, saved); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	failSearch := true
+	search := func(ctx context.Context, req *pb.SearchRequest) (*pb.SearchResponse, error) {
+		if failSearch {
+			return nil, errors.New("upstreams down")
+		}
+		return resultsFor("https://new"), nil
+	}
+	scheduler := NewScheduler(store, search, http.DefaultClient, time.Minute)
+	scheduler.now = func() time.Time { return now }
+
+	// A failed search still moves on to the next run
+	scheduler.runDue(ctx)
+	if list, _ := store.List(ctx); !list[0].NextRunAt.After(now) {
+		t.Fatalf("next run = %v, want it rescheduled after a failed search", list[0].NextRunAt)
+	}
+
+	failSearch = false
+	now = now.Add(time.Minute)
+	scheduler.runDue(ctx)
+	hook.status = http.StatusOK
+	now = now.Add(time.Minute)
+	scheduler.runDue(ctx)
+
+	if len(hook.payloads) != 2 || len(hook.payloads[1].Results) != 1 {
+		t.Errorf("webhook payloads = %+v, want the same new result posted again", hook.payloads)
+	}
+	if urls, _ := store.ResultURLs(ctx, saved.ID); !urls["https://new"] {
+		t.Errorf("recorded results = %v, want https://new once the webhook accepted it", urls)
+	}
+}
+
+func TestSchedulerSurvivesPanickingSearch(t *testing.T) {
+	store := openTestStore(t)
+	ctx := context.Background()
+
+	now := time.Date(2024, 5, 15, 10, 0, 0, 0, time.UTC)
+	for _, query := range []string{"panic", "go"} {
+		saved := &SavedSearch{Request: &pb.SearchRequest{Query: query}, Schedule: "@hourly", WebhookURL: "http://hook", CreatedAt: now, NextRunAt: now}
+		if err := store.Create(ctx, saved); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	var searched []string
+	search := func(ctx context.Context, req *pb.SearchRequest) (*pb.SearchResponse, error) {
+		if req.Query == "panic" {
+			panic("search blew up")
+		}
+		searched = append(searched, req.Query)
+		return resultsFor("https://a"), nil
+	}
+	scheduler := NewScheduler(store, search, http.DefaultClient, time.Minute)
+	scheduler.now = func() time.Time { return now }
+	scheduler.runDue(ctx)
+
+	if len(searched) != 1 {
+		t.Errorf("searched %v, want the saved search after the panicking one still run", searched)
+	}
+	// The panicking search waits for its next run rather than retrying at once
+	if due, _ := store.Due(ctx, now); len(due) != 0 {
+		t.Errorf("due after the run = %d saved searches, want none", len(due))
+	}
+}
+
+func TestStoreDeleteForgetsResults(t *testing.T) {
+	store := openTestStore(t)
+	ctx := context.Background()
+
+	now := time.Now()
+	saved := &SavedSearch{Request: &pb.SearchRequest{Query: "go"}, Schedule: "@daily", WebhookURL: "http://hook", CreatedAt: now, NextRunAt: now}
+	if err := store.Create(ctx, saved); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := store.RecordRun(ctx, saved.ID, []string{"https://a"}, now, now); err != nil {
+		t.Fatalf("RecordRun() error = %v", err)
+	}
+
+	if deleted, err := store.Delete(ctx, saved.ID); err != nil || !deleted {
+		t.Fatalf("Delete() = %v, %v; want deleted", deleted, err)
+	}
+	if deleted, _ := store.Delete(ctx, saved.ID); deleted {
+		t.Error("second Delete() reported a deletion")
+	}
+	if urls, _ := store.ResultURLs(ctx, saved.ID); len(urls) != 0 {
+		t.Errorf("ResultURLs() after Delete() = %v, want none", urls)
+	}
+	// A run finishing after the delete doesn't bring results back
+	store.RecordRun(ctx, saved.ID, []string{"https://a"}, now, now)
+	if urls, _ := store.ResultURLs(ctx, saved.ID); len(urls) != 0 {
+		t.Errorf("ResultURLs() after a late run = %v, want none", urls)
+	}
+}
@@ -0,0 +1,249 @@
+package alerts
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	// The saved searches live in SQLite
+	_ "modernc.org/sqlite"
+
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+// SavedSearch is a search re-run on a schedule, its new results posted to
+// a webhook
+type SavedSearch struct {
+	ID         int64
+	Name       string
+	Request    *pb.SearchRequest
+	Schedule   string
+	WebhookURL string
+	CreatedAt  time.Time
+	// LastRunAt is when the results last compared against were recorded;
+	// zero until the first successful run
+	LastRunAt time.Time
+	NextRunAt time.Time
+}
+
+// Store persists saved searches and the result set of each one's last run
+type Store struct {
+	db *sql.DB
+}
+
+// Open opens the SQLite database at path, creating it and the schema if
+// they are missing
+func Open(ctx context.Context, path string) (*Store, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open alerts database: %w", err)
+	}
+	// SQLite allows a single writer; serializing avoids SQLITE_BUSY errors
+	db.SetMaxOpenConns(1)
+
+	s := &Store{db: db}
+	if err := s.migrate(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Close closes the underlying database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func (s *Store) migrate(ctx context.Context) error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS saved_searches (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			request BLOB NOT NULL,
+			schedule TEXT NOT NULL,
+			webhook_url TEXT NOT NULL,
+			created_at INTEGER NOT NULL,
+			last_run_at INTEGER NOT NULL,
+			next_run_at INTEGER NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_saved_searches_next_run_at ON saved_searches (next_run_at)`,
+		`CREATE TABLE IF NOT EXISTS saved_search_results (
+			saved_search_id INTEGER NOT NULL,
+			url TEXT NOT NULL,
+			PRIMARY KEY (saved_search_id, url)
+		)`,
+	}
+	for _, stmt := range statements {
+		if _, err := s.db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to migrate alerts schema: %w", err)
+		}
+	}
+	return nil
+}
+
+// Create stores a new saved search, setting its ID
+func (s *Store) Create(ctx context.Context, saved *SavedSearch) error {
+	request, err := proto.Marshal(saved.Request)
+	if err != nil {
+		return fmt.Errorf("failed to encode saved search request: %w", err)
+	}
+	err = s.db.QueryRowContext(ctx, `
+		INSERT INTO saved_searches (name, request, schedule, webhook_url, created_at, last_run_at, next_run_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		RETURNING id`,
+		saved.Name, request, saved.Schedule, saved.WebhookURL,
+		saved.CreatedAt.Unix(), unixOrZero(saved.LastRunAt), saved.NextRunAt.Unix(),
+	).Scan(&saved.ID)
+	if err != nil {
+		return fmt.Errorf("failed to insert saved search: %w", err)
+	}
+	return nil
+}
+
+// Count returns how many saved searches there are
+func (s *Store) Count(ctx context.Context) (int, error) {
+	var n int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM saved_searches`).Scan(&n); err != nil {
+		return 0, fmt.Errorf("failed to count saved searches: %w", err)
+	}
+	return n, nil
+}
+
+// List returns every saved search, oldest first
+func (s *Store) List(ctx context.Context) ([]*SavedSearch, error) {
+	return s.query(ctx, `ORDER BY id`)
+}
+
+// Due returns the saved searches whose next run is at or before now,
+// longest overdue first
+func (s *Store) Due(ctx context.Context, now time.Time) ([]*SavedSearch, error) {
+	return s.query(ctx, `WHERE next_run_at <= ? ORDER BY next_run_at, id`, now.Unix())
+}
+
+func (s *Store) query(ctx context.Context, clause string, args ...any) ([]*SavedSearch, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, name, request, schedule, webhook_url, created_at, last_run_at, next_run_at
+		FROM saved_searches `+clause, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query saved searches: %w", err)
+	}
+	defer rows.Close()
+
+	var saved []*SavedSearch
+	for rows.Next() {
+		var (
+			search                      SavedSearch
+			request                     []byte
+			createdAt, lastRun, nextRun int64
+		)
+		if err := rows.Scan(&search.ID, &search.Name, &request, &search.Schedule, &search.WebhookURL,
+			&createdAt, &lastRun, &nextRun); err != nil {
+			return nil, fmt.Errorf("failed to scan saved search: %w", err)
+		}
+		search.Request = &pb.SearchRequest{}
+		if err := proto.Unmarshal(request, search.Request); err != nil {
+			return nil, fmt.Errorf("failed to decode saved search %d: %w", search.ID, err)
+		}
+		search.CreatedAt = time.Unix(createdAt, 0)
+		if lastRun != 0 {
+			search.LastRunAt = time.Unix(lastRun, 0)
+		}
+		search.NextRunAt = time.Unix(nextRun, 0)
+		saved = append(saved, &search)
+	}
+	return saved, rows.Err()
+}
+
+// Delete removes a saved search and its results, reporting whether it
+// existed
+func (s *Store) Delete(ctx context.Context, id int64) (bool, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM saved_searches WHERE id = ?`, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete saved search: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM saved_search_results WHERE saved_search_id = ?`, id); err != nil {
+		return false, fmt.Errorf("failed to delete saved search results: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit delete: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// ResultURLs returns the URLs of the results the last run recorded
+func (s *Store) ResultURLs(ctx context.Context, id int64) (map[string]bool, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT url FROM saved_search_results WHERE saved_search_id = ?`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query saved search results: %w", err)
+	}
+	defer rows.Close()
+
+	urls := make(map[string]bool)
+	for rows.Next() {
+		var url string
+		if err := rows.Scan(&url); err != nil {
+			return nil, fmt.Errorf("failed to scan saved search result: %w", err)
+		}
+		urls[url] = true
+	}
+	return urls, rows.Err()
+}
+
+// RecordRun replaces a saved search's result set with urls and schedules
+// its next run. A search deleted meanwhile is left deleted.
+func (s *Store) RecordRun(ctx context.Context, id int64, urls []string, ranAt, nextRun time.Time) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `UPDATE saved_searches SET last_run_at = ?, next_run_at = ? WHERE id = ?`,
+		ranAt.Unix(), nextRun.Unix(), id)
+	if err != nil {
+		return fmt.Errorf("failed to update saved search: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM saved_search_results WHERE saved_search_id = ?`, id); err != nil {
+		return fmt.Errorf("failed to clear saved search results: %w", err)
+	}
+	for _, url := range urls {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT OR IGNORE INTO saved_search_results (saved_search_id, url) VALUES (?, ?)`, id, url); err != nil {
+			return fmt.Errorf("failed to insert saved search result: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit saved search run: %w", err)
+	}
+	return nil
+}
+
+// Reschedule sets a saved search's next run without touching its results,
+// for runs that failed
+func (s *Store) Reschedule(ctx context.Context, id int64, nextRun time.Time) error {
+	if _, err := s.db.ExecContext(ctx, `UPDATE saved_searches SET next_run_at = ? WHERE id = ?`, nextRun.Unix(), id); err != nil {
+		return fmt.Errorf("failed to reschedule saved search: %w", err)
+	}
+	return nil
+}
+
+func unixOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
@@ -0,0 +1,92 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrPrivateWebhook is returned for webhooks that would reach a loopback,
+// private, link-local or otherwise internal address
+var ErrPrivateWebhook = errors.New("webhook address is not public")
+
+// internalAddress reports whether webhooks must stay away from ip: the
+// server's own loopback, private networks, link-local addresses such as
+// cloud metadata endpoints, and addresses that aren't unicast at all
+func internalAddress(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return !ip.IsValid() || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified()
+}
+
+// CheckWebhookHost resolves rawURL's host with lookup and fails with
+// ErrPrivateWebhook if any of its addresses is internal
+func CheckWebhookHost(ctx context.Context, rawURL string, lookup func(ctx context.Context, host string) ([]netip.Addr, error)) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	addrs, err := lookup(ctx, u.Hostname())
+	if err != nil {
+		return fmt.Errorf("resolving webhook host %s: %w", u.Hostname(), err)
+	}
+	for _, addr := range addrs {
+		if internalAddress(addr) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrPrivateWebhook, u.Hostname(), addr)
+		}
+	}
+	return nil
+}
+
+// LookupHost resolves host with the default resolver; IP literals are
+// returned as they are
+func LookupHost(ctx context.Context, host string) ([]netip.Addr, error) {
+	return net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+}
+
+// WebhookClient returns the client webhooks are posted with. Unless
+// allowPrivate is set it refuses to connect to internal addresses, checked
+// as each connection is made, so neither a DNS change after the webhook was
+// saved nor a redirect can point it inside the network. It never uses a
+// proxy, which would hide the address actually reached.
+func WebhookClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if internalAddress(addrPort.Addr()) {
+				return fmt.Errorf("%w: %s", ErrPrivateWebhook, addrPort.Addr())
+			}
+			return nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+// RedactWebhookURL hides the parts of a webhook URL that commonly carry its
+// secret, the user info, path and query, keeping the scheme and host so the
+// webhook can still be recognized
+func RedactWebhookURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "REDACTED"
+	}
+	redacted := u.Scheme + "://" + u.Host
+	if u.User != nil || (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" {
+		redacted += "/REDACTED"
+	}
+	return redacted
+}
@@ -0,0 +1,170 @@
+// Package cache keeps recent search responses, in memory or in Redis, so
+// identical requests within a TTL are answered without calling the
+// platforms again
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+// Cache stores search responses by request key
+type Cache interface {
+	// Get returns the live entry for key, or nil on a miss. The returned
+	// response is a copy the caller may modify.
+	Get(ctx context.Context, key string) (*Hit, error)
+
+	// Put stores a copy of response under key
+	Put(ctx context.Context, key string, response *pb.SearchResponse) error
+
+	// Stats returns the traffic counters
+	Stats() Stats
+
+	// Flush removes every entry and returns how many there were
+	Flush(ctx context.Context) (int, error)
+}
+
+// LocalCache is a least-recently-used response cache whose entries also
+// expire after a fixed TTL. Each process has its own.
+type LocalCache struct {
+	mu         sync.Mutex
+	entries    map[string]*list.Element
+	lru        *list.List
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+
+	hits      atomic.Int64
+	misses    atomic.Int64
+	evictions atomic.Int64
+}
+
+type entry struct {
+	key       string
+	response  *pb.SearchResponse
+	storedAt  time.Time
+	expiresAt time.Time
+}
+
+// Hit is a cached response and how long ago it was stored
+type Hit struct {
+	Response *pb.SearchResponse
+	Age      time.Duration
+}
+
+// Stats counts cache traffic since the process started
+type Stats struct {
+	// Backend is "local" or "redis"
+	Backend string
+	Hits    int64
+	Misses  int64
+	// Errors counts failed lookups and writes, which behave as misses
+	Errors int64
+	// Evictions and Entries are only known to the local cache; Redis
+	// expires and evicts on its own
+	Evictions int64
+	Entries   int
+}
+
+// NewLocalCache creates a cache holding up to maxEntries responses for ttl
+// each
+func NewLocalCache(maxEntries int, ttl time.Duration) *LocalCache {
+	return &LocalCache{
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		now:        time.Now,
+	}
+}
+
+// Get implements Cache; it never fails
+func (c *LocalCache) Get(ctx context.Context, key string) (*Hit, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		c.misses.Add(1)
+		return nil, nil
+	}
+	e := elem.Value.(*entry)
+	now := c.now()
+	if now.After(e.expiresAt) {
+		c.remove(elem)
+		c.misses.Add(1)
+		return nil, nil
+	}
+
+	c.lru.MoveToFront(elem)
+	c.hits.Add(1)
+	return &Hit{
+		Response: proto.Clone(e.response).(*pb.SearchResponse),
+		Age:      now.Sub(e.storedAt),
+	}, nil
+}
+
+// Put implements Cache, evicting the least recently used entry when full.
+// It never fails.
+func (c *LocalCache) Put(ctx context.Context, key string, response *pb.SearchResponse) error {
+	e := &entry{
+		key:      key,
+		response: proto.Clone(response).(*pb.SearchResponse),
+		storedAt: c.now(),
+	}
+	e.expiresAt = e.storedAt.Add(c.ttl)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = e
+		c.lru.MoveToFront(elem)
+		return nil
+	}
+	c.entries[key] = c.lru.PushFront(e)
+
+	for c.maxEntries > 0 && c.lru.Len() > c.maxEntries {
+		c.remove(c.lru.Back())
+		c.evictions.Add(1)
+	}
+	return nil
+}
+
+// Stats implements Cache, including the current size
+func (c *LocalCache) Stats() Stats {
+	c.mu.Lock()
+	entries := c.lru.Len()
+	c.mu.Unlock()
+
+	return Stats{
+		Backend:   "local",
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+		Entries:   entries,
+	}
+}
+
+// Flush implements Cache; it never fails
+func (c *LocalCache) Flush(ctx context.Context) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := c.lru.Len()
+	c.entries = make(map[string]*list.Element)
+	c.lru.Init()
+	return n, nil
+}
+
+func (c *LocalCache) remove(elem *list.Element) {
+	c.lru.Remove(elem)
+	delete(c.entries, elem.Value.(*entry).key)
+}
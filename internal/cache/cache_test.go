@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+var ctx = context.Background()
+
+// get looks key up in a cache that can't fail
+func get(c Cache, key string) *Hit {
+	hit, _ := c.Get(ctx, key)
+	return hit
+}
+
+func response(count int32) *pb.SearchResponse {
+	return &pb.SearchResponse{TotalCount: count}
+}
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewLocalCache(2, time.Minute)
+	c.Put(ctx, "a", response(1))
+	c.Put(ctx, "b", response(2))
+	get(c, "a")
+	c.Put(ctx, "c", response(3))
+
+	if get(c, "b") != nil {
+		t.Error("Get(b) hit, want it evicted as least recently used")
+	}
+	if hit := get(c, "a"); hit == nil || hit.Response.TotalCount != 1 {
+		t.Errorf("Get(a) = %v, want the cached response", hit)
+	}
+
+	want := Stats{Backend: "local", Hits: 2, Misses: 1, Evictions: 1, Entries: 2}
+	if got := c.Stats(); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+}
+
+func TestCacheExpiresEntries(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewLocalCache(10, time.Minute)
+	c.now = func() time.Time { return now }
+	c.Put(ctx, "a", response(1))
+
+	now = now.Add(30 * time.Second)
+	if hit := get(c, "a"); hit == nil || hit.Age != 30*time.Second {
+		t.Errorf("Get(a) = %v, want a hit 30s old", hit)
+	}
+
+	now = now.Add(time.Minute)
+	if get(c, "a") != nil {
+		t.Error("Get(a) hit after the TTL")
+	}
+	if entries := c.Stats().Entries; entries != 0 {
+		t.Errorf("Entries = %d, want the expired entry dropped", entries)
+	}
+}
+
+func TestCacheFlush(t *testing.T) {
+	c := NewLocalCache(10, time.Minute)
+	c.Put(ctx, "a", response(1))
+	c.Put(ctx, "b", response(2))
+
+	if n, err := c.Flush(ctx); n != 2 || err != nil {
+		t.Fatalf("Flush() = %d, %v; want 2 entries removed", n, err)
+	}
+	if get(c, "a") != nil || c.Stats().Entries != 0 {
+		t.Error("cache still holds entries after Flush()")
+	}
+	c.Put(ctx, "c", response(3))
+	if get(c, "c") == nil {
+		t.Error("Get(c) missed after Put() on a flushed cache")
+	}
+}
+
+func TestCacheReturnsCopies(t *testing.T) {
+	c := NewLocalCache(10, time.Minute)
+	stored := response(1)
+	c.Put(ctx, "a", stored)
+	stored.TotalCount = 99
+
+	hit := get(c, "a")
+	hit.Response.TotalCount = 42
+	if again := get(c, "a"); again.Response.TotalCount != 1 {
+		t.Errorf("TotalCount = %d, want the cached copy unaffected by callers", again.Response.TotalCount)
+	}
+}
@@ -0,0 +1,135 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+// RedisCache shares cached responses between every replica connected to the
+// same Redis. Entries expire through Redis TTLs; size is bounded by the
+// server's own eviction policy.
+type RedisCache struct {
+	client    redis.UniversalClient
+	keyPrefix string
+	ttl       time.Duration
+	now       func() time.Time
+
+	hits   atomic.Int64
+	misses atomic.Int64
+	errors atomic.Int64
+}
+
+// NewRedisCache creates a cache storing responses under keyPrefix for ttl
+func NewRedisCache(client redis.UniversalClient, keyPrefix string, ttl time.Duration) *RedisCache {
+	return &RedisCache{
+		client:    client,
+		keyPrefix: keyPrefix,
+		ttl:       ttl,
+		now:       time.Now,
+	}
+}
+
+// redisKey hashes key, so queries never appear in Redis and keys stay short
+func (c *RedisCache) redisKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return c.keyPrefix + hex.EncodeToString(sum[:])
+}
+
+// Get implements Cache. Failures count as misses.
+func (c *RedisCache) Get(ctx context.Context, key string) (*Hit, error) {
+	value, err := c.client.Get(ctx, c.redisKey(key)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		c.misses.Add(1)
+		return nil, nil
+	}
+	if err != nil {
+		c.errors.Add(1)
+		c.misses.Add(1)
+		return nil, fmt.Errorf("redis cache lookup failed: %w", err)
+	}
+
+	// Values are the store time in Unix milliseconds, then the response
+	if len(value) < 8 {
+		c.errors.Add(1)
+		c.misses.Add(1)
+		return nil, fmt.Errorf("redis cache entry too short (%d bytes)", len(value))
+	}
+	response := &pb.SearchResponse{}
+	if err := proto.Unmarshal(value[8:], response); err != nil {
+		c.errors.Add(1)
+		c.misses.Add(1)
+		return nil, fmt.Errorf("failed to decode cached response: %w", err)
+	}
+
+	c.hits.Add(1)
+	storedAt := time.UnixMilli(int64(binary.BigEndian.Uint64(value)))
+	return &Hit{
+		Response: response,
+		Age:      max(c.now().Sub(storedAt), 0),
+	}, nil
+}
+
+// Put implements Cache
+func (c *RedisCache) Put(ctx context.Context, key string, response *pb.SearchResponse) error {
+	data, err := proto.Marshal(response)
+	if err != nil {
+		c.errors.Add(1)
+		return fmt.Errorf("failed to encode response: %w", err)
+	}
+	value := binary.BigEndian.AppendUint64(make([]byte, 0, 8+len(data)), uint64(c.now().UnixMilli()))
+	value = append(value, data...)
+
+	if err := c.client.Set(ctx, c.redisKey(key), value, c.ttl).Err(); err != nil {
+		c.errors.Add(1)
+		return fmt.Errorf("redis cache write failed: %w", err)
+	}
+	return nil
+}
+
+// flushBatch is how many keys Flush scans for and deletes at a time
+const flushBatch = 500
+
+// Flush implements Cache, deleting the entries every replica shares. Keys
+// outside the prefix are left alone.
+func (c *RedisCache) Flush(ctx context.Context) (int, error) {
+	deleted := 0
+	var cursor uint64
+	for {
+		keys, next, err := c.client.Scan(ctx, cursor, c.keyPrefix+"*", flushBatch).Result()
+		if err != nil {
+			return deleted, fmt.Errorf("redis cache scan failed: %w", err)
+		}
+		if len(keys) > 0 {
+			n, err := c.client.Unlink(ctx, keys...).Result()
+			if err != nil {
+				return deleted, fmt.Errorf("redis cache delete failed: %w", err)
+			}
+			deleted += int(n)
+		}
+		if next == 0 {
+			return deleted, nil
+		}
+		cursor = next
+	}
+}
+
+// Stats implements Cache. Counters are this replica's own traffic.
+func (c *RedisCache) Stats() Stats {
+	return Stats{
+		Backend: "redis",
+		Hits:    c.hits.Load(),
+		Misses:  c.misses.Load(),
+		Errors:  c.errors.Load(),
+	}
+}
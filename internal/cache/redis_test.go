@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestRedisCache(t *testing.T) (*RedisCache, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	// Fail at once rather than retry, for the outage test
+	client := redis.NewClient(&redis.Options{Addr: server.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { client.Close() })
+	return NewRedisCache(client, "test:", time.Minute), server
+}
+
+func TestRedisCacheSharesEntriesBetweenInstances(t *testing.T) {
+	c, server := newTestRedisCache(t)
+	other := NewRedisCache(redis.NewClient(&redis.Options{Addr: server.Addr()}), "test:", time.Minute)
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+	other.now = func() time.Time { return now.Add(5 * time.Second) }
+	if err := c.Put(ctx, "go\x00scope", response(7)); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	hit, err := other.Get(ctx, "go\x00scope")
+	if err != nil || hit == nil || hit.Response.TotalCount != 7 || hit.Age != 5*time.Second {
+		t.Fatalf("Get() = %v, %v; want the response stored by the other instance, 5s old", hit, err)
+	}
+	if get(other, "rust\x00scope") != nil {
+		t.Error("Get() of another key hit")
+	}
+
+	for _, key := range server.Keys() {
+		if !strings.HasPrefix(key, "test:") || strings.Contains(key, "go") {
+			t.Errorf("redis key %q, want the prefix and a hash of the request", key)
+		}
+	}
+	if ttl := server.TTL(server.Keys()[0]); ttl != time.Minute {
+		t.Errorf("TTL = %v, want a minute", ttl)
+	}
+
+	want := Stats{Backend: "redis", Hits: 1, Misses: 1}
+	if got := other.Stats(); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+}
+
+func TestRedisCacheExpiresEntries(t *testing.T) {
+	c, server := newTestRedisCache(t)
+	c.Put(ctx, "a", response(1))
+
+	server.FastForward(time.Minute)
+	if get(c, "a") != nil {
+		t.Error("Get(a) hit after the TTL")
+	}
+}
+
+func TestRedisCacheFlushKeepsOtherKeys(t *testing.T) {
+	c, server := newTestRedisCache(t)
+	c.Put(ctx, "a", response(1))
+	c.Put(ctx, "b", response(2))
+	server.Set("ratelimit:github", "3")
+
+	if n, err := c.Flush(ctx); n != 2 || err != nil {
+		t.Fatalf("Flush() = %d, %v; want 2 entries removed", n, err)
+	}
+	if keys := server.Keys(); len(keys) != 1 || keys[0] != "ratelimit:github" {
+		t.Errorf("keys after Flush() = %v, want only the unprefixed one", keys)
+	}
+}
+
+func TestRedisCacheReportsOutages(t *testing.T) {
+	c, server := newTestRedisCache(t)
+	server.Close()
+
+	if err := c.Put(ctx, "a", response(1)); err == nil {
+		t.Error("Put() succeeded without Redis")
+	}
+	if hit, err := c.Get(ctx, "a"); hit != nil || err == nil {
+		t.Errorf("Get() = %v, %v; want a miss with an error", hit, err)
+	}
+	if stats := c.Stats(); stats.Errors != 2 || stats.Misses != 1 {
+		t.Errorf("Stats() = %+v, want 2 errors and 1 miss", stats)
+	}
+}
@@ -4,39 +4,149 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/farhapartex/search-proxy/internal/logging"
 	"github.com/joho/godotenv"
 )
 
 // Config holds all configuration for the application
 type Config struct {
-	Server    ServerConfig
-	GitHub    GitHubConfig
+	Server        ServerConfig
+	GRPC          GRPCConfig
+	TLS           TLSConfig
+	GitHub        GitHubConfig
 	StackOverflow StackOverflowConfig
-	Reddit    RedditConfig
-	Performance PerformanceConfig
-	Logging   LoggingConfig
+	Reddit        RedditConfig
+	HackerNews    HackerNewsConfig
+	DockerHub     DockerHubConfig
+	DevTo         DevToConfig
+	Performance   PerformanceConfig
+	HTTPClient    HTTPClientConfig
+	Limits        LimitsConfig
+	RateLimit     RateLimitConfig
+	Redis         RedisConfig
+	Index         IndexConfig
+	Store         StoreConfig
+	Events        EventsConfig
+	Alerts        AlertsConfig
+	Embeddings    EmbeddingsConfig
+	SemanticCache SemanticCacheConfig
+	ResponseCache ResponseCacheConfig
+	Summarize     SummarizeConfig
+	Answer        AnswerConfig
+	Suggestions   SuggestionsConfig
+	Ranking       RankingConfig
+	Sources       SourcesConfig
+	Mock          MockConfig
+	Deterministic DeterministicConfig
+	Admin         AdminConfig
+	Logging       LoggingConfig
+	Tracing       TracingConfig
 }
 
 // ServerConfig holds server-related configuration
 type ServerConfig struct {
-	GRPCPort       string
-	ServerTimeout  time.Duration
-	PerAPITimeout  time.Duration
+	GRPCPort string
+	// HTTPPort serves health and metrics over plain HTTP; the same value as
+	// GRPCPort shares that port, empty disables HTTP
+	HTTPPort string
+	// UnixSocket, when set, also serves gRPC on this Unix domain socket path
+	UnixSocket    string
+	ServerTimeout time.Duration
+	PerAPITimeout time.Duration
+
+	// Upper bounds for the timeouts a request may ask for
+	MaxServerTimeout time.Duration
+	MaxPerAPITimeout time.Duration
+
+	// DrainTimeout bounds how long shutdown waits for in-flight searches and
+	// background work before stopping anyway
+	DrainTimeout time.Duration
+
+	// MaxMultiSearchQueries caps the searches in one MultiSearch call or
+	// GraphQL operation
+	MaxMultiSearchQueries int
+}
+
+// GRPCConfig holds gRPC server connection tuning
+type GRPCConfig struct {
+	MaxRecvMsgSize       int
+	MaxSendMsgSize       int
+	MaxConcurrentStreams uint32
+	// Server-initiated pings on idle connections
+	KeepaliveTime    time.Duration
+	KeepaliveTimeout time.Duration
+	// Connection lifetime limits, useful to rebalance behind L4 load balancers
+	MaxConnectionIdle     time.Duration
+	MaxConnectionAge      time.Duration
+	MaxConnectionAgeGrace time.Duration
+	// Enforcement policy for client pings
+	KeepaliveMinTime             time.Duration
+	KeepalivePermitWithoutStream bool
+}
+
+// TLSConfig secures the gRPC listener. TLS is off unless CertFile is set.
+type TLSConfig struct {
+	CertFile string
+	KeyFile  string
+	// ClientCAFile, when set, requires clients to present a certificate
+	// signed by one of its CAs (mutual TLS)
+	ClientCAFile string
+	// ReloadInterval is how often the files are checked for changes, so
+	// rotated certificates apply without a restart; 0 never reloads
+	ReloadInterval time.Duration
+}
+
+// Enabled reports whether the gRPC listener serves TLS
+func (t TLSConfig) Enabled() bool {
+	return t.CertFile != ""
 }
 
 // GitHubConfig holds GitHub API configuration
 type GitHubConfig struct {
-	APIToken  string
-	BaseURL   string
+	// APIToken is a comma-separated list of tokens, tried in order when one
+	// is rejected or out of quota
+	APIToken string
+	// AnonymousFallback retries unauthenticated when every token is rejected
+	AnonymousFallback bool
+	// BaseURL is a comma-separated list of API base URLs, e.g. a GitHub
+	// Enterprise instance followed by api.github.com
+	BaseURL string
 }
 
 // StackOverflowConfig holds StackOverflow API configuration
 type StackOverflowConfig struct {
-	APIKey  string
+	// APIKey is a comma-separated list of keys, tried in order when one is
+	// rejected or out of quota
+	APIKey string
+	// AnonymousFallback retries without a key when every key is rejected
+	AnonymousFallback bool
+	// BaseURL is a comma-separated list of API base URLs (mirrors)
 	BaseURL string
+	// BodyExcerpts builds snippets from question bodies; off, snippets are
+	// the title and tags, and responses are smaller
+	BodyExcerpts bool
+	// Sites is a comma-separated list of other Stack Exchange sites to
+	// search, each a platform of its own sharing the keys above
+	Sites string
+}
+
+// stackExchangeSites are the values STACKEXCHANGE_SITES accepts
+var stackExchangeSites = []string{"serverfault", "superuser", "dba", "askubuntu"}
+
+// SiteList returns the configured Stack Exchange sites, lowercased
+func (c StackOverflowConfig) SiteList() []string {
+	var sites []string
+	for _, site := range strings.Split(c.Sites, ",") {
+		if site = strings.ToLower(strings.TrimSpace(site)); site != "" && !slices.Contains(sites, site) {
+			sites = append(sites, site)
+		}
+	}
+	return sites
 }
 
 // RedditConfig holds Reddit API configuration
@@ -45,14 +155,315 @@ type RedditConfig struct {
 	ClientSecret string
 	UserAgent    string
 	BaseURL      string
+	// SnippetFormat is how selftext markdown is rendered: plain, markdown or html
+	SnippetFormat string
+}
+
+// HackerNewsConfig holds Hacker News (Algolia) API configuration
+type HackerNewsConfig struct {
+	// BaseURL is a comma-separated list of API base URLs
+	BaseURL string
+}
+
+// DockerHubConfig holds Docker Hub API configuration
+type DockerHubConfig struct {
+	// BaseURL is a comma-separated list of API base URLs
+	BaseURL string
+}
+
+// DevToConfig holds Dev.to (Forem) API configuration
+type DevToConfig struct {
+	// BaseURL is a comma-separated list of API base URLs
+	BaseURL string
 }
 
 // PerformanceConfig holds performance tuning configuration
 type PerformanceConfig struct {
-	MaxResultsPerPlatform int
-	EnableCircuitBreaker  bool
+	MaxResultsPerPlatform   int
+	EnableCircuitBreaker    bool
 	CircuitBreakerThreshold int
-	CircuitBreakerTimeout time.Duration
+	CircuitBreakerTimeout   time.Duration
+	// DirectProtoConversion has fetchers build protobuf results straight
+	// from the upstream response, skipping the internal result copy
+	DirectProtoConversion bool
+	// EndpointSelection picks among multiple base URLs per platform:
+	// "priority" (configured order) or "latency" (fastest observed first)
+	EndpointSelection string
+	// FetcherInit controls when fetchers are initialized: "background"
+	// (warmed up at startup) or "lazy" (on the first search that needs them)
+	FetcherInit string
+	// RetryMaxAttempts is how many times a fetch is tried when it fails
+	// with a network error or 5xx; 1 disables retries
+	RetryMaxAttempts int
+	// RetryBaseDelay is the backoff before the first retry, doubled after
+	RetryBaseDelay time.Duration
+	// RetryJitter is the fraction of each backoff that is randomized (0-1)
+	RetryJitter float64
+	// MaxConcurrentFetches caps the platform fetches running at once across
+	// all searches, and MaxConcurrentFetchesPerPlatform those for any one
+	// platform; 0 disables the cap. FetchQueueSize fetches may wait for a
+	// free slot, further ones are skipped as overloaded.
+	MaxConcurrentFetches            int
+	MaxConcurrentFetchesPerPlatform int
+	FetchQueueSize                  int
+}
+
+// HTTPClientConfig holds tuning for the shared upstream HTTP client
+type HTTPClientConfig struct {
+	Timeout             time.Duration
+	ProxyURL            string
+	MaxResponseBytes    int64
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	MaxConnsPerHost     int
+	IdleConnTimeout     time.Duration
+	// DNSCacheTTL enables the caching resolver for upstream hosts; 0 disables it
+	DNSCacheTTL time.Duration
+	// VCRMode records upstream traffic to VCRDir or replays it from there:
+	// "off", "record" or "replay"
+	VCRMode string
+	VCRDir  string
+}
+
+// LimitsConfig bounds how much result data a single search may assemble.
+// A value of 0 disables the corresponding cap.
+type LimitsConfig struct {
+	// DefaultSnippetLength is the snippet length, in characters, for
+	// requests that don't set max_snippet_length
+	DefaultSnippetLength int
+	// MaxSnippetLength caps the snippet length, in characters, whatever
+	// max_snippet_length a request sets
+	MaxSnippetLength int
+	MaxTotalResults  int
+	MaxSnippetBytes  int
+	MaxMetadataBytes int
+	// MaxResponseBytes caps the serialized response; the remainder is
+	// served through a continuation token
+	MaxResponseBytes int
+	ContinuationTTL  time.Duration
+}
+
+// RateLimitConfig holds per-platform upstream request quotas
+type RateLimitConfig struct {
+	Enabled bool
+	// Backend is "local" for per-process buckets or "redis" to share the
+	// budget across all replicas
+	Backend                string
+	KeyPrefix              string
+	Burst                  int
+	GitHubPerMinute        int
+	StackOverflowPerMinute int
+	RedditPerMinute        int
+	HackerNewsPerMinute    int
+	DockerHubPerMinute     int
+	DevToPerMinute         int
+	// QuotaThrottle holds requests back once an upstream reports its own
+	// quota nearly spent: it is paused at QuotaReserve remaining requests,
+	// and paced below QuotaSlowdownPercent of its limit. Independent of
+	// Enabled.
+	QuotaThrottle        bool
+	QuotaReserve         int
+	QuotaSlowdownPercent int
+}
+
+// RedisConfig holds connection settings for the shared Redis instance
+type RedisConfig struct {
+	Addr     string
+	Password string
+	DB       int
+}
+
+// IndexConfig holds settings for the embedded local result index
+type IndexConfig struct {
+	Enabled bool
+	// Path is the on-disk index location; empty keeps the index in memory
+	Path string
+}
+
+// StoreConfig holds settings for the optional search/analytics store.
+// Enabling it persists queries, so it is off by default.
+type StoreConfig struct {
+	Enabled bool
+	// Driver is "sqlite" or "postgres"
+	Driver string
+	DSN    string
+}
+
+// EventsConfig holds settings for publishing completed searches to a broker
+type EventsConfig struct {
+	Enabled bool
+	// Backend is "kafka" or "nats"
+	Backend string
+	// Brokers is a comma-separated Kafka broker list or a NATS server URL
+	Brokers string
+	// Topic is the Kafka topic or NATS subject
+	Topic string
+}
+
+// AlertsConfig holds settings for saved searches, re-run on a schedule with
+// their new results posted to a webhook
+type AlertsConfig struct {
+	Enabled bool
+	// DBPath is the SQLite database the saved searches are kept in
+	DBPath string
+	// PollInterval is how often the scheduler looks for saved searches due
+	PollInterval time.Duration
+	// WebhookTimeout bounds each webhook POST
+	WebhookTimeout time.Duration
+	// MaxSavedSearches bounds how many saved searches can exist at once
+	MaxSavedSearches int
+	// Token is the bearer token AlertService calls must present; empty
+	// refuses them all
+	Token string
+	// AllowPrivateWebhooks lets webhooks reach loopback, private and
+	// link-local addresses, for deployments whose receivers are internal
+	AllowPrivateWebhooks bool
+}
+
+// SemanticCacheConfig holds settings for the embedding-based response cache
+type SemanticCacheConfig struct {
+	Enabled bool
+	// SimilarityThreshold is the minimum cosine similarity for a cached
+	// query to be served in place of a new one
+	SimilarityThreshold float64
+	TTL                 time.Duration
+	MaxEntries          int
+}
+
+// ResponseCacheConfig holds settings for the in-memory cache of responses to
+// identical requests
+type ResponseCacheConfig struct {
+	Enabled bool
+	// Backend is "local" for a per-process cache or "redis" to share cached
+	// responses between replicas through the Redis instance
+	Backend    string
+	TTL        time.Duration
+	MaxEntries int
+	// KeyPrefix namespaces the cache's Redis keys
+	KeyPrefix string
+}
+
+// EmbeddingsConfig selects the model that turns queries into vectors for
+// semantic features
+type EmbeddingsConfig struct {
+	// Provider is openai (any OpenAI-compatible server), ollama or cohere
+	Provider string
+	// URL and Model default to the provider's when empty
+	URL     string
+	Model   string
+	APIKey  string
+	Timeout time.Duration
+}
+
+// SummarizeConfig holds settings for LLM summaries of long snippets
+type SummarizeConfig struct {
+	Enabled bool
+	// URL is an OpenAI-compatible chat completions endpoint
+	URL    string
+	Model  string
+	APIKey string
+	// Timeout bounds the whole summarization stage; results whose summary
+	// isn't ready by then are returned without one
+	Timeout time.Duration
+	// MinSnippetLength is the shortest snippet, in characters, worth
+	// summarizing
+	MinSnippetLength int
+	// Concurrency is the number of summaries requested at once
+	Concurrency int
+}
+
+// AnswerConfig holds settings for the AnswerSearch RPC
+type AnswerConfig struct {
+	Enabled bool
+	// URL is an OpenAI-compatible chat completions endpoint
+	URL    string
+	Model  string
+	APIKey string
+	// Timeout bounds answer generation, on top of the search itself
+	Timeout time.Duration
+	// DefaultSources is how many top results are used when the request
+	// doesn't say
+	DefaultSources int
+}
+
+// SuggestionsConfig holds settings for related-query suggestions
+type SuggestionsConfig struct {
+	Enabled bool
+	// Max is the number of related queries per response
+	Max int
+	// HistorySize is how many recent queries are remembered
+	HistorySize int
+}
+
+// RankingConfig holds settings for ordering merged results
+type RankingConfig struct {
+	// DefaultSort applies to requests that don't set sort: "arrival" keeps
+	// platforms in the order they answered, "relevance" fuses their
+	// rankings, "score" orders by normalized popularity
+	DefaultSort string
+	// RRFK is the reciprocal rank fusion constant; larger values flatten the
+	// advantage of top-ranked results
+	RRFK int
+	// SemanticRerank reorders relevance-sorted results by embedding
+	// similarity to the query, using the Embeddings model. It adds an
+	// embedding call to every such search.
+	SemanticRerank bool
+	// RerankTopN is how many of the fused results are embedded and
+	// reordered; the rest follow in fused order
+	RerankTopN    int
+	RerankTimeout time.Duration
+	// ScoreNormalization scales each platform's popularity into a result's
+	// normalized_score: "minmax" or "zscore"
+	ScoreNormalization string
+}
+
+// SourcesConfig holds the result sources allowed or denied for every
+// request, as comma-separated rules like "subreddit:golang" or
+// "domain:example.com"
+type SourcesConfig struct {
+	Allow string
+	Deny  string
+}
+
+// MockConfig switches platforms to fixture data instead of live upstreams
+type MockConfig struct {
+	Enabled bool
+	// Platforms is a comma-separated list of platforms to mock; empty mocks
+	// every platform when Enabled
+	Platforms string
+	// FixturesDir holds <platform>.json fixture files that replace the
+	// embedded sample set; platforms without one keep the samples
+	FixturesDir string
+}
+
+// Mocks reports whether platform should be served from fixtures
+func (m MockConfig) Mocks(platform string) bool {
+	if !m.Enabled {
+		return false
+	}
+	if strings.TrimSpace(m.Platforms) == "" {
+		return true
+	}
+	for _, name := range strings.Split(m.Platforms, ",") {
+		if strings.TrimSpace(name) == platform {
+			return true
+		}
+	}
+	return false
+}
+
+// DeterministicConfig makes responses reproducible for golden-file tests
+type DeterministicConfig struct {
+	Enabled bool
+	// Seed drives every random choice, such as continuation tokens
+	Seed int64
+}
+
+// AdminConfig controls the AdminService RPCs
+type AdminConfig struct {
+	// Token is the bearer token admin calls must present; empty disables
+	// the admin API
+	Token string
 }
 
 // LoggingConfig holds logging configuration
@@ -61,40 +472,252 @@ type LoggingConfig struct {
 	Format string
 }
 
-// Load loads configuration from environment variables
-func Load() (*Config, error) {
+// TracingConfig holds OpenTelemetry tracing configuration
+type TracingConfig struct {
+	Enabled bool
+	// Endpoint is the host:port of the OTLP/gRPC collector
+	Endpoint string
+	// Insecure sends spans without TLS, as to a local collector
+	Insecure    bool
+	ServiceName string
+	// SampleRatio is the fraction of searches traced (0-1) when the caller
+	// sent no sampling decision of its own
+	SampleRatio float64
+}
+
+// Load loads configuration from environment variables and, when path is
+// set, a YAML config file. Variables that are set, including from .env,
+// override the file. An empty path falls back to CONFIG_FILE.
+func Load(path string) (*Config, error) {
 	// Load .env file (ignore error if file doesn't exist)
 	_ = godotenv.Load()
 
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	var src source
+	if path != "" {
+		file, err := readFile(path)
+		if err != nil {
+			return nil, err
+		}
+		src.file = file
+	}
+
 	config := &Config{
 		Server: ServerConfig{
-			GRPCPort:      getEnv("GRPC_SERVER_PORT", "50051"),
-			ServerTimeout: getDurationEnv("SERVER_TIMEOUT_MS", 500) * time.Millisecond,
-			PerAPITimeout: getDurationEnv("PER_API_TIMEOUT_MS", 400) * time.Millisecond,
+			GRPCPort:              src.getEnv("GRPC_SERVER_PORT", "50051"),
+			HTTPPort:              src.lookup("HTTP_SERVER_PORT"),
+			UnixSocket:            src.lookup("GRPC_UNIX_SOCKET"),
+			ServerTimeout:         src.getDurationEnv("SERVER_TIMEOUT_MS", 500) * time.Millisecond,
+			PerAPITimeout:         src.getDurationEnv("PER_API_TIMEOUT_MS", 400) * time.Millisecond,
+			MaxServerTimeout:      src.getDurationEnv("MAX_SERVER_TIMEOUT_MS", 5000) * time.Millisecond,
+			MaxPerAPITimeout:      src.getDurationEnv("MAX_PER_API_TIMEOUT_MS", 4000) * time.Millisecond,
+			DrainTimeout:          src.getDurationEnv("SHUTDOWN_DRAIN_TIMEOUT_SEC", 30) * time.Second,
+			MaxMultiSearchQueries: src.getIntEnv("MULTI_SEARCH_MAX_QUERIES", 10),
+		},
+		GRPC: GRPCConfig{
+			MaxRecvMsgSize:               src.getIntEnv("GRPC_MAX_RECV_MSG_BYTES", 4<<20),
+			MaxSendMsgSize:               src.getIntEnv("GRPC_MAX_SEND_MSG_BYTES", 4<<20),
+			MaxConcurrentStreams:         uint32(src.getIntEnv("GRPC_MAX_CONCURRENT_STREAMS", 1000)),
+			KeepaliveTime:                src.getDurationEnv("GRPC_KEEPALIVE_TIME_SEC", 7200) * time.Second,
+			KeepaliveTimeout:             src.getDurationEnv("GRPC_KEEPALIVE_TIMEOUT_SEC", 20) * time.Second,
+			MaxConnectionIdle:            src.getDurationEnv("GRPC_MAX_CONNECTION_IDLE_SEC", 0) * time.Second,
+			MaxConnectionAge:             src.getDurationEnv("GRPC_MAX_CONNECTION_AGE_SEC", 0) * time.Second,
+			MaxConnectionAgeGrace:        src.getDurationEnv("GRPC_MAX_CONNECTION_AGE_GRACE_SEC", 0) * time.Second,
+			KeepaliveMinTime:             src.getDurationEnv("GRPC_KEEPALIVE_MIN_TIME_SEC", 300) * time.Second,
+			KeepalivePermitWithoutStream: src.getBoolEnv("GRPC_KEEPALIVE_PERMIT_WITHOUT_STREAM", false),
+		},
+		TLS: TLSConfig{
+			CertFile:       src.lookup("GRPC_TLS_CERT_FILE"),
+			KeyFile:        src.lookup("GRPC_TLS_KEY_FILE"),
+			ClientCAFile:   src.lookup("GRPC_TLS_CLIENT_CA_FILE"),
+			ReloadInterval: src.getDurationEnv("GRPC_TLS_RELOAD_INTERVAL_SEC", 60) * time.Second,
 		},
 		GitHub: GitHubConfig{
-			APIToken: getEnv("GITHUB_API_TOKEN", ""),
-			BaseURL:  getEnv("GITHUB_API_BASE_URL", "https://api.github.com"),
+			APIToken:          src.getEnv("GITHUB_API_TOKEN", ""),
+			BaseURL:           src.getEnv("GITHUB_API_BASE_URL", "https://api.github.com"),
+			AnonymousFallback: src.getBoolEnv("GITHUB_ANONYMOUS_FALLBACK", true),
 		},
 		StackOverflow: StackOverflowConfig{
-			APIKey:  getEnv("STACKOVERFLOW_API_KEY", ""),
-			BaseURL: getEnv("STACKOVERFLOW_API_BASE_URL", "https://api.stackexchange.com/2.3"),
+			APIKey:            src.getEnv("STACKOVERFLOW_API_KEY", ""),
+			BaseURL:           src.getEnv("STACKOVERFLOW_API_BASE_URL", "https://api.stackexchange.com/2.3"),
+			AnonymousFallback: src.getBoolEnv("STACKOVERFLOW_ANONYMOUS_FALLBACK", true),
+			BodyExcerpts:      src.getBoolEnv("STACKOVERFLOW_BODY_EXCERPTS", true),
+			Sites:             src.getEnv("STACKEXCHANGE_SITES", ""),
 		},
 		Reddit: RedditConfig{
-			ClientID:     getEnv("REDDIT_CLIENT_ID", ""),
-			ClientSecret: getEnv("REDDIT_CLIENT_SECRET", ""),
-			UserAgent:    getEnv("REDDIT_USER_AGENT", "FederatedSearchEngine/1.0"),
-			BaseURL:      getEnv("REDDIT_API_BASE_URL", "https://oauth.reddit.com"),
+			ClientID:      src.getEnv("REDDIT_CLIENT_ID", ""),
+			ClientSecret:  src.getEnv("REDDIT_CLIENT_SECRET", ""),
+			UserAgent:     src.getEnv("REDDIT_USER_AGENT", "FederatedSearchEngine/1.0"),
+			BaseURL:       src.getEnv("REDDIT_API_BASE_URL", "https://oauth.reddit.com"),
+			SnippetFormat: src.getEnv("REDDIT_SNIPPET_FORMAT", "plain"),
+		},
+		HackerNews: HackerNewsConfig{
+			BaseURL: src.getEnv("HACKERNEWS_API_BASE_URL", "https://hn.algolia.com/api/v1"),
+		},
+		DockerHub: DockerHubConfig{
+			BaseURL: src.getEnv("DOCKERHUB_API_BASE_URL", "https://hub.docker.com/v2"),
+		},
+		DevTo: DevToConfig{
+			BaseURL: src.getEnv("DEVTO_API_BASE_URL", "https://dev.to/api"),
 		},
 		Performance: PerformanceConfig{
-			MaxResultsPerPlatform:   getIntEnv("MAX_RESULTS_PER_PLATFORM", 20),
-			EnableCircuitBreaker:    getBoolEnv("ENABLE_CIRCUIT_BREAKER", true),
-			CircuitBreakerThreshold: getIntEnv("CIRCUIT_BREAKER_THRESHOLD", 5),
-			CircuitBreakerTimeout:   getDurationEnv("CIRCUIT_BREAKER_TIMEOUT_SEC", 30) * time.Second,
+			MaxResultsPerPlatform:           src.getIntEnv("MAX_RESULTS_PER_PLATFORM", 20),
+			EnableCircuitBreaker:            src.getBoolEnv("ENABLE_CIRCUIT_BREAKER", true),
+			CircuitBreakerThreshold:         src.getIntEnv("CIRCUIT_BREAKER_THRESHOLD", 5),
+			CircuitBreakerTimeout:           src.getDurationEnv("CIRCUIT_BREAKER_TIMEOUT_SEC", 30) * time.Second,
+			DirectProtoConversion:           src.getBoolEnv("DIRECT_PROTO_CONVERSION", false),
+			EndpointSelection:               src.getEnv("ENDPOINT_SELECTION", "priority"),
+			FetcherInit:                     src.getEnv("FETCHER_INIT", "background"),
+			RetryMaxAttempts:                src.getIntEnv("RETRY_MAX_ATTEMPTS", 3),
+			RetryBaseDelay:                  src.getDurationEnv("RETRY_BASE_DELAY_MS", 50) * time.Millisecond,
+			RetryJitter:                     src.getFloatEnv("RETRY_JITTER", 0.5),
+			MaxConcurrentFetches:            src.getIntEnv("MAX_CONCURRENT_FETCHES", 256),
+			MaxConcurrentFetchesPerPlatform: src.getIntEnv("MAX_CONCURRENT_FETCHES_PER_PLATFORM", 64),
+			FetchQueueSize:                  src.getIntEnv("FETCH_QUEUE_SIZE", 1024),
+		},
+		HTTPClient: HTTPClientConfig{
+			Timeout:             src.getDurationEnv("HTTP_CLIENT_TIMEOUT_MS", 10000) * time.Millisecond,
+			ProxyURL:            src.getEnv("HTTP_PROXY_URL", ""),
+			MaxResponseBytes:    int64(src.getIntEnv("HTTP_MAX_RESPONSE_BYTES", 2<<20)),
+			MaxIdleConns:        src.getIntEnv("HTTP_MAX_IDLE_CONNS", 100),
+			MaxIdleConnsPerHost: src.getIntEnv("HTTP_MAX_IDLE_CONNS_PER_HOST", 20),
+			MaxConnsPerHost:     src.getIntEnv("HTTP_MAX_CONNS_PER_HOST", 50),
+			IdleConnTimeout:     src.getDurationEnv("HTTP_IDLE_CONN_TIMEOUT_SEC", 90) * time.Second,
+			DNSCacheTTL:         src.getDurationEnv("DNS_CACHE_TTL_SEC", 300) * time.Second,
+			VCRMode:             src.getEnv("UPSTREAM_VCR_MODE", "off"),
+			VCRDir:              src.getEnv("UPSTREAM_VCR_DIR", "testdata/recordings"),
+		},
+		Limits: LimitsConfig{
+			DefaultSnippetLength: src.getIntEnv("DEFAULT_SNIPPET_LENGTH", 500),
+			MaxSnippetLength:     src.getIntEnv("MAX_SNIPPET_LENGTH", 5000),
+			MaxTotalResults:      src.getIntEnv("MAX_TOTAL_RESULTS", 300),
+			MaxSnippetBytes:      src.getIntEnv("MAX_SNIPPET_BYTES", 2048),
+			MaxMetadataBytes:     src.getIntEnv("MAX_METADATA_BYTES", 4096),
+			MaxResponseBytes:     src.getIntEnv("MAX_RESPONSE_BYTES", 3*1024*1024),
+			ContinuationTTL:      src.getDurationEnv("CONTINUATION_TTL_SEC", 120) * time.Second,
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:                src.getBoolEnv("RATE_LIMIT_ENABLED", false),
+			Backend:                src.getEnv("RATE_LIMIT_BACKEND", "local"),
+			KeyPrefix:              src.getEnv("RATE_LIMIT_KEY_PREFIX", "search-proxy:ratelimit:"),
+			Burst:                  src.getIntEnv("RATE_LIMIT_BURST", 5),
+			GitHubPerMinute:        src.getIntEnv("RATE_LIMIT_GITHUB_PER_MIN", 30),
+			StackOverflowPerMinute: src.getIntEnv("RATE_LIMIT_STACKOVERFLOW_PER_MIN", 300),
+			RedditPerMinute:        src.getIntEnv("RATE_LIMIT_REDDIT_PER_MIN", 60),
+			HackerNewsPerMinute:    src.getIntEnv("RATE_LIMIT_HACKERNEWS_PER_MIN", 150),
+			DockerHubPerMinute:     src.getIntEnv("RATE_LIMIT_DOCKERHUB_PER_MIN", 60),
+			DevToPerMinute:         src.getIntEnv("RATE_LIMIT_DEVTO_PER_MIN", 30),
+			QuotaThrottle:          src.getBoolEnv("UPSTREAM_QUOTA_THROTTLE", true),
+			QuotaReserve:           src.getIntEnv("UPSTREAM_QUOTA_RESERVE", 1),
+			QuotaSlowdownPercent:   src.getIntEnv("UPSTREAM_QUOTA_SLOWDOWN_PERCENT", 10),
+		},
+		Redis: RedisConfig{
+			Addr:     src.getEnv("REDIS_ADDR", "localhost:6379"),
+			Password: src.getEnv("REDIS_PASSWORD", ""),
+			DB:       src.getIntEnv("REDIS_DB", 0),
+		},
+		Index: IndexConfig{
+			Enabled: src.getBoolEnv("LOCAL_INDEX_ENABLED", false),
+			Path:    src.getEnv("LOCAL_INDEX_PATH", ""),
+		},
+		Store: StoreConfig{
+			Enabled: src.getBoolEnv("STORE_ENABLED", false),
+			Driver:  src.getEnv("STORE_DRIVER", "sqlite"),
+			DSN:     src.getEnv("STORE_DSN", "search-proxy.db"),
+		},
+		Events: EventsConfig{
+			Enabled: src.getBoolEnv("EVENTS_ENABLED", false),
+			Backend: src.getEnv("EVENTS_BACKEND", "kafka"),
+			Brokers: src.getEnv("EVENTS_BROKERS", "localhost:9092"),
+			Topic:   src.getEnv("EVENTS_TOPIC", "search-proxy.searches"),
+		},
+		Alerts: AlertsConfig{
+			Enabled:              src.getBoolEnv("ALERTS_ENABLED", false),
+			DBPath:               src.getEnv("ALERTS_DB_PATH", "alerts.db"),
+			PollInterval:         src.getDurationEnv("ALERTS_POLL_INTERVAL_SEC", 30) * time.Second,
+			WebhookTimeout:       src.getDurationEnv("ALERTS_WEBHOOK_TIMEOUT_SEC", 10) * time.Second,
+			MaxSavedSearches:     src.getIntEnv("ALERTS_MAX_SAVED_SEARCHES", 100),
+			Token:                src.getEnv("ALERTS_TOKEN", ""),
+			AllowPrivateWebhooks: src.getBoolEnv("ALERTS_ALLOW_PRIVATE_WEBHOOKS", false),
+		},
+		SemanticCache: SemanticCacheConfig{
+			Enabled:             src.getBoolEnv("SEMANTIC_CACHE_ENABLED", false),
+			SimilarityThreshold: src.getFloatEnv("SEMANTIC_CACHE_THRESHOLD", 0.92),
+			TTL:                 src.getDurationEnv("SEMANTIC_CACHE_TTL_SEC", 300) * time.Second,
+			MaxEntries:          src.getIntEnv("SEMANTIC_CACHE_MAX_ENTRIES", 1000),
+		},
+		ResponseCache: ResponseCacheConfig{
+			Enabled:    src.getBoolEnv("RESPONSE_CACHE_ENABLED", false),
+			Backend:    src.getEnv("RESPONSE_CACHE_BACKEND", "local"),
+			TTL:        src.getDurationEnv("RESPONSE_CACHE_TTL_SEC", 60) * time.Second,
+			MaxEntries: src.getIntEnv("RESPONSE_CACHE_MAX_ENTRIES", 1000),
+			KeyPrefix:  src.getEnv("RESPONSE_CACHE_KEY_PREFIX", "search-proxy:cache:"),
+		},
+		Embeddings: EmbeddingsConfig{
+			Provider: src.getEnv("EMBEDDING_PROVIDER", "openai"),
+			URL:      src.getEnv("EMBEDDING_API_URL", ""),
+			Model:    src.getEnv("EMBEDDING_MODEL", ""),
+			APIKey:   src.getEnv("EMBEDDING_API_KEY", ""),
+			Timeout:  src.getDurationEnv("EMBEDDING_TIMEOUT_MS", 150) * time.Millisecond,
+		},
+		Summarize: SummarizeConfig{
+			Enabled:          src.getBoolEnv("SUMMARIZE_ENABLED", false),
+			URL:              src.getEnv("SUMMARIZE_API_URL", "http://localhost:11434/v1/chat/completions"),
+			Model:            src.getEnv("SUMMARIZE_MODEL", "llama3.2"),
+			APIKey:           src.getEnv("SUMMARIZE_API_KEY", ""),
+			Timeout:          src.getDurationEnv("SUMMARIZE_TIMEOUT_MS", 1500) * time.Millisecond,
+			MinSnippetLength: src.getIntEnv("SUMMARIZE_MIN_SNIPPET_LENGTH", 300),
+			Concurrency:      src.getIntEnv("SUMMARIZE_CONCURRENCY", 4),
+		},
+		Answer: AnswerConfig{
+			Enabled:        src.getBoolEnv("ANSWER_ENABLED", false),
+			URL:            src.getEnv("ANSWER_API_URL", "http://localhost:11434/v1/chat/completions"),
+			Model:          src.getEnv("ANSWER_MODEL", "llama3.2"),
+			APIKey:         src.getEnv("ANSWER_API_KEY", ""),
+			Timeout:        src.getDurationEnv("ANSWER_TIMEOUT_MS", 15000) * time.Millisecond,
+			DefaultSources: src.getIntEnv("ANSWER_DEFAULT_SOURCES", 5),
+		},
+		Suggestions: SuggestionsConfig{
+			Enabled:     src.getBoolEnv("SUGGESTIONS_ENABLED", true),
+			Max:         src.getIntEnv("SUGGESTIONS_MAX", 5),
+			HistorySize: src.getIntEnv("SUGGESTIONS_HISTORY_SIZE", 1000),
+		},
+		Ranking: RankingConfig{
+			DefaultSort:        src.getEnv("RANKING_DEFAULT_SORT", "arrival"),
+			RRFK:               src.getIntEnv("RANKING_RRF_K", 60),
+			SemanticRerank:     src.getBoolEnv("RANKING_SEMANTIC_RERANK", false),
+			RerankTopN:         src.getIntEnv("RANKING_RERANK_TOP_N", 30),
+			RerankTimeout:      src.getDurationEnv("RANKING_RERANK_TIMEOUT_MS", 300) * time.Millisecond,
+			ScoreNormalization: src.getEnv("RANKING_SCORE_NORMALIZATION", "minmax"),
+		},
+		Sources: SourcesConfig{
+			Allow: src.getEnv("SOURCES_ALLOW", ""),
+			Deny:  src.getEnv("SOURCES_DENY", ""),
+		},
+		Mock: MockConfig{
+			Enabled:     src.getBoolEnv("MOCK_MODE", false),
+			Platforms:   src.getEnv("MOCK_PLATFORMS", ""),
+			FixturesDir: src.getEnv("MOCK_FIXTURES_DIR", ""),
+		},
+		Deterministic: DeterministicConfig{
+			Enabled: src.getBoolEnv("DETERMINISTIC_MODE", false),
+			Seed:    int64(src.getIntEnv("DETERMINISTIC_SEED", 1)),
+		},
+		Admin: AdminConfig{
+			Token: src.lookup("ADMIN_TOKEN"),
 		},
 		Logging: LoggingConfig{
-			Level:  getEnv("LOG_LEVEL", "info"),
-			Format: getEnv("LOG_FORMAT", "json"),
+			Level:  src.getEnv("LOG_LEVEL", "info"),
+			Format: src.getEnv("LOG_FORMAT", "json"),
+		},
+		Tracing: TracingConfig{
+			Enabled:     src.getBoolEnv("TRACING_ENABLED", false),
+			Endpoint:    src.getEnv("TRACING_OTLP_ENDPOINT", "localhost:4317"),
+			Insecure:    src.getBoolEnv("TRACING_OTLP_INSECURE", true),
+			ServiceName: src.getEnv("TRACING_SERVICE_NAME", "search-proxy"),
+			SampleRatio: src.getFloatEnv("TRACING_SAMPLE_RATIO", 1),
 		},
 	}
 
@@ -109,35 +732,212 @@ func Load() (*Config, error) {
 // Validate checks if required configuration fields are set
 func (c *Config) Validate() error {
 	// GitHub token is optional (but recommended for higher rate limits)
-	if c.GitHub.APIToken == "" {
+	if c.GitHub.APIToken == "" && !c.Mock.Mocks("github") {
 		log.Println("WARNING: GITHUB_API_TOKEN not set. Rate limit: 60 requests/hour")
 	}
 
 	// StackOverflow key is optional
-	if c.StackOverflow.APIKey == "" {
+	if c.StackOverflow.APIKey == "" && !c.Mock.Mocks("stackoverflow") {
 		log.Println("WARNING: STACKOVERFLOW_API_KEY not set. Rate limit: 300 requests/day")
 	}
 
 	// Reddit credentials are optional
-	if c.Reddit.ClientID == "" || c.Reddit.ClientSecret == "" {
-		log.Println("WARNING: REDDIT_CLIENT_ID or REDDIT_CLIENT_SECRET not set. Using unauthenticated access")
+	if (c.Reddit.ClientID == "" || c.Reddit.ClientSecret == "") && !c.Mock.Mocks("reddit") {
+		log.Println("WARNING: REDDIT_CLIENT_ID or REDDIT_CLIENT_SECRET not set. Using the public endpoint with lower rate limits")
+	}
+
+	for _, site := range c.StackOverflow.SiteList() {
+		if !slices.Contains(stackExchangeSites, site) {
+			return fmt.Errorf("invalid STACKEXCHANGE_SITES entry %q (valid: %s)", site, strings.Join(stackExchangeSites, ", "))
+		}
+	}
+
+	if c.Server.MaxServerTimeout < c.Server.ServerTimeout {
+		return fmt.Errorf("invalid MAX_SERVER_TIMEOUT_MS %d (must be at least SERVER_TIMEOUT_MS)", c.Server.MaxServerTimeout.Milliseconds())
+	}
+
+	if c.Server.MaxPerAPITimeout < c.Server.PerAPITimeout {
+		return fmt.Errorf("invalid MAX_PER_API_TIMEOUT_MS %d (must be at least PER_API_TIMEOUT_MS)", c.Server.MaxPerAPITimeout.Milliseconds())
+	}
+
+	if (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+		return fmt.Errorf("invalid GRPC_TLS_KEY_FILE %q (GRPC_TLS_CERT_FILE and GRPC_TLS_KEY_FILE must be set together)", c.TLS.KeyFile)
+	}
+
+	if c.TLS.ClientCAFile != "" && !c.TLS.Enabled() {
+		return fmt.Errorf("invalid GRPC_TLS_CLIENT_CA_FILE %q (mutual TLS needs GRPC_TLS_CERT_FILE)", c.TLS.ClientCAFile)
+	}
+
+	if c.TLS.ReloadInterval < 0 {
+		return fmt.Errorf("invalid GRPC_TLS_RELOAD_INTERVAL_SEC %d (must not be negative)", int(c.TLS.ReloadInterval.Seconds()))
+	}
+
+	// cmux tells gRPC from HTTP by reading plaintext HTTP/2 headers
+	if c.TLS.Enabled() && c.Server.HTTPPort != "" && c.Server.HTTPPort == c.Server.GRPCPort {
+		return fmt.Errorf("invalid HTTP_SERVER_PORT %s (can't share the gRPC port when TLS is enabled)", c.Server.HTTPPort)
+	}
+
+	if c.Server.MaxMultiSearchQueries < 1 {
+		return fmt.Errorf("invalid MULTI_SEARCH_MAX_QUERIES %d (must be at least 1)", c.Server.MaxMultiSearchQueries)
+	}
+
+	if c.Performance.EndpointSelection != "priority" && c.Performance.EndpointSelection != "latency" {
+		return fmt.Errorf("invalid ENDPOINT_SELECTION %q (valid: priority, latency)", c.Performance.EndpointSelection)
+	}
+
+	if c.Performance.FetcherInit != "background" && c.Performance.FetcherInit != "lazy" {
+		return fmt.Errorf("invalid FETCHER_INIT %q (valid: background, lazy)", c.Performance.FetcherInit)
+	}
+
+	if c.Performance.EnableCircuitBreaker && c.Performance.CircuitBreakerThreshold < 1 {
+		return fmt.Errorf("invalid CIRCUIT_BREAKER_THRESHOLD %d (must be at least 1)", c.Performance.CircuitBreakerThreshold)
+	}
+
+	if c.Performance.EnableCircuitBreaker && c.Performance.CircuitBreakerTimeout <= 0 {
+		return fmt.Errorf("invalid CIRCUIT_BREAKER_TIMEOUT_SEC %d (must be positive)", int(c.Performance.CircuitBreakerTimeout.Seconds()))
+	}
+
+	if c.Performance.RetryMaxAttempts < 1 {
+		return fmt.Errorf("invalid RETRY_MAX_ATTEMPTS %d (must be at least 1)", c.Performance.RetryMaxAttempts)
+	}
+
+	if c.Performance.RetryBaseDelay < 0 {
+		return fmt.Errorf("invalid RETRY_BASE_DELAY_MS %d (must not be negative)", c.Performance.RetryBaseDelay.Milliseconds())
+	}
+
+	if c.Performance.RetryJitter < 0 || c.Performance.RetryJitter > 1 {
+		return fmt.Errorf("invalid RETRY_JITTER %v (must be between 0 and 1)", c.Performance.RetryJitter)
+	}
+
+	if c.Performance.MaxConcurrentFetches < 0 {
+		return fmt.Errorf("invalid MAX_CONCURRENT_FETCHES %d (must not be negative)", c.Performance.MaxConcurrentFetches)
+	}
+
+	if c.Performance.MaxConcurrentFetchesPerPlatform < 0 {
+		return fmt.Errorf("invalid MAX_CONCURRENT_FETCHES_PER_PLATFORM %d (must not be negative)", c.Performance.MaxConcurrentFetchesPerPlatform)
+	}
+
+	if c.Performance.FetchQueueSize < 0 {
+		return fmt.Errorf("invalid FETCH_QUEUE_SIZE %d (must not be negative)", c.Performance.FetchQueueSize)
+	}
+
+	if mode := c.HTTPClient.VCRMode; mode != "off" && mode != "record" && mode != "replay" {
+		return fmt.Errorf("invalid UPSTREAM_VCR_MODE %q (valid: off, record, replay)", c.HTTPClient.VCRMode)
+	}
+
+	if f := c.Reddit.SnippetFormat; f != "plain" && f != "markdown" && f != "html" {
+		return fmt.Errorf("invalid REDDIT_SNIPPET_FORMAT %q (valid: plain, markdown, html)", c.Reddit.SnippetFormat)
+	}
+
+	if _, err := logging.ParseLevel(c.Logging.Level); err != nil {
+		return fmt.Errorf("invalid LOG_LEVEL %q (valid: debug, info, warn, error)", c.Logging.Level)
+	}
+
+	if c.ResponseCache.Enabled && (c.ResponseCache.TTL <= 0 || c.ResponseCache.MaxEntries < 1) {
+		return fmt.Errorf("invalid RESPONSE_CACHE_TTL_SEC or RESPONSE_CACHE_MAX_ENTRIES (both must be positive)")
+	}
+
+	if c.ResponseCache.Enabled && c.ResponseCache.Backend != "local" && c.ResponseCache.Backend != "redis" {
+		return fmt.Errorf("invalid RESPONSE_CACHE_BACKEND %q (valid: local, redis)", c.ResponseCache.Backend)
+	}
+
+	if s := c.Ranking.DefaultSort; s != "arrival" && s != "relevance" && s != "score" {
+		return fmt.Errorf("invalid RANKING_DEFAULT_SORT %q (valid: arrival, relevance, score)", s)
+	}
+
+	if n := c.Ranking.ScoreNormalization; n != "minmax" && n != "zscore" {
+		return fmt.Errorf("invalid RANKING_SCORE_NORMALIZATION %q (valid: minmax, zscore)", n)
+	}
+
+	if c.Ranking.RRFK < 1 {
+		return fmt.Errorf("invalid RANKING_RRF_K %d (must be at least 1)", c.Ranking.RRFK)
+	}
+
+	if c.Ranking.SemanticRerank {
+		if c.Ranking.RerankTopN < 2 || c.Ranking.RerankTopN > 200 {
+			return fmt.Errorf("invalid RANKING_RERANK_TOP_N %d (valid: 2-200)", c.Ranking.RerankTopN)
+		}
+		if c.Ranking.RerankTimeout <= 0 {
+			return fmt.Errorf("invalid RANKING_RERANK_TIMEOUT_MS %v (must be positive)", c.Ranking.RerankTimeout)
+		}
+	}
+
+	if c.Limits.MaxSnippetLength < 0 || c.Limits.MaxSnippetLength > 5000 {
+		return fmt.Errorf("invalid MAX_SNIPPET_LENGTH %d (valid: 0-5000)", c.Limits.MaxSnippetLength)
+	}
+	maxSnippetLength := c.Limits.MaxSnippetLength
+	if maxSnippetLength == 0 {
+		maxSnippetLength = 5000
+	}
+	if c.Limits.DefaultSnippetLength < 1 || c.Limits.DefaultSnippetLength > maxSnippetLength {
+		return fmt.Errorf("invalid DEFAULT_SNIPPET_LENGTH %d (valid: 1-%d)", c.Limits.DefaultSnippetLength, maxSnippetLength)
+	}
+
+	if p := c.Embeddings.Provider; p != "openai" && p != "ollama" && p != "cohere" {
+		return fmt.Errorf("invalid EMBEDDING_PROVIDER %q (valid: openai, ollama, cohere)", p)
+	}
+
+	if c.RateLimit.Enabled && c.RateLimit.Backend != "local" && c.RateLimit.Backend != "redis" {
+		return fmt.Errorf("invalid RATE_LIMIT_BACKEND %q (valid: local, redis)", c.RateLimit.Backend)
+	}
+
+	if c.RateLimit.QuotaReserve < 0 {
+		return fmt.Errorf("invalid UPSTREAM_QUOTA_RESERVE %d (must not be negative)", c.RateLimit.QuotaReserve)
+	}
+
+	if p := c.RateLimit.QuotaSlowdownPercent; p < 0 || p > 100 {
+		return fmt.Errorf("invalid UPSTREAM_QUOTA_SLOWDOWN_PERCENT %d (valid: 0-100)", p)
+	}
+
+	if c.Alerts.PollInterval <= 0 {
+		return fmt.Errorf("invalid ALERTS_POLL_INTERVAL_SEC %d (must be positive)", int(c.Alerts.PollInterval.Seconds()))
+	}
+
+	if c.Alerts.WebhookTimeout <= 0 {
+		return fmt.Errorf("invalid ALERTS_WEBHOOK_TIMEOUT_SEC %d (must be positive)", int(c.Alerts.WebhookTimeout.Seconds()))
+	}
+
+	if c.Alerts.MaxSavedSearches < 1 {
+		return fmt.Errorf("invalid ALERTS_MAX_SAVED_SEARCHES %d (must be at least 1)", c.Alerts.MaxSavedSearches)
+	}
+
+	if c.Tracing.Enabled && c.Tracing.Endpoint == "" {
+		return fmt.Errorf("invalid TRACING_OTLP_ENDPOINT (required when TRACING_ENABLED=true)")
+	}
+
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		return fmt.Errorf("invalid TRACING_SAMPLE_RATIO %v (must be between 0 and 1)", c.Tracing.SampleRatio)
 	}
 
 	return nil
 }
 
-// Helper functions to get environment variables with defaults
+// source looks settings up in the environment, then in the config file
+type source struct {
+	file map[string]string
+}
+
+// lookup returns the variable's value, or the config file's setting for it
+// when the variable is unset or empty
+func (s source) lookup(key string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return s.file[key]
+}
+
+// Helper functions to get settings with defaults
 
-func getEnv(key, defaultValue string) string {
-	value := os.Getenv(key)
+func (s source) getEnv(key, defaultValue string) string {
+	value := s.lookup(key)
 	if value == "" {
 		return defaultValue
 	}
 	return value
 }
 
-func getIntEnv(key string, defaultValue int) int {
-	valueStr := os.Getenv(key)
+func (s source) getIntEnv(key string, defaultValue int) int {
+	valueStr := s.lookup(key)
 	if valueStr == "" {
 		return defaultValue
 	}
@@ -151,8 +951,8 @@ func getIntEnv(key string, defaultValue int) int {
 	return value
 }
 
-func getBoolEnv(key string, defaultValue bool) bool {
-	valueStr := os.Getenv(key)
+func (s source) getBoolEnv(key string, defaultValue bool) bool {
+	valueStr := s.lookup(key)
 	if valueStr == "" {
 		return defaultValue
 	}
@@ -166,8 +966,23 @@ func getBoolEnv(key string, defaultValue bool) bool {
 	return value
 }
 
-func getDurationEnv(key string, defaultValue int) time.Duration {
-	valueStr := os.Getenv(key)
+func (s source) getFloatEnv(key string, defaultValue float64) float64 {
+	valueStr := s.lookup(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+
+	value, err := strconv.ParseFloat(valueStr, 64)
+	if err != nil {
+		log.Printf("WARNING: Invalid float value for %s: %s. Using default: %g", key, valueStr, defaultValue)
+		return defaultValue
+	}
+
+	return value
+}
+
+func (s source) getDurationEnv(key string, defaultValue int) time.Duration {
+	valueStr := s.lookup(key)
 	if valueStr == "" {
 		return time.Duration(defaultValue)
 	}
@@ -0,0 +1,294 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fileKeys maps every config file setting, a dotted path of YAML keys, to
+// the environment variable it stands in for. Keys missing here are
+// rejected, so a typo can't silently leave a default in place.
+var fileKeys = map[string]string{
+	"server.grpc_port":                  "GRPC_SERVER_PORT",
+	"server.http_port":                  "HTTP_SERVER_PORT",
+	"server.unix_socket":                "GRPC_UNIX_SOCKET",
+	"server.timeout_ms":                 "SERVER_TIMEOUT_MS",
+	"server.per_api_timeout_ms":         "PER_API_TIMEOUT_MS",
+	"server.max_timeout_ms":             "MAX_SERVER_TIMEOUT_MS",
+	"server.max_per_api_timeout_ms":     "MAX_PER_API_TIMEOUT_MS",
+	"server.shutdown_drain_timeout_sec": "SHUTDOWN_DRAIN_TIMEOUT_SEC",
+	"server.multi_search_max_queries":   "MULTI_SEARCH_MAX_QUERIES",
+
+	"grpc.max_recv_msg_bytes":              "GRPC_MAX_RECV_MSG_BYTES",
+	"grpc.max_send_msg_bytes":              "GRPC_MAX_SEND_MSG_BYTES",
+	"grpc.max_concurrent_streams":          "GRPC_MAX_CONCURRENT_STREAMS",
+	"grpc.keepalive_time_sec":              "GRPC_KEEPALIVE_TIME_SEC",
+	"grpc.keepalive_timeout_sec":           "GRPC_KEEPALIVE_TIMEOUT_SEC",
+	"grpc.max_connection_idle_sec":         "GRPC_MAX_CONNECTION_IDLE_SEC",
+	"grpc.max_connection_age_sec":          "GRPC_MAX_CONNECTION_AGE_SEC",
+	"grpc.max_connection_age_grace_sec":    "GRPC_MAX_CONNECTION_AGE_GRACE_SEC",
+	"grpc.keepalive_min_time_sec":          "GRPC_KEEPALIVE_MIN_TIME_SEC",
+	"grpc.keepalive_permit_without_stream": "GRPC_KEEPALIVE_PERMIT_WITHOUT_STREAM",
+
+	"tls.cert_file":           "GRPC_TLS_CERT_FILE",
+	"tls.key_file":            "GRPC_TLS_KEY_FILE",
+	"tls.client_ca_file":      "GRPC_TLS_CLIENT_CA_FILE",
+	"tls.reload_interval_sec": "GRPC_TLS_RELOAD_INTERVAL_SEC",
+
+	"platforms.github.api_token":          "GITHUB_API_TOKEN",
+	"platforms.github.anonymous_fallback": "GITHUB_ANONYMOUS_FALLBACK",
+	"platforms.github.base_url":           "GITHUB_API_BASE_URL",
+	"platforms.github.rate_limit_per_min": "RATE_LIMIT_GITHUB_PER_MIN",
+
+	"platforms.stackoverflow.api_key":            "STACKOVERFLOW_API_KEY",
+	"platforms.stackoverflow.anonymous_fallback": "STACKOVERFLOW_ANONYMOUS_FALLBACK",
+	"platforms.stackoverflow.base_url":           "STACKOVERFLOW_API_BASE_URL",
+	"platforms.stackoverflow.body_excerpts":      "STACKOVERFLOW_BODY_EXCERPTS",
+	"platforms.stackoverflow.sites":              "STACKEXCHANGE_SITES",
+	"platforms.stackoverflow.rate_limit_per_min": "RATE_LIMIT_STACKOVERFLOW_PER_MIN",
+
+	"platforms.reddit.client_id":          "REDDIT_CLIENT_ID",
+	"platforms.reddit.client_secret":      "REDDIT_CLIENT_SECRET",
+	"platforms.reddit.user_agent":         "REDDIT_USER_AGENT",
+	"platforms.reddit.base_url":           "REDDIT_API_BASE_URL",
+	"platforms.reddit.snippet_format":     "REDDIT_SNIPPET_FORMAT",
+	"platforms.reddit.rate_limit_per_min": "RATE_LIMIT_REDDIT_PER_MIN",
+
+	"platforms.hackernews.base_url":           "HACKERNEWS_API_BASE_URL",
+	"platforms.hackernews.rate_limit_per_min": "RATE_LIMIT_HACKERNEWS_PER_MIN",
+
+	"platforms.dockerhub.base_url":           "DOCKERHUB_API_BASE_URL",
+	"platforms.dockerhub.rate_limit_per_min": "RATE_LIMIT_DOCKERHUB_PER_MIN",
+
+	"platforms.devto.base_url":           "DEVTO_API_BASE_URL",
+	"platforms.devto.rate_limit_per_min": "RATE_LIMIT_DEVTO_PER_MIN",
+
+	"performance.max_results_per_platform":            "MAX_RESULTS_PER_PLATFORM",
+	"performance.circuit_breaker_enabled":             "ENABLE_CIRCUIT_BREAKER",
+	"performance.circuit_breaker_threshold":           "CIRCUIT_BREAKER_THRESHOLD",
+	"performance.circuit_breaker_timeout_sec":         "CIRCUIT_BREAKER_TIMEOUT_SEC",
+	"performance.direct_proto_conversion":             "DIRECT_PROTO_CONVERSION",
+	"performance.endpoint_selection":                  "ENDPOINT_SELECTION",
+	"performance.fetcher_init":                        "FETCHER_INIT",
+	"performance.retry_max_attempts":                  "RETRY_MAX_ATTEMPTS",
+	"performance.retry_base_delay_ms":                 "RETRY_BASE_DELAY_MS",
+	"performance.retry_jitter":                        "RETRY_JITTER",
+	"performance.max_concurrent_fetches":              "MAX_CONCURRENT_FETCHES",
+	"performance.max_concurrent_fetches_per_platform": "MAX_CONCURRENT_FETCHES_PER_PLATFORM",
+	"performance.fetch_queue_size":                    "FETCH_QUEUE_SIZE",
+
+	"http_client.timeout_ms":              "HTTP_CLIENT_TIMEOUT_MS",
+	"http_client.proxy_url":               "HTTP_PROXY_URL",
+	"http_client.max_response_bytes":      "HTTP_MAX_RESPONSE_BYTES",
+	"http_client.max_idle_conns":          "HTTP_MAX_IDLE_CONNS",
+	"http_client.max_idle_conns_per_host": "HTTP_MAX_IDLE_CONNS_PER_HOST",
+	"http_client.max_conns_per_host":      "HTTP_MAX_CONNS_PER_HOST",
+	"http_client.idle_conn_timeout_sec":   "HTTP_IDLE_CONN_TIMEOUT_SEC",
+	"http_client.dns_cache_ttl_sec":       "DNS_CACHE_TTL_SEC",
+	"http_client.vcr_mode":                "UPSTREAM_VCR_MODE",
+	"http_client.vcr_dir":                 "UPSTREAM_VCR_DIR",
+
+	"limits.default_snippet_length": "DEFAULT_SNIPPET_LENGTH",
+	"limits.max_snippet_length":     "MAX_SNIPPET_LENGTH",
+	"limits.max_total_results":      "MAX_TOTAL_RESULTS",
+	"limits.max_snippet_bytes":      "MAX_SNIPPET_BYTES",
+	"limits.max_metadata_bytes":     "MAX_METADATA_BYTES",
+	"limits.max_response_bytes":     "MAX_RESPONSE_BYTES",
+	"limits.continuation_ttl_sec":   "CONTINUATION_TTL_SEC",
+
+	"rate_limit.enabled":    "RATE_LIMIT_ENABLED",
+	"rate_limit.backend":    "RATE_LIMIT_BACKEND",
+	"rate_limit.key_prefix": "RATE_LIMIT_KEY_PREFIX",
+	"rate_limit.burst":      "RATE_LIMIT_BURST",
+
+	"rate_limit.quota_throttle":         "UPSTREAM_QUOTA_THROTTLE",
+	"rate_limit.quota_reserve":          "UPSTREAM_QUOTA_RESERVE",
+	"rate_limit.quota_slowdown_percent": "UPSTREAM_QUOTA_SLOWDOWN_PERCENT",
+
+	"redis.addr":     "REDIS_ADDR",
+	"redis.password": "REDIS_PASSWORD",
+	"redis.db":       "REDIS_DB",
+
+	"local_index.enabled": "LOCAL_INDEX_ENABLED",
+	"local_index.path":    "LOCAL_INDEX_PATH",
+
+	"store.enabled": "STORE_ENABLED",
+	"store.driver":  "STORE_DRIVER",
+	"store.dsn":     "STORE_DSN",
+
+	"events.enabled": "EVENTS_ENABLED",
+	"events.backend": "EVENTS_BACKEND",
+	"events.brokers": "EVENTS_BROKERS",
+	"events.topic":   "EVENTS_TOPIC",
+
+	"alerts.enabled":                "ALERTS_ENABLED",
+	"alerts.db_path":                "ALERTS_DB_PATH",
+	"alerts.poll_interval_sec":      "ALERTS_POLL_INTERVAL_SEC",
+	"alerts.webhook_timeout_sec":    "ALERTS_WEBHOOK_TIMEOUT_SEC",
+	"alerts.max_saved_searches":     "ALERTS_MAX_SAVED_SEARCHES",
+	"alerts.token":                  "ALERTS_TOKEN",
+	"alerts.allow_private_webhooks": "ALERTS_ALLOW_PRIVATE_WEBHOOKS",
+
+	"semantic_cache.enabled":     "SEMANTIC_CACHE_ENABLED",
+	"semantic_cache.threshold":   "SEMANTIC_CACHE_THRESHOLD",
+	"semantic_cache.ttl_sec":     "SEMANTIC_CACHE_TTL_SEC",
+	"semantic_cache.max_entries": "SEMANTIC_CACHE_MAX_ENTRIES",
+
+	"response_cache.enabled":     "RESPONSE_CACHE_ENABLED",
+	"response_cache.backend":     "RESPONSE_CACHE_BACKEND",
+	"response_cache.ttl_sec":     "RESPONSE_CACHE_TTL_SEC",
+	"response_cache.max_entries": "RESPONSE_CACHE_MAX_ENTRIES",
+	"response_cache.key_prefix":  "RESPONSE_CACHE_KEY_PREFIX",
+
+	"embeddings.provider":   "EMBEDDING_PROVIDER",
+	"embeddings.api_url":    "EMBEDDING_API_URL",
+	"embeddings.model":      "EMBEDDING_MODEL",
+	"embeddings.api_key":    "EMBEDDING_API_KEY",
+	"embeddings.timeout_ms": "EMBEDDING_TIMEOUT_MS",
+
+	"summarize.enabled":            "SUMMARIZE_ENABLED",
+	"summarize.api_url":            "SUMMARIZE_API_URL",
+	"summarize.model":              "SUMMARIZE_MODEL",
+	"summarize.api_key":            "SUMMARIZE_API_KEY",
+	"summarize.timeout_ms":         "SUMMARIZE_TIMEOUT_MS",
+	"summarize.min_snippet_length": "SUMMARIZE_MIN_SNIPPET_LENGTH",
+	"summarize.concurrency":        "SUMMARIZE_CONCURRENCY",
+
+	"answer.enabled":         "ANSWER_ENABLED",
+	"answer.api_url":         "ANSWER_API_URL",
+	"answer.model":           "ANSWER_MODEL",
+	"answer.api_key":         "ANSWER_API_KEY",
+	"answer.timeout_ms":      "ANSWER_TIMEOUT_MS",
+	"answer.default_sources": "ANSWER_DEFAULT_SOURCES",
+
+	"suggestions.enabled":      "SUGGESTIONS_ENABLED",
+	"suggestions.max":          "SUGGESTIONS_MAX",
+	"suggestions.history_size": "SUGGESTIONS_HISTORY_SIZE",
+
+	"ranking.default_sort":        "RANKING_DEFAULT_SORT",
+	"ranking.rrf_k":               "RANKING_RRF_K",
+	"ranking.semantic_rerank":     "RANKING_SEMANTIC_RERANK",
+	"ranking.rerank_top_n":        "RANKING_RERANK_TOP_N",
+	"ranking.rerank_timeout_ms":   "RANKING_RERANK_TIMEOUT_MS",
+	"ranking.score_normalization": "RANKING_SCORE_NORMALIZATION",
+
+	"sources.allow": "SOURCES_ALLOW",
+	"sources.deny":  "SOURCES_DENY",
+
+	"mock.enabled":      "MOCK_MODE",
+	"mock.platforms":    "MOCK_PLATFORMS",
+	"mock.fixtures_dir": "MOCK_FIXTURES_DIR",
+
+	"deterministic.enabled": "DETERMINISTIC_MODE",
+	"deterministic.seed":    "DETERMINISTIC_SEED",
+
+	"admin.token": "ADMIN_TOKEN",
+
+	"logging.level":  "LOG_LEVEL",
+	"logging.format": "LOG_FORMAT",
+
+	"tracing.enabled":       "TRACING_ENABLED",
+	"tracing.otlp_endpoint": "TRACING_OTLP_ENDPOINT",
+	"tracing.otlp_insecure": "TRACING_OTLP_INSECURE",
+	"tracing.service_name":  "TRACING_SERVICE_NAME",
+	"tracing.sample_ratio":  "TRACING_SAMPLE_RATIO",
+}
+
+// readFile reads a YAML config file into the values of the environment
+// variables its settings stand in for. Lists are joined with commas, the
+// way the variables take them.
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	values := map[string]string{}
+	// An empty file has no document at all
+	if len(doc.Content) == 0 {
+		return values, nil
+	}
+	if err := readSection(doc.Content[0], "", values); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return values, nil
+}
+
+// readSection reads the settings of one mapping, and of the sections
+// nested in it, whose keys start with prefix
+func readSection(node *yaml.Node, prefix string, values map[string]string) error {
+	if node.Kind != yaml.MappingNode {
+		if prefix == "" {
+			return fmt.Errorf("line %d: the file must be a mapping of sections", node.Line)
+		}
+		return fmt.Errorf("line %d: %s must be a mapping", node.Line, prefix)
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, value := node.Content[i], node.Content[i+1]
+		key := keyNode.Value
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if value.Kind == yaml.AliasNode {
+			value = value.Alias
+		}
+
+		if env, ok := fileKeys[key]; ok {
+			s, err := settingValue(value)
+			if err != nil {
+				return fmt.Errorf("line %d: %s %w", value.Line, key, err)
+			}
+			values[env] = s
+			continue
+		}
+		if !isSection(key) {
+			return fmt.Errorf("line %d: unknown key %q", keyNode.Line, key)
+		}
+		if err := readSection(value, key, values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isSection reports whether key holds other settings, like "platforms" or
+// "platforms.github"
+func isSection(key string) bool {
+	for setting := range fileKeys {
+		if strings.HasPrefix(setting, key+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// settingValue renders a scalar or a list of scalars as the environment
+// variable would spell it
+func settingValue(node *yaml.Node) (string, error) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if node.Tag == "!!null" {
+			return "", nil
+		}
+		return node.Value, nil
+	case yaml.SequenceNode:
+		items := make([]string, len(node.Content))
+		for i, item := range node.Content {
+			if item.Kind != yaml.ScalarNode {
+				return "", fmt.Errorf("must be a list of values")
+			}
+			items[i] = item.Value
+		}
+		return strings.Join(items, ","), nil
+	}
+	return "", fmt.Errorf("must be a value or a list of values")
+}
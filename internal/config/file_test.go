@@ -0,0 +1,90 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadReadsConfigFileUnderEnvironment(t *testing.T) {
+	path := writeConfigFile(t, `
+server:
+  timeout_ms: 800
+  per_api_timeout_ms: 600
+platforms:
+  github:
+    api_token: [first, second]
+    rate_limit_per_min: 10
+  reddit:
+    snippet_format: markdown
+response_cache:
+  enabled: true
+  ttl_sec: ~
+`)
+	t.Setenv("PER_API_TIMEOUT_MS", "700")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if cfg.Server.ServerTimeout != 800*time.Millisecond {
+		t.Errorf("ServerTimeout = %v, want 800ms from the file", cfg.Server.ServerTimeout)
+	}
+	if cfg.Server.PerAPITimeout != 700*time.Millisecond {
+		t.Errorf("PerAPITimeout = %v, want 700ms from the environment", cfg.Server.PerAPITimeout)
+	}
+	if cfg.GitHub.APIToken != "first,second" || cfg.RateLimit.GitHubPerMinute != 10 {
+		t.Errorf("GitHub = %q at %d/min, want both tokens at 10/min", cfg.GitHub.APIToken, cfg.RateLimit.GitHubPerMinute)
+	}
+	if cfg.Reddit.SnippetFormat != "markdown" || !cfg.ResponseCache.Enabled {
+		t.Errorf("Reddit.SnippetFormat = %q, ResponseCache.Enabled = %t; want the file's settings",
+			cfg.Reddit.SnippetFormat, cfg.ResponseCache.Enabled)
+	}
+	if cfg.ResponseCache.TTL != time.Minute {
+		t.Errorf("ResponseCache.TTL = %v, want the default for a null setting", cfg.ResponseCache.TTL)
+	}
+}
+
+func TestExampleConfigFileLoads(t *testing.T) {
+	cfg, err := Load(filepath.Join("..", "..", "config.example.yaml"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Server.ServerTimeout != 2*time.Second || cfg.HTTPClient.ProxyURL != "" {
+		t.Errorf("ServerTimeout = %v, ProxyURL = %q; want the example's values", cfg.Server.ServerTimeout, cfg.HTTPClient.ProxyURL)
+	}
+}
+
+func TestLoadRejectsBadConfigFiles(t *testing.T) {
+	for _, tc := range []struct {
+		name, content, want string
+	}{
+		{"unknown section", "sever:\n  grpc_port: 1\n", `line 1: unknown key "sever"`},
+		{"unknown key", "platforms:\n  github:\n    token: x\n", `line 3: unknown key "platforms.github.token"`},
+		{"value for a section", "platforms: github\n", "platforms must be a mapping"},
+		{"section for a value", "redis:\n  addr:\n    host: x\n", "redis.addr must be a value"},
+		{"not a mapping", "- server\n", "must be a mapping of sections"},
+		{"invalid YAML", "server: [\n", "failed to parse"},
+	} {
+		_, err := Load(writeConfigFile(t, tc.content))
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: Load() error = %v, want %q", tc.name, err, tc.want)
+		}
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("Load() of a missing file succeeded")
+	}
+}
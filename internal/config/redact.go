@@ -0,0 +1,76 @@
+package config
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// redacted stands in for a secret in Config.Redacted
+const redacted = "REDACTED"
+
+// secretFields are the fields holding tokens, keys and passwords
+var secretFields = map[string]bool{
+	"APIToken":     true,
+	"APIKey":       true,
+	"ClientSecret": true,
+	"Password":     true,
+	"Token":        true,
+	// Database DSNs may carry a password in any of several syntaxes
+	"DSN": true,
+}
+
+// Redacted returns the configuration as nested maps of field names to
+// values, ready to encode as JSON. Secrets that are set become "REDACTED",
+// passwords in URLs are masked, and durations are written like "500ms".
+func (c *Config) Redacted() map[string]any {
+	return redactStruct(reflect.ValueOf(c).Elem())
+}
+
+func redactStruct(v reflect.Value) map[string]any {
+	fields := make(map[string]any, v.NumField())
+	for i := range v.NumField() {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fields[field.Name] = redactValue(field.Name, v.Field(i))
+	}
+	return fields
+}
+
+func redactValue(name string, v reflect.Value) any {
+	if d, ok := v.Interface().(time.Duration); ok {
+		return d.String()
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		return redactStruct(v)
+	case reflect.String:
+		s := v.String()
+		if s != "" && secretFields[name] {
+			return redacted
+		}
+		return redactURLs(s)
+	}
+	return v.Interface()
+}
+
+// redactURLs masks the passwords of the URLs in a comma-separated list,
+// leaving anything else as it is
+func redactURLs(s string) string {
+	if !strings.Contains(s, "@") {
+		return s
+	}
+	parts := strings.Split(s, ",")
+	for i, part := range parts {
+		if u, err := url.Parse(strings.TrimSpace(part)); err == nil && u.User != nil {
+			if _, hasPassword := u.User.Password(); hasPassword {
+				u.User = url.UserPassword(u.User.Username(), redacted)
+				parts[i] = u.String()
+			}
+		}
+	}
+	return strings.Join(parts, ",")
+}
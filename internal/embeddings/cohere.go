@@ -0,0 +1,90 @@
+package embeddings
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// CohereEmbedder calls Cohere's v2 /embed endpoint
+type CohereEmbedder struct {
+	url    string
+	model  string
+	apiKey string
+	client *http.Client
+}
+
+// NewCohere creates an embedder posting to url
+func NewCohere(url, model, apiKey string, client *http.Client) *CohereEmbedder {
+	return &CohereEmbedder{
+		url:    url,
+		model:  model,
+		apiKey: apiKey,
+		client: client,
+	}
+}
+
+type cohereRequest struct {
+	Model          string   `json:"model"`
+	Texts          []string `json:"texts"`
+	InputType      string   `json:"input_type"`
+	EmbeddingTypes []string `json:"embedding_types"`
+}
+
+type cohereResponse struct {
+	Embeddings struct {
+		Float [][]float32 `json:"float"`
+	} `json:"embeddings"`
+}
+
+// Embed returns the embedding vector for text, embedded as a search query
+func (e *CohereEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	vectors, err := e.embed(ctx, []string{text}, "search_query")
+	if err != nil {
+		return nil, err
+	}
+	return vectors[0], nil
+}
+
+// EmbedDocuments returns the embedding vectors for texts, embedded as
+// documents to search, in one request
+func (e *CohereEmbedder) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	return e.embed(ctx, texts, "search_document")
+}
+
+func (e *CohereEmbedder) embed(ctx context.Context, texts []string, inputType string) ([][]float32, error) {
+	body, err := json.Marshal(cohereRequest{
+		Model:          e.model,
+		Texts:          texts,
+		InputType:      inputType,
+		EmbeddingTypes: []string{"float"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode embedding request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+e.apiKey)
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError("Cohere", resp)
+	}
+
+	var embResp cohereResponse
+	if err := json.NewDecoder(resp.Body).Decode(&embResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return checkVectors(embResp.Embeddings.Float, len(texts))
+}
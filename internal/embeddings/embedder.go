@@ -0,0 +1,91 @@
+// Package embeddings turns text into vectors for semantic caching and
+// ranking, using a configurable model provider
+package embeddings
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Embedder turns text into a vector
+type Embedder interface {
+	Embed(ctx context.Context, text string) ([]float32, error)
+}
+
+// DocumentEmbedder is an Embedder that can embed many texts in one call, as
+// documents to compare with an embedded query. All built-in providers are.
+type DocumentEmbedder interface {
+	EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// EmbedDocuments returns a vector for each of texts, in one call when e is a
+// DocumentEmbedder and one call per text otherwise
+func EmbedDocuments(ctx context.Context, e Embedder, texts []string) ([][]float32, error) {
+	if d, ok := e.(DocumentEmbedder); ok {
+		return d.EmbedDocuments(ctx, texts)
+	}
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vector, err := e.Embed(ctx, text)
+		if err != nil {
+			return nil, err
+		}
+		vectors[i] = vector
+	}
+	return vectors, nil
+}
+
+// New creates an embedder for provider. Empty url and model select the
+// provider's defaults.
+func New(provider, url, model, apiKey string, client *http.Client) (Embedder, error) {
+	switch provider {
+	case "openai":
+		return NewOpenAI(
+			orDefault(url, "http://localhost:11434/v1/embeddings"),
+			orDefault(model, "nomic-embed-text"),
+			apiKey, client,
+		), nil
+	case "ollama":
+		return NewOllama(
+			orDefault(url, "http://localhost:11434/api/embed"),
+			orDefault(model, "nomic-embed-text"),
+			client,
+		), nil
+	case "cohere":
+		return NewCohere(
+			orDefault(url, "https://api.cohere.com/v2/embed"),
+			orDefault(model, "embed-english-v3.0"),
+			apiKey, client,
+		), nil
+	}
+	return nil, fmt.Errorf("unknown embedding provider %q", provider)
+}
+
+func orDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// checkVectors makes sure an embedding API answered with a vector for each
+// of n texts
+func checkVectors(vectors [][]float32, n int) ([][]float32, error) {
+	if len(vectors) != n {
+		return nil, fmt.Errorf("embedding API returned %d vectors for %d texts", len(vectors), n)
+	}
+	for _, vector := range vectors {
+		if len(vector) == 0 {
+			return nil, fmt.Errorf("embedding API returned no vector")
+		}
+	}
+	return vectors, nil
+}
+
+// statusError describes a non-200 answer from an embedding API
+func statusError(provider string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("%s embedding API error: status=%d, body=%s", provider, resp.StatusCode, string(body))
+}
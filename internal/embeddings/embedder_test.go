@@ -0,0 +1,119 @@
+package embeddings
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestProviders(t *testing.T) {
+	tests := []struct {
+		provider string
+		response string
+		// field is a request body field that must carry the text
+		field string
+	}{
+		{"openai", `{"data":[{"embedding":[0.5,1]}]}`, "input"},
+		{"ollama", `{"embeddings":[[0.5,1]]}`, "input"},
+		{"cohere", `{"embeddings":{"float":[[0.5,1]]}}`, "texts"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			var got map[string]any
+			upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				json.NewDecoder(r.Body).Decode(&got)
+				w.Write([]byte(tt.response))
+			}))
+			defer upstream.Close()
+
+			embedder, err := New(tt.provider, upstream.URL, "", "key", upstream.Client())
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			vector, err := embedder.Embed(context.Background(), "goroutine leak")
+			if err != nil {
+				t.Fatalf("Embed() error = %v", err)
+			}
+
+			if !slices.Equal(vector, []float32{0.5, 1}) {
+				t.Errorf("Embed() = %v, want [0.5 1]", vector)
+			}
+			if !strings.Contains(fmtValue(got[tt.field]), "goroutine leak") || got["model"] == "" {
+				t.Errorf("request = %v, want the text in %q and a default model", got, tt.field)
+			}
+		})
+	}
+}
+
+func TestEmbedReportsUpstreamErrors(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid api token", http.StatusUnauthorized)
+	}))
+	defer upstream.Close()
+
+	embedder, _ := New("cohere", upstream.URL, "", "", upstream.Client())
+	if _, err := embedder.Embed(context.Background(), "go"); err == nil || !strings.Contains(err.Error(), "status=401") {
+		t.Errorf("Embed() error = %v, want a 401 error", err)
+	}
+}
+
+func TestEmbedDocuments(t *testing.T) {
+	tests := []struct {
+		provider string
+		response string
+	}{
+		// OpenAI-compatible servers may answer out of order; index says
+		{"openai", `{"data":[{"index":1,"embedding":[0,1]},{"index":0,"embedding":[1,0]}]}`},
+		{"ollama", `{"embeddings":[[1,0],[0,1]]}`},
+		{"cohere", `{"embeddings":{"float":[[1,0],[0,1]]}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			var got map[string]any
+			upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				json.NewDecoder(r.Body).Decode(&got)
+				w.Write([]byte(tt.response))
+			}))
+			defer upstream.Close()
+
+			embedder, _ := New(tt.provider, upstream.URL, "", "key", upstream.Client())
+			vectors, err := EmbedDocuments(context.Background(), embedder, []string{"first", "second"})
+			if err != nil {
+				t.Fatalf("EmbedDocuments() error = %v", err)
+			}
+			if len(vectors) != 2 || !slices.Equal(vectors[0], []float32{1, 0}) || !slices.Equal(vectors[1], []float32{0, 1}) {
+				t.Errorf("EmbedDocuments() = %v, want one vector per text in order", vectors)
+			}
+			if tt.provider == "cohere" && got["input_type"] != "search_document" {
+				t.Errorf("input_type = %v, want search_document", got["input_type"])
+			}
+		})
+	}
+}
+
+func TestEmbedDocumentsRejectsMissingVectors(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"embeddings":[[1,0]]}`))
+	}))
+	defer upstream.Close()
+
+	embedder, _ := New("ollama", upstream.URL, "", "", upstream.Client())
+	if _, err := EmbedDocuments(context.Background(), embedder, []string{"a", "b"}); err == nil {
+		t.Error("EmbedDocuments() error = nil, want an error for 1 vector for 2 texts")
+	}
+}
+
+func TestNewRejectsUnknownProvider(t *testing.T) {
+	if _, err := New("onnx", "", "", "", http.DefaultClient); err == nil {
+		t.Error("New(onnx) error = nil, want an unknown provider error")
+	}
+}
+
+func fmtValue(v any) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
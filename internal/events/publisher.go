@@ -0,0 +1,158 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/segmentio/kafka-go"
+
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+// Publisher emits completed-search events to a message broker
+type Publisher interface {
+	Publish(ctx context.Context, event *SearchEvent) error
+	Close() error
+}
+
+// SearchEvent describes one completed federated search
+type SearchEvent struct {
+	Query          string          `json:"query"`
+	Platforms      []string        `json:"platforms"`
+	ResponseTimeMs int32           `json:"response_time_ms"`
+	TotalCount     int32           `json:"total_count"`
+	PlatformStats  []PlatformStats `json:"platform_stats"`
+	Results        []ResultSummary `json:"results"`
+	Timestamp      int64           `json:"timestamp"`
+}
+
+// PlatformStats is the outcome of a single platform within a search
+type PlatformStats struct {
+	Platform    string `json:"platform"`
+	Status      string `json:"status"`
+	ResultCount int    `json:"result_count"`
+	DurationMs  int64  `json:"duration_ms"`
+}
+
+// ResultSummary is the part of a result that downstream consumers need
+type ResultSummary struct {
+	Platform string `json:"platform"`
+	Title    string `json:"title"`
+	URL      string `json:"url"`
+}
+
+// NewSearchEvent builds an event from a finished search. durations holds the
+// fetch time of each platform that reported back.
+func NewSearchEvent(req *pb.SearchRequest, resp *pb.SearchResponse, durations map[string]time.Duration) *SearchEvent {
+	event := &SearchEvent{
+		Query:      req.Query,
+		Platforms:  req.Platforms,
+		TotalCount: resp.TotalCount,
+		Timestamp:  time.Now().Unix(),
+	}
+	if resp.Metadata != nil {
+		event.ResponseTimeMs = resp.Metadata.ResponseTimeMs
+	}
+
+	counts := make(map[string]int)
+	for _, result := range resp.Results {
+		counts[result.Platform]++
+		event.Results = append(event.Results, ResultSummary{
+			Platform: result.Platform,
+			Title:    result.Title,
+			URL:      result.Url,
+		})
+	}
+
+	addStats := func(platforms []string, status string) {
+		for _, platform := range platforms {
+			event.PlatformStats = append(event.PlatformStats, PlatformStats{
+				Platform:    platform,
+				Status:      status,
+				ResultCount: counts[platform],
+				DurationMs:  durations[platform].Milliseconds(),
+			})
+		}
+	}
+	addStats(resp.PlatformsSuccess, "success")
+	addStats(resp.PlatformsTimeout, "timeout")
+	addStats(resp.PlatformsError, "error")
+
+	return event
+}
+
+// NewPublisher creates a publisher for the given broker. backend is "kafka"
+// (brokers is a comma-separated address list) or "nats" (brokers is the server URL).
+func NewPublisher(backend, brokers, topic string) (Publisher, error) {
+	switch backend {
+	case "kafka":
+		return &kafkaPublisher{
+			writer: &kafka.Writer{
+				Addr:         kafka.TCP(strings.Split(brokers, ",")...),
+				Topic:        topic,
+				Balancer:     &kafka.Hash{},
+				RequiredAcks: kafka.RequireOne,
+				Async:        true,
+				// Async writes return before the broker answers, so this is
+				// the only place their failures show up
+				Completion: func(messages []kafka.Message, err error) {
+					if err != nil {
+						log.Printf("ERROR: Failed to publish %d search events: %v", len(messages), err)
+					}
+				},
+			},
+		}, nil
+	case "nats":
+		conn, err := nats.Connect(brokers, nats.Name("search-proxy"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to NATS at %s: %w", brokers, err)
+		}
+		return &natsPublisher{conn: conn, subject: topic}, nil
+	default:
+		return nil, fmt.Errorf("unsupported event backend %q (valid: kafka, nats)", backend)
+	}
+}
+
+type kafkaPublisher struct {
+	writer *kafka.Writer
+}
+
+func (p *kafkaPublisher) Publish(ctx context.Context, event *SearchEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	// Keying by query keeps all events for one query in the same partition
+	return p.writer.WriteMessages(ctx, kafka.Message{
+		Key:   []byte(strings.ToLower(event.Query)),
+		Value: payload,
+	})
+}
+
+// Close flushes the events still buffered by the writer
+func (p *kafkaPublisher) Close() error {
+	return p.writer.Close()
+}
+
+type natsPublisher struct {
+	conn    *nats.Conn
+	subject string
+}
+
+func (p *natsPublisher) Publish(ctx context.Context, event *SearchEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+	return p.conn.Publish(p.subject, payload)
+}
+
+func (p *natsPublisher) Close() error {
+	return p.conn.Drain()
+}
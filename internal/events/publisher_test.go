@@ -0,0 +1,153 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+func TestNewSearchEventSummarizesSearch(t *testing.T) {
+	req := &pb.SearchRequest{Query: "golang", Platforms: []string{"github", "reddit", "devto"}}
+	resp := &pb.SearchResponse{
+		Results: []*pb.Result{
+			{Platform: "github", Title: "golang/go", Url: "https://github.com/golang/go", Snippet: "not sent"},
+			{Platform: "github", Title: "golang/tools", Url: "https://github.com/golang/tools"},
+		},
+		TotalCount:       2,
+		PlatformsSuccess: []string{"github"},
+		PlatformsTimeout: []string{"reddit"},
+		PlatformsError:   []string{"devto"},
+		Metadata:         &pb.ResponseMetadata{ResponseTimeMs: 250},
+	}
+	durations := map[string]time.Duration{"github": 120 * time.Millisecond, "devto": 40 * time.Millisecond}
+
+	event := NewSearchEvent(req, resp, durations)
+
+	if event.Query != "golang" || event.TotalCount != 2 || event.ResponseTimeMs != 250 || event.Timestamp == 0 {
+		t.Errorf("event = %+v, want the search's query, count, time and timestamp", event)
+	}
+	if len(event.Results) != 2 || event.Results[1] != (ResultSummary{Platform: "github", Title: "golang/tools", URL: "https://github.com/golang/tools"}) {
+		t.Errorf("results = %+v, want each result's platform, title and URL", event.Results)
+	}
+	want := []PlatformStats{
+		{Platform: "github", Status: "success", ResultCount: 2, DurationMs: 120},
+		{Platform: "reddit", Status: "timeout"},
+		{Platform: "devto", Status: "error", DurationMs: 40},
+	}
+	if len(event.PlatformStats) != len(want) {
+		t.Fatalf("platform stats = %+v, want %+v", event.PlatformStats, want)
+	}
+	for i := range want {
+		if event.PlatformStats[i] != want[i] {
+			t.Errorf("platform stats[%d] = %+v, want %+v", i, event.PlatformStats[i], want[i])
+		}
+	}
+}
+
+func TestNewSearchEventWithoutMetadata(t *testing.T) {
+	event := NewSearchEvent(&pb.SearchRequest{Query: "go"}, &pb.SearchResponse{}, nil)
+	if event.ResponseTimeMs != 0 || len(event.Results) != 0 || len(event.PlatformStats) != 0 {
+		t.Errorf("event = %+v, want an empty summary", event)
+	}
+}
+
+func TestNewPublisherRejectsUnknownBackend(t *testing.T) {
+	if _, err := NewPublisher("rabbitmq", "localhost:5672", "searches"); err == nil {
+		t.Error("NewPublisher(rabbitmq) succeeded, want an error")
+	}
+}
+
+func TestNewPublisherFailsWhenNATSIsUnreachable(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := lis.Addr().String()
+	lis.Close()
+
+	if _, err := NewPublisher("nats", "nats://"+addr, "searches"); err == nil {
+		t.Error("NewPublisher(nats) with no server succeeded, want an error")
+	}
+}
+
+// fakeNATS speaks enough of the NATS protocol to accept one client's
+// publications, sending each PUB's subject and payload to published
+func fakeNATS(t *testing.T) (url string, published <-chan [2]string) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { lis.Close() })
+
+	messages := make(chan [2]string, 10)
+	go func() {
+		conn, err := lis.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		fmt.Fprint(conn, "INFO {\"server_id\":\"fake\",\"max_payload\":1048576}\r\n")
+		r := bufio.NewReader(conn)
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			fields := strings.Fields(line)
+			if len(fields) == 0 {
+				continue
+			}
+			switch fields[0] {
+			case "PING":
+				fmt.Fprint(conn, "PONG\r\n")
+			case "PUB":
+				var size int
+				fmt.Sscan(fields[len(fields)-1], &size)
+				payload := make([]byte, size+2)
+				if _, err := io.ReadFull(r, payload); err != nil {
+					return
+				}
+				messages <- [2]string{fields[1], string(payload[:size])}
+			}
+		}
+	}()
+	return "nats://" + lis.Addr().String(), messages
+}
+
+func TestNATSPublisherPublishesJSONEvents(t *testing.T) {
+	url, published := fakeNATS(t)
+	publisher, err := NewPublisher("nats", url, "search.completed")
+	if err != nil {
+		t.Fatalf("NewPublisher() error = %v", err)
+	}
+
+	event := &SearchEvent{Query: "golang", TotalCount: 3, Results: []ResultSummary{{Platform: "github", Title: "golang/go"}}}
+	if err := publisher.Publish(context.Background(), event); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	// Close drains, so the event is on the wire once it returns
+	if err := publisher.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	select {
+	case msg := <-published:
+		var got SearchEvent
+		if err := json.Unmarshal([]byte(msg[1]), &got); err != nil {
+			t.Fatalf("payload %q: %v", msg[1], err)
+		}
+		if msg[0] != "search.completed" || got.Query != "golang" || got.TotalCount != 3 || len(got.Results) != 1 {
+			t.Errorf("published %s %+v, want the event on search.completed", msg[0], got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event published")
+	}
+}
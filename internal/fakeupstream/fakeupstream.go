@@ -0,0 +1,302 @@
+// Package fakeupstream serves stubbed search APIs shaped like GitHub,
+// StackOverflow, Reddit, Hacker News, Docker Hub and Dev.to, with adjustable latency and failures. It backs
+// both the test harness and the standalone fake-upstream binary.
+package fakeupstream
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Platform search paths, relative to the base URL
+const (
+	GitHubPath        = "/search/repositories"
+	StackOverflowPath = "/search/advanced"
+	RedditPath        = "/search.json"
+	HackerNewsPath    = "/search"
+	// DockerHubPath differs from GitHubPath only by its trailing slash
+	DockerHubPath = "/search/repositories/"
+	DevToPath     = "/articles/search"
+
+	// GitHub's other searches: code and issues over REST, discussions over
+	// GraphQL
+	GitHubCodePath    = "/search/code"
+	GitHubIssuesPath  = "/search/issues"
+	GitHubGraphQLPath = "/graphql"
+
+	// RedditTokenPath issues OAuth access tokens
+	RedditTokenPath = "/api/v1/access_token"
+)
+
+// Behavior controls how a fake upstream answers
+type Behavior struct {
+	// Latency delays every response; the delay ends early if the client
+	// gives up
+	Latency time.Duration
+	// Jitter adds a random extra delay of up to this much
+	Jitter time.Duration
+	// StatusCode, when set to anything but 200, makes every request fail
+	// with that status
+	StatusCode int
+	// FailureRate is the fraction of requests answered with a 500
+	FailureRate float64
+	// RateLimited answers every request with the platform's own rate-limit
+	// response
+	RateLimited bool
+	// RateLimitRate is the fraction of requests answered as rate limited
+	RateLimitRate float64
+	// Results is the number of items the search matches, served in pages of
+	// the size the client asked for. Zero means 3.
+	Results int
+}
+
+// Handler is one fake platform API
+type Handler struct {
+	platform  string
+	endpoints map[string]endpoint
+
+	mu       sync.Mutex
+	behavior Behavior
+	rng      *rand.Rand
+	requests []*url.URL
+}
+
+// endpoint is one search API of a platform
+type endpoint struct {
+	pageParam string
+	// queryParam is the name of the search terms parameter
+	queryParam string
+	// offset is where the requested page starts, read from the platform's
+	// own pagination parameters
+	offset func(q url.Values, size int) int
+	body   func(query string, p page) any
+}
+
+// NewHandler creates the fake API for platform ("github", "stackoverflow",
+// "reddit", "hackernews", "dockerhub" or "devto"). seed makes the random failures and jitter reproducible.
+func NewHandler(platform string, seed uint64) (*Handler, error) {
+	h := &Handler{
+		platform: platform,
+		rng:      rand.New(rand.NewPCG(seed, 0)),
+	}
+
+	pages := pageNumberOffset("page", 1)
+	switch platform {
+	case "github":
+		h.endpoints = map[string]endpoint{
+			GitHubPath:        {"per_page", "q", pages, githubBody},
+			GitHubCodePath:    {"per_page", "q", pages, githubCodeBody},
+			GitHubIssuesPath:  {"per_page", "q", pages, githubIssuesBody},
+			GitHubGraphQLPath: {"first", "q", graphqlOffset, githubDiscussionsBody},
+		}
+	case "stackoverflow":
+		h.endpoints = map[string]endpoint{StackOverflowPath: {"pagesize", "q", pages, stackOverflowBody}}
+	case "reddit":
+		h.endpoints = map[string]endpoint{RedditPath: {"limit", "q", redditOffset, redditBody}}
+	case "hackernews":
+		h.endpoints = map[string]endpoint{HackerNewsPath: {"hitsPerPage", "query", pageNumberOffset("page", 0), hackerNewsBody}}
+	case "dockerhub":
+		h.endpoints = map[string]endpoint{DockerHubPath: {"page_size", "query", pages, dockerHubBody}}
+	case "devto":
+		h.endpoints = map[string]endpoint{DevToPath: {"per_page", "q", pages, devToBody}}
+	default:
+		return nil, fmt.Errorf("unknown platform: %s", platform)
+	}
+	return h, nil
+}
+
+// Paths returns the search paths the handler serves, sorted
+func (h *Handler) Paths() []string {
+	paths := make([]string, 0, len(h.endpoints))
+	for path := range h.endpoints {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Set replaces the behavior for subsequent requests
+func (h *Handler) Set(b Behavior) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.behavior = b
+}
+
+// Requests returns the URLs of all requests received so far. GraphQL
+// requests are recorded with their variables as query parameters.
+func (h *Handler) Requests() []*url.URL {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]*url.URL(nil), h.requests...)
+}
+
+// outcome is what the handler decided to do with one request
+type outcome struct {
+	delay       time.Duration
+	fail        bool
+	rateLimited bool
+	behavior    Behavior
+}
+
+func (h *Handler) decide(u *url.URL) outcome {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.requests = append(h.requests, u)
+	b := h.behavior
+
+	o := outcome{delay: b.Latency, behavior: b}
+	if b.Jitter > 0 {
+		o.delay += time.Duration(h.rng.Int64N(int64(b.Jitter)))
+	}
+	o.rateLimited = b.RateLimited || (b.RateLimitRate > 0 && h.rng.Float64() < b.RateLimitRate)
+	o.fail = b.FailureRate > 0 && h.rng.Float64() < b.FailureRate
+	return o
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e, ok := h.endpoints[r.URL.Path]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	u := r.URL
+	if r.URL.Path == GitHubGraphQLPath {
+		u = &url.URL{Path: r.URL.Path, RawQuery: graphqlParams(r).Encode()}
+	}
+	params := u.Query()
+	o := h.decide(u)
+
+	if o.delay > 0 {
+		select {
+		case <-time.After(o.delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	switch {
+	case o.rateLimited:
+		h.writeRateLimited(w)
+		return
+	case o.fail:
+		http.Error(w, fmt.Sprintf(`{"message":"simulated %s failure"}`, h.platform), http.StatusInternalServerError)
+		return
+	case o.behavior.StatusCode != 0 && o.behavior.StatusCode != http.StatusOK:
+		http.Error(w, fmt.Sprintf(`{"message":"simulated %s failure"}`, h.platform), o.behavior.StatusCode)
+		return
+	}
+
+	total := o.behavior.Results
+	if total == 0 {
+		total = 3
+	}
+	size := total
+	if n, err := strconv.Atoi(params.Get(e.pageParam)); err == nil && n > 0 {
+		size = n
+	}
+	p := page{offset: e.offset(params, size), size: size, total: total, params: params}
+	p.count = max(min(size, total-p.offset), 0)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(e.body(params.Get(e.queryParam), p))
+}
+
+// graphqlParams reads the variables of a GraphQL request as parameters
+func graphqlParams(r *http.Request) url.Values {
+	var request struct {
+		Variables struct {
+			Q     string `json:"q"`
+			First int    `json:"first"`
+			After string `json:"after"`
+		} `json:"variables"`
+	}
+	json.NewDecoder(r.Body).Decode(&request)
+
+	params := url.Values{"q": {request.Variables.Q}, "first": {strconv.Itoa(request.Variables.First)}}
+	if request.Variables.After != "" {
+		params.Set("after", request.Variables.After)
+	}
+	return params
+}
+
+// graphqlOffset reads the "after" cursor, which the fake sets to the offset
+// of the next page
+func graphqlOffset(q url.Values, size int) int {
+	n, _ := strconv.Atoi(q.Get("after"))
+	return n
+}
+
+// page is the slice of the matching items one response carries
+type page struct {
+	offset, count, size, total int
+	// params are the request's parameters, for payloads with optional fields
+	params url.Values
+}
+
+func (p page) more() bool {
+	return p.offset+p.count < p.total
+}
+
+// pageNumberOffset reads a page number parameter counting from first
+func pageNumberOffset(param string, first int) func(url.Values, int) int {
+	return func(q url.Values, size int) int {
+		n, err := strconv.Atoi(q.Get(param))
+		if err != nil || n < first {
+			return 0
+		}
+		return (n - first) * size
+	}
+}
+
+// redditOffset reads the "after" cursor, the fullname of the last post of
+// the previous page
+func redditOffset(q url.Values, size int) int {
+	n, _ := strconv.Atoi(strings.TrimPrefix(q.Get("after"), "t3_fake"))
+	return n
+}
+
+// RedditToken answers Reddit's client credentials grant with a fake token
+// for any credentials
+func RedditToken(w http.ResponseWriter, r *http.Request) {
+	if _, _, ok := r.BasicAuth(); !ok || r.Method != http.MethodPost {
+		http.Error(w, `{"message":"Unauthorized","error":401}`, http.StatusUnauthorized)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"access_token": "fake-token",
+		"token_type":   "bearer",
+		"expires_in":   86400,
+		"scope":        "*",
+	})
+}
+
+// writeRateLimited mimics how each platform reports an exhausted quota
+func (h *Handler) writeRateLimited(w http.ResponseWriter) {
+	reset := strconv.FormatInt(time.Now().Add(time.Minute).Unix(), 10)
+
+	switch h.platform {
+	case "github":
+		w.Header().Set("X-RateLimit-Limit", "60")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", reset)
+		http.Error(w, `{"message":"API rate limit exceeded"}`, http.StatusForbidden)
+	case "stackoverflow":
+		http.Error(w, `{"error_id":502,"error_message":"too many requests from this IP","error_name":"throttle_violation"}`,
+			http.StatusBadRequest)
+	default:
+		w.Header().Set("X-Ratelimit-Remaining", "0")
+		w.Header().Set("X-Ratelimit-Reset", "60")
+		w.Header().Set("Retry-After", "60")
+		http.Error(w, `{"message":"Too Many Requests","error":429}`, http.StatusTooManyRequests)
+	}
+}
@@ -0,0 +1,258 @@
+package fakeupstream
+
+import (
+	"fmt"
+	"time"
+)
+
+var created = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func githubBody(query string, p page) any {
+	count := p.count
+	items := make([]map[string]any, count)
+	for j := range items {
+		i := p.offset + j
+		items[j] = map[string]any{
+			"id":                i + 1,
+			"name":              fmt.Sprintf("repo-%d", i+1),
+			"full_name":         fmt.Sprintf("fake/repo-%d", i+1),
+			"description":       fmt.Sprintf("Repository %d about %s", i+1, query),
+			"html_url":          fmt.Sprintf("https://github.com/fake/repo-%d", i+1),
+			"stargazers_count":  100 * (count - j),
+			"forks_count":       10 * (count - j),
+			"language":          "Go",
+			"open_issues_count": i,
+			"created_at":        created.Format(time.RFC3339),
+			"updated_at":        created.Format(time.RFC3339),
+			"owner": map[string]any{
+				"login":      "fake",
+				"html_url":   "https://github.com/fake",
+				"avatar_url": "https://avatars.githubusercontent.com/u/1",
+			},
+		}
+	}
+	return map[string]any{"total_count": p.total, "incomplete_results": false, "items": items}
+}
+
+func stackOverflowBody(query string, p page) any {
+	count := p.count
+	items := make([]map[string]any, count)
+	for j := range items {
+		i := p.offset + j
+		items[j] = map[string]any{
+			"question_id":        1000 + i,
+			"title":              fmt.Sprintf("Question %d about %s", i+1, query),
+			"link":               fmt.Sprintf("https://stackoverflow.com/questions/%d", 1000+i),
+			"score":              count - j,
+			"answer_count":       i,
+			"view_count":         1000 * (count - j),
+			"is_answered":        i%2 == 0,
+			"tags":               []string{"go", "testing"},
+			"creation_date":      created.Unix(),
+			"last_activity_date": created.Add(time.Duration(i) * time.Hour).Unix(),
+			"owner": map[string]any{
+				"user_id":      42,
+				"display_name": "Tester &amp; Co",
+				"link":         "https://stackoverflow.com/users/42/tester",
+			},
+		}
+		if p.params.Get("filter") == "withbody" {
+			items[j]["body"] = fmt.Sprintf("<p>How do I fix %s &amp; why?</p>\n<pre><code>go run .\n</code></pre>", query)
+		}
+	}
+	return map[string]any{"items": items, "has_more": p.more(), "quota_max": 300, "quota_remaining": 299}
+}
+
+func redditBody(query string, p page) any {
+	count := p.count
+	children := make([]map[string]any, count)
+	for j := range children {
+		i := p.offset + j
+		children[j] = map[string]any{
+			"kind": "t3",
+			"data": map[string]any{
+				"id":           fmt.Sprintf("fake%d", i+1),
+				"title":        fmt.Sprintf("Post %d about %s", i+1, query),
+				"selftext":     fmt.Sprintf("Discussion %d of %s", i+1, query),
+				"author":       "tester",
+				"subreddit":    "golang",
+				"score":        10 * (count - j),
+				"num_comments": i,
+				"created_utc":  float64(created.Unix()),
+				"edited":       false,
+				"permalink":    fmt.Sprintf("/r/golang/comments/fake%d/", i+1),
+				"upvote_ratio": 0.9,
+				"thumbnail":    "self",
+			},
+		}
+	}
+	after := ""
+	if p.more() {
+		after = fmt.Sprintf("t3_fake%d", p.offset+count)
+	}
+	return map[string]any{"kind": "Listing", "data": map[string]any{"after": after, "children": children}}
+}
+
+func hackerNewsBody(query string, p page) any {
+	count := p.count
+	hits := make([]map[string]any, count)
+	for j := range hits {
+		i := p.offset + j
+		hit := map[string]any{
+			"objectID":     fmt.Sprintf("%d", 40000000+i),
+			"title":        fmt.Sprintf("Story %d about %s", i+1, query),
+			"url":          fmt.Sprintf("https://example.com/story-%d", i+1),
+			"author":       "tester",
+			"points":       50 * (count - j),
+			"num_comments": 5 * i,
+			"created_at_i": created.Unix(),
+		}
+		// Every other story is a text post without a link
+		if i%2 == 1 {
+			hit["url"] = nil
+			hit["story_text"] = fmt.Sprintf("<p>Ask HN %d: %s?</p>", i+1, query)
+		}
+		hits[j] = hit
+	}
+	pages := (p.total + p.size - 1) / p.size
+	return map[string]any{"hits": hits, "nbHits": p.total, "hitsPerPage": p.size, "nbPages": pages}
+}
+
+func dockerHubBody(query string, p page) any {
+	results := make([]map[string]any, p.count)
+	for j := range results {
+		i := p.offset + j
+		repo := map[string]any{
+			"repo_name":         fmt.Sprintf("fake/image-%d", i+1),
+			"short_description": fmt.Sprintf("Image %d for %s", i+1, query),
+			"star_count":        10 * (p.count - j),
+			"pull_count":        int64(1_000_000_000) * int64(p.count-j),
+			"is_official":       false,
+			"date_registered":   created.Format(time.RFC3339),
+			"last_updated":      created.Add(time.Duration(i) * time.Hour).Format(time.RFC3339),
+		}
+		// The first image is official, the second from a verified publisher
+		switch i {
+		case 0:
+			repo["repo_name"] = query
+			repo["is_official"] = true
+		case 1:
+			repo["badge"] = "verified_publisher"
+		}
+		results[j] = repo
+	}
+	next := ""
+	if p.more() {
+		next = fmt.Sprintf("/v2/search/repositories/?page=%d", (p.offset+p.count)/p.size+1)
+	}
+	return map[string]any{"count": p.total, "next": next, "results": results}
+}
+
+func devToBody(query string, p page) any {
+	articles := make([]map[string]any, p.count)
+	for j := range articles {
+		i := p.offset + j
+		article := map[string]any{
+			"id":                     3000000 + i + 1,
+			"title":                  fmt.Sprintf("Article %d: %s", i+1, query),
+			"description":            fmt.Sprintf("A tutorial about %s", query),
+			"url":                    fmt.Sprintf("https://dev.to/tester/article-%d", i+1),
+			"cover_image":            fmt.Sprintf("https://media.dev.to/cover-%d.png", i+1),
+			"comments_count":         i,
+			"public_reactions_count": 50 * (p.count - j),
+			"reading_time_minutes":   5,
+			"tag_list":               []string{"go", "tutorial"},
+			"published_at":           created.Format(time.RFC3339),
+			"edited_at":              nil,
+			"user":                   map[string]any{"name": "Test Writer", "username": "tester"},
+		}
+		// The second article was edited and has no cover
+		if i == 1 {
+			article["edited_at"] = created.Add(time.Hour).Format(time.RFC3339)
+			article["cover_image"] = nil
+		}
+		articles[j] = article
+	}
+	return articles
+}
+
+func githubCodeBody(query string, p page) any {
+	items := make([]map[string]any, p.count)
+	for j := range items {
+		i := p.offset + j
+		path := fmt.Sprintf("internal/file%d.go", i+1)
+		items[j] = map[string]any{
+			"name":     fmt.Sprintf("file%d.go", i+1),
+			"path":     path,
+			"sha":      fmt.Sprintf("%040d", i+1),
+			"html_url": fmt.Sprintf("https://github.com/fake/repo-%d/blob/main/%s", i+1, path),
+			"repository": map[string]any{
+				"full_name":   fmt.Sprintf("fake/repo-%d", i+1),
+				"description": fmt.Sprintf("Repository %d about %s", i+1, query),
+				"owner": map[string]any{
+					"login":      "fake",
+					"html_url":   "https://github.com/fake",
+					"avatar_url": "https://avatars.githubusercontent.com/u/1",
+				},
+			},
+			"text_matches": []map[string]any{
+				{"property": "content", "fragment": fmt.Sprintf("func %s() {\n\treturn\n}", query)},
+			},
+		}
+	}
+	return map[string]any{"total_count": p.total, "incomplete_results": false, "items": items}
+}
+
+func githubIssuesBody(query string, p page) any {
+	items := make([]map[string]any, p.count)
+	for j := range items {
+		i := p.offset + j
+		items[j] = map[string]any{
+			"number":         i + 1,
+			"title":          fmt.Sprintf("Issue %d about %s", i+1, query),
+			"html_url":       fmt.Sprintf("https://github.com/fake/repo/issues/%d", i+1),
+			"body":           fmt.Sprintf("Steps to reproduce **%s**", query),
+			"state":          "open",
+			"comments":       i,
+			"repository_url": "https://api.github.com/repos/fake/repo",
+			"created_at":     created.Format(time.RFC3339),
+			"updated_at":     created.Format(time.RFC3339),
+			"labels":         []map[string]any{{"name": "bug"}},
+			"user": map[string]any{
+				"login":      "tester",
+				"html_url":   "https://github.com/tester",
+				"avatar_url": "https://avatars.githubusercontent.com/u/2",
+			},
+		}
+	}
+	return map[string]any{"total_count": p.total, "incomplete_results": false, "items": items}
+}
+
+func githubDiscussionsBody(query string, p page) any {
+	nodes := make([]map[string]any, p.count)
+	for j := range nodes {
+		i := p.offset + j
+		nodes[j] = map[string]any{
+			"number":      i + 1,
+			"title":       fmt.Sprintf("Discussion %d about %s", i+1, query),
+			"url":         fmt.Sprintf("https://github.com/fake/repo/discussions/%d", i+1),
+			"bodyText":    fmt.Sprintf("How do you use %s?", query),
+			"createdAt":   created.Format(time.RFC3339),
+			"updatedAt":   created.Format(time.RFC3339),
+			"upvoteCount": p.count - j,
+			"isAnswered":  i%2 == 0,
+			"comments":    map[string]any{"totalCount": i},
+			"category":    map[string]any{"name": "Q&A"},
+			"repository":  map[string]any{"nameWithOwner": "fake/repo"},
+			"author": map[string]any{
+				"login":     "tester",
+				"url":       "https://github.com/tester",
+				"avatarUrl": "https://avatars.githubusercontent.com/u/2",
+			},
+		}
+	}
+	return map[string]any{"data": map[string]any{"search": map[string]any{
+		"pageInfo": map[string]any{"hasNextPage": p.more(), "endCursor": fmt.Sprintf("%d", p.offset+p.count)},
+		"nodes":    nodes,
+	}}}
+}
@@ -0,0 +1,23 @@
+package fetchers
+
+import "strings"
+
+// aliases maps the short names clients commonly use to platform names
+var aliases = map[string]string{
+	"gh":     "github",
+	"so":     "stackoverflow",
+	"hn":     "hackernews",
+	"docker": "dockerhub",
+	"dev.to": "devto",
+	"forem":  "devto",
+}
+
+// CanonicalName returns the platform name for a name or alias given in a
+// request. Names are case-insensitive; unknown names are returned lowercased.
+func CanonicalName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if canonical, ok := aliases[name]; ok {
+		return canonical
+	}
+	return name
+}
@@ -0,0 +1,47 @@
+package fetchers
+
+import "sort"
+
+// commonFilters are the SearchRequest filters that apply to every platform.
+// Filters are named after their SearchRequest field, or after the source
+// rule prefix for allow_sources and deny_sources.
+var commonFilters = []string{"accept_language", "author:", "domain:", "filter", "since", "until"}
+
+// platformFilters are the filters only some built-in platforms honour
+var platformFilters = map[string][]string{
+	"github":        {"content_type", "min_stars", "org:"},
+	"stackoverflow": {"answered_only", "locale"},
+	"reddit":        {"min_upvotes", "subreddit:"},
+	// Other Stack Exchange sites have no localized editions
+	"serverfault": {"answered_only"},
+	"superuser":   {"answered_only"},
+	"dba":         {"answered_only"},
+	"askubuntu":   {"answered_only"},
+}
+
+// queryTerms are the query DSL fields each built-in platform translates
+var queryTerms = map[string][]string{
+	"github":        {"after", "before", "lang", "stars", "tag"},
+	"stackoverflow": {"after", "before", "lang", "tag"},
+	"reddit":        {"after", "before", "subreddit"},
+	"hackernews":    {"after", "before"},
+	"devto":         {"after", "lang", "tag"},
+	"serverfault":   {"after", "before", "lang", "tag"},
+	"superuser":     {"after", "before", "lang", "tag"},
+	"dba":           {"after", "before", "lang", "tag"},
+	"askubuntu":     {"after", "before", "lang", "tag"},
+}
+
+// QueryTermsFor returns the query DSL fields a platform translates, in name
+// order
+func QueryTermsFor(name string) []string {
+	return append([]string(nil), queryTerms[name]...)
+}
+
+// FiltersFor returns the filters a platform honours, in name order. Filters
+// it doesn't list leave its results alone.
+func FiltersFor(name string) []string {
+	filters := append(append([]string(nil), commonFilters...), platformFilters[name]...)
+	sort.Strings(filters)
+	return filters
+}
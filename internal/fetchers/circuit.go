@@ -0,0 +1,249 @@
+package fetchers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/farhapartex/search-proxy/internal/models"
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+// CircuitState is where a circuit breaker stands
+type CircuitState int
+
+const (
+	// CircuitClosed lets every call through
+	CircuitClosed CircuitState = iota
+	// CircuitOpen fails calls at once until the open timeout passes
+	CircuitOpen
+	// CircuitHalfOpen lets a single probe through to see if the platform
+	// has recovered
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "closed"
+}
+
+// CircuitOpenError is returned without calling a platform whose breaker is
+// open
+type CircuitOpenError struct {
+	Platform string
+
+	// RetryAfter is how long until the breaker lets a probe through
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%s circuit breaker is open", e.Platform)
+}
+
+// CircuitBreaker stops calling a platform after threshold consecutive
+// failures, so a dead upstream costs searches nothing instead of a timeout.
+// After timeout one probe is let through: success closes the breaker again,
+// failure keeps it open for another timeout. Failures are the ones worth a
+// retry, network errors and 5xx answers; refused requests don't count.
+type CircuitBreaker struct {
+	platform  string
+	threshold int
+	timeout   time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// NewCircuitBreaker creates a closed breaker for platform
+func NewCircuitBreaker(platform string, threshold int, timeout time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		platform:  platform,
+		threshold: threshold,
+		timeout:   timeout,
+		now:       time.Now,
+	}
+}
+
+// State returns the breaker's current state
+func (b *CircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state()
+}
+
+func (b *CircuitBreaker) state() CircuitState {
+	switch {
+	case b.failures < b.threshold:
+		return CircuitClosed
+	case b.now().Sub(b.openedAt) < b.timeout:
+		return CircuitOpen
+	}
+	return CircuitHalfOpen
+}
+
+// allow reports whether a call may go ahead, and whether it is the probe of
+// a half-open breaker
+func (b *CircuitBreaker) allow() (probe bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state() {
+	case CircuitClosed:
+		return false, nil
+	case CircuitOpen:
+		return false, &CircuitOpenError{Platform: b.platform, RetryAfter: b.timeout - b.now().Sub(b.openedAt)}
+	}
+	if b.probing {
+		return false, &CircuitOpenError{Platform: b.platform}
+	}
+	b.probing = true
+	return true, nil
+}
+
+// Reset closes the breaker, forgetting the failures it has counted
+func (b *CircuitBreaker) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures >= b.threshold {
+		log.Printf("Circuit breaker for %s reset", b.platform)
+	}
+	b.failures = 0
+	b.probing = false
+}
+
+// record counts the outcome of a call let through by allow
+func (b *CircuitBreaker) record(err error, probe bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if probe {
+		b.probing = false
+	}
+	switch {
+	case err == nil:
+		if b.failures >= b.threshold {
+			log.Printf("Circuit breaker for %s closed", b.platform)
+		}
+		b.failures = 0
+	case retryable(err):
+		b.failures++
+		if b.failures >= b.threshold {
+			if b.failures == b.threshold || probe {
+				log.Printf("WARNING: Circuit breaker for %s opened after %d consecutive failures", b.platform, b.failures)
+			}
+			b.openedAt = b.now()
+		}
+	}
+}
+
+// CircuitBreakers holds one breaker per platform. Breakers outlive the
+// fetchers they guard, so a platform re-registered at runtime keeps its
+// state.
+type CircuitBreakers struct {
+	threshold int
+	timeout   time.Duration
+
+	mu       sync.Mutex
+	breakers map[string]*CircuitBreaker
+}
+
+// NewCircuitBreakers creates breakers that open after threshold consecutive
+// failures and probe again after timeout
+func NewCircuitBreakers(threshold int, timeout time.Duration) *CircuitBreakers {
+	return &CircuitBreakers{
+		threshold: threshold,
+		timeout:   timeout,
+		breakers:  make(map[string]*CircuitBreaker),
+	}
+}
+
+// For returns the breaker of platform, creating it closed if needed
+func (c *CircuitBreakers) For(platform string) *CircuitBreaker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	breaker, ok := c.breakers[platform]
+	if !ok {
+		breaker = NewCircuitBreaker(platform, c.threshold, c.timeout)
+		c.breakers[platform] = breaker
+	}
+	return breaker
+}
+
+// Wrap is a Middleware guarding each fetcher with its platform's breaker
+func (c *CircuitBreakers) Wrap(fetcher Fetcher) Fetcher {
+	return NewCircuitBreakerFetcher(fetcher, c.For(fetcher.Name()))
+}
+
+// CircuitBreakerFetcher calls the wrapped fetcher only while its breaker
+// allows it
+type CircuitBreakerFetcher struct {
+	next    Fetcher
+	breaker *CircuitBreaker
+}
+
+// NewCircuitBreakerFetcher wraps next with breaker
+func NewCircuitBreakerFetcher(next Fetcher, breaker *CircuitBreaker) *CircuitBreakerFetcher {
+	return &CircuitBreakerFetcher{
+		next:    next,
+		breaker: breaker,
+	}
+}
+
+// Name returns the platform name
+func (c *CircuitBreakerFetcher) Name() string {
+	return c.next.Name()
+}
+
+// Unwrap returns the wrapped fetcher
+func (c *CircuitBreakerFetcher) Unwrap() Fetcher {
+	return c.next
+}
+
+// Fetch retrieves search results unless the breaker is open
+func (c *CircuitBreakerFetcher) Fetch(ctx context.Context, query string, maxResults int) ([]*models.SearchResult, error) {
+	probe, err := c.breaker.allow()
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := c.next.Fetch(ctx, query, maxResults)
+	c.breaker.record(err, probe)
+	return results, err
+}
+
+// FetchProto is like Fetch but returns protobuf results
+func (c *CircuitBreakerFetcher) FetchProto(ctx context.Context, query string, maxResults int) ([]*pb.Result, error) {
+	probe, err := c.breaker.allow()
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := FetchProto(ctx, c.next, query, maxResults)
+	c.breaker.record(err, probe)
+	return results, err
+}
+
+// FetchDetails fetches a result's details unless the breaker is open; as
+// with searches, network errors and 5xx answers count against it
+func (c *CircuitBreakerFetcher) FetchDetails(ctx context.Context, resultURL string, maxReplies int) (*pb.ResultDetailsResponse, error) {
+	probe, err := c.breaker.allow()
+	if err != nil {
+		return nil, err
+	}
+
+	details, err := FetchDetails(ctx, c.next, resultURL, maxReplies)
+	c.breaker.record(err, probe)
+	return details, err
+}
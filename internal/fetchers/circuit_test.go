@@ -0,0 +1,93 @@
+package fetchers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerOpensAndProbes(t *testing.T) {
+	now := time.Now()
+	breakers := NewCircuitBreakers(2, time.Minute)
+	breaker := breakers.For("flaky")
+	breaker.now = func() time.Time { return now }
+
+	serverError := &StatusError{Platform: "flaky", StatusCode: http.StatusBadGateway}
+	flaky := &flakyFetcher{errs: []error{serverError, serverError, serverError}}
+	fetcher := breakers.Wrap(flaky)
+
+	for range 2 {
+		if _, err := fetcher.Fetch(context.Background(), "go", 5); !errors.Is(err, serverError) {
+			t.Fatalf("Fetch() error = %v, want the upstream's", err)
+		}
+	}
+	if state := breaker.State(); state != CircuitOpen {
+		t.Fatalf("state after two failures = %v, want open", state)
+	}
+
+	var open *CircuitOpenError
+	if _, err := fetcher.Fetch(context.Background(), "go", 5); !errors.As(err, &open) || open.RetryAfter != time.Minute {
+		t.Fatalf("Fetch() on an open breaker error = %v, want CircuitOpenError retrying after a minute", err)
+	}
+	if flaky.calls != 2 {
+		t.Errorf("calls = %d, want the open breaker to skip the upstream", flaky.calls)
+	}
+
+	// A failed probe opens the breaker for another timeout
+	now = now.Add(time.Minute)
+	if state := breaker.State(); state != CircuitHalfOpen {
+		t.Fatalf("state after the timeout = %v, want half-open", state)
+	}
+	if _, err := fetcher.Fetch(context.Background(), "go", 5); !errors.Is(err, serverError) {
+		t.Fatalf("probe error = %v, want the upstream's", err)
+	}
+	if state := breaker.State(); state != CircuitOpen {
+		t.Fatalf("state after a failed probe = %v, want open", state)
+	}
+
+	// A successful probe closes it
+	now = now.Add(time.Minute)
+	if results, err := fetcher.Fetch(context.Background(), "go", 5); err != nil || len(results) != 1 {
+		t.Fatalf("probe = %d results, %v; want the upstream's result", len(results), err)
+	}
+	if state := breaker.State(); state != CircuitClosed {
+		t.Errorf("state after a successful probe = %v, want closed", state)
+	}
+}
+
+func TestCircuitBreakerIgnoresRefusedRequests(t *testing.T) {
+	breakers := NewCircuitBreakers(1, time.Minute)
+	fetcher := breakers.Wrap(&flakyFetcher{errs: []error{
+		&StatusError{Platform: "flaky", StatusCode: http.StatusUnprocessableEntity},
+		context.Canceled,
+	}})
+
+	for range 2 {
+		if _, err := fetcher.Fetch(context.Background(), "go", 5); err == nil {
+			t.Fatal("Fetch() succeeded, want the queued error")
+		}
+	}
+	if state := breakers.For("flaky").State(); state != CircuitClosed {
+		t.Errorf("state = %v, want closed: neither error is the upstream failing", state)
+	}
+}
+
+func TestCircuitBreakerReset(t *testing.T) {
+	breakers := NewCircuitBreakers(1, time.Minute)
+	flaky := &flakyFetcher{errs: []error{&StatusError{Platform: "flaky", StatusCode: http.StatusBadGateway}}}
+	fetcher := breakers.Wrap(flaky)
+
+	if _, err := fetcher.Fetch(context.Background(), "go", 5); err == nil {
+		t.Fatal("Fetch() succeeded, want the upstream's error")
+	}
+	if state := breakers.For("flaky").State(); state != CircuitOpen {
+		t.Fatalf("state = %v, want open", state)
+	}
+
+	breakers.For("flaky").Reset()
+	if results, err := fetcher.Fetch(context.Background(), "go", 5); err != nil || len(results) != 1 {
+		t.Errorf("Fetch() after Reset() = %d results, %v; want the upstream called again", len(results), err)
+	}
+}
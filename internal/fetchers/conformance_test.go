@@ -0,0 +1,77 @@
+package fetchers_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/farhapartex/search-proxy/internal/fetchers"
+	"github.com/farhapartex/search-proxy/internal/fetchers/fetchertest"
+	"github.com/farhapartex/search-proxy/internal/testutil"
+)
+
+var (
+	healthy = testutil.Behavior{Results: 10}
+	hanging = testutil.Behavior{Latency: time.Hour}
+	failing = testutil.Behavior{StatusCode: http.StatusBadGateway}
+)
+
+// harness builds a Harness whose fetchers talk to fake upstreams
+func harness(newUpstream func(testing.TB) *testutil.Upstream, build func(*testutil.Upstream) fetchers.Fetcher) fetchertest.Harness {
+	with := func(b testutil.Behavior) func(t *testing.T) fetchers.Fetcher {
+		return func(t *testing.T) fetchers.Fetcher {
+			upstream := newUpstream(t)
+			upstream.Set(b)
+			return build(upstream)
+		}
+	}
+	return fetchertest.Harness{Healthy: with(healthy), Hanging: with(hanging), Failing: with(failing)}
+}
+
+func TestGitHubFetcherConformance(t *testing.T) {
+	fetchertest.Run(t, harness(testutil.NewGitHub, func(u *testutil.Upstream) fetchers.Fetcher {
+		return fetchers.NewGitHubFetcher(nil, fetchers.NewEndpointPool(u.URL(), fetchers.SelectPriority), http.DefaultClient)
+	}))
+}
+
+func TestStackOverflowFetcherConformance(t *testing.T) {
+	fetchertest.Run(t, harness(testutil.NewStackOverflow, func(u *testutil.Upstream) fetchers.Fetcher {
+		return fetchers.NewStackOverflowFetcher(nil, fetchers.NewEndpointPool(u.URL(), fetchers.SelectPriority), true, http.DefaultClient)
+	}))
+}
+
+func TestRedditFetcherConformance(t *testing.T) {
+	fetchertest.Run(t, harness(testutil.NewReddit, func(u *testutil.Upstream) fetchers.Fetcher {
+		return fetchers.NewRedditFetcher("", "", "test-agent/1.0", u.URL(), "", u.Client())
+	}))
+}
+
+func TestHackerNewsFetcherConformance(t *testing.T) {
+	fetchertest.Run(t, harness(testutil.NewHackerNews, func(u *testutil.Upstream) fetchers.Fetcher {
+		return fetchers.NewHackerNewsFetcher(fetchers.NewEndpointPool(u.URL(), fetchers.SelectPriority), http.DefaultClient)
+	}))
+}
+
+func TestDockerHubFetcherConformance(t *testing.T) {
+	fetchertest.Run(t, harness(testutil.NewDockerHub, func(u *testutil.Upstream) fetchers.Fetcher {
+		return fetchers.NewDockerHubFetcher(fetchers.NewEndpointPool(u.URL(), fetchers.SelectPriority), http.DefaultClient)
+	}))
+}
+
+func TestDevToFetcherConformance(t *testing.T) {
+	fetchertest.Run(t, harness(testutil.NewDevTo, func(u *testutil.Upstream) fetchers.Fetcher {
+		return fetchers.NewDevToFetcher(fetchers.NewEndpointPool(u.URL(), fetchers.SelectPriority), http.DefaultClient)
+	}))
+}
+
+func TestMockFetcherConformance(t *testing.T) {
+	fetchertest.Run(t, fetchertest.Harness{
+		Healthy: func(t *testing.T) fetchers.Fetcher {
+			f, err := fetchers.NewMockFetcher("github", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			return f
+		},
+	})
+}
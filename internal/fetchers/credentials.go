@@ -0,0 +1,210 @@
+package fetchers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// credentialCooldown is how long a rejected credential is moved to the back
+// of the selection order
+const credentialCooldown = time.Minute
+
+// StatusError is a non-200 answer from a platform API
+type StatusError struct {
+	Platform   string
+	StatusCode int
+	Body       string
+
+	// RetryAfter is how long the upstream asked callers to back off, or
+	// zero when it didn't say
+	RetryAfter time.Duration
+
+	// CredentialRejected is set when the upstream refused the credential
+	// used (invalid, or out of quota), so another one may still succeed
+	CredentialRejected bool
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s API error: status=%d, body=%s", e.Platform, e.StatusCode, e.Body)
+}
+
+// retryAfter reads a Retry-After header, given either in seconds or as an
+// HTTP date
+func retryAfter(header http.Header) time.Duration {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0)
+	}
+	return 0
+}
+
+// CredentialPool holds the API keys or tokens a platform can be called with.
+// When the upstream rejects one, the call is retried once with the next, or
+// without credentials if anonymous access is allowed as a last resort.
+type CredentialPool struct {
+	mu          sync.Mutex
+	credentials []*credential
+	anonymous   bool
+}
+
+type credential struct {
+	value         string
+	priority      int
+	cooldownUntil time.Time
+}
+
+// NewCredentialPool creates a pool from a comma-separated list of
+// credentials. anonymousFallback allows a final unauthenticated attempt.
+func NewCredentialPool(values string, anonymousFallback bool) *CredentialPool {
+	return &CredentialPool{anonymous: anonymousFallback, credentials: parseCredentials(values)}
+}
+
+// Replace swaps in a new comma-separated list of credentials, for rotation
+// without a restart. Searches already running finish with the old ones.
+func (p *CredentialPool) Replace(values string) {
+	credentials := parseCredentials(values)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.credentials = credentials
+}
+
+// Len returns the number of credentials in the pool
+func (p *CredentialPool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.credentials)
+}
+
+func parseCredentials(values string) []*credential {
+	var credentials []*credential
+	for _, value := range strings.Split(values, ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		credentials = append(credentials, &credential{
+			value:    value,
+			priority: len(credentials),
+		})
+	}
+	return credentials
+}
+
+// CredentialValidator is implemented by fetchers that can check a credential
+// with their upstream before it is used for searches
+type CredentialValidator interface {
+	ValidateCredential(ctx context.Context, credential string) error
+}
+
+// ErrCannotValidate is returned for fetchers that can't check credentials
+var ErrCannotValidate = errors.New("platform cannot validate credentials")
+
+// ValidateCredential checks credential with f's upstream, looking through
+// middleware
+func ValidateCredential(ctx context.Context, f Fetcher, credential string) error {
+	validator, ok := findInterface[CredentialValidator](f)
+	if !ok {
+		return ErrCannotValidate
+	}
+	return validator.ValidateCredential(ctx, credential)
+}
+
+// checkCredential sends req and reports a non-200 answer as a StatusError;
+// rejected tells whether the answer blames the credential
+func checkCredential(client *http.Client, req *http.Request, platform string, rejected func(statusCode int, body []byte) bool) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+		return &StatusError{
+			Platform:           platform,
+			StatusCode:         resp.StatusCode,
+			Body:               string(body),
+			CredentialRejected: rejected(resp.StatusCode, body),
+		}
+	}
+	return nil
+}
+
+// Try calls fn with the preferred credential, an empty string meaning
+// anonymous. If the upstream rejects it and time remains, fn is retried once
+// with the next alternative. A nil pool always calls anonymously.
+func (p *CredentialPool) Try(ctx context.Context, fn func(credential string) error) error {
+	order := p.order()
+
+	err := p.call(order[0], fn)
+	if !isCredentialRejected(err) || len(order) < 2 || ctx.Err() != nil {
+		return err
+	}
+
+	if retryErr := p.call(order[1], fn); retryErr != nil {
+		return errors.Join(err, retryErr)
+	}
+	return nil
+}
+
+// call runs fn with c and records whether the upstream accepted it
+func (p *CredentialPool) call(c *credential, fn func(credential string) error) error {
+	err := fn(c.value)
+	if p == nil || c.value == "" || (err != nil && !isCredentialRejected(err)) {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err != nil {
+		c.cooldownUntil = time.Now().Add(credentialCooldown)
+	} else {
+		c.cooldownUntil = time.Time{}
+	}
+	return err
+}
+
+// order returns the credentials in the order they should be tried, ending
+// with anonymous access when allowed
+func (p *CredentialPool) order() []*credential {
+	if p == nil {
+		return []*credential{{}}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	order := append([]*credential(nil), p.credentials...)
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := order[i], order[j]
+		aCooling, bCooling := now.Before(a.cooldownUntil), now.Before(b.cooldownUntil)
+		if aCooling != bCooling {
+			return !aCooling
+		}
+		return a.priority < b.priority
+	})
+	if p.anonymous || len(order) == 0 {
+		order = append(order, &credential{})
+	}
+	return order
+}
+
+func isCredentialRejected(err error) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && statusErr.CredentialRejected
+}
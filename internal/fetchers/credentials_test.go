@@ -0,0 +1,199 @@
+package fetchers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+func rejectedBy(bad ...string) (func(string) error, *[]string) {
+	var used []string
+	return func(credential string) error {
+		used = append(used, credential)
+		for _, b := range bad {
+			if credential == b {
+				return &StatusError{Platform: "Test", StatusCode: http.StatusTooManyRequests, CredentialRejected: true}
+			}
+		}
+		return nil
+	}, &used
+}
+
+func TestCredentialPoolFailsOverToNextCredential(t *testing.T) {
+	pool := NewCredentialPool("first, second", false)
+	fn, used := rejectedBy("first")
+
+	if err := pool.Try(context.Background(), fn); err != nil {
+		t.Fatalf("Try() error = %v", err)
+	}
+	if want := []string{"first", "second"}; !slices.Equal(*used, want) {
+		t.Errorf("credentials used = %q, want %q", *used, want)
+	}
+
+	// The rejected credential cools down, so the next call leads with the other
+	*used = nil
+	if err := pool.Try(context.Background(), fn); err != nil {
+		t.Fatalf("Try() error = %v", err)
+	}
+	if want := []string{"second"}; !slices.Equal(*used, want) {
+		t.Errorf("credentials used after cooldown = %q, want %q", *used, want)
+	}
+}
+
+func TestCredentialPoolFallsBackToAnonymous(t *testing.T) {
+	fn, used := rejectedBy("only")
+
+	if err := NewCredentialPool("only", true).Try(context.Background(), fn); err != nil {
+		t.Fatalf("Try() error = %v", err)
+	}
+	if want := []string{"only", ""}; !slices.Equal(*used, want) {
+		t.Errorf("credentials used = %q, want %q", *used, want)
+	}
+
+	*used = nil
+	if err := NewCredentialPool("only", false).Try(context.Background(), fn); !isCredentialRejected(err) {
+		t.Errorf("Try() without fallback error = %v, want the rejection", err)
+	}
+	if want := []string{"only"}; !slices.Equal(*used, want) {
+		t.Errorf("credentials used without fallback = %q, want %q", *used, want)
+	}
+}
+
+func TestCredentialPoolRetriesOnlyOnce(t *testing.T) {
+	pool := NewCredentialPool("a,b,c", true)
+	fn, used := rejectedBy("a", "b", "c")
+
+	err := pool.Try(context.Background(), fn)
+	if !isCredentialRejected(err) {
+		t.Fatalf("Try() error = %v, want the rejection", err)
+	}
+	if len(*used) != 2 {
+		t.Errorf("credentials used = %q, want exactly one retry", *used)
+	}
+}
+
+func TestCredentialPoolKeepsOtherErrors(t *testing.T) {
+	upstreamDown := &StatusError{Platform: "Test", StatusCode: http.StatusBadGateway}
+	calls := 0
+	err := NewCredentialPool("a,b", true).Try(context.Background(), func(string) error {
+		calls++
+		return upstreamDown
+	})
+
+	if !errors.Is(err, upstreamDown) || calls != 1 {
+		t.Errorf("Try() = %v after %d calls, want the 502 without a retry", err, calls)
+	}
+}
+
+func TestCredentialPoolSkipsRetryWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	err := NewCredentialPool("a,b", false).Try(ctx, func(string) error {
+		calls++
+		cancel()
+		return &StatusError{Platform: "Test", StatusCode: http.StatusUnauthorized, CredentialRejected: true}
+	})
+
+	if err == nil || calls != 1 {
+		t.Errorf("Try() = %v after %d calls, want no retry once the budget is spent", err, calls)
+	}
+}
+
+func TestStackOverflowFetcherRotatesRejectedKey(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("key") == "revoked" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error_id":400,"error_message":"key is invalid","error_name":"bad_parameter"}`))
+			return
+		}
+		w.Write([]byte(`{"items":[{"question_id":1,"title":"Go","link":"https://stackoverflow.com/q/1"}]}`))
+	}))
+	defer upstream.Close()
+
+	keys := NewCredentialPool("revoked,valid", false)
+	fetcher := NewStackOverflowFetcher(keys, NewEndpointPool(upstream.URL, SelectPriority), true, upstream.Client())
+
+	results, err := fetcher.Fetch(context.Background(), "go", 5)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if len(results) != 1 {
+		t.Errorf("len(results) = %d, want 1", len(results))
+	}
+}
+
+func TestStackOverflowKeyRejected(t *testing.T) {
+	tests := []struct {
+		status int
+		body   string
+		want   bool
+	}{
+		{http.StatusBadRequest, `{"error_id":502,"error_name":"throttle_violation"}`, true},
+		{http.StatusBadRequest, `{"error_id":400,"error_message":"key is invalid"}`, true},
+		{http.StatusBadRequest, `{"error_id":400,"error_message":"sort is invalid"}`, false},
+		{http.StatusTooManyRequests, ``, true},
+		{http.StatusServiceUnavailable, `<html>`, false},
+	}
+	for _, tt := range tests {
+		if got := stackOverflowKeyRejected(tt.status, []byte(tt.body)); got != tt.want {
+			t.Errorf("stackOverflowKeyRejected(%d, %s) = %v, want %v", tt.status, tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	for value, want := range map[string]time.Duration{
+		"":     0,
+		"120":  2 * time.Minute,
+		"soon": 0,
+		"-5":   0,
+		time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat): 0,
+	} {
+		header := http.Header{}
+		if value != "" {
+			header.Set("Retry-After", value)
+		}
+		if got := retryAfter(header); got != want {
+			t.Errorf("retryAfter(%q) = %v, want %v", value, got, want)
+		}
+	}
+}
+
+func TestCredentialPoolReplace(t *testing.T) {
+	pool := NewCredentialPool("old", false)
+	pool.Replace("new1, new2")
+
+	fn, used := rejectedBy()
+	if err := pool.Try(context.Background(), fn); err != nil {
+		t.Fatalf("Try() error = %v", err)
+	}
+	if want := []string{"new1"}; !slices.Equal(*used, want) {
+		t.Errorf("credentials used after Replace = %q, want %q", *used, want)
+	}
+}
+
+func TestGitHubFetcherValidatesCredential(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rate_limit" || r.Header.Get("Authorization") != "Bearer good" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"message":"Bad credentials"}`))
+			return
+		}
+		w.Write([]byte(`{"resources":{}}`))
+	}))
+	defer upstream.Close()
+
+	fetcher := NewGitHubFetcher(nil, NewEndpointPool(upstream.URL, SelectPriority), upstream.Client())
+
+	if err := ValidateCredential(context.Background(), fetcher, "good"); err != nil {
+		t.Errorf("ValidateCredential(good) error = %v", err)
+	}
+	var statusErr *StatusError
+	if err := ValidateCredential(context.Background(), fetcher, "revoked"); !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("ValidateCredential(revoked) error = %v, want a 401", err)
+	}
+}
@@ -0,0 +1,73 @@
+package fetchers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+// maxDetailsBytes caps how much of an upstream answer is read when fetching
+// a result's details; READMEs in particular can be large
+const maxDetailsBytes = 4 << 20
+
+// DetailsFetcher is implemented by fetchers that can retrieve the full
+// content behind one of their results, for clients that need more than the
+// snippet
+type DetailsFetcher interface {
+	// FetchDetails returns the content at resultURL, a Result.url this
+	// fetcher returned, with at most maxReplies answers or comments
+	FetchDetails(ctx context.Context, resultURL string, maxReplies int) (*pb.ResultDetailsResponse, error)
+}
+
+// ErrDetailsUnsupported is returned for fetchers that can't fetch details
+var ErrDetailsUnsupported = errors.New("platform cannot fetch result details")
+
+// ErrUnrecognizedURL is returned for a URL that doesn't point to a result
+// the platform can fetch details for
+var ErrUnrecognizedURL = errors.New("url does not point to a result on this platform")
+
+// FetchDetails fetches the content behind resultURL with f's upstream,
+// through the same middleware as its searches. Platforms without details
+// fail before any middleware spends budget on them.
+func FetchDetails(ctx context.Context, f Fetcher, resultURL string, maxReplies int) (*pb.ResultDetailsResponse, error) {
+	if _, ok := innermost(f).(DetailsFetcher); !ok {
+		return nil, ErrDetailsUnsupported
+	}
+	details, _ := findInterface[DetailsFetcher](f)
+	return details.FetchDetails(ctx, resultURL, maxReplies)
+}
+
+// innermost returns the fetcher at the bottom of f's middleware
+func innermost(f Fetcher) Fetcher {
+	for {
+		unwrapper, ok := f.(Unwrapper)
+		if !ok {
+			return f
+		}
+		f = unwrapper.Unwrap()
+	}
+}
+
+// resultPath parses an http(s) URL and returns its lowercased host, without
+// "www.", and its non-empty path segments
+func resultPath(resultURL string) (host string, segments []string, err error) {
+	u, err := url.Parse(resultURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", nil, ErrUnrecognizedURL
+	}
+	for segment := range strings.SplitSeq(u.Path, "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www."), segments, nil
+}
+
+// readDetails reads an upstream body up to maxDetailsBytes
+func readDetails(body io.Reader) ([]byte, error) {
+	return io.ReadAll(io.LimitReader(body, maxDetailsBytes))
+}
@@ -0,0 +1,209 @@
+package fetchers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/farhapartex/search-proxy/internal/ratelimit"
+)
+
+func TestStackOverflowFetcherFetchesDetails(t *testing.T) {
+	var sites []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sites = append(sites, r.URL.Query().Get("site"))
+		switch r.URL.Path {
+		case "/questions/123":
+			fmt.Fprint(w, `{"items": [{"question_id": 123, "title": "Why &quot;nil&quot;?",
+				"link": "https://stackoverflow.com/questions/123/why-nil", "body": "<p>Question</p>",
+				"answer_count": 3, "accepted_answer_id": 7, "creation_date": 1700000000,
+				"owner": {"user_id": 1, "display_name": "asker"}}]}`)
+		case "/questions/123/answers":
+			// The accepted answer isn't among the highest voted
+			fmt.Fprint(w, `{"items": [{"answer_id": 8, "score": 10, "body": "<p>Top</p>"},
+				{"answer_id": 9, "score": 5, "body": "<p>Next</p>"}]}`)
+		case "/answers/7":
+			fmt.Fprint(w, `{"items": [{"answer_id": 7, "score": 2, "is_accepted": true, "body": "<p>Accepted</p>",
+				"owner": {"user_id": 2, "display_name": "answerer"}}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	fetcher := NewStackOverflowFetcher(nil, NewEndpointPool(server.URL, SelectPriority), false, server.Client())
+	details, err := fetcher.FetchDetails(context.Background(), "https://stackoverflow.com/questions/123/why-nil", 2)
+	if err != nil {
+		t.Fatalf("FetchDetails() error = %v", err)
+	}
+
+	if details.Title != `Why "nil"?` || details.Content != "<p>Question</p>" || details.ContentFormat != SnippetHTML {
+		t.Errorf("details = %v, want the question's title and HTML body", details)
+	}
+	if details.Author.GetName() != "asker" || details.CreatedAt != 1700000000 {
+		t.Errorf("details = %v, want the asker and creation time", details)
+	}
+	if len(details.Replies) != 2 || !details.Replies[0].Accepted || details.Replies[1].Content != "<p>Top</p>" {
+		t.Fatalf("replies = %v, want the accepted answer then the top voted one", details.Replies)
+	}
+	if want := "https://stackoverflow.com/a/7"; details.Replies[0].Url != want {
+		t.Errorf("replies[0].Url = %q, want %q", details.Replies[0].Url, want)
+	}
+	for _, site := range sites {
+		if site != "stackoverflow.com" {
+			t.Errorf("site = %q, want the question's host", site)
+		}
+	}
+}
+
+func TestStackOverflowQuestionFor(t *testing.T) {
+	for _, tc := range []struct {
+		site, url string
+		host      string
+		id        int
+	}{
+		{"stackoverflow", "https://stackoverflow.com/questions/123/why-nil", "stackoverflow.com", 123},
+		{"stackoverflow", "https://stackoverflow.com/q/123", "stackoverflow.com", 123},
+		{"stackoverflow", "https://pt.stackoverflow.com/questions/5", "pt.stackoverflow.com", 5},
+		{"dba", "https://dba.stackexchange.com/questions/9/index", "dba.stackexchange.com", 9},
+		{"serverfault", "https://stackoverflow.com/questions/123", "", 0},
+		{"stackoverflow", "https://stackoverflow.com/users/123", "", 0},
+		{"stackoverflow", "https://stackoverflow.com/questions/tagged", "", 0},
+		{"stackoverflow", "ftp://stackoverflow.com/questions/123", "", 0},
+	} {
+		fetcher := NewStackExchangeFetcher(tc.site, nil, nil, false, http.DefaultClient)
+		host, id, err := fetcher.questionFor(tc.url)
+		if tc.id == 0 {
+			if !errors.Is(err, ErrUnrecognizedURL) {
+				t.Errorf("%s questionFor(%q) error = %v, want ErrUnrecognizedURL", tc.site, tc.url, err)
+			}
+			continue
+		}
+		if err != nil || host != tc.host || id != tc.id {
+			t.Errorf("%s questionFor(%q) = %q, %d, %v, want %q, %d", tc.site, tc.url, host, id, err, tc.host, tc.id)
+		}
+	}
+}
+
+func TestGitHubFetcherFetchesReadme(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/golang/go/readme" || r.Header.Get("Accept") != "application/vnd.github.raw+json" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, "# The Go Programming Language")
+	}))
+	defer server.Close()
+
+	fetcher := NewGitHubFetcher(nil, NewEndpointPool(server.URL, SelectPriority), server.Client())
+	details, err := fetcher.FetchDetails(context.Background(), "https://github.com/golang/go", 5)
+	if err != nil {
+		t.Fatalf("FetchDetails() error = %v", err)
+	}
+	if details.Title != "golang/go" || details.Content != "# The Go Programming Language" || details.ContentFormat != SnippetMarkdown {
+		t.Errorf("details = %v, want the README as markdown", details)
+	}
+	if details.Author.GetHandle() != "golang" {
+		t.Errorf("details.Author = %v, want the owner", details.Author)
+	}
+
+	if _, err := fetcher.FetchDetails(context.Background(), "https://github.com/golang/go/issues/1", 5); !errors.Is(err, ErrUnrecognizedURL) {
+		t.Errorf("FetchDetails(issue) error = %v, want ErrUnrecognizedURL", err)
+	}
+}
+
+func TestRedditFetcherFetchesPostAndComments(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/comments/abc.json" || r.URL.Query().Get("raw_json") != "1" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `[
+			{"kind": "Listing", "data": {"children": [{"kind": "t3", "data": {"id": "abc", "title": "Channels",
+				"selftext": "Why **channels**?", "author": "gopher", "created_utc": 1700000000,
+				"permalink": "/r/golang/comments/abc/channels/"}}]}},
+			{"kind": "Listing", "data": {"children": [
+				{"kind": "t1", "data": {"id": "c1", "body": "Because", "author": "rob", "score": 42,
+					"permalink": "/r/golang/comments/abc/channels/c1/"}},
+				{"kind": "t1", "data": {"id": "c2", "body": "[deleted]", "author": "[deleted]"}},
+				{"kind": "t1", "data": {"id": "c3", "body": "Also", "author": "ken", "score": 7}},
+				{"kind": "more", "data": {"count": 12, "children": ["c4"]}}
+			]}}
+		]`)
+	}))
+	defer server.Close()
+
+	fetcher := NewRedditFetcher("", "", "test-agent/1.0", server.URL, "", server.Client())
+	details, err := fetcher.FetchDetails(context.Background(), "https://www.reddit.com/r/golang/comments/abc/channels/", 5)
+	if err != nil {
+		t.Fatalf("FetchDetails() error = %v", err)
+	}
+	if details.Title != "Channels" || details.Content != "Why **channels**?" || details.ContentFormat != SnippetMarkdown {
+		t.Errorf("details = %v, want the post's markdown", details)
+	}
+	if len(details.Replies) != 2 || details.Replies[0].Author.GetHandle() != "rob" || details.Replies[0].Score != 42 {
+		t.Fatalf("replies = %v, want the two comments left", details.Replies)
+	}
+	if want := "https://www.reddit.com/r/golang/comments/abc/channels/c1/"; details.Replies[0].Url != want {
+		t.Errorf("replies[0].Url = %q, want %q", details.Replies[0].Url, want)
+	}
+}
+
+func TestRedditPostID(t *testing.T) {
+	for url, want := range map[string]string{
+		"https://www.reddit.com/r/golang/comments/abc/channels/": "abc",
+		"https://old.reddit.com/comments/abc":                    "abc",
+		"https://redd.it/abc":                                    "abc",
+		"https://www.reddit.com/r/golang/":                       "",
+		"https://example.com/r/golang/comments/abc/":             "",
+	} {
+		got, err := redditPostID(url)
+		if want == "" {
+			if !errors.Is(err, ErrUnrecognizedURL) {
+				t.Errorf("redditPostID(%q) error = %v, want ErrUnrecognizedURL", url, err)
+			}
+			continue
+		}
+		if got != want || err != nil {
+			t.Errorf("redditPostID(%q) = %q, %v, want %q", url, got, err, want)
+		}
+	}
+}
+
+func TestFetchDetailsGoesThroughMiddleware(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		fmt.Fprint(w, "# README")
+	}))
+	defer server.Close()
+
+	github := NewGitHubFetcher(nil, NewEndpointPool(server.URL, SelectPriority), server.Client())
+	limiter := ratelimit.NewLocalLimiter(map[string]ratelimit.Limit{"github": {Rate: 0.001, Burst: 1}})
+	fetcher := NewCircuitBreakers(1, time.Minute).Wrap(NewRateLimitedFetcher(github, limiter))
+
+	if _, err := FetchDetails(context.Background(), fetcher, "https://github.com/golang/go", 5); err != nil {
+		t.Fatalf("first FetchDetails() error = %v", err)
+	}
+	_, err := FetchDetails(context.Background(), fetcher, "https://github.com/golang/go", 5)
+	var limited *ratelimit.LimitedError
+	if !errors.As(err, &limited) {
+		t.Fatalf("second FetchDetails() error = %v, want a LimitedError", err)
+	}
+	if calls != 1 {
+		t.Errorf("upstream called %d times, want once", calls)
+	}
+
+	// Platforms without details fail before spending any of the budget
+	unsupported := NewRateLimitedFetcher(&flakyFetcher{}, ratelimit.NewLocalLimiter(map[string]ratelimit.Limit{"flaky": {Rate: 0.001, Burst: 1}}))
+	if _, err := FetchDetails(context.Background(), unsupported, "https://example.com", 5); !errors.Is(err, ErrDetailsUnsupported) {
+		t.Fatalf("FetchDetails(flaky) error = %v, want ErrDetailsUnsupported", err)
+	}
+	if _, err := unsupported.Fetch(context.Background(), "go", 5); err != nil {
+		t.Errorf("Fetch() after unsupported details error = %v, want the budget untouched", err)
+	}
+}
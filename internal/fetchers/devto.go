@@ -0,0 +1,233 @@
+package fetchers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/farhapartex/search-proxy/internal/models"
+	dsl "github.com/farhapartex/search-proxy/internal/query"
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+// devToURL is where Dev.to shows articles and profiles
+const devToURL = "https://dev.to"
+
+// DevToFetcher fetches articles from the Dev.to (Forem) article search API,
+// which needs no credentials
+type DevToFetcher struct {
+	endpoints *EndpointPool
+	client    *http.Client
+	now       func() time.Time
+}
+
+// NewDevToFetcher creates a new Dev.to fetcher
+func NewDevToFetcher(endpoints *EndpointPool, client *http.Client) *DevToFetcher {
+	return &DevToFetcher{
+		endpoints: endpoints,
+		client:    client,
+		now:       time.Now,
+	}
+}
+
+// Name returns the platform name
+func (d *DevToFetcher) Name() string {
+	return "devto"
+}
+
+// Fetch retrieves search results from Dev.to
+func (d *DevToFetcher) Fetch(ctx context.Context, query string, maxResults int) ([]*models.SearchResult, error) {
+	articles, err := d.search(ctx, query, maxResults)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*models.SearchResult, len(articles))
+	for i := range articles {
+		results[i] = articles[i].result()
+	}
+	return results, nil
+}
+
+// FetchProto retrieves search results from Dev.to as protobuf results
+func (d *DevToFetcher) FetchProto(ctx context.Context, query string, maxResults int) ([]*pb.Result, error) {
+	articles, err := d.search(ctx, query, maxResults)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*pb.Result, len(articles))
+	for i := range articles {
+		results[i] = articles[i].proto()
+	}
+	return results, nil
+}
+
+func (d *DevToFetcher) search(ctx context.Context, query string, maxResults int) ([]DevToArticle, error) {
+	var articles []DevToArticle
+	err := d.endpoints.Try(ctx, func(baseURL string) error {
+		var err error
+		articles, err = d.searchAt(ctx, baseURL, query, maxResults)
+		return err
+	})
+	return articles, err
+}
+
+// devToParams translates the DSL terms Dev.to has an equivalent for: tag
+// and lang become article tags, and after, or the request's since, becomes
+// the top period, the most reacted articles of the last that many days
+func (d *DevToFetcher) devToParams(q dsl.Query, opts RequestOptions) string {
+	var params string
+	var tags []string
+	for _, tag := range append(q.Values(dsl.Tag), q.Values(dsl.Lang)...) {
+		tags = append(tags, strings.ToLower(tag))
+	}
+	if len(tags) > 0 {
+		params += "&tags=" + url.QueryEscape(strings.Join(tags, ","))
+	}
+	if from, _ := createdRange(q, opts); !from.IsZero() {
+		days := max(int(math.Ceil(d.now().Sub(from).Hours()/24)), 1)
+		params += fmt.Sprintf("&top=%d", days)
+	}
+	return params
+}
+
+func (d *DevToFetcher) searchAt(ctx context.Context, baseURL, query string, maxResults int) ([]DevToArticle, error) {
+	q := dsl.Parse(query)
+	page := PageFrom(ctx)
+	searchURL := fmt.Sprintf("%s/articles/search?q=%s&per_page=%d&page=%d%s",
+		baseURL,
+		url.QueryEscape(q.Text),
+		maxResults,
+		page.Number(),
+		d.devToParams(q, RequestOptionsFrom(ctx)),
+	)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, searchURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.forem.api-v1+json")
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+		return nil, &StatusError{
+			Platform:   "DevTo",
+			StatusCode: resp.StatusCode,
+			Body:       string(body),
+			RetryAfter: retryAfter(resp.Header),
+		}
+	}
+
+	var articles []DevToArticle
+	if err := json.NewDecoder(resp.Body).Decode(&articles); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	// Forem doesn't say whether more follow; a full page suggests they do
+	page.setNextNumber(len(articles) == maxResults)
+	return articles, nil
+}
+
+// DevToArticle represents an article in Dev.to search results
+type DevToArticle struct {
+	ID          int    `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	URL         string `json:"url"`
+	// CoverImage is empty for articles without one
+	CoverImage           string    `json:"cover_image"`
+	CommentsCount        int       `json:"comments_count"`
+	PublicReactionsCount int       `json:"public_reactions_count"`
+	ReadingTimeMinutes   int       `json:"reading_time_minutes"`
+	TagList              []string  `json:"tag_list"`
+	PublishedAt          time.Time `json:"published_at"`
+	// EditedAt is zero for articles never edited
+	EditedAt time.Time `json:"edited_at"`
+	User     DevToUser `json:"user"`
+}
+
+// DevToUser is the author of an article
+type DevToUser struct {
+	Name     string `json:"name"`
+	Username string `json:"username"`
+}
+
+// times returns the publication and last edit times in Unix seconds
+func (a *DevToArticle) times() (created, updated int64) {
+	created = models.UnixSeconds(a.PublishedAt)
+	return created, max(models.UnixSeconds(a.EditedAt), created)
+}
+
+// snippet is the description, which Forem fills from the body when the
+// author leaves it blank, or the title
+func (a *DevToArticle) snippet() string {
+	if a.Description != "" {
+		return a.Description
+	}
+	return a.Title
+}
+
+// author is nil when the account is gone
+func (a *DevToArticle) author() *models.Author {
+	if a.User.Username == "" {
+		return nil
+	}
+	name := a.User.Name
+	if name == "" {
+		name = a.User.Username
+	}
+	return &models.Author{
+		Name:       name,
+		Handle:     a.User.Username,
+		ProfileURL: devToURL + "/" + url.PathEscape(a.User.Username),
+	}
+}
+
+func (a *DevToArticle) result() *models.SearchResult {
+	result := models.NewSearchResult("devto", a.Title, a.snippet(), a.URL)
+	result.SetTimes(a.times())
+	result.Author = a.author()
+	result.ThumbnailURL = a.CoverImage
+	result.Type = pb.ResultType_RESULT_TYPE_ARTICLE
+	result.Metadata = a.metadata()
+	return result
+}
+
+func (a *DevToArticle) proto() *pb.Result {
+	created, updated := a.times()
+	return &pb.Result{
+		Platform:     "devto",
+		Title:        a.Title,
+		Snippet:      a.snippet(),
+		Url:          a.URL,
+		Timestamp:    created,
+		CreatedAt:    created,
+		UpdatedAt:    updated,
+		ThumbnailUrl: a.CoverImage,
+		Metadata:     a.metadata(),
+		Author:       a.author().ToProto(),
+		ResultType:   pb.ResultType_RESULT_TYPE_ARTICLE,
+	}
+}
+
+func (a *DevToArticle) metadata() map[string]string {
+	return map[string]string{
+		"reactions":            fmt.Sprintf("%d", a.PublicReactionsCount),
+		"comments":             fmt.Sprintf("%d", a.CommentsCount),
+		"reading_time_minutes": fmt.Sprintf("%d", a.ReadingTimeMinutes),
+		"tags":                 strings.Join(a.TagList, ","),
+	}
+}
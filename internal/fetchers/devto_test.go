@@ -0,0 +1,73 @@
+package fetchers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/farhapartex/search-proxy/internal/testutil"
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+func newTestDevToFetcher(upstream *testutil.Upstream) *DevToFetcher {
+	return NewDevToFetcher(NewEndpointPool(upstream.URL(), SelectPriority), http.DefaultClient)
+}
+
+func TestDevToFetcherReturnsResults(t *testing.T) {
+	upstream := testutil.NewDevTo(t)
+	upstream.Set(testutil.Behavior{Results: 5})
+
+	results, err := newTestDevToFetcher(upstream).Fetch(context.Background(), "golang", 3)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	if len(results) != 3 {
+		t.Fatalf("len(results) = %d, want 3", len(results))
+	}
+	first, edited := results[0], results[1]
+	if first.URL != "https://dev.to/tester/article-1" || first.Metadata["reactions"] != "150" ||
+		first.Metadata["comments"] != "0" || first.Metadata["tags"] != "go,tutorial" ||
+		first.ThumbnailURL != "https://media.dev.to/cover-1.png" || first.Type != pb.ResultType_RESULT_TYPE_ARTICLE {
+		t.Errorf("results[0] = %+v, want the article with reactions, comments, tags and cover", first)
+	}
+	if first.Author == nil || first.Author.Name != "Test Writer" || first.Author.ProfileURL != "https://dev.to/tester" {
+		t.Errorf("results[0].Author = %+v, want the writer's profile", first.Author)
+	}
+	if edited.ThumbnailURL != "" || edited.UpdatedAt-edited.CreatedAt != 3600 {
+		t.Errorf("results[1] = %+v, want no cover and the edit an hour after publication", edited)
+	}
+
+	query := upstream.Requests()[0].Query()
+	if query.Get("q") != "golang" || query.Get("per_page") != "3" || query.Get("page") != "1" {
+		t.Errorf("upstream query = %v, want q=golang per_page=3 page=1", query)
+	}
+}
+
+func TestDevToFetcherTranslatesQueryTerms(t *testing.T) {
+	upstream := testutil.NewDevTo(t)
+	fetcher := newTestDevToFetcher(upstream)
+	fetcher.now = func() time.Time { return time.Date(2024, 3, 11, 12, 0, 0, 0, time.UTC) }
+
+	if _, err := fetcher.Fetch(context.Background(), "error handling tag:Go lang:rust after:2024-03-01", 5); err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	// after:2024-03-01 allows articles from March 2nd, 9.5 days earlier
+	query := upstream.Requests()[0].Query()
+	if query.Get("q") != "error handling" || query.Get("tags") != "go,rust" || query.Get("top") != "10" {
+		t.Errorf("upstream query = %v, want q=error handling tags=go,rust top=10", query)
+	}
+}
+
+func TestDevToFetcherReportsServerErrors(t *testing.T) {
+	upstream := testutil.NewDevTo(t)
+	upstream.Set(testutil.Behavior{StatusCode: http.StatusServiceUnavailable})
+
+	_, err := newTestDevToFetcher(upstream).Fetch(context.Background(), "golang", 5)
+	if err == nil || !strings.Contains(err.Error(), "status=503") {
+		t.Fatalf("Fetch() error = %v, want a 503 error", err)
+	}
+}
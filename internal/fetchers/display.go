@@ -0,0 +1,83 @@
+package fetchers
+
+// Display is how a platform is presented to users
+type Display struct {
+	DisplayName string
+	IconURL     string
+	BrandColor  string
+	Description string
+}
+
+// displays holds the presentation of the built-in platforms. Mocked
+// platforms share the entry of the platform they stand in for.
+var displays = map[string]Display{
+	"github": {
+		DisplayName: "GitHub",
+		IconURL:     "https://github.githubassets.com/favicons/favicon.svg",
+		BrandColor:  "#181717",
+		Description: "Open source repositories",
+	},
+	"stackoverflow": {
+		DisplayName: "Stack Overflow",
+		IconURL:     "https://cdn.sstatic.net/Sites/stackoverflow/Img/apple-touch-icon.png",
+		BrandColor:  "#F48024",
+		Description: "Programming questions and answers",
+	},
+	"serverfault": {
+		DisplayName: "Server Fault",
+		IconURL:     "https://cdn.sstatic.net/Sites/serverfault/Img/apple-touch-icon.png",
+		BrandColor:  "#E7282D",
+		Description: "System and network administration questions and answers",
+	},
+	"superuser": {
+		DisplayName: "Super User",
+		IconURL:     "https://cdn.sstatic.net/Sites/superuser/Img/apple-touch-icon.png",
+		BrandColor:  "#2EACE3",
+		Description: "Computer enthusiast and power user questions and answers",
+	},
+	"dba": {
+		DisplayName: "Database Administrators",
+		IconURL:     "https://cdn.sstatic.net/Sites/dba/Img/apple-touch-icon.png",
+		BrandColor:  "#3F6B92",
+		Description: "Database questions and answers",
+	},
+	"askubuntu": {
+		DisplayName: "Ask Ubuntu",
+		IconURL:     "https://cdn.sstatic.net/Sites/askubuntu/Img/apple-touch-icon.png",
+		BrandColor:  "#DD4814",
+		Description: "Ubuntu questions and answers",
+	},
+	"reddit": {
+		DisplayName: "Reddit",
+		IconURL:     "https://www.redditstatic.com/desktop2x/img/favicon/android-icon-192x192.png",
+		BrandColor:  "#FF4500",
+		Description: "Community discussions",
+	},
+	"hackernews": {
+		DisplayName: "Hacker News",
+		IconURL:     "https://news.ycombinator.com/y18.svg",
+		BrandColor:  "#FF6600",
+		Description: "Tech news and discussions",
+	},
+	"dockerhub": {
+		DisplayName: "Docker Hub",
+		IconURL:     "https://hub.docker.com/favicon.ico",
+		BrandColor:  "#1D63ED",
+		Description: "Container images",
+	},
+	"devto": {
+		DisplayName: "DEV Community",
+		IconURL:     "https://dev.to/favicon.ico",
+		BrandColor:  "#0A0A0A",
+		Description: "Developer blog posts and tutorials",
+	},
+}
+
+// DisplayFor returns the presentation of a platform. Platforms without an
+// entry are shown under their name.
+func DisplayFor(name string) Display {
+	if display, ok := displays[name]; ok {
+		return display
+	}
+	return Display{DisplayName: name}
+}
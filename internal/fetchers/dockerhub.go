@@ -0,0 +1,239 @@
+package fetchers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/farhapartex/search-proxy/internal/models"
+	dsl "github.com/farhapartex/search-proxy/internal/query"
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+// dockerHubURL is where Docker Hub shows image repositories
+const dockerHubURL = "https://hub.docker.com"
+
+// DockerHubFetcher fetches image repositories from the Docker Hub search
+// API, which needs no credentials
+type DockerHubFetcher struct {
+	endpoints *EndpointPool
+	client    *http.Client
+}
+
+// NewDockerHubFetcher creates a new Docker Hub fetcher
+func NewDockerHubFetcher(endpoints *EndpointPool, client *http.Client) *DockerHubFetcher {
+	return &DockerHubFetcher{
+		endpoints: endpoints,
+		client:    client,
+	}
+}
+
+// Name returns the platform name
+func (d *DockerHubFetcher) Name() string {
+	return "dockerhub"
+}
+
+// Fetch retrieves search results from Docker Hub
+func (d *DockerHubFetcher) Fetch(ctx context.Context, query string, maxResults int) ([]*models.SearchResult, error) {
+	repos, err := d.search(ctx, query, maxResults)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*models.SearchResult, len(repos))
+	for i := range repos {
+		results[i] = repos[i].result()
+	}
+	return results, nil
+}
+
+// FetchProto retrieves search results from Docker Hub as protobuf results
+func (d *DockerHubFetcher) FetchProto(ctx context.Context, query string, maxResults int) ([]*pb.Result, error) {
+	repos, err := d.search(ctx, query, maxResults)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*pb.Result, len(repos))
+	for i := range repos {
+		results[i] = repos[i].proto()
+	}
+	return results, nil
+}
+
+func (d *DockerHubFetcher) search(ctx context.Context, query string, maxResults int) ([]DockerHubRepository, error) {
+	var repos []DockerHubRepository
+	err := d.endpoints.Try(ctx, func(baseURL string) error {
+		var err error
+		repos, err = d.searchAt(ctx, baseURL, query, maxResults)
+		return err
+	})
+	return repos, err
+}
+
+func (d *DockerHubFetcher) searchAt(ctx context.Context, baseURL, query string, maxResults int) ([]DockerHubRepository, error) {
+	// Docker Hub has no search qualifiers; DSL terms are dropped
+	page := PageFrom(ctx)
+	searchURL := fmt.Sprintf("%s/search/repositories/?query=%s&page_size=%d&page=%d",
+		baseURL,
+		url.QueryEscape(dsl.Parse(query).Text),
+		maxResults,
+		page.Number(),
+	)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, searchURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+		return nil, &StatusError{
+			Platform:   "DockerHub",
+			StatusCode: resp.StatusCode,
+			Body:       string(body),
+			RetryAfter: retryAfter(resp.Header),
+		}
+	}
+
+	var hubResp DockerHubSearchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&hubResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	page.setNextNumber(hubResp.Next != "")
+	return hubResp.Results, nil
+}
+
+// DockerHubSearchResponse represents the Docker Hub repository search
+// response
+type DockerHubSearchResponse struct {
+	Count int `json:"count"`
+	// Next is the URL of the following page; empty on the last
+	Next    string                `json:"next"`
+	Results []DockerHubRepository `json:"results"`
+}
+
+// DockerHubRepository represents an image repository in search results
+type DockerHubRepository struct {
+	// RepoName is "namespace/name", or just "name" for official images
+	RepoName         string `json:"repo_name"`
+	ShortDescription string `json:"short_description"`
+	StarCount        int    `json:"star_count"`
+	PullCount        int64  `json:"pull_count"`
+	IsOfficial       bool   `json:"is_official"`
+	// Badge is "official", "verified_publisher" or "open_source" when the
+	// repository carries one
+	Badge string `json:"badge"`
+	// DateRegistered and LastUpdated are zero when the API leaves them out
+	DateRegistered time.Time `json:"date_registered"`
+	LastUpdated    time.Time `json:"last_updated"`
+}
+
+// times returns the registration and last push times in Unix seconds
+func (r *DockerHubRepository) times() (created, updated int64) {
+	created = models.UnixSeconds(r.DateRegistered)
+	return created, max(models.UnixSeconds(r.LastUpdated), created)
+}
+
+// namespace is the account publishing the image
+func (r *DockerHubRepository) namespace() string {
+	if namespace, _, ok := strings.Cut(r.RepoName, "/"); ok {
+		return namespace
+	}
+	return "library"
+}
+
+func (r *DockerHubRepository) official() bool {
+	return r.IsOfficial || r.Badge == "official"
+}
+
+// link is the repository's page: /_/name for official images, /r/ for
+// the rest
+func (r *DockerHubRepository) link() string {
+	if r.official() {
+		return dockerHubURL + "/_/" + strings.TrimPrefix(r.RepoName, "library/")
+	}
+	return dockerHubURL + "/r/" + r.RepoName
+}
+
+// snippet is the description, or the name for images without one
+func (r *DockerHubRepository) snippet() string {
+	if r.ShortDescription != "" {
+		return r.ShortDescription
+	}
+	return r.RepoName
+}
+
+// author is the publishing account; nil for official images, which Docker
+// curates
+func (r *DockerHubRepository) author() *models.Author {
+	if r.official() {
+		return nil
+	}
+	namespace := r.namespace()
+	return &models.Author{
+		Name:       namespace,
+		Handle:     namespace,
+		ProfileURL: dockerHubURL + "/u/" + url.PathEscape(namespace),
+	}
+}
+
+func (r *DockerHubRepository) result() *models.SearchResult {
+	result := models.NewSearchResult("dockerhub", r.RepoName, r.snippet(), r.link())
+	result.SetTimes(r.times())
+	result.Author = r.author()
+	result.Type = pb.ResultType_RESULT_TYPE_CONTAINER_IMAGE
+	result.Metadata = r.metadata()
+	result.DockerHub = r.details()
+	return result
+}
+
+func (r *DockerHubRepository) proto() *pb.Result {
+	created, updated := r.times()
+	return &pb.Result{
+		Platform:        "dockerhub",
+		Title:           r.RepoName,
+		Snippet:         r.snippet(),
+		Url:             r.link(),
+		Timestamp:       created,
+		CreatedAt:       created,
+		UpdatedAt:       updated,
+		Metadata:        r.metadata(),
+		Author:          r.author().ToProto(),
+		ResultType:      pb.ResultType_RESULT_TYPE_CONTAINER_IMAGE,
+		PlatformDetails: &pb.Result_Dockerhub{Dockerhub: r.details()},
+	}
+}
+
+func (r *DockerHubRepository) metadata() map[string]string {
+	return map[string]string{
+		"stars":     fmt.Sprintf("%d", r.StarCount),
+		"pulls":     fmt.Sprintf("%d", r.PullCount),
+		"official":  fmt.Sprintf("%t", r.official()),
+		"verified":  fmt.Sprintf("%t", r.Badge == "verified_publisher"),
+		"namespace": r.namespace(),
+	}
+}
+
+func (r *DockerHubRepository) details() *pb.DockerHubMeta {
+	return &pb.DockerHubMeta{
+		Stars:     int32(r.StarCount),
+		Pulls:     r.PullCount,
+		Official:  r.official(),
+		Verified:  r.Badge == "verified_publisher",
+		Namespace: r.namespace(),
+	}
+}
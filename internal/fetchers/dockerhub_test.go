@@ -0,0 +1,56 @@
+package fetchers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/farhapartex/search-proxy/internal/testutil"
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+func newTestDockerHubFetcher(upstream *testutil.Upstream) *DockerHubFetcher {
+	return NewDockerHubFetcher(NewEndpointPool(upstream.URL(), SelectPriority), http.DefaultClient)
+}
+
+func TestDockerHubFetcherReturnsResults(t *testing.T) {
+	upstream := testutil.NewDockerHub(t)
+	upstream.Set(testutil.Behavior{Results: 5})
+
+	results, err := newTestDockerHubFetcher(upstream).Fetch(context.Background(), "redis", 3)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	if len(results) != 3 {
+		t.Fatalf("len(results) = %d, want 3", len(results))
+	}
+	official, verified, other := results[0], results[1], results[2]
+	if official.URL != "https://hub.docker.com/_/redis" || official.Author != nil ||
+		!official.DockerHub.GetOfficial() || official.Metadata["namespace"] != "library" {
+		t.Errorf("results[0] = %+v, want the official image without an author", official)
+	}
+	if verified.URL != "https://hub.docker.com/r/fake/image-2" || verified.Metadata["verified"] != "true" ||
+		verified.Author == nil || verified.Author.ProfileURL != "https://hub.docker.com/u/fake" {
+		t.Errorf("results[1] = %+v, want the verified publisher's image", verified)
+	}
+	if other.Metadata["pulls"] != "1000000000" || other.DockerHub.GetVerified() || other.Type != pb.ResultType_RESULT_TYPE_CONTAINER_IMAGE {
+		t.Errorf("results[2] = %+v, want a plain image with its pulls", other)
+	}
+
+	query := upstream.Requests()[0].Query()
+	if query.Get("query") != "redis" || query.Get("page_size") != "3" || query.Get("page") != "1" {
+		t.Errorf("upstream query = %v, want query=redis page_size=3 page=1", query)
+	}
+}
+
+func TestDockerHubFetcherReportsServerErrors(t *testing.T) {
+	upstream := testutil.NewDockerHub(t)
+	upstream.Set(testutil.Behavior{StatusCode: http.StatusServiceUnavailable})
+
+	_, err := newTestDockerHubFetcher(upstream).Fetch(context.Background(), "redis", 5)
+	if err == nil || !strings.Contains(err.Error(), "status=503") {
+		t.Fatalf("Fetch() error = %v, want a 503 error", err)
+	}
+}
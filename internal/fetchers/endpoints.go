@@ -0,0 +1,137 @@
+package fetchers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Endpoint selection strategies
+const (
+	// SelectPriority tries endpoints in configured order
+	SelectPriority = "priority"
+	// SelectLatency tries the endpoint with the lowest observed latency first
+	SelectLatency = "latency"
+)
+
+// endpointCooldown is how long a failed endpoint is moved to the back of the
+// selection order
+const endpointCooldown = 30 * time.Second
+
+// latencyDecay weights the newest sample in the moving latency average
+const latencyDecay = 0.3
+
+// EndpointPool holds the base URLs a platform can be reached at and picks
+// which one to try first, failing over to the others on error
+type EndpointPool struct {
+	mu        sync.Mutex
+	endpoints []*endpoint
+	strategy  string
+}
+
+type endpoint struct {
+	baseURL       string
+	priority      int
+	latency       time.Duration
+	cooldownUntil time.Time
+}
+
+// NewEndpointPool creates a pool from a comma-separated list of base URLs
+func NewEndpointPool(baseURLs, strategy string) *EndpointPool {
+	pool := &EndpointPool{strategy: strategy}
+	for _, baseURL := range strings.Split(baseURLs, ",") {
+		baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
+		if baseURL == "" {
+			continue
+		}
+		pool.endpoints = append(pool.endpoints, &endpoint{
+			baseURL:  baseURL,
+			priority: len(pool.endpoints),
+		})
+	}
+	return pool
+}
+
+// Preferred returns the base URL that would be tried first, or "" if there
+// are none
+func (p *EndpointPool) Preferred() string {
+	if order := p.order(); len(order) > 0 {
+		return order[0].baseURL
+	}
+	return ""
+}
+
+// Try calls fn with each base URL in selection order until one succeeds. It
+// stops early when ctx is done, since later endpoints could not answer in time.
+func (p *EndpointPool) Try(ctx context.Context, fn func(baseURL string) error) error {
+	order := p.order()
+	if len(order) == 0 {
+		return errors.New("no endpoints configured")
+	}
+
+	var errs []error
+	for _, ep := range order {
+		start := time.Now()
+		err := fn(ep.baseURL)
+		if err == nil {
+			p.observe(ep, time.Since(start))
+			return nil
+		}
+
+		if ctx.Err() != nil {
+			return err
+		}
+		p.fail(ep)
+		errs = append(errs, fmt.Errorf("%s: %w", ep.baseURL, err))
+	}
+
+	if len(errs) == 1 {
+		return errors.Unwrap(errs[0])
+	}
+	return errors.Join(errs...)
+}
+
+// order returns the endpoints in the order they should be tried
+func (p *EndpointPool) order() []*endpoint {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	order := append([]*endpoint(nil), p.endpoints...)
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := order[i], order[j]
+		aCooling, bCooling := now.Before(a.cooldownUntil), now.Before(b.cooldownUntil)
+		if aCooling != bCooling {
+			return !aCooling
+		}
+		if p.strategy == SelectLatency && a.latency != b.latency {
+			// Endpoints without samples yet sort first so they get measured
+			return a.latency < b.latency
+		}
+		return a.priority < b.priority
+	})
+	return order
+}
+
+func (p *EndpointPool) observe(ep *endpoint, latency time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if ep.latency == 0 {
+		ep.latency = latency
+	} else {
+		ep.latency = time.Duration(latencyDecay*float64(latency) + (1-latencyDecay)*float64(ep.latency))
+	}
+	ep.cooldownUntil = time.Time{}
+}
+
+func (p *EndpointPool) fail(ep *endpoint) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ep.cooldownUntil = time.Now().Add(endpointCooldown)
+}
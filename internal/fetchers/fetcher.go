@@ -4,8 +4,13 @@ import (
 	"context"
 
 	"github.com/farhapartex/search-proxy/internal/models"
+	pb "github.com/farhapartex/search-proxy/proto"
 )
 
+// maxErrorBodyBytes bounds how much of a non-200 response body is read into
+// the returned error
+const maxErrorBodyBytes = 1024
+
 // Fetcher is the interface that all platform fetchers must implement
 type Fetcher interface {
 	// Fetch retrieves search results from the platform
@@ -17,3 +22,32 @@ type Fetcher interface {
 	// Name returns the platform name
 	Name() string
 }
+
+// ProtoFetcher is implemented by fetchers that can build protobuf results
+// directly from the upstream response, skipping the intermediate
+// models.SearchResult copy
+type ProtoFetcher interface {
+	Fetcher
+
+	// FetchProto is like Fetch but returns protobuf results
+	FetchProto(ctx context.Context, query string, maxResults int) ([]*pb.Result, error)
+}
+
+// FetchProto fetches protobuf results from f, converting from the internal
+// format when f does not support direct conversion
+func FetchProto(ctx context.Context, f Fetcher, query string, maxResults int) ([]*pb.Result, error) {
+	if pf, ok := f.(ProtoFetcher); ok {
+		return pf.FetchProto(ctx, query, maxResults)
+	}
+
+	results, err := f.Fetch(ctx, query, maxResults)
+	if err != nil {
+		return nil, err
+	}
+
+	protos := make([]*pb.Result, len(results))
+	for i, result := range results {
+		protos[i] = result.ToProto()
+	}
+	return protos, nil
+}
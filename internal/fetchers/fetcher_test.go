@@ -0,0 +1,190 @@
+package fetchers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	dsl "github.com/farhapartex/search-proxy/internal/query"
+	"github.com/farhapartex/search-proxy/internal/testutil"
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+// FuzzQueryReachesUpstreamIntact checks that every fetcher escapes the query
+// so the upstream receives exactly what the client searched for, and that no
+// input breaks the request URL
+func FuzzQueryReachesUpstreamIntact(f *testing.F) {
+	f.Add("react hooks")
+	f.Add("a&per_page=1000#frag")
+	f.Add("100% ?=&+ /..//")
+	f.Add("line\r\nHost: evil.example")
+	f.Add("emoji \U0001F469\u200d\U0001F4BB")
+	f.Add("日本語 検索")
+
+	github := testutil.NewGitHub(f)
+	stackOverflow := testutil.NewStackOverflow(f)
+	reddit := testutil.NewReddit(f)
+
+	upstreams := []struct {
+		upstream *testutil.Upstream
+		fetcher  Fetcher
+	}{
+		{github, NewGitHubFetcher(nil, NewEndpointPool(github.URL(), SelectPriority), http.DefaultClient)},
+		{stackOverflow, NewStackOverflowFetcher(nil, NewEndpointPool(stackOverflow.URL(), SelectPriority), true, http.DefaultClient)},
+		{reddit, NewRedditFetcher("", "", "test-agent/1.0", reddit.URL(), "", reddit.Client())},
+	}
+
+	f.Fuzz(func(t *testing.T, query string) {
+		// The server rejects invalid UTF-8 before it gets this far, and
+		// query DSL terms are translated on purpose
+		if !utf8.ValidString(query) || len(dsl.Parse(query).Terms) > 0 {
+			t.Skip()
+		}
+
+		for _, u := range upstreams {
+			if _, err := u.fetcher.Fetch(context.Background(), query, 5); err != nil {
+				t.Fatalf("%s: Fetch(%q) error = %v", u.fetcher.Name(), query, err)
+			}
+
+			requests := u.upstream.Requests()
+			got := requests[len(requests)-1].Query()
+			if got.Get("q") != query {
+				t.Fatalf("%s: upstream got q=%q, want %q", u.fetcher.Name(), got.Get("q"), query)
+			}
+		}
+	})
+}
+
+func TestFetchersTranslateQueryTerms(t *testing.T) {
+	github := testutil.NewGitHub(t)
+	stackOverflow := testutil.NewStackOverflow(t)
+	reddit := testutil.NewReddit(t)
+	hackerNews := testutil.NewHackerNews(t)
+	githubFetcher := NewGitHubFetcher(nil, NewEndpointPool(github.URL(), SelectPriority), http.DefaultClient)
+	issues := WithRequestOptions(context.Background(), RequestOptions{ContentType: pb.ContentType_CONTENT_TYPE_ISSUES})
+
+	const query = "worker pool lang:go stars:>100 tag:concurrency subreddit:golang after:2023-01-01 before:2023-06-01"
+	for _, tc := range []struct {
+		name     string
+		ctx      context.Context
+		upstream *testutil.Upstream
+		fetcher  Fetcher
+		want     url.Values
+	}{
+		{"github", context.Background(), github, githubFetcher, url.Values{
+			"q": {"worker pool language:go stars:>100 topic:concurrency created:>2023-01-01 created:<2023-06-01"},
+		}},
+		{"github issues", issues, github, githubFetcher, url.Values{
+			"q": {"worker pool language:go label:concurrency created:>2023-01-01 created:<2023-06-01 is:issue"},
+		}},
+		{"stackoverflow", context.Background(), stackOverflow,
+			NewStackOverflowFetcher(nil, NewEndpointPool(stackOverflow.URL(), SelectPriority), true, http.DefaultClient),
+			url.Values{"q": {"worker pool"}, "tagged": {"go;concurrency"}, "fromdate": {"1672617600"}, "todate": {"1685577599"}}},
+		{"reddit", context.Background(), reddit, NewRedditFetcher("", "", "test-agent/1.0", reddit.URL(), "", reddit.Client()),
+			url.Values{"q": {"worker pool subreddit:golang"}}},
+		{"hackernews", context.Background(), hackerNews, newTestHackerNewsFetcher(hackerNews),
+			url.Values{"query": {"worker pool"}, "numericFilters": {"created_at_i>=1672617600,created_at_i<1685577600"}}},
+	} {
+		if _, err := tc.fetcher.Fetch(tc.ctx, query, 5); err != nil {
+			t.Fatalf("%s: Fetch() error = %v", tc.name, err)
+		}
+
+		requests := tc.upstream.Requests()
+		got := requests[len(requests)-1].Query()
+		for param, want := range tc.want {
+			if got.Get(param) != want[0] {
+				t.Errorf("%s: %s = %q, want %q", tc.name, param, got.Get(param), want[0])
+			}
+		}
+	}
+}
+
+func TestFetchersSetTypedMetadata(t *testing.T) {
+	github := testutil.NewGitHub(t)
+	stackOverflow := testutil.NewStackOverflow(t)
+	reddit := testutil.NewReddit(t)
+	dockerHub := testutil.NewDockerHub(t)
+
+	for _, tc := range []struct {
+		fetcher Fetcher
+		check   func(*pb.Result) bool
+	}{
+		{newTestGitHubFetcher(github), func(r *pb.Result) bool {
+			return fmt.Sprint(r.GetGithub().GetStars()) == r.Metadata["stars"] && r.GetGithub().GetLanguage() == r.Metadata["language"]
+		}},
+		{NewStackOverflowFetcher(nil, NewEndpointPool(stackOverflow.URL(), SelectPriority), false, http.DefaultClient), func(r *pb.Result) bool {
+			meta := r.GetStackoverflow()
+			return fmt.Sprint(meta.GetScore()) == r.Metadata["score"] && strings.Join(meta.GetTags(), ",") == r.Metadata["tags"]
+		}},
+		{NewRedditFetcher("", "", "test-agent/1.0", reddit.URL(), "", reddit.Client()), func(r *pb.Result) bool {
+			meta := r.GetReddit()
+			return fmt.Sprint(meta.GetNumComments()) == r.Metadata["num_comments"] && meta.GetSubreddit() == r.Metadata["subreddit"]
+		}},
+		{newTestDockerHubFetcher(dockerHub), func(r *pb.Result) bool {
+			meta := r.GetDockerhub()
+			return fmt.Sprint(meta.GetPulls()) == r.Metadata["pulls"] && fmt.Sprint(meta.GetOfficial()) == r.Metadata["official"]
+		}},
+	} {
+		results, err := FetchProto(context.Background(), tc.fetcher, "golang", 2)
+		if err != nil || len(results) == 0 {
+			t.Fatalf("%s: FetchProto() = %d results, error %v", tc.fetcher.Name(), len(results), err)
+		}
+		for i, result := range results {
+			if result.GetPlatformDetails() == nil || !tc.check(result) {
+				t.Errorf("%s: results[%d] details %v disagree with metadata %v",
+					tc.fetcher.Name(), i, result.GetPlatformDetails(), result.Metadata)
+			}
+		}
+	}
+}
+
+func TestFetchersFollowPageCursors(t *testing.T) {
+	github := testutil.NewGitHub(t)
+	stackOverflow := testutil.NewStackOverflow(t)
+	reddit := testutil.NewReddit(t)
+	hackerNews := testutil.NewHackerNews(t)
+	dockerHub := testutil.NewDockerHub(t)
+	devTo := testutil.NewDevTo(t)
+
+	for _, tc := range []struct {
+		upstream *testutil.Upstream
+		fetcher  Fetcher
+		// param is the pagination parameter the second request must carry
+		param, value string
+	}{
+		{github, NewGitHubFetcher(nil, NewEndpointPool(github.URL(), SelectPriority), http.DefaultClient), "page", "2"},
+		{stackOverflow, NewStackOverflowFetcher(nil, NewEndpointPool(stackOverflow.URL(), SelectPriority), true, http.DefaultClient), "page", "2"},
+		{reddit, NewRedditFetcher("", "", "test-agent/1.0", reddit.URL(), "", reddit.Client()), "after", "t3_fake2"},
+		{hackerNews, newTestHackerNewsFetcher(hackerNews), "page", "1"},
+		{dockerHub, newTestDockerHubFetcher(dockerHub), "page", "2"},
+		{devTo, newTestDevToFetcher(devTo), "page", "2"},
+	} {
+		name := tc.fetcher.Name()
+		tc.upstream.Set(testutil.Behavior{Results: 3})
+
+		page := &Page{}
+		if _, err := tc.fetcher.Fetch(WithPage(context.Background(), page), "go", 2); err != nil {
+			t.Fatalf("%s: first page error = %v", name, err)
+		}
+		if page.Next == "" {
+			t.Fatalf("%s: no cursor after the first of two pages", name)
+		}
+
+		page = &Page{Cursor: page.Next}
+		results, err := tc.fetcher.Fetch(WithPage(context.Background(), page), "go", 2)
+		if err != nil {
+			t.Fatalf("%s: second page error = %v", name, err)
+		}
+		if len(results) != 1 || page.Next != "" {
+			t.Errorf("%s: second page = %d results, next %q; want the last result and no cursor", name, len(results), page.Next)
+		}
+		requests := tc.upstream.Requests()
+		if got := requests[len(requests)-1].Query().Get(tc.param); got != tc.value {
+			t.Errorf("%s: second request %s=%q, want %q", name, tc.param, got, tc.value)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+// Package fetchertest is a conformance suite for fetchers.Fetcher
+// implementations. A fetcher's tests describe how to build it against a
+// healthy, a hanging and a failing upstream, and Run checks the behavior
+// every fetcher must share.
+package fetchertest
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/farhapartex/search-proxy/internal/fetchers"
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+// Harness builds the fetcher under test. Healthy is required; the others
+// may be nil when the fetcher has no upstream that can hang or fail, in
+// which case the corresponding checks are skipped.
+type Harness struct {
+	// Healthy returns a fetcher whose upstream answers every query with at
+	// least five results
+	Healthy func(t *testing.T) fetchers.Fetcher
+	// Hanging returns a fetcher whose upstream never answers
+	Hanging func(t *testing.T) fetchers.Fetcher
+	// Failing returns a fetcher whose upstream answers with an error
+	Failing func(t *testing.T) fetchers.Fetcher
+}
+
+// metadataKey is the convention for result metadata keys
+var metadataKey = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// Run runs the conformance suite as subtests of t
+func Run(t *testing.T, h Harness) {
+	t.Run("Name", func(t *testing.T) {
+		name := h.Healthy(t).Name()
+		if name == "" || name != strings.ToLower(name) || strings.ContainsAny(name, " ,") {
+			t.Errorf("Name() = %q, want a non-empty lowercase identifier", name)
+		}
+	})
+
+	t.Run("HonorsMaxResults", func(t *testing.T) {
+		f := h.Healthy(t)
+		for _, maxResults := range []int{1, 2, 5} {
+			results, err := f.Fetch(context.Background(), "golang", maxResults)
+			if err != nil {
+				t.Fatalf("Fetch(maxResults=%d) error = %v", maxResults, err)
+			}
+			if len(results) == 0 || len(results) > maxResults {
+				t.Errorf("Fetch(maxResults=%d) returned %d results", maxResults, len(results))
+			}
+		}
+	})
+
+	t.Run("ResultConventions", func(t *testing.T) {
+		f := h.Healthy(t)
+		results, err := f.Fetch(context.Background(), "golang", 5)
+		if err != nil {
+			t.Fatalf("Fetch() error = %v", err)
+		}
+
+		for i, result := range results {
+			if result.Platform != f.Name() {
+				t.Errorf("results[%d].Platform = %q, want %q", i, result.Platform, f.Name())
+			}
+			if result.Title == "" {
+				t.Errorf("results[%d].Title is empty", i)
+			}
+			if u, err := url.Parse(result.URL); err != nil || !u.IsAbs() {
+				t.Errorf("results[%d].URL = %q, want an absolute URL", i, result.URL)
+			}
+			if result.Timestamp <= 0 {
+				t.Errorf("results[%d].Timestamp = %d, want a Unix time", i, result.Timestamp)
+			}
+			if result.CreatedAt != result.Timestamp || result.UpdatedAt < result.CreatedAt {
+				t.Errorf("results[%d] created/updated = %d/%d, want created = timestamp %d and updated no earlier",
+					i, result.CreatedAt, result.UpdatedAt, result.Timestamp)
+			}
+			if u, err := url.Parse(result.ThumbnailURL); result.ThumbnailURL != "" && (err != nil || !u.IsAbs()) {
+				t.Errorf("results[%d].ThumbnailURL = %q, want empty or an absolute URL", i, result.ThumbnailURL)
+			}
+			if a := result.Author; a != nil && (a.Name == "" || !strings.HasPrefix(a.ProfileURL, "https://")) {
+				t.Errorf("results[%d].Author = %+v, want a name and an https profile URL", i, a)
+			}
+			if result.Type == pb.ResultType_RESULT_TYPE_UNSPECIFIED {
+				t.Errorf("results[%d].Type is unspecified", i)
+			}
+			if result.Metadata == nil {
+				t.Errorf("results[%d].Metadata is nil, want an empty map at least", i)
+			}
+			for key, value := range result.Metadata {
+				if !metadataKey.MatchString(key) {
+					t.Errorf("results[%d].Metadata key %q is not snake_case", i, key)
+				}
+				if !utf8.ValidString(value) {
+					t.Errorf("results[%d].Metadata[%q] is not valid UTF-8", i, key)
+				}
+			}
+		}
+	})
+
+	t.Run("ProtoMatchesFetch", func(t *testing.T) {
+		f := h.Healthy(t)
+		if _, ok := f.(fetchers.ProtoFetcher); !ok {
+			t.Skip("fetcher does not convert to protobuf directly")
+		}
+
+		results, err := f.Fetch(context.Background(), "golang", 5)
+		if err != nil {
+			t.Fatalf("Fetch() error = %v", err)
+		}
+		protos, err := fetchers.FetchProto(context.Background(), f, "golang", 5)
+		if err != nil {
+			t.Fatalf("FetchProto() error = %v", err)
+		}
+
+		if len(protos) != len(results) {
+			t.Fatalf("FetchProto() returned %d results, Fetch() %d", len(protos), len(results))
+		}
+		for i := range results {
+			want := results[i].ToProto()
+			if protos[i].Url != want.Url || protos[i].Title != want.Title || protos[i].Snippet != want.Snippet {
+				t.Errorf("FetchProto()[%d] = %v, want %v", i, protos[i], want)
+			}
+		}
+	})
+
+	t.Run("StopsOnCancel", func(t *testing.T) {
+		if h.Hanging == nil {
+			t.Skip("no hanging upstream")
+		}
+		f := h.Hanging(t)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(20*time.Millisecond, cancel)
+
+		start := time.Now()
+		results, err := f.Fetch(ctx, "golang", 5)
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("Fetch() returned %v after cancel, want promptly", elapsed)
+		}
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Fetch() error = %v, want it to wrap context.Canceled", err)
+		}
+		if len(results) != 0 {
+			t.Errorf("Fetch() returned %d results after cancel", len(results))
+		}
+	})
+
+	t.Run("StopsAtDeadline", func(t *testing.T) {
+		if h.Hanging == nil {
+			t.Skip("no hanging upstream")
+		}
+		f := h.Hanging(t)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		if _, err := f.Fetch(ctx, "golang", 5); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Fetch() error = %v, want it to wrap context.DeadlineExceeded", err)
+		}
+	})
+
+	t.Run("ReportsUpstreamErrors", func(t *testing.T) {
+		if h.Failing == nil {
+			t.Skip("no failing upstream")
+		}
+		f := h.Failing(t)
+
+		results, err := f.Fetch(context.Background(), "golang", 5)
+		if err == nil {
+			t.Fatal("Fetch() error = nil, want the upstream failure")
+		}
+		if len(results) != 0 {
+			t.Errorf("Fetch() returned %d results alongside an error", len(results))
+		}
+		// Errors end up in logs next to other platforms' errors
+		if !strings.Contains(strings.ToLower(err.Error()), f.Name()) {
+			t.Errorf("Fetch() error = %q, want it to name the platform", err)
+		}
+	})
+}
@@ -11,23 +11,24 @@ import (
 	"time"
 
 	"github.com/farhapartex/search-proxy/internal/models"
+	dsl "github.com/farhapartex/search-proxy/internal/query"
+	pb "github.com/farhapartex/search-proxy/proto"
 )
 
 // GitHubFetcher fetches search results from GitHub
 type GitHubFetcher struct {
-	apiToken string
-	baseURL  string
-	client   *http.Client
+	tokens    *CredentialPool
+	endpoints *EndpointPool
+	client    *http.Client
 }
 
-// NewGitHubFetcher creates a new GitHub fetcher
-func NewGitHubFetcher(apiToken, baseURL string) *GitHubFetcher {
+// NewGitHubFetcher creates a new GitHub fetcher. A nil token pool searches
+// anonymously.
+func NewGitHubFetcher(tokens *CredentialPool, endpoints *EndpointPool, client *http.Client) *GitHubFetcher {
 	return &GitHubFetcher{
-		apiToken: apiToken,
-		baseURL:  baseURL,
-		client: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+		tokens:    tokens,
+		endpoints: endpoints,
+		client:    client,
 	}
 }
 
@@ -36,14 +37,242 @@ func (g *GitHubFetcher) Name() string {
 	return "github"
 }
 
+// MaxQueryLength is GitHub's search query limit
+func (g *GitHubFetcher) MaxQueryLength() int {
+	return 256
+}
+
 // Fetch retrieves search results from GitHub
 func (g *GitHubFetcher) Fetch(ctx context.Context, query string, maxResults int) ([]*models.SearchResult, error) {
+	items, err := g.search(ctx, query, maxResults)
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert to internal format
+	results := make([]*models.SearchResult, len(items))
+	for i, item := range items {
+		results[i] = item.result()
+	}
+
+	return results, nil
+}
+
+// FetchProto retrieves search results from GitHub as protobuf results
+func (g *GitHubFetcher) FetchProto(ctx context.Context, query string, maxResults int) ([]*pb.Result, error) {
+	items, err := g.search(ctx, query, maxResults)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*pb.Result, len(items))
+	for i, item := range items {
+		results[i] = item.proto()
+	}
+
+	return results, nil
+}
+
+// ValidateCredential checks a token against the rate limit endpoint, which
+// doesn't count against the limit itself. Failures don't fail the endpoint
+// over; they say nothing about its health.
+func (g *GitHubFetcher) ValidateCredential(ctx context.Context, token string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.endpoints.Preferred()+"/rate_limit", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	return checkCredential(g.client, req, "GitHub", func(statusCode int, _ []byte) bool {
+		return statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden
+	})
+}
+
+// FetchDetails returns a repository's README as markdown. Only repository
+// results on github.com are supported; issues, code and discussions aren't.
+func (g *GitHubFetcher) FetchDetails(ctx context.Context, resultURL string, maxReplies int) (*pb.ResultDetailsResponse, error) {
+	host, segments, err := resultPath(resultURL)
+	if err != nil {
+		return nil, err
+	}
+	if host != "github.com" || len(segments) != 2 {
+		return nil, ErrUnrecognizedURL
+	}
+	owner, repo := segments[0], segments[1]
+
+	var readme []byte
+	err = g.endpoints.Try(ctx, func(baseURL string) error {
+		return g.tokens.Try(ctx, func(token string) error {
+			var err error
+			readme, err = g.readmeAt(ctx, baseURL, token, owner, repo)
+			return err
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ownerURL := "https://github.com/" + owner
+	return &pb.ResultDetailsResponse{
+		Platform:      "github",
+		Url:           ownerURL + "/" + repo,
+		Title:         owner + "/" + repo,
+		Content:       string(readme),
+		ContentFormat: SnippetMarkdown,
+		Author:        (&GitHubUser{Login: owner, HTMLURL: ownerURL}).author().ToProto(),
+	}, nil
+}
+
+func (g *GitHubFetcher) readmeAt(ctx context.Context, baseURL, token, owner, repo string) ([]byte, error) {
+	readmeURL := fmt.Sprintf("%s/repos/%s/%s/readme", baseURL, url.PathEscape(owner), url.PathEscape(repo))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, readmeURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	// The raw media type sends the file itself instead of base64 in JSON
+	req.Header.Set("Accept", "application/vnd.github.raw+json")
+	if token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+		req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+	if quota, ok := headerQuota(resp.Header); ok {
+		reportQuota(ctx, quota)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, githubStatusError(resp)
+	}
+	readme, err := readDetails(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read README: %w", err)
+	}
+	return readme, nil
+}
+
+// githubItem is one search hit: a repository, code file, issue or
+// discussion
+type githubItem interface {
+	result() *models.SearchResult
+	proto() *pb.Result
+}
+
+func (g *GitHubFetcher) search(ctx context.Context, query string, maxResults int) ([]githubItem, error) {
+	var items []githubItem
+	err := g.endpoints.Try(ctx, func(baseURL string) error {
+		return g.tokens.Try(ctx, func(token string) error {
+			var err error
+			items, err = g.searchAt(ctx, baseURL, token, query, maxResults)
+			return err
+		})
+	})
+	return items, err
+}
+
+// searchAt searches the content type the request asked for
+func (g *GitHubFetcher) searchAt(ctx context.Context, baseURL, token, query string, maxResults int) ([]githubItem, error) {
+	opts := RequestOptionsFrom(ctx)
+	contentType := opts.ContentType
+	query = githubQuery(dsl.Parse(query), opts)
+
+	switch contentType {
+	case pb.ContentType_CONTENT_TYPE_CODE:
+		// Fragments of the matching lines make better snippets than the
+		// repository description
+		return searchGitHubREST[GitHubCode](ctx, g.client, baseURL+"/search/code", token, query, maxResults,
+			"application/vnd.github.text-match+json", "")
+	case pb.ContentType_CONTENT_TYPE_ISSUES:
+		// The issues endpoint also finds pull requests unless told otherwise
+		if !hasQualifier(query, "is") && !hasQualifier(query, "type") {
+			query += " is:issue"
+		}
+		return searchGitHubREST[GitHubIssue](ctx, g.client, baseURL+"/search/issues", token, query, maxResults, "", "")
+	case pb.ContentType_CONTENT_TYPE_DISCUSSIONS:
+		return g.searchDiscussions(ctx, baseURL, token, query, maxResults)
+	}
+
+	// Let GitHub apply the star threshold so the page is filled with
+	// repositories that pass it
+	if minStars := opts.MinStars; minStars > 0 {
+		query += fmt.Sprintf(" stars:>=%d", minStars)
+	}
+	return searchGitHubREST[GitHubRepository](ctx, g.client, baseURL+"/search/repositories", token, query, maxResults, "",
+		"&sort=stars&order=desc")
+}
+
+// githubTimeLayout is how search qualifiers write a time to the second
+const githubTimeLayout = "2006-01-02T15:04:05Z"
+
+// hasQualifier reports whether query has a name: qualifier, negated or not,
+// as a term of its own; "axis:" doesn't count as "is:"
+func hasQualifier(query, name string) bool {
+	for _, term := range strings.Fields(query) {
+		if strings.HasPrefix(strings.TrimPrefix(term, "-"), name+":") {
+			return true
+		}
+	}
+	return false
+}
+
+// githubQuery spells the DSL terms as the qualifiers the search for the
+// requested content type understands, dropping the others. A request time
+// range replaces the after and before terms with one range to the second,
+// the tighter of both.
+func githubQuery(q dsl.Query, opts RequestOptions) string {
+	contentType := opts.ContentType
+	repositories := contentType == pb.ContentType_CONTENT_TYPE_UNSPECIFIED ||
+		contentType == pb.ContentType_CONTENT_TYPE_REPOSITORIES
+	// Code search has no creation dates
+	dated := contentType != pb.ContentType_CONTENT_TYPE_CODE
+	dateTerms := dated && !opts.timeRange()
+
+	var qualifiers []string
+	for _, term := range q.Terms {
+		switch {
+		case term.Field == dsl.Lang && contentType != pb.ContentType_CONTENT_TYPE_DISCUSSIONS:
+			qualifiers = append(qualifiers, "language:"+term.Value)
+		case term.Field == dsl.Stars && repositories:
+			qualifiers = append(qualifiers, "stars:"+term.Value)
+		case term.Field == dsl.Tag && repositories:
+			qualifiers = append(qualifiers, "topic:"+term.Value)
+		case term.Field == dsl.Tag && contentType == pb.ContentType_CONTENT_TYPE_ISSUES:
+			qualifiers = append(qualifiers, "label:"+term.Value)
+		case term.Field == dsl.After && dateTerms:
+			qualifiers = append(qualifiers, "created:>"+term.Value)
+		case term.Field == dsl.Before && dateTerms:
+			qualifiers = append(qualifiers, "created:<"+term.Value)
+		}
+	}
+	if dated && opts.timeRange() {
+		from, to := createdRange(q, opts)
+		if !from.IsZero() {
+			qualifiers = append(qualifiers, "created:>="+from.UTC().Format(githubTimeLayout))
+		}
+		if !to.IsZero() {
+			qualifiers = append(qualifiers, "created:<"+to.UTC().Format(githubTimeLayout))
+		}
+	}
+	return q.With(qualifiers...)
+}
+
+// searchGitHubREST runs one of the REST search endpoints, which share their
+// parameters, pagination and response envelope. extra is appended to the
+// query string as is.
+func searchGitHubREST[T any, P interface {
+	*T
+	githubItem
+}](ctx context.Context, client *http.Client, endpoint, token, query string, maxResults int, accept, extra string) ([]githubItem, error) {
 	// Build search URL
-	searchURL := fmt.Sprintf("%s/search/repositories?q=%s&per_page=%d&sort=stars&order=desc",
-		g.baseURL,
-		url.QueryEscape(query),
-		maxResults,
-	)
+	page := PageFrom(ctx)
+	searchURL := fmt.Sprintf("%s?q=%s&per_page=%d%s", endpoint, url.QueryEscape(query), maxResults, extra)
+	if page.Number() > 1 {
+		searchURL += fmt.Sprintf("&page=%d", page.Number())
+	}
 
 	// Create request
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, searchURL, nil)
@@ -53,77 +282,137 @@ func (g *GitHubFetcher) Fetch(ctx context.Context, query string, maxResults int)
 
 	// Add headers
 	//req.Header.Set("Accept", "application/vnd.github.v3+json")
-	if g.apiToken != "" {
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", g.apiToken))
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
 		req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
 	}
 
 	// Execute request
-	resp, err := g.client.Do(req)
+	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute request: %w", err)
 	}
 	defer resp.Body.Close()
+	if quota, ok := headerQuota(resp.Header); ok {
+		reportQuota(ctx, quota)
+	}
 
 	// Check status code
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("GitHub API error: status=%d, body=%s", resp.StatusCode, string(body))
+		return nil, githubStatusError(resp)
 	}
 
 	// Parse response
-	var githubResp GitHubSearchResponse
+	var githubResp GitHubSearchResponse[T]
 	if err := json.NewDecoder(resp.Body).Decode(&githubResp); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	// Convert to internal format
-	results := make([]*models.SearchResult, 0, len(githubResp.Items))
-	for _, item := range githubResp.Items {
-		result := models.NewSearchResult(
-			"github",
-			item.FullName,
-			item.Description,
-			item.HTMLURL,
-		)
-		result.Timestamp = item.CreatedAt.Unix()
-		result.Metadata = map[string]string{
-			"stars":       fmt.Sprintf("%d", item.StargazersCount),
-			"forks":       fmt.Sprintf("%d", item.ForksCount),
-			"language":    item.Language,
-			"open_issues": fmt.Sprintf("%d", item.OpenIssuesCount),
-		}
-		results = append(results, result)
+	seen := page.Number() * maxResults
+	page.setNextNumber(len(githubResp.Items) == maxResults &&
+		seen < min(githubResp.TotalCount, githubMaxResults))
+
+	items := make([]githubItem, len(githubResp.Items))
+	for i := range githubResp.Items {
+		items[i] = P(&githubResp.Items[i])
 	}
+	return items, nil
+}
 
-	return results, nil
+// githubStatusError describes a non-200 answer from the GitHub API
+func githubStatusError(resp *http.Response) *StatusError {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+	return &StatusError{
+		Platform:   "GitHub",
+		StatusCode: resp.StatusCode,
+		Body:       string(body),
+		RetryAfter: retryAfter(resp.Header),
+		// Bad credentials, or a primary or secondary rate limit
+		CredentialRejected: resp.StatusCode == http.StatusUnauthorized ||
+			resp.StatusCode == http.StatusForbidden ||
+			resp.StatusCode == http.StatusTooManyRequests,
+	}
 }
 
 // GitHubSearchResponse represents the GitHub API search response
-type GitHubSearchResponse struct {
-	TotalCount int                `json:"total_count"`
-	Items      []GitHubRepository `json:"items"`
+type GitHubSearchResponse[T any] struct {
+	TotalCount int `json:"total_count"`
+	Items      []T `json:"items"`
 }
 
 // GitHubRepository represents a GitHub repository in search results
 type GitHubRepository struct {
-	ID              int       `json:"id"`
-	Name            string    `json:"name"`
-	FullName        string    `json:"full_name"`
-	Description     string    `json:"description"`
-	HTMLURL         string    `json:"html_url"`
-	StargazersCount int       `json:"stargazers_count"`
-	ForksCount      int       `json:"forks_count"`
-	Language        string    `json:"language"`
-	OpenIssuesCount int       `json:"open_issues_count"`
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
-}
-
-// TruncateString truncates a string to maxLength and adds "..." if truncated
-func TruncateString(s string, maxLength int) string {
-	if len(s) <= maxLength {
-		return s
-	}
-	return strings.TrimSpace(s[:maxLength-3]) + "..."
+	ID              int        `json:"id"`
+	Name            string     `json:"name"`
+	FullName        string     `json:"full_name"`
+	Description     string     `json:"description"`
+	HTMLURL         string     `json:"html_url"`
+	StargazersCount int        `json:"stargazers_count"`
+	ForksCount      int        `json:"forks_count"`
+	Language        string     `json:"language"`
+	OpenIssuesCount int        `json:"open_issues_count"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	Owner           GitHubUser `json:"owner"`
+}
+
+// times returns the creation and last update times in Unix seconds
+func (r *GitHubRepository) times() (created, updated int64) {
+	created = models.UnixSeconds(r.CreatedAt)
+	return created, max(models.UnixSeconds(r.UpdatedAt), created)
+}
+
+func (r *GitHubRepository) result() *models.SearchResult {
+	result := models.NewSearchResult(
+		"github",
+		r.FullName,
+		r.Description,
+		r.HTMLURL,
+	)
+	result.SetTimes(r.times())
+	result.ThumbnailURL = r.Owner.AvatarURL
+	result.Author = r.Owner.author()
+	result.Type = pb.ResultType_RESULT_TYPE_REPOSITORY
+	result.Metadata = r.metadata()
+	result.GitHub = r.details()
+	return result
+}
+
+func (r *GitHubRepository) proto() *pb.Result {
+	created, updated := r.times()
+	return &pb.Result{
+		Platform:        "github",
+		Title:           r.FullName,
+		Snippet:         r.Description,
+		Url:             r.HTMLURL,
+		Timestamp:       created,
+		CreatedAt:       created,
+		UpdatedAt:       updated,
+		Metadata:        r.metadata(),
+		ThumbnailUrl:    r.Owner.AvatarURL,
+		Author:          r.Owner.author().ToProto(),
+		ResultType:      pb.ResultType_RESULT_TYPE_REPOSITORY,
+		PlatformDetails: &pb.Result_Github{Github: r.details()},
+	}
+}
+
+func (r *GitHubRepository) metadata() map[string]string {
+	return map[string]string{
+		"stars":       fmt.Sprintf("%d", r.StargazersCount),
+		"forks":       fmt.Sprintf("%d", r.ForksCount),
+		"language":    r.Language,
+		"open_issues": fmt.Sprintf("%d", r.OpenIssuesCount),
+	}
+}
+
+func (r *GitHubRepository) details() *pb.GitHubMeta {
+	return &pb.GitHubMeta{
+		Stars:      int32(r.StargazersCount),
+		Forks:      int32(r.ForksCount),
+		Language:   r.Language,
+		OpenIssues: int32(r.OpenIssuesCount),
+	}
 }
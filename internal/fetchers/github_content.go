@@ -0,0 +1,384 @@
+package fetchers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/farhapartex/search-proxy/internal/models"
+	"github.com/farhapartex/search-proxy/internal/sanitize"
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+// GitHubUser is the owner or author of a GitHub search hit
+type GitHubUser struct {
+	Login     string `json:"login"`
+	HTMLURL   string `json:"html_url"`
+	AvatarURL string `json:"avatar_url"`
+}
+
+func (u *GitHubUser) author() *models.Author {
+	if u == nil || u.Login == "" {
+		return nil
+	}
+	return &models.Author{Name: u.Login, Handle: u.Login, ProfileURL: u.HTMLURL}
+}
+
+// GitHubCode represents a file in code search results
+type GitHubCode struct {
+	Name       string `json:"name"`
+	Path       string `json:"path"`
+	SHA        string `json:"sha"`
+	HTMLURL    string `json:"html_url"`
+	Repository struct {
+		FullName    string     `json:"full_name"`
+		Description string     `json:"description"`
+		Owner       GitHubUser `json:"owner"`
+	} `json:"repository"`
+	// TextMatches holds the matching lines, sent when asked for with the
+	// text-match media type
+	TextMatches []struct {
+		Fragment string `json:"fragment"`
+	} `json:"text_matches"`
+}
+
+func (c *GitHubCode) title() string {
+	return c.Repository.FullName + "/" + c.Path
+}
+
+// snippet joins the matching fragments on one line, falling back to the
+// repository description
+func (c *GitHubCode) snippet() string {
+	fragments := make([]string, 0, len(c.TextMatches))
+	for _, match := range c.TextMatches {
+		if fragment := strings.Join(strings.Fields(match.Fragment), " "); fragment != "" {
+			fragments = append(fragments, fragment)
+		}
+	}
+	if len(fragments) == 0 {
+		return c.Repository.Description
+	}
+	return strings.Join(fragments, " … ")
+}
+
+func (c *GitHubCode) metadata() map[string]string {
+	return map[string]string{
+		"path":       c.Path,
+		"repository": c.Repository.FullName,
+		"sha":        c.SHA,
+	}
+}
+
+func (c *GitHubCode) details() *pb.GitHubMeta {
+	return &pb.GitHubMeta{Repository: c.Repository.FullName, Path: c.Path, Sha: c.SHA}
+}
+
+func (c *GitHubCode) result() *models.SearchResult {
+	result := models.NewSearchResult("github", c.title(), c.snippet(), c.HTMLURL)
+	result.ThumbnailURL = c.Repository.Owner.AvatarURL
+	result.Author = c.Repository.Owner.author()
+	result.Type = pb.ResultType_RESULT_TYPE_CODE
+	result.Metadata = c.metadata()
+	result.GitHub = c.details()
+	return result
+}
+
+func (c *GitHubCode) proto() *pb.Result {
+	return &pb.Result{
+		Platform:        "github",
+		Title:           c.title(),
+		Snippet:         c.snippet(),
+		Url:             c.HTMLURL,
+		Metadata:        c.metadata(),
+		ThumbnailUrl:    c.Repository.Owner.AvatarURL,
+		Author:          c.Repository.Owner.author().ToProto(),
+		ResultType:      pb.ResultType_RESULT_TYPE_CODE,
+		PlatformDetails: &pb.Result_Github{Github: c.details()},
+	}
+}
+
+// GitHubIssue represents an issue or pull request in issue search results
+type GitHubIssue struct {
+	Number        int        `json:"number"`
+	Title         string     `json:"title"`
+	HTMLURL       string     `json:"html_url"`
+	Body          string     `json:"body"`
+	State         string     `json:"state"`
+	Comments      int        `json:"comments"`
+	User          GitHubUser `json:"user"`
+	RepositoryURL string     `json:"repository_url"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	Labels        []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	// PullRequest is only present on pull requests
+	PullRequest *struct{} `json:"pull_request"`
+}
+
+// repository returns "owner/name" from the repository's API URL
+func (i *GitHubIssue) repository() string {
+	_, repo, _ := strings.Cut(i.RepositoryURL, "/repos/")
+	return repo
+}
+
+func (i *GitHubIssue) times() (created, updated int64) {
+	created = models.UnixSeconds(i.CreatedAt)
+	return created, max(models.UnixSeconds(i.UpdatedAt), created)
+}
+
+func (i *GitHubIssue) labels() []string {
+	labels := make([]string, len(i.Labels))
+	for j, label := range i.Labels {
+		labels[j] = label.Name
+	}
+	return labels
+}
+
+func (i *GitHubIssue) metadata() map[string]string {
+	return map[string]string{
+		"state":        i.State,
+		"comments":     strconv.Itoa(i.Comments),
+		"number":       strconv.Itoa(i.Number),
+		"repository":   i.repository(),
+		"labels":       strings.Join(i.labels(), ","),
+		"pull_request": strconv.FormatBool(i.PullRequest != nil),
+	}
+}
+
+func (i *GitHubIssue) details() *pb.GitHubMeta {
+	return &pb.GitHubMeta{
+		Repository:  i.repository(),
+		Number:      int32(i.Number),
+		Comments:    int32(i.Comments),
+		State:       i.State,
+		Labels:      i.labels(),
+		PullRequest: i.PullRequest != nil,
+	}
+}
+
+func (i *GitHubIssue) result() *models.SearchResult {
+	result := models.NewSearchResult("github", i.Title, sanitize.StripMarkdown(i.Body), i.HTMLURL)
+	result.SetTimes(i.times())
+	result.ThumbnailURL = i.User.AvatarURL
+	result.Author = i.User.author()
+	result.Type = pb.ResultType_RESULT_TYPE_ISSUE
+	result.Metadata = i.metadata()
+	result.GitHub = i.details()
+	return result
+}
+
+func (i *GitHubIssue) proto() *pb.Result {
+	created, updated := i.times()
+	return &pb.Result{
+		Platform:        "github",
+		Title:           i.Title,
+		Snippet:         sanitize.StripMarkdown(i.Body),
+		Url:             i.HTMLURL,
+		Timestamp:       created,
+		CreatedAt:       created,
+		UpdatedAt:       updated,
+		Metadata:        i.metadata(),
+		ThumbnailUrl:    i.User.AvatarURL,
+		Author:          i.User.author().ToProto(),
+		ResultType:      pb.ResultType_RESULT_TYPE_ISSUE,
+		PlatformDetails: &pb.Result_Github{Github: i.details()},
+	}
+}
+
+// githubDiscussionsQuery searches discussions, which only the GraphQL API
+// can do
+const githubDiscussionsQuery = `query($q: String!, $first: Int!, $after: String) {
+  search(query: $q, type: DISCUSSION, first: $first, after: $after) {
+    pageInfo { hasNextPage endCursor }
+    nodes {
+      ... on Discussion {
+        number title url bodyText createdAt updatedAt upvoteCount isAnswered
+        comments { totalCount }
+        category { name }
+        repository { nameWithOwner }
+        author { login url avatarUrl }
+      }
+    }
+  }
+}`
+
+// GitHubDiscussion represents a discussion in GraphQL search results
+type GitHubDiscussion struct {
+	Number      int       `json:"number"`
+	Title       string    `json:"title"`
+	URL         string    `json:"url"`
+	BodyText    string    `json:"bodyText"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+	UpvoteCount int       `json:"upvoteCount"`
+	IsAnswered  bool      `json:"isAnswered"`
+	Comments    struct {
+		TotalCount int `json:"totalCount"`
+	} `json:"comments"`
+	Category struct {
+		Name string `json:"name"`
+	} `json:"category"`
+	Repository struct {
+		NameWithOwner string `json:"nameWithOwner"`
+	} `json:"repository"`
+	// Author is null for deleted accounts
+	Author *struct {
+		Login     string `json:"login"`
+		URL       string `json:"url"`
+		AvatarURL string `json:"avatarUrl"`
+	} `json:"author"`
+}
+
+func (d *GitHubDiscussion) user() *GitHubUser {
+	if d.Author == nil {
+		return nil
+	}
+	return &GitHubUser{Login: d.Author.Login, HTMLURL: d.Author.URL, AvatarURL: d.Author.AvatarURL}
+}
+
+func (d *GitHubDiscussion) snippet() string {
+	return d.BodyText
+}
+
+func (d *GitHubDiscussion) times() (created, updated int64) {
+	created = models.UnixSeconds(d.CreatedAt)
+	return created, max(models.UnixSeconds(d.UpdatedAt), created)
+}
+
+func (d *GitHubDiscussion) metadata() map[string]string {
+	return map[string]string{
+		"comments":    strconv.Itoa(d.Comments.TotalCount),
+		"upvotes":     strconv.Itoa(d.UpvoteCount),
+		"is_answered": strconv.FormatBool(d.IsAnswered),
+		"category":    d.Category.Name,
+		"number":      strconv.Itoa(d.Number),
+		"repository":  d.Repository.NameWithOwner,
+	}
+}
+
+func (d *GitHubDiscussion) details() *pb.GitHubMeta {
+	return &pb.GitHubMeta{
+		Repository: d.Repository.NameWithOwner,
+		Number:     int32(d.Number),
+		Comments:   int32(d.Comments.TotalCount),
+		Upvotes:    int32(d.UpvoteCount),
+		IsAnswered: d.IsAnswered,
+		Category:   d.Category.Name,
+	}
+}
+
+func (d *GitHubDiscussion) result() *models.SearchResult {
+	result := models.NewSearchResult("github", d.Title, d.snippet(), d.URL)
+	result.SetTimes(d.times())
+	if user := d.user(); user != nil {
+		result.ThumbnailURL = user.AvatarURL
+		result.Author = user.author()
+	}
+	result.Type = pb.ResultType_RESULT_TYPE_DISCUSSION
+	result.Metadata = d.metadata()
+	result.GitHub = d.details()
+	return result
+}
+
+func (d *GitHubDiscussion) proto() *pb.Result {
+	created, updated := d.times()
+	result := &pb.Result{
+		Platform:        "github",
+		Title:           d.Title,
+		Snippet:         d.snippet(),
+		Url:             d.URL,
+		Timestamp:       created,
+		CreatedAt:       created,
+		UpdatedAt:       updated,
+		Metadata:        d.metadata(),
+		ResultType:      pb.ResultType_RESULT_TYPE_DISCUSSION,
+		PlatformDetails: &pb.Result_Github{Github: d.details()},
+	}
+	if user := d.user(); user != nil {
+		result.ThumbnailUrl = user.AvatarURL
+		result.Author = user.author().ToProto()
+	}
+	return result
+}
+
+// GitHubDiscussionsResponse represents the GraphQL discussion search response
+type GitHubDiscussionsResponse struct {
+	Data struct {
+		Search struct {
+			PageInfo struct {
+				HasNextPage bool   `json:"hasNextPage"`
+				EndCursor   string `json:"endCursor"`
+			} `json:"pageInfo"`
+			Nodes []GitHubDiscussion `json:"nodes"`
+		} `json:"search"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// searchDiscussions pages with GraphQL's own cursors rather than page numbers
+func (g *GitHubFetcher) searchDiscussions(ctx context.Context, baseURL, token, query string, maxResults int) ([]githubItem, error) {
+	page := PageFrom(ctx)
+	variables := map[string]any{"q": query, "first": maxResults}
+	if page.Cursor != "" {
+		variables["after"] = page.Cursor
+	}
+	body, err := json.Marshal(map[string]any{"query": githubDiscussionsQuery, "variables": variables})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode query: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, githubGraphQLURL(baseURL), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, githubStatusError(resp)
+	}
+
+	var graphqlResp GitHubDiscussionsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&graphqlResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	// GraphQL reports bad queries with a 200
+	if len(graphqlResp.Errors) > 0 {
+		return nil, fmt.Errorf("GitHub GraphQL error: %s", graphqlResp.Errors[0].Message)
+	}
+
+	search := graphqlResp.Data.Search
+	page.Next = ""
+	if search.PageInfo.HasNextPage {
+		page.Next = search.PageInfo.EndCursor
+	}
+
+	items := make([]githubItem, len(search.Nodes))
+	for i := range search.Nodes {
+		items[i] = &search.Nodes[i]
+	}
+	return items, nil
+}
+
+// githubGraphQLURL returns the GraphQL endpoint next to a REST base URL.
+// GitHub Enterprise Server serves REST under /api/v3 and GraphQL at
+// /api/graphql.
+func githubGraphQLURL(baseURL string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(baseURL, "/"), "/v3") + "/graphql"
+}
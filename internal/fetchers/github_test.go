@@ -0,0 +1,196 @@
+package fetchers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/farhapartex/search-proxy/internal/testutil"
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+func newTestGitHubFetcher(upstreams ...*testutil.Upstream) *GitHubFetcher {
+	urls := make([]string, len(upstreams))
+	for i, upstream := range upstreams {
+		urls[i] = upstream.URL()
+	}
+	return NewGitHubFetcher(nil, NewEndpointPool(strings.Join(urls, ","), SelectPriority), http.DefaultClient)
+}
+
+func TestGitHubFetcherReturnsResults(t *testing.T) {
+	upstream := testutil.NewGitHub(t)
+	upstream.Set(testutil.Behavior{Results: 5})
+
+	results, err := newTestGitHubFetcher(upstream).Fetch(context.Background(), "grpc", 2)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("len(results) = %d, want 2", len(results))
+	}
+	if results[0].Platform != "github" || results[0].Metadata["stars"] == "" {
+		t.Errorf("results[0] = %+v, want a github result with stars", results[0])
+	}
+	if results[0].ThumbnailURL == "" {
+		t.Errorf("results[0].ThumbnailURL is empty, want the owner avatar")
+	}
+
+	query := upstream.Requests()[0].Query()
+	if query.Get("q") != "grpc" || query.Get("per_page") != "2" {
+		t.Errorf("upstream query = %v, want q=grpc per_page=2", query)
+	}
+}
+
+func TestGitHubFetcherAppliesMinStars(t *testing.T) {
+	upstream := testutil.NewGitHub(t)
+
+	ctx := WithRequestOptions(context.Background(), RequestOptions{MinStars: 500})
+	if _, err := newTestGitHubFetcher(upstream).Fetch(ctx, "grpc", 5); err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	if got := upstream.Requests()[0].Query().Get("q"); got != "grpc stars:>=500" {
+		t.Errorf("upstream q = %q, want the stars qualifier appended", got)
+	}
+}
+
+func TestGitHubFetcherAppliesTimeRange(t *testing.T) {
+	upstream := testutil.NewGitHub(t)
+
+	// The request range is tighter than the after term, so it wins
+	ctx := WithRequestOptions(context.Background(), RequestOptions{
+		Since: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+		Until: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+	})
+	if _, err := newTestGitHubFetcher(upstream).Fetch(ctx, "grpc after:2024-01-01", 5); err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	want := "grpc created:>=2024-03-01T12:00:00Z created:<2024-04-01T00:00:00Z"
+	if got := upstream.Requests()[0].Query().Get("q"); got != want {
+		t.Errorf("upstream q = %q, want %q", got, want)
+	}
+}
+
+func TestGitHubFetcherReportsRateLimit(t *testing.T) {
+	upstream := testutil.NewGitHub(t)
+	upstream.Set(testutil.Behavior{RateLimited: true})
+
+	_, err := newTestGitHubFetcher(upstream).Fetch(context.Background(), "grpc", 5)
+	if err == nil || !strings.Contains(err.Error(), "status=403") {
+		t.Fatalf("Fetch() error = %v, want a 403 error", err)
+	}
+}
+
+func TestGitHubFetcherStopsAtDeadline(t *testing.T) {
+	upstream := testutil.NewGitHub(t)
+	upstream.Set(testutil.Behavior{Latency: time.Minute})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := newTestGitHubFetcher(upstream).Fetch(ctx, "grpc", 5); err == nil {
+		t.Fatal("Fetch() error = nil, want deadline error")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Fetch() took %v, want it to stop at the deadline", elapsed)
+	}
+}
+
+func TestGitHubFetcherFailsOverToNextEndpoint(t *testing.T) {
+	broken := testutil.NewGitHub(t)
+	broken.Set(testutil.Behavior{StatusCode: http.StatusBadGateway})
+	healthy := testutil.NewGitHub(t)
+
+	results, err := newTestGitHubFetcher(broken, healthy).Fetch(context.Background(), "grpc", 5)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if len(results) != 3 {
+		t.Errorf("len(results) = %d, want 3", len(results))
+	}
+	if len(broken.Requests()) != 1 || len(healthy.Requests()) != 1 {
+		t.Errorf("requests = %d broken, %d healthy; want 1 each", len(broken.Requests()), len(healthy.Requests()))
+	}
+}
+
+func TestGitHubFetcherSearchesContentTypes(t *testing.T) {
+	for _, tc := range []struct {
+		contentType pb.ContentType
+		path, query string
+		resultType  pb.ResultType
+		metadata    string
+	}{
+		{pb.ContentType_CONTENT_TYPE_CODE, "/search/code", "grpc", pb.ResultType_RESULT_TYPE_CODE, "path"},
+		{pb.ContentType_CONTENT_TYPE_ISSUES, "/search/issues", "grpc is:issue", pb.ResultType_RESULT_TYPE_ISSUE, "state"},
+		{pb.ContentType_CONTENT_TYPE_DISCUSSIONS, "/graphql", "grpc", pb.ResultType_RESULT_TYPE_DISCUSSION, "is_answered"},
+	} {
+		t.Run(tc.contentType.String(), func(t *testing.T) {
+			upstream := testutil.NewGitHub(t)
+			upstream.Set(testutil.Behavior{Results: 3})
+			fetcher := newTestGitHubFetcher(upstream)
+
+			page := &Page{}
+			ctx := WithPage(WithRequestOptions(context.Background(), RequestOptions{ContentType: tc.contentType}), page)
+			results, err := fetcher.FetchProto(ctx, "grpc", 2)
+			if err != nil {
+				t.Fatalf("FetchProto() error = %v", err)
+			}
+			if len(results) != 2 || results[0].ResultType != tc.resultType || results[0].Metadata[tc.metadata] == "" {
+				t.Fatalf("results = %v, want 2 %v results with %s metadata", results, tc.resultType, tc.metadata)
+			}
+			if results[0].Snippet == "" || results[0].Author == nil {
+				t.Errorf("results[0] = %v, want a snippet and an author", results[0])
+			}
+
+			request := upstream.Requests()[0]
+			if request.Path != tc.path || request.Query().Get("q") != tc.query {
+				t.Errorf("upstream request = %v, want %s with q=%q", request, tc.path, tc.query)
+			}
+
+			// The second page picks up where the first ended
+			next, err := fetcher.FetchProto(WithPage(ctx, &Page{Cursor: page.Next}), "grpc", 2)
+			if err != nil || len(next) != 1 || next[0].Url == results[0].Url {
+				t.Errorf("second page = %v, %v; want the one remaining result", next, err)
+			}
+		})
+	}
+}
+
+func TestHasQualifier(t *testing.T) {
+	for _, tc := range []struct {
+		query string
+		want  bool
+	}{
+		{"is:pr grpc", true},
+		{"grpc is:open", true},
+		{"grpc\tis:closed", true},
+		{"grpc -is:pr", true},
+		{"grpc", false},
+		{"this:that", false},
+		{"axis:x grpc", false},
+		{"grpc gis:data", false},
+	} {
+		if got := hasQualifier(tc.query, "is"); got != tc.want {
+			t.Errorf("hasQualifier(%q, is) = %t, want %t", tc.query, got, tc.want)
+		}
+	}
+	if hasQualifier("subtype:x", "type") || !hasQualifier("grpc type:pr", "type") {
+		t.Error("hasQualifier(type) matched inside a term or missed a whole one")
+	}
+}
+
+func TestGitHubGraphQLURL(t *testing.T) {
+	for base, want := range map[string]string{
+		"https://api.github.com":            "https://api.github.com/graphql",
+		"https://github.example.com/api/v3": "https://github.example.com/api/graphql",
+	} {
+		if got := githubGraphQLURL(base); got != want {
+			t.Errorf("githubGraphQLURL(%q) = %q, want %q", base, got, want)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package fetchers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/farhapartex/search-proxy/internal/models"
+	dsl "github.com/farhapartex/search-proxy/internal/query"
+	"github.com/farhapartex/search-proxy/internal/sanitize"
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+// hackerNewsItemURL is the discussion page of a Hacker News item
+const hackerNewsItemURL = "https://news.ycombinator.com/item?id="
+
+// HackerNewsFetcher fetches stories from the Algolia Hacker News search API,
+// which needs no credentials
+type HackerNewsFetcher struct {
+	endpoints *EndpointPool
+	client    *http.Client
+}
+
+// NewHackerNewsFetcher creates a new Hacker News fetcher
+func NewHackerNewsFetcher(endpoints *EndpointPool, client *http.Client) *HackerNewsFetcher {
+	return &HackerNewsFetcher{
+		endpoints: endpoints,
+		client:    client,
+	}
+}
+
+// Name returns the platform name
+func (h *HackerNewsFetcher) Name() string {
+	return "hackernews"
+}
+
+// Fetch retrieves search results from Hacker News
+func (h *HackerNewsFetcher) Fetch(ctx context.Context, query string, maxResults int) ([]*models.SearchResult, error) {
+	hits, err := h.search(ctx, query, maxResults)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*models.SearchResult, 0, len(hits))
+	for _, hit := range hits {
+		result := models.NewSearchResult(
+			"hackernews",
+			hit.Title,
+			hit.snippet(),
+			hit.link(),
+		)
+		result.SetTimes(hit.CreatedAtI, hit.CreatedAtI)
+		result.Author = hit.author()
+		result.Type = pb.ResultType_RESULT_TYPE_POST
+		result.Metadata = hit.metadata()
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// FetchProto retrieves search results from Hacker News as protobuf results
+func (h *HackerNewsFetcher) FetchProto(ctx context.Context, query string, maxResults int) ([]*pb.Result, error) {
+	hits, err := h.search(ctx, query, maxResults)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*pb.Result, len(hits))
+	for i := range hits {
+		hit := &hits[i]
+		results[i] = &pb.Result{
+			Platform:   "hackernews",
+			Title:      hit.Title,
+			Snippet:    hit.snippet(),
+			Url:        hit.link(),
+			Timestamp:  hit.CreatedAtI,
+			CreatedAt:  hit.CreatedAtI,
+			UpdatedAt:  hit.CreatedAtI,
+			Metadata:   hit.metadata(),
+			Author:     hit.author().ToProto(),
+			ResultType: pb.ResultType_RESULT_TYPE_POST,
+		}
+	}
+
+	return results, nil
+}
+
+func (h *HackerNewsFetcher) search(ctx context.Context, query string, maxResults int) ([]HackerNewsHit, error) {
+	var hits []HackerNewsHit
+	err := h.endpoints.Try(ctx, func(baseURL string) error {
+		var err error
+		hits, err = h.searchAt(ctx, baseURL, query, maxResults)
+		return err
+	})
+	return hits, err
+}
+
+// hackerNewsFilters turns the after and before terms and the request's
+// time range into a filter on the story's creation time, the only DSL
+// terms Algolia has an equivalent for
+func hackerNewsFilters(q dsl.Query, opts RequestOptions) string {
+	var filters []string
+	from, to := createdRange(q, opts)
+	if !from.IsZero() {
+		filters = append(filters, fmt.Sprintf("created_at_i>=%d", from.Unix()))
+	}
+	if !to.IsZero() {
+		filters = append(filters, fmt.Sprintf("created_at_i<%d", to.Unix()))
+	}
+	if len(filters) == 0 {
+		return ""
+	}
+	return "&numericFilters=" + url.QueryEscape(strings.Join(filters, ","))
+}
+
+func (h *HackerNewsFetcher) searchAt(ctx context.Context, baseURL, query string, maxResults int) ([]HackerNewsHit, error) {
+	// Stories only; comments would crowd out the discussions they belong to
+	q := dsl.Parse(query)
+	searchURL := fmt.Sprintf("%s/search?query=%s&tags=story&hitsPerPage=%d%s",
+		baseURL,
+		url.QueryEscape(q.Text),
+		maxResults,
+		hackerNewsFilters(q, RequestOptionsFrom(ctx)),
+	)
+	// Algolia numbers pages from 0
+	page := PageFrom(ctx)
+	if page.Number() > 1 {
+		searchURL += fmt.Sprintf("&page=%d", page.Number()-1)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, searchURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+		return nil, &StatusError{
+			Platform:   "HackerNews",
+			StatusCode: resp.StatusCode,
+			Body:       string(body),
+			RetryAfter: retryAfter(resp.Header),
+		}
+	}
+
+	var hnResp HackerNewsSearchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&hnResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	page.setNextNumber(page.Number() < hnResp.NbPages)
+	return hnResp.Hits, nil
+}
+
+// HackerNewsSearchResponse represents the Algolia Hacker News search response
+type HackerNewsSearchResponse struct {
+	Hits        []HackerNewsHit `json:"hits"`
+	NbHits      int             `json:"nbHits"`
+	HitsPerPage int             `json:"hitsPerPage"`
+	NbPages     int             `json:"nbPages"`
+}
+
+// HackerNewsHit represents a Hacker News story in search results
+type HackerNewsHit struct {
+	ObjectID string `json:"objectID"`
+	Title    string `json:"title"`
+	// URL is empty for text posts such as Ask HN
+	URL         string `json:"url"`
+	Author      string `json:"author"`
+	Points      int    `json:"points"`
+	NumComments int    `json:"num_comments"`
+	// StoryText is the HTML body of a text post
+	StoryText  string `json:"story_text"`
+	CreatedAtI int64  `json:"created_at_i"`
+}
+
+// snippet is the text of a text post, or the title for link posts
+func (s *HackerNewsHit) snippet() string {
+	if text := sanitize.HTMLText(s.StoryText); text != "" {
+		return text
+	}
+	return s.Title
+}
+
+// link is the story's target, or its discussion for text posts
+func (s *HackerNewsHit) link() string {
+	if s.URL != "" {
+		return s.URL
+	}
+	return s.discussionURL()
+}
+
+func (s *HackerNewsHit) discussionURL() string {
+	return hackerNewsItemURL + s.ObjectID
+}
+
+// author is nil when the account is gone
+func (s *HackerNewsHit) author() *models.Author {
+	if s.Author == "" {
+		return nil
+	}
+	return &models.Author{
+		Name:       s.Author,
+		Handle:     s.Author,
+		ProfileURL: "https://news.ycombinator.com/user?id=" + url.QueryEscape(s.Author),
+	}
+}
+
+func (s *HackerNewsHit) metadata() map[string]string {
+	metadata := map[string]string{
+		"points":         fmt.Sprintf("%d", s.Points),
+		"num_comments":   fmt.Sprintf("%d", s.NumComments),
+		"discussion_url": s.discussionURL(),
+	}
+	if u, err := url.Parse(s.URL); err == nil && u.Hostname() != "" {
+		metadata["link_domain"] = strings.TrimPrefix(u.Hostname(), "www.")
+	}
+	return metadata
+}
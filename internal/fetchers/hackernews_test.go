@@ -0,0 +1,51 @@
+package fetchers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/farhapartex/search-proxy/internal/testutil"
+)
+
+func newTestHackerNewsFetcher(upstream *testutil.Upstream) *HackerNewsFetcher {
+	return NewHackerNewsFetcher(NewEndpointPool(upstream.URL(), SelectPriority), http.DefaultClient)
+}
+
+func TestHackerNewsFetcherReturnsResults(t *testing.T) {
+	upstream := testutil.NewHackerNews(t)
+	upstream.Set(testutil.Behavior{Results: 5})
+
+	results, err := newTestHackerNewsFetcher(upstream).Fetch(context.Background(), "sqlite", 2)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("len(results) = %d, want 2", len(results))
+	}
+	link, text := results[0], results[1]
+	if link.URL != "https://example.com/story-1" || link.Metadata["link_domain"] != "example.com" ||
+		link.Metadata["points"] != "100" || link.Author == nil || link.Author.Handle != "tester" {
+		t.Errorf("results[0] = %+v, want the linked story with points and author", link)
+	}
+	if text.URL != "https://news.ycombinator.com/item?id=40000001" || text.Snippet != "Ask HN 2: sqlite?" {
+		t.Errorf("results[1] = %+v, want the text post linking to its discussion", text)
+	}
+
+	query := upstream.Requests()[0].Query()
+	if query.Get("query") != "sqlite" || query.Get("hitsPerPage") != "2" || query.Get("tags") != "story" {
+		t.Errorf("upstream query = %v, want query=sqlite hitsPerPage=2 tags=story", query)
+	}
+}
+
+func TestHackerNewsFetcherReportsServerErrors(t *testing.T) {
+	upstream := testutil.NewHackerNews(t)
+	upstream.Set(testutil.Behavior{StatusCode: http.StatusServiceUnavailable})
+
+	_, err := newTestHackerNewsFetcher(upstream).Fetch(context.Background(), "sqlite", 5)
+	if err == nil || !strings.Contains(err.Error(), "status=503") {
+		t.Fatalf("Fetch() error = %v, want a 503 error", err)
+	}
+}
@@ -0,0 +1,187 @@
+package fetchers
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/farhapartex/search-proxy/internal/models"
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+//go:embed fixtures/*.json
+var fixtureFS embed.FS
+
+// fixtureResult is the on-disk format of a fixture entry. A fixture file,
+// <platform>.json, holds a JSON array of them.
+type fixtureResult struct {
+	Title     string            `json:"title"`
+	Snippet   string            `json:"snippet"`
+	URL       string            `json:"url"`
+	Timestamp int64             `json:"timestamp"`
+	Metadata  map[string]string `json:"metadata"`
+	Thumbnail string            `json:"thumbnail_url"`
+	Author    *models.Author    `json:"author"`
+	// Type is a result type such as "issue" or "article"; empty uses the
+	// platform's usual type
+	Type string `json:"type"`
+}
+
+// fixtureTypes is the kind of content each platform's fixtures stand for
+var fixtureTypes = map[string]pb.ResultType{
+	"github":        pb.ResultType_RESULT_TYPE_REPOSITORY,
+	"stackoverflow": pb.ResultType_RESULT_TYPE_QUESTION,
+	"reddit":        pb.ResultType_RESULT_TYPE_POST,
+	"hackernews":    pb.ResultType_RESULT_TYPE_POST,
+	"dockerhub":     pb.ResultType_RESULT_TYPE_CONTAINER_IMAGE,
+	"devto":         pb.ResultType_RESULT_TYPE_ARTICLE,
+}
+
+// MockFetcher serves deterministic results from fixtures instead of calling
+// the platform, for demos, frontend development and CI without credentials
+// or network access
+type MockFetcher struct {
+	name     string
+	fixtures []fixtureResult
+}
+
+// NewMockFetcher loads the fixtures for the named platform: dir/<name>.json
+// when dir is set and has one, the embedded sample set otherwise. Other
+// Stack Exchange sites serve Stack Overflow's when they have none of their
+// own.
+func NewMockFetcher(name, dir string) (*MockFetcher, error) {
+	data, err := readFixtures(name, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var fixtures []fixtureResult
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		return nil, fmt.Errorf("failed to parse %s fixtures: %w", name, err)
+	}
+	for i, fixture := range fixtures {
+		if _, err := fixtureType(name, fixture.Type); err != nil {
+			return nil, fmt.Errorf("%s fixture %d: %w", name, i, err)
+		}
+	}
+
+	return &MockFetcher{name: name, fixtures: fixtures}, nil
+}
+
+// readFixtures returns the first fixture file found for name, looking in
+// dir before the embedded fixtures
+func readFixtures(name, dir string) ([]byte, error) {
+	files := []string{name + ".json"}
+	if platform := fixturePlatform(name); platform != name {
+		files = append(files, platform+".json")
+	}
+
+	if dir != "" {
+		for _, file := range files {
+			data, err := os.ReadFile(filepath.Join(dir, file))
+			if err == nil {
+				return data, nil
+			}
+			if !errors.Is(err, fs.ErrNotExist) {
+				return nil, fmt.Errorf("failed to read %s fixtures: %w", name, err)
+			}
+		}
+	}
+	for _, file := range files {
+		if data, err := fixtureFS.ReadFile("fixtures/" + file); err == nil {
+			return data, nil
+		}
+	}
+	return nil, fmt.Errorf("no fixtures for platform %s", name)
+}
+
+// fixtureType resolves a fixture's type, falling back to the platform's
+func fixtureType(name, typ string) (pb.ResultType, error) {
+	if typ == "" {
+		return fixtureTypes[fixturePlatform(name)], nil
+	}
+	value, ok := pb.ResultType_value["RESULT_TYPE_"+strings.ToUpper(typ)]
+	if !ok {
+		return 0, fmt.Errorf("unknown result type %q", typ)
+	}
+	return pb.ResultType(value), nil
+}
+
+// Fetch returns the fixtures matching any query term, or all of them when
+// nothing matches so a demo always shows results
+func (f *MockFetcher) Fetch(ctx context.Context, query string, maxResults int) ([]*models.SearchResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var matched, rest []*models.SearchResult
+	for _, fixture := range f.fixtures {
+		result := f.toSearchResult(fixture)
+		if matchesQuery(fixture, query) {
+			matched = append(matched, result)
+		} else {
+			rest = append(rest, result)
+		}
+	}
+
+	results := matched
+	if len(results) == 0 {
+		results = rest
+	}
+	if len(results) > maxResults {
+		results = results[:maxResults]
+	}
+	return results, nil
+}
+
+// Name returns the platform name
+func (f *MockFetcher) Name() string {
+	return f.name
+}
+
+func (f *MockFetcher) toSearchResult(fixture fixtureResult) *models.SearchResult {
+	metadata := make(map[string]string, len(fixture.Metadata)+1)
+	for key, value := range fixture.Metadata {
+		metadata[key] = value
+	}
+	metadata["mock"] = "true"
+
+	result := &models.SearchResult{
+		Platform:     f.name,
+		Title:        fixture.Title,
+		Snippet:      fixture.Snippet,
+		URL:          fixture.URL,
+		Metadata:     metadata,
+		ThumbnailURL: fixture.Thumbnail,
+		Author:       fixture.Author,
+	}
+	// Checked when the fixtures were loaded
+	result.Type, _ = fixtureType(f.name, fixture.Type)
+	result.SetTimes(fixture.Timestamp, 0)
+	return result
+}
+
+// fixturePlatform is the platform whose fixtures name serves
+func fixturePlatform(name string) string {
+	if IsStackExchange(name) {
+		return "stackoverflow"
+	}
+	return name
+}
+
+// matchesQuery reports whether any query term appears in the title or snippet
+func matchesQuery(fixture fixtureResult, query string) bool {
+	text := strings.ToLower(fixture.Title + " " + fixture.Snippet)
+	for _, term := range strings.Fields(strings.ToLower(query)) {
+		if strings.Contains(text, term) {
+			return true
+		}
+	}
+	return false
+}
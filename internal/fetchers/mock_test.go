@@ -0,0 +1,54 @@
+package fetchers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+func TestMockFetcherReadsFixturesDir(t *testing.T) {
+	dir := t.TempDir()
+	fixtures := `[{"title": "Custom issue", "snippet": "From the fixtures dir", "url": "https://github.com/o/r/issues/1", "type": "issue"}]`
+	if err := os.WriteFile(filepath.Join(dir, "github.json"), []byte(fixtures), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := NewMockFetcher("github", dir)
+	if err != nil {
+		t.Fatalf("NewMockFetcher() error = %v", err)
+	}
+	results, err := f.Fetch(context.Background(), "anything", 10)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Title != "Custom issue" || results[0].Type != pb.ResultType_RESULT_TYPE_ISSUE {
+		t.Errorf("results = %v, want the directory's issue", results)
+	}
+
+	// Platforms missing from the directory keep the embedded samples
+	if f, err := NewMockFetcher("reddit", dir); err != nil || len(f.fixtures) == 0 {
+		t.Errorf("NewMockFetcher(reddit) = %v, %v, want the embedded fixtures", f, err)
+	}
+}
+
+func TestMockFetcherRejectsBadFixtures(t *testing.T) {
+	dir := t.TempDir()
+	for name, fixtures := range map[string]string{
+		"github": `{"title": "not an array"}`,
+		"devto":  `[{"title": "x", "type": "podcast"}]`,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name+".json"), []byte(fixtures), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := NewMockFetcher(name, dir); err == nil || !strings.Contains(err.Error(), name) {
+			t.Errorf("NewMockFetcher(%s) error = %v, want a fixture error naming the platform", name, err)
+		}
+	}
+	if _, err := NewMockFetcher("nowhere", ""); err == nil {
+		t.Error("NewMockFetcher(nowhere) error = nil, want no fixtures")
+	}
+}
@@ -0,0 +1,77 @@
+package fetchers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	dsl "github.com/farhapartex/search-proxy/internal/query"
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+// RequestOptions carries per-request hints that only some platforms can use.
+// Fetchers read them from the context so the Fetcher interface stays stable
+// as hints are added.
+type RequestOptions struct {
+	// Locale is a canonical BCP 47 tag such as "pt-BR", or empty
+	Locale string
+
+	// MinStars asks for repositories with at least this many stars
+	MinStars int
+
+	// AnsweredOnly asks for questions that have answers
+	AnsweredOnly bool
+
+	// ContentType picks what GitHub searches; unspecified means repositories
+	ContentType pb.ContentType
+
+	// Since and Until bound when results were created, Since inclusive and
+	// Until exclusive; a zero time is no bound
+	Since time.Time
+	Until time.Time
+}
+
+// Key encodes the options for use in cache keys; equal options give equal keys
+func (o RequestOptions) Key() string {
+	return fmt.Sprintf("%s|%d|%t|%d|%d|%d", o.Locale, o.MinStars, o.AnsweredOnly, o.ContentType,
+		unixOrZero(o.Since), unixOrZero(o.Until))
+}
+
+// timeRange reports whether the request bounds creation times
+func (o RequestOptions) timeRange() bool {
+	return !o.Since.IsZero() || !o.Until.IsZero()
+}
+
+// createdRange is the creation time range a search allows: the tighter of
+// the query's after and before terms and the request's since and until
+// bounds. A zero time is no bound.
+func createdRange(q dsl.Query, opts RequestOptions) (from, to time.Time) {
+	from, to = q.CreatedRange()
+	if opts.Since.After(from) {
+		from = opts.Since
+	}
+	if !opts.Until.IsZero() && (to.IsZero() || opts.Until.Before(to)) {
+		to = opts.Until
+	}
+	return from, to
+}
+
+func unixOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+type requestOptionsKey struct{}
+
+// WithRequestOptions returns a context carrying opts
+func WithRequestOptions(ctx context.Context, opts RequestOptions) context.Context {
+	return context.WithValue(ctx, requestOptionsKey{}, opts)
+}
+
+// RequestOptionsFrom returns the options carried by ctx, or the zero value
+func RequestOptionsFrom(ctx context.Context) RequestOptions {
+	opts, _ := ctx.Value(requestOptionsKey{}).(RequestOptions)
+	return opts
+}
@@ -0,0 +1,55 @@
+package fetchers
+
+import (
+	"context"
+	"strconv"
+)
+
+// githubMaxResults is how deep GitHub lets a search page: the first 1000
+// results only
+const githubMaxResults = 1000
+
+// Page carries a platform's pagination cursor into a fetch and the cursor of
+// the following page back out. Like RequestOptions it travels in the context,
+// so fetchers that can't page simply ignore it.
+type Page struct {
+	// Cursor is where the fetch continues: a page number, or the platform's
+	// own cursor such as Reddit's "after". Empty means the first page.
+	Cursor string
+
+	// Next is set by the fetcher when more results follow
+	Next string
+}
+
+// Number is the cursor as a 1-based page number
+func (p *Page) Number() int {
+	if n, err := strconv.Atoi(p.Cursor); err == nil && n > 1 {
+		return n
+	}
+	return 1
+}
+
+// setNextNumber points Next at the following page number, or clears it
+// when no more results follow
+func (p *Page) setNextNumber(more bool) {
+	p.Next = ""
+	if more {
+		p.Next = strconv.Itoa(p.Number() + 1)
+	}
+}
+
+type pageKey struct{}
+
+// WithPage returns a context carrying page
+func WithPage(ctx context.Context, page *Page) context.Context {
+	return context.WithValue(ctx, pageKey{}, page)
+}
+
+// PageFrom returns the page carried by ctx. Without one it returns a fresh
+// first page, so fetchers can always record Next.
+func PageFrom(ctx context.Context) *Page {
+	if page, ok := ctx.Value(pageKey{}).(*Page); ok {
+		return page
+	}
+	return &Page{}
+}
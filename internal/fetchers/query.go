@@ -0,0 +1,95 @@
+package fetchers
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/rivo/uniseg"
+)
+
+// QueryLimiter is implemented by fetchers whose upstream rejects queries
+// longer than a fixed number of characters
+type QueryLimiter interface {
+	MaxQueryLength() int
+}
+
+// Unwrapper is implemented by middleware so the wrapped fetcher's optional
+// interfaces can still be found
+type Unwrapper interface {
+	Unwrap() Fetcher
+}
+
+// MaxQueryLength returns the longest query f's upstream accepts, looking
+// through middleware. Zero means no limit.
+func MaxQueryLength(f Fetcher) int {
+	if limiter, ok := findInterface[QueryLimiter](f); ok {
+		return limiter.MaxQueryLength()
+	}
+	return 0
+}
+
+// findInterface returns f, or the first fetcher wrapped by it, that
+// implements T
+func findInterface[T any](f Fetcher) (T, bool) {
+	for f != nil {
+		if t, ok := f.(T); ok {
+			return t, true
+		}
+		unwrapper, ok := f.(Unwrapper)
+		if !ok {
+			break
+		}
+		f = unwrapper.Unwrap()
+	}
+	var zero T
+	return zero, false
+}
+
+// ShortenQuery fits query into maxLen characters by dropping trailing words,
+// cutting a single over-long word only when nothing else fits. A quote left
+// unbalanced by the cut is removed so the upstream doesn't reject the
+// phrase syntax.
+func ShortenQuery(query string, maxLen int) string {
+	if maxLen <= 0 || utf8.RuneCountInString(query) <= maxLen {
+		return query
+	}
+
+	var kept []string
+	length := 0
+	for _, word := range strings.Fields(query) {
+		wordLen := utf8.RuneCountInString(word)
+		if len(kept) > 0 {
+			wordLen++ // the separating space
+		}
+		if length+wordLen > maxLen {
+			break
+		}
+		kept = append(kept, word)
+		length += wordLen
+	}
+
+	short := strings.Join(kept, " ")
+	if len(kept) == 0 {
+		short = truncateRunes(strings.TrimSpace(query), maxLen)
+	}
+	if strings.Count(short, `"`)%2 == 1 {
+		i := strings.LastIndex(short, `"`)
+		short = strings.TrimSpace(short[:i] + short[i+1:])
+	}
+	return short
+}
+
+// truncateRunes returns the longest prefix of s with at most n runes that
+// doesn't split a grapheme cluster
+func truncateRunes(s string, n int) string {
+	graphemes := uniseg.NewGraphemes(s)
+	end := 0
+	for graphemes.Next() {
+		n -= len(graphemes.Runes())
+		if n < 0 {
+			break
+		}
+		_, end = graphemes.Positions()
+	}
+	return s[:end]
+}
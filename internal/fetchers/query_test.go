@@ -0,0 +1,49 @@
+package fetchers
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/farhapartex/search-proxy/internal/ratelimit"
+)
+
+func TestShortenQuery(t *testing.T) {
+	for _, tc := range []struct {
+		query  string
+		maxLen int
+		want   string
+	}{
+		{"golang channels", 0, "golang channels"},
+		{"golang channels", 15, "golang channels"},
+		{"golang channels tutorial", 16, "golang channels"},
+		{"golang   channels tutorial", 17, "golang channels"},
+		{`"error handling" in go`, 10, "error"},
+		{`go "error handling"`, 12, "go error"},
+		{"supercalifragilistic", 5, "super"},
+		{"héllo wörld", 7, "héllo"},
+	} {
+		got := ShortenQuery(tc.query, tc.maxLen)
+		if got != tc.want {
+			t.Errorf("ShortenQuery(%q, %d) = %q, want %q", tc.query, tc.maxLen, got, tc.want)
+		}
+		if tc.maxLen > 0 && utf8.RuneCountInString(got) > tc.maxLen {
+			t.Errorf("ShortenQuery(%q, %d) is %d characters long", tc.query, tc.maxLen, utf8.RuneCountInString(got))
+		}
+	}
+}
+
+func TestMaxQueryLengthLooksThroughMiddleware(t *testing.T) {
+	github := NewGitHubFetcher(nil, NewEndpointPool("https://api.github.com", "priority"), nil)
+	wrapped := NewRateLimitedFetcher(github, ratelimit.NewLocalLimiter(nil))
+
+	if got := MaxQueryLength(wrapped); got != 256 {
+		t.Errorf("MaxQueryLength(rate limited github) = %d, want 256", got)
+	}
+	if got := MaxQueryLength(NewStackOverflowFetcher(nil, nil, true, nil)); got != 0 {
+		t.Errorf("MaxQueryLength(stackoverflow) = %d, want 0", got)
+	}
+	if long := strings.Repeat("go ", 200); len(ShortenQuery(long, MaxQueryLength(wrapped))) > 256 {
+		t.Errorf("shortened query is still over GitHub's limit")
+	}
+}
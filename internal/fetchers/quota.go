@@ -0,0 +1,270 @@
+package fetchers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/farhapartex/search-proxy/internal/models"
+	"github.com/farhapartex/search-proxy/internal/ratelimit"
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+// Quota is an upstream's own request allowance, as it last reported it:
+// GitHub and Reddit in response headers, Stack Overflow in the response
+// body. With several credentials it is the allowance of the one used last.
+type Quota struct {
+	// Limit is 0 when the platform doesn't report it
+	Limit     int
+	Remaining int
+	// Reset is when the allowance refills; zero when unknown
+	Reset time.Time
+}
+
+// QuotaStatus is a platform's quota and whether requests are held back to
+// save it
+type QuotaStatus struct {
+	Quota
+	Throttled bool
+}
+
+type quotaReporterKey struct{}
+
+// withQuotaReporter returns a context whose fetcher passes the quotas it
+// sees to report
+func withQuotaReporter(ctx context.Context, report func(Quota)) context.Context {
+	return context.WithValue(ctx, quotaReporterKey{}, report)
+}
+
+// reportQuota passes q to the reporter ctx carries, if any
+func reportQuota(ctx context.Context, q Quota) {
+	if report, ok := ctx.Value(quotaReporterKey{}).(func(Quota)); ok {
+		report(q)
+	}
+}
+
+// headerQuota reads GitHub-style rate limit headers, where the reset is a
+// Unix time
+func headerQuota(header http.Header) (Quota, bool) {
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return Quota{}, false
+	}
+	q := Quota{Remaining: remaining}
+	q.Limit, _ = strconv.Atoi(header.Get("X-RateLimit-Limit"))
+	if reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		q.Reset = time.Unix(reset, 0)
+	}
+	return q, true
+}
+
+// redditQuota reads Reddit's rate limit headers, which count requests as
+// decimals and give the reset in seconds from now
+func redditQuota(header http.Header) (Quota, bool) {
+	remaining, err := strconv.ParseFloat(header.Get("X-Ratelimit-Remaining"), 64)
+	if err != nil {
+		return Quota{}, false
+	}
+	q := Quota{Remaining: int(remaining)}
+	if used, err := strconv.ParseFloat(header.Get("X-Ratelimit-Used"), 64); err == nil {
+		q.Limit = int(used + remaining)
+	}
+	if reset, err := strconv.Atoi(header.Get("X-Ratelimit-Reset")); err == nil {
+		q.Reset = time.Now().Add(time.Duration(reset) * time.Second)
+	}
+	return q, true
+}
+
+// QuotaThrottle records the quota each platform reports and, when enabled,
+// holds requests back before it runs out: once the remaining requests fall
+// to reserve the platform is paused until its reset, and below
+// slowdownPercent of its limit the rest is spread evenly until the reset.
+// Refused requests fail with a ratelimit.LimitedError, as the local rate
+// limiter's do, rather than spending the last requests on rejections.
+type QuotaThrottle struct {
+	enabled         bool
+	reserve         int
+	slowdownPercent int
+	now             func() time.Time
+
+	mu     sync.Mutex
+	quotas map[string]*quotaState
+}
+
+type quotaState struct {
+	quota Quota
+	// lastSent is when the last request was let through
+	lastSent time.Time
+}
+
+// NewQuotaThrottle creates a throttle. A disabled one only records quotas.
+func NewQuotaThrottle(enabled bool, reserve, slowdownPercent int) *QuotaThrottle {
+	return &QuotaThrottle{
+		enabled:         enabled,
+		reserve:         reserve,
+		slowdownPercent: slowdownPercent,
+		now:             time.Now,
+		quotas:          make(map[string]*quotaState),
+	}
+}
+
+// Wrap is a Middleware tracking and throttling each fetcher's quota
+func (t *QuotaThrottle) Wrap(fetcher Fetcher) Fetcher {
+	return &QuotaFetcher{next: fetcher, throttle: t}
+}
+
+// Status returns the last quota platform reported, if any
+func (t *QuotaThrottle) Status(platform string) (QuotaStatus, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state, ok := t.current(platform)
+	if !ok {
+		return QuotaStatus{}, false
+	}
+	return QuotaStatus{Quota: state.quota, Throttled: t.check(platform, state) != nil}, true
+}
+
+// Statuses returns the quota of every platform that reported one
+func (t *QuotaThrottle) Statuses() map[string]QuotaStatus {
+	t.mu.Lock()
+	names := make([]string, 0, len(t.quotas))
+	for name := range t.quotas {
+		names = append(names, name)
+	}
+	t.mu.Unlock()
+
+	statuses := make(map[string]QuotaStatus, len(names))
+	for _, name := range names {
+		if status, ok := t.Status(name); ok {
+			statuses[name] = status
+		}
+	}
+	return statuses
+}
+
+// current returns platform's quota state, forgetting it once its reset has
+// passed. Callers hold t.mu.
+func (t *QuotaThrottle) current(platform string) (*quotaState, bool) {
+	state, ok := t.quotas[platform]
+	if !ok {
+		return nil, false
+	}
+	if !state.quota.Reset.IsZero() && !t.now().Before(state.quota.Reset) {
+		delete(t.quotas, platform)
+		return nil, false
+	}
+	return state, true
+}
+
+// check returns an error if a request to platform can't be sent now.
+// Callers hold t.mu.
+func (t *QuotaThrottle) check(platform string, state *quotaState) error {
+	// Without a reset time there's no telling when to try again
+	if !t.enabled || state.quota.Reset.IsZero() {
+		return nil
+	}
+
+	untilReset := state.quota.Reset.Sub(t.now())
+	spare := state.quota.Remaining - t.reserve
+	if spare <= 0 {
+		return &ratelimit.LimitedError{Platform: platform, RetryAfter: untilReset}
+	}
+	if state.quota.Limit <= 0 || state.quota.Remaining*100 >= state.quota.Limit*t.slowdownPercent {
+		return nil
+	}
+
+	// Nearly spent: one request per share of the time left
+	wait := untilReset/time.Duration(spare) - t.now().Sub(state.lastSent)
+	if wait > 0 {
+		return &ratelimit.LimitedError{Platform: platform, RetryAfter: wait}
+	}
+	return nil
+}
+
+// admit lets a request to platform through or refuses it. An admitted
+// request counts against the recorded quota until the platform reports a
+// fresh one.
+func (t *QuotaThrottle) admit(platform string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state, ok := t.current(platform)
+	if !ok {
+		return nil
+	}
+	if err := t.check(platform, state); err != nil {
+		return err
+	}
+	state.lastSent = t.now()
+	state.quota.Remaining = max(state.quota.Remaining-1, 0)
+	return nil
+}
+
+// record stores the quota platform just reported
+func (t *QuotaThrottle) record(platform string, q Quota) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state, ok := t.quotas[platform]
+	if !ok {
+		state = &quotaState{}
+		t.quotas[platform] = state
+	}
+	state.quota = q
+}
+
+// QuotaFetcher reports the quota the wrapped fetcher sees to its throttle,
+// and calls it only while the throttle allows
+type QuotaFetcher struct {
+	next     Fetcher
+	throttle *QuotaThrottle
+}
+
+// Name returns the platform name
+func (q *QuotaFetcher) Name() string {
+	return q.next.Name()
+}
+
+// Unwrap returns the wrapped fetcher
+func (q *QuotaFetcher) Unwrap() Fetcher {
+	return q.next
+}
+
+// Fetch retrieves search results unless the platform's quota is being saved
+func (q *QuotaFetcher) Fetch(ctx context.Context, query string, maxResults int) ([]*models.SearchResult, error) {
+	ctx, err := q.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return q.next.Fetch(ctx, query, maxResults)
+}
+
+// FetchProto is like Fetch but returns protobuf results
+func (q *QuotaFetcher) FetchProto(ctx context.Context, query string, maxResults int) ([]*pb.Result, error) {
+	ctx, err := q.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return FetchProto(ctx, q.next, query, maxResults)
+}
+
+// FetchDetails fetches a result's details unless the platform's quota is
+// being saved; the quota the upstream reports is recorded as for searches
+func (q *QuotaFetcher) FetchDetails(ctx context.Context, resultURL string, maxReplies int) (*pb.ResultDetailsResponse, error) {
+	ctx, err := q.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return FetchDetails(ctx, q.next, resultURL, maxReplies)
+}
+
+func (q *QuotaFetcher) begin(ctx context.Context) (context.Context, error) {
+	name := q.Name()
+	if err := q.throttle.admit(name); err != nil {
+		return ctx, err
+	}
+	return withQuotaReporter(ctx, func(quota Quota) { q.throttle.record(name, quota) }), nil
+}
@@ -0,0 +1,119 @@
+package fetchers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/farhapartex/search-proxy/internal/ratelimit"
+	"github.com/farhapartex/search-proxy/internal/testutil"
+)
+
+func TestQuotaThrottlePausesAtReserve(t *testing.T) {
+	now := time.Now()
+	throttle := NewQuotaThrottle(true, 1, 0)
+	throttle.now = func() time.Time { return now }
+	throttle.record("github", Quota{Limit: 30, Remaining: 2, Reset: now.Add(time.Minute)})
+
+	if err := throttle.admit("github"); err != nil {
+		t.Fatalf("admit() with a spare request error = %v", err)
+	}
+	var limited *ratelimit.LimitedError
+	if err := throttle.admit("github"); !errors.As(err, &limited) || limited.RetryAfter != time.Minute {
+		t.Fatalf("admit() at the reserve error = %v, want LimitedError retrying at the reset", err)
+	}
+	if status, _ := throttle.Status("github"); !status.Throttled || status.Remaining != 1 {
+		t.Errorf("Status() = %+v, want 1 left and throttled", status)
+	}
+
+	now = now.Add(time.Minute)
+	if err := throttle.admit("github"); err != nil {
+		t.Errorf("admit() after the reset error = %v", err)
+	}
+	if _, ok := throttle.Status("github"); ok {
+		t.Error("Status() still reports the quota after its reset")
+	}
+}
+
+func TestQuotaThrottlePacesNearlySpentQuota(t *testing.T) {
+	now := time.Now()
+	throttle := NewQuotaThrottle(true, 1, 10)
+	throttle.now = func() time.Time { return now }
+
+	// Plenty left: no pacing
+	throttle.record("reddit", Quota{Limit: 100, Remaining: 50, Reset: now.Add(time.Minute)})
+	for range 2 {
+		if err := throttle.admit("reddit"); err != nil {
+			t.Fatalf("admit() with half the quota left error = %v", err)
+		}
+	}
+
+	// 5 left, 1 in reserve: one request per 10s of the 40s left
+	now = now.Add(20 * time.Second)
+	throttle.record("reddit", Quota{Limit: 100, Remaining: 5, Reset: now.Add(40 * time.Second)})
+	if err := throttle.admit("reddit"); err != nil {
+		t.Fatalf("first paced admit() error = %v", err)
+	}
+	var limited *ratelimit.LimitedError
+	if err := throttle.admit("reddit"); !errors.As(err, &limited) || limited.RetryAfter <= 0 {
+		t.Fatalf("immediate second admit() error = %v, want LimitedError", err)
+	}
+
+	now = now.Add(limited.RetryAfter)
+	if err := throttle.admit("reddit"); err != nil {
+		t.Errorf("admit() after waiting error = %v", err)
+	}
+}
+
+func TestQuotaThrottleDisabledOnlyRecords(t *testing.T) {
+	throttle := NewQuotaThrottle(false, 1, 10)
+	throttle.record("github", Quota{Limit: 30, Remaining: 0, Reset: time.Now().Add(time.Minute)})
+
+	if err := throttle.admit("github"); err != nil {
+		t.Errorf("admit() error = %v, want a disabled throttle to let requests through", err)
+	}
+	if status, ok := throttle.Status("github"); !ok || status.Throttled {
+		t.Errorf("Status() = %+v, %t; want the quota, not throttled", status, ok)
+	}
+}
+
+func TestQuotaFetcherStopsBeforeExhaustedQuota(t *testing.T) {
+	upstream := testutil.NewGitHub(t)
+	upstream.Set(testutil.Behavior{RateLimited: true})
+	throttle := NewQuotaThrottle(true, 0, 10)
+	fetcher := throttle.Wrap(newTestGitHubFetcher(upstream))
+
+	var statusErr *StatusError
+	if _, err := fetcher.Fetch(context.Background(), "go", 5); !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusForbidden {
+		t.Fatalf("first Fetch() error = %v, want the upstream's 403", err)
+	}
+	if status, ok := throttle.Status("github"); !ok || status.Limit != 60 || status.Remaining != 0 || !status.Throttled {
+		t.Fatalf("Status() = %+v, %t; want the reported 0 of 60, throttled", status, ok)
+	}
+
+	if _, err := fetcher.Fetch(context.Background(), "go", 5); !errors.Is(err, ratelimit.ErrLimited) {
+		t.Errorf("second Fetch() error = %v, want it refused locally", err)
+	}
+	if n := len(upstream.Requests()); n != 1 {
+		t.Errorf("upstream saw %d requests, want 1", n)
+	}
+}
+
+func TestStackOverflowFetcherReportsQuota(t *testing.T) {
+	upstream := testutil.NewStackOverflow(t)
+	throttle := NewQuotaThrottle(true, 1, 10)
+	fetcher := throttle.Wrap(NewStackOverflowFetcher(nil, NewEndpointPool(upstream.URL(), SelectPriority), false, http.DefaultClient))
+
+	if _, err := FetchProto(context.Background(), fetcher, "go", 5); err != nil {
+		t.Fatalf("FetchProto() error = %v", err)
+	}
+	status, ok := throttle.Status("stackoverflow")
+	if !ok || status.Limit != 300 || status.Remaining != 299 || status.Throttled {
+		t.Errorf("Status() = %+v, %t; want 299 of 300 left", status, ok)
+	}
+	if status.Reset.UTC().Hour() != 0 || !status.Reset.After(time.Now()) {
+		t.Errorf("Reset = %v, want the next midnight UTC", status.Reset)
+	}
+}
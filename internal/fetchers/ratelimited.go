@@ -0,0 +1,78 @@
+package fetchers
+
+import (
+	"context"
+
+	"github.com/farhapartex/search-proxy/internal/logging"
+	"github.com/farhapartex/search-proxy/internal/models"
+	"github.com/farhapartex/search-proxy/internal/ratelimit"
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+// RateLimitedFetcher checks a shared request budget before calling the
+// wrapped fetcher, so the upstream quota is never exceeded
+type RateLimitedFetcher struct {
+	next    Fetcher
+	limiter ratelimit.Limiter
+}
+
+// NewRateLimitedFetcher wraps next with limiter
+func NewRateLimitedFetcher(next Fetcher, limiter ratelimit.Limiter) *RateLimitedFetcher {
+	return &RateLimitedFetcher{
+		next:    next,
+		limiter: limiter,
+	}
+}
+
+// Name returns the platform name
+func (r *RateLimitedFetcher) Name() string {
+	return r.next.Name()
+}
+
+// Unwrap returns the wrapped fetcher
+func (r *RateLimitedFetcher) Unwrap() Fetcher {
+	return r.next
+}
+
+// Fetch retrieves search results if the platform still has budget left
+func (r *RateLimitedFetcher) Fetch(ctx context.Context, query string, maxResults int) ([]*models.SearchResult, error) {
+	if err := r.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	return r.next.Fetch(ctx, query, maxResults)
+}
+
+// FetchProto is like Fetch but returns protobuf results
+func (r *RateLimitedFetcher) FetchProto(ctx context.Context, query string, maxResults int) ([]*pb.Result, error) {
+	if err := r.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	return FetchProto(ctx, r.next, query, maxResults)
+}
+
+// FetchDetails fetches a result's details against the same budget as
+// searches
+func (r *RateLimitedFetcher) FetchDetails(ctx context.Context, resultURL string, maxReplies int) (*pb.ResultDetailsResponse, error) {
+	if err := r.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	return FetchDetails(ctx, r.next, resultURL, maxReplies)
+}
+
+// wait consumes one request from the platform budget, returning an error if
+// the budget is exhausted
+func (r *RateLimitedFetcher) wait(ctx context.Context) error {
+	allowed, retryAfter, err := r.limiter.Allow(ctx, r.Name())
+	if err != nil {
+		// Fail open: a limiter outage shouldn't take search down with it
+		logging.Printf(ctx, "WARNING: rate limiter unavailable for %s: %v", r.Name(), err)
+		return nil
+	}
+	if !allowed {
+		return &ratelimit.LimitedError{Platform: r.Name(), RetryAfter: retryAfter}
+	}
+	return nil
+}
@@ -3,36 +3,70 @@ package fetchers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"html"
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/farhapartex/search-proxy/internal/models"
+	dsl "github.com/farhapartex/search-proxy/internal/query"
+	"github.com/farhapartex/search-proxy/internal/sanitize"
+	pb "github.com/farhapartex/search-proxy/proto"
 )
 
+// Reddit serves its public JSON endpoints and its OAuth token endpoint from
+// www.reddit.com, and the authenticated API from oauth.reddit.com
+const (
+	redditOAuthHost  = "oauth.reddit.com"
+	redditPublicURL  = "https://www.reddit.com"
+	redditTokenPath  = "/api/v1/access_token"
+	redditSearchPath = "/search.json"
+)
+
+// redditTokenSlack renews access tokens this long before they expire, so a
+// token never runs out during a search
+const redditTokenSlack = time.Minute
+
 // RedditFetcher fetches search results from Reddit
 type RedditFetcher struct {
-	clientID     string
-	clientSecret string
-	userAgent    string
-	baseURL      string
-	client       *http.Client
-	accessToken  string
-	tokenExpiry  time.Time
+	clientID      string
+	clientSecret  string
+	userAgent     string
+	baseURL       string
+	snippetFormat string
+	client        *http.Client
+
+	// mu guards the client credentials and the cached token; it is held
+	// while a new token is fetched so concurrent searches wait for it
+	// rather than each asking for one
+	mu          sync.Mutex
+	accessToken string
+	tokenExpiry time.Time
 }
 
-// NewRedditFetcher creates a new Reddit fetcher
-func NewRedditFetcher(clientID, clientSecret, userAgent, baseURL string) *RedditFetcher {
+// NewRedditFetcher creates a new Reddit fetcher. With a client ID and secret
+// it searches the OAuth API at baseURL, which has far higher rate limits;
+// without them it uses the public JSON endpoint. Reddit splits its API
+// across hosts, so any base URL other than oauth.reddit.com (a proxy or a
+// fake) must serve the token endpoint and, for unauthenticated use, search
+// as well. snippetFormat is one of SnippetPlain (the default when empty),
+// SnippetMarkdown or SnippetHTML.
+func NewRedditFetcher(clientID, clientSecret, userAgent, baseURL, snippetFormat string, client *http.Client) *RedditFetcher {
+	if snippetFormat == "" {
+		snippetFormat = SnippetPlain
+	}
 	return &RedditFetcher{
-		clientID:     clientID,
-		clientSecret: clientSecret,
-		userAgent:    userAgent,
-		baseURL:      baseURL,
-		client: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+		clientID:      clientID,
+		clientSecret:  clientSecret,
+		userAgent:     userAgent,
+		baseURL:       baseURL,
+		snippetFormat: snippetFormat,
+		client:        client,
 	}
 }
 
@@ -41,87 +75,514 @@ func (r *RedditFetcher) Name() string {
 	return "reddit"
 }
 
+// MaxQueryLength is Reddit's search query limit
+func (r *RedditFetcher) MaxQueryLength() int {
+	return 512
+}
+
+// SnippetFormat is the configured REDDIT_SNIPPET_FORMAT
+func (r *RedditFetcher) SnippetFormat() string {
+	return r.snippetFormat
+}
+
 // Fetch retrieves search results from Reddit
 func (r *RedditFetcher) Fetch(ctx context.Context, query string, maxResults int) ([]*models.SearchResult, error) {
-	// For simplicity, use the public JSON endpoint (no OAuth required)
-	// This works without authentication but has lower rate limits
-	searchURL := fmt.Sprintf("https://www.reddit.com/search.json?q=%s&limit=%d&sort=relevance",
-		url.QueryEscape(query),
+	children, err := r.search(ctx, query, maxResults)
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert to internal format
+	results := make([]*models.SearchResult, 0, len(children))
+	for _, child := range children {
+		post := &child.Data
+		result := models.NewSearchResult(
+			"reddit",
+			post.Title,
+			post.snippet(r.snippetFormat),
+			post.permalinkURL(),
+		)
+		result.SetTimes(post.times())
+		result.ThumbnailURL = post.thumbnailURL()
+		result.Author = post.author()
+		result.Type = pb.ResultType_RESULT_TYPE_POST
+		result.Metadata = post.metadata()
+		result.Reddit = post.details()
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// FetchProto retrieves search results from Reddit as protobuf results
+func (r *RedditFetcher) FetchProto(ctx context.Context, query string, maxResults int) ([]*pb.Result, error) {
+	children, err := r.search(ctx, query, maxResults)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*pb.Result, len(children))
+	for i := range children {
+		post := &children[i].Data
+		created, updated := post.times()
+		results[i] = &pb.Result{
+			Platform:        "reddit",
+			Title:           post.Title,
+			Snippet:         post.snippet(r.snippetFormat),
+			Url:             post.permalinkURL(),
+			Timestamp:       created,
+			CreatedAt:       created,
+			UpdatedAt:       updated,
+			Metadata:        post.metadata(),
+			ThumbnailUrl:    post.thumbnailURL(),
+			Author:          post.author().ToProto(),
+			ResultType:      pb.ResultType_RESULT_TYPE_POST,
+			PlatformDetails: &pb.Result_Reddit{Reddit: post.details()},
+		}
+	}
+
+	return results, nil
+}
+
+func (r *RedditFetcher) search(ctx context.Context, query string, maxResults int) ([]RedditChild, error) {
+	var children []RedditChild
+	err := r.call(ctx, func(baseURL, token string) error {
+		var err error
+		children, err = r.searchAt(ctx, baseURL, token, query, maxResults)
+		return err
+	})
+	return children, err
+}
+
+// call calls fn with the API's base URL and, when client credentials are
+// configured, an access token
+func (r *RedditFetcher) call(ctx context.Context, fn func(baseURL, token string) error) error {
+	attempt := func() (string, error) {
+		token, err := r.token(ctx)
+		if err != nil {
+			return "", err
+		}
+		if token == "" {
+			return "", fn(r.publicURL(), "")
+		}
+		return token, fn(r.baseURL, token)
+	}
+
+	token, err := attempt()
+
+	// Reddit may revoke a token before it expires; one fresh token is
+	// worth a retry
+	var statusErr *StatusError
+	if token != "" && errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusUnauthorized {
+		r.invalidateToken(token)
+		_, err = attempt()
+	}
+	return err
+}
+
+// redditQuery keeps the subreddit terms, which Reddit's search reads as its
+// own operator, and drops the DSL terms it has no equivalent for
+func redditQuery(q dsl.Query) string {
+	subreddits := q.Values(dsl.Subreddit)
+	for i, name := range subreddits {
+		subreddits[i] = "subreddit:" + name
+	}
+	return q.With(subreddits...)
+}
+
+// redditPeriods are the time filters Reddit search offers, shortest first
+var redditPeriods = []struct {
+	name   string
+	length time.Duration
+}{
+	{"hour", time.Hour},
+	{"day", 24 * time.Hour},
+	{"week", 7 * 24 * time.Hour},
+	{"month", 31 * 24 * time.Hour},
+	{"year", 366 * 24 * time.Hour},
+}
+
+// redditPeriod is the shortest time filter reaching back to from, or empty
+// when none does. Reddit has no other date filter, so the posts it returns
+// are still checked against the exact range.
+func redditPeriod(from, now time.Time) string {
+	if from.IsZero() {
+		return ""
+	}
+	for _, period := range redditPeriods {
+		if now.Sub(from) <= period.length {
+			return period.name
+		}
+	}
+	return ""
+}
+
+// inRange keeps the posts created within [from, to), and those without a
+// creation time; zero bounds are open
+func inRange(children []RedditChild, from, to time.Time) []RedditChild {
+	if from.IsZero() && to.IsZero() {
+		return children
+	}
+	kept := children[:0]
+	for _, child := range children {
+		created := time.Unix(int64(child.Data.CreatedUTC), 0)
+		if child.Data.CreatedUTC == 0 ||
+			(from.IsZero() || !created.Before(from)) && (to.IsZero() || created.Before(to)) {
+			kept = append(kept, child)
+		}
+	}
+	return kept
+}
+
+func (r *RedditFetcher) searchAt(ctx context.Context, baseURL, token, query string, maxResults int) ([]RedditChild, error) {
+	q := dsl.Parse(query)
+	from, to := createdRange(q, RequestOptionsFrom(ctx))
+	searchURL := fmt.Sprintf("%s%s?q=%s&limit=%d&sort=relevance",
+		baseURL,
+		redditSearchPath,
+		url.QueryEscape(redditQuery(q)),
 		maxResults,
 	)
+	if period := redditPeriod(from, time.Now()); period != "" {
+		searchURL += "&t=" + period
+	}
+	// Reddit pages by the fullname of the last post seen
+	page := PageFrom(ctx)
+	if page.Cursor != "" {
+		searchURL += "&after=" + url.QueryEscape(page.Cursor)
+	}
 
+	var redditResp RedditSearchResponse
+	if err := r.get(ctx, searchURL, token, &redditResp); err != nil {
+		return nil, err
+	}
+	page.Next = redditResp.Data.After
+	return inRange(redditResp.Data.Children, from, to), nil
+}
+
+// get calls the API and decodes its answer into out
+func (r *RedditFetcher) get(ctx context.Context, apiURL, token string, out any) error {
 	// Create request
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, searchURL, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Add headers
 	req.Header.Set("User-Agent", r.userAgent)
 	req.Header.Set("Accept", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
 
 	// Execute request
 	resp, err := r.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
+		return fmt.Errorf("failed to execute request: %w", err)
 	}
 	defer resp.Body.Close()
+	if quota, ok := redditQuota(resp.Header); ok {
+		reportQuota(ctx, quota)
+	}
 
 	// Check status code
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("Reddit API error: status=%d, body=%s", resp.StatusCode, string(body))
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+		return &StatusError{
+			Platform:   "Reddit",
+			StatusCode: resp.StatusCode,
+			Body:       string(body),
+			RetryAfter: retryAfter(resp.Header),
+		}
 	}
 
 	// Parse response
-	var redditResp RedditSearchResponse
-	if err := json.NewDecoder(resp.Body).Decode(&redditResp); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
 	}
+	return nil
+}
 
-	// Convert to internal format
-	results := make([]*models.SearchResult, 0, len(redditResp.Data.Children))
-	for _, child := range redditResp.Data.Children {
-		post := child.Data
+// FetchDetails returns a post with up to maxReplies of its top-level
+// comments, highest scored first. Link posts have the page they link to as
+// their content.
+func (r *RedditFetcher) FetchDetails(ctx context.Context, resultURL string, maxReplies int) (*pb.ResultDetailsResponse, error) {
+	id, err := redditPostID(resultURL)
+	if err != nil {
+		return nil, err
+	}
 
-		// Build Reddit URL
-		postURL := fmt.Sprintf("https://www.reddit.com%s", post.Permalink)
+	var listings []redditCommentsListing
+	err = r.call(ctx, func(baseURL, token string) error {
+		// raw_json=1 leaves the markdown unescaped; depth=1 skips replies
+		// to comments
+		commentsURL := fmt.Sprintf("%s/comments/%s.json?sort=top&depth=1&limit=%d&raw_json=1",
+			baseURL, url.PathEscape(id), max(maxReplies, 1))
+		return r.get(ctx, commentsURL, token, &listings)
+	})
+	if err != nil {
+		return nil, err
+	}
+	// The post's listing comes first, its comments' second
+	if len(listings) == 0 || len(listings[0].Data.Children) == 0 {
+		return nil, &StatusError{Platform: "Reddit", StatusCode: http.StatusNotFound, Body: "post not found"}
+	}
+
+	var post RedditPost
+	if err := json.Unmarshal(listings[0].Data.Children[0].Data, &post); err != nil {
+		return nil, fmt.Errorf("failed to decode post: %w", err)
+	}
+	created, _ := post.times()
+	details := &pb.ResultDetailsResponse{
+		Platform:      "reddit",
+		Url:           post.permalinkURL(),
+		Title:         post.Title,
+		Content:       post.Selftext,
+		ContentFormat: SnippetMarkdown,
+		Author:        post.author().ToProto(),
+		CreatedAt:     created,
+	}
+	if details.Content == "" {
+		_, details.Content = post.link()
+	}
 
-		// Create snippet from selftext or title
-		snippet := post.Selftext
-		if snippet == "" {
-			snippet = post.Title
+	if len(listings) < 2 {
+		return details, nil
+	}
+	for _, child := range listings[1].Data.Children {
+		if len(details.Replies) >= maxReplies {
+			break
+		}
+		// "more" entries stand for comments left out of the listing
+		if child.Kind != "t1" {
+			continue
 		}
+		var comment RedditComment
+		if err := json.Unmarshal(child.Data, &comment); err != nil {
+			return nil, fmt.Errorf("failed to decode comment: %w", err)
+		}
+		if comment.Body == "[deleted]" || comment.Body == "[removed]" {
+			continue
+		}
+		details.Replies = append(details.Replies, comment.reply())
+	}
+	return details, nil
+}
 
-		result := models.NewSearchResult(
-			"reddit",
-			post.Title,
-			TruncateString(snippet, 500),
-			postURL,
-		)
-		result.Timestamp = int64(post.CreatedUTC)
-		result.Metadata = map[string]string{
-			"score":         fmt.Sprintf("%d", post.Score),
-			"num_comments":  fmt.Sprintf("%d", post.NumComments),
-			"subreddit":     post.Subreddit,
-			"author":        post.Author,
-			"upvote_ratio":  fmt.Sprintf("%.2f", post.UpvoteRatio),
+// redditPostID reads the post ID from a Reddit URL, either
+// /r/{subreddit}/comments/{id}/{slug} or /comments/{id}, on any reddit.com
+// host, or a redd.it short link
+func redditPostID(resultURL string) (string, error) {
+	host, segments, err := resultPath(resultURL)
+	if err != nil {
+		return "", err
+	}
+	if host == "redd.it" && len(segments) == 1 {
+		return segments[0], nil
+	}
+	if host != "reddit.com" && !strings.HasSuffix(host, ".reddit.com") {
+		return "", ErrUnrecognizedURL
+	}
+	if len(segments) >= 2 && segments[0] == "r" {
+		segments = segments[2:]
+	}
+	if len(segments) < 2 || segments[0] != "comments" {
+		return "", ErrUnrecognizedURL
+	}
+	return segments[1], nil
+}
+
+// authenticated reports whether client credentials are configured. The
+// caller holds r.mu.
+func (r *RedditFetcher) authenticated() bool {
+	return r.clientID != "" && r.clientSecret != ""
+}
+
+// SplitRedditCredential splits a "client_id:client_secret" credential, as
+// given to Replace and ValidateCredential
+func SplitRedditCredential(credential string) (clientID, clientSecret string, ok bool) {
+	clientID, clientSecret, ok = strings.Cut(credential, ":")
+	return clientID, clientSecret, ok && clientID != "" && clientSecret != ""
+}
+
+// Replace swaps in a new "client_id:client_secret" pair, for rotation
+// without a restart, and drops the token issued for the old one. An empty
+// or malformed value leaves only the public API.
+func (r *RedditFetcher) Replace(credential string) {
+	clientID, clientSecret, _ := SplitRedditCredential(credential)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.clientID, r.clientSecret = clientID, clientSecret
+	r.accessToken = ""
+	r.tokenExpiry = time.Time{}
+}
+
+// Len is 1 when client credentials are configured, 0 otherwise
+func (r *RedditFetcher) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.authenticated() {
+		return 1
+	}
+	return 0
+}
+
+// ValidateCredential asks the token endpoint for an access token with a
+// "client_id:client_secret" pair, without caching it
+func (r *RedditFetcher) ValidateCredential(ctx context.Context, credential string) error {
+	clientID, clientSecret, ok := SplitRedditCredential(credential)
+	if !ok {
+		return errors.New("reddit credential must be client_id:client_secret")
+	}
+	_, _, err := r.requestToken(ctx, clientID, clientSecret)
+	return err
+}
+
+// publicURL is where unauthenticated searches go
+func (r *RedditFetcher) publicURL() string {
+	if r.usesRedditHosts() {
+		return redditPublicURL
+	}
+	return r.baseURL
+}
+
+// tokenURL is where access tokens come from
+func (r *RedditFetcher) tokenURL() string {
+	if r.usesRedditHosts() {
+		return redditPublicURL + redditTokenPath
+	}
+	return r.baseURL + redditTokenPath
+}
+
+func (r *RedditFetcher) usesRedditHosts() bool {
+	u, err := url.Parse(r.baseURL)
+	return r.baseURL == "" || (err == nil && u.Hostname() == redditOAuthHost)
+}
+
+// token returns a cached access token, fetching a new one with the client
+// credentials once it is about to expire, or "" when no credentials are
+// configured
+func (r *RedditFetcher) token(ctx context.Context) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.authenticated() {
+		return "", nil
+	}
+	if r.accessToken != "" && time.Now().Before(r.tokenExpiry.Add(-redditTokenSlack)) {
+		return r.accessToken, nil
+	}
+
+	token, expiresIn, err := r.requestToken(ctx, r.clientID, r.clientSecret)
+	if err != nil {
+		return "", err
+	}
+	r.accessToken = token
+	r.tokenExpiry = time.Now().Add(expiresIn)
+	return r.accessToken, nil
+}
+
+// requestToken asks the token endpoint for an access token with the client
+// credentials grant
+func (r *RedditFetcher) requestToken(ctx context.Context, clientID, clientSecret string) (string, time.Duration, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.tokenURL(), strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.SetBasicAuth(clientID, clientSecret)
+	req.Header.Set("User-Agent", r.userAgent)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to request access token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+		return "", 0, &StatusError{
+			Platform:           "Reddit",
+			StatusCode:         resp.StatusCode,
+			Body:               string(body),
+			RetryAfter:         retryAfter(resp.Header),
+			CredentialRejected: resp.StatusCode == http.StatusUnauthorized,
 		}
-		results = append(results, result)
 	}
 
-	return results, nil
+	// Reddit answers bad credentials with 200 and an error field
+	var tokenResp struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+		Error       string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		return "", 0, fmt.Errorf("failed to decode access token: %w", err)
+	}
+	if tokenResp.AccessToken == "" {
+		return "", 0, &StatusError{
+			Platform:           "Reddit",
+			StatusCode:         http.StatusUnauthorized,
+			Body:               tokenResp.Error,
+			CredentialRejected: true,
+		}
+	}
+
+	return tokenResp.AccessToken, time.Duration(tokenResp.ExpiresIn) * time.Second, nil
+}
+
+// invalidateToken drops token from the cache unless it was already replaced
+func (r *RedditFetcher) invalidateToken(token string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.accessToken == token {
+		r.accessToken = ""
+	}
 }
 
 // RedditSearchResponse represents the Reddit API search response
 type RedditSearchResponse struct {
 	Kind string `json:"kind"`
 	Data struct {
-		After    string         `json:"after"`
-		Children []RedditChild  `json:"children"`
+		After    string        `json:"after"`
+		Children []RedditChild `json:"children"`
+	} `json:"data"`
+}
+
+// redditCommentsListing is one of the two listings a comments page
+// returns; their children are decoded once their kind is known
+type redditCommentsListing struct {
+	Data struct {
+		Children []struct {
+			Kind string          `json:"kind"`
+			Data json.RawMessage `json:"data"`
+		} `json:"children"`
 	} `json:"data"`
 }
 
+// RedditComment is a comment on a post
+type RedditComment struct {
+	ID         string  `json:"id"`
+	Body       string  `json:"body"`
+	Author     string  `json:"author"`
+	Score      int     `json:"score"`
+	CreatedUTC float64 `json:"created_utc"`
+	Permalink  string  `json:"permalink"`
+}
+
+func (c *RedditComment) reply() *pb.Reply {
+	return &pb.Reply{
+		Content:   c.Body,
+		Author:    redditAuthor(c.Author).ToProto(),
+		Score:     int32(c.Score),
+		CreatedAt: models.UnixSecondsFloat(c.CreatedUTC),
+		Url:       "https://www.reddit.com" + c.Permalink,
+	}
+}
+
 // RedditChild represents a child item in Reddit response
 type RedditChild struct {
 	Kind string     `json:"kind"`
@@ -130,15 +591,139 @@ type RedditChild struct {
 
 // RedditPost represents a Reddit post in search results
 type RedditPost struct {
-	ID           string  `json:"id"`
-	Title        string  `json:"title"`
-	Selftext     string  `json:"selftext"`
-	Author       string  `json:"author"`
-	Subreddit    string  `json:"subreddit"`
-	Score        int     `json:"score"`
-	NumComments  int     `json:"num_comments"`
-	CreatedUTC   float64 `json:"created_utc"`
-	Permalink    string  `json:"permalink"`
-	URL          string  `json:"url"`
-	UpvoteRatio  float64 `json:"upvote_ratio"`
+	ID           string       `json:"id"`
+	Title        string       `json:"title"`
+	Selftext     string       `json:"selftext"`
+	SelftextHTML string       `json:"selftext_html"`
+	Author       string       `json:"author"`
+	Subreddit    string       `json:"subreddit"`
+	Score        int          `json:"score"`
+	NumComments  int          `json:"num_comments"`
+	CreatedUTC   float64      `json:"created_utc"`
+	Edited       redditEdited `json:"edited"`
+	Permalink    string       `json:"permalink"`
+	URL          string       `json:"url"`
+	// Domain is the host a link post points to, or "self.<subreddit>"
+	Domain      string  `json:"domain"`
+	UpvoteRatio float64 `json:"upvote_ratio"`
+	// Thumbnail is a URL, or a placeholder such as "self" or "nsfw"
+	Thumbnail string `json:"thumbnail"`
+	Preview   struct {
+		Images []struct {
+			Source struct {
+				URL string `json:"url"`
+			} `json:"source"`
+		} `json:"images"`
+	} `json:"preview"`
+}
+
+// snippet renders the selftext in the given format, falling back to the
+// title for link posts. HTML is left for the search handler to clean.
+func (p *RedditPost) snippet(format string) string {
+	switch {
+	case p.Selftext == "" && format == SnippetHTML:
+		return html.EscapeString(p.Title)
+	case p.Selftext == "":
+		return p.Title
+	case format == SnippetMarkdown:
+		return p.Selftext
+	case format == SnippetHTML && p.SelftextHTML != "":
+		// Reddit sends the rendered HTML escaped
+		return html.UnescapeString(p.SelftextHTML)
+	case format == SnippetHTML:
+		return "<p>" + html.EscapeString(sanitize.StripMarkdown(p.Selftext)) + "</p>"
+	default:
+		return sanitize.StripMarkdown(p.Selftext)
+	}
+}
+
+// times returns the creation and last edit times in Unix seconds
+func (p *RedditPost) times() (created, updated int64) {
+	created = models.UnixSecondsFloat(p.CreatedUTC)
+	return created, max(models.UnixSecondsFloat(float64(p.Edited)), created)
+}
+
+// redditEdited is Reddit's "edited" field: false for posts that were never
+// edited, otherwise the edit time in fractional Unix seconds
+type redditEdited float64
+
+func (e *redditEdited) UnmarshalJSON(data []byte) error {
+	var seconds float64
+	if err := json.Unmarshal(data, &seconds); err != nil {
+		// false (or anything else that isn't a time) means never edited
+		*e = 0
+		return nil
+	}
+	*e = redditEdited(seconds)
+	return nil
+}
+
+// thumbnailURL prefers the full-size preview over the small thumbnail, and
+// ignores Reddit's placeholder values
+func (p *RedditPost) thumbnailURL() string {
+	if images := p.Preview.Images; len(images) > 0 && images[0].Source.URL != "" {
+		// Preview URLs come HTML-escaped
+		return html.UnescapeString(images[0].Source.URL)
+	}
+	if strings.HasPrefix(p.Thumbnail, "https://") || strings.HasPrefix(p.Thumbnail, "http://") {
+		return p.Thumbnail
+	}
+	return ""
+}
+
+func (p *RedditPost) author() *models.Author {
+	return redditAuthor(p.Author)
+}
+
+// redditAuthor is nil for deleted accounts
+func redditAuthor(name string) *models.Author {
+	if name == "" || name == "[deleted]" {
+		return nil
+	}
+	return &models.Author{
+		Name:       name,
+		Handle:     name,
+		ProfileURL: "https://www.reddit.com/user/" + name,
+	}
+}
+
+func (p *RedditPost) permalinkURL() string {
+	return fmt.Sprintf("https://www.reddit.com%s", p.Permalink)
+}
+
+func (p *RedditPost) metadata() map[string]string {
+	metadata := map[string]string{
+		"score":        fmt.Sprintf("%d", p.Score),
+		"num_comments": fmt.Sprintf("%d", p.NumComments),
+		"subreddit":    p.Subreddit,
+		"upvote_ratio": fmt.Sprintf("%.2f", p.UpvoteRatio),
+	}
+	if domain, link := p.link(); domain != "" {
+		metadata["link_domain"] = domain
+		if link != "" {
+			metadata["link_url"] = link
+		}
+	}
+	return metadata
+}
+
+func (p *RedditPost) details() *pb.RedditMeta {
+	domain, link := p.link()
+	return &pb.RedditMeta{
+		Score:       int32(p.Score),
+		NumComments: int32(p.NumComments),
+		Subreddit:   p.Subreddit,
+		UpvoteRatio: float32(p.UpvoteRatio),
+		LinkDomain:  domain,
+		LinkUrl:     link,
+	}
+}
+
+// link returns the site and page a link post points to. The result links
+// to the discussion; this is what it discusses. Text posts have neither.
+func (p *RedditPost) link() (domain, page string) {
+	if p.Domain == "" || strings.HasPrefix(p.Domain, "self.") {
+		return "", ""
+	}
+	return p.Domain, p.URL
 }
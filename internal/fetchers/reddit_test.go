@@ -0,0 +1,214 @@
+package fetchers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/farhapartex/search-proxy/internal/sanitize"
+	"github.com/farhapartex/search-proxy/internal/testutil"
+)
+
+func TestRedditFetcherReturnsResults(t *testing.T) {
+	upstream := testutil.NewReddit(t)
+	fetcher := NewRedditFetcher("", "", "test-agent/1.0", upstream.URL(), "", upstream.Client())
+
+	results, err := fetcher.Fetch(context.Background(), "channels", 2)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("len(results) = %d, want 2", len(results))
+	}
+	if want := "https://www.reddit.com/r/golang/comments/fake1/"; results[0].URL != want {
+		t.Errorf("results[0].URL = %q, want %q", results[0].URL, want)
+	}
+	if results[0].Metadata["subreddit"] != "golang" {
+		t.Errorf("results[0].Metadata = %v, want subreddit golang", results[0].Metadata)
+	}
+}
+
+func TestRedditFetcherReportsServerErrors(t *testing.T) {
+	upstream := testutil.NewReddit(t)
+	upstream.Set(testutil.Behavior{StatusCode: http.StatusServiceUnavailable})
+	fetcher := NewRedditFetcher("", "", "test-agent/1.0", upstream.URL(), "", upstream.Client())
+
+	_, err := fetcher.Fetch(context.Background(), "channels", 5)
+	if err == nil || !strings.Contains(err.Error(), "status=503") {
+		t.Fatalf("Fetch() error = %v, want a 503 error", err)
+	}
+}
+
+func TestRedditPostTimes(t *testing.T) {
+	for _, tc := range []struct {
+		data        string
+		wantUpdated int64
+	}{
+		{`{"created_utc": 1700000000.5, "edited": false}`, 1700000000},
+		{`{"created_utc": 1700000000.5, "edited": 1700086400.25}`, 1700086400},
+		{`{"created_utc": 1700000000.5}`, 1700000000},
+	} {
+		var post RedditPost
+		if err := json.Unmarshal([]byte(tc.data), &post); err != nil {
+			t.Fatalf("Unmarshal(%s) error = %v", tc.data, err)
+		}
+
+		created, updated := post.times()
+		if created != 1700000000 || updated != tc.wantUpdated {
+			t.Errorf("times() for %s = (%d, %d), want (1700000000, %d)", tc.data, created, updated, tc.wantUpdated)
+		}
+	}
+}
+
+func TestRedditTimeRange(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	for from, want := range map[time.Time]string{
+		{}:                             "",
+		now.Add(-30 * time.Minute):     "hour",
+		now.Add(-3 * 24 * time.Hour):   "week",
+		now.Add(-400 * 24 * time.Hour): "",
+	} {
+		if got := redditPeriod(from, now); got != want {
+			t.Errorf("redditPeriod(%v) = %q, want %q", now.Sub(from), got, want)
+		}
+	}
+
+	children := []RedditChild{
+		{Data: RedditPost{ID: "old", CreatedUTC: 1600000000}},
+		{Data: RedditPost{ID: "in", CreatedUTC: 1650000000}},
+		{Data: RedditPost{ID: "undated"}},
+		{Data: RedditPost{ID: "new", CreatedUTC: 1700000000}},
+	}
+	var ids []string
+	for _, child := range inRange(children, time.Unix(1650000000, 0), time.Unix(1700000000, 0)) {
+		ids = append(ids, child.Data.ID)
+	}
+	if strings.Join(ids, ",") != "in,undated" {
+		t.Errorf("inRange() kept %v, want in and undated", ids)
+	}
+}
+
+func TestRedditPostThumbnailURL(t *testing.T) {
+	for _, tc := range []struct {
+		data string
+		want string
+	}{
+		{`{"thumbnail": "self"}`, ""},
+		{`{"thumbnail": "nsfw"}`, ""},
+		{`{"thumbnail": "https://b.thumbs.redditmedia.com/x.jpg"}`, "https://b.thumbs.redditmedia.com/x.jpg"},
+		{
+			`{"thumbnail": "https://b.thumbs.redditmedia.com/x.jpg", "preview": {"images": [{"source": {"url": "https://preview.redd.it/y.png?width=640&amp;s=abc"}}]}}`,
+			"https://preview.redd.it/y.png?width=640&s=abc",
+		},
+	} {
+		var post RedditPost
+		if err := json.Unmarshal([]byte(tc.data), &post); err != nil {
+			t.Fatalf("Unmarshal(%s) error = %v", tc.data, err)
+		}
+		if got := post.thumbnailURL(); got != tc.want {
+			t.Errorf("thumbnailURL() for %s = %q, want %q", tc.data, got, tc.want)
+		}
+	}
+}
+
+func TestRedditFetcherUsesOAuthWithCredentials(t *testing.T) {
+	upstream := testutil.NewReddit(t)
+	fetcher := NewRedditFetcher("id", "secret", "test-agent/1.0", upstream.URL(), "", http.DefaultClient)
+
+	for range 2 {
+		if _, err := fetcher.Fetch(context.Background(), "channels", 2); err != nil {
+			t.Fatalf("Fetch() error = %v", err)
+		}
+	}
+
+	// One token serves both searches
+	if n := len(upstream.Requests()); n != 2 {
+		t.Errorf("upstream saw %d searches, want 2", n)
+	}
+	if fetcher.accessToken != "fake-token" {
+		t.Errorf("accessToken = %q, want the token from the token endpoint", fetcher.accessToken)
+	}
+}
+
+func TestRedditFetcherRenewsRevokedTokens(t *testing.T) {
+	var tokens, searches int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case redditTokenPath:
+			tokens++
+			fmt.Fprintf(w, `{"access_token": "token-%d", "expires_in": 3600}`, tokens)
+		case redditSearchPath:
+			searches++
+			if r.Header.Get("Authorization") != "Bearer token-2" {
+				http.Error(w, `{"message": "Unauthorized", "error": 401}`, http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprint(w, `{"kind": "Listing", "data": {"children": []}}`)
+		}
+	}))
+	defer server.Close()
+
+	fetcher := NewRedditFetcher("id", "secret", "test-agent/1.0", server.URL, "", server.Client())
+	if _, err := fetcher.Fetch(context.Background(), "channels", 2); err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if tokens != 2 || searches != 2 {
+		t.Errorf("%d tokens and %d searches, want the search retried once with a new token", tokens, searches)
+	}
+}
+
+func TestRedditFetcherReportsRejectedCredentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"error": "invalid_grant"}`)
+	}))
+	defer server.Close()
+
+	fetcher := NewRedditFetcher("id", "wrong", "test-agent/1.0", server.URL, "", server.Client())
+	_, err := fetcher.Fetch(context.Background(), "channels", 2)
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || !statusErr.CredentialRejected {
+		t.Errorf("Fetch() error = %v, want a rejected credential", err)
+	}
+}
+
+func TestRedditFetcherHosts(t *testing.T) {
+	fetcher := NewRedditFetcher("", "", "test-agent/1.0", "https://oauth.reddit.com", "", nil)
+	if fetcher.publicURL() != "https://www.reddit.com" || fetcher.tokenURL() != "https://www.reddit.com/api/v1/access_token" {
+		t.Errorf("publicURL() = %q, tokenURL() = %q, want www.reddit.com", fetcher.publicURL(), fetcher.tokenURL())
+	}
+
+	proxied := NewRedditFetcher("", "", "test-agent/1.0", "http://localhost:8089", "", nil)
+	if proxied.publicURL() != "http://localhost:8089" || proxied.tokenURL() != "http://localhost:8089/api/v1/access_token" {
+		t.Errorf("publicURL() = %q, tokenURL() = %q, want the base URL", proxied.publicURL(), proxied.tokenURL())
+	}
+}
+
+func TestRedditPostSnippetFormats(t *testing.T) {
+	post := RedditPost{
+		Title:        "Channels",
+		Selftext:     "Use **buffered** channels",
+		SelftextHTML: "&lt;div class=\"md\"&gt;&lt;p&gt;Use &lt;strong&gt;buffered&lt;/strong&gt; channels&lt;/p&gt;&lt;/div&gt;",
+	}
+
+	for format, want := range map[string]string{
+		SnippetPlain:    "Use buffered channels",
+		SnippetMarkdown: "Use **buffered** channels",
+		SnippetHTML:     "<p>Use <strong>buffered</strong> channels</p>",
+	} {
+		if got := sanitize.Snippet(post.snippet(format), format, sanitize.MaxSnippetLength); got != want {
+			t.Errorf("snippet(%q) = %q, want %q", format, got, want)
+		}
+	}
+
+	link := RedditPost{Title: "Go & Rust"}
+	if got := sanitize.Snippet(link.snippet(SnippetHTML), SnippetHTML, sanitize.MaxSnippetLength); got != "Go &amp; Rust" {
+		t.Errorf("snippet(html) of a link post = %q, want the escaped title", got)
+	}
+}
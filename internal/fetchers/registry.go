@@ -0,0 +1,240 @@
+package fetchers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Factory constructs a fetcher. It is called at most once per successful
+// registration, the first time the fetcher is needed.
+type Factory func() (Fetcher, error)
+
+// Initializer is implemented by fetchers that need to do work before their
+// first search, such as acquiring an OAuth token or probing the upstream
+type Initializer interface {
+	Init(ctx context.Context) error
+}
+
+// ErrPlatformDisabled is reported for a platform an operator has disabled
+var ErrPlatformDisabled = errors.New("platform is disabled")
+
+// Middleware wraps a fetcher after it has been initialized
+type Middleware func(Fetcher) Fetcher
+
+// initRetryDelay is how long a failed initialization is remembered before
+// the next search tries again
+const initRetryDelay = 30 * time.Second
+
+// warmUpTimeout bounds each platform's background initialization
+const warmUpTimeout = time.Minute
+
+// Registry holds the available platforms and initializes their fetchers on
+// demand. Platforms can be added and removed while searches are running.
+type Registry struct {
+	mu          sync.RWMutex
+	entries     map[string]*registration
+	middlewares []Middleware
+
+	// disabled holds the platforms taken out of searches; a platform stays
+	// disabled when it is registered again
+	disabled map[string]bool
+}
+
+type registration struct {
+	factory Factory
+
+	mu       sync.Mutex
+	fetcher  Fetcher
+	err      error
+	failedAt time.Time
+	// initializing is closed once the initialization in progress ends; nil
+	// when none is
+	initializing chan struct{}
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		entries:  make(map[string]*registration),
+		disabled: make(map[string]bool),
+	}
+}
+
+// Use adds middleware applied to every fetcher initialized afterwards
+func (r *Registry) Use(middleware Middleware) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.middlewares = append(r.middlewares, middleware)
+}
+
+// Register adds or replaces a platform. Its fetcher is built lazily.
+func (r *Registry) Register(name string, factory Factory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries[name] = &registration{factory: factory}
+}
+
+// RegisterFetcher adds or replaces a platform with an already built fetcher
+func (r *Registry) RegisterFetcher(fetcher Fetcher) {
+	r.Register(fetcher.Name(), func() (Fetcher, error) { return fetcher, nil })
+}
+
+// Unregister removes a platform
+func (r *Registry) Unregister(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, ok := r.entries[name]
+	delete(r.entries, name)
+	return ok
+}
+
+// Has reports whether a platform is registered
+func (r *Registry) Has(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.entries[name]
+	return ok
+}
+
+// Names returns the registered platform names in sorted order
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.entries))
+	for name := range r.entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetEnabled takes a registered platform out of searches, or puts it back.
+// It reports false for an unknown platform.
+func (r *Registry) SetEnabled(name string, enabled bool) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.entries[name]; !ok {
+		return false
+	}
+	if enabled {
+		delete(r.disabled, name)
+	} else {
+		r.disabled[name] = true
+	}
+	return true
+}
+
+// Enabled reports whether a platform is registered and not disabled
+func (r *Registry) Enabled(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.entries[name]
+	return ok && !r.disabled[name]
+}
+
+// EnabledNames returns the platforms that are not disabled in sorted order
+func (r *Registry) EnabledNames() []string {
+	names := r.Names()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return slices.DeleteFunc(names, func(name string) bool { return r.disabled[name] })
+}
+
+// Get returns the fetcher for a platform, initializing it first if needed.
+// Concurrent callers wait for a single initialization, each only as long as
+// its own ctx allows.
+func (r *Registry) Get(ctx context.Context, name string) (Fetcher, error) {
+	r.mu.RLock()
+	reg, ok := r.entries[name]
+	middlewares := r.middlewares
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown platform: %s", name)
+	}
+
+	for {
+		reg.mu.Lock()
+		if reg.fetcher != nil {
+			reg.mu.Unlock()
+			return reg.fetcher, nil
+		}
+		if reg.err != nil && time.Since(reg.failedAt) < initRetryDelay {
+			reg.mu.Unlock()
+			return nil, reg.err
+		}
+		if initializing := reg.initializing; initializing != nil {
+			reg.mu.Unlock()
+			select {
+			case <-initializing:
+				// Done, or given up by its caller: look again
+				continue
+			case <-ctx.Done():
+				return nil, fmt.Errorf("waiting for %s to initialize: %w", name, ctx.Err())
+			}
+		}
+		done := make(chan struct{})
+		reg.initializing = done
+		reg.mu.Unlock()
+
+		fetcher, err := initFetcher(ctx, name, reg.factory)
+		if err == nil {
+			for _, middleware := range middlewares {
+				fetcher = middleware(fetcher)
+			}
+		}
+
+		reg.mu.Lock()
+		reg.initializing = nil
+		close(done)
+		if err != nil {
+			// A caller giving up is not the platform's fault
+			if ctx.Err() == nil {
+				reg.err, reg.failedAt = err, time.Now()
+			}
+		} else {
+			reg.fetcher, reg.err = fetcher, nil
+		}
+		reg.mu.Unlock()
+		return fetcher, err
+	}
+}
+
+// WarmUp initializes every registered fetcher in the background so the first
+// searches don't pay for it. Each gets at most warmUpTimeout; failures are
+// logged and retried on demand.
+func (r *Registry) WarmUp(ctx context.Context) {
+	for _, name := range r.Names() {
+		go func(name string) {
+			ctx, cancel := context.WithTimeout(ctx, warmUpTimeout)
+			defer cancel()
+			if _, err := r.Get(ctx, name); err != nil {
+				log.Printf("WARNING: Background initialization of %s failed: %v", name, err)
+				return
+			}
+			log.Printf("Platform %s initialized", name)
+		}(name)
+	}
+}
+
+func initFetcher(ctx context.Context, name string, factory Factory) (Fetcher, error) {
+	fetcher, err := factory()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s fetcher: %w", name, err)
+	}
+
+	if initializer, ok := fetcher.(Initializer); ok {
+		if err := initializer.Init(ctx); err != nil {
+			return nil, fmt.Errorf("failed to initialize %s fetcher: %w", name, err)
+		}
+	}
+	return fetcher, nil
+}
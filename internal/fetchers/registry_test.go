@@ -0,0 +1,106 @@
+package fetchers
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/farhapartex/search-proxy/internal/models"
+)
+
+// slowInitFetcher's Init blocks until release is closed or its ctx ends
+type slowInitFetcher struct {
+	release chan struct{}
+	inits   *atomic.Int32
+}
+
+func (f *slowInitFetcher) Name() string { return "slow" }
+
+func (f *slowInitFetcher) Fetch(ctx context.Context, query string, maxResults int) ([]*models.SearchResult, error) {
+	return nil, nil
+}
+
+func (f *slowInitFetcher) Init(ctx context.Context) error {
+	f.inits.Add(1)
+	select {
+	case <-f.release:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func newSlowRegistry() (*Registry, chan struct{}, *atomic.Int32) {
+	release := make(chan struct{})
+	inits := new(atomic.Int32)
+	registry := NewRegistry()
+	registry.Register("slow", func() (Fetcher, error) {
+		return &slowInitFetcher{release: release, inits: inits}, nil
+	})
+	return registry, release, inits
+}
+
+func TestRegistryGetWaitsOnlyAsLongAsItsContext(t *testing.T) {
+	registry, release, inits := newSlowRegistry()
+
+	first := make(chan error, 1)
+	go func() {
+		_, err := registry.Get(context.Background(), "slow")
+		first <- err
+	}()
+	for inits.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := registry.Get(ctx, "slow"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Get() during initialization error = %v, want DeadlineExceeded", err)
+	}
+	// The registry itself stays usable meanwhile
+	if !registry.Enabled("slow") {
+		t.Error("Enabled(slow) = false during initialization")
+	}
+
+	close(release)
+	if err := <-first; err != nil {
+		t.Fatalf("first Get() error = %v", err)
+	}
+	if _, err := registry.Get(context.Background(), "slow"); err != nil || inits.Load() != 1 {
+		t.Errorf("Get() after initialization = %v with %d inits, want one shared initialization", err, inits.Load())
+	}
+}
+
+func TestRegistryGetTakesOverAbandonedInitialization(t *testing.T) {
+	registry, release, inits := newSlowRegistry()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	abandoned := make(chan error, 1)
+	go func() {
+		_, err := registry.Get(ctx, "slow")
+		abandoned <- err
+	}()
+	for inits.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	waiter := make(chan error, 1)
+	go func() {
+		_, err := registry.Get(context.Background(), "slow")
+		waiter <- err
+	}()
+	cancel()
+	if err := <-abandoned; !errors.Is(err, context.Canceled) {
+		t.Fatalf("abandoned Get() error = %v, want Canceled", err)
+	}
+
+	close(release)
+	if err := <-waiter; err != nil {
+		t.Fatalf("waiting Get() error = %v, want it to initialize the platform itself", err)
+	}
+	if n := inits.Load(); n != 2 {
+		t.Errorf("inits = %d, want the waiter to retry after the caller gave up", n)
+	}
+}
@@ -0,0 +1,140 @@
+package fetchers
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/farhapartex/search-proxy/internal/models"
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+// RetryPolicy says how often and how patiently a failed fetch is retried
+type RetryPolicy struct {
+	// MaxAttempts counts the first try; 1 disables retries
+	MaxAttempts int
+	// BaseDelay is the wait before the first retry, doubled for each one after
+	BaseDelay time.Duration
+	// Jitter is the fraction of each delay that is randomized, from 0 (fixed
+	// delays) to 1 (anywhere between zero and the full delay), so instances
+	// that failed together don't retry in lockstep
+	Jitter float64
+}
+
+// delay is how long to wait before the given retry, counting from 1
+func (p RetryPolicy) delay(retry int) time.Duration {
+	d := p.BaseDelay << (retry - 1)
+	if p.Jitter > 0 {
+		d -= time.Duration(p.Jitter * rand.Float64() * float64(d))
+	}
+	return d
+}
+
+// RetryFetcher retries the wrapped fetcher after transient failures: network
+// errors and 5xx answers. It never waits past the context deadline; when the
+// next attempt could not start in time, the last error is returned at once.
+type RetryFetcher struct {
+	next   Fetcher
+	policy RetryPolicy
+}
+
+// NewRetryFetcher wraps next with policy
+func NewRetryFetcher(next Fetcher, policy RetryPolicy) *RetryFetcher {
+	return &RetryFetcher{
+		next:   next,
+		policy: policy,
+	}
+}
+
+// Name returns the platform name
+func (r *RetryFetcher) Name() string {
+	return r.next.Name()
+}
+
+// Unwrap returns the wrapped fetcher
+func (r *RetryFetcher) Unwrap() Fetcher {
+	return r.next
+}
+
+// Fetch retrieves search results, retrying transient failures
+func (r *RetryFetcher) Fetch(ctx context.Context, query string, maxResults int) ([]*models.SearchResult, error) {
+	var results []*models.SearchResult
+	err := r.retry(ctx, func() error {
+		var err error
+		results, err = r.next.Fetch(ctx, query, maxResults)
+		return err
+	})
+	return results, err
+}
+
+// FetchProto is like Fetch but returns protobuf results
+func (r *RetryFetcher) FetchProto(ctx context.Context, query string, maxResults int) ([]*pb.Result, error) {
+	var results []*pb.Result
+	err := r.retry(ctx, func() error {
+		var err error
+		results, err = FetchProto(ctx, r.next, query, maxResults)
+		return err
+	})
+	return results, err
+}
+
+// FetchDetails fetches a result's details, retrying transient failures
+func (r *RetryFetcher) FetchDetails(ctx context.Context, resultURL string, maxReplies int) (*pb.ResultDetailsResponse, error) {
+	var details *pb.ResultDetailsResponse
+	err := r.retry(ctx, func() error {
+		var err error
+		details, err = FetchDetails(ctx, r.next, resultURL, maxReplies)
+		return err
+	})
+	return details, err
+}
+
+func (r *RetryFetcher) retry(ctx context.Context, fn func() error) error {
+	span := trace.SpanFromContext(ctx)
+	err := fn()
+	for retry := 1; retry < r.policy.MaxAttempts && err != nil && retryable(err); retry++ {
+		wait := r.policy.delay(retry)
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) {
+			wait = max(wait, statusErr.RetryAfter)
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= wait {
+			return err
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		span.SetAttributes(attribute.Int("search.retries", retry))
+		span.AddEvent("retry", trace.WithAttributes(
+			attribute.Int("retry", retry),
+			attribute.String("error", err.Error()),
+		))
+		err = fn()
+	}
+	return err
+}
+
+// retryable reports whether err is worth another attempt: the upstream
+// failed on its side or the connection did, rather than the request being
+// refused or the caller giving up
+func retryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= 500
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
@@ -0,0 +1,95 @@
+package fetchers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/farhapartex/search-proxy/internal/models"
+	"github.com/farhapartex/search-proxy/internal/testutil"
+)
+
+// flakyFetcher fails with the given errors, one per call, then succeeds
+type flakyFetcher struct {
+	errs  []error
+	calls int
+}
+
+func (f *flakyFetcher) Name() string { return "flaky" }
+
+func (f *flakyFetcher) Fetch(ctx context.Context, query string, maxResults int) ([]*models.SearchResult, error) {
+	f.calls++
+	if f.calls <= len(f.errs) {
+		return nil, f.errs[f.calls-1]
+	}
+	return []*models.SearchResult{models.NewSearchResult("flaky", "title", "snippet", "https://example.com")}, nil
+}
+
+var testRetryPolicy = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}
+
+func TestRetryFetcherRecoversFromServerErrors(t *testing.T) {
+	flaky := &flakyFetcher{errs: []error{
+		&StatusError{Platform: "flaky", StatusCode: http.StatusBadGateway},
+		&StatusError{Platform: "flaky", StatusCode: http.StatusServiceUnavailable},
+	}}
+
+	results, err := NewRetryFetcher(flaky, testRetryPolicy).Fetch(context.Background(), "go", 5)
+	if err != nil || len(results) != 1 {
+		t.Fatalf("Fetch() = %d results, %v; want the third attempt's result", len(results), err)
+	}
+	if flaky.calls != 3 {
+		t.Errorf("calls = %d, want 3", flaky.calls)
+	}
+}
+
+func TestRetryFetcherGivesUpAfterMaxAttempts(t *testing.T) {
+	upstream := testutil.NewHackerNews(t)
+	upstream.Set(testutil.Behavior{StatusCode: http.StatusInternalServerError})
+
+	_, err := NewRetryFetcher(newTestHackerNewsFetcher(upstream), testRetryPolicy).Fetch(context.Background(), "go", 5)
+	if err == nil {
+		t.Fatal("Fetch() succeeded against a failing upstream")
+	}
+	if got := len(upstream.Requests()); got != 3 {
+		t.Errorf("upstream got %d requests, want 3", got)
+	}
+}
+
+func TestRetryFetcherDoesNotRetryClientErrors(t *testing.T) {
+	upstream := testutil.NewHackerNews(t)
+	upstream.Set(testutil.Behavior{StatusCode: http.StatusBadRequest})
+
+	if _, err := NewRetryFetcher(newTestHackerNewsFetcher(upstream), testRetryPolicy).Fetch(context.Background(), "go", 5); err == nil {
+		t.Fatal("Fetch() succeeded against a failing upstream")
+	}
+	if got := len(upstream.Requests()); got != 1 {
+		t.Errorf("upstream got %d requests, want 1", got)
+	}
+}
+
+func TestRetryFetcherStopsAtDeadline(t *testing.T) {
+	flaky := &flakyFetcher{errs: []error{&StatusError{Platform: "flaky", StatusCode: http.StatusServiceUnavailable}}}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := NewRetryFetcher(flaky, RetryPolicy{MaxAttempts: 3, BaseDelay: time.Second}).Fetch(ctx, "go", 5)
+	if err == nil || flaky.calls != 1 {
+		t.Fatalf("Fetch() = %v after %d calls, want the first error without retrying", err, flaky.calls)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Millisecond {
+		t.Errorf("Fetch() took %v, want it to return without waiting", elapsed)
+	}
+}
+
+func TestRetryPolicyDelayBacksOffWithJitter(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, Jitter: 0.5}
+	for retry, full := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 3: 400 * time.Millisecond} {
+		for range 20 {
+			if d := policy.delay(retry); d > full || d < full/2 {
+				t.Fatalf("delay(%d) = %v, want between %v and %v", retry, d, full/2, full)
+			}
+		}
+	}
+}
@@ -4,56 +4,184 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"html"
 	"io"
+	"maps"
 	"net/http"
 	"net/url"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/farhapartex/search-proxy/internal/models"
+	dsl "github.com/farhapartex/search-proxy/internal/query"
+	"github.com/farhapartex/search-proxy/internal/sanitize"
+	pb "github.com/farhapartex/search-proxy/proto"
 )
 
-// StackOverflowFetcher fetches search results from StackOverflow
+// StackOverflowFetcher fetches search results from StackOverflow, or from
+// another Stack Exchange site
 type StackOverflowFetcher struct {
-	apiKey  string
-	baseURL string
-	client  *http.Client
+	// site is the API site parameter, also the platform name
+	site         string
+	keys         *CredentialPool
+	endpoints    *EndpointPool
+	bodyExcerpts bool
+	client       *http.Client
 }
 
-// NewStackOverflowFetcher creates a new StackOverflow fetcher
-func NewStackOverflowFetcher(apiKey, baseURL string) *StackOverflowFetcher {
+// NewStackOverflowFetcher creates a new StackOverflow fetcher. A nil key pool
+// searches without an API key. bodyExcerpts asks for question bodies to make
+// snippets from; without them snippets are the title and tags, which keeps
+// responses small.
+func NewStackOverflowFetcher(keys *CredentialPool, endpoints *EndpointPool, bodyExcerpts bool, client *http.Client) *StackOverflowFetcher {
+	return NewStackExchangeFetcher("stackoverflow", keys, endpoints, bodyExcerpts, client)
+}
+
+// NewStackExchangeFetcher creates a fetcher for one Stack Exchange site,
+// such as serverfault, named after it. Keys are valid across the whole
+// network, so sites can share a key pool.
+func NewStackExchangeFetcher(site string, keys *CredentialPool, endpoints *EndpointPool, bodyExcerpts bool, client *http.Client) *StackOverflowFetcher {
 	return &StackOverflowFetcher{
-		apiKey:  apiKey,
-		baseURL: baseURL,
-		client: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+		site:         site,
+		keys:         keys,
+		endpoints:    endpoints,
+		bodyExcerpts: bodyExcerpts,
+		client:       client,
 	}
 }
 
 // Name returns the platform name
 func (s *StackOverflowFetcher) Name() string {
-	return "stackoverflow"
+	return s.site
 }
 
 // Fetch retrieves search results from StackOverflow
 func (s *StackOverflowFetcher) Fetch(ctx context.Context, query string, maxResults int) ([]*models.SearchResult, error) {
+	items, err := s.search(ctx, query, maxResults)
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert to internal format
+	results := make([]*models.SearchResult, 0, len(items))
+	for _, item := range items {
+		result := models.NewSearchResult(
+			s.site,
+			item.Title,
+			item.snippet(),
+			item.Link,
+		)
+		result.SetTimes(item.times())
+		result.Author = item.author()
+		result.Type = pb.ResultType_RESULT_TYPE_QUESTION
+		result.Metadata = item.metadata()
+		result.StackOverflow = item.details()
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// FetchProto retrieves search results from StackOverflow as protobuf results
+func (s *StackOverflowFetcher) FetchProto(ctx context.Context, query string, maxResults int) ([]*pb.Result, error) {
+	items, err := s.search(ctx, query, maxResults)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*pb.Result, len(items))
+	for i := range items {
+		item := &items[i]
+		created, updated := item.times()
+		results[i] = &pb.Result{
+			Platform:        s.site,
+			Title:           item.Title,
+			Snippet:         item.snippet(),
+			Url:             item.Link,
+			Timestamp:       created,
+			CreatedAt:       created,
+			UpdatedAt:       updated,
+			Metadata:        item.metadata(),
+			Author:          item.author().ToProto(),
+			ResultType:      pb.ResultType_RESULT_TYPE_QUESTION,
+			PlatformDetails: &pb.Result_Stackoverflow{Stackoverflow: item.details()},
+		}
+	}
+
+	return results, nil
+}
+
+// ValidateCredential checks a key with the site info endpoint, which fails
+// for unknown or revoked keys
+func (s *StackOverflowFetcher) ValidateCredential(ctx context.Context, key string) error {
+	infoURL := fmt.Sprintf("%s/info?site=%s&key=%s", s.endpoints.Preferred(), s.site, url.QueryEscape(key))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, infoURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	return checkCredential(s.client, req, s.label(), stackOverflowKeyRejected)
+}
+
+func (s *StackOverflowFetcher) search(ctx context.Context, query string, maxResults int) ([]StackOverflowQuestion, error) {
+	var items []StackOverflowQuestion
+	err := s.endpoints.Try(ctx, func(baseURL string) error {
+		return s.keys.Try(ctx, func(key string) error {
+			var err error
+			items, err = s.searchAt(ctx, baseURL, key, query, maxResults)
+			return err
+		})
+	})
+	return items, err
+}
+
+func (s *StackOverflowFetcher) searchAt(ctx context.Context, baseURL, key, query string, maxResults int) ([]StackOverflowQuestion, error) {
 	// Build search URL
-	searchURL := fmt.Sprintf("%s/search/advanced?q=%s&pagesize=%d&order=desc&sort=relevance&site=stackoverflow",
-		s.baseURL,
-		url.QueryEscape(query),
+	q := dsl.Parse(query)
+	searchURL := fmt.Sprintf("%s/search/advanced?q=%s&pagesize=%d&order=desc&sort=relevance&site=%s%s",
+		baseURL,
+		url.QueryEscape(q.Text),
 		maxResults,
+		s.siteFor(RequestOptionsFrom(ctx).Locale),
+		stackOverflowFilters(q, RequestOptionsFrom(ctx)),
 	)
+	page := PageFrom(ctx)
+	if page.Number() > 1 {
+		searchURL += fmt.Sprintf("&page=%d", page.Number())
+	}
+
+	// Unanswered questions can't be answered; skip them upstream
+	if RequestOptionsFrom(ctx).AnsweredOnly {
+		searchURL += "&answers=1"
+	}
+
+	// The built-in withbody filter adds each question's HTML body
+	if s.bodyExcerpts {
+		searchURL += "&filter=withbody"
+	}
 
 	// Add API key if available
-	if s.apiKey != "" {
-		searchURL += fmt.Sprintf("&key=%s", s.apiKey)
+	if key != "" {
+		searchURL += fmt.Sprintf("&key=%s", url.QueryEscape(key))
 	}
 
+	var soResp StackOverflowSearchResponse
+	if err := s.get(ctx, searchURL, &soResp); err != nil {
+		return nil, err
+	}
+	page.setNextNumber(soResp.HasMore)
+	return soResp.Items, nil
+}
+
+// get calls the API and decodes its answer into out, which must embed
+// stackExchangeQuota so the quota left can be reported
+func (s *StackOverflowFetcher) get(ctx context.Context, apiURL string, out interface{ quota() stackExchangeQuota }) error {
 	// Create request
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, searchURL, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Add headers
@@ -62,68 +190,347 @@ func (s *StackOverflowFetcher) Fetch(ctx context.Context, query string, maxResul
 	// Execute request
 	resp, err := s.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
+		return fmt.Errorf("failed to execute request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// Check status code
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("StackOverflow API error: status=%d, body=%s", resp.StatusCode, string(body))
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+		return &StatusError{
+			Platform:           s.label(),
+			StatusCode:         resp.StatusCode,
+			Body:               string(body),
+			RetryAfter:         retryAfter(resp.Header),
+			CredentialRejected: stackOverflowKeyRejected(resp.StatusCode, body),
+		}
 	}
 
 	// Parse response
-	var soResp StackOverflowSearchResponse
-	if err := json.NewDecoder(resp.Body).Decode(&soResp); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	// Convert to internal format
-	results := make([]*models.SearchResult, 0, len(soResp.Items))
-	for _, item := range soResp.Items {
-		// Create snippet from title and tags
-		snippet := item.Title
-		if len(item.Tags) > 0 {
-			snippet += " | Tags: " + strings.Join(item.Tags, ", ")
-		}
+	if q := out.quota(); q.QuotaMax > 0 {
+		// The quota refills at midnight UTC
+		reset := time.Now().UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+		reportQuota(ctx, Quota{Limit: q.QuotaMax, Remaining: q.QuotaRemaining, Reset: reset})
+	}
+	return nil
+}
 
-		result := models.NewSearchResult(
-			"stackoverflow",
-			item.Title,
-			TruncateString(snippet, 500),
-			item.Link,
-		)
-		result.Timestamp = item.CreationDate
-		result.Metadata = map[string]string{
-			"score":        fmt.Sprintf("%d", item.Score),
-			"answer_count": fmt.Sprintf("%d", item.AnswerCount),
-			"view_count":   fmt.Sprintf("%d", item.ViewCount),
-			"is_answered":  fmt.Sprintf("%t", item.IsAnswered),
-			"tags":         strings.Join(item.Tags, ","),
+// FetchDetails returns a question with up to maxReplies of its answers: the
+// accepted answer first, then the highest voted
+func (s *StackOverflowFetcher) FetchDetails(ctx context.Context, resultURL string, maxReplies int) (*pb.ResultDetailsResponse, error) {
+	host, id, err := s.questionFor(resultURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var details *pb.ResultDetailsResponse
+	err = s.endpoints.Try(ctx, func(baseURL string) error {
+		return s.keys.Try(ctx, func(key string) error {
+			var err error
+			details, err = s.detailsAt(ctx, baseURL, key, host, id, maxReplies)
+			return err
+		})
+	})
+	return details, err
+}
+
+// questionFor reads the site and question ID from a question URL, either
+// /questions/{id}/{slug} or the short /q/{id}. The API takes the site's
+// host as its site parameter, which covers Stack Overflow's localized
+// editions too.
+func (s *StackOverflowFetcher) questionFor(resultURL string) (host string, id int, err error) {
+	host, segments, err := resultPath(resultURL)
+	if err != nil {
+		return "", 0, err
+	}
+	site := strings.TrimSuffix(strings.TrimSuffix(host, ".com"), ".stackexchange")
+	localized := s.site == "stackoverflow" && slices.Contains(slices.Collect(maps.Values(localizedSites)), site)
+	if site != s.site && !localized {
+		return "", 0, ErrUnrecognizedURL
+	}
+	if len(segments) < 2 || (segments[0] != "questions" && segments[0] != "q") {
+		return "", 0, ErrUnrecognizedURL
+	}
+	if id, err = strconv.Atoi(segments[1]); err != nil || id <= 0 {
+		return "", 0, ErrUnrecognizedURL
+	}
+	return host, id, nil
+}
+
+func (s *StackOverflowFetcher) detailsAt(ctx context.Context, baseURL, key, host string, id, maxReplies int) (*pb.ResultDetailsResponse, error) {
+	// The built-in withbody filter adds the HTML bodies
+	params := "site=" + url.QueryEscape(host) + "&filter=withbody"
+	if key != "" {
+		params += "&key=" + url.QueryEscape(key)
+	}
+
+	var questions StackOverflowSearchResponse
+	if err := s.get(ctx, fmt.Sprintf("%s/questions/%d?%s", baseURL, id, params), &questions); err != nil {
+		return nil, err
+	}
+	if len(questions.Items) == 0 {
+		return nil, &StatusError{Platform: s.label(), StatusCode: http.StatusNotFound, Body: "question not found"}
+	}
+	question := &questions.Items[0]
+	details := &pb.ResultDetailsResponse{
+		Platform:      s.site,
+		Url:           question.Link,
+		Title:         question.title(),
+		Content:       question.Body,
+		ContentFormat: SnippetHTML,
+		Author:        question.author().ToProto(),
+		CreatedAt:     question.CreationDate,
+	}
+	if maxReplies <= 0 || question.AnswerCount == 0 {
+		return details, nil
+	}
+
+	var answers stackOverflowAnswersResponse
+	answersURL := fmt.Sprintf("%s/questions/%d/answers?%s&sort=votes&order=desc&pagesize=%d", baseURL, id, params, min(maxReplies, 100))
+	if err := s.get(ctx, answersURL, &answers); err != nil {
+		return nil, err
+	}
+	// The accepted answer goes first, fetched on its own when it isn't
+	// among the highest voted
+	items := answers.Items
+	switch i := slices.IndexFunc(items, func(a StackOverflowAnswer) bool { return a.IsAccepted }); {
+	case i > 0:
+		answer := items[i]
+		items = slices.Insert(slices.Delete(items, i, i+1), 0, answer)
+	case i < 0 && question.AcceptedAnswerID != 0:
+		var accepted stackOverflowAnswersResponse
+		if err := s.get(ctx, fmt.Sprintf("%s/answers/%d?%s", baseURL, question.AcceptedAnswerID, params), &accepted); err != nil {
+			return nil, err
 		}
-		results = append(results, result)
+		items = append(accepted.Items, items...)
 	}
 
-	return results, nil
+	for _, answer := range items[:min(len(items), maxReplies)] {
+		details.Replies = append(details.Replies, &pb.Reply{
+			Content:   answer.Body,
+			Author:    answer.Owner.author().ToProto(),
+			Score:     int32(answer.Score),
+			Accepted:  answer.IsAccepted,
+			CreatedAt: answer.CreationDate,
+			Url:       fmt.Sprintf("https://%s/a/%d", host, answer.AnswerID),
+		})
+	}
+	return details, nil
+}
+
+// stackOverflowKeyRejected reports whether an error response blames the API
+// key: an invalid or revoked key, or a throttle or quota violation
+func stackOverflowKeyRejected(statusCode int, body []byte) bool {
+	switch statusCode {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusTooManyRequests:
+		return true
+	}
+
+	var apiErr struct {
+		ErrorID      int    `json:"error_id"`
+		ErrorMessage string `json:"error_message"`
+	}
+	if json.Unmarshal(body, &apiErr) != nil {
+		return false
+	}
+	switch apiErr.ErrorID {
+	case 401, 402, 403, 502:
+		// access_token_required, invalid/revoked access token, throttle_violation
+		return true
+	case 400:
+		// bad_parameter, rejected when the key itself is malformed
+		return strings.Contains(strings.ToLower(apiErr.ErrorMessage), "key")
+	}
+	return false
+}
+
+// stackExchangeSites are the Stack Exchange sites besides Stack Overflow
+// that can be searched as platforms of their own, named after their API
+// site parameter
+var stackExchangeSites = []string{"serverfault", "superuser", "dba", "askubuntu"}
+
+// IsStackExchange reports whether the platform is Stack Overflow or one of
+// the other Stack Exchange sites, which share its results' shape
+func IsStackExchange(name string) bool {
+	return name == "stackoverflow" || slices.Contains(stackExchangeSites, name)
+}
+
+// localizedSites maps languages to their Stack Overflow editions
+var localizedSites = map[string]string{
+	"es": "es.stackoverflow",
+	"ja": "ja.stackoverflow",
+	"pt": "pt.stackoverflow",
+	"ru": "ru.stackoverflow",
+}
+
+// stackOverflowFilters turns the DSL terms and the request's time range
+// into search parameters. Languages are tags on Stack Overflow; stars and
+// subreddits mean nothing there.
+func stackOverflowFilters(q dsl.Query, opts RequestOptions) string {
+	var params string
+	if tags := append(q.Values(dsl.Lang), q.Values(dsl.Tag)...); len(tags) > 0 {
+		params += "&tagged=" + url.QueryEscape(strings.Join(tags, ";"))
+	}
+	from, to := createdRange(q, opts)
+	if !from.IsZero() {
+		params += fmt.Sprintf("&fromdate=%d", from.Unix())
+	}
+	// todate is inclusive
+	if !to.IsZero() {
+		params += fmt.Sprintf("&todate=%d", to.Unix()-1)
+	}
+	return params
+}
+
+// siteFor is the site to search for a locale; only Stack Overflow has
+// localized editions
+func (s *StackOverflowFetcher) siteFor(locale string) string {
+	if s.site != "stackoverflow" {
+		return s.site
+	}
+	return stackOverflowSite(locale)
+}
+
+// label names the site in errors
+func (s *StackOverflowFetcher) label() string {
+	if s.site == "stackoverflow" {
+		return "StackOverflow"
+	}
+	return DisplayFor(s.site).DisplayName
+}
+
+// stackOverflowSite picks the Stack Overflow edition for a BCP 47 locale,
+// falling back to the English site
+func stackOverflowSite(locale string) string {
+	lang, _, _ := strings.Cut(strings.ToLower(locale), "-")
+	if site, ok := localizedSites[lang]; ok {
+		return site
+	}
+	return "stackoverflow"
+}
+
+// stackExchangeQuota is the quota every API response reports
+type stackExchangeQuota struct {
+	QuotaMax       int `json:"quota_max"`
+	QuotaRemaining int `json:"quota_remaining"`
+}
+
+func (q stackExchangeQuota) quota() stackExchangeQuota {
+	return q
 }
 
 // StackOverflowSearchResponse represents the StackOverflow API search response
 type StackOverflowSearchResponse struct {
-	Items          []StackOverflowQuestion `json:"items"`
-	HasMore        bool                    `json:"has_more"`
-	QuotaMax       int                     `json:"quota_max"`
-	QuotaRemaining int                     `json:"quota_remaining"`
+	Items   []StackOverflowQuestion `json:"items"`
+	HasMore bool                    `json:"has_more"`
+	stackExchangeQuota
+}
+
+// stackOverflowAnswersResponse is the API's answer to an answers query
+type stackOverflowAnswersResponse struct {
+	Items []StackOverflowAnswer `json:"items"`
+	stackExchangeQuota
+}
+
+// StackExchangeUser is the owner of a question or answer
+type StackExchangeUser struct {
+	UserID      int    `json:"user_id"`
+	DisplayName string `json:"display_name"`
+	Link        string `json:"link"`
+}
+
+// author is nil for deleted accounts, which have no user ID
+func (u *StackExchangeUser) author() *models.Author {
+	if u.UserID == 0 {
+		return nil
+	}
+	return &models.Author{
+		// Display names come HTML-escaped
+		Name:       html.UnescapeString(u.DisplayName),
+		Handle:     strconv.Itoa(u.UserID),
+		ProfileURL: u.Link,
+	}
+}
+
+// StackOverflowAnswer is an answer to a question, with its HTML body
+type StackOverflowAnswer struct {
+	AnswerID     int               `json:"answer_id"`
+	Score        int               `json:"score"`
+	IsAccepted   bool              `json:"is_accepted"`
+	Body         string            `json:"body"`
+	CreationDate int64             `json:"creation_date"`
+	Owner        StackExchangeUser `json:"owner"`
 }
 
 // StackOverflowQuestion represents a StackOverflow question in search results
 type StackOverflowQuestion struct {
-	QuestionID   int      `json:"question_id"`
-	Title        string   `json:"title"`
-	Link         string   `json:"link"`
-	Score        int      `json:"score"`
-	AnswerCount  int      `json:"answer_count"`
-	ViewCount    int      `json:"view_count"`
-	IsAnswered   bool     `json:"is_answered"`
-	Tags         []string `json:"tags"`
-	CreationDate int64    `json:"creation_date"`
+	QuestionID  int      `json:"question_id"`
+	Title       string   `json:"title"`
+	Link        string   `json:"link"`
+	Score       int      `json:"score"`
+	AnswerCount int      `json:"answer_count"`
+	ViewCount   int      `json:"view_count"`
+	IsAnswered  bool     `json:"is_answered"`
+	Tags        []string `json:"tags"`
+	// Body is the question as HTML, only sent with the withbody filter
+	Body         string `json:"body"`
+	CreationDate int64  `json:"creation_date"`
+	// LastActivityDate covers edits, answers and comments
+	LastActivityDate int64             `json:"last_activity_date"`
+	AcceptedAnswerID int               `json:"accepted_answer_id"`
+	Owner            StackExchangeUser `json:"owner"`
+}
+
+// title decodes the HTML entities the API escapes titles with. Search
+// results keep them, for the search handler to decode with every other
+// platform's.
+func (q *StackOverflowQuestion) title() string {
+	return html.UnescapeString(q.Title)
+}
+
+// snippet is the text of the question body or, when the body wasn't asked
+// for, the title and tags
+func (q *StackOverflowQuestion) snippet() string {
+	if body := sanitize.HTMLText(q.Body); body != "" {
+		return body
+	}
+	snippet := q.Title
+	if len(q.Tags) > 0 {
+		snippet += " | Tags: " + strings.Join(q.Tags, ", ")
+	}
+	return snippet
+}
+
+// times returns the creation and last activity times in Unix seconds
+func (q *StackOverflowQuestion) times() (created, updated int64) {
+	return q.CreationDate, max(q.LastActivityDate, q.CreationDate)
+}
+
+// author describes the asker
+func (q *StackOverflowQuestion) author() *models.Author {
+	return q.Owner.author()
+}
+
+func (q *StackOverflowQuestion) metadata() map[string]string {
+	return map[string]string{
+		"score":        fmt.Sprintf("%d", q.Score),
+		"answer_count": fmt.Sprintf("%d", q.AnswerCount),
+		"view_count":   fmt.Sprintf("%d", q.ViewCount),
+		"is_answered":  fmt.Sprintf("%t", q.IsAnswered),
+		"tags":         strings.Join(q.Tags, ","),
+	}
+}
+
+func (q *StackOverflowQuestion) details() *pb.StackOverflowMeta {
+	return &pb.StackOverflowMeta{
+		Score:       int32(q.Score),
+		AnswerCount: int32(q.AnswerCount),
+		ViewCount:   int32(q.ViewCount),
+		IsAnswered:  q.IsAnswered,
+		Tags:        q.Tags,
+	}
 }
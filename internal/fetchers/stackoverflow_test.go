@@ -0,0 +1,125 @@
+package fetchers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/farhapartex/search-proxy/internal/testutil"
+)
+
+func TestStackOverflowFetcherReturnsResults(t *testing.T) {
+	upstream := testutil.NewStackOverflow(t)
+	fetcher := NewStackOverflowFetcher(NewCredentialPool("secret", false), NewEndpointPool(upstream.URL(), SelectPriority), false, http.DefaultClient)
+
+	results, err := fetcher.Fetch(context.Background(), "goroutine leak", 10)
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	if len(results) != 3 {
+		t.Fatalf("len(results) = %d, want 3", len(results))
+	}
+	if !strings.Contains(results[0].Snippet, "Tags: go, testing") {
+		t.Errorf("results[0].Snippet = %q, want tags appended", results[0].Snippet)
+	}
+	if a := results[0].Author; a == nil || a.Name != "Tester & Co" || a.Handle != "42" {
+		t.Errorf("results[0].Author = %+v, want the unescaped asker", a)
+	}
+
+	query := upstream.Requests()[0].Query()
+	if query.Get("q") != "goroutine leak" || query.Get("key") != "secret" || query.Get("site") != "stackoverflow" {
+		t.Errorf("upstream query = %v, want q, key and site set", query)
+	}
+	if query.Has("filter") {
+		t.Errorf("upstream query = %v, want no filter without body excerpts", query)
+	}
+}
+
+func TestStackOverflowFetcherUsesBodyExcerpts(t *testing.T) {
+	upstream := testutil.NewStackOverflow(t)
+	fetcher := NewStackOverflowFetcher(nil, NewEndpointPool(upstream.URL(), SelectPriority), true, http.DefaultClient)
+
+	results, err := fetcher.FetchProto(context.Background(), "goroutine leak", 10)
+	if err != nil {
+		t.Fatalf("FetchProto() error = %v", err)
+	}
+
+	if got := upstream.Requests()[0].Query().Get("filter"); got != "withbody" {
+		t.Errorf("filter = %q, want withbody", got)
+	}
+	if want := "How do I fix goroutine leak & why? go run ."; results[0].Snippet != want {
+		t.Errorf("results[0].Snippet = %q, want the decoded body %q", results[0].Snippet, want)
+	}
+}
+
+func TestStackOverflowFetcherReportsThrottling(t *testing.T) {
+	upstream := testutil.NewStackOverflow(t)
+	upstream.Set(testutil.Behavior{RateLimited: true})
+	fetcher := NewStackOverflowFetcher(nil, NewEndpointPool(upstream.URL(), SelectPriority), true, http.DefaultClient)
+
+	_, err := fetcher.Fetch(context.Background(), "go", 10)
+	if err == nil || !strings.Contains(err.Error(), "throttle_violation") {
+		t.Fatalf("Fetch() error = %v, want a throttle_violation error", err)
+	}
+}
+
+func TestStackOverflowFetcherAppliesTimeRange(t *testing.T) {
+	upstream := testutil.NewStackOverflow(t)
+	fetcher := NewStackOverflowFetcher(nil, NewEndpointPool(upstream.URL(), SelectPriority), false, http.DefaultClient)
+
+	ctx := WithRequestOptions(context.Background(), RequestOptions{Since: time.Unix(1700000000, 0), Until: time.Unix(1710000000, 0)})
+	if _, err := fetcher.Fetch(ctx, "go", 5); err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	query := upstream.Requests()[0].Query()
+	if query.Get("fromdate") != "1700000000" || query.Get("todate") != "1709999999" {
+		t.Errorf("upstream query = %v, want fromdate and an inclusive todate", query)
+	}
+}
+
+func TestStackOverflowFetcherUsesLocalizedSite(t *testing.T) {
+	upstream := testutil.NewStackOverflow(t)
+	fetcher := NewStackOverflowFetcher(nil, NewEndpointPool(upstream.URL(), SelectPriority), true, http.DefaultClient)
+
+	for locale, site := range map[string]string{"pt-BR": "pt.stackoverflow", "de-DE": "stackoverflow", "": "stackoverflow"} {
+		ctx := WithRequestOptions(context.Background(), RequestOptions{Locale: locale})
+		if _, err := fetcher.Fetch(ctx, "go", 5); err != nil {
+			t.Fatalf("Fetch(locale=%q) error = %v", locale, err)
+		}
+
+		requests := upstream.Requests()
+		if got := requests[len(requests)-1].Query().Get("site"); got != site {
+			t.Errorf("locale %q: site = %q, want %q", locale, got, site)
+		}
+	}
+}
+
+func TestStackExchangeFetcherSearchesItsSite(t *testing.T) {
+	upstream := testutil.NewStackOverflow(t)
+	fetcher := NewStackExchangeFetcher("serverfault", nil, NewEndpointPool(upstream.URL(), SelectPriority), false, http.DefaultClient)
+	if fetcher.Name() != "serverfault" {
+		t.Errorf("Name() = %q, want serverfault", fetcher.Name())
+	}
+
+	// Only Stack Overflow has localized editions
+	ctx := WithRequestOptions(context.Background(), RequestOptions{Locale: "pt-BR"})
+	results, err := fetcher.FetchProto(ctx, "nginx", 5)
+	if err != nil {
+		t.Fatalf("FetchProto() error = %v", err)
+	}
+	if len(results) == 0 || results[0].Platform != "serverfault" || results[0].GetStackoverflow() == nil {
+		t.Errorf("results = %v, want serverfault questions", results)
+	}
+	if got := upstream.Requests()[0].Query().Get("site"); got != "serverfault" {
+		t.Errorf("site = %q, want serverfault", got)
+	}
+
+	upstream.Set(testutil.Behavior{StatusCode: http.StatusServiceUnavailable})
+	if _, err := fetcher.Fetch(ctx, "nginx", 5); err == nil || !strings.Contains(err.Error(), "Server Fault") {
+		t.Errorf("Fetch() error = %v, want it to name Server Fault", err)
+	}
+}
@@ -0,0 +1,27 @@
+package fetchers
+
+import "github.com/farhapartex/search-proxy/internal/sanitize"
+
+// Snippet formats, for platforms whose content is written in markdown or
+// HTML. Fetchers return snippets whole; the search handler sanitizes and
+// cuts them.
+const (
+	SnippetPlain    = sanitize.Plain
+	SnippetMarkdown = sanitize.Markdown
+	SnippetHTML     = sanitize.HTML
+)
+
+// SnippetFormatter is implemented by fetchers whose snippets may be in a
+// format other than plain text
+type SnippetFormatter interface {
+	SnippetFormat() string
+}
+
+// SnippetFormat returns the format of f's snippets, looking through
+// middleware
+func SnippetFormat(f Fetcher) string {
+	if formatter, ok := findInterface[SnippetFormatter](f); ok {
+		return formatter.SnippetFormat()
+	}
+	return SnippetPlain
+}
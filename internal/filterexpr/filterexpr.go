@@ -0,0 +1,176 @@
+// Package filterexpr evaluates CEL expressions over search results, such as
+// `platform == "github" && int(metadata.stars) > 100`, so clients can filter
+// on the server instead of over-fetching.
+//
+// Expressions are standard CEL (https://cel.dev) over these variables:
+//
+//	platform, title, snippet, url, author  string
+//	timestamp, created_at, updated_at      int, Unix seconds
+//	age_days                               int
+//	metadata                               map(string, string)
+//
+// author is the author's handle, "" when unknown. Metadata values are
+// strings, as the platforms report them, so numeric comparisons convert
+// them first: `int(metadata.stars) > 100`. An expression must be boolean
+// and type-check against these variables. A result the expression fails to
+// evaluate on, such as one without a metadata key it reads, doesn't match;
+// `has(metadata.score)` or `"score" in metadata` test for a key.
+package filterexpr
+
+import (
+	"container/list"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+// MaxLength is the longest expression accepted, in bytes
+const MaxLength = 512
+
+// maxDepth bounds nesting so hostile input can't exhaust the stack
+const maxDepth = 32
+
+// maxCost bounds the work one evaluation may do, such as regular
+// expression matches over long snippets
+const maxCost = 10_000
+
+// maxCached bounds how many compiled expressions are kept for reuse
+const maxCached = 256
+
+// env declares the variables expressions see; it is built once, as
+// building it is far costlier than compiling an expression
+var env = sync.OnceValues(func() (*cel.Env, error) {
+	return cel.NewEnv(
+		cel.Variable("platform", cel.StringType),
+		cel.Variable("title", cel.StringType),
+		cel.Variable("snippet", cel.StringType),
+		cel.Variable("url", cel.StringType),
+		cel.Variable("author", cel.StringType),
+		cel.Variable("timestamp", cel.IntType),
+		cel.Variable("created_at", cel.IntType),
+		cel.Variable("updated_at", cel.IntType),
+		cel.Variable("age_days", cel.IntType),
+		cel.Variable("metadata", cel.MapType(cel.StringType, cel.StringType)),
+		cel.ParserRecursionLimit(maxDepth),
+		cel.ParserExpressionSizeLimit(MaxLength),
+	)
+})
+
+// Expr is a compiled expression. It is safe for concurrent use.
+type Expr struct {
+	program cel.Program
+}
+
+// compiled holds expressions already compiled, by source. A request's
+// filter is compiled when the request is validated and again when the
+// search runs, and clients tend to reuse a few filters.
+var compiled = newExprCache(maxCached)
+
+// exprCache keeps the most recently used compiled expressions
+type exprCache struct {
+	mu      sync.Mutex
+	max     int
+	entries map[string]*list.Element
+	lru     *list.List
+}
+
+type cachedExpr struct {
+	src  string
+	expr *Expr
+}
+
+func newExprCache(max int) *exprCache {
+	return &exprCache{max: max, entries: make(map[string]*list.Element), lru: list.New()}
+}
+
+func (c *exprCache) get(src string) (*Expr, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[src]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*cachedExpr).expr, true
+}
+
+func (c *exprCache) put(src string, expr *Expr) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[src]; ok {
+		return
+	}
+	c.entries[src] = c.lru.PushFront(&cachedExpr{src: src, expr: expr})
+	for c.lru.Len() > c.max {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cachedExpr).src)
+	}
+}
+
+// Compile parses and type-checks an expression, reusing the result of an
+// earlier call with the same source. An empty expression compiles to nil,
+// which matches everything.
+func Compile(src string) (*Expr, error) {
+	if strings.TrimSpace(src) == "" {
+		return nil, nil
+	}
+	if len(src) > MaxLength {
+		return nil, fmt.Errorf("filter too long (max %d bytes)", MaxLength)
+	}
+	if expr, ok := compiled.get(src); ok {
+		return expr, nil
+	}
+	e, err := env()
+	if err != nil {
+		return nil, err
+	}
+
+	ast, issues := e.Compile(src)
+	if issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	if !ast.OutputType().IsExactType(cel.BoolType) {
+		return nil, fmt.Errorf("filter must be a boolean expression, not %s", ast.OutputType())
+	}
+	program, err := e.Program(ast, cel.CostLimit(maxCost))
+	if err != nil {
+		return nil, err
+	}
+	expr := &Expr{program: program}
+	compiled.put(src, expr)
+	return expr, nil
+}
+
+// Keep reports whether result matches the expression. A nil expression
+// matches everything.
+func (e *Expr) Keep(result *pb.Result) bool {
+	if e == nil {
+		return true
+	}
+	metadata := result.Metadata
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	out, _, err := e.program.Eval(map[string]any{
+		"platform":   result.Platform,
+		"title":      result.Title,
+		"snippet":    result.Snippet,
+		"url":        result.Url,
+		"author":     result.Author.GetHandle(),
+		"timestamp":  result.Timestamp,
+		"created_at": result.CreatedAt,
+		"updated_at": result.UpdatedAt,
+		"age_days":   int64(result.AgeDays),
+		"metadata":   metadata,
+	})
+	if err != nil {
+		return false
+	}
+	keep, ok := out.Value().(bool)
+	return ok && keep
+}
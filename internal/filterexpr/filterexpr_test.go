@@ -0,0 +1,142 @@
+package filterexpr
+
+import (
+	"strings"
+	"testing"
+
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+var (
+	goRepo = &pb.Result{Platform: "github", Title: "golang/go", AgeDays: 30, Timestamp: 1704067200,
+		Author:   &pb.Author{Handle: "golang"},
+		Metadata: map[string]string{"stars": "120000", "language": "Go"}}
+	smallRepo = &pb.Result{Platform: "github", Title: "tiny", AgeDays: 400,
+		Metadata: map[string]string{"stars": "12", "language": "Rust"}}
+	question = &pb.Result{Platform: "stackoverflow", Title: "How do channels work?",
+		Metadata: map[string]string{"score": "42", "is_answered": "true"}}
+)
+
+func TestExprKeep(t *testing.T) {
+	tests := []struct {
+		expr string
+		want map[*pb.Result]bool
+	}{
+		{`int(metadata.stars) > 100 && metadata.language == "Go"`, map[*pb.Result]bool{goRepo: true, smallRepo: false, question: false}},
+		{`platform == "stackoverflow" || int(metadata.stars) >= 12`, map[*pb.Result]bool{goRepo: true, smallRepo: true, question: true}},
+		{`!(platform == "github") && bool(metadata.is_answered)`, map[*pb.Result]bool{goRepo: false, smallRepo: false, question: true}},
+		{`age_days < 365`, map[*pb.Result]bool{goRepo: true, smallRepo: false, question: true}},
+		// A missing metadata key fails the evaluation, so never matches
+		{`metadata.language != "Go"`, map[*pb.Result]bool{goRepo: false, smallRepo: true, question: false}},
+		{`!has(metadata.language) || metadata.language != "Go"`, map[*pb.Result]bool{goRepo: false, smallRepo: true, question: true}},
+		{`title == "How do channels work?"`, map[*pb.Result]bool{goRepo: false, smallRepo: false, question: true}},
+		// A value that doesn't convert fails the evaluation too
+		{`int(metadata.language) > 1`, map[*pb.Result]bool{goRepo: false, smallRepo: false, question: false}},
+		// Strings compare as text, so "42" > "100"
+		{`metadata.score > "100" && platform != "reddit"`, map[*pb.Result]bool{goRepo: false, smallRepo: false, question: true}},
+		{`metadata["language"] == "Go" || int(metadata.score) >= 42`, map[*pb.Result]bool{goRepo: true, smallRepo: false, question: true}},
+		{`timestamp > 1700000000 && author == "golang"`, map[*pb.Result]bool{goRepo: true, smallRepo: false, question: false}},
+		{`title.startsWith("golang/") || "score" in metadata`, map[*pb.Result]bool{goRepo: true, smallRepo: false, question: true}},
+	}
+	for _, tt := range tests {
+		expr, err := Compile(tt.expr)
+		if err != nil {
+			t.Fatalf("Compile(%q) error = %v", tt.expr, err)
+		}
+		for result, want := range tt.want {
+			if got := expr.Keep(result); got != want {
+				t.Errorf("%s: Keep(%s) = %v, want %v", tt.expr, result.Title, got, want)
+			}
+		}
+	}
+}
+
+func TestCompileEmptyMatchesEverything(t *testing.T) {
+	expr, err := Compile("  ")
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	if !expr.Keep(question) {
+		t.Error("empty filter dropped a result")
+	}
+}
+
+func TestCompileRejectsInvalid(t *testing.T) {
+	for _, src := range []string{
+		`metadata.stars >`,
+		`platform = "github"`,
+		`(age_days > 5`,
+		`age_days > 5 platform == "github"`,
+		`stars > 100`,
+		`metadata.stars > 100`,
+		`age_days + 1`,
+		`title == "unterminated`,
+		`age_days > 5 ; drop`,
+		strings.Repeat("x", MaxLength+1),
+	} {
+		if _, err := Compile(src); err == nil {
+			t.Errorf("Compile(%q) succeeded, want an error", src)
+		}
+	}
+}
+
+func TestCompileLimitsNesting(t *testing.T) {
+	deep := strings.Repeat("!(", 100) + `age_days > 1` + strings.Repeat(")", 100)
+	if _, err := Compile(deep); err == nil {
+		t.Error("Compile() accepted 100 levels of nesting")
+	}
+}
+
+func TestKeepLimitsCost(t *testing.T) {
+	expr, err := Compile(`snippet.matches("a+$") && snippet.matches("(a|b)+$") && snippet.contains("aaa")`)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	if !expr.Keep(&pb.Result{Snippet: "aaaa"}) {
+		t.Fatal("Keep() dropped a short snippet")
+	}
+	if expr.Keep(&pb.Result{Snippet: strings.Repeat("a", 1<<20)}) {
+		t.Error("Keep() matched a snippet over the cost limit")
+	}
+}
+
+func TestCompileReusesCompiledExpressions(t *testing.T) {
+	src := `platform == "github" && age_days < 30`
+	first, err := Compile(src)
+	if err != nil {
+		t.Fatalf("Compile() error = %v", err)
+	}
+	if again, _ := Compile(src); again != first {
+		t.Error("Compile() compiled the same expression twice")
+	}
+
+	// Expressions used least recently are dropped first
+	cache := newExprCache(2)
+	a, b, c := &Expr{}, &Expr{}, &Expr{}
+	cache.put("a", a)
+	cache.put("b", b)
+	cache.get("a")
+	cache.put("c", c)
+	if got, ok := cache.get("b"); ok {
+		t.Errorf("get(b) = %v, want it evicted", got)
+	}
+	if got, _ := cache.get("a"); got != a {
+		t.Error("get(a) missed the recently used entry")
+	}
+	if got, _ := cache.get("c"); got != c {
+		t.Error("get(c) missed the newest entry")
+	}
+}
+
+func FuzzCompile(f *testing.F) {
+	f.Add(`int(metadata.stars) > 100 && metadata.language == "Go"`)
+	f.Add(`!(title == "x" || age_days <= -1)`)
+
+	f.Fuzz(func(t *testing.T, src string) {
+		expr, err := Compile(src)
+		if err != nil {
+			return
+		}
+		expr.Keep(goRepo)
+	})
+}
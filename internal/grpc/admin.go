@@ -0,0 +1,262 @@
+package grpc
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/farhapartex/search-proxy/internal/config"
+	"github.com/farhapartex/search-proxy/internal/fetchers"
+	"github.com/farhapartex/search-proxy/internal/handlers"
+	"github.com/farhapartex/search-proxy/internal/logging"
+	pb "github.com/farhapartex/search-proxy/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// maxCredentials bounds how many credentials one platform can hold
+const maxCredentials = 50
+
+// Defaults and bounds for the search history calls
+const (
+	defaultHistoryLimit   = 20
+	defaultTrendingLimit  = 10
+	maxHistoryLimit       = 100
+	defaultTrendingWindow = 24 * time.Hour
+	maxTrendingWindow     = 30 * 24 * time.Hour
+)
+
+// AdminServer implements AdminService on top of the search server's handler.
+// It does no authorization itself: register it only on a server using
+// AdminAuthInterceptor.
+type AdminServer struct {
+	pb.UnimplementedAdminServiceServer
+	searchHandler *handlers.SearchHandler
+	config        *config.Config
+}
+
+// NewAdminServer creates the admin API for s
+func NewAdminServer(s *Server) *AdminServer {
+	return &AdminServer{searchHandler: s.searchHandler, config: s.config}
+}
+
+func (a *AdminServer) UpdateCredentials(ctx context.Context, req *pb.UpdateCredentialsRequest) (*pb.UpdateCredentialsResponse, error) {
+	platform := fetchers.CanonicalName(req.Platform)
+	if len(req.Credentials) > maxCredentials {
+		return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("too many credentials (max %d)", maxCredentials))
+	}
+	for i, credential := range req.Credentials {
+		if strings.TrimSpace(credential) == "" || strings.ContainsAny(credential, ", \t\r\n") {
+			return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("credential %d is blank or contains separators", i))
+		}
+	}
+	// Reddit authenticates with a single client ID and secret pair
+	if platform == "reddit" {
+		if len(req.Credentials) > 1 {
+			return nil, status.Error(codes.InvalidArgument, "reddit takes one client_id:client_secret credential")
+		}
+		for i, credential := range req.Credentials {
+			if _, _, ok := fetchers.SplitRedditCredential(credential); !ok {
+				return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("credential %d is not client_id:client_secret", i))
+			}
+		}
+	}
+
+	err := a.searchHandler.UpdateCredentials(ctx, platform, req.Credentials, req.SkipValidation)
+	var rejected *handlers.CredentialError
+	switch {
+	case errors.Is(err, handlers.ErrCredentialsNotRotatable):
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, fetchers.ErrCannotValidate):
+		return nil, status.Error(codes.FailedPrecondition, err.Error()+"; set skip_validation to swap anyway")
+	case errors.As(err, &rejected):
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case err != nil:
+		return nil, status.Error(codes.Unavailable, fmt.Sprintf("validating credentials: %v", err))
+	}
+
+	return &pb.UpdateCredentialsResponse{Platform: platform, Credentials: int32(len(req.Credentials))}, nil
+}
+
+func (a *AdminServer) SetPlatformEnabled(ctx context.Context, req *pb.SetPlatformEnabledRequest) (*pb.SetPlatformEnabledResponse, error) {
+	platform := fetchers.CanonicalName(req.Platform)
+	if platform == "" {
+		return nil, status.Error(codes.InvalidArgument, "platform is required")
+	}
+
+	err := a.searchHandler.SetPlatformEnabled(platform, req.Enabled)
+	if errors.Is(err, handlers.ErrUnknownPlatform) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &pb.SetPlatformEnabledResponse{Platform: platform, Enabled: req.Enabled}, nil
+}
+
+func (a *AdminServer) ResetCircuitBreakers(ctx context.Context, req *pb.ResetCircuitBreakersRequest) (*pb.ResetCircuitBreakersResponse, error) {
+	platforms := make([]string, len(req.Platforms))
+	for i, platform := range req.Platforms {
+		platforms[i] = fetchers.CanonicalName(platform)
+	}
+
+	reset, err := a.searchHandler.ResetCircuitBreakers(platforms)
+	switch {
+	case errors.Is(err, handlers.ErrCircuitBreakersDisabled):
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, handlers.ErrUnknownPlatform):
+		return nil, status.Error(codes.NotFound, err.Error())
+	case err != nil:
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &pb.ResetCircuitBreakersResponse{Platforms: reset}, nil
+}
+
+func (a *AdminServer) FlushCache(ctx context.Context, req *pb.FlushCacheRequest) (*pb.FlushCacheResponse, error) {
+	responses, semantic, err := a.searchHandler.FlushCache(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, fmt.Sprintf("flushing the response cache: %v", err))
+	}
+	return &pb.FlushCacheResponse{Responses: int32(responses), SemanticEntries: int32(semantic)}, nil
+}
+
+func (a *AdminServer) SetLogLevel(ctx context.Context, req *pb.SetLogLevelRequest) (*pb.SetLogLevelResponse, error) {
+	level, err := logging.ParseLevel(req.Level)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	previous := logging.SetLevel(level)
+	log.Printf("Log level changed from %s to %s", previous, level)
+	return &pb.SetLogLevelResponse{Level: level.String(), PreviousLevel: previous.String()}, nil
+}
+
+func (a *AdminServer) GetConfig(ctx context.Context, req *pb.GetConfigRequest) (*pb.GetConfigResponse, error) {
+	data, err := json.Marshal(a.config.Redacted())
+	if err != nil {
+		return nil, status.Error(codes.Internal, fmt.Sprintf("encoding config: %v", err))
+	}
+	return &pb.GetConfigResponse{ConfigJson: string(data)}, nil
+}
+
+func (a *AdminServer) ListRecentSearches(ctx context.Context, req *pb.ListRecentSearchesRequest) (*pb.ListRecentSearchesResponse, error) {
+	limit, err := historyLimit(req.Limit, defaultHistoryLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := a.searchHandler.RecentSearches(ctx, limit)
+	if err != nil {
+		return nil, historyError(err)
+	}
+	searches := make([]*pb.RecordedSearch, len(records))
+	for i, record := range records {
+		searches[i] = &pb.RecordedSearch{
+			Id:               record.ID,
+			Query:            record.Query,
+			Platforms:        record.Platforms,
+			ResultCount:      int32(record.ResultCount),
+			ResponseTimeMs:   int32(record.ResponseTimeMs),
+			PlatformsSuccess: record.PlatformsSuccess,
+			PlatformsTimeout: record.PlatformsTimeout,
+			PlatformsError:   record.PlatformsError,
+			CreatedAt:        record.CreatedAt.Unix(),
+		}
+	}
+	return &pb.ListRecentSearchesResponse{Searches: searches}, nil
+}
+
+func (a *AdminServer) ListTrendingQueries(ctx context.Context, req *pb.ListTrendingQueriesRequest) (*pb.ListTrendingQueriesResponse, error) {
+	limit, err := historyLimit(req.Limit, defaultTrendingLimit)
+	if err != nil {
+		return nil, err
+	}
+	window := time.Duration(req.WindowSeconds) * time.Second
+	switch {
+	case req.WindowSeconds < 0 || window > maxTrendingWindow:
+		return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("window_seconds must be between 0 and %d", int64(maxTrendingWindow/time.Second)))
+	case window == 0:
+		window = defaultTrendingWindow
+	}
+
+	trending, err := a.searchHandler.TrendingQueries(ctx, window, limit)
+	if err != nil {
+		return nil, historyError(err)
+	}
+	queries := make([]*pb.TrendingQuery, len(trending))
+	for i, t := range trending {
+		queries[i] = &pb.TrendingQuery{Query: t.Query, Count: int32(t.Count)}
+	}
+	return &pb.ListTrendingQueriesResponse{Queries: queries}, nil
+}
+
+// historyLimit checks a history call's limit, using def when it is unset
+func historyLimit(limit int32, def int) (int, error) {
+	switch {
+	case limit < 0 || limit > maxHistoryLimit:
+		return 0, status.Error(codes.InvalidArgument, fmt.Sprintf("limit must be between 0 and %d", maxHistoryLimit))
+	case limit == 0:
+		return def, nil
+	}
+	return int(limit), nil
+}
+
+// historyError maps a search store failure to a status
+func historyError(err error) error {
+	if errors.Is(err, handlers.ErrStoreDisabled) {
+		return status.Error(codes.FailedPrecondition, err.Error()+" (STORE_ENABLED not set)")
+	}
+	return status.Error(codes.Unavailable, fmt.Sprintf("reading the search store: %v", err))
+}
+
+// AdminAuthInterceptor requires the admin token on every AdminService call
+// and lets other services' calls through untouched. The token is the admin
+// API's own permission; nothing else grants it.
+func AdminAuthInterceptor(cfg config.AdminConfig) grpc.UnaryServerInterceptor {
+	return tokenAuthInterceptor(pb.AdminService_ServiceDesc.ServiceName, cfg.Token, "admin API is disabled (ADMIN_TOKEN not set)")
+}
+
+// AlertsAuthInterceptor requires the alerts token on every AlertService
+// call. Saved searches make the server post to URLs of the caller's
+// choosing and hold those URLs' secrets, so they are not open to everyone
+// who can search.
+func AlertsAuthInterceptor(cfg config.AlertsConfig) grpc.UnaryServerInterceptor {
+	return tokenAuthInterceptor(pb.AlertService_ServiceDesc.ServiceName, cfg.Token, "alerts API is disabled (ALERTS_TOKEN not set)")
+}
+
+// tokenAuthInterceptor requires token on every call to service
+func tokenAuthInterceptor(service, token, disabled string) grpc.UnaryServerInterceptor {
+	prefix := "/" + service + "/"
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if strings.HasPrefix(info.FullMethod, prefix) {
+			if err := authorizeToken(ctx, token, disabled); err != nil {
+				return nil, err
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+// authorizeToken checks the caller's bearer token against token; an empty
+// token refuses every call with the disabled message
+func authorizeToken(ctx context.Context, token, disabled string) error {
+	if token == "" {
+		return status.Error(codes.PermissionDenied, disabled)
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get("authorization") {
+		presented, ok := strings.CutPrefix(value, "Bearer ")
+		if ok && subtle.ConstantTimeCompare([]byte(presented), []byte(token)) == 1 {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "missing or invalid bearer token")
+}
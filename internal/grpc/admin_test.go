@@ -0,0 +1,309 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/farhapartex/search-proxy/internal/config"
+	"github.com/farhapartex/search-proxy/internal/logging"
+	"github.com/farhapartex/search-proxy/internal/testutil"
+	pb "github.com/farhapartex/search-proxy/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestAdminAuthInterceptorRequiresToken(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		token  string
+		method string
+		header string
+		want   codes.Code
+	}{
+		{"disabled", "", "/search.AdminService/FlushCache", "Bearer anything", codes.PermissionDenied},
+		{"missing header", "s3cret", "/search.AdminService/FlushCache", "", codes.Unauthenticated},
+		{"wrong token", "s3cret", "/search.AdminService/UpdateCredentials", "Bearer guess", codes.Unauthenticated},
+		{"not bearer", "s3cret", "/search.AdminService/GetConfig", "s3cret", codes.Unauthenticated},
+		{"valid", "s3cret", "/search.AdminService/GetConfig", "Bearer s3cret", codes.OK},
+		{"search call", "s3cret", "/search.SearchService/FederatedSearch", "", codes.OK},
+		{"search call, admin disabled", "", "/search.SearchService/ListPlatforms", "", codes.OK},
+		{"alerts call", "s3cret", "/search.AlertService/ListSavedSearches", "", codes.OK},
+	} {
+		interceptor := AdminAuthInterceptor(config.AdminConfig{Token: tc.token})
+		ctx := context.Background()
+		if tc.header != "" {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", tc.header))
+		}
+
+		called := false
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tc.method}, func(ctx context.Context, req any) (any, error) {
+			called = true
+			return nil, nil
+		})
+		if got := status.Code(err); got != tc.want {
+			t.Errorf("%s: code = %v, want %v", tc.name, got, tc.want)
+		}
+		if called != (tc.want == codes.OK) {
+			t.Errorf("%s: handler called = %t, want %t", tc.name, called, tc.want == codes.OK)
+		}
+	}
+}
+
+func TestAlertsAuthInterceptorRequiresToken(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		token  string
+		method string
+		header string
+		want   codes.Code
+	}{
+		{"disabled", "", "/search.AlertService/ListSavedSearches", "Bearer anything", codes.PermissionDenied},
+		{"missing header", "s3cret", "/search.AlertService/CreateSavedSearch", "", codes.Unauthenticated},
+		{"admin token", "s3cret", "/search.AlertService/DeleteSavedSearch", "Bearer admin", codes.Unauthenticated},
+		{"valid", "s3cret", "/search.AlertService/ListSavedSearches", "Bearer s3cret", codes.OK},
+		{"search call", "", "/search.SearchService/FederatedSearch", "", codes.OK},
+	} {
+		interceptor := AlertsAuthInterceptor(config.AlertsConfig{Token: tc.token})
+		ctx := context.Background()
+		if tc.header != "" {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", tc.header))
+		}
+
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tc.method}, func(ctx context.Context, req any) (any, error) {
+			return nil, nil
+		})
+		if got := status.Code(err); got != tc.want {
+			t.Errorf("%s: code = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestAdminServerUpdateCredentialsCodes(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		status int
+		down   bool
+		want   codes.Code
+	}{
+		{name: "rejected", status: http.StatusUnauthorized, want: codes.InvalidArgument},
+		{name: "server error", status: http.StatusServiceUnavailable, want: codes.Unavailable},
+		{name: "unreachable", down: true, want: codes.Unavailable},
+	} {
+		upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tc.status)
+		}))
+		if tc.down {
+			upstream.Close()
+		}
+		s, err := NewServer(&config.Config{
+			Server:      config.ServerConfig{MaxMultiSearchQueries: 1},
+			GitHub:      config.GitHubConfig{BaseURL: upstream.URL},
+			Performance: config.PerformanceConfig{MaxResultsPerPlatform: 10},
+		})
+		if err != nil {
+			t.Fatalf("NewServer() error = %v", err)
+		}
+
+		_, err = NewAdminServer(s).UpdateCredentials(context.Background(), &pb.UpdateCredentialsRequest{Platform: "github", Credentials: []string{"fresh"}})
+		if got := status.Code(err); got != tc.want {
+			t.Errorf("%s: UpdateCredentials() error = %v, want %v", tc.name, err, tc.want)
+		}
+		upstream.Close()
+	}
+}
+
+func TestAdminServerRejectsMalformedRedditCredentials(t *testing.T) {
+	s, _ := newTestServer(t)
+	admin := NewAdminServer(s)
+	for _, credentials := range [][]string{
+		{"client-id-only"},
+		{":secret"},
+		{"id:"},
+		{"id:secret", "other:secret"},
+	} {
+		_, err := admin.UpdateCredentials(context.Background(), &pb.UpdateCredentialsRequest{Platform: "reddit", Credentials: credentials, SkipValidation: true})
+		if got := status.Code(err); got != codes.InvalidArgument {
+			t.Errorf("UpdateCredentials(%q) error = %v, want InvalidArgument", credentials, err)
+		}
+	}
+	if _, err := admin.UpdateCredentials(context.Background(), &pb.UpdateCredentialsRequest{Platform: "reddit", Credentials: []string{"id:secret"}, SkipValidation: true}); err != nil {
+		t.Errorf("UpdateCredentials(id:secret) error = %v", err)
+	}
+}
+
+func TestAdminServerListsSearchHistory(t *testing.T) {
+	s, _ := newTestServer(t)
+	admin := NewAdminServer(s)
+	ctx := context.Background()
+	if _, err := admin.ListRecentSearches(ctx, &pb.ListRecentSearchesRequest{}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("ListRecentSearches() without a store error = %v, want FailedPrecondition", err)
+	}
+
+	github := testutil.NewGitHub(t)
+	s, err := NewServer(&config.Config{
+		Server:      config.ServerConfig{ServerTimeout: 5 * time.Second, PerAPITimeout: 5 * time.Second, MaxServerTimeout: 5 * time.Second, MaxPerAPITimeout: 5 * time.Second},
+		GitHub:      config.GitHubConfig{BaseURL: github.URL()},
+		Performance: config.PerformanceConfig{MaxResultsPerPlatform: 10},
+		Store:       config.StoreConfig{Enabled: true, Driver: "sqlite", DSN: filepath.Join(t.TempDir(), "searches.db")},
+	})
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+	admin = NewAdminServer(s)
+	for _, query := range []string{"go", "Go", "rust"} {
+		if _, err := s.FederatedSearch(ctx, &pb.SearchRequest{Query: query, Platforms: []string{"github"}}); err != nil {
+			t.Fatalf("FederatedSearch(%s) error = %v", query, err)
+		}
+	}
+	// Searches are recorded in the background
+	if err := s.Drain(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	recent, err := admin.ListRecentSearches(ctx, &pb.ListRecentSearchesRequest{Limit: 2})
+	if err != nil {
+		t.Fatalf("ListRecentSearches() error = %v", err)
+	}
+	if len(recent.Searches) != 2 || recent.Searches[0].Query != "rust" || recent.Searches[0].Platforms[0] != "github" {
+		t.Errorf("ListRecentSearches() = %v, want the 2 latest searches, rust first", recent.Searches)
+	}
+	trending, err := admin.ListTrendingQueries(ctx, &pb.ListTrendingQueriesRequest{})
+	if err != nil {
+		t.Fatalf("ListTrendingQueries() error = %v", err)
+	}
+	if q := trending.Queries; len(q) != 2 || q[0].Query != "go" || q[0].Count != 2 {
+		t.Errorf("ListTrendingQueries() = %v, want go searched twice first", q)
+	}
+
+	for _, req := range []*pb.ListTrendingQueriesRequest{{Limit: 101}, {Limit: -1}, {WindowSeconds: -1}, {WindowSeconds: 31 * 86400}} {
+		if _, err := admin.ListTrendingQueries(ctx, req); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("ListTrendingQueries(%v) error = %v, want InvalidArgument", req, err)
+		}
+	}
+}
+
+func TestAdminServerDisablesPlatform(t *testing.T) {
+	s, github := newTestServer(t)
+	admin := NewAdminServer(s)
+	ctx := context.Background()
+
+	resp, err := admin.SetPlatformEnabled(ctx, &pb.SetPlatformEnabledRequest{Platform: "gh", Enabled: false})
+	if err != nil || resp.Platform != "github" || resp.Enabled {
+		t.Fatalf("SetPlatformEnabled() = %v, %v; want github disabled", resp, err)
+	}
+
+	search, err := s.FederatedSearch(ctx, &pb.SearchRequest{Query: "go", Platforms: []string{"github"}})
+	if err != nil {
+		t.Fatalf("FederatedSearch() error = %v", err)
+	}
+	statuses := search.PlatformStatuses
+	if len(statuses) != 1 || statuses[0].State != pb.PlatformState_PLATFORM_STATE_SKIPPED || statuses[0].ErrorCode != "disabled" {
+		t.Errorf("statuses = %v, want github skipped as disabled", statuses)
+	}
+	if n := len(github.Requests()); n != 0 {
+		t.Errorf("upstream saw %d requests, want none for a disabled platform", n)
+	}
+	platforms, _ := s.ListPlatforms(ctx, &pb.ListPlatformsRequest{})
+	for _, p := range platforms.Platforms {
+		if p.Disabled != (p.Name == "github") {
+			t.Errorf("ListPlatforms() %s disabled = %t", p.Name, p.Disabled)
+		}
+	}
+
+	admin.SetPlatformEnabled(ctx, &pb.SetPlatformEnabledRequest{Platform: "github", Enabled: true})
+	if search, err := s.FederatedSearch(ctx, &pb.SearchRequest{Query: "go", Platforms: []string{"github"}}); err != nil || len(search.Results) == 0 {
+		t.Errorf("FederatedSearch() after enabling = %v, %v; want results", search, err)
+	}
+
+	if _, err := admin.SetPlatformEnabled(ctx, &pb.SetPlatformEnabledRequest{Platform: "myspace"}); status.Code(err) != codes.NotFound {
+		t.Errorf("SetPlatformEnabled(myspace) error = %v, want NotFound", err)
+	}
+}
+
+func TestAdminServerResetsCircuitBreakers(t *testing.T) {
+	s, _ := newTestServer(t)
+	admin := NewAdminServer(s)
+	ctx := context.Background()
+	if _, err := admin.ResetCircuitBreakers(ctx, &pb.ResetCircuitBreakersRequest{}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("ResetCircuitBreakers() without breakers error = %v, want FailedPrecondition", err)
+	}
+
+	github := testutil.NewGitHub(t)
+	github.Set(testutil.Behavior{StatusCode: 502})
+	s, err := NewServer(&config.Config{
+		Server:      config.ServerConfig{ServerTimeout: 5 * time.Second, PerAPITimeout: 5 * time.Second, MaxServerTimeout: 5 * time.Second, MaxPerAPITimeout: 5 * time.Second},
+		GitHub:      config.GitHubConfig{BaseURL: github.URL()},
+		Performance: config.PerformanceConfig{MaxResultsPerPlatform: 10, EnableCircuitBreaker: true, CircuitBreakerThreshold: 1, CircuitBreakerTimeout: time.Minute},
+		Limits:      config.LimitsConfig{DefaultSnippetLength: 500},
+	})
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+	admin = NewAdminServer(s)
+	search := func() {
+		s.FederatedSearch(ctx, &pb.SearchRequest{Query: "go", Platforms: []string{"github"}})
+	}
+
+	search()
+	search()
+	if n := len(github.Requests()); n != 1 {
+		t.Fatalf("upstream saw %d requests, want the open breaker to stop the second", n)
+	}
+
+	resp, err := admin.ResetCircuitBreakers(ctx, &pb.ResetCircuitBreakersRequest{Platforms: []string{"gh"}})
+	if err != nil || len(resp.Platforms) != 1 || resp.Platforms[0] != "github" {
+		t.Fatalf("ResetCircuitBreakers() = %v, %v; want github reset", resp, err)
+	}
+	search()
+	if n := len(github.Requests()); n != 2 {
+		t.Errorf("upstream saw %d requests, want the reset breaker to let a search through", n)
+	}
+
+	if _, err := admin.ResetCircuitBreakers(ctx, &pb.ResetCircuitBreakersRequest{Platforms: []string{"myspace"}}); status.Code(err) != codes.NotFound {
+		t.Errorf("ResetCircuitBreakers(myspace) error = %v, want NotFound", err)
+	}
+}
+
+func TestAdminServerSetsLogLevel(t *testing.T) {
+	admin := &AdminServer{}
+	defer logging.SetLevel(logging.CurrentLevel())
+	logging.SetLevel(logging.LevelInfo)
+
+	resp, err := admin.SetLogLevel(context.Background(), &pb.SetLogLevelRequest{Level: "warn"})
+	if err != nil || resp.Level != "warn" || resp.PreviousLevel != "info" {
+		t.Fatalf("SetLogLevel(warn) = %v, %v", resp, err)
+	}
+	if level := logging.CurrentLevel(); level != logging.LevelWarn {
+		t.Errorf("level = %v, want warn", level)
+	}
+
+	if _, err := admin.SetLogLevel(context.Background(), &pb.SetLogLevelRequest{Level: "loud"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("SetLogLevel(loud) error = %v, want InvalidArgument", err)
+	}
+}
+
+func TestAdminServerRedactsConfig(t *testing.T) {
+	admin := &AdminServer{config: &config.Config{
+		GitHub: config.GitHubConfig{APIToken: "ghp_secret"},
+		Admin:  config.AdminConfig{Token: "s3cret"},
+	}}
+
+	resp, err := admin.GetConfig(context.Background(), &pb.GetConfigRequest{})
+	if err != nil {
+		t.Fatalf("GetConfig() error = %v", err)
+	}
+	var cfg map[string]map[string]any
+	if err := json.Unmarshal([]byte(resp.ConfigJson), &cfg); err != nil {
+		t.Fatalf("config_json is not JSON: %v", err)
+	}
+	if cfg["GitHub"]["APIToken"] != "REDACTED" || cfg["Admin"]["Token"] != "REDACTED" {
+		t.Errorf("config = %s, want the tokens redacted", resp.ConfigJson)
+	}
+}
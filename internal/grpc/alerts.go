@@ -0,0 +1,177 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/netip"
+	"net/url"
+	"time"
+
+	"github.com/farhapartex/search-proxy/internal/alerts"
+	pb "github.com/farhapartex/search-proxy/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// AlertServer implements AlertService: saved searches kept in SQLite and
+// re-run by a scheduler through the search server, so scheduled runs are
+// validated, limited and drained like any other search
+type AlertServer struct {
+	pb.UnimplementedAlertServiceServer
+	server    *Server
+	store     *alerts.Store
+	scheduler *alerts.Scheduler
+	now       func() time.Time
+	// lookupHost resolves webhook hosts to check they are public
+	lookupHost func(ctx context.Context, host string) ([]netip.Addr, error)
+}
+
+// NewAlertServer opens the saved search database and starts the scheduler.
+// The search server stops the scheduler when it drains and closes the
+// database when it closes.
+func NewAlertServer(s *Server) (*AlertServer, error) {
+	cfg := s.config.Alerts
+	store, err := alerts.Open(context.Background(), cfg.DBPath)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &AlertServer{server: s, store: store, now: time.Now, lookupHost: alerts.LookupHost}
+	a.scheduler = alerts.NewScheduler(store, a.scheduledSearch,
+		alerts.WebhookClient(cfg.WebhookTimeout, cfg.AllowPrivateWebhooks), cfg.PollInterval)
+	a.scheduler.Start()
+	s.alerts = a
+	return a, nil
+}
+
+// scheduledSearch runs a saved search as FederatedSearch would
+func (a *AlertServer) scheduledSearch(ctx context.Context, req *pb.SearchRequest) (*pb.SearchResponse, error) {
+	ctx, end, ok := a.server.drain.begin(ctx)
+	if !ok {
+		return nil, errDraining
+	}
+	defer end()
+
+	// Validation rewrites aliases in place; the stored request stays as is
+	return a.server.search(ctx, proto.Clone(req).(*pb.SearchRequest))
+}
+
+func (a *AlertServer) CreateSavedSearch(ctx context.Context, req *pb.CreateSavedSearchRequest) (*pb.CreateSavedSearchResponse, error) {
+	if req.Search == nil {
+		return nil, status.Error(codes.InvalidArgument, "search is required")
+	}
+	if err := a.server.validateSearchRequest(req.Search); err != nil {
+		return nil, err
+	}
+	schedule, err := alerts.ParseSchedule(req.Schedule)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := a.validateWebhookURL(ctx, req.WebhookUrl); err != nil {
+		return nil, err
+	}
+
+	count, err := a.store.Count(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if limit := a.server.config.Alerts.MaxSavedSearches; count >= limit {
+		return nil, status.Error(codes.ResourceExhausted, fmt.Sprintf("too many saved searches (max %d)", limit))
+	}
+
+	now := a.now()
+	saved := &alerts.SavedSearch{
+		Name:       req.Name,
+		Request:    req.Search,
+		Schedule:   req.Schedule,
+		WebhookURL: req.WebhookUrl,
+		CreatedAt:  now,
+		NextRunAt:  schedule.Next(now),
+	}
+	if err := a.store.Create(ctx, saved); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	log.Printf("Saved search %d created: schedule=%q", saved.ID, saved.Schedule)
+	return &pb.CreateSavedSearchResponse{SavedSearch: savedSearchProto(saved)}, nil
+}
+
+func (a *AlertServer) ListSavedSearches(ctx context.Context, req *pb.ListSavedSearchesRequest) (*pb.ListSavedSearchesResponse, error) {
+	saved, err := a.store.List(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	resp := &pb.ListSavedSearchesResponse{SavedSearches: make([]*pb.SavedSearch, len(saved))}
+	for i, search := range saved {
+		resp.SavedSearches[i] = savedSearchProto(search)
+	}
+	return resp, nil
+}
+
+func (a *AlertServer) DeleteSavedSearch(ctx context.Context, req *pb.DeleteSavedSearchRequest) (*pb.DeleteSavedSearchResponse, error) {
+	deleted, err := a.store.Delete(ctx, req.Id)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if !deleted {
+		return nil, status.Error(codes.NotFound, fmt.Sprintf("saved search %d not found", req.Id))
+	}
+	log.Printf("Saved search %d deleted", req.Id)
+	return &pb.DeleteSavedSearchResponse{}, nil
+}
+
+// stop stops the scheduler; runs it cancels are retried once the server is
+// back, as they stay due
+func (a *AlertServer) stop() {
+	if a != nil {
+		a.scheduler.Stop()
+	}
+}
+
+// close stops the scheduler and closes the database
+func (a *AlertServer) close() {
+	if a == nil {
+		return
+	}
+	a.scheduler.Stop()
+	if err := a.store.Close(); err != nil {
+		log.Printf("WARNING: Failed to close alerts database: %v", err)
+	}
+}
+
+// validateWebhookURL requires an absolute http or https URL whose host
+// resolves to public addresses only, unless private webhooks are allowed.
+// The webhook client checks the address again as it connects.
+func (a *AlertServer) validateWebhookURL(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return status.Error(codes.InvalidArgument, "webhook_url must be an http or https URL")
+	}
+	if a.server.config.Alerts.AllowPrivateWebhooks {
+		return nil
+	}
+	if err := alerts.CheckWebhookHost(ctx, raw, a.lookupHost); err != nil {
+		return status.Error(codes.InvalidArgument, fmt.Sprintf("webhook_url: %v", err))
+	}
+	return nil
+}
+
+// savedSearchProto converts a saved search for responses. Webhook URLs often
+// carry the receiver's secret, so only their scheme and host are returned.
+func savedSearchProto(saved *alerts.SavedSearch) *pb.SavedSearch {
+	var lastRun int64
+	if !saved.LastRunAt.IsZero() {
+		lastRun = saved.LastRunAt.Unix()
+	}
+	return &pb.SavedSearch{
+		Id:         saved.ID,
+		Name:       saved.Name,
+		Search:     saved.Request,
+		Schedule:   saved.Schedule,
+		WebhookUrl: alerts.RedactWebhookURL(saved.WebhookURL),
+		CreatedAt:  saved.CreatedAt.Unix(),
+		LastRunAt:  lastRun,
+		NextRunAt:  saved.NextRunAt.Unix(),
+	}
+}
@@ -0,0 +1,120 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/farhapartex/search-proxy/internal/config"
+	pb "github.com/farhapartex/search-proxy/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newTestAlertServer(t *testing.T) *AlertServer {
+	t.Helper()
+	s, _ := newTestServer(t)
+	s.config.Alerts = config.AlertsConfig{
+		DBPath:           filepath.Join(t.TempDir(), "alerts.db"),
+		PollInterval:     time.Hour,
+		WebhookTimeout:   time.Second,
+		MaxSavedSearches: 2,
+	}
+	alerts, err := NewAlertServer(s)
+	if err != nil {
+		t.Fatalf("NewAlertServer() error = %v", err)
+	}
+	alerts.lookupHost = func(ctx context.Context, host string) ([]netip.Addr, error) {
+		if host == "hooks.example.com" {
+			return []netip.Addr{netip.MustParseAddr("93.184.216.34")}, nil
+		}
+		addr, err := netip.ParseAddr(host)
+		if err != nil {
+			return nil, fmt.Errorf("no such host %s", host)
+		}
+		return []netip.Addr{addr}, nil
+	}
+	t.Cleanup(s.Close)
+	return alerts
+}
+
+func TestAlertServerManagesSavedSearches(t *testing.T) {
+	alerts := newTestAlertServer(t)
+	ctx := context.Background()
+	now := time.Date(2024, 5, 15, 10, 7, 0, 0, time.UTC)
+	alerts.now = func() time.Time { return now }
+
+	created, err := alerts.CreateSavedSearch(ctx, &pb.CreateSavedSearchRequest{
+		Name:       "grpc",
+		Search:     &pb.SearchRequest{Query: "grpc", Platforms: []string{"gh"}},
+		Schedule:   "0 * * * *",
+		WebhookUrl: "https://hooks.example.com/search?token=s3cret",
+	})
+	if err != nil {
+		t.Fatalf("CreateSavedSearch() error = %v", err)
+	}
+	saved := created.SavedSearch
+	if saved.Id == 0 || saved.NextRunAt != time.Date(2024, 5, 15, 11, 0, 0, 0, time.UTC).Unix() || saved.LastRunAt != 0 {
+		t.Errorf("saved search = %v, want an ID and the next run at 11:00", saved)
+	}
+	if got := saved.Search.Platforms; len(got) != 1 || got[0] != "github" {
+		t.Errorf("saved platforms = %v, want aliases resolved", got)
+	}
+
+	list, err := alerts.ListSavedSearches(ctx, &pb.ListSavedSearchesRequest{})
+	if err != nil || len(list.SavedSearches) != 1 || list.SavedSearches[0].Name != "grpc" {
+		t.Fatalf("ListSavedSearches() = %v, %v; want the saved search", list, err)
+	}
+	if got := list.SavedSearches[0].WebhookUrl; got != "https://hooks.example.com/REDACTED" {
+		t.Errorf("listed webhook_url = %q, want it redacted", got)
+	}
+
+	if _, err := alerts.DeleteSavedSearch(ctx, &pb.DeleteSavedSearchRequest{Id: saved.Id}); err != nil {
+		t.Fatalf("DeleteSavedSearch() error = %v", err)
+	}
+	if _, err := alerts.DeleteSavedSearch(ctx, &pb.DeleteSavedSearchRequest{Id: saved.Id}); status.Code(err) != codes.NotFound {
+		t.Errorf("second DeleteSavedSearch() error = %v, want NotFound", err)
+	}
+}
+
+func TestAlertServerValidatesSavedSearches(t *testing.T) {
+	alerts := newTestAlertServer(t)
+	ctx := context.Background()
+
+	valid := func() *pb.CreateSavedSearchRequest {
+		return &pb.CreateSavedSearchRequest{
+			Search:     &pb.SearchRequest{Query: "go"},
+			Schedule:   "@daily",
+			WebhookUrl: "https://hooks.example.com/hook",
+		}
+	}
+	for name, tc := range map[string]func(*pb.CreateSavedSearchRequest){
+		"no search":        func(r *pb.CreateSavedSearchRequest) { r.Search = nil },
+		"blank query":      func(r *pb.CreateSavedSearchRequest) { r.Search.Query = " " },
+		"bad schedule":     func(r *pb.CreateSavedSearchRequest) { r.Schedule = "every day" },
+		"no webhook":       func(r *pb.CreateSavedSearchRequest) { r.WebhookUrl = "" },
+		"non-http webhook": func(r *pb.CreateSavedSearchRequest) { r.WebhookUrl = "file:///etc/passwd" },
+		"loopback webhook": func(r *pb.CreateSavedSearchRequest) { r.WebhookUrl = "http://127.0.0.1:9000/hook" },
+		"metadata webhook": func(r *pb.CreateSavedSearchRequest) { r.WebhookUrl = "http://169.254.169.254/latest" },
+		"private webhook":  func(r *pb.CreateSavedSearchRequest) { r.WebhookUrl = "http://10.0.0.7/hook" },
+		"unresolvable":     func(r *pb.CreateSavedSearchRequest) { r.WebhookUrl = "http://nowhere.invalid/hook" },
+	} {
+		req := valid()
+		tc(req)
+		if _, err := alerts.CreateSavedSearch(ctx, req); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("%s: CreateSavedSearch() error = %v, want InvalidArgument", name, err)
+		}
+	}
+
+	for range 2 {
+		if _, err := alerts.CreateSavedSearch(ctx, valid()); err != nil {
+			t.Fatalf("CreateSavedSearch() error = %v", err)
+		}
+	}
+	if _, err := alerts.CreateSavedSearch(ctx, valid()); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("CreateSavedSearch() past the limit error = %v, want ResourceExhausted", err)
+	}
+}
@@ -0,0 +1,101 @@
+package grpc
+
+import (
+	"context"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// drainState tracks in-flight searches so shutdown can wait for them once it
+// stops admitting new ones, and cancel those still running at its deadline
+type drainState struct {
+	mu       sync.Mutex
+	draining bool
+	inFlight sync.WaitGroup
+	// cancels holds the cancel function of each in-flight search by the
+	// number begin gave it
+	cancels map[uint64]context.CancelCauseFunc
+	next    uint64
+}
+
+// errDraining is returned to searches that arrive during shutdown, or are
+// cancelled by it, so clients retry against another instance
+var errDraining = status.Error(codes.Unavailable, "server is shutting down")
+
+// begin admits a search, returning false once draining has started. An
+// admitted search runs under the returned context, which shutdown cancels
+// if the search outlives the drain deadline, and must call end.
+func (d *drainState) begin(ctx context.Context) (_ context.Context, end func(), ok bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.draining {
+		return ctx, nil, false
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	if d.cancels == nil {
+		d.cancels = make(map[uint64]context.CancelCauseFunc)
+	}
+	id := d.next
+	d.next++
+	d.cancels[id] = cancel
+	d.inFlight.Add(1)
+
+	return ctx, func() {
+		d.mu.Lock()
+		delete(d.cancels, id)
+		d.mu.Unlock()
+		cancel(nil)
+		d.inFlight.Done()
+	}, true
+}
+
+// abort cancels every in-flight search with errDraining as the cause
+func (d *drainState) abort() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, cancel := range d.cancels {
+		cancel(errDraining)
+	}
+}
+
+// isDraining reports whether shutdown has started
+func (d *drainState) isDraining() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.draining
+}
+
+// Drain stops admitting searches, then waits for in-flight searches and the
+// background work they started, until ctx ends. Searches still running then
+// are cancelled and fail with UNAVAILABLE. Health checks report "draining"
+// from the moment it is called.
+func (s *Server) Drain(ctx context.Context) error {
+	s.alerts.stop()
+
+	s.drain.mu.Lock()
+	s.drain.draining = true
+	s.drain.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.drain.inFlight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.drain.abort()
+		return ctx.Err()
+	}
+	return s.searchHandler.Drain(ctx)
+}
+
+// Close releases the upstream connections once the server has stopped
+func (s *Server) Close() {
+	s.alerts.close()
+	s.searchHandler.Close()
+}
@@ -0,0 +1,64 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/farhapartex/search-proxy/internal/handlers"
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+func TestDrainRefusesNewSearchesAndWaitsForInFlight(t *testing.T) {
+	s := &Server{searchHandler: &handlers.SearchHandler{}}
+
+	_, end, ok := s.drain.begin(context.Background())
+	if !ok {
+		t.Fatal("begin() refused a search before draining")
+	}
+
+	drained := make(chan error, 1)
+	go func() { drained <- s.Drain(context.Background()) }()
+
+	// Wait for draining to start, then check new work is refused
+	for !s.drain.isDraining() {
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := s.FederatedSearch(context.Background(), &pb.SearchRequest{Query: "go"}); !errors.Is(err, errDraining) {
+		t.Errorf("FederatedSearch() while draining error = %v, want %v", err, errDraining)
+	}
+	if health, _ := s.HealthCheck(context.Background(), &pb.HealthCheckRequest{}); health.Status != "draining" {
+		t.Errorf("HealthCheck() status = %q, want draining", health.Status)
+	}
+
+	select {
+	case err := <-drained:
+		t.Fatalf("Drain() returned %v with a search still in flight", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	end()
+	if err := <-drained; err != nil {
+		t.Errorf("Drain() error = %v", err)
+	}
+}
+
+func TestDrainCancelsSearchesAtDeadline(t *testing.T) {
+	s := &Server{searchHandler: &handlers.SearchHandler{}}
+	searchCtx, end, _ := s.drain.begin(context.Background())
+	defer end()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := s.Drain(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Drain() error = %v, want context.DeadlineExceeded", err)
+	}
+
+	if searchCtx.Err() == nil {
+		t.Fatal("in-flight search was not cancelled at the drain deadline")
+	}
+	if err := searchError(searchCtx, searchCtx.Err()); !errors.Is(err, errDraining) {
+		t.Errorf("searchError() = %v, want %v", err, errDraining)
+	}
+}
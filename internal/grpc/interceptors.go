@@ -0,0 +1,207 @@
+package grpc
+
+import (
+	"context"
+	"maps"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/farhapartex/search-proxy/internal/logging"
+	"github.com/farhapartex/search-proxy/internal/requestid"
+)
+
+// errPanic is returned for a call whose handler panicked; the panic itself
+// is only logged, so internals don't leak to clients
+var errPanic = status.Error(codes.Internal, "internal error")
+
+// RequestIDUnaryInterceptor gives each call a request ID, the caller's
+// x-request-id if usable or a new one, stored in the context for the logs
+// and spans below and sent back in the response header
+func RequestIDUnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	id := requestid.FromIncoming(ctx)
+	grpc.SetHeader(ctx, metadata.Pairs(requestid.MetadataKey, id))
+	return handler(requestid.NewContext(ctx, id), req)
+}
+
+// RequestIDStreamInterceptor is RequestIDUnaryInterceptor for streaming
+// calls
+func RequestIDStreamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	id := requestid.FromIncoming(ss.Context())
+	ss.SetHeader(metadata.Pairs(requestid.MetadataKey, id))
+	return handler(srv, &contextStream{ServerStream: ss, ctx: requestid.NewContext(ss.Context(), id)})
+}
+
+// contextStream is a server stream with its context replaced
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+// RecoveryUnaryInterceptor turns a panic in a handler into an INTERNAL
+// error for that call, logged with its stack, instead of a crashed process
+func RecoveryUnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	defer recoverCall(ctx, info.FullMethod, &err)
+	return handler(ctx, req)
+}
+
+// RecoveryStreamInterceptor is RecoveryUnaryInterceptor for streaming calls
+func RecoveryStreamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer recoverCall(ss.Context(), info.FullMethod, &err)
+	return handler(srv, ss)
+}
+
+// recoverCall must be deferred directly by the interceptor, as recover only
+// stops a panic there
+func recoverCall(ctx context.Context, method string, err *error) {
+	if p := recover(); p != nil {
+		logging.Printf(ctx, "ERROR: Panic in %s: %v\n%s", method, p, debug.Stack())
+		*err = errPanic
+	}
+}
+
+// LoggingUnaryInterceptor logs each call's method, status code, latency and
+// message sizes as key=value pairs. Queries and other request fields are
+// never logged.
+func LoggingUnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	logCall(ctx, info.FullMethod, status.Code(err), time.Since(start), messageSize(req), messageSize(resp))
+	return resp, err
+}
+
+// LoggingStreamInterceptor is LoggingUnaryInterceptor for streaming calls;
+// message sizes aren't known, so they are left out
+func LoggingStreamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	logCall(ss.Context(), info.FullMethod, status.Code(err), time.Since(start), -1, -1)
+	return err
+}
+
+// serverFault reports whether code means the server, not the request, is
+// at fault
+func serverFault(code codes.Code) bool {
+	switch code {
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unimplemented:
+		return true
+	}
+	return false
+}
+
+func logCall(ctx context.Context, method string, code codes.Code, duration time.Duration, requestBytes, responseBytes int) {
+	prefix := ""
+	if serverFault(code) {
+		prefix = "ERROR: "
+	}
+	if requestBytes < 0 {
+		logging.Printf(ctx, "%srpc method=%s code=%s duration_ms=%.1f", prefix, method, code, durationMs(duration))
+		return
+	}
+	logging.Printf(ctx, "%srpc method=%s code=%s duration_ms=%.1f request_bytes=%d response_bytes=%d",
+		prefix, method, code, durationMs(duration), requestBytes, responseBytes)
+}
+
+func durationMs(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// messageSize is the encoded size of a protobuf message, 0 for none
+func messageSize(m any) int {
+	if msg, ok := m.(proto.Message); ok {
+		return proto.Size(msg)
+	}
+	return 0
+}
+
+// RPCStats is a point-in-time view of the calls to one method
+type RPCStats struct {
+	Calls int64
+	// Errors counts calls that ended with any code but OK
+	Errors       int64
+	Codes        map[string]int64
+	TotalLatency time.Duration
+	MaxLatency   time.Duration
+}
+
+// AvgLatency is the mean latency of the calls, or 0 before any
+func (s RPCStats) AvgLatency() time.Duration {
+	if s.Calls == 0 {
+		return 0
+	}
+	return s.TotalLatency / time.Duration(s.Calls)
+}
+
+// RPCMetrics counts calls, status codes and latency per gRPC method
+type RPCMetrics struct {
+	mu      sync.Mutex
+	methods map[string]*RPCStats
+}
+
+// NewRPCMetrics creates empty RPC metrics
+func NewRPCMetrics() *RPCMetrics {
+	return &RPCMetrics{methods: make(map[string]*RPCStats)}
+}
+
+// UnaryInterceptor records every unary call
+func (m *RPCMetrics) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		m.record(info.FullMethod, status.Code(err), time.Since(start))
+		return resp, err
+	}
+}
+
+// StreamInterceptor records every streaming call, timed until the stream
+// ends
+func (m *RPCMetrics) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		m.record(info.FullMethod, status.Code(err), time.Since(start))
+		return err
+	}
+}
+
+func (m *RPCMetrics) record(method string, code codes.Code, latency time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats, ok := m.methods[method]
+	if !ok {
+		stats = &RPCStats{Codes: make(map[string]int64)}
+		m.methods[method] = stats
+	}
+	stats.Calls++
+	if code != codes.OK {
+		stats.Errors++
+	}
+	stats.Codes[code.String()]++
+	stats.TotalLatency += latency
+	stats.MaxLatency = max(stats.MaxLatency, latency)
+}
+
+// Snapshot returns a copy of the stats of every method called so far
+func (m *RPCMetrics) Snapshot() map[string]RPCStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	out := make(map[string]RPCStats, len(m.methods))
+	for method, stats := range m.methods {
+		copied := *stats
+		copied.Codes = maps.Clone(stats.Codes)
+		out[method] = copied
+	}
+	return out
+}
@@ -0,0 +1,97 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/farhapartex/search-proxy/internal/requestid"
+	pb "github.com/farhapartex/search-proxy/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// testStream is a server stream with just a context and headers
+type testStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	header metadata.MD
+}
+
+func (s *testStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *testStream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func TestRequestIDInterceptorsStoreAndReturnID(t *testing.T) {
+	incoming := metadata.NewIncomingContext(context.Background(), metadata.Pairs(requestid.MetadataKey, "req-1"))
+	var got string
+	RequestIDUnaryInterceptor(incoming, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, _ any) (any, error) {
+		got = requestid.FromContext(ctx)
+		return nil, nil
+	})
+	if got != "req-1" {
+		t.Errorf("unary request ID = %q, want the caller's", got)
+	}
+
+	stream := &testStream{ctx: context.Background()}
+	got = ""
+	RequestIDStreamInterceptor(nil, stream, &grpc.StreamServerInfo{}, func(_ any, ss grpc.ServerStream) error {
+		got = requestid.FromContext(ss.Context())
+		return nil
+	})
+	if got == "" {
+		t.Fatal("stream request ID is empty, want a generated one")
+	}
+	if sent := stream.header.Get(requestid.MetadataKey); len(sent) != 1 || sent[0] != got {
+		t.Errorf("header %s = %v, want %q", requestid.MetadataKey, sent, got)
+	}
+}
+
+func TestRecoveryInterceptorTurnsPanicIntoInternal(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/search.SearchService/FederatedSearch"}
+	_, err := RecoveryUnaryInterceptor(context.Background(), &pb.SearchRequest{}, info, func(context.Context, any) (any, error) {
+		panic("nil map")
+	})
+	if status.Code(err) != codes.Internal {
+		t.Errorf("error = %v, want INTERNAL", err)
+	}
+	if status.Convert(err).Message() == "nil map" {
+		t.Error("panic value leaked to the client")
+	}
+
+	streamInfo := &grpc.StreamServerInfo{FullMethod: "/search.SearchService/SearchStream"}
+	err = RecoveryStreamInterceptor(nil, &testStream{ctx: context.Background()}, streamInfo, func(any, grpc.ServerStream) error {
+		panic("closed channel")
+	})
+	if status.Code(err) != codes.Internal {
+		t.Errorf("stream error = %v, want INTERNAL", err)
+	}
+}
+
+func TestRPCMetricsCountCallsAndCodes(t *testing.T) {
+	metrics := NewRPCMetrics()
+	interceptor := metrics.UnaryInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/search.SearchService/FederatedSearch"}
+	for _, err := range []error{nil, nil, status.Error(codes.InvalidArgument, "empty query")} {
+		interceptor(context.Background(), nil, info, func(context.Context, any) (any, error) {
+			return nil, err
+		})
+	}
+
+	stats := metrics.Snapshot()[info.FullMethod]
+	if stats.Calls != 3 || stats.Errors != 1 || stats.Codes["OK"] != 2 || stats.Codes["InvalidArgument"] != 1 {
+		t.Errorf("stats = %+v, want 3 calls, 2 OK and 1 InvalidArgument", stats)
+	}
+
+	// The snapshot is a copy
+	stats.Codes["OK"] = 100
+	if metrics.Snapshot()[info.FullMethod].Codes["OK"] != 2 {
+		t.Error("changing a snapshot changed the metrics")
+	}
+}
@@ -2,13 +2,30 @@ package grpc
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"runtime/debug"
+	"sync"
 	"time"
+	"unicode/utf8"
 
+	"github.com/farhapartex/search-proxy/internal/cache"
 	"github.com/farhapartex/search-proxy/internal/config"
+	"github.com/farhapartex/search-proxy/internal/fetchers"
 	"github.com/farhapartex/search-proxy/internal/handlers"
+	"github.com/farhapartex/search-proxy/internal/httpclient"
+	"github.com/farhapartex/search-proxy/internal/logging"
+	"github.com/farhapartex/search-proxy/internal/ratelimit"
+	"github.com/farhapartex/search-proxy/internal/requestid"
+	"github.com/farhapartex/search-proxy/internal/tracing"
+	"github.com/farhapartex/search-proxy/internal/version"
+	"github.com/farhapartex/search-proxy/internal/workpool"
 	pb "github.com/farhapartex/search-proxy/proto"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -17,74 +34,346 @@ type Server struct {
 	pb.UnimplementedSearchServiceServer
 	searchHandler *handlers.SearchHandler
 	config        *config.Config
+	drain         drainState
+	rpcMetrics    *RPCMetrics
+	// alerts runs saved searches; nil unless ALERTS_ENABLED
+	alerts *AlertServer
 }
 
-func NewServer(cfg *config.Config) *Server {
+func NewServer(cfg *config.Config) (*Server, error) {
+	searchHandler, err := handlers.NewSearchHandler(cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Server{
-		searchHandler: handlers.NewSearchHandler(cfg),
+		searchHandler: searchHandler,
 		config:        cfg,
+		rpcMetrics:    NewRPCMetrics(),
+	}, nil
+}
+
+func (s *Server) FederatedSearch(ctx context.Context, req *pb.SearchRequest) (response *pb.SearchResponse, err error) {
+	ctx, span := startSearchSpan(ctx, "FederatedSearch", req)
+	defer func() { endSearchSpan(span, response, err) }()
+
+	ctx, end, ok := s.drain.begin(ctx)
+	if !ok {
+		return nil, errDraining
 	}
+	defer end()
+
+	return s.search(ctx, req)
 }
 
-func (s *Server) FederatedSearch(ctx context.Context, req *pb.SearchRequest) (*pb.SearchResponse, error) {
+// search validates and runs one search within its time budget
+func (s *Server) search(ctx context.Context, req *pb.SearchRequest) (*pb.SearchResponse, error) {
 	if err := s.validateSearchRequest(req); err != nil {
 		return nil, err
 	}
 
-	log.Printf("Received search request: query=%q, max_results=%d, platforms=%v",
+	logging.Printf(ctx, "Received search request: query=%q, max_results=%d, platforms=%v",
 		req.Query, req.MaxResults, req.Platforms)
 
-	searchCtx, cancel := context.WithTimeout(ctx, s.config.Server.ServerTimeout)
+	searchCtx, cancel := context.WithTimeout(ctx, s.searchTimeout(req))
 	defer cancel()
 
 	response, err := s.searchHandler.Search(searchCtx, req)
 	if err != nil {
-		log.Printf("Search failed: %v", err)
-		return nil, status.Error(codes.Internal, fmt.Sprintf("search failed: %v", err))
+		return nil, searchError(ctx, err)
 	}
 
 	return response, nil
 }
 
+// MultiSearch runs every search of the batch at once, each as FederatedSearch
+// would. Only a malformed batch fails the call; a failed search is reported
+// in its own result.
+func (s *Server) MultiSearch(ctx context.Context, req *pb.MultiSearchRequest) (*pb.MultiSearchResponse, error) {
+	ctx, span := tracing.Tracer().Start(tracing.FromIncoming(ctx), "MultiSearch",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.Int("search.batch_size", len(req.Searches)),
+			attribute.String("request.id", requestid.FromContext(ctx)),
+		))
+	defer span.End()
+
+	ctx, end, ok := s.drain.begin(ctx)
+	if !ok {
+		return nil, errDraining
+	}
+	defer end()
+
+	if len(req.Searches) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "searches cannot be empty")
+	}
+	if maxSearches := s.config.Server.MaxMultiSearchQueries; len(req.Searches) > maxSearches {
+		return nil, status.Error(codes.InvalidArgument,
+			fmt.Sprintf("too many searches (max %d)", maxSearches))
+	}
+
+	logging.Printf(ctx, "Received multi-search request: searches=%d", len(req.Searches))
+
+	results := make([]*pb.MultiSearchResult, len(req.Searches))
+	var wg sync.WaitGroup
+	for i, search := range req.Searches {
+		wg.Go(func() {
+			// A panic fails its own search, not the batch or the process
+			defer func() {
+				if p := recover(); p != nil {
+					logging.Printf(ctx, "ERROR: Panic in multi-search %d: %v\n%s", i, p, debug.Stack())
+					st := status.Convert(errPanic)
+					results[i] = &pb.MultiSearchResult{ErrorCode: int32(st.Code()), ErrorMessage: st.Message()}
+				}
+			}()
+			results[i] = s.multiSearchResult(ctx, search)
+		})
+	}
+	wg.Wait()
+
+	return &pb.MultiSearchResponse{Results: results}, nil
+}
+
+// multiSearchResult runs one search of a batch under its own span
+func (s *Server) multiSearchResult(ctx context.Context, req *pb.SearchRequest) *pb.MultiSearchResult {
+	if req == nil {
+		return &pb.MultiSearchResult{ErrorCode: int32(codes.InvalidArgument), ErrorMessage: "search is required"}
+	}
+
+	ctx, span := tracing.Tracer().Start(ctx, "search", trace.WithAttributes(searchAttributes(req)...))
+	response, err := s.search(ctx, req)
+	endSearchSpan(span, response, err)
+
+	if err != nil {
+		st := status.Convert(err)
+		return &pb.MultiSearchResult{ErrorCode: int32(st.Code()), ErrorMessage: st.Message()}
+	}
+	return &pb.MultiSearchResult{Response: response}
+}
+
+// SearchStream is FederatedSearch streamed: each platform's results are sent
+// as it finishes, then the complete response
+func (s *Server) SearchStream(req *pb.SearchRequest, stream pb.SearchService_SearchStreamServer) (err error) {
+	ctx, span := startSearchSpan(stream.Context(), "SearchStream", req)
+	defer func() { endSearchSpan(span, nil, err) }()
+
+	ctx, end, ok := s.drain.begin(ctx)
+	if !ok {
+		return errDraining
+	}
+	defer end()
+
+	if err := s.validateSearchRequest(req); err != nil {
+		return err
+	}
+
+	logging.Printf(ctx, "Received search stream request: query=%q, max_results=%d, platforms=%v",
+		req.Query, req.MaxResults, req.Platforms)
+
+	searchCtx, cancel := context.WithTimeout(ctx, s.searchTimeout(req))
+	defer cancel()
+
+	if err := s.searchHandler.SearchStream(searchCtx, req, stream.Send); err != nil {
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+		return searchError(ctx, err)
+	}
+	return nil
+}
+
 func (s *Server) HealthCheck(ctx context.Context, req *pb.HealthCheckRequest) (*pb.HealthCheckResponse, error) {
 	log.Printf("Health check requested for service: %s", req.Service)
 
+	health := "healthy"
+	if s.drain.isDraining() {
+		health = "draining"
+	}
+
+	build := version.Get()
 	return &pb.HealthCheckResponse{
-		Status:    "healthy",
-		Version:   "1.0.0",
+		Status:    health,
+		Version:   build.Version,
 		Timestamp: time.Now().Unix(),
+		Commit:    build.Commit,
+		BuildDate: build.BuildDate,
 	}, nil
 }
 
-func (s *Server) validateSearchRequest(req *pb.SearchRequest) error {
-	if req.Query == "" {
-		return status.Error(codes.InvalidArgument, "query cannot be empty")
+// HTTPMetrics returns per-host statistics for upstream HTTP traffic
+func (s *Server) HTTPMetrics() map[string]httpclient.HostStats {
+	return s.searchHandler.HTTPMetrics()
+}
+
+// RPCMetrics returns the metrics its interceptors record calls to
+func (s *Server) RPCMetrics() *RPCMetrics {
+	return s.rpcMetrics
+}
+
+// RPCStats returns the calls recorded so far per gRPC method
+func (s *Server) RPCStats() map[string]RPCStats {
+	return s.rpcMetrics.Snapshot()
+}
+
+// UpstreamQuotas returns the quota each platform last reported
+func (s *Server) UpstreamQuotas() map[string]fetchers.QuotaStatus {
+	return s.searchHandler.UpstreamQuotas()
+}
+
+// CacheStats returns the response cache counters, or false when the cache is
+// disabled
+func (s *Server) CacheStats() (cache.Stats, bool) {
+	return s.searchHandler.CacheStats()
+}
+
+func (s *Server) ListPlatforms(ctx context.Context, req *pb.ListPlatformsRequest) (*pb.ListPlatformsResponse, error) {
+	return &pb.ListPlatformsResponse{Platforms: s.searchHandler.ListPlatforms()}, nil
+}
+
+// AnswerSearch runs the search within the usual budget, then gives the LLM
+// its own timeout to answer from the results
+func (s *Server) AnswerSearch(ctx context.Context, req *pb.AnswerRequest) (*pb.AnswerResponse, error) {
+	ctx, end, ok := s.drain.begin(ctx)
+	if !ok {
+		return nil, errDraining
 	}
+	defer end()
 
-	if len(req.Query) > 500 {
-		return status.Error(codes.InvalidArgument, "query too long (max 500 characters)")
+	if !s.searchHandler.AnswersEnabled() {
+		return nil, status.Error(codes.FailedPrecondition, handlers.ErrAnswersDisabled.Error())
+	}
+	if req.Search == nil {
+		return nil, status.Error(codes.InvalidArgument, "search is required")
+	}
+	if req.MaxSources < 0 || req.MaxSources > maxAnswerSources {
+		return nil, status.Error(codes.InvalidArgument,
+			fmt.Sprintf("max_sources must be between 0 and %d", maxAnswerSources))
 	}
+	if err := s.validateSearchRequest(req.Search); err != nil {
+		return nil, err
+	}
+
+	logging.Printf(ctx, "Received answer request: query=%q, max_sources=%d", req.Search.Query, req.MaxSources)
 
-	if req.MaxResults < 0 {
-		return status.Error(codes.InvalidArgument, "max_results cannot be negative")
+	searchCtx, cancel := context.WithTimeout(ctx, s.searchTimeout(req.Search))
+	response, err := s.searchHandler.Search(searchCtx, req.Search)
+	cancel()
+	if err != nil {
+		return nil, searchError(ctx, err)
 	}
 
-	if req.MaxResults > 100 {
-		return status.Error(codes.InvalidArgument, "max_results cannot exceed 100")
+	return s.searchHandler.Answer(ctx, req.Search.Query, int(req.MaxSources), response)
+}
+
+// GetResultDetails fetches a result's full content, within the server's
+// maximum search budget since it isn't bound by the snippet-sized one
+func (s *Server) GetResultDetails(ctx context.Context, req *pb.ResultDetailsRequest) (*pb.ResultDetailsResponse, error) {
+	ctx, end, ok := s.drain.begin(ctx)
+	if !ok {
+		return nil, errDraining
 	}
+	defer end()
 
-	validPlatforms := map[string]bool{
-		"github":        true,
-		"stackoverflow": true,
-		"reddit":        true,
+	if err := validateResultDetailsRequest(req, s.searchHandler.HasPlatform, s.searchHandler.Platforms); err != nil {
+		return nil, err
 	}
 
-	for _, platform := range req.Platforms {
-		if !validPlatforms[platform] {
-			return status.Error(codes.InvalidArgument,
-				fmt.Sprintf("invalid platform: %s (valid: github, stackoverflow, reddit)", platform))
-		}
+	ctx, cancel := context.WithTimeout(ctx, s.config.Server.MaxServerTimeout)
+	defer cancel()
+	details, err := s.searchHandler.ResultDetails(ctx, req)
+	if err != nil {
+		return nil, detailsError(ctx, err)
 	}
+	return details, nil
+}
 
-	return nil
+// detailsError maps a failed details fetch to a gRPC status
+func detailsError(ctx context.Context, err error) error {
+	if context.Cause(ctx) == errDraining {
+		return errDraining
+	}
+	var statusErr *fetchers.StatusError
+	var limited *ratelimit.LimitedError
+	switch {
+	case errors.Is(err, fetchers.ErrDetailsUnsupported), errors.Is(err, fetchers.ErrUnrecognizedURL):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.As(err, &limited), errors.Is(err, workpool.ErrOverloaded):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, fetchers.ErrPlatformDisabled):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, handlers.ErrUnknownPlatform),
+		errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound:
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, "request cancelled by client")
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, "fetching result details timed out")
+	}
+	return status.Error(codes.Unavailable, fmt.Sprintf("fetching result details: %v", err))
+}
+
+// startSearchSpan starts the root span of a search, continuing the caller's
+// trace if it sent one
+func startSearchSpan(ctx context.Context, name string, req *pb.SearchRequest) (context.Context, trace.Span) {
+	return tracing.Tracer().Start(tracing.FromIncoming(ctx), name,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(searchAttributes(req)...),
+		trace.WithAttributes(attribute.String("request.id", requestid.FromContext(ctx))))
+}
+
+// searchAttributes describes a search request. The query itself is left
+// out; it may be sensitive and the logs already carry it.
+func searchAttributes(req *pb.SearchRequest) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.StringSlice("search.platforms", req.Platforms),
+		attribute.Int("search.max_results", int(req.MaxResults)),
+		attribute.Int("search.query_length", utf8.RuneCountInString(req.Query)),
+		attribute.Bool("search.paged", req.PageToken != ""),
+	}
+}
+
+// endSearchSpan records how a search ended and closes its span
+func endSearchSpan(span trace.Span, response *pb.SearchResponse, err error) {
+	defer span.End()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, status.Code(err).String())
+		return
+	}
+	if response != nil {
+		span.SetAttributes(
+			attribute.Int("search.results", len(response.Results)),
+			attribute.StringSlice("search.platforms_timeout", response.PlatformsTimeout),
+			attribute.StringSlice("search.platforms_error", response.PlatformsError),
+		)
+	}
+}
+
+// searchError maps a failed search to a gRPC status. A search cancelled by
+// shutdown fails like one refused during it.
+func searchError(ctx context.Context, err error) error {
+	if context.Cause(ctx) == errDraining {
+		return errDraining
+	}
+	if errors.Is(err, context.Canceled) {
+		return status.Error(codes.Canceled, "search cancelled by client")
+	}
+	if errors.Is(err, handlers.ErrInvalidPageToken) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	logging.Printf(ctx, "Search failed: %v", err)
+	return status.Error(codes.Internal, fmt.Sprintf("search failed: %v", err))
+}
+
+// searchTimeout is the request's own search budget, capped at the server's
+// maximum, or the server default when it sets none
+func (s *Server) searchTimeout(req *pb.SearchRequest) time.Duration {
+	if req.TimeoutMs <= 0 {
+		return s.config.Server.ServerTimeout
+	}
+	return min(time.Duration(req.TimeoutMs)*time.Millisecond, s.config.Server.MaxServerTimeout)
+}
+
+func (s *Server) validateSearchRequest(req *pb.SearchRequest) error {
+	return validateSearchRequest(req, s.searchHandler.HasPlatform, s.searchHandler.Platforms)
 }
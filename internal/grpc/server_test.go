@@ -0,0 +1,166 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/farhapartex/search-proxy/internal/config"
+	"github.com/farhapartex/search-proxy/internal/fetchers"
+	"github.com/farhapartex/search-proxy/internal/models"
+	"github.com/farhapartex/search-proxy/internal/testutil"
+	pb "github.com/farhapartex/search-proxy/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newTestServer(t *testing.T) (*Server, *testutil.Upstream) {
+	github := testutil.NewGitHub(t)
+	s, err := NewServer(&config.Config{
+		Server: config.ServerConfig{
+			ServerTimeout:         5 * time.Second,
+			PerAPITimeout:         5 * time.Second,
+			MaxServerTimeout:      5 * time.Second,
+			MaxPerAPITimeout:      5 * time.Second,
+			MaxMultiSearchQueries: 3,
+		},
+		GitHub:      config.GitHubConfig{BaseURL: github.URL()},
+		Performance: config.PerformanceConfig{MaxResultsPerPlatform: 10},
+		Limits:      config.LimitsConfig{DefaultSnippetLength: 500},
+	})
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+	return s, github
+}
+
+func TestMultiSearchAnswersEachSearchInOrder(t *testing.T) {
+	s, github := newTestServer(t)
+
+	resp, err := s.MultiSearch(context.Background(), &pb.MultiSearchRequest{Searches: []*pb.SearchRequest{
+		{Query: "go", Platforms: []string{"github"}, MaxResults: 2},
+		{Query: " ", Platforms: []string{"github"}},
+		{Query: "rust", Platforms: []string{"gh"}, MaxResults: 1},
+	}})
+	if err != nil {
+		t.Fatalf("MultiSearch() error = %v", err)
+	}
+	if len(resp.Results) != 3 {
+		t.Fatalf("len(results) = %d, want 3", len(resp.Results))
+	}
+
+	for i, want := range map[int]int{0: 2, 2: 1} {
+		result := resp.Results[i]
+		if result.ErrorCode != 0 || len(result.Response.GetResults()) != want {
+			t.Errorf("results[%d] = %v, want %d results", i, result, want)
+		}
+	}
+	queries := map[string]bool{}
+	for _, u := range github.Requests() {
+		queries[u.Query().Get("q")] = true
+	}
+	if !queries["go"] || !queries["rust"] || len(queries) != 2 {
+		t.Errorf("upstream queries = %v, want go and rust", queries)
+	}
+
+	failed := resp.Results[1]
+	if codes.Code(failed.ErrorCode) != codes.InvalidArgument || failed.ErrorMessage == "" || failed.Response != nil {
+		t.Errorf("results[1] = %v, want an INVALID_ARGUMENT error and no response", failed)
+	}
+}
+
+// panickingFetcher panics on every search
+type panickingFetcher struct{}
+
+func (panickingFetcher) Name() string { return "boom" }
+
+func (panickingFetcher) Fetch(context.Context, string, int) ([]*models.SearchResult, error) {
+	panic("upstream sent something unexpected")
+}
+
+func TestMultiSearchSurvivesPanickingFetcher(t *testing.T) {
+	s, _ := newTestServer(t)
+	s.searchHandler.RegisterFetcher("boom", func() (fetchers.Fetcher, error) { return panickingFetcher{}, nil })
+
+	resp, err := s.MultiSearch(context.Background(), &pb.MultiSearchRequest{Searches: []*pb.SearchRequest{
+		{Query: "go", Platforms: []string{"github"}, MaxResults: 2},
+		{Query: "go", Platforms: []string{"boom"}},
+		{Query: "go", Platforms: []string{"github", "boom"}, MaxResults: 2},
+	}})
+	if err != nil {
+		t.Fatalf("MultiSearch() error = %v", err)
+	}
+	if len(resp.Results) != 3 || len(resp.Results[0].Response.GetResults()) != 2 {
+		t.Fatalf("results = %v, want the github search answered", resp.Results)
+	}
+	for _, i := range []int{1, 2} {
+		if errored := resp.Results[i].Response.GetPlatformsError(); len(errored) != 1 || errored[0] != "boom" {
+			t.Errorf("results[%d] = %v, want boom reported as failed", i, resp.Results[i])
+		}
+	}
+	if got := len(resp.Results[2].Response.GetResults()); got != 2 {
+		t.Errorf("results[2] has %d results, want github's despite boom", got)
+	}
+}
+
+func TestMultiSearchRejectsMalformedBatches(t *testing.T) {
+	s, _ := newTestServer(t)
+	search := &pb.SearchRequest{Query: "go"}
+
+	for name, searches := range map[string][]*pb.SearchRequest{
+		"empty":    nil,
+		"too many": {search, search, search, search},
+	} {
+		_, err := s.MultiSearch(context.Background(), &pb.MultiSearchRequest{Searches: searches})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("%s: MultiSearch() error = %v, want INVALID_ARGUMENT", name, err)
+		}
+	}
+}
+
+func TestFederatedSearchSortsByNormalizedScore(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	resp, err := s.FederatedSearch(context.Background(), &pb.SearchRequest{
+		Query: "go", Platforms: []string{"github"}, MaxResults: 3, Sort: pb.SortOrder_SORT_ORDER_SCORE,
+	})
+	if err != nil {
+		t.Fatalf("FederatedSearch() error = %v", err)
+	}
+	if len(resp.Results) != 3 {
+		t.Fatalf("got %d results, want 3", len(resp.Results))
+	}
+	// The most starred repository scores 1 and the least 0
+	first, last := resp.Results[0].NormalizedScore, resp.Results[2].NormalizedScore
+	if first != 1 || last != 0 {
+		t.Errorf("normalized scores run %v to %v, want 1 to 0", first, last)
+	}
+	for i := 1; i < len(resp.Results); i++ {
+		if resp.Results[i].NormalizedScore > resp.Results[i-1].NormalizedScore {
+			t.Errorf("results not ordered by normalized score: %v", resp.Results)
+		}
+	}
+}
+
+func TestGetResultDetailsMapsErrors(t *testing.T) {
+	s, _ := newTestServer(t)
+
+	for _, tc := range []struct {
+		name string
+		req  *pb.ResultDetailsRequest
+		want codes.Code
+	}{
+		{"no platform", &pb.ResultDetailsRequest{Url: "https://github.com/golang/go"}, codes.InvalidArgument},
+		{"unknown platform", &pb.ResultDetailsRequest{Platform: "myspace", Url: "https://github.com/golang/go"}, codes.InvalidArgument},
+		{"no url", &pb.ResultDetailsRequest{Platform: "gh"}, codes.InvalidArgument},
+		{"too many replies", &pb.ResultDetailsRequest{Platform: "github", Url: "https://github.com/golang/go", MaxReplies: 51}, codes.InvalidArgument},
+		{"not a repository", &pb.ResultDetailsRequest{Platform: "github", Url: "https://github.com/golang/go/issues/1"}, codes.InvalidArgument},
+		// The fake GitHub API has no READMEs
+		{"missing readme", &pb.ResultDetailsRequest{Platform: "gh", Url: "https://github.com/golang/go"}, codes.NotFound},
+	} {
+		_, err := s.GetResultDetails(context.Background(), tc.req)
+		if status.Code(err) != tc.want {
+			t.Errorf("%s: GetResultDetails() error = %v, want %s", tc.name, err, tc.want)
+		}
+	}
+}
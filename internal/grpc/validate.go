@@ -0,0 +1,225 @@
+package grpc
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/farhapartex/search-proxy/internal/fetchers"
+	"github.com/farhapartex/search-proxy/internal/filterexpr"
+	"github.com/farhapartex/search-proxy/internal/sanitize"
+	"github.com/farhapartex/search-proxy/internal/sourcefilter"
+	pb "github.com/farhapartex/search-proxy/proto"
+	"golang.org/x/text/language"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	maxQueryRunes      = 500
+	maxPlatforms       = 32
+	maxPageTokenLength = 512 // room for a cursor per platform
+	maxLocaleLength    = 35
+	maxSnippetLength   = sanitize.MaxSnippetLength
+	maxAnswerSources   = 20
+	maxSourceRules     = 50
+	maxWeight          = 100
+
+	maxAcceptLanguageLength = 256
+
+	maxDetailsURLLength     = 2048
+	maxDetailsReplies       = 50
+	maxDetailsContentLength = 100000
+)
+
+// validateResultDetailsRequest checks a details request the way
+// validateSearchRequest checks a search, rewriting a platform alias to its
+// canonical name
+func validateResultDetailsRequest(req *pb.ResultDetailsRequest, isPlatform func(string) bool, platforms func() []string) error {
+	if strings.TrimSpace(req.Platform) == "" {
+		return status.Error(codes.InvalidArgument, "platform is required")
+	}
+	canonical := fetchers.CanonicalName(req.Platform)
+	if !isPlatform(canonical) {
+		return status.Error(codes.InvalidArgument,
+			fmt.Sprintf("invalid platform: %q (valid: %s)", req.Platform, strings.Join(platforms(), ", ")))
+	}
+	req.Platform = canonical
+
+	if strings.TrimSpace(req.Url) == "" {
+		return status.Error(codes.InvalidArgument, "url is required")
+	}
+	if len(req.Url) > maxDetailsURLLength {
+		return status.Error(codes.InvalidArgument,
+			fmt.Sprintf("url too long (max %d bytes)", maxDetailsURLLength))
+	}
+
+	if req.MaxReplies < 0 || req.MaxReplies > maxDetailsReplies {
+		return status.Error(codes.InvalidArgument,
+			fmt.Sprintf("max_replies must be between 0 and %d", maxDetailsReplies))
+	}
+	if req.MaxContentLength < 0 || req.MaxContentLength > maxDetailsContentLength {
+		return status.Error(codes.InvalidArgument,
+			fmt.Sprintf("max_content_length must be between 0 and %d", maxDetailsContentLength))
+	}
+	return nil
+}
+
+// validateSearchRequest rejects requests that could not be turned into sane
+// upstream calls. It must never panic, whatever bytes the client sends.
+// Platform aliases such as "gh" are rewritten to their canonical names.
+func validateSearchRequest(req *pb.SearchRequest, isPlatform func(string) bool, platforms func() []string) error {
+	if strings.TrimSpace(req.Query) == "" {
+		return status.Error(codes.InvalidArgument, "query cannot be empty")
+	}
+
+	if !utf8.ValidString(req.Query) {
+		return status.Error(codes.InvalidArgument, "query must be valid UTF-8")
+	}
+
+	if utf8.RuneCountInString(req.Query) > maxQueryRunes {
+		return status.Error(codes.InvalidArgument,
+			fmt.Sprintf("query too long (max %d characters)", maxQueryRunes))
+	}
+
+	if strings.IndexFunc(req.Query, isDisallowedRune) >= 0 {
+		return status.Error(codes.InvalidArgument, "query contains control characters")
+	}
+
+	if req.MaxResults < 0 {
+		return status.Error(codes.InvalidArgument, "max_results cannot be negative")
+	}
+
+	if req.MaxResults > 100 {
+		return status.Error(codes.InvalidArgument, "max_results cannot exceed 100")
+	}
+
+	if req.MaxSnippetLength < 0 || req.MaxSnippetLength > maxSnippetLength {
+		return status.Error(codes.InvalidArgument,
+			fmt.Sprintf("max_snippet_length must be between 0 and %d", maxSnippetLength))
+	}
+
+	if len(req.Platforms) > maxPlatforms {
+		return status.Error(codes.InvalidArgument,
+			fmt.Sprintf("too many platforms (max %d)", maxPlatforms))
+	}
+
+	for i, platform := range req.Platforms {
+		canonical := fetchers.CanonicalName(platform)
+		if !isPlatform(canonical) {
+			return status.Error(codes.InvalidArgument,
+				fmt.Sprintf("invalid platform: %q (valid: %s)", platform, strings.Join(platforms(), ", ")))
+		}
+		req.Platforms[i] = canonical
+	}
+
+	if len(req.InterleaveWeights) > maxPlatforms {
+		return status.Error(codes.InvalidArgument,
+			fmt.Sprintf("too many interleave weights (max %d)", maxPlatforms))
+	}
+	weights := make(map[string]int32, len(req.InterleaveWeights))
+	for platform, weight := range req.InterleaveWeights {
+		canonical := fetchers.CanonicalName(platform)
+		if !isPlatform(canonical) {
+			return status.Error(codes.InvalidArgument,
+				fmt.Sprintf("invalid interleave weight platform: %q (valid: %s)", platform, strings.Join(platforms(), ", ")))
+		}
+		if weight < 1 || weight > maxWeight {
+			return status.Error(codes.InvalidArgument,
+				fmt.Sprintf("interleave weight for %s must be between 1 and %d", platform, maxWeight))
+		}
+		weights[canonical] = weight
+	}
+	if req.InterleaveWeights != nil {
+		req.InterleaveWeights = weights
+	}
+
+	if len(req.MaxResultsPerPlatform) > maxPlatforms {
+		return status.Error(codes.InvalidArgument,
+			fmt.Sprintf("too many max_results_per_platform entries (max %d)", maxPlatforms))
+	}
+	perPlatform := make(map[string]int32, len(req.MaxResultsPerPlatform))
+	for platform, maxResults := range req.MaxResultsPerPlatform {
+		canonical := fetchers.CanonicalName(platform)
+		if !isPlatform(canonical) {
+			return status.Error(codes.InvalidArgument,
+				fmt.Sprintf("invalid max_results_per_platform platform: %q (valid: %s)", platform, strings.Join(platforms(), ", ")))
+		}
+		if maxResults < 1 || maxResults > 100 {
+			return status.Error(codes.InvalidArgument,
+				fmt.Sprintf("max_results_per_platform for %s must be between 1 and 100", platform))
+		}
+		perPlatform[canonical] = maxResults
+	}
+	if req.MaxResultsPerPlatform != nil {
+		req.MaxResultsPerPlatform = perPlatform
+	}
+
+	if _, ok := pb.SortOrder_name[int32(req.Sort)]; !ok {
+		return status.Error(codes.InvalidArgument, fmt.Sprintf("invalid sort: %d", req.Sort))
+	}
+
+	if _, ok := pb.ContentType_name[int32(req.ContentType)]; !ok {
+		return status.Error(codes.InvalidArgument, fmt.Sprintf("invalid content_type: %d", req.ContentType))
+	}
+
+	if req.Locale != "" {
+		if _, err := language.Parse(req.Locale); err != nil || len(req.Locale) > maxLocaleLength {
+			return status.Error(codes.InvalidArgument, fmt.Sprintf("invalid locale: %q", req.Locale))
+		}
+	}
+
+	if req.TimeoutMs < 0 || req.PerPlatformTimeoutMs < 0 {
+		return status.Error(codes.InvalidArgument, "timeout_ms and per_platform_timeout_ms cannot be negative")
+	}
+
+	if req.MinStars < 0 || req.MinUpvotes < 0 {
+		return status.Error(codes.InvalidArgument, "min_stars and min_upvotes cannot be negative")
+	}
+
+	if req.Since < 0 || req.Until < 0 {
+		return status.Error(codes.InvalidArgument, "since and until cannot be negative")
+	}
+	if req.Since > 0 && req.Until > 0 && req.Until <= req.Since {
+		return status.Error(codes.InvalidArgument, "until must be after since")
+	}
+
+	if len(req.AllowSources)+len(req.DenySources) > maxSourceRules {
+		return status.Error(codes.InvalidArgument,
+			fmt.Sprintf("too many source rules (max %d)", maxSourceRules))
+	}
+	for _, rules := range [][]string{req.AllowSources, req.DenySources} {
+		if _, err := sourcefilter.Parse(rules); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	if len(req.AcceptLanguage) > maxAcceptLanguageLength {
+		return status.Error(codes.InvalidArgument, "accept_language is too long")
+	}
+	if _, _, err := language.ParseAcceptLanguage(req.AcceptLanguage); err != nil {
+		return status.Error(codes.InvalidArgument, fmt.Sprintf("invalid accept_language: %q", req.AcceptLanguage))
+	}
+
+	if _, err := filterexpr.Compile(req.Filter); err != nil {
+		return status.Error(codes.InvalidArgument, fmt.Sprintf("invalid filter: %v", err))
+	}
+
+	if len(req.PageToken) > maxPageTokenLength {
+		return status.Error(codes.InvalidArgument, "page_token is malformed")
+	}
+
+	return nil
+}
+
+// isDisallowedRune reports control and format characters other than ordinary
+// whitespace; they have no meaning in a search and upstreams handle them badly
+func isDisallowedRune(r rune) bool {
+	switch r {
+	// Zero-width joiners hold emoji sequences together
+	case '\t', '\n', '\r', '\u200d':
+		return false
+	}
+	return unicode.IsControl(r) || unicode.Is(unicode.Cf, r)
+}
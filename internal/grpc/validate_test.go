@@ -0,0 +1,142 @@
+package grpc
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+var testPlatforms = map[string]bool{"github": true, "stackoverflow": true, "reddit": true}
+
+func validateForTest(req *pb.SearchRequest) error {
+	return validateSearchRequest(req,
+		func(name string) bool { return testPlatforms[name] },
+		func() []string { return []string{"github", "reddit", "stackoverflow"} },
+	)
+}
+
+func FuzzValidateSearchRequest(f *testing.F) {
+	f.Add("react hooks", int32(10), "github", "")
+	f.Add("", int32(0), "", "")
+	f.Add("   \t ", int32(5), "reddit", "")
+	f.Add("go\x00lang", int32(5), "", "")
+	f.Add("\xff\xfe", int32(5), "", "")
+	f.Add("emoji \U0001F469\u200d\U0001F4BB test", int32(100), "stackoverflow", "")
+	f.Add("bidi \u202e override", int32(1), "", "")
+	f.Add(strings.Repeat("ü", 501), int32(1), "", "")
+	f.Add("q", int32(-1), "github", strings.Repeat("a", 200))
+
+	f.Fuzz(func(t *testing.T, query string, maxResults int32, platform, pageToken string) {
+		req := &pb.SearchRequest{Query: query, MaxResults: maxResults, PageToken: pageToken}
+		if platform != "" {
+			req.Platforms = []string{platform}
+		}
+
+		if err := validateForTest(req); err != nil {
+			return
+		}
+
+		// Anything accepted must be safe to hand to the fetchers
+		if !utf8.ValidString(query) {
+			t.Fatalf("accepted invalid UTF-8 query %q", query)
+		}
+		if strings.TrimSpace(query) == "" {
+			t.Fatalf("accepted blank query %q", query)
+		}
+		if n := utf8.RuneCountInString(query); n > maxQueryRunes {
+			t.Fatalf("accepted query of %d characters", n)
+		}
+		if i := strings.IndexFunc(query, isDisallowedRune); i >= 0 {
+			t.Fatalf("accepted query %q with control character at %d", query, i)
+		}
+		if maxResults < 0 || maxResults > 100 {
+			t.Fatalf("accepted max_results %d", maxResults)
+		}
+		if platform != "" && !testPlatforms[req.Platforms[0]] {
+			t.Fatalf("accepted platform %q as %q", platform, req.Platforms[0])
+		}
+	})
+}
+
+func TestValidateSearchRequestNormalizesAliases(t *testing.T) {
+	req := &pb.SearchRequest{Query: "go", Platforms: []string{"gh", "SO", " Reddit "}}
+	if err := validateForTest(req); err != nil {
+		t.Fatalf("validateSearchRequest() error = %v", err)
+	}
+
+	want := []string{"github", "stackoverflow", "reddit"}
+	for i := range want {
+		if req.Platforms[i] != want[i] {
+			t.Errorf("Platforms = %q, want %q", req.Platforms, want)
+			break
+		}
+	}
+
+	// Aliases of platforms that aren't registered are still rejected
+	err := validateForTest(&pb.SearchRequest{Query: "go", Platforms: []string{"hn"}})
+	if err == nil || !strings.Contains(err.Error(), `"hn"`) {
+		t.Errorf("validateSearchRequest(hn) error = %v, want it rejected by the name given", err)
+	}
+}
+
+func TestValidateSearchRequestChecksInterleaveWeights(t *testing.T) {
+	req := &pb.SearchRequest{Query: "go", InterleaveWeights: map[string]int32{"gh": 3, "reddit": 1}}
+	if err := validateForTest(req); err != nil {
+		t.Fatalf("validateSearchRequest() error = %v", err)
+	}
+	if req.InterleaveWeights["github"] != 3 || len(req.InterleaveWeights) != 2 {
+		t.Errorf("InterleaveWeights = %v, want aliases resolved", req.InterleaveWeights)
+	}
+
+	for _, weights := range []map[string]int32{
+		{"github": 0},
+		{"github": maxWeight + 1},
+		{"hn": 2},
+	} {
+		if err := validateForTest(&pb.SearchRequest{Query: "go", InterleaveWeights: weights}); err == nil {
+			t.Errorf("validateSearchRequest(%v) accepted", weights)
+		}
+	}
+}
+
+func TestValidateSearchRequestChecksMaxResultsPerPlatform(t *testing.T) {
+	req := &pb.SearchRequest{Query: "go", MaxResultsPerPlatform: map[string]int32{"gh": 50, "reddit": 10}}
+	if err := validateForTest(req); err != nil {
+		t.Fatalf("validateSearchRequest() error = %v", err)
+	}
+	if req.MaxResultsPerPlatform["github"] != 50 || len(req.MaxResultsPerPlatform) != 2 {
+		t.Errorf("MaxResultsPerPlatform = %v, want aliases resolved", req.MaxResultsPerPlatform)
+	}
+
+	for _, perPlatform := range []map[string]int32{
+		{"github": 0},
+		{"github": 101},
+		{"hn": 2},
+	} {
+		if err := validateForTest(&pb.SearchRequest{Query: "go", MaxResultsPerPlatform: perPlatform}); err == nil {
+			t.Errorf("validateSearchRequest(%v) accepted", perPlatform)
+		}
+	}
+}
+
+func TestValidateSearchRequestChecksTimeRange(t *testing.T) {
+	for _, tc := range []struct {
+		since, until int64
+		ok           bool
+	}{
+		{0, 0, true},
+		{1700000000, 0, true},
+		{0, 1700000000, true},
+		{1700000000, 1700000001, true},
+		{1700000000, 1700000000, false},
+		{1700000001, 1700000000, false},
+		{-1, 0, false},
+	} {
+		err := validateForTest(&pb.SearchRequest{Query: "go", Since: tc.since, Until: tc.until})
+		if (err == nil) != tc.ok {
+			t.Errorf("validateSearchRequest(since=%d, until=%d) error = %v, want ok=%v", tc.since, tc.until, err, tc.ok)
+		}
+	}
+}
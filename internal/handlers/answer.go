@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/farhapartex/search-proxy/internal/logging"
+	"github.com/farhapartex/search-proxy/internal/summarize"
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+// ErrAnswersDisabled is returned by Answer when no LLM is configured
+var ErrAnswersDisabled = errors.New("answer synthesis is disabled")
+
+// AnswersEnabled reports whether Answer can synthesize answers
+func (h *SearchHandler) AnswersEnabled() bool {
+	return h.answerer != nil
+}
+
+// Answer has the LLM answer query from the top results of response, within
+// the answer timeout. If it fails the response carries the results without
+// an answer.
+func (h *SearchHandler) Answer(ctx context.Context, query string, maxSources int, response *pb.SearchResponse) (*pb.AnswerResponse, error) {
+	if h.answerer == nil {
+		return nil, ErrAnswersDisabled
+	}
+
+	answerResp := &pb.AnswerResponse{Search: response}
+
+	if maxSources <= 0 {
+		maxSources = h.config.Answer.DefaultSources
+	}
+	top := response.Results[:min(maxSources, len(response.Results))]
+	if len(top) == 0 {
+		return answerResp, nil
+	}
+
+	sources := make([]summarize.Source, len(top))
+	for i, result := range top {
+		text := result.Snippet
+		if result.Summary != "" {
+			text = result.Summary
+		}
+		sources[i] = summarize.Source{Title: result.Title, URL: result.Url, Text: text}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, h.config.Answer.Timeout)
+	defer cancel()
+
+	answer, err := h.answerer.Answer(ctx, query, sources)
+	if err != nil {
+		logging.Printf(ctx, "Answer synthesis failed: %v", err)
+		return answerResp, nil
+	}
+
+	answerResp.Answer = answer
+	for _, n := range summarize.Citations(answer, len(top)) {
+		result := top[n-1]
+		answerResp.Citations = append(answerResp.Citations, &pb.Citation{
+			Index:    int32(n),
+			Url:      result.Url,
+			Title:    result.Title,
+			Platform: result.Platform,
+		})
+	}
+	return answerResp, nil
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+// continuationStore holds the results that did not fit into a size-limited
+// response until the client asks for them with the continuation token.
+// Tokens are local to this process and expire after ttl.
+type continuationStore struct {
+	mu         sync.Mutex
+	pages      map[string]*continuation
+	ttl        time.Duration
+	maxEntries int
+	newToken   func() string
+}
+
+type continuation struct {
+	query     string
+	response  *pb.SearchResponse
+	expiresAt time.Time
+}
+
+func newContinuationStore(ttl time.Duration, maxEntries int) *continuationStore {
+	return &continuationStore{
+		pages:      make(map[string]*continuation),
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		newToken:   newToken,
+	}
+}
+
+// put stores the remaining results of response and returns their token
+func (s *continuationStore) put(query string, response *pb.SearchResponse) string {
+	token := s.newToken()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for key, page := range s.pages {
+		if now.After(page.expiresAt) {
+			delete(s.pages, key)
+		}
+	}
+	if s.maxEntries > 0 && len(s.pages) >= s.maxEntries {
+		// Still full after expiring entries: drop an arbitrary one rather
+		// than letting abandoned continuations grow without bound
+		for key := range s.pages {
+			delete(s.pages, key)
+			break
+		}
+	}
+
+	s.pages[token] = &continuation{
+		query:     query,
+		response:  response,
+		expiresAt: now.Add(s.ttl),
+	}
+	return token
+}
+
+// take removes and returns the continuation for token if it is still valid
+// and belongs to query
+func (s *continuationStore) take(token, query string) (*pb.SearchResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	page, ok := s.pages[token]
+	if !ok || page.query != query {
+		return nil, false
+	}
+	delete(s.pages, token)
+
+	if time.Now().After(page.expiresAt) {
+		return nil, false
+	}
+	return page.response, true
+}
+
+// splitToSize moves trailing results out of response until its serialized
+// size fits into maxBytes, returning the removed results in order. At least
+// one result is always kept so every page makes progress.
+func splitToSize(response *pb.SearchResponse, maxBytes int) []*pb.Result {
+	if maxBytes <= 0 || proto.Size(response) <= maxBytes {
+		return nil
+	}
+
+	// Leave room for the continuation token that will be attached
+	budget := maxBytes - 64
+	size := proto.Size(response)
+	cut := len(response.Results)
+	for cut > 1 && size > budget {
+		cut--
+		// Field tag plus length prefix around each embedded result
+		size -= proto.Size(response.Results[cut]) + 1 + varintSize(proto.Size(response.Results[cut]))
+	}
+
+	overflow := response.Results[cut:]
+	response.Results = response.Results[:cut]
+	response.TotalCount = int32(len(response.Results))
+	return overflow
+}
+
+func varintSize(n int) int {
+	size := 1
+	for n >= 0x80 {
+		n >>= 7
+		size++
+	}
+	return size
+}
+
+func newToken() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
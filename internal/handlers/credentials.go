@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/farhapartex/search-proxy/internal/fetchers"
+)
+
+// ErrCredentialsNotRotatable is returned for platforms whose credentials
+// can't be changed at runtime
+var ErrCredentialsNotRotatable = errors.New("platform has no rotatable credentials")
+
+// credentialSet holds a platform's rotatable credentials: a
+// fetchers.CredentialPool of tokens or keys, or the Reddit fetcher's client
+// ID and secret
+type credentialSet interface {
+	Replace(values string)
+	Len() int
+}
+
+// CredentialError reports a credential the upstream refused; Index is its
+// position in the update, never the secret itself. An upstream that fails
+// or can't be reached isn't a refusal and is reported as it is.
+type CredentialError struct {
+	Platform string
+	Index    int
+	Err      error
+}
+
+func (e *CredentialError) Error() string {
+	return fmt.Sprintf("%s credential %d rejected: %v", e.Platform, e.Index, e.Err)
+}
+
+func (e *CredentialError) Unwrap() error {
+	return e.Err
+}
+
+// UpdateCredentials replaces a platform's credentials. Unless skipValidation
+// is set every new credential is first checked with the upstream, and
+// nothing changes if any is refused.
+func (h *SearchHandler) UpdateCredentials(ctx context.Context, platform string, credentials []string, skipValidation bool) error {
+	set, ok := h.credentials[platform]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrCredentialsNotRotatable, platform)
+	}
+
+	if !skipValidation {
+		fetcher, err := h.fetchers.Get(ctx, platform)
+		if err != nil {
+			return err
+		}
+		for i, credential := range credentials {
+			err := fetchers.ValidateCredential(ctx, fetcher, credential)
+			var statusErr *fetchers.StatusError
+			switch {
+			case errors.As(err, &statusErr) && statusErr.CredentialRejected:
+				return &CredentialError{Platform: platform, Index: i, Err: err}
+			case err != nil:
+				return fmt.Errorf("%s credential %d: %w", platform, i, err)
+			}
+		}
+	}
+
+	set.Replace(strings.Join(credentials, ","))
+	log.Printf("Rotated %s credentials (%d configured)", platform, len(credentials))
+	return nil
+}
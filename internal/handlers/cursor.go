@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// cursorTokenPrefix tells cursor tokens apart from the continuation tokens
+// of size-limited responses, and versions their encoding
+const cursorTokenPrefix = "c1."
+
+// pageCursors maps each platform that has more results to the cursor of its
+// next page. It travels to the client as an opaque page token, so paging
+// needs no server-side state and works across instances.
+type pageCursors map[string]string
+
+// token encodes the cursors, or returns "" when no platform has more
+func (c pageCursors) token() string {
+	if len(c) == 0 {
+		return ""
+	}
+	// Map keys marshal sorted, so equal cursors give equal tokens
+	data, _ := json.Marshal(c)
+	return cursorTokenPrefix + base64.RawURLEncoding.EncodeToString(data)
+}
+
+// platforms returns the platforms the cursors continue, sorted
+func (c pageCursors) platforms() []string {
+	platforms := make([]string, 0, len(c))
+	for platform := range c {
+		platforms = append(platforms, platform)
+	}
+	sort.Strings(platforms)
+	return platforms
+}
+
+// isCursorToken reports whether token came from pageCursors.token
+func isCursorToken(token string) bool {
+	return strings.HasPrefix(token, cursorTokenPrefix)
+}
+
+// parseCursorToken decodes a token made by pageCursors.token
+func parseCursorToken(token string) (pageCursors, error) {
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(token, cursorTokenPrefix))
+	if err != nil {
+		return nil, ErrInvalidPageToken
+	}
+	var cursors pageCursors
+	if err := json.Unmarshal(data, &cursors); err != nil || len(cursors) == 0 {
+		return nil, ErrInvalidPageToken
+	}
+	return cursors, nil
+}
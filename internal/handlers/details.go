@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/farhapartex/search-proxy/internal/fetchers"
+	"github.com/farhapartex/search-proxy/internal/sanitize"
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+// Defaults for result details requests that leave the limits unset
+const (
+	defaultDetailsReplies       = 5
+	defaultDetailsContentLength = 20000
+)
+
+// ResultDetails fetches the full content behind a result of a registered
+// and enabled platform. It goes through the platform's rate limiter, quota
+// throttle and circuit breaker, and takes a worker slot, like a search's
+// fetch.
+func (h *SearchHandler) ResultDetails(ctx context.Context, req *pb.ResultDetailsRequest) (*pb.ResultDetailsResponse, error) {
+	platform := req.Platform
+	if !h.fetchers.Has(platform) {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownPlatform, platform)
+	}
+	if !h.fetchers.Enabled(platform) {
+		return nil, disabledError(platform)
+	}
+	fetcher, err := h.fetchers.Get(ctx, platform)
+	if err != nil {
+		return nil, err
+	}
+
+	slot, err := h.workers.Reserve(platform)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", platform, err)
+	}
+	defer slot.Release()
+	if err := slot.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	maxReplies := int(req.MaxReplies)
+	if maxReplies == 0 {
+		maxReplies = defaultDetailsReplies
+	}
+	details, err := fetchers.FetchDetails(ctx, fetcher, req.Url, maxReplies)
+	if err != nil {
+		return nil, err
+	}
+
+	maxLength := int(req.MaxContentLength)
+	if maxLength == 0 {
+		maxLength = defaultDetailsContentLength
+	}
+	details.Content = truncateDetails(details, details.Content, maxLength)
+	for _, reply := range details.Replies {
+		reply.Content = truncateDetails(details, reply.Content, maxLength)
+	}
+	return details, nil
+}
+
+// truncateDetails cuts content to maxLength characters, marking details
+// truncated when it does. Upstream content isn't trusted to be valid
+// UTF-8, which protobuf strings must be.
+func truncateDetails(details *pb.ResultDetailsResponse, content string, maxLength int) string {
+	content = strings.ToValidUTF8(content, "\uFFFD")
+	short := sanitize.TruncateString(content, maxLength)
+	if short != content {
+		details.Truncated = true
+	}
+	return short
+}
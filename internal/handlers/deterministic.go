@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// fixedTime is what the clock reads in deterministic mode
+var fixedTime = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// makeDeterministic pins the clock, seeds continuation tokens and releases
+// platform results in a fixed order instead of arrival order, so the same
+// upstream data always produces the same response. Meant for golden-file
+// tests of the merge pipeline, not for production traffic.
+func (h *SearchHandler) makeDeterministic(seed int64) {
+	h.deterministic = true
+	h.now = func() time.Time { return fixedTime }
+	h.pages.newToken = seededTokens(seed)
+}
+
+// seededTokens returns a token generator that yields the same sequence for
+// the same seed
+func seededTokens(seed int64) func() string {
+	var mu sync.Mutex
+	rng := rand.New(rand.NewPCG(uint64(seed), 0))
+
+	return func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		return fmt.Sprintf("%016x%016x", rng.Uint64(), rng.Uint64())
+	}
+}
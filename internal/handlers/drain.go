@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"context"
+	"log"
+)
+
+// background runs fn on its own goroutine, tracked so shutdown can wait for
+// it. Use it for work that outlives the request, such as indexing results;
+// ctx only labels a panic's log line with the request, fn isn't canceled
+// with it.
+func (h *SearchHandler) background(ctx context.Context, fn func()) {
+	h.jobs.Add(1)
+	go func() {
+		defer h.jobs.Done()
+		defer logPanic(ctx, "background job")
+		fn()
+	}()
+}
+
+// Drain waits for background work started by earlier searches to finish,
+// giving up when ctx ends
+func (h *SearchHandler) Drain(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		h.jobs.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close drops the upstream client's idle connections, stops refreshing
+// cached DNS lookups, and flushes and closes the event publisher, search
+// store and result index. Call it once no searches or background jobs are
+// left running, so nothing writes to them after they close.
+func (h *SearchHandler) Close() {
+	if h.client != nil {
+		h.client.CloseIdleConnections()
+	}
+	if h.resolver != nil {
+		h.resolver.Close()
+	}
+	if h.publisher != nil {
+		if err := h.publisher.Close(); err != nil {
+			log.Printf("WARNING: Failed to close event publisher: %v", err)
+		}
+	}
+	if h.store != nil {
+		if err := h.store.Close(); err != nil {
+			log.Printf("WARNING: Failed to close search store: %v", err)
+		}
+	}
+	if h.index != nil {
+		if err := h.index.Close(); err != nil {
+			log.Printf("WARNING: Failed to close result index: %v", err)
+		}
+	}
+}
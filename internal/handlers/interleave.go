@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+// interleaveWeights blends platforms by the request's merge weights: each
+// round takes as many results from a platform as its weight, so 3 for GitHub
+// and 1 for Reddit gives three repositories for every post. Platforms the
+// request doesn't weigh count 1.
+type interleaveWeights map[string]int
+
+// newInterleaveWeights returns the request's weights, or nil if it sets none
+func newInterleaveWeights(req *pb.SearchRequest) interleaveWeights {
+	if len(req.InterleaveWeights) == 0 {
+		return nil
+	}
+	weights := make(interleaveWeights, len(req.InterleaveWeights))
+	for platform, weight := range req.InterleaveWeights {
+		weights[platform] = int(weight)
+	}
+	return weights
+}
+
+// key encodes the weights for cache scopes
+func (w interleaveWeights) key() string {
+	platforms := make([]string, 0, len(w))
+	for platform := range w {
+		platforms = append(platforms, platform)
+	}
+	slices.Sort(platforms)
+
+	var b strings.Builder
+	for _, platform := range platforms {
+		b.WriteString(platform + ":" + strconv.Itoa(w[platform]) + ",")
+	}
+	return b.String()
+}
+
+// apply reorders results round by round, visiting platforms in the order
+// their first result appears and keeping each platform's own order. Without
+// weights results are left as merged.
+func (w interleaveWeights) apply(results []*pb.Result) []*pb.Result {
+	if w == nil {
+		return results
+	}
+
+	var order []string
+	byPlatform := make(map[string][]*pb.Result)
+	for _, result := range results {
+		if _, seen := byPlatform[result.Platform]; !seen {
+			order = append(order, result.Platform)
+		}
+		byPlatform[result.Platform] = append(byPlatform[result.Platform], result)
+	}
+
+	blended := make([]*pb.Result, 0, len(results))
+	for len(blended) < len(results) {
+		for _, platform := range order {
+			weight, ok := w[platform]
+			if !ok {
+				weight = 1
+			}
+			take := min(weight, len(byPlatform[platform]))
+			blended = append(blended, byPlatform[platform][:take]...)
+			byPlatform[platform] = byPlatform[platform][take:]
+		}
+	}
+	return blended
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/abadojack/whatlanggo"
+	"golang.org/x/text/language"
+
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+// contentLanguageKey is the metadata entry holding a result's detected
+// language as an ISO 639-1 code
+const contentLanguageKey = "content_language"
+
+// minLanguageConfidence is how sure detection must be before a result counts
+// as being in a language; titles and snippets are short, so this is looser
+// than whatlanggo's own reliability threshold
+const minLanguageConfidence = 0.5
+
+// languagePreference ranks results by the languages a request accepts
+type languagePreference struct {
+	// rank maps base languages to their position in the accept list
+	rank map[string]int
+	only bool
+	raw  string
+}
+
+// newLanguagePreference parses the request's accept_language, returning nil
+// when it is empty
+func newLanguagePreference(req *pb.SearchRequest) (*languagePreference, error) {
+	if strings.TrimSpace(req.AcceptLanguage) == "" {
+		return nil, nil
+	}
+	tags, _, err := language.ParseAcceptLanguage(req.AcceptLanguage)
+	if err != nil {
+		return nil, fmt.Errorf("invalid accept_language %q: %w", req.AcceptLanguage, err)
+	}
+
+	pref := &languagePreference{rank: make(map[string]int), only: req.AcceptLanguageOnly}
+	for _, tag := range tags {
+		base, _ := tag.Base()
+		if _, seen := pref.rank[base.String()]; !seen && tag != language.Und {
+			pref.rank[base.String()] = len(pref.rank)
+		}
+	}
+	codes := make([]string, len(pref.rank))
+	for code, i := range pref.rank {
+		codes[i] = code
+	}
+	pref.raw = strings.Join(codes, ",")
+	return pref, nil
+}
+
+// key encodes the preference for cache scopes
+func (l *languagePreference) key() string {
+	if l == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s,%t", l.raw, l.only)
+}
+
+// languageStage records each result's detected language and, when the
+// request accepts only its languages, drops results detected as another.
+// Results whose language can't be told are always kept.
+func languageStage(pref *languagePreference) resultStage {
+	return func(platform string, results []*pb.Result) []*pb.Result {
+		if pref == nil {
+			return results
+		}
+		kept := make([]*pb.Result, 0, len(results))
+		for _, result := range results {
+			code := detectLanguage(result)
+			if code != "" {
+				if result.Metadata == nil {
+					result.Metadata = make(map[string]string)
+				}
+				result.Metadata[contentLanguageKey] = code
+			}
+			if _, accepted := pref.rank[code]; pref.only && code != "" && !accepted {
+				continue
+			}
+			kept = append(kept, result)
+		}
+		return kept
+	}
+}
+
+// sort moves results in accepted languages ahead of the rest, most preferred
+// first, keeping the order within each language
+func (l *languagePreference) sort(results []*pb.Result) {
+	if l == nil {
+		return
+	}
+	slices.SortStableFunc(results, func(a, b *pb.Result) int {
+		return l.position(a) - l.position(b)
+	})
+}
+
+// position is the result's rank in the accept list; undetected languages
+// come right after the accepted ones, other languages last
+func (l *languagePreference) position(result *pb.Result) int {
+	code := result.Metadata[contentLanguageKey]
+	if i, ok := l.rank[code]; ok {
+		return i
+	}
+	if code == "" {
+		return len(l.rank)
+	}
+	return len(l.rank) + 1
+}
+
+// detectLanguage returns the ISO 639-1 code of the result's text, or "" when
+// detection isn't confident
+func detectLanguage(result *pb.Result) string {
+	info := whatlanggo.Detect(result.Title + "\n" + result.Snippet)
+	if info.Confidence < minLanguageConfidence {
+		return ""
+	}
+	return info.Lang.Iso6391()
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"maps"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/farhapartex/search-proxy/internal/config"
+	"github.com/farhapartex/search-proxy/internal/models"
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+// resultBudget bounds the memory a single search can allocate for results and
+// keeps count of everything it had to cut so callers can see it happened
+type resultBudget struct {
+	limits config.LimitsConfig
+
+	resultsDropped    int32
+	snippetsTruncated int32
+	metadataTruncated int32
+}
+
+func newResultBudget(limits config.LimitsConfig) *resultBudget {
+	return &resultBudget{limits: limits}
+}
+
+// resultLimits is how many results each platform is asked for: the
+// request's max_results_per_platform entry for it, or else the request-wide
+// maximum
+type resultLimits struct {
+	fallback    int
+	perPlatform map[string]int
+}
+
+func newResultLimits(req *pb.SearchRequest, fallback int) resultLimits {
+	limits := resultLimits{fallback: fallback}
+	if len(req.MaxResultsPerPlatform) > 0 {
+		limits.perPlatform = make(map[string]int, len(req.MaxResultsPerPlatform))
+		for platform, maxResults := range req.MaxResultsPerPlatform {
+			limits.perPlatform[platform] = int(maxResults)
+		}
+	}
+	return limits
+}
+
+// For returns the maximum number of results from platform
+func (l resultLimits) For(platform string) int {
+	if maxResults, ok := l.perPlatform[platform]; ok {
+		return maxResults
+	}
+	return l.fallback
+}
+
+// key encodes the per-platform overrides for cache scopes; the request-wide
+// maximum is part of the scope already
+func (l resultLimits) key() string {
+	platforms := slices.Sorted(maps.Keys(l.perPlatform))
+	var b strings.Builder
+	for _, platform := range platforms {
+		b.WriteString(platform + ":" + strconv.Itoa(l.perPlatform[platform]) + ",")
+	}
+	return b.String()
+}
+
+// platformResults returns a platform's results as protobuf, trimmed to the
+// number that was requested to guard against upstreams that ignore the page
+// size parameter. Only the results that are kept are converted.
+func (b *resultBudget) platformResults(fetchResult *models.FetchResult, maxResults int) []*pb.Result {
+	if fetchResult.ProtoResults != nil {
+		return capResults(b, fetchResult.ProtoResults, maxResults)
+	}
+
+	results := capResults(b, fetchResult.Results, maxResults)
+	protos := make([]*pb.Result, len(results))
+	for i, result := range results {
+		protos[i] = result.ToProto()
+	}
+	return protos
+}
+
+func capResults[T any](b *resultBudget, results []T, maxResults int) []T {
+	if len(results) <= maxResults {
+		return results
+	}
+	b.resultsDropped += int32(len(results) - maxResults)
+	return results[:maxResults]
+}
+
+// admit applies the per-result caps to result and reports whether it still
+// fits into the response. collected is the number of results already kept.
+func (b *resultBudget) admit(result *pb.Result, collected int) bool {
+	if b.limits.MaxTotalResults > 0 && collected >= b.limits.MaxTotalResults {
+		b.resultsDropped++
+		return false
+	}
+
+	if b.limits.MaxSnippetBytes > 0 && len(result.Snippet) > b.limits.MaxSnippetBytes {
+		result.Snippet = truncateBytes(result.Snippet, b.limits.MaxSnippetBytes)
+		b.snippetsTruncated++
+	}
+
+	if b.limits.MaxMetadataBytes > 0 {
+		if trimmed, dropped := trimMetadata(result.Metadata, b.limits.MaxMetadataBytes); dropped {
+			result.Metadata = trimmed
+			b.metadataTruncated++
+		}
+	}
+
+	return true
+}
+
+// record copies the truncation counters into the response metadata
+func (b *resultBudget) record(metadata *pb.ResponseMetadata) {
+	metadata.ResultsDropped = b.resultsDropped
+	metadata.SnippetsTruncated = b.snippetsTruncated
+	metadata.MetadataTruncated = b.metadataTruncated
+}
+
+// truncateBytes cuts s to at most maxBytes without splitting a UTF-8 sequence
+func truncateBytes(s string, maxBytes int) string {
+	if len(s) <= maxBytes {
+		return s
+	}
+
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut]
+}
+
+// trimMetadata returns metadata reduced to entries whose combined key and
+// value size fits into maxBytes, keeping keys in sorted order so the outcome
+// is deterministic. The input map may be shared with the fetcher's result, so
+// it is never modified; the boolean reports whether anything was dropped.
+func trimMetadata(metadata map[string]string, maxBytes int) (map[string]string, bool) {
+	size := 0
+	for key, value := range metadata {
+		size += len(key) + len(value)
+	}
+	if size <= maxBytes {
+		return metadata, false
+	}
+
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	trimmed := make(map[string]string, len(metadata))
+	used := 0
+	for _, key := range keys {
+		entry := len(key) + len(metadata[key])
+		if used+entry > maxBytes {
+			continue
+		}
+		trimmed[key] = metadata[key]
+		used += entry
+	}
+
+	return trimmed, true
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/farhapartex/search-proxy/internal/fetchers"
+	"github.com/farhapartex/search-proxy/internal/store"
+)
+
+// ErrUnknownPlatform is returned for a platform that isn't registered
+var ErrUnknownPlatform = errors.New("unknown platform")
+
+// ErrCircuitBreakersDisabled is returned when circuit breakers are turned off
+var ErrCircuitBreakersDisabled = errors.New("circuit breakers are disabled")
+
+// ErrStoreDisabled is returned when the search store is turned off
+var ErrStoreDisabled = errors.New("search store is disabled")
+
+// SetPlatformEnabled takes a platform out of searches, or puts it back.
+// Searches naming a disabled platform report it as skipped.
+func (h *SearchHandler) SetPlatformEnabled(platform string, enabled bool) error {
+	if !h.fetchers.SetEnabled(platform, enabled) {
+		return fmt.Errorf("%w: %s", ErrUnknownPlatform, platform)
+	}
+	if enabled {
+		log.Printf("Platform %s enabled", platform)
+	} else {
+		log.Printf("WARNING: Platform %s disabled", platform)
+	}
+	return nil
+}
+
+// ResetCircuitBreakers closes the circuit breakers of platforms, or of every
+// platform when none are given, and returns the platforms reset in name
+// order
+func (h *SearchHandler) ResetCircuitBreakers(platforms []string) ([]string, error) {
+	if h.breakers == nil {
+		return nil, ErrCircuitBreakersDisabled
+	}
+	if len(platforms) == 0 {
+		platforms = h.fetchers.Names()
+	}
+	for _, platform := range platforms {
+		if !h.fetchers.Has(platform) {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownPlatform, platform)
+		}
+	}
+
+	reset := slices.Compact(slices.Sorted(slices.Values(platforms)))
+	for _, platform := range reset {
+		h.breakers.For(platform).Reset()
+	}
+	return reset, nil
+}
+
+// FlushCache drops every cached response and returns how many the response
+// cache and the semantic cache held. Caches that are disabled count 0.
+func (h *SearchHandler) FlushCache(ctx context.Context) (responses, semantic int, err error) {
+	if h.semCache != nil {
+		semantic = h.semCache.Flush()
+	}
+	if h.respCache != nil {
+		if responses, err = h.respCache.Flush(ctx); err != nil {
+			return responses, semantic, err
+		}
+	}
+	log.Printf("Cache flushed: %d responses, %d semantic entries", responses, semantic)
+	return responses, semantic, nil
+}
+
+// disabledError reports a platform skipped because it is disabled
+func disabledError(platform string) error {
+	return fmt.Errorf("%s: %w", platform, fetchers.ErrPlatformDisabled)
+}
+
+// RecentSearches returns up to limit of the searches recorded in the store,
+// newest first
+func (h *SearchHandler) RecentSearches(ctx context.Context, limit int) ([]*store.SearchRecord, error) {
+	if h.store == nil {
+		return nil, ErrStoreDisabled
+	}
+	return h.store.RecentSearches(ctx, limit)
+}
+
+// TrendingQueries returns up to limit of the queries searched most often in
+// the last window
+func (h *SearchHandler) TrendingQueries(ctx context.Context, window time.Duration, limit int) ([]store.TrendingQuery, error) {
+	if h.store == nil {
+		return nil, ErrStoreDisabled
+	}
+	return h.store.TrendingQueries(ctx, h.now().Add(-window), limit)
+}
@@ -0,0 +1,299 @@
+package handlers
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/farhapartex/search-proxy/internal/models"
+	"github.com/farhapartex/search-proxy/internal/ranking"
+	"github.com/farhapartex/search-proxy/internal/sanitize"
+	"github.com/farhapartex/search-proxy/internal/urlcanon"
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+// resultStage processes one platform's results as they arrive. A stage may
+// drop, rewrite, or reorder results; it only ever sees one batch at a time.
+type resultStage func(platform string, results []*pb.Result) []*pb.Result
+
+// resultFilter decides whether a result belongs in the response
+type resultFilter interface {
+	Keep(result *pb.Result) bool
+}
+
+// emitFunc receives each batch of results the moment it has been merged,
+// with how long the platform took to fetch them (zero for results that came
+// from the local index)
+type emitFunc func(platform string, results []*pb.Result, duration time.Duration)
+
+// mergePipeline merges platform results incrementally instead of after the
+// whole fan-in, so every batch is deduplicated and truncated while the
+// slower platforms are still in flight
+type mergePipeline struct {
+	budget *resultBudget
+	limits resultLimits
+	stages []resultStage
+	emit   emitFunc
+
+	// seen holds the admitted result for each page, for deduplication
+	seen deduper
+
+	// snippetFormats holds the snippet format of each platform seen so far
+	snippetFormats map[string]string
+	// durations holds the fetch duration of each platform pushed so far
+	durations map[string]time.Duration
+
+	results []*pb.Result
+	// lists holds each platform's filtered results in the platform's own
+	// order, duplicates of other platforms' results included, for ranking
+	lists []ranking.List
+
+	// order, when set, releases batches in this platform order rather than
+	// arrival order, holding early arrivals until their turn
+	order []string
+	next  int
+	held  map[string][]*pb.Result
+}
+
+// newMergePipeline creates a pipeline sanitizing results, cutting snippets
+// to snippetLength characters and dropping results that any of filters
+// rejects. Languages are detected only when languages is set, before
+// filtering so filters can read them.
+func newMergePipeline(budget *resultBudget, limits resultLimits, snippetLength int, languages *languagePreference, emit emitFunc, filters ...resultFilter) *mergePipeline {
+	p := &mergePipeline{
+		budget:         budget,
+		limits:         limits,
+		emit:           emit,
+		snippetFormats: make(map[string]string),
+		durations:      make(map[string]time.Duration),
+		seen:           make(deduper),
+	}
+	p.stages = []resultStage{
+		canonicalizeStage,
+		languageStage(languages),
+		filterStage(filters),
+		p.recordStage,
+		sanitizeStage(snippetLength, p.snippetFormats),
+	}
+	return p
+}
+
+// inOrder makes the pipeline release batches in the given platform order.
+// Every listed platform must eventually be pushed or skipped, or flush
+// must be called.
+func (p *mergePipeline) inOrder(platforms []string) {
+	p.order = platforms
+	p.held = make(map[string][]*pb.Result, len(platforms))
+}
+
+// push merges a finished fetch
+func (p *mergePipeline) push(fetchResult *models.FetchResult) {
+	p.snippetFormats[fetchResult.Platform] = fetchResult.SnippetFormat
+	p.durations[fetchResult.Platform] = fetchResult.Duration
+	p.pushProto(fetchResult.Platform, p.budget.platformResults(fetchResult, p.limits.For(fetchResult.Platform)))
+}
+
+// pushProto merges results that are already in protobuf form
+func (p *mergePipeline) pushProto(platform string, results []*pb.Result) {
+	if p.next >= len(p.order) {
+		p.merge(platform, results)
+		return
+	}
+
+	p.held[platform] = results
+	for p.next < len(p.order) {
+		batch, ok := p.held[p.order[p.next]]
+		if !ok {
+			return
+		}
+		p.merge(p.order[p.next], batch)
+		p.next++
+	}
+}
+
+// skip marks a platform that will not deliver results
+func (p *mergePipeline) skip(platform string) {
+	p.pushProto(platform, nil)
+}
+
+// flush releases every held batch, giving up on platforms still missing
+func (p *mergePipeline) flush() {
+	for ; p.next < len(p.order); p.next++ {
+		if batch, ok := p.held[p.order[p.next]]; ok {
+			p.merge(p.order[p.next], batch)
+		}
+	}
+}
+
+func (p *mergePipeline) merge(platform string, results []*pb.Result) {
+	for _, stage := range p.stages {
+		results = stage(platform, results)
+	}
+
+	// Only admitted results claim their pages, so a duplicate of one the
+	// budget cut can still take its place
+	admitted := make([]*pb.Result, 0, len(results))
+	for _, result := range results {
+		if first := p.seen.duplicateOf(result); first != nil {
+			mergeDuplicate(first, result)
+			continue
+		}
+		if !p.budget.admit(result, len(p.results)) {
+			continue
+		}
+		p.seen.add(result)
+		p.results = append(p.results, result)
+		admitted = append(admitted, result)
+	}
+
+	if p.emit != nil && len(admitted) > 0 {
+		p.emit(platform, admitted, p.durations[platform])
+	}
+}
+
+// merged returns everything admitted so far
+func (p *mergePipeline) merged() []*pb.Result {
+	return p.results
+}
+
+// ranked returns everything admitted so far, ordered by ranker
+func (p *mergePipeline) ranked(ranker ranking.Ranker) []*pb.Result {
+	admitted := make(map[*pb.Result]bool, len(p.results))
+	for _, result := range p.results {
+		admitted[result] = true
+	}
+
+	// Duplicates are ranked as the copy seen first, which is the one that
+	// was admitted; results cut by the budget drop out
+	ranked := make([]*pb.Result, 0, len(p.results))
+	for _, result := range ranker.Rank(p.lists) {
+		if admitted[result] {
+			ranked = append(ranked, result)
+		}
+	}
+	return ranked
+}
+
+// recordStage keeps each platform's list for ranking
+func (p *mergePipeline) recordStage(platform string, results []*pb.Result) []*pb.Result {
+	if len(results) > 0 {
+		p.lists = append(p.lists, ranking.List{Platform: platform, Results: results})
+	}
+	return results
+}
+
+// linkURLKey is the metadata key of the page a discussion result (such as a
+// Reddit link post) is about
+const linkURLKey = "link_url"
+
+// alsoOnKey lists the other platforms a merged result was found on
+const alsoOnKey = "also_on"
+
+// canonicalizeStage rewrites result URLs to their canonical form, so links to
+// the same page are deduplicated and clients see one spelling of each
+func canonicalizeStage(platform string, results []*pb.Result) []*pb.Result {
+	for _, result := range results {
+		result.Url = urlcanon.Canonicalize(result.Url)
+		if link, ok := result.Metadata[linkURLKey]; ok {
+			result.Metadata[linkURLKey] = urlcanon.Canonicalize(link)
+		}
+	}
+	return results
+}
+
+// filterStage drops results that don't meet the deployment's or the request's
+// criteria, such as excluded sources or a minimum score
+func filterStage(filters []resultFilter) resultStage {
+	return func(platform string, results []*pb.Result) []*pb.Result {
+		kept := make([]*pb.Result, 0, len(results))
+	next:
+		for _, result := range results {
+			for _, filter := range filters {
+				if !filter.Keep(result) {
+					continue next
+				}
+			}
+			kept = append(kept, result)
+		}
+		return kept
+	}
+}
+
+// sanitizeStage cleans titles and snippets and cuts snippets to the
+// requested length. formats maps platforms to their snippet format;
+// platforms missing from it are treated as plain text.
+func sanitizeStage(length int, formats map[string]string) resultStage {
+	return func(platform string, results []*pb.Result) []*pb.Result {
+		for _, result := range results {
+			result.Title = sanitize.Title(result.Title)
+			result.Snippet = sanitize.Snippet(result.Snippet, formats[platform], length)
+		}
+		return results
+	}
+}
+
+// deduper maps the pages already in the response to the result admitted for
+// each. A discussion counts as its link_url, so a Reddit post about a
+// repository is a duplicate of the repository. When the duplicate comes from
+// another platform, what that platform said about the page is merged into
+// the result kept.
+type deduper map[string]*pb.Result
+
+// duplicateOf returns the admitted result for a page result points to, or
+// nil when it is the first
+func (d deduper) duplicateOf(result *pb.Result) *pb.Result {
+	for _, key := range dedupeKeys(result) {
+		if first, ok := d[key]; ok {
+			return first
+		}
+	}
+	return nil
+}
+
+// add records result as the one kept for its pages
+func (d deduper) add(result *pb.Result) {
+	for _, key := range dedupeKeys(result) {
+		d[key] = result
+	}
+}
+
+// dedupeKeys returns the URLs a result is known by: its own and, for
+// discussions, the page discussed
+func dedupeKeys(result *pb.Result) []string {
+	var keys []string
+	if result.Url != "" {
+		keys = append(keys, result.Url)
+	}
+	if link := result.Metadata[linkURLKey]; link != "" && link != result.Url {
+		keys = append(keys, link)
+	}
+	return keys
+}
+
+// mergeDuplicate records dup's platform on first, with dup's URL and
+// metadata under keys prefixed by the platform ("reddit.url",
+// "reddit.score"). Duplicates from first's own platform add nothing.
+func mergeDuplicate(first, dup *pb.Result) {
+	if dup.Platform == first.Platform {
+		return
+	}
+	also := first.Metadata[alsoOnKey]
+	if slices.Contains(strings.Split(also, ","), dup.Platform) {
+		return
+	}
+	if first.Metadata == nil {
+		first.Metadata = make(map[string]string)
+	}
+	if also != "" {
+		also += ","
+	}
+	first.Metadata[alsoOnKey] = also + dup.Platform
+
+	prefix := dup.Platform + "."
+	first.Metadata[prefix+"url"] = dup.Url
+	for key, value := range dup.Metadata {
+		if key != alsoOnKey && !strings.Contains(key, ".") {
+			first.Metadata[prefix+key] = value
+		}
+	}
+}
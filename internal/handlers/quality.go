@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/farhapartex/search-proxy/internal/fetchers"
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+// qualityFilter applies the request's quality presets. Each preset only
+// judges the platform whose metric it reads; answered_only judges every
+// Stack Exchange site.
+type qualityFilter struct {
+	answeredOnly bool
+	minStars     int
+	minUpvotes   int
+}
+
+// newQualityFilter returns the request's presets, or nil if it sets none
+func newQualityFilter(req *pb.SearchRequest) *qualityFilter {
+	if !req.AnsweredOnly && req.MinStars <= 0 && req.MinUpvotes <= 0 {
+		return nil
+	}
+	return &qualityFilter{
+		answeredOnly: req.AnsweredOnly,
+		minStars:     int(req.MinStars),
+		minUpvotes:   int(req.MinUpvotes),
+	}
+}
+
+// Keep reports whether result meets the presets. A nil filter keeps
+// everything.
+func (q *qualityFilter) Keep(result *pb.Result) bool {
+	if q == nil {
+		return true
+	}
+
+	platform := result.Platform
+	if fetchers.IsStackExchange(platform) {
+		platform = "stackoverflow"
+	}
+	switch platform {
+	case "stackoverflow":
+		return !q.answeredOnly || result.Metadata["is_answered"] == "true"
+	case "github":
+		// Only repositories have stars; code, issues and discussions pass
+		if result.ResultType != pb.ResultType_RESULT_TYPE_UNSPECIFIED && result.ResultType != pb.ResultType_RESULT_TYPE_REPOSITORY {
+			return true
+		}
+		return q.minStars <= 0 || metadataInt(result, "stars") >= q.minStars
+	case "reddit":
+		return q.minUpvotes <= 0 || metadataInt(result, "score") >= q.minUpvotes
+	}
+	return true
+}
+
+// key encodes the presets for cache scopes
+func (q *qualityFilter) key() string {
+	if q == nil {
+		return ""
+	}
+	return strconv.FormatBool(q.answeredOnly) + "," + strconv.Itoa(q.minStars) + "," + strconv.Itoa(q.minUpvotes)
+}
+
+// metadataInt reads a numeric metadata entry; missing or malformed values
+// count as zero
+func metadataInt(result *pb.Result, key string) int {
+	n, _ := strconv.Atoi(result.Metadata[key])
+	return n
+}
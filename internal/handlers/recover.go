@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/farhapartex/search-proxy/internal/logging"
+)
+
+// logPanic stops a panic on one of the handler's own goroutines from taking
+// the process down, logging it with its stack instead. It must be deferred
+// directly, as recover only stops a panic there.
+func logPanic(ctx context.Context, what string) {
+	if p := recover(); p != nil {
+		logging.Printf(ctx, "ERROR: Panic in %s: %v\n%s", what, p, debug.Stack())
+	}
+}
+
+// panicError reports a fetcher that panicked as a failed fetch
+func panicError(platform string, p any) error {
+	return fmt.Errorf("%s fetcher panicked: %v", platform, p)
+}
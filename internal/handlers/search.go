@@ -2,147 +2,1153 @@ package handlers
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	"net/http"
+	"net/url"
+	"runtime/debug"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/farhapartex/search-proxy/internal/cache"
 	"github.com/farhapartex/search-proxy/internal/config"
+	"github.com/farhapartex/search-proxy/internal/embeddings"
+	"github.com/farhapartex/search-proxy/internal/events"
 	"github.com/farhapartex/search-proxy/internal/fetchers"
+	"github.com/farhapartex/search-proxy/internal/filterexpr"
+	"github.com/farhapartex/search-proxy/internal/httpclient"
+	"github.com/farhapartex/search-proxy/internal/index"
+	"github.com/farhapartex/search-proxy/internal/logging"
 	"github.com/farhapartex/search-proxy/internal/models"
+	"github.com/farhapartex/search-proxy/internal/ranking"
+	"github.com/farhapartex/search-proxy/internal/ratelimit"
+	"github.com/farhapartex/search-proxy/internal/replay"
+	"github.com/farhapartex/search-proxy/internal/requestid"
+	"github.com/farhapartex/search-proxy/internal/semcache"
+	"github.com/farhapartex/search-proxy/internal/sourcefilter"
+	"github.com/farhapartex/search-proxy/internal/store"
+	"github.com/farhapartex/search-proxy/internal/suggest"
+	"github.com/farhapartex/search-proxy/internal/summarize"
+	"github.com/farhapartex/search-proxy/internal/tracing"
+	"github.com/farhapartex/search-proxy/internal/workpool"
 	pb "github.com/farhapartex/search-proxy/proto"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/text/language"
+	"google.golang.org/protobuf/proto"
 )
 
 // SearchHandler orchestrates concurrent searches across multiple platforms
 type SearchHandler struct {
-	fetchers map[string]fetchers.Fetcher
-	config   *config.Config
+	fetchers    *fetchers.Registry
+	config      *config.Config
+	httpMetrics *httpclient.Metrics
+	client      *http.Client
+	resolver    *httpclient.Resolver
+	index       *index.Index
+	store       *store.Store
+	publisher   events.Publisher
+	semCache    *semcache.Cache
+	respCache   cache.Cache
+	embedder    embeddings.Embedder
+	summarizer  summarize.Summarizer
+	answerer    summarize.Answerer
+	history     *suggest.History
+	sources     *sourcefilter.Filter
+	ranker      ranking.Ranker
+	reranker    *ranking.SemanticReranker
+	pages       *continuationStore
+
+	// credentials holds the rotatable credential pools by platform
+	credentials map[string]credentialSet
+
+	// breakers holds the platforms' circuit breakers, or nil when they are
+	// disabled
+	breakers *fetchers.CircuitBreakers
+
+	// quotas tracks the quota each upstream reports
+	quotas *fetchers.QuotaThrottle
+
+	// workers bounds the platform fetches running at once across searches
+	workers *workpool.Pool
+
+	// jobs tracks background work that outlives a search
+	jobs sync.WaitGroup
+
+	// now is the handler's clock; deterministic mode pins it
+	now           func() time.Time
+	deterministic bool
 }
 
+// ErrInvalidPageToken is returned when a continuation token is unknown,
+// expired, or was issued for a different query
+var ErrInvalidPageToken = errors.New("invalid or expired page token")
+
 // NewSearchHandler creates a new search handler
-func NewSearchHandler(cfg *config.Config) *SearchHandler {
+func NewSearchHandler(cfg *config.Config) (*SearchHandler, error) {
 	handler := &SearchHandler{
-		fetchers: make(map[string]fetchers.Fetcher),
-		config:   cfg,
+		fetchers:    fetchers.NewRegistry(),
+		config:      cfg,
+		httpMetrics: httpclient.NewMetrics(),
+		ranker:      ranking.NewReciprocalRankFusion(cfg.Ranking.RRFK),
+		pages:       newContinuationStore(cfg.Limits.ContinuationTTL, 10000),
+		workers: workpool.New(workpool.Config{
+			MaxConcurrent: cfg.Performance.MaxConcurrentFetches,
+			MaxPerKey:     cfg.Performance.MaxConcurrentFetchesPerPlatform,
+			MaxQueued:     cfg.Performance.FetchQueueSize,
+		}),
+		now: time.Now,
 	}
 
-	// Initialize fetchers
-	handler.fetchers["github"] = fetchers.NewGitHubFetcher(
-		cfg.GitHub.APIToken,
-		cfg.GitHub.BaseURL,
-	)
-	handler.fetchers["stackoverflow"] = fetchers.NewStackOverflowFetcher(
-		cfg.StackOverflow.APIKey,
-		cfg.StackOverflow.BaseURL,
-	)
-	handler.fetchers["reddit"] = fetchers.NewRedditFetcher(
+	if cfg.Deterministic.Enabled {
+		log.Println("WARNING: DETERMINISTIC_MODE is enabled; response timings are fixed and results are ordered by platform")
+		handler.makeDeterministic(cfg.Deterministic.Seed)
+	}
+
+	// All fetchers share one instrumented client so connection pooling and
+	// per-host limits apply across the whole process
+	clientOpts := httpclient.DefaultOptions()
+	clientOpts.Timeout = cfg.HTTPClient.Timeout
+	clientOpts.ProxyURL = cfg.HTTPClient.ProxyURL
+	clientOpts.MaxResponseBytes = cfg.HTTPClient.MaxResponseBytes
+	clientOpts.MaxIdleConns = cfg.HTTPClient.MaxIdleConns
+	clientOpts.MaxIdleConnsPerHost = cfg.HTTPClient.MaxIdleConnsPerHost
+	clientOpts.MaxConnsPerHost = cfg.HTTPClient.MaxConnsPerHost
+	clientOpts.IdleConnTimeout = cfg.HTTPClient.IdleConnTimeout
+	clientOpts.VCRMode = cfg.HTTPClient.VCRMode
+	clientOpts.VCRDir = cfg.HTTPClient.VCRDir
+	if cfg.HTTPClient.DNSCacheTTL > 0 {
+		handler.resolver = httpclient.NewResolver(cfg.HTTPClient.DNSCacheTTL)
+		clientOpts.Resolver = handler.resolver
+		go handler.resolver.Prewarm(context.Background(), upstreamHosts(cfg)...)
+	}
+
+	client, err := httpclient.New(clientOpts, handler.httpMetrics)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client: %w", err)
+	}
+	handler.client = client
+	if cfg.HTTPClient.VCRMode != replay.Off {
+		log.Printf("Upstream traffic %s mode (directory: %s)", cfg.HTTPClient.VCRMode, cfg.HTTPClient.VCRDir)
+	}
+
+	// Quotas are checked and recorded right around the platform's own
+	// requests, so every attempt counts
+	handler.quotas = fetchers.NewQuotaThrottle(cfg.RateLimit.QuotaThrottle, cfg.RateLimit.QuotaReserve, cfg.RateLimit.QuotaSlowdownPercent)
+	handler.fetchers.Use(handler.quotas.Wrap)
+
+	if cfg.RateLimit.Enabled {
+		limiter := newRateLimiter(cfg)
+		handler.fetchers.Use(func(fetcher fetchers.Fetcher) fetchers.Fetcher {
+			return fetchers.NewRateLimitedFetcher(fetcher, limiter)
+		})
+		log.Printf("Upstream rate limiting enabled (backend: %s)", cfg.RateLimit.Backend)
+	}
+
+	// Retries wrap the rate limiter, so every attempt spends from the budget
+	if cfg.Performance.RetryMaxAttempts > 1 {
+		policy := fetchers.RetryPolicy{
+			MaxAttempts: cfg.Performance.RetryMaxAttempts,
+			BaseDelay:   cfg.Performance.RetryBaseDelay,
+			Jitter:      cfg.Performance.RetryJitter,
+		}
+		handler.fetchers.Use(func(fetcher fetchers.Fetcher) fetchers.Fetcher {
+			return fetchers.NewRetryFetcher(fetcher, policy)
+		})
+	}
+
+	// The breaker wraps the retries, so a search counts as one failure and
+	// an open breaker skips the retries too
+	if cfg.Performance.EnableCircuitBreaker {
+		handler.breakers = fetchers.NewCircuitBreakers(cfg.Performance.CircuitBreakerThreshold, cfg.Performance.CircuitBreakerTimeout)
+		handler.fetchers.Use(handler.breakers.Wrap)
+	}
+
+	// Fetchers are built on first use so slow token acquisition or health
+	// probes never hold up startup
+	githubCredentials := fetchers.NewCredentialPool(cfg.GitHub.APIToken, cfg.GitHub.AnonymousFallback)
+	stackOverflowCredentials := fetchers.NewCredentialPool(cfg.StackOverflow.APIKey, cfg.StackOverflow.AnonymousFallback)
+	// The Reddit fetcher holds its own client ID and secret, so it is built
+	// up front for rotation to reach it; building it makes no requests
+	reddit := fetchers.NewRedditFetcher(
 		cfg.Reddit.ClientID,
 		cfg.Reddit.ClientSecret,
 		cfg.Reddit.UserAgent,
 		cfg.Reddit.BaseURL,
+		cfg.Reddit.SnippetFormat,
+		client,
 	)
+	handler.credentials = map[string]credentialSet{
+		"github":        githubCredentials,
+		"stackoverflow": stackOverflowCredentials,
+		"reddit":        reddit,
+	}
+	handler.fetchers.Register("github", func() (fetchers.Fetcher, error) {
+		return fetchers.NewGitHubFetcher(
+			githubCredentials,
+			fetchers.NewEndpointPool(cfg.GitHub.BaseURL, cfg.Performance.EndpointSelection),
+			client,
+		), nil
+	})
+	handler.fetchers.Register("stackoverflow", func() (fetchers.Fetcher, error) {
+		return fetchers.NewStackOverflowFetcher(
+			stackOverflowCredentials,
+			fetchers.NewEndpointPool(cfg.StackOverflow.BaseURL, cfg.Performance.EndpointSelection),
+			cfg.StackOverflow.BodyExcerpts,
+			client,
+		), nil
+	})
+	// Other Stack Exchange sites share Stack Overflow's keys and mirrors
+	for _, site := range cfg.StackOverflow.SiteList() {
+		handler.fetchers.Register(site, func() (fetchers.Fetcher, error) {
+			return fetchers.NewStackExchangeFetcher(
+				site,
+				stackOverflowCredentials,
+				fetchers.NewEndpointPool(cfg.StackOverflow.BaseURL, cfg.Performance.EndpointSelection),
+				cfg.StackOverflow.BodyExcerpts,
+				client,
+			), nil
+		})
+	}
+	handler.fetchers.Register("reddit", func() (fetchers.Fetcher, error) {
+		return reddit, nil
+	})
+	handler.fetchers.Register("hackernews", func() (fetchers.Fetcher, error) {
+		return fetchers.NewHackerNewsFetcher(
+			fetchers.NewEndpointPool(cfg.HackerNews.BaseURL, cfg.Performance.EndpointSelection),
+			client,
+		), nil
+	})
+	handler.fetchers.Register("dockerhub", func() (fetchers.Fetcher, error) {
+		return fetchers.NewDockerHubFetcher(
+			fetchers.NewEndpointPool(cfg.DockerHub.BaseURL, cfg.Performance.EndpointSelection),
+			client,
+		), nil
+	})
+	handler.fetchers.Register("devto", func() (fetchers.Fetcher, error) {
+		return fetchers.NewDevToFetcher(
+			fetchers.NewEndpointPool(cfg.DevTo.BaseURL, cfg.Performance.EndpointSelection),
+			client,
+		), nil
+	})
+
+	// Mocked platforms keep their names but never touch the network
+	for _, name := range handler.fetchers.Names() {
+		if cfg.Mock.Mocks(name) {
+			handler.fetchers.Register(name, func() (fetchers.Fetcher, error) {
+				return fetchers.NewMockFetcher(name, cfg.Mock.FixturesDir)
+			})
+			log.Printf("Mock mode: serving %s from fixtures", name)
+		}
+	}
+
+	if cfg.Performance.FetcherInit == "background" {
+		handler.fetchers.WarmUp(context.Background())
+	}
+
+	if cfg.Index.Enabled {
+		if cfg.Performance.DirectProtoConversion {
+			log.Println("WARNING: DIRECT_PROTO_CONVERSION is enabled; the local index will only serve existing entries")
+		}
+
+		idx, err := index.Open(cfg.Index.Path)
+		if err != nil {
+			return nil, err
+		}
+		handler.index = idx
+	}
+
+	if cfg.Store.Enabled {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		st, err := store.Open(ctx, cfg.Store.Driver, cfg.Store.DSN)
+		if err != nil {
+			return nil, err
+		}
+		handler.store = st
+	}
+
+	if cfg.Events.Enabled {
+		publisher, err := events.NewPublisher(cfg.Events.Backend, cfg.Events.Brokers, cfg.Events.Topic)
+		if err != nil {
+			return nil, err
+		}
+		handler.publisher = publisher
+	}
+
+	if cfg.ResponseCache.Enabled {
+		handler.respCache = newResponseCache(cfg)
+	}
+
+	if cfg.SemanticCache.Enabled || cfg.Ranking.SemanticRerank {
+		embedder, err := embeddings.New(
+			cfg.Embeddings.Provider,
+			cfg.Embeddings.URL,
+			cfg.Embeddings.Model,
+			cfg.Embeddings.APIKey,
+			client,
+		)
+		if err != nil {
+			return nil, err
+		}
+		handler.embedder = embedder
+	}
+
+	if cfg.SemanticCache.Enabled {
+		handler.semCache = semcache.New(
+			cfg.SemanticCache.SimilarityThreshold,
+			cfg.SemanticCache.TTL,
+			cfg.SemanticCache.MaxEntries,
+		)
+	}
+
+	if cfg.Ranking.SemanticRerank {
+		handler.reranker = ranking.NewSemanticReranker(handler.embedder, cfg.Ranking.RerankTopN)
+	}
 
-	return handler
+	if cfg.Summarize.Enabled {
+		handler.summarizer = summarize.NewHTTPSummarizer(
+			cfg.Summarize.URL,
+			cfg.Summarize.Model,
+			cfg.Summarize.APIKey,
+			client,
+		)
+	}
+
+	allow, err := sourcefilter.ParseList(cfg.Sources.Allow)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SOURCES_ALLOW: %w", err)
+	}
+	deny, err := sourcefilter.ParseList(cfg.Sources.Deny)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SOURCES_DENY: %w", err)
+	}
+	handler.sources = sourcefilter.New(allow, deny)
+
+	if cfg.Suggestions.Enabled {
+		handler.history = suggest.NewHistory(cfg.Suggestions.HistorySize)
+	}
+
+	if cfg.Answer.Enabled {
+		handler.answerer = summarize.NewHTTPSummarizer(
+			cfg.Answer.URL,
+			cfg.Answer.Model,
+			cfg.Answer.APIKey,
+			client,
+		)
+	}
+
+	return handler, nil
+}
+
+// upstreamHosts lists the hosts fetchers will talk to, for DNS pre-resolution
+func upstreamHosts(cfg *config.Config) []string {
+	hosts := []string{"www.reddit.com"}
+	for _, baseURLs := range []string{cfg.GitHub.BaseURL, cfg.StackOverflow.BaseURL, cfg.Reddit.BaseURL, cfg.HackerNews.BaseURL, cfg.DockerHub.BaseURL, cfg.DevTo.BaseURL} {
+		for _, baseURL := range strings.Split(baseURLs, ",") {
+			if u, err := url.Parse(strings.TrimSpace(baseURL)); err == nil && u.Hostname() != "" {
+				hosts = append(hosts, u.Hostname())
+			}
+		}
+	}
+	return hosts
+}
+
+// newRateLimiter builds the limiter selected by the rate limit configuration
+func newRateLimiter(cfg *config.Config) ratelimit.Limiter {
+	limits := map[string]ratelimit.Limit{
+		"github":        ratelimit.PerMinute(cfg.RateLimit.GitHubPerMinute, cfg.RateLimit.Burst),
+		"stackoverflow": ratelimit.PerMinute(cfg.RateLimit.StackOverflowPerMinute, cfg.RateLimit.Burst),
+		"reddit":        ratelimit.PerMinute(cfg.RateLimit.RedditPerMinute, cfg.RateLimit.Burst),
+		"hackernews":    ratelimit.PerMinute(cfg.RateLimit.HackerNewsPerMinute, cfg.RateLimit.Burst),
+		"dockerhub":     ratelimit.PerMinute(cfg.RateLimit.DockerHubPerMinute, cfg.RateLimit.Burst),
+		"devto":         ratelimit.PerMinute(cfg.RateLimit.DevToPerMinute, cfg.RateLimit.Burst),
+	}
+	for _, site := range cfg.StackOverflow.SiteList() {
+		limits[site] = ratelimit.PerMinute(cfg.RateLimit.StackOverflowPerMinute, cfg.RateLimit.Burst)
+	}
+
+	if cfg.RateLimit.Backend == "redis" {
+		return ratelimit.NewRedisLimiter(newRedisClient(cfg), cfg.RateLimit.KeyPrefix, limits)
+	}
+
+	return ratelimit.NewLocalLimiter(limits)
+}
+
+// newResponseCache builds the cache selected by the response cache
+// configuration
+func newResponseCache(cfg *config.Config) cache.Cache {
+	if cfg.ResponseCache.Backend == "redis" {
+		log.Printf("Response cache shared through Redis at %s", cfg.Redis.Addr)
+		return cache.NewRedisCache(newRedisClient(cfg), cfg.ResponseCache.KeyPrefix, cfg.ResponseCache.TTL)
+	}
+	return cache.NewLocalCache(cfg.ResponseCache.MaxEntries, cfg.ResponseCache.TTL)
+}
+
+// newRedisClient connects to the shared Redis instance
+func newRedisClient(cfg *config.Config) *redis.Client {
+	return redis.NewClient(&redis.Options{
+		Addr:     cfg.Redis.Addr,
+		Password: cfg.Redis.Password,
+		DB:       cfg.Redis.DB,
+	})
+}
+
+// HTTPMetrics returns per-host statistics for upstream HTTP traffic
+func (h *SearchHandler) HTTPMetrics() map[string]httpclient.HostStats {
+	return h.httpMetrics.Snapshot()
+}
+
+// CacheStats returns the response cache counters, or false when the cache is
+// disabled
+func (h *SearchHandler) CacheStats() (cache.Stats, bool) {
+	if h.respCache == nil {
+		return cache.Stats{}, false
+	}
+	return h.respCache.Stats(), true
+}
+
+// RegisterFetcher adds or replaces a platform at runtime
+func (h *SearchHandler) RegisterFetcher(name string, factory fetchers.Factory) {
+	h.fetchers.Register(name, factory)
+	log.Printf("Platform %s registered", name)
+}
+
+// UnregisterFetcher removes a platform at runtime
+func (h *SearchHandler) UnregisterFetcher(name string) bool {
+	return h.fetchers.Unregister(name)
+}
+
+// HasPlatform reports whether a platform can be searched
+func (h *SearchHandler) HasPlatform(name string) bool {
+	return h.fetchers.Has(name)
+}
+
+// Platforms returns the searchable platforms in sorted order
+func (h *SearchHandler) Platforms() []string {
+	return h.fetchers.Names()
+}
+
+// ListPlatforms describes every registered platform in name order
+func (h *SearchHandler) ListPlatforms() []*pb.PlatformInfo {
+	names := h.fetchers.Names()
+	platforms := make([]*pb.PlatformInfo, len(names))
+	for i, name := range names {
+		display := fetchers.DisplayFor(name)
+		platforms[i] = &pb.PlatformInfo{
+			Name:                  name,
+			DisplayName:           display.DisplayName,
+			IconUrl:               display.IconURL,
+			BrandColor:            display.BrandColor,
+			Description:           display.Description,
+			CredentialsConfigured: h.hasCredentials(name),
+			SupportedFilters:      fetchers.FiltersFor(name),
+			QueryTerms:            fetchers.QueryTermsFor(name),
+			CircuitState:          h.circuitState(name),
+			DefaultMaxResults:     int32(h.config.Performance.MaxResultsPerPlatform),
+			Quota:                 h.upstreamQuota(name),
+			Disabled:              !h.fetchers.Enabled(name),
+		}
+	}
+	return platforms
+}
+
+// UpstreamQuotas returns the quota each platform last reported
+func (h *SearchHandler) UpstreamQuotas() map[string]fetchers.QuotaStatus {
+	if h.quotas == nil {
+		return nil
+	}
+	return h.quotas.Statuses()
+}
+
+// upstreamQuota describes the quota a platform last reported, or nil if it
+// hasn't reported one
+func (h *SearchHandler) upstreamQuota(name string) *pb.UpstreamQuota {
+	if h.quotas == nil {
+		return nil
+	}
+	status, ok := h.quotas.Status(name)
+	if !ok {
+		return nil
+	}
+	quota := &pb.UpstreamQuota{
+		Limit:     int32(status.Limit),
+		Remaining: int32(status.Remaining),
+		Throttled: status.Throttled,
+	}
+	if !status.Reset.IsZero() {
+		quota.ResetAt = status.Reset.Unix()
+	}
+	return quota
+}
+
+// hasCredentials reports whether the server can authenticate with a
+// platform rather than search it anonymously
+func (h *SearchHandler) hasCredentials(name string) bool {
+	if set, ok := h.credentials[name]; ok {
+		return set.Len() > 0
+	}
+	return false
+}
+
+// circuitState reports the state of a platform's circuit breaker
+func (h *SearchHandler) circuitState(name string) pb.CircuitState {
+	if h.breakers == nil {
+		return pb.CircuitState_CIRCUIT_STATE_UNSPECIFIED
+	}
+	switch h.breakers.For(name).State() {
+	case fetchers.CircuitOpen:
+		return pb.CircuitState_CIRCUIT_STATE_OPEN
+	case fetchers.CircuitHalfOpen:
+		return pb.CircuitState_CIRCUIT_STATE_HALF_OPEN
+	}
+	return pb.CircuitState_CIRCUIT_STATE_CLOSED
 }
 
 // Search performs a federated search using the Fan-out/Fan-in pattern
 func (h *SearchHandler) Search(ctx context.Context, req *pb.SearchRequest) (*pb.SearchResponse, error) {
-	startTime := time.Now()
+	return h.search(ctx, req, nil)
+}
+
+// search runs the federated search, handing each platform's merged results
+// to emit as soon as they are ready. emit may be nil.
+func (h *SearchHandler) search(ctx context.Context, req *pb.SearchRequest, emit emitFunc) (*pb.SearchResponse, error) {
+	startTime := h.now()
+
+	// Fetchers still running when Search returns (budget expired, client
+	// went away) are cancelled rather than left to finish in the background
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// A cursor token fetches the next page of every platform that has one;
+	// any other token is the rest of a size-limited response
+	var cursors pageCursors
+	if req.PageToken != "" {
+		if !isCursorToken(req.PageToken) {
+			return h.continueSearch(ctx, req, startTime)
+		}
+		var err error
+		if cursors, err = parseCursorToken(req.PageToken); err != nil {
+			return nil, err
+		}
+	}
 
 	platforms := req.Platforms
-	if len(platforms) == 0 {
-		platforms = []string{"github", "stackoverflow", "reddit"}
+	if cursors != nil {
+		platforms = cursors.platforms()
+	} else if len(platforms) == 0 {
+		platforms = h.fetchers.EnabledNames()
 	}
 
 	maxResults := int(req.MaxResults)
 	if maxResults <= 0 || maxResults > 100 {
 		maxResults = h.config.Performance.MaxResultsPerPlatform
 	}
+	limits := newResultLimits(req, maxResults)
 
-	resultsChan := make(chan *models.FetchResult, len(platforms))
+	opts := requestOptions(req)
+	ctx = fetchers.WithRequestOptions(ctx, opts)
+
+	var queryVector []float32
+	requestSources, err := requestSourceFilter(req)
+	if err != nil {
+		return nil, err
+	}
+
+	summarizing := req.Summarize && h.summarizer != nil
+	snippetLength := int(req.MaxSnippetLength)
+	if snippetLength <= 0 {
+		snippetLength = h.config.Limits.DefaultSnippetLength
+	}
+	if limit := h.config.Limits.MaxSnippetLength; limit > 0 {
+		snippetLength = min(snippetLength, limit)
+	}
+
+	quality := newQualityFilter(req)
+	expr, err := filterexpr.Compile(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+	languages, err := newLanguagePreference(req)
+	if err != nil {
+		return nil, err
+	}
+
+	weights := newInterleaveWeights(req)
+	sortOrder := h.sortOrder(req)
+
+	scopeOptions := fmt.Sprintf("%s|%d|%s|%s|%s|%s|%s|%s|%s|%s", opts.Key(), snippetLength,
+		strings.Join(req.AllowSources, ","), strings.Join(req.DenySources, ","), quality.key(), req.Filter, languages.key(),
+		weights.key(), sortOrder, limits.key())
+	if summarizing {
+		scopeOptions += "|summarized"
+	}
+	if cursors != nil {
+		scopeOptions += "|" + req.PageToken
+	}
+	cacheScope := semcache.Scope(platforms, maxResults, scopeOptions)
+	cacheKey := req.Query + "\x00" + cacheScope
+	if h.respCache != nil {
+		hit, err := h.respCache.Get(ctx, cacheKey)
+		if err != nil {
+			logging.Printf(ctx, "WARNING: response cache unavailable: %v", err)
+		}
+		if hit != nil {
+			response := hit.Response
+			response.Metadata.ResponseTimeMs = int32(h.now().Sub(startTime).Milliseconds())
+			response.Metadata.CacheHit = true
+			response.Metadata.RequestId = requestid.FromContext(ctx)
+			for _, status := range response.PlatformStatuses {
+				status.ServedFromCache = true
+				status.CacheAgeMs = hit.Age.Milliseconds()
+			}
+			setAgeDays(response.Results, h.now())
+			h.limitResponseSize(req.Query, response)
+			return response, nil
+		}
+	}
+	if h.semCache != nil {
+		queryVector = h.embedQuery(ctx, req.Query)
+		if hit := h.semCache.Lookup(cacheScope, queryVector); hit != nil {
+			logging.Printf(ctx, "Semantic cache hit (similarity %.3f)", hit.Similarity)
+			response := hit.Response
+			response.Metadata.ResponseTimeMs = int32(h.now().Sub(startTime).Milliseconds())
+			response.Metadata.Approximate = true
+			response.Metadata.RequestId = requestid.FromContext(ctx)
+			response.Metadata.ApproximateQuery = hit.Query
+			response.Metadata.Similarity = float32(hit.Similarity)
+			for _, status := range response.PlatformStatuses {
+				status.ServedFromCache = true
+				status.CacheAgeMs = hit.Age.Milliseconds()
+			}
+			setAgeDays(response.Results, h.now())
+			h.suggestRelated(req.Query, response)
+			h.limitResponseSize(req.Query, response)
+			return response, nil
+		}
+	}
+
+	perPlatformTimeout := h.config.Server.PerAPITimeout
+	if ms := req.PerPlatformTimeoutMs; ms > 0 {
+		perPlatformTimeout = min(time.Duration(ms)*time.Millisecond, h.config.Server.MaxPerAPITimeout)
+	}
 
-	var wg sync.WaitGroup
+	// resultsChan is buffered so fetchers that finish after the search budget
+	// has expired never block on send once we have stopped reading.
+	resultsChan := make(chan *models.FetchResult, len(platforms))
+	pending := make(map[string]bool, len(platforms))
+	var launched []string
 
 	for _, platform := range platforms {
-		fetcher, exists := h.fetchers[platform]
-		if !exists {
-			log.Printf("WARNING: Unknown platform: %s", platform)
+		if pending[platform] {
 			continue
 		}
 
-		wg.Add(1)
-		go h.fetchFromPlatform(ctx, fetcher, req.Query, maxResults, resultsChan, &wg)
+		if !h.fetchers.Has(platform) {
+			logging.Printf(ctx, "WARNING: Unknown platform: %s", platform)
+			continue
+		}
+
+		pending[platform] = true
+		launched = append(launched, platform)
+
+		// A fetch only gets a goroutine once it has a slot or a place in
+		// the queue; past that the platform is skipped as overloaded
+		slot, err := h.workers.Reserve(platform)
+		if err != nil {
+			result := models.NewFetchResult(platform)
+			result.Error = fmt.Errorf("%s: %w", platform, err)
+			resultsChan <- result
+			continue
+		}
+		go h.fetchFromPlatform(ctx, platform, req.Query, cursors[platform], limits.For(platform), perPlatformTimeout, slot, resultsChan)
 	}
 
-	go func() {
-		wg.Wait()
-		close(resultsChan)
-	}()
+	// Look the query up in the local index alongside the upstream calls so
+	// its hits are ready to stand in for any platform that fails. Only first
+	// pages of the default content type use it: on later ones its hits
+	// would repeat earlier results, and it doesn't know code from issues.
+	var indexHits <-chan map[string][]*models.SearchResult
+	if cursors == nil && req.ContentType == pb.ContentType_CONTENT_TYPE_UNSPECIFIED {
+		indexHits = h.lookupIndex(ctx, req.Query, platforms, limits)
+	}
+
+	budget := newResultBudget(h.config.Limits)
+	merger := newMergePipeline(budget, limits, snippetLength, languages, emit, h.sources, requestSources, quality, expr, newTimeRange(req))
+	if h.deterministic {
+		merger.inOrder(launched)
+	}
+	durations := make(map[string]time.Duration, len(platforms))
+	var shortenedQueries map[string]string
+	nextCursors := make(pageCursors)
 
-	var allResults []*pb.Result
 	var platformsSuccess []string
 	var platformsTimeout []string
 	var platformsError []string
+	var platformStatuses []*pb.PlatformStatus
 
-	for fetchResult := range resultsChan {
+collect:
+	for len(pending) > 0 {
+		select {
+		case fetchResult := <-resultsChan:
+			delete(pending, fetchResult.Platform)
+			durations[fetchResult.Platform] = fetchResult.Duration
+			status := platformStatus(fetchResult)
+			if fetchResult.Error == nil {
+				status.DataAsOf = h.now().Unix()
+			}
+			platformStatuses = append(platformStatuses, status)
+			if fetchResult.Query != "" {
+				if shortenedQueries == nil {
+					shortenedQueries = make(map[string]string)
+				}
+				shortenedQueries[fetchResult.Platform] = fetchResult.Query
+			}
 
-		if fetchResult.Error != nil {
-			log.Printf(fetchResult.Error.Error())
-			if fetchResult.TimedOut {
-				platformsTimeout = append(platformsTimeout, fetchResult.Platform)
-				log.Printf("Platform %s timed out: %v", fetchResult.Platform, fetchResult.Error)
-			} else {
-				platformsError = append(platformsError, fetchResult.Platform)
-				log.Printf("Platform %s error: %v", fetchResult.Platform, fetchResult.Error)
+			if fetchResult.Error != nil {
+				if fetchResult.TimedOut {
+					platformsTimeout = append(platformsTimeout, fetchResult.Platform)
+					logging.Printf(ctx, "Platform %s timed out: %v", fetchResult.Platform, fetchResult.Error)
+				} else {
+					platformsError = append(platformsError, fetchResult.Platform)
+					logging.Printf(ctx, "Platform %s error: %v", fetchResult.Platform, fetchResult.Error)
+				}
+				merger.skip(fetchResult.Platform)
+				continue
 			}
-			continue
+
+			platformsSuccess = append(platformsSuccess, fetchResult.Platform)
+			if fetchResult.NextCursor != "" {
+				nextCursors[fetchResult.Platform] = fetchResult.NextCursor
+			}
+			logging.Printf(ctx, "Platform %s returned %d results in %v",
+				fetchResult.Platform, len(fetchResult.Results), fetchResult.Duration)
+
+			if h.index != nil && len(fetchResult.Results) > 0 {
+				results := fetchResult.Results
+				h.background(ctx, func() { h.indexResults(results) })
+			}
+
+			merger.push(fetchResult)
+
+		case <-ctx.Done():
+			// Nobody is waiting for a response any more; the deferred cancel
+			// aborts the in-flight upstream requests
+			if errors.Is(ctx.Err(), context.Canceled) {
+				logging.Printf(ctx, "Search cancelled by client with %d platforms in flight", len(pending))
+				return nil, ctx.Err()
+			}
+
+			// The search budget is spent: return what we have and report the
+			// stragglers as timed out instead of waiting for them.
+			for _, platform := range platforms {
+				if pending[platform] {
+					platformsTimeout = append(platformsTimeout, platform)
+					platformStatuses = append(platformStatuses, timedOutStatus(platform, h.now().Sub(startTime)))
+					logging.Printf(ctx, "Platform %s timed out: %v", platform, ctx.Err())
+				}
+			}
+			break collect
 		}
+	}
+
+	merger.flush()
 
-		platformsSuccess = append(platformsSuccess, fetchResult.Platform)
-		log.Printf("Platform %s returned %d results in %v",
-			fetchResult.Platform, len(fetchResult.Results), fetchResult.Duration)
+	if h.deterministic {
+		sort.Strings(platformsSuccess)
+		sort.Strings(platformsTimeout)
+		sort.Strings(platformsError)
+		sort.Slice(platformStatuses, func(i, j int) bool {
+			return platformStatuses[i].Platform < platformStatuses[j].Platform
+		})
+		for _, status := range platformStatuses {
+			status.DurationMs = 0
+		}
+	}
 
-		for _, result := range fetchResult.Results {
-			allResults = append(allResults, result.ToProto())
+	var platformsFromIndex []string
+	failed := append(append([]string{}, platformsTimeout...), platformsError...)
+	if indexHits != nil && len(failed) > 0 {
+		hits := awaitIndexHits(ctx, indexHits)
+		for _, platform := range failed {
+			// A disabled platform is left out, not stood in for
+			if len(hits[platform]) == 0 || !h.fetchers.Enabled(platform) {
+				continue
+			}
+			platformsFromIndex = append(platformsFromIndex, platform)
+			markServedFromIndex(platformStatuses, platform, hits[platform], h.now())
+			protoResults := make([]*pb.Result, len(hits[platform]))
+			for i, result := range hits[platform] {
+				protoResults[i] = result.ToProto()
+			}
+			merger.pushProto(platform, protoResults)
 		}
 	}
 
-	responseTime := time.Since(startTime)
+	allResults := merger.merged()
+	ranking.Normalize(allResults, ranking.Normalization(h.config.Ranking.ScoreNormalization))
+	switch sortOrder {
+	case pb.SortOrder_SORT_ORDER_RELEVANCE:
+		allResults = merger.ranked(h.ranker)
+		if h.reranker != nil {
+			allResults = h.rerank(ctx, req.Query, queryVector, allResults)
+		}
+	case pb.SortOrder_SORT_ORDER_SCORE:
+		allResults = merger.ranked(ranking.ScoreRanker{})
+	}
+	allResults = weights.apply(allResults)
+	languages.sort(allResults)
+	setAgeDays(allResults, h.now())
+	if summarizing {
+		h.summarizeResults(ctx, allResults)
+	}
+	responseTime := h.now().Sub(startTime)
 
 	response := &pb.SearchResponse{
 		Results:          allResults,
 		TotalCount:       int32(len(allResults)),
+		NextPageToken:    nextCursors.token(),
 		PlatformsSuccess: platformsSuccess,
 		PlatformsTimeout: platformsTimeout,
 		PlatformsError:   platformsError,
+		PlatformStatuses: platformStatuses,
 		Metadata: &pb.ResponseMetadata{
-			ResponseTimeMs:   int32(responseTime.Milliseconds()),
-			PlatformsQueried: int32(len(platforms)),
+			ResponseTimeMs:     int32(responseTime.Milliseconds()),
+			PlatformsQueried:   int32(len(platforms)),
+			PlatformsFromIndex: platformsFromIndex,
+			ShortenedQueries:   shortenedQueries,
+			RequestId:          requestid.FromContext(ctx),
 		},
 	}
 
-	log.Printf("Search completed in %v. Total results: %d (Success: %d, Timeout: %d, Error: %d)",
+	budget.record(response.Metadata)
+	h.suggestRelated(req.Query, response)
+
+	// Only complete answers are worth reusing
+	if len(platformsTimeout) == 0 && len(platformsError) == 0 {
+		if h.respCache != nil {
+			h.cacheResponse(ctx, cacheKey, response)
+		}
+		if queryVector != nil {
+			h.semCache.Store(req.Query, cacheScope, queryVector, response)
+		}
+	}
+
+	h.limitResponseSize(req.Query, response)
+
+	if h.store != nil {
+		h.background(ctx, func() { h.recordSearch(req, response) })
+	}
+	if h.publisher != nil {
+		event := events.NewSearchEvent(req, response, durations)
+		h.background(ctx, func() { h.publishSearch(event) })
+	}
+
+	logging.Printf(ctx, "Search completed in %v. Total results: %d (Success: %d, Timeout: %d, Error: %d)",
 		responseTime, len(allResults), len(platformsSuccess), len(platformsTimeout), len(platformsError))
 
 	return response, nil
 }
 
+// requestOptions extracts the per-request hints forwarded to fetchers
+func requestOptions(req *pb.SearchRequest) fetchers.RequestOptions {
+	var opts fetchers.RequestOptions
+	if tag, err := language.Parse(req.Locale); err == nil && tag != language.Und {
+		opts.Locale = tag.String()
+	}
+	opts.MinStars = int(req.MinStars)
+	opts.AnsweredOnly = req.AnsweredOnly
+	opts.ContentType = req.ContentType
+	if req.Since > 0 {
+		opts.Since = time.Unix(req.Since, 0)
+	}
+	if req.Until > 0 {
+		opts.Until = time.Unix(req.Until, 0)
+	}
+	return opts
+}
+
+// suggestRelated fills in related queries from the response's results, then
+// remembers query for future suggestions
+func (h *SearchHandler) suggestRelated(query string, response *pb.SearchResponse) {
+	if h.history == nil {
+		return
+	}
+	response.Metadata.RelatedQueries = suggest.Related(query, response.Results, h.history, h.config.Suggestions.Max)
+	h.history.Add(query)
+}
+
+// requestSourceFilter builds the filter for the request's own allow and deny
+// lists
+func requestSourceFilter(req *pb.SearchRequest) (*sourcefilter.Filter, error) {
+	allow, err := sourcefilter.Parse(req.AllowSources)
+	if err != nil {
+		return nil, err
+	}
+	deny, err := sourcefilter.Parse(req.DenySources)
+	if err != nil {
+		return nil, err
+	}
+	return sourcefilter.New(allow, deny), nil
+}
+
+// setAgeDays computes each result's age as of now. It runs on every response,
+// including cached ones, so ages never go stale.
+func setAgeDays(results []*pb.Result, now time.Time) {
+	for _, result := range results {
+		result.AgeDays = models.AgeDays(result.CreatedAt, now)
+	}
+}
+
+// sortOrder is the request's sort, or the configured default when it sets
+// none
+func (h *SearchHandler) sortOrder(req *pb.SearchRequest) pb.SortOrder {
+	if req.Sort != pb.SortOrder_SORT_ORDER_UNSPECIFIED {
+		return req.Sort
+	}
+	switch h.config.Ranking.DefaultSort {
+	case "relevance":
+		return pb.SortOrder_SORT_ORDER_RELEVANCE
+	case "score":
+		return pb.SortOrder_SORT_ORDER_SCORE
+	}
+	return pb.SortOrder_SORT_ORDER_ARRIVAL
+}
+
+// indexLookupTimeout bounds how long the local index lookup may take
+const indexLookupTimeout = 500 * time.Millisecond
+
+// lookupIndex searches the local index for every platform in the background.
+// The returned channel receives the hits exactly once; platforms whose lookup
+// failed are simply missing. It is nil when the index is disabled.
+func (h *SearchHandler) lookupIndex(ctx context.Context, query string, platforms []string, limits resultLimits) <-chan map[string][]*models.SearchResult {
+	if h.index == nil {
+		return nil
+	}
+	hitsChan := make(chan map[string][]*models.SearchResult, 1)
+
+	go func() {
+		// The lookup has its own deadline rather than the request's, so
+		// its hits are still there for platforms that timed out once the
+		// request has ended
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), indexLookupTimeout)
+		defer cancel()
+		defer logPanic(ctx, "local index lookup")
+		hits := make(map[string][]*models.SearchResult, len(platforms))
+		for _, platform := range platforms {
+			results, err := h.index.Search(ctx, query, platform, limits.For(platform))
+			if err != nil {
+				logging.Printf(ctx, "Local index lookup for %s failed: %v", platform, err)
+				continue
+			}
+			hits[platform] = results
+		}
+		hitsChan <- hits
+	}()
+
+	return hitsChan
+}
+
+// awaitIndexHits waits for the local index lookup until ctx ends. Hits that
+// are already in are taken even when ctx has ended too, which a plain select
+// would do only half the time.
+func awaitIndexHits(ctx context.Context, indexHits <-chan map[string][]*models.SearchResult) map[string][]*models.SearchResult {
+	select {
+	case hits := <-indexHits:
+		return hits
+	default:
+	}
+	select {
+	case hits := <-indexHits:
+		return hits
+	case <-ctx.Done():
+		return nil
+	}
+}
+
+// cacheWriteTimeout bounds how long a response cache write may take
+const cacheWriteTimeout = 200 * time.Millisecond
+
+// cacheResponse stores a complete response for identical requests. The
+// write gets its own short deadline, so a search that used up its budget
+// is still cached and a slow cache can't hold the response back for long.
+func (h *SearchHandler) cacheResponse(ctx context.Context, key string, response *pb.SearchResponse) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cacheWriteTimeout)
+	defer cancel()
+
+	if err := h.respCache.Put(ctx, key, response); err != nil {
+		logging.Printf(ctx, "WARNING: failed to cache response: %v", err)
+	}
+}
+
+// indexResults stores freshly fetched results in the local index
+func (h *SearchHandler) indexResults(results []*models.SearchResult) {
+	if err := h.index.Add(results); err != nil {
+		log.Printf("Failed to index results: %v", err)
+	}
+}
+
+// continueSearch serves the results held back from an earlier size-limited
+// response
+func (h *SearchHandler) continueSearch(ctx context.Context, req *pb.SearchRequest, startTime time.Time) (*pb.SearchResponse, error) {
+	response, ok := h.pages.take(req.PageToken, req.Query)
+	if !ok {
+		return nil, ErrInvalidPageToken
+	}
+
+	response.Metadata.ResponseTimeMs = int32(h.now().Sub(startTime).Milliseconds())
+	response.Metadata.RequestId = requestid.FromContext(ctx)
+	setAgeDays(response.Results, h.now())
+	h.limitResponseSize(req.Query, response)
+	return response, nil
+}
+
+// limitResponseSize cuts response down to the configured maximum size and
+// parks the overflow behind a continuation token
+func (h *SearchHandler) limitResponseSize(query string, response *pb.SearchResponse) {
+	overflow := splitToSize(response, h.config.Limits.MaxResponseBytes)
+	if len(overflow) == 0 {
+		return
+	}
+
+	next := &pb.SearchResponse{
+		Results:          overflow,
+		TotalCount:       int32(len(overflow)),
+		PlatformsSuccess: response.PlatformsSuccess,
+		PlatformsTimeout: response.PlatformsTimeout,
+		PlatformsError:   response.PlatformsError,
+		PlatformStatuses: response.PlatformStatuses,
+		Metadata:         proto.Clone(response.Metadata).(*pb.ResponseMetadata),
+		// The last part of the response leads on to the next page
+		NextPageToken: response.NextPageToken,
+	}
+	response.NextPageToken = h.pages.put(query, next)
+
+	log.Printf("Response exceeded %d bytes, deferred %d results to a continuation",
+		h.config.Limits.MaxResponseBytes, len(overflow))
+}
+
+// embedQuery returns the query embedding, or nil if the embedding backend is
+// too slow or unavailable, in which case the semantic cache is skipped
+func (h *SearchHandler) embedQuery(ctx context.Context, query string) []float32 {
+	embedCtx, cancel := context.WithTimeout(ctx, h.config.Embeddings.Timeout)
+	defer cancel()
+
+	vector, err := h.embedder.Embed(embedCtx, query)
+	if err != nil {
+		logging.Printf(ctx, "Query embedding failed, skipping semantic cache: %v", err)
+		return nil
+	}
+	return vector
+}
+
+// rerank reorders fused results by similarity to the query, reusing the
+// semantic cache's query embedding when there is one, or keeps the fused
+// order if the embedding backend is too slow or unavailable
+func (h *SearchHandler) rerank(ctx context.Context, query string, queryVector []float32, results []*pb.Result) []*pb.Result {
+	rerankCtx, cancel := context.WithTimeout(ctx, h.config.Ranking.RerankTimeout)
+	defer cancel()
+
+	reranked, err := h.reranker.Rerank(rerankCtx, query, queryVector, results)
+	if err != nil {
+		logging.Printf(ctx, "Semantic re-ranking failed, keeping fused order: %v", err)
+	}
+	return reranked
+}
+
+// recordSearch persists the search outside the request's deadline so a slow
+// database never delays the response
+func (h *SearchHandler) recordSearch(req *pb.SearchRequest, response *pb.SearchResponse) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := h.store.RecordSearch(ctx, req, response); err != nil {
+		log.Printf("Failed to record search: %v", err)
+	}
+}
+
+// publishSearch emits the search event outside the request's deadline
+func (h *SearchHandler) publishSearch(event *events.SearchEvent) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := h.publisher.Publish(ctx, event); err != nil {
+		log.Printf("Failed to publish search event: %v", err)
+	}
+}
+
 func (h *SearchHandler) fetchFromPlatform(
 	parentCtx context.Context,
-	fetcher fetchers.Fetcher,
+	platform string,
 	query string,
+	cursor string,
 	maxResults int,
+	timeout time.Duration,
+	slot *workpool.Slot,
 	resultsChan chan<- *models.FetchResult,
-	wg *sync.WaitGroup,
 ) {
-	defer wg.Done()
-
 	startTime := time.Now()
-	result := models.NewFetchResult(fetcher.Name())
+	result := models.NewFetchResult(platform)
 
-	ctx, cancel := context.WithTimeout(parentCtx, h.config.Server.PerAPITimeout)
+	// A panicking fetcher fails its platform like any other error; the
+	// search still waits for this result, so it must be sent
+	defer func() {
+		if p := recover(); p != nil {
+			logging.Printf(parentCtx, "ERROR: Panic fetching from %s: %v\n%s", platform, p, debug.Stack())
+			result.Results, result.ProtoResults = nil, nil
+			result.Error = panicError(platform, p)
+			result.Duration = time.Since(startTime)
+			resultsChan <- result
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(parentCtx, timeout)
 	defer cancel()
+	ctx, span := tracing.Tracer().Start(ctx, "fetch "+platform,
+		trace.WithAttributes(
+			attribute.String("search.platform", platform),
+			attribute.String("request.id", requestid.FromContext(ctx)),
+		))
+	defer span.End()
+	page := &fetchers.Page{Cursor: cursor}
+	ctx = fetchers.WithPage(ctx, page)
 
-	results, err := fetcher.Fetch(ctx, query, maxResults)
+	// Waiting for a slot and initialization count against the platform's
+	// timeout like any other upstream work. A disabled platform isn't even
+	// initialized.
+	err := slot.Wait(ctx)
+	defer slot.Release()
+	var fetcher fetchers.Fetcher
+	if err == nil {
+		err = disabledError(platform)
+		if h.fetchers.Enabled(platform) {
+			fetcher, err = h.fetchers.Get(ctx, platform)
+		}
+	}
+	if err == nil {
+		result.SnippetFormat = fetchers.SnippetFormat(fetcher)
+		// An over-long query would fail the platform outright; a shorter
+		// one still finds something
+		if short := fetchers.ShortenQuery(query, fetchers.MaxQueryLength(fetcher)); short != query {
+			logging.Printf(ctx, "WARNING: Query too long for %s, shortened to %q", platform, short)
+			query, result.Query = short, short
+		}
+		if h.config.Performance.DirectProtoConversion {
+			result.ProtoResults, err = fetchers.FetchProto(ctx, fetcher, query, maxResults)
+		} else {
+			result.Results, err = fetcher.Fetch(ctx, query, maxResults)
+		}
+	}
+	fetchedAt := h.now().Unix()
+	for _, r := range result.Results {
+		r.FetchedAt = fetchedAt
+	}
 	result.Duration = time.Since(startTime)
 
 	if err != nil {
@@ -150,9 +1156,15 @@ func (h *SearchHandler) fetchFromPlatform(
 		if ctx.Err() == context.DeadlineExceeded {
 			result.TimedOut = true
 		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 	} else {
-		result.Results = results
+		result.NextCursor = page.Next
 	}
+	span.SetAttributes(
+		attribute.Int("search.results", len(result.Results)+len(result.ProtoResults)),
+		attribute.Bool("search.timed_out", result.TimedOut),
+	)
 
 	resultsChan <- result
 }
@@ -0,0 +1,1376 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/goleak"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/farhapartex/search-proxy/internal/cache"
+	"github.com/farhapartex/search-proxy/internal/config"
+	"github.com/farhapartex/search-proxy/internal/events"
+	"github.com/farhapartex/search-proxy/internal/fetchers"
+	"github.com/farhapartex/search-proxy/internal/httpclient"
+	"github.com/farhapartex/search-proxy/internal/index"
+	"github.com/farhapartex/search-proxy/internal/models"
+	"github.com/farhapartex/search-proxy/internal/ranking"
+	"github.com/farhapartex/search-proxy/internal/ratelimit"
+	"github.com/farhapartex/search-proxy/internal/requestid"
+	"github.com/farhapartex/search-proxy/internal/store"
+	"github.com/farhapartex/search-proxy/internal/summarize"
+	"github.com/farhapartex/search-proxy/internal/testutil"
+	"github.com/farhapartex/search-proxy/internal/workpool"
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files in testdata")
+
+// stubFetcher returns canned results after delay. Unless ignoreCtx is set it
+// gives up as soon as its context is cancelled, like a real HTTP fetcher.
+type stubFetcher struct {
+	name      string
+	delay     time.Duration
+	ignoreCtx bool
+	err       error
+	cancelled chan struct{}
+	// results replaces the canned result when set
+	results []*models.SearchResult
+}
+
+func newStubFetcher(name string, delay time.Duration) *stubFetcher {
+	return &stubFetcher{name: name, delay: delay, cancelled: make(chan struct{})}
+}
+
+func (f *stubFetcher) Name() string {
+	return f.name
+}
+
+func (f *stubFetcher) Fetch(ctx context.Context, query string, maxResults int) ([]*models.SearchResult, error) {
+	if f.ignoreCtx {
+		time.Sleep(f.delay)
+	} else {
+		select {
+		case <-time.After(f.delay):
+		case <-ctx.Done():
+			close(f.cancelled)
+			return nil, ctx.Err()
+		}
+	}
+	if f.err != nil {
+		return nil, f.err
+	}
+	if f.results != nil {
+		return f.results, nil
+	}
+
+	result := models.NewSearchResult(f.name, query, "snippet", "https://example.com/"+f.name)
+	result.Timestamp = 1700000000
+	return []*models.SearchResult{result}, nil
+}
+
+func newTestHandler(fs ...fetchers.Fetcher) *SearchHandler {
+	cfg := &config.Config{
+		Server:      config.ServerConfig{PerAPITimeout: 5 * time.Second, MaxPerAPITimeout: 5 * time.Second},
+		Performance: config.PerformanceConfig{MaxResultsPerPlatform: 10},
+		Limits:      config.LimitsConfig{DefaultSnippetLength: 500},
+	}
+	h := &SearchHandler{
+		fetchers: fetchers.NewRegistry(),
+		config:   cfg,
+		pages:    newContinuationStore(time.Minute, 10),
+		workers:  workpool.New(workpool.Config{}),
+		now:      time.Now,
+	}
+	for _, f := range fs {
+		h.fetchers.RegisterFetcher(f)
+	}
+	return h
+}
+
+func platformNames(fs ...*stubFetcher) []string {
+	names := make([]string, len(fs))
+	for i, f := range fs {
+		names[i] = f.name
+	}
+	return names
+}
+
+func TestSearchHonoursPerPlatformTimeout(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		requested int32
+		max       time.Duration
+	}{
+		{"requested", 50, 5 * time.Second},
+		{"capped at the server maximum", 600000, 50 * time.Millisecond},
+	} {
+		fast := newStubFetcher("fast", 0)
+		slow := newStubFetcher("slow", time.Hour)
+		h := newTestHandler(fast, slow)
+		h.config.Server.MaxPerAPITimeout = tc.max
+		req := &pb.SearchRequest{Query: "go", Platforms: platformNames(fast, slow), PerPlatformTimeoutMs: tc.requested}
+
+		done := make(chan *pb.SearchResponse, 1)
+		go func() {
+			response, err := h.Search(context.Background(), req)
+			if err != nil {
+				t.Errorf("%s: Search() error = %v", tc.name, err)
+			}
+			done <- response
+		}()
+
+		select {
+		case response := <-done:
+			if response != nil && !slices.Equal(response.PlatformsTimeout, []string{"slow"}) {
+				t.Errorf("%s: PlatformsTimeout = %v, want [slow]", tc.name, response.PlatformsTimeout)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("%s: Search() ignored per_platform_timeout_ms", tc.name)
+		}
+	}
+}
+
+func TestSearchBoundsConcurrentFetches(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		queue      int
+		overloaded []string
+	}{
+		{"queued", 1, nil},
+		{"shed", 0, []string{"second"}},
+	} {
+		first := newStubFetcher("first", 20*time.Millisecond)
+		second := newStubFetcher("second", 0)
+		h := newTestHandler(first, second)
+		h.workers = workpool.New(workpool.Config{MaxConcurrent: 1, MaxQueued: tc.queue})
+
+		response, err := h.Search(context.Background(), &pb.SearchRequest{Query: "go", Platforms: platformNames(first, second)})
+		if err != nil {
+			t.Fatalf("%s: Search() error = %v", tc.name, err)
+		}
+		var overloaded []string
+		for _, status := range response.PlatformStatuses {
+			if status.ErrorCode == "overloaded" {
+				overloaded = append(overloaded, status.Platform)
+			}
+		}
+		if !slices.Equal(overloaded, tc.overloaded) || len(response.PlatformsSuccess)+len(overloaded) != 2 {
+			t.Errorf("%s: statuses = %v, want %v overloaded and the rest successful", tc.name, response.PlatformStatuses, tc.overloaded)
+		}
+	}
+}
+
+func TestSearchAbortsFetchersOnClientCancel(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	slowA := newStubFetcher("a", time.Hour)
+	slowB := newStubFetcher("b", time.Hour)
+	h := newTestHandler(slowA, slowB)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	_, err := h.Search(ctx, &pb.SearchRequest{Query: "go", Platforms: platformNames(slowA, slowB)})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Search() error = %v, want context.Canceled", err)
+	}
+
+	for _, f := range []*stubFetcher{slowA, slowB} {
+		select {
+		case <-f.cancelled:
+		case <-time.After(time.Second):
+			t.Errorf("fetcher %s was not cancelled", f.name)
+		}
+	}
+}
+
+func TestSearchReturnsPartialResultsAtDeadline(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	fast := newStubFetcher("fast", 0)
+	slow := newStubFetcher("slow", time.Hour)
+	h := newTestHandler(fast, slow)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	resp, err := h.Search(ctx, &pb.SearchRequest{Query: "go", Platforms: platformNames(fast, slow)})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Search() took %v, want it to return at the deadline", elapsed)
+	}
+
+	if len(resp.PlatformsSuccess) != 1 || resp.PlatformsSuccess[0] != "fast" {
+		t.Errorf("PlatformsSuccess = %v, want [fast]", resp.PlatformsSuccess)
+	}
+	if len(resp.PlatformsTimeout) != 1 || resp.PlatformsTimeout[0] != "slow" {
+		t.Errorf("PlatformsTimeout = %v, want [slow]", resp.PlatformsTimeout)
+	}
+	if resp.TotalCount != 1 {
+		t.Errorf("TotalCount = %d, want 1", resp.TotalCount)
+	}
+
+	select {
+	case <-slow.cancelled:
+	case <-time.After(time.Second):
+		t.Error("straggling fetcher was not cancelled after Search returned")
+	}
+}
+
+func TestSearchDoesNotBlockLateFetchers(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	// A fetcher that ignores cancellation must neither hold the response
+	// past the deadline nor block when it delivers its result after Search
+	// has stopped reading, and must then exit
+	fast := newStubFetcher("fast", 0)
+	stubborn := newStubFetcher("stubborn", 200*time.Millisecond)
+	stubborn.ignoreCtx = true
+	h := newTestHandler(fast, stubborn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	resp, err := h.Search(ctx, &pb.SearchRequest{Query: "go", Platforms: platformNames(fast, stubborn)})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed >= 150*time.Millisecond {
+		t.Errorf("Search() took %v, want it to return at the deadline without waiting for the stubborn fetcher", elapsed)
+	}
+	if len(resp.PlatformsTimeout) != 1 || resp.PlatformsTimeout[0] != "stubborn" {
+		t.Errorf("PlatformsTimeout = %v, want [stubborn]", resp.PlatformsTimeout)
+	}
+	if resp.TotalCount != 1 {
+		t.Errorf("TotalCount = %d, want fast's result", resp.TotalCount)
+	}
+	if len(resp.PlatformStatuses) != 2 {
+		t.Errorf("PlatformStatuses = %v, want one per platform", resp.PlatformStatuses)
+	}
+}
+
+func TestSearchAgainstSimulatedUpstreams(t *testing.T) {
+	github := testutil.NewGitHub(t)
+	stackOverflow := testutil.NewStackOverflow(t)
+	stackOverflow.Set(testutil.Behavior{RateLimited: true})
+	reddit := testutil.NewReddit(t)
+	reddit.Set(testutil.Behavior{Latency: time.Minute})
+
+	h := newTestHandler(
+		fetchers.NewGitHubFetcher(nil, fetchers.NewEndpointPool(github.URL(), fetchers.SelectPriority), http.DefaultClient),
+		fetchers.NewStackOverflowFetcher(nil, fetchers.NewEndpointPool(stackOverflow.URL(), fetchers.SelectPriority), true, http.DefaultClient),
+		fetchers.NewRedditFetcher("", "", "test-agent/1.0", reddit.URL(), "", reddit.Client()),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	resp, err := h.Search(ctx, &pb.SearchRequest{Query: "context cancellation"})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+
+	if len(resp.PlatformsSuccess) != 1 || resp.PlatformsSuccess[0] != "github" {
+		t.Errorf("PlatformsSuccess = %v, want [github]", resp.PlatformsSuccess)
+	}
+	if len(resp.PlatformsError) != 1 || resp.PlatformsError[0] != "stackoverflow" {
+		t.Errorf("PlatformsError = %v, want [stackoverflow]", resp.PlatformsError)
+	}
+	if len(resp.PlatformsTimeout) != 1 || resp.PlatformsTimeout[0] != "reddit" {
+		t.Errorf("PlatformsTimeout = %v, want [reddit]", resp.PlatformsTimeout)
+	}
+	if resp.TotalCount != 3 {
+		t.Errorf("TotalCount = %d, want 3", resp.TotalCount)
+	}
+}
+
+func TestSearchPagesThroughPlatforms(t *testing.T) {
+	github := testutil.NewGitHub(t)
+	github.Set(testutil.Behavior{Results: 5})
+	reddit := testutil.NewReddit(t)
+	reddit.Set(testutil.Behavior{Results: 3})
+
+	h := newTestHandler(
+		fetchers.NewGitHubFetcher(nil, fetchers.NewEndpointPool(github.URL(), fetchers.SelectPriority), http.DefaultClient),
+		fetchers.NewRedditFetcher("", "", "test-agent/1.0", reddit.URL(), "", reddit.Client()),
+	)
+
+	// GitHub has five results and Reddit three, two per page: the last page
+	// only needs GitHub
+	req := &pb.SearchRequest{Query: "go", MaxResults: 2}
+	var pages [][]string
+	for len(pages) < 5 {
+		resp, err := h.Search(context.Background(), req)
+		if err != nil {
+			t.Fatalf("page %d: Search() error = %v", len(pages)+1, err)
+		}
+		var urls []string
+		for _, result := range resp.Results {
+			urls = append(urls, result.Url)
+		}
+		slices.Sort(urls)
+		pages = append(pages, urls)
+
+		if resp.NextPageToken == "" {
+			break
+		}
+		req = proto.Clone(req).(*pb.SearchRequest)
+		req.PageToken = resp.NextPageToken
+	}
+
+	want := [][]string{
+		{"https://github.com/fake/repo-1", "https://github.com/fake/repo-2",
+			"https://www.reddit.com/r/golang/comments/fake1", "https://www.reddit.com/r/golang/comments/fake2"},
+		{"https://github.com/fake/repo-3", "https://github.com/fake/repo-4", "https://www.reddit.com/r/golang/comments/fake3"},
+		{"https://github.com/fake/repo-5"},
+	}
+	if !slices.EqualFunc(pages, want, slices.Equal[[]string]) {
+		t.Errorf("pages = %v, want %v", pages, want)
+	}
+	if got := len(reddit.Requests()); got != 2 {
+		t.Errorf("reddit got %d requests, want 2: it had no third page", got)
+	}
+}
+
+func TestSearchIndexesResultsUntouchedByMerging(t *testing.T) {
+	repo := models.NewSearchResult("github", "golang/go", "The Go language", "https://github.com/golang/go")
+	repo.Metadata = map[string]string{"stars": "120000"}
+	github := newStubFetcher("github", 0)
+	github.results = []*models.SearchResult{repo}
+	post := models.NewSearchResult("reddit", "Go is open source", "", "https://www.reddit.com/r/golang/comments/abc/")
+	post.Metadata = map[string]string{"link_url": "https://m.github.com/golang/go/"}
+	reddit := newStubFetcher("reddit", 10*time.Millisecond)
+	reddit.results = []*models.SearchResult{post}
+
+	h := newTestHandler(github, reddit)
+	idx, err := index.Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+	h.index = idx
+
+	// The indexer reads the fetched results in the background while the
+	// pipeline merges the duplicates; under -race this would report any
+	// map the two share
+	response, err := h.Search(context.Background(), &pb.SearchRequest{Query: "go", Platforms: []string{"github", "reddit"}})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if err := h.Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(response.Results) != 1 || response.Results[0].Metadata[alsoOnKey] != "reddit" {
+		t.Fatalf("results = %v, want the repository merged with the post", response.Results)
+	}
+	if _, ok := repo.Metadata[alsoOnKey]; ok {
+		t.Errorf("fetched metadata = %v, want it left as fetched", repo.Metadata)
+	}
+	if post.Metadata["link_url"] != "https://m.github.com/golang/go/" {
+		t.Errorf("fetched link_url = %q, want it left as fetched", post.Metadata["link_url"])
+	}
+	indexed, err := idx.Search(context.Background(), "go", "github", 10)
+	if err != nil || len(indexed) != 1 {
+		t.Errorf("indexed github results = %v, %v, want the repository", indexed, err)
+	}
+}
+
+func TestSearchRejectsCorruptCursorToken(t *testing.T) {
+	h := newTestHandler(newStubFetcher("a", 0))
+
+	for _, token := range []string{cursorTokenPrefix + "!!!", cursorTokenPrefix + "e30"} {
+		_, err := h.Search(context.Background(), &pb.SearchRequest{Query: "go", PageToken: token})
+		if !errors.Is(err, ErrInvalidPageToken) {
+			t.Errorf("PageToken %q: error = %v, want ErrInvalidPageToken", token, err)
+		}
+	}
+}
+
+func TestSearchMergesDuplicatesAcrossPlatforms(t *testing.T) {
+	repo := models.NewSearchResult("github", "golang/go", "The Go language", "https://github.com/golang/go")
+	repo.Metadata = map[string]string{"stars": "120000"}
+	github := newStubFetcher("github", 0)
+	github.results = []*models.SearchResult{repo}
+
+	// A link post about the repository, a mobile link to it, and a post
+	// about something else
+	post := models.NewSearchResult("reddit", "Go is open source", "", "https://www.reddit.com/r/golang/comments/abc/")
+	post.Metadata = map[string]string{"score": "42", "link_url": "https://m.github.com/golang/go/?utm_source=reddit"}
+	other := models.NewSearchResult("reddit", "Other", "", "https://www.reddit.com/r/golang/comments/def/")
+	reddit := newStubFetcher("reddit", 10*time.Millisecond)
+	reddit.results = []*models.SearchResult{post, other}
+
+	story := models.NewSearchResult("hackernews", "Go", "", "https://github.com/golang/go/")
+	story.Metadata = map[string]string{"points": "300"}
+	hackerNews := newStubFetcher("hackernews", 20*time.Millisecond)
+	hackerNews.results = []*models.SearchResult{story}
+
+	resp, err := newTestHandler(github, reddit, hackerNews).Search(context.Background(), &pb.SearchRequest{Query: "go"})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+
+	if len(resp.Results) != 2 {
+		t.Fatalf("results = %v, want the repository and the unrelated post", resp.Results)
+	}
+	merged := resp.Results[0]
+	want := map[string]string{
+		"stars":             "120000",
+		"also_on":           "reddit,hackernews",
+		"reddit.url":        "https://www.reddit.com/r/golang/comments/abc",
+		"reddit.score":      "42",
+		"hackernews.url":    "https://github.com/golang/go",
+		"hackernews.points": "300",
+	}
+	for key, value := range want {
+		if merged.Metadata[key] != value {
+			t.Errorf("metadata[%q] = %q, want %q", key, merged.Metadata[key], value)
+		}
+	}
+	if merged.Platform != "github" || merged.Url != "https://github.com/golang/go" {
+		t.Errorf("kept %s %s, want the GitHub result", merged.Platform, merged.Url)
+	}
+}
+
+func TestSearchTracesPlatformFetches(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	github := testutil.NewGitHub(t)
+	github.Set(testutil.Behavior{StatusCode: http.StatusBadGateway})
+	client, err := httpclient.New(httpclient.DefaultOptions(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fetcher := fetchers.NewRetryFetcher(
+		fetchers.NewGitHubFetcher(nil, fetchers.NewEndpointPool(github.URL(), fetchers.SelectPriority), client),
+		fetchers.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond},
+	)
+
+	if _, err := newTestHandler(fetcher).Search(context.Background(), &pb.SearchRequest{Query: "go"}); err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+
+	var fetch sdktrace.ReadOnlySpan
+	var requests []sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		switch span.Name() {
+		case "fetch github":
+			fetch = span
+		case "HTTP GET":
+			requests = append(requests, span)
+		}
+	}
+	if fetch == nil {
+		t.Fatal("no fetch span recorded")
+	}
+	attrs := attribute.NewSet(fetch.Attributes()...)
+	if retries, _ := attrs.Value("search.retries"); retries.AsInt64() != 1 || fetch.Status().Code != codes.Error {
+		t.Errorf("fetch span retries = %v, status = %v; want 1 retry and an error", retries.AsInt64(), fetch.Status())
+	}
+
+	if len(requests) != 2 {
+		t.Fatalf("recorded %d HTTP spans, want one per attempt", len(requests))
+	}
+	for _, request := range requests {
+		attrs := attribute.NewSet(request.Attributes()...)
+		status, _ := attrs.Value("http.response.status_code")
+		url, _ := attrs.Value("url.full")
+		if request.Parent().SpanID() != fetch.SpanContext().SpanID() || status.AsInt64() != http.StatusBadGateway ||
+			!strings.HasPrefix(url.AsString(), github.URL()) {
+			t.Errorf("HTTP span = parent %v, status %v, url %q; want a 502 from %s under the fetch span",
+				request.Parent().SpanID(), status.AsInt64(), url.AsString(), github.URL())
+		}
+	}
+}
+
+func TestSearchDeterministicGolden(t *testing.T) {
+	// Platforms answer in the reverse of their sorted order, and the result
+	// cap means whoever is merged last gets dropped
+	a := newStubFetcher("a", 30*time.Millisecond)
+	b := newStubFetcher("b", 15*time.Millisecond)
+	c := newStubFetcher("c", 0)
+	d := newStubFetcher("d", 0)
+	d.err = errors.New("upstream unavailable")
+
+	h := newTestHandler(a, b, c, d)
+	h.config.Limits.MaxTotalResults = 2
+	h.makeDeterministic(42)
+
+	var first []byte
+	for run := 0; run < 3; run++ {
+		resp, err := h.Search(context.Background(), &pb.SearchRequest{Query: "go"})
+		if err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+
+		got := marshalGolden(t, resp)
+		if first == nil {
+			first = got
+		} else if !bytes.Equal(got, first) {
+			t.Fatalf("run %d differs from run 0:\n%s\nvs\n%s", run, got, first)
+		}
+	}
+
+	golden := filepath.Join("testdata", "search_deterministic.golden.json")
+	if *update {
+		if err := os.WriteFile(golden, first, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("reading golden file (run with -update to create it): %v", err)
+	}
+	if !bytes.Equal(first, want) {
+		t.Errorf("response does not match %s:\n%s", golden, first)
+	}
+}
+
+// limitedStub is a stubFetcher whose upstream caps query length
+type limitedStub struct {
+	*stubFetcher
+	maxLen int
+}
+
+func (f limitedStub) MaxQueryLength() int {
+	return f.maxLen
+}
+
+func TestSearchStreamSendsPlatformsAsTheyFinish(t *testing.T) {
+	fast := newStubFetcher("fast", 0)
+	slow := newStubFetcher("slow", 50*time.Millisecond)
+	failing := newStubFetcher("failing", 0)
+	failing.err = errors.New("boom")
+	h := newTestHandler(fast, slow, failing)
+
+	var messages []*pb.SearchStreamResponse
+	err := h.SearchStream(context.Background(), &pb.SearchRequest{Query: "go", Platforms: platformNames(fast, slow, failing)},
+		func(msg *pb.SearchStreamResponse) error {
+			messages = append(messages, msg)
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("SearchStream() error = %v", err)
+	}
+
+	if len(messages) != 3 {
+		t.Fatalf("got %d messages, want one per successful platform and the final response", len(messages))
+	}
+	if messages[0].Platform != "fast" || messages[1].Platform != "slow" || len(messages[1].Results) != 1 {
+		t.Errorf("streamed %q then %q, want fast then slow with their results", messages[0].Platform, messages[1].Platform)
+	}
+	if messages[1].DurationMs < 50 {
+		t.Errorf("slow DurationMs = %d, want at least 50", messages[1].DurationMs)
+	}
+	final := messages[2]
+	if final.Platform != "" || final.Response == nil || final.Response.TotalCount != 2 ||
+		!slices.Equal(final.Response.PlatformsError, []string{"failing"}) {
+		t.Errorf("final message = %v, want the complete response", final)
+	}
+}
+
+func TestSearchStreamStopsSendingAfterError(t *testing.T) {
+	a := newStubFetcher("a", 0)
+	b := newStubFetcher("b", 20*time.Millisecond)
+	h := newTestHandler(a, b)
+
+	sent := 0
+	sendErr := errors.New("client gone")
+	err := h.SearchStream(context.Background(), &pb.SearchRequest{Query: "go", Platforms: platformNames(a, b)},
+		func(*pb.SearchStreamResponse) error {
+			sent++
+			return sendErr
+		})
+	if !errors.Is(err, sendErr) || sent != 1 {
+		t.Errorf("SearchStream() = %v after %d sends, want the send error after one", err, sent)
+	}
+}
+
+func TestSearchServesIdenticalRequestsFromCache(t *testing.T) {
+	f := newStubFetcher("a", 0)
+	h := newTestHandler(f)
+	h.respCache = cache.NewLocalCache(10, time.Minute)
+
+	req := &pb.SearchRequest{Query: "go", Platforms: []string{"a"}}
+	first, err := h.Search(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	f.err = errors.New("upstream called again")
+	second, err := h.Search(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+
+	if first.Metadata.CacheHit || !second.Metadata.CacheHit || second.TotalCount != 1 {
+		t.Errorf("cache_hit = %t then %t with %d results, want a miss then a hit with the same result",
+			first.Metadata.CacheHit, second.Metadata.CacheHit, second.TotalCount)
+	}
+	if !second.PlatformStatuses[0].ServedFromCache {
+		t.Errorf("status = %v, want served from cache", second.PlatformStatuses[0])
+	}
+
+	// Other parameters miss
+	other, err := h.Search(context.Background(), &pb.SearchRequest{Query: "go", Platforms: []string{"a"}, MaxResults: 3})
+	if err != nil || other.Metadata.CacheHit {
+		t.Errorf("Search(max_results=3) = %v, %v, want a miss", other, err)
+	}
+}
+
+func TestSearchReturnsEachCallsRequestID(t *testing.T) {
+	h := newTestHandler(newStubFetcher("a", 0))
+	h.respCache = cache.NewLocalCache(10, time.Minute)
+
+	req := &pb.SearchRequest{Query: "go", Platforms: []string{"a"}}
+	for _, id := range []string{"first", "second"} {
+		response, err := h.Search(requestid.NewContext(context.Background(), id), req)
+		if err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+		// The second call is a cache hit, which must not echo the first ID
+		if response.Metadata.RequestId != id {
+			t.Errorf("request_id = %q, want %q", response.Metadata.RequestId, id)
+		}
+	}
+}
+
+func TestSearchShortensOverlongQueries(t *testing.T) {
+	h := newTestHandler(limitedStub{newStubFetcher("short", 0), 10}, newStubFetcher("long", 0))
+
+	resp, err := h.Search(context.Background(), &pb.SearchRequest{Query: "alpha beta gamma"})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+
+	want := map[string]string{"short": "alpha beta"}
+	if got := resp.Metadata.ShortenedQueries; len(got) != 1 || got["short"] != want["short"] {
+		t.Errorf("ShortenedQueries = %v, want %v", got, want)
+	}
+	for _, result := range resp.Results {
+		// The stub echoes the query it received as the title
+		if result.Platform == "short" && result.Title != "alpha beta" {
+			t.Errorf("short platform received %q, want the shortened query", result.Title)
+		}
+		if result.Platform == "long" && result.Title != "alpha beta gamma" {
+			t.Errorf("long platform received %q, want the full query", result.Title)
+		}
+	}
+}
+
+func TestSearchCutsSnippetsToRequestedLength(t *testing.T) {
+	h := newTestHandler(newStubFetcher("a", 0))
+	h.config.Limits.DefaultSnippetLength = 6
+
+	for maxLength, want := range map[int32]string{0: "sni...", 4: "s...", 100: "snippet"} {
+		resp, err := h.Search(context.Background(), &pb.SearchRequest{Query: "go", MaxSnippetLength: maxLength})
+		if err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+		if got := resp.Results[0].Snippet; got != want {
+			t.Errorf("max_snippet_length=%d: Snippet = %q, want %q", maxLength, got, want)
+		}
+	}
+}
+
+func TestSearchSanitizesResults(t *testing.T) {
+	f := newStubFetcher("a", 0)
+	f.results = []*models.SearchResult{
+		models.NewSearchResult("a", "Why &quot;nil&quot;?", ":rocket: Fast\n\n  and   small &amp; simple", "https://example.com/1"),
+	}
+	h := newTestHandler(f)
+	h.config.Limits.MaxSnippetLength = 12
+
+	resp, err := h.Search(context.Background(), &pb.SearchRequest{Query: "go", MaxSnippetLength: 100})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if got := resp.Results[0].Title; got != `Why "nil"?` {
+		t.Errorf("Title = %q, want entities decoded", got)
+	}
+	// MAX_SNIPPET_LENGTH wins over the request's longer limit
+	if got, want := resp.Results[0].Snippet, "🚀 Fast an..."; got != want {
+		t.Errorf("Snippet = %q, want %q", got, want)
+	}
+}
+
+func TestQualityPresetsOnlyJudgeTheirPlatform(t *testing.T) {
+	filter := newQualityFilter(&pb.SearchRequest{AnsweredOnly: true, MinStars: 100, MinUpvotes: 10})
+
+	for _, tc := range []struct {
+		result *pb.Result
+		want   bool
+	}{
+		{&pb.Result{Platform: "stackoverflow", Metadata: map[string]string{"is_answered": "true"}}, true},
+		{&pb.Result{Platform: "stackoverflow", Metadata: map[string]string{"is_answered": "false"}}, false},
+		{&pb.Result{Platform: "serverfault", Metadata: map[string]string{"is_answered": "false"}}, false},
+		{&pb.Result{Platform: "github", Metadata: map[string]string{"stars": "100"}}, true},
+		{&pb.Result{Platform: "github", Metadata: map[string]string{"stars": "99"}}, false},
+		{&pb.Result{Platform: "reddit", Metadata: map[string]string{"score": "-3"}}, false},
+		{&pb.Result{Platform: "reddit", Metadata: map[string]string{"score": "12"}}, true},
+		{&pb.Result{Platform: "custom"}, true},
+	} {
+		if got := filter.Keep(tc.result); got != tc.want {
+			t.Errorf("Keep(%v) = %v, want %v", tc.result, got, tc.want)
+		}
+	}
+
+	if newQualityFilter(&pb.SearchRequest{}) != nil {
+		t.Error("newQualityFilter() without presets is not nil")
+	}
+}
+
+func TestTimeRangeKeepsResultsCreatedWithin(t *testing.T) {
+	filter := newTimeRange(&pb.SearchRequest{Since: 1000, Until: 2000})
+
+	for createdAt, want := range map[int64]bool{999: false, 1000: true, 1999: true, 2000: false, 0: true} {
+		if got := filter.Keep(&pb.Result{CreatedAt: createdAt}); got != want {
+			t.Errorf("Keep(created_at=%d) = %v, want %v", createdAt, got, want)
+		}
+	}
+
+	if newTimeRange(&pb.SearchRequest{}) != nil {
+		t.Error("newTimeRange() without bounds is not nil")
+	}
+}
+
+func TestLanguagePreferenceRanksAndDrops(t *testing.T) {
+	english := &pb.Result{Title: "How to read a file line by line", Snippet: "The easiest way is to use a scanner from the standard library and loop over each line until the end of the file."}
+	portuguese := &pb.Result{Title: "Como ler um arquivo linha por linha", Snippet: "A maneira mais simples é usar um scanner da biblioteca padrão e percorrer cada linha até o final do arquivo."}
+	unknown := &pb.Result{Title: "go"}
+
+	for _, tc := range []struct {
+		only bool
+		want []*pb.Result
+	}{
+		{false, []*pb.Result{portuguese, unknown, english}},
+		{true, []*pb.Result{portuguese, unknown}},
+	} {
+		pref, err := newLanguagePreference(&pb.SearchRequest{AcceptLanguage: "pt-BR, pt;q=0.9", AcceptLanguageOnly: tc.only})
+		if err != nil {
+			t.Fatalf("newLanguagePreference() error = %v", err)
+		}
+		results := languageStage(pref)("a", []*pb.Result{english, unknown, portuguese})
+		pref.sort(results)
+
+		if !slices.Equal(results, tc.want) {
+			t.Errorf("only=%v: results = %v, want %v", tc.only, results, tc.want)
+		}
+	}
+
+	if got := portuguese.Metadata[contentLanguageKey]; got != "pt" {
+		t.Errorf("content_language = %q, want pt", got)
+	}
+	if _, err := newLanguagePreference(&pb.SearchRequest{AcceptLanguage: "en;q=bogus"}); err == nil {
+		t.Error("newLanguagePreference() accepted a malformed header")
+	}
+}
+
+func TestSearchAppliesMaxResultsPerPlatform(t *testing.T) {
+	a, b := newStubFetcher("a", 0), newStubFetcher("b", 0)
+	for _, f := range []*stubFetcher{a, b} {
+		for i := range 5 {
+			f.results = append(f.results, models.NewSearchResult(f.name, "go", "snippet", fmt.Sprintf("https://example.com/%s/%d", f.name, i)))
+		}
+	}
+	h := newTestHandler(a, b)
+
+	response, err := h.Search(context.Background(), &pb.SearchRequest{
+		Query: "go", Platforms: platformNames(a, b), MaxResults: 4,
+		MaxResultsPerPlatform: map[string]int32{"a": 2},
+	})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	counts := make(map[string]int)
+	for _, result := range response.Results {
+		counts[result.Platform]++
+	}
+	if counts["a"] != 2 || counts["b"] != 4 {
+		t.Errorf("results by platform = %v, want a's override and b at max_results", counts)
+	}
+}
+
+func TestInterleaveWeightsBlendRoundByRound(t *testing.T) {
+	var results []*pb.Result
+	for _, platform := range []string{"a", "a", "a", "a", "b", "b", "c"} {
+		results = append(results, &pb.Result{Platform: platform})
+	}
+
+	blended := interleaveWeights{"a": 3}.apply(results)
+	var got []string
+	for _, result := range blended {
+		got = append(got, result.Platform)
+	}
+	if want := []string{"a", "a", "a", "b", "c", "a", "b"}; !slices.Equal(got, want) {
+		t.Errorf("apply() = %v, want %v", got, want)
+	}
+
+	if kept := interleaveWeights(nil).apply(results); &kept[0] != &results[0] {
+		t.Error("apply() without weights reordered results")
+	}
+}
+
+func TestPipelineRanksByRelevance(t *testing.T) {
+	batch := func(platform string, urls ...string) []*pb.Result {
+		results := make([]*pb.Result, len(urls))
+		for i, url := range urls {
+			results[i] = &pb.Result{Platform: platform, Url: "https://example.com/" + url}
+		}
+		return results
+	}
+	budget := newResultBudget(config.LimitsConfig{MaxTotalResults: 4})
+	p := newMergePipeline(budget, resultLimits{fallback: 10}, 500, nil, nil)
+	p.pushProto("a", batch("a", "a1", "a2", "shared"))
+	p.pushProto("b", batch("b", "b1", "shared", "b3"))
+
+	var got []string
+	for _, result := range p.ranked(ranking.NewReciprocalRankFusion(ranking.DefaultK)) {
+		got = append(got, result.Platform+":"+strings.TrimPrefix(result.Url, "https://example.com/"))
+	}
+	// The duplicate ranks first as the copy that was admitted; b3 was cut
+	// by the budget
+	if want := []string{"a:shared", "a:a1", "b:b1", "a:a2"}; !slices.Equal(got, want) {
+		t.Errorf("ranked() = %v, want %v", got, want)
+	}
+}
+
+func TestPipelineDedupesOnlyAgainstAdmittedResults(t *testing.T) {
+	result := func(platform, url string) *pb.Result {
+		return &pb.Result{Platform: platform, Url: "https://example.com/" + url}
+	}
+	budget := newResultBudget(config.LimitsConfig{MaxTotalResults: 2})
+	p := newMergePipeline(budget, resultLimits{fallback: 10}, 500, nil, nil)
+	p.pushProto("a", []*pb.Result{result("a", "a1"), result("a", "shared")})
+	p.pushProto("b", []*pb.Result{result("b", "b1"), result("b", "shared")})
+
+	merged := p.merged()
+	if len(merged) != 2 || merged[1].Metadata[alsoOnKey] != "b" {
+		t.Fatalf("merged() = %v, want a's two results with shared also on b", merged)
+	}
+	// b1 was cut by the budget, so it doesn't stand for its page
+	if first := p.seen.duplicateOf(result("c", "b1")); first != nil {
+		t.Errorf("a result cut by the budget claimed its page: %v", first)
+	}
+	if budget.resultsDropped != 1 {
+		t.Errorf("dropped %d results, want only b1", budget.resultsDropped)
+	}
+}
+
+func TestPlatformStatusExplainsFailures(t *testing.T) {
+	for _, tc := range []struct {
+		err        error
+		timedOut   bool
+		state      pb.PlatformState
+		code       string
+		httpStatus int32
+		retryAfter int32
+	}{
+		{nil, false, pb.PlatformState_PLATFORM_STATE_OK, "", 0, 0},
+		{context.DeadlineExceeded, true, pb.PlatformState_PLATFORM_STATE_TIMEOUT, "timeout", 0, 0},
+		{&fetchers.StatusError{StatusCode: http.StatusTooManyRequests, RetryAfter: 1500 * time.Millisecond},
+			false, pb.PlatformState_PLATFORM_STATE_ERROR, "rate_limited", 429, 2},
+		{&fetchers.StatusError{StatusCode: http.StatusUnauthorized}, false, pb.PlatformState_PLATFORM_STATE_ERROR, "unauthorized", 401, 0},
+		{fmt.Errorf("wrapped: %w", &fetchers.StatusError{StatusCode: http.StatusBadGateway}),
+			false, pb.PlatformState_PLATFORM_STATE_ERROR, "upstream_unavailable", 502, 0},
+		{&ratelimit.LimitedError{Platform: "a", RetryAfter: 30 * time.Second}, false, pb.PlatformState_PLATFORM_STATE_SKIPPED, "rate_limited", 0, 30},
+		{fmt.Errorf("github: %w", &fetchers.CircuitOpenError{Platform: "a", RetryAfter: 10 * time.Second}),
+			false, pb.PlatformState_PLATFORM_STATE_SKIPPED, "circuit_open", 0, 10},
+		{fmt.Errorf("a: %w", workpool.ErrOverloaded), false, pb.PlatformState_PLATFORM_STATE_SKIPPED, "overloaded", 0, 0},
+		{fmt.Errorf("%w: %w", workpool.ErrQueueTimeout, context.DeadlineExceeded),
+			true, pb.PlatformState_PLATFORM_STATE_TIMEOUT, "queue_timeout", 0, 0},
+		{errors.New("boom"), false, pb.PlatformState_PLATFORM_STATE_ERROR, "internal", 0, 0},
+	} {
+		got := platformStatus(&models.FetchResult{Platform: "a", Error: tc.err, TimedOut: tc.timedOut})
+		if got.State != tc.state || got.ErrorCode != tc.code || got.HttpStatus != tc.httpStatus || got.RetryAfterSeconds != tc.retryAfter {
+			t.Errorf("platformStatus(%v) = %v, want state=%v code=%q http=%d retry=%d",
+				tc.err, got, tc.state, tc.code, tc.httpStatus, tc.retryAfter)
+		}
+	}
+}
+
+func TestSearchFallsBackToIndexAfterClientDeadline(t *testing.T) {
+	idx, err := index.Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer idx.Close()
+	if err := idx.Add([]*models.SearchResult{models.NewSearchResult("github", "golang/go", "The Go language", "https://github.com/golang/go")}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The client's deadline has passed by the time github times out, so
+	// both the index hits and ctx are ready; the hits must win every time
+	for i := range 10 {
+		h := newTestHandler(newStubFetcher("github", time.Minute))
+		h.index = idx
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		response, err := h.Search(ctx, &pb.SearchRequest{Query: "golang", Platforms: []string{"github"}})
+		cancel()
+		if err != nil {
+			t.Fatalf("search %d: Search() error = %v", i, err)
+		}
+		if len(response.Results) != 1 || response.Results[0].Url != "https://github.com/golang/go" {
+			t.Fatalf("search %d: results = %v, want the indexed github result", i, response.Results)
+		}
+	}
+}
+
+func TestMarkServedFromIndexDatesByOldestHit(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	statuses := []*pb.PlatformStatus{{Platform: "a"}, {Platform: "b"}, {Platform: "c"}}
+	markServedFromIndex(statuses, "a", []*models.SearchResult{
+		{FetchedAt: now.Add(-time.Hour).Unix()},
+		{FetchedAt: now.Add(-2 * time.Hour).Unix()},
+		{},
+	}, now)
+	markServedFromIndex(statuses, "c", []*models.SearchResult{{}}, now)
+
+	if a := statuses[0]; !a.ServedFromCache || a.DataAsOf != now.Add(-2*time.Hour).Unix() || a.CacheAgeMs != (2*time.Hour).Milliseconds() {
+		t.Errorf("status a = %v, want served from cache as of two hours ago", a)
+	}
+	if b := statuses[1]; b.ServedFromCache {
+		t.Errorf("status b = %v, want untouched", b)
+	}
+	if c := statuses[2]; !c.ServedFromCache || c.DataAsOf != 0 || c.CacheAgeMs != 0 {
+		t.Errorf("status c = %v, want served from cache with unknown age", c)
+	}
+}
+
+func TestUpdateCredentialsSwapsOnlyValidatedCredentials(t *testing.T) {
+	var searchedWith []string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token == "revoked" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path == "/search/repositories" {
+			searchedWith = append(searchedWith, token)
+		}
+		w.Write([]byte(`{"items":[]}`))
+	}))
+	defer upstream.Close()
+
+	pool := fetchers.NewCredentialPool("old", false)
+	h := newTestHandler(fetchers.NewGitHubFetcher(pool, fetchers.NewEndpointPool(upstream.URL, fetchers.SelectPriority), upstream.Client()))
+	h.credentials = map[string]credentialSet{"github": pool}
+
+	var rejected *CredentialError
+	if err := h.UpdateCredentials(context.Background(), "github", []string{"fresh", "revoked"}, false); !errors.As(err, &rejected) || rejected.Index != 1 {
+		t.Fatalf("UpdateCredentials() error = %v, want credential 1 rejected", err)
+	}
+	if err := h.UpdateCredentials(context.Background(), "github", []string{"fresh"}, false); err != nil {
+		t.Fatalf("UpdateCredentials() error = %v", err)
+	}
+	if err := h.UpdateCredentials(context.Background(), "devto", []string{"x"}, true); !errors.Is(err, ErrCredentialsNotRotatable) {
+		t.Errorf("UpdateCredentials(devto) error = %v, want ErrCredentialsNotRotatable", err)
+	}
+
+	if _, err := h.Search(context.Background(), &pb.SearchRequest{Query: "go"}); err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if !slices.Equal(searchedWith, []string{"fresh"}) {
+		t.Errorf("searched with %q, want only the rotated-in token", searchedWith)
+	}
+}
+
+func TestUpdateCredentialsRotatesRedditClient(t *testing.T) {
+	var searchedWith []string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/access_token" {
+			id, secret, _ := r.BasicAuth()
+			switch id + ":" + secret {
+			case "old-id:old-secret":
+				w.Write([]byte(`{"access_token": "old-token", "expires_in": 3600}`))
+			case "new-id:new-secret":
+				w.Write([]byte(`{"access_token": "new-token", "expires_in": 3600}`))
+			default:
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+			return
+		}
+		searchedWith = append(searchedWith, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+		w.Write([]byte(`{"data": {"children": []}}`))
+	}))
+	defer upstream.Close()
+
+	reddit := fetchers.NewRedditFetcher("old-id", "old-secret", "test", upstream.URL, "", upstream.Client())
+	h := newTestHandler(reddit)
+	h.credentials = map[string]credentialSet{"reddit": reddit}
+	search := func() {
+		t.Helper()
+		if _, err := h.Search(context.Background(), &pb.SearchRequest{Query: "go", Platforms: []string{"reddit"}}); err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+	}
+
+	search()
+	var rejected *CredentialError
+	if err := h.UpdateCredentials(context.Background(), "reddit", []string{"bad-id:bad-secret"}, false); !errors.As(err, &rejected) {
+		t.Fatalf("UpdateCredentials(bad pair) error = %v, want it rejected", err)
+	}
+	search()
+	if err := h.UpdateCredentials(context.Background(), "reddit", []string{"new-id:new-secret"}, false); err != nil {
+		t.Fatalf("UpdateCredentials() error = %v", err)
+	}
+	// The token cached for the old pair is dropped with it
+	search()
+
+	if want := []string{"old-token", "old-token", "new-token"}; !slices.Equal(searchedWith, want) {
+		t.Errorf("searched with %q, want %q", searchedWith, want)
+	}
+	if !h.hasCredentials("reddit") {
+		t.Error("hasCredentials(reddit) = false after rotation")
+	}
+	if err := h.UpdateCredentials(context.Background(), "reddit", nil, true); err != nil || h.hasCredentials("reddit") {
+		t.Errorf("UpdateCredentials(none) error = %v, hasCredentials = %t; want only the public API left", err, h.hasCredentials("reddit"))
+	}
+}
+
+func TestUpdateCredentialsReportsOnlyRefusalsAsRejected(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		status   int
+		down     bool
+		rejected bool
+	}{
+		{name: "unauthorized", status: http.StatusUnauthorized, rejected: true},
+		{name: "forbidden", status: http.StatusForbidden, rejected: true},
+		{name: "server error", status: http.StatusBadGateway},
+		{name: "unreachable", down: true},
+	} {
+		upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tc.status)
+		}))
+		if tc.down {
+			upstream.Close()
+		}
+
+		pool := fetchers.NewCredentialPool("old", false)
+		h := newTestHandler(fetchers.NewGitHubFetcher(pool, fetchers.NewEndpointPool(upstream.URL, fetchers.SelectPriority), upstream.Client()))
+		h.credentials = map[string]credentialSet{"github": pool}
+
+		err := h.UpdateCredentials(context.Background(), "github", []string{"fresh"}, false)
+		var rejected *CredentialError
+		if err == nil || errors.As(err, &rejected) != tc.rejected {
+			t.Errorf("%s: UpdateCredentials() error = %v, want rejected=%t", tc.name, err, tc.rejected)
+		}
+		upstream.Close()
+	}
+}
+
+// stubSummarizer summarizes instantly, except for texts it has been told to
+// hang on until the summarization timeout
+type stubSummarizer struct {
+	hang string
+}
+
+func (s stubSummarizer) Summarize(ctx context.Context, text string) (string, error) {
+	if text == s.hang {
+		<-ctx.Done()
+		return "", ctx.Err()
+	}
+	return "tl;dr " + text, nil
+}
+
+func TestSearchSummarizesLongSnippets(t *testing.T) {
+	h := newTestHandler(newStubFetcher("a", 0))
+	h.summarizer = stubSummarizer{}
+	h.config.Summarize = config.SummarizeConfig{Timeout: time.Second, MinSnippetLength: 5}
+
+	resp, err := h.Search(context.Background(), &pb.SearchRequest{Query: "go", Summarize: true})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if got := resp.Results[0].Summary; got != "tl;dr snippet" {
+		t.Errorf("Summary = %q, want the summarized snippet", got)
+	}
+
+	resp, err = h.Search(context.Background(), &pb.SearchRequest{Query: "go"})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if got := resp.Results[0].Summary; got != "" {
+		t.Errorf("Summary = %q without summarize, want none", got)
+	}
+}
+
+func TestSearchSkipsSummariesAfterTimeout(t *testing.T) {
+	h := newTestHandler(newStubFetcher("a", 0))
+	h.summarizer = stubSummarizer{hang: "snippet"}
+	h.config.Summarize = config.SummarizeConfig{Timeout: 20 * time.Millisecond, MinSnippetLength: 5}
+
+	start := time.Now()
+	resp, err := h.Search(context.Background(), &pb.SearchRequest{Query: "go", Summarize: true})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Search() took %v, want it bounded by the summarization timeout", elapsed)
+	}
+	if got := resp.Results[0].Summary; got != "" || resp.Results[0].Snippet != "snippet" {
+		t.Errorf("result = %v, want the snippet kept and no summary", resp.Results[0])
+	}
+}
+
+// stubAnswerer cites the sources it was told to, or fails with err
+type stubAnswerer struct {
+	answer  string
+	err     error
+	sources []summarize.Source
+}
+
+func (a *stubAnswerer) Answer(ctx context.Context, question string, sources []summarize.Source) (string, error) {
+	a.sources = sources
+	return a.answer, a.err
+}
+
+func TestAnswerCitesTopResults(t *testing.T) {
+	h := newTestHandler(newStubFetcher("a", 0), newStubFetcher("b", 0), newStubFetcher("c", 0))
+	answerer := &stubAnswerer{answer: "Yes [2], see also [1] and [9]."}
+	h.answerer = answerer
+	h.config.Answer = config.AnswerConfig{Timeout: time.Second, DefaultSources: 2}
+
+	response, err := h.Search(context.Background(), &pb.SearchRequest{Query: "go"})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	answer, err := h.Answer(context.Background(), "go", 0, response)
+	if err != nil {
+		t.Fatalf("Answer() error = %v", err)
+	}
+
+	if len(answerer.sources) != 2 {
+		t.Fatalf("answerer got %d sources, want the default 2", len(answerer.sources))
+	}
+	if len(answer.Citations) != 2 || answer.Citations[0].Index != 2 || answer.Citations[1].Index != 1 {
+		t.Fatalf("Citations = %v, want [2] then [1], ignoring [9]", answer.Citations)
+	}
+	if got, want := answer.Citations[0].Url, response.Results[1].Url; got != want {
+		t.Errorf("citation [2] url = %q, want %q", got, want)
+	}
+	if answer.Search != response {
+		t.Errorf("Search = %v, want the underlying response", answer.Search)
+	}
+}
+
+func TestAnswerKeepsResultsWhenLLMFails(t *testing.T) {
+	h := newTestHandler(newStubFetcher("a", 0))
+	h.answerer = &stubAnswerer{err: errors.New("model overloaded")}
+	h.config.Answer = config.AnswerConfig{Timeout: time.Second, DefaultSources: 5}
+
+	response, err := h.Search(context.Background(), &pb.SearchRequest{Query: "go"})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	answer, err := h.Answer(context.Background(), "go", 0, response)
+	if err != nil {
+		t.Fatalf("Answer() error = %v", err)
+	}
+	if answer.Answer != "" || len(answer.Search.Results) != 1 {
+		t.Errorf("Answer() = %v, want the results without an answer", answer)
+	}
+}
+
+func TestListPlatformsIncludesDisplayMetadata(t *testing.T) {
+	h := newTestHandler(&stubFetcher{name: "reddit"}, &stubFetcher{name: "custom"})
+
+	platforms := h.ListPlatforms()
+	if len(platforms) != 2 {
+		t.Fatalf("len(platforms) = %d, want 2", len(platforms))
+	}
+
+	custom, reddit := platforms[0], platforms[1]
+	if reddit.Name != "reddit" || reddit.DisplayName != "Reddit" || reddit.BrandColor != "#FF4500" || reddit.IconUrl == "" {
+		t.Errorf("reddit = %v, want its display metadata", reddit)
+	}
+	if custom.Name != "custom" || custom.DisplayName != "custom" {
+		t.Errorf("custom = %v, want the name as display name", custom)
+	}
+}
+
+func TestListPlatformsDescribesCapabilities(t *testing.T) {
+	h := newTestHandler(&stubFetcher{name: "github"}, &stubFetcher{name: "reddit"})
+	h.credentials = map[string]credentialSet{"github": fetchers.NewCredentialPool("token", false)}
+	h.breakers = fetchers.NewCircuitBreakers(1, time.Minute)
+	h.fetchers.Use(h.breakers.Wrap)
+
+	github := newStubFetcher("github", 0)
+	github.err = &fetchers.StatusError{Platform: "github", StatusCode: http.StatusServiceUnavailable}
+	h.fetchers.RegisterFetcher(github)
+	if _, err := h.Search(context.Background(), &pb.SearchRequest{Query: "go", Platforms: []string{"github"}}); err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+
+	platforms := h.ListPlatforms()
+	gh, reddit := platforms[0], platforms[1]
+	if !gh.CredentialsConfigured || reddit.CredentialsConfigured {
+		t.Errorf("credentials configured = %v, %v; want only github's", gh.CredentialsConfigured, reddit.CredentialsConfigured)
+	}
+	if !slices.Contains(gh.SupportedFilters, "min_stars") || slices.Contains(reddit.SupportedFilters, "min_stars") ||
+		!slices.Contains(reddit.SupportedFilters, "subreddit:") {
+		t.Errorf("supported filters = %v, %v; want min_stars for github and subreddit: for reddit", gh.SupportedFilters, reddit.SupportedFilters)
+	}
+	if !slices.Contains(gh.QueryTerms, "stars") || !slices.Equal(reddit.QueryTerms, []string{"after", "before", "subreddit"}) {
+		t.Errorf("query terms = %v, %v; want stars for github and dates and subreddit for reddit", gh.QueryTerms, reddit.QueryTerms)
+	}
+	if gh.CircuitState != pb.CircuitState_CIRCUIT_STATE_OPEN || reddit.CircuitState != pb.CircuitState_CIRCUIT_STATE_CLOSED {
+		t.Errorf("circuit states = %v, %v; want github open after its failure", gh.CircuitState, reddit.CircuitState)
+	}
+	if gh.DefaultMaxResults != 10 {
+		t.Errorf("default max results = %d, want MAX_RESULTS_PER_PLATFORM", gh.DefaultMaxResults)
+	}
+}
+
+// marshalGolden renders resp as stable, indented JSON. protojson output is
+// deliberately unstable in whitespace, so it is normalized first.
+func marshalGolden(t *testing.T, resp *pb.SearchResponse) []byte {
+	t.Helper()
+
+	raw, err := protojson.Marshal(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var compact, indented bytes.Buffer
+	if err := json.Compact(&compact, raw); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Indent(&indented, compact.Bytes(), "", "  "); err != nil {
+		t.Fatal(err)
+	}
+	indented.WriteByte('\n')
+	return indented.Bytes()
+}
+
+// panickingFetcher panics on every fetch, like a fetcher with a bug
+type panickingFetcher struct{}
+
+func (panickingFetcher) Name() string {
+	return "buggy"
+}
+
+func (panickingFetcher) Fetch(context.Context, string, int) ([]*models.SearchResult, error) {
+	var result *models.SearchResult
+	return []*models.SearchResult{result}, errors.New(result.Title)
+}
+
+func TestSearchSurvivesPanickingFetcher(t *testing.T) {
+	fine := newStubFetcher("fine", 0)
+	h := newTestHandler(fine, panickingFetcher{})
+
+	response, err := h.Search(context.Background(), &pb.SearchRequest{Query: "go", Platforms: []string{"fine", "buggy"}})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if !slices.Equal(response.PlatformsSuccess, []string{"fine"}) || !slices.Equal(response.PlatformsError, []string{"buggy"}) {
+		t.Errorf("success = %v, errors = %v; want the panicking platform failed alone",
+			response.PlatformsSuccess, response.PlatformsError)
+	}
+	if len(response.Results) != 1 {
+		t.Errorf("got %d results, want the other platform's", len(response.Results))
+	}
+}
+
+func TestTruncateDetailsMarksTruncation(t *testing.T) {
+	details := &pb.ResultDetailsResponse{}
+	if got := truncateDetails(details, "short", 10); got != "short" || details.Truncated {
+		t.Errorf("truncateDetails(short) = %q, truncated=%v, want it unchanged", got, details.Truncated)
+	}
+	if got := truncateDetails(details, "a much longer README", 10); got != "a much..." || !details.Truncated {
+		t.Errorf("truncateDetails(long) = %q, truncated=%v, want it cut and marked", got, details.Truncated)
+	}
+	if got := truncateDetails(&pb.ResultDetailsResponse{}, "bad \xff byte", 100); got != "bad � byte" {
+		t.Errorf("truncateDetails(invalid UTF-8) = %q, want the byte replaced", got)
+	}
+}
+
+// recordingPublisher counts the events published before it was closed
+type recordingPublisher struct {
+	mu        sync.Mutex
+	published int
+	late      int
+	closed    bool
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, event *events.SearchEvent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		p.late++
+		return errors.New("publisher closed")
+	}
+	p.published++
+	return nil
+}
+
+func (p *recordingPublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	return nil
+}
+
+func TestCloseFlushesSinksAfterDrain(t *testing.T) {
+	h := newTestHandler(newStubFetcher("github", time.Millisecond))
+	publisher := &recordingPublisher{}
+	h.publisher = publisher
+	st, err := store.Open(context.Background(), "sqlite", filepath.Join(t.TempDir(), "searches.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.store = st
+	idx, err := index.Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.index = idx
+
+	if _, err := h.Search(context.Background(), &pb.SearchRequest{Query: "go", Platforms: []string{"github"}}); err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if err := h.Drain(context.Background()); err != nil {
+		t.Fatalf("Drain() error = %v", err)
+	}
+	h.Close()
+
+	if publisher.published != 1 || publisher.late != 0 || !publisher.closed {
+		t.Errorf("publisher published %d, %d after close, closed %t; want 1 event then closed",
+			publisher.published, publisher.late, publisher.closed)
+	}
+	if _, err := st.RecordSearch(context.Background(), &pb.SearchRequest{Query: "go"}, &pb.SearchResponse{}); err == nil {
+		t.Error("store still writable after Close")
+	}
+	if _, err := idx.Search(context.Background(), "go", "", 10); err == nil {
+		t.Error("index still searchable after Close")
+	}
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/farhapartex/search-proxy/internal/fetchers"
+	"github.com/farhapartex/search-proxy/internal/httpclient"
+	"github.com/farhapartex/search-proxy/internal/models"
+	"github.com/farhapartex/search-proxy/internal/ratelimit"
+	"github.com/farhapartex/search-proxy/internal/workpool"
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+// platformStatus describes how a finished fetch went
+func platformStatus(fetchResult *models.FetchResult) *pb.PlatformStatus {
+	status := &pb.PlatformStatus{
+		Platform:   fetchResult.Platform,
+		State:      pb.PlatformState_PLATFORM_STATE_OK,
+		DurationMs: int32(fetchResult.Duration.Milliseconds()),
+	}
+	if fetchResult.Error == nil {
+		return status
+	}
+	if fetchResult.TimedOut {
+		status.State = pb.PlatformState_PLATFORM_STATE_TIMEOUT
+		status.ErrorCode = "timeout"
+		if errors.Is(fetchResult.Error, workpool.ErrQueueTimeout) {
+			status.ErrorCode = "queue_timeout"
+		}
+		return status
+	}
+
+	status.State = pb.PlatformState_PLATFORM_STATE_ERROR
+	status.ErrorCode = "internal"
+
+	var statusErr *fetchers.StatusError
+	var limited *ratelimit.LimitedError
+	var open *fetchers.CircuitOpenError
+	switch {
+	case errors.As(fetchResult.Error, &limited):
+		status.State = pb.PlatformState_PLATFORM_STATE_SKIPPED
+		status.ErrorCode = "rate_limited"
+		status.RetryAfterSeconds = retryAfterSeconds(limited.RetryAfter)
+	case errors.Is(fetchResult.Error, workpool.ErrOverloaded):
+		status.State = pb.PlatformState_PLATFORM_STATE_SKIPPED
+		status.ErrorCode = "overloaded"
+	case errors.Is(fetchResult.Error, fetchers.ErrPlatformDisabled):
+		status.State = pb.PlatformState_PLATFORM_STATE_SKIPPED
+		status.ErrorCode = "disabled"
+	case errors.As(fetchResult.Error, &open):
+		status.State = pb.PlatformState_PLATFORM_STATE_SKIPPED
+		status.ErrorCode = "circuit_open"
+		status.RetryAfterSeconds = retryAfterSeconds(open.RetryAfter)
+	case errors.As(fetchResult.Error, &statusErr):
+		status.HttpStatus = int32(statusErr.StatusCode)
+		status.RetryAfterSeconds = retryAfterSeconds(statusErr.RetryAfter)
+		status.ErrorCode = statusErrorCode(statusErr)
+	case errors.Is(fetchResult.Error, httpclient.ErrBodyTooLarge):
+		status.ErrorCode = "response_too_large"
+	case errors.Is(fetchResult.Error, context.DeadlineExceeded):
+		status.ErrorCode = "timeout"
+	}
+	return status
+}
+
+// timedOutStatus describes a platform still in flight when the search
+// budget ran out
+func timedOutStatus(platform string, elapsed time.Duration) *pb.PlatformStatus {
+	return &pb.PlatformStatus{
+		Platform:   platform,
+		State:      pb.PlatformState_PLATFORM_STATE_TIMEOUT,
+		ErrorCode:  "timeout",
+		DurationMs: int32(elapsed.Milliseconds()),
+	}
+}
+
+// statusErrorCode classifies an upstream's non-200 answer
+func statusErrorCode(err *fetchers.StatusError) string {
+	switch {
+	case err.StatusCode == http.StatusTooManyRequests:
+		return "rate_limited"
+	// GitHub answers 403 once a rate limit is spent
+	case err.StatusCode == http.StatusForbidden && err.RetryAfter > 0:
+		return "rate_limited"
+	case err.StatusCode == http.StatusUnauthorized || err.StatusCode == http.StatusForbidden:
+		return "unauthorized"
+	case err.StatusCode >= 500:
+		return "upstream_unavailable"
+	}
+	return "upstream_error"
+}
+
+// retryAfterSeconds rounds a backoff up to whole seconds, so a client never
+// retries early
+func retryAfterSeconds(d time.Duration) int32 {
+	return int32((d + time.Second - 1) / time.Second)
+}
+
+// markServedFromIndex records on platform's status that its results came from
+// the local index, dated by the oldest hit; hits indexed before fetch times
+// were recorded leave the age unknown
+func markServedFromIndex(statuses []*pb.PlatformStatus, platform string, hits []*models.SearchResult, now time.Time) {
+	var oldest int64
+	for _, hit := range hits {
+		if hit.FetchedAt > 0 && (oldest == 0 || hit.FetchedAt < oldest) {
+			oldest = hit.FetchedAt
+		}
+	}
+	for _, status := range statuses {
+		if status.Platform != platform {
+			continue
+		}
+		status.ServedFromCache = true
+		if oldest > 0 {
+			status.DataAsOf = oldest
+			status.CacheAgeMs = now.Sub(time.Unix(oldest, 0)).Milliseconds()
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+// SearchStream runs the search like Search, handing each platform's merged
+// results to send as soon as they are ready and the complete response last.
+// Once send fails the remaining messages are dropped and its error returned.
+func (h *SearchHandler) SearchStream(ctx context.Context, req *pb.SearchRequest, send func(*pb.SearchStreamResponse) error) error {
+	var sendErr error
+	emit := func(platform string, results []*pb.Result, duration time.Duration) {
+		if sendErr != nil {
+			return
+		}
+		setAgeDays(results, h.now())
+		sendErr = send(&pb.SearchStreamResponse{
+			Platform:   platform,
+			Results:    results,
+			DurationMs: int32(duration.Milliseconds()),
+		})
+	}
+
+	response, err := h.search(ctx, req, emit)
+	if err != nil {
+		return err
+	}
+	if sendErr != nil {
+		return sendErr
+	}
+	return send(&pb.SearchStreamResponse{Response: response})
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"context"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/farhapartex/search-proxy/internal/logging"
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+// summarizeResults fills in Result.summary for long snippets. It runs after
+// the search budget has been spent, so it gets its own timeout; summaries not
+// ready by then are left out rather than delaying the response further.
+func (h *SearchHandler) summarizeResults(ctx context.Context, results []*pb.Result) {
+	cfg := h.config.Summarize
+
+	var long []*pb.Result
+	for _, result := range results {
+		if utf8.RuneCountInString(result.Snippet) >= cfg.MinSnippetLength {
+			long = append(long, result)
+		}
+	}
+	if len(long) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cfg.Timeout)
+	defer cancel()
+
+	summaries := make([]string, len(long))
+	errs := make([]error, len(long))
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for range max(cfg.Concurrency, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer logPanic(ctx, "summarizer")
+			for i := range work {
+				summaries[i], errs[i] = h.summarizer.Summarize(ctx, long[i].Snippet)
+			}
+		}()
+	}
+
+feed:
+	for i := range long {
+		select {
+		case work <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(work)
+	wg.Wait()
+
+	// Results are only touched once every worker is done with them
+	var summarized int
+	var lastErr error
+	for i, result := range long {
+		if errs[i] != nil {
+			lastErr = errs[i]
+			continue
+		}
+		if summaries[i] != "" {
+			result.Summary = summaries[i]
+			summarized++
+		}
+	}
+	if summarized < len(long) {
+		logging.Printf(ctx, "Summarized %d of %d long snippets (last error: %v)", summarized, len(long), lastErr)
+	}
+}
@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+// timeRange drops results created outside the request's since and until,
+// for platforms that can't filter by date themselves or only roughly
+type timeRange struct {
+	since, until int64
+}
+
+// newTimeRange returns the request's range, or nil if it sets none
+func newTimeRange(req *pb.SearchRequest) *timeRange {
+	if req.Since <= 0 && req.Until <= 0 {
+		return nil
+	}
+	return &timeRange{since: req.Since, until: req.Until}
+}
+
+// Keep reports whether result was created within the range. Results
+// without a creation time are kept, as there is nothing to judge them by.
+// A nil range keeps everything.
+func (r *timeRange) Keep(result *pb.Result) bool {
+	if r == nil || result.CreatedAt == 0 {
+		return true
+	}
+	if r.since > 0 && result.CreatedAt < r.since {
+		return false
+	}
+	return r.until <= 0 || result.CreatedAt < r.until
+}
@@ -0,0 +1,208 @@
+package httpapi
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+// maxRequestBytes bounds JSON request bodies; search requests are small
+const maxRequestBytes = 1 << 20
+
+var (
+	// Unknown request fields are rejected rather than silently ignored
+	unmarshalOptions = protojson.UnmarshalOptions{}
+	// Responses use the proto field names, like the JSON examples in the
+	// README, and include zero values so clients see every field
+	marshalOptions = protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true}
+)
+
+// search handles GET /v1/search, with the request fields as query
+// parameters, and POST /v1/search, with a JSON SearchRequest body
+func search(backend Backend) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &pb.SearchRequest{}
+		var err error
+		if r.Method == http.MethodGet {
+			err = fromQuery(r.URL.Query(), req)
+		} else {
+			err = fromBody(r, req)
+		}
+		if err != nil {
+			writeError(w, status.Error(codes.InvalidArgument, err.Error()))
+			return
+		}
+
+		response, err := backend.FederatedSearch(r.Context(), req)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeProto(w, http.StatusOK, response)
+	}
+}
+
+// health handles GET /v1/health with the gRPC health check's response
+func health(backend Backend) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response, err := backend.HealthCheck(r.Context(), &pb.HealthCheckRequest{Service: r.URL.Query().Get("service")})
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeProto(w, http.StatusOK, response)
+	}
+}
+
+func fromBody(r *http.Request, msg proto.Message) error {
+	body, err := io.ReadAll(http.MaxBytesReader(nil, r.Body, maxRequestBytes))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return fmt.Errorf("request body exceeds %d bytes", tooLarge.Limit)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read request body: %w", err)
+	}
+	if err := unmarshalOptions.Unmarshal(body, msg); err != nil {
+		return fmt.Errorf("invalid JSON request: %w", err)
+	}
+	return nil
+}
+
+// fromQuery sets msg's scalar and repeated scalar fields from query
+// parameters named after the proto or JSON field names. Repeated fields take
+// repeated parameters or comma-separated values; "q" is short for "query".
+func fromQuery(values url.Values, msg proto.Message) error {
+	m := msg.ProtoReflect()
+	fields := m.Descriptor().Fields()
+	for name, raw := range values {
+		if name == "q" {
+			name = "query"
+		}
+		field := fields.ByName(protoreflect.Name(name))
+		if field == nil {
+			field = fields.ByJSONName(name)
+		}
+		if field == nil {
+			return fmt.Errorf("unknown parameter %q", name)
+		}
+		if field.IsMap() || field.Kind() == protoreflect.MessageKind {
+			return fmt.Errorf("parameter %q can only be set in a POST body", name)
+		}
+
+		if !field.IsList() {
+			value, err := scalarValue(field, raw[len(raw)-1])
+			if err != nil {
+				return fmt.Errorf("invalid %s: %w", name, err)
+			}
+			m.Set(field, value)
+			continue
+		}
+		list := m.Mutable(field).List()
+		for _, joined := range raw {
+			for _, item := range strings.Split(joined, ",") {
+				value, err := scalarValue(field, strings.TrimSpace(item))
+				if err != nil {
+					return fmt.Errorf("invalid %s: %w", name, err)
+				}
+				list.Append(value)
+			}
+		}
+	}
+	return nil
+}
+
+// scalarValue parses a query parameter as the field's type
+func scalarValue(field protoreflect.FieldDescriptor, raw string) (protoreflect.Value, error) {
+	switch field.Kind() {
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(raw), nil
+	case protoreflect.BoolKind:
+		b, err := strconv.ParseBool(raw)
+		return protoreflect.ValueOfBool(b), err
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		n, err := strconv.ParseInt(raw, 10, 32)
+		return protoreflect.ValueOfInt32(int32(n)), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		return protoreflect.ValueOfInt64(n), err
+	case protoreflect.FloatKind:
+		f, err := strconv.ParseFloat(raw, 32)
+		return protoreflect.ValueOfFloat32(float32(f)), err
+	case protoreflect.DoubleKind:
+		f, err := strconv.ParseFloat(raw, 64)
+		return protoreflect.ValueOfFloat64(f), err
+	case protoreflect.EnumKind:
+		if value := field.Enum().Values().ByName(protoreflect.Name(raw)); value != nil {
+			return protoreflect.ValueOfEnum(value.Number()), nil
+		}
+		return protoreflect.Value{}, fmt.Errorf("unknown value %q", raw)
+	}
+	return protoreflect.Value{}, fmt.Errorf("unsupported type %s", field.Kind())
+}
+
+func writeProto(w http.ResponseWriter, code int, msg proto.Message) {
+	body, err := marshalOptions.Marshal(msg)
+	if err != nil {
+		writeError(w, status.Error(codes.Internal, err.Error()))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(body)
+}
+
+// writeError answers with the HTTP status matching err's gRPC code and a
+// {"code", "message"} body, as grpc-gateway does
+func writeError(w http.ResponseWriter, err error) {
+	st, ok := status.FromError(err)
+	if !ok {
+		st = status.New(codes.Internal, err.Error())
+	}
+	writeJSON(w, httpStatus(st.Code()), map[string]any{
+		"code":    st.Code().String(),
+		"message": st.Message(),
+	})
+}
+
+// httpStatus maps gRPC codes to HTTP statuses
+func httpStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Canceled:
+		// nginx's "client closed request"
+		return 499
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusInternalServerError
+}
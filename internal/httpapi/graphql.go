@@ -0,0 +1,531 @@
+package httpapi
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"net/http"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+//go:embed schema.graphql
+var graphqlSchema string
+
+// maxQueryDepth bounds how deeply a GraphQL query may nest; the schema
+// itself is at most five levels deep, introspection a few more
+const maxQueryDepth = 15
+
+// graphqlHandler serves the GraphQL schema at GET /graphql, with query,
+// variables and operationName as query parameters, and POST /graphql, with
+// them as a JSON body. Introspection is on, so gateways can stitch the
+// schema in. Like MultiSearch, one operation runs at most maxSearches
+// searches, and no more than that at once.
+func graphqlHandler(backend Backend, maxSearches int) http.HandlerFunc {
+	schema := graphql.MustParseSchema(graphqlSchema, &queryResolver{backend: backend},
+		graphql.MaxDepth(maxQueryDepth), graphql.MaxParallelism(maxSearches))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		var params struct {
+			Query         string         `json:"query"`
+			OperationName string         `json:"operationName"`
+			Variables     map[string]any `json:"variables"`
+		}
+		if r.Method == http.MethodGet {
+			query := r.URL.Query()
+			params.Query = query.Get("query")
+			params.OperationName = query.Get("operationName")
+			if variables := query.Get("variables"); variables != "" {
+				if err := json.Unmarshal([]byte(variables), &params.Variables); err != nil {
+					http.Error(w, "invalid variables: "+err.Error(), http.StatusBadRequest)
+					return
+				}
+			}
+		} else {
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBytes))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+				return
+			}
+			if err := json.Unmarshal(body, &params); err != nil {
+				http.Error(w, "invalid JSON body: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		if params.Query == "" {
+			http.Error(w, "missing query", http.StatusBadRequest)
+			return
+		}
+
+		// Errors are reported in the response body, as GraphQL clients
+		// expect, so the status is OK whatever happened
+		ctx := context.WithValue(r.Context(), searchBudgetKey{}, &searchBudget{max: int32(maxSearches)})
+		writeJSON(w, http.StatusOK, schema.Exec(ctx, params.Query, params.OperationName, params.Variables))
+	}
+}
+
+type searchBudgetKey struct{}
+
+// searchBudget counts the searches one operation has run, however they are
+// spelled: aliases and fragments each resolve search again
+type searchBudget struct {
+	max  int32
+	used atomic.Int32
+}
+
+// take reports whether the operation may run another search
+func (b *searchBudget) take() bool {
+	return b.used.Add(1) <= b.max
+}
+
+// graphqlError carries a backend error's gRPC code into the GraphQL error's
+// extensions
+type graphqlError struct {
+	err error
+}
+
+func (e graphqlError) Error() string {
+	return status.Convert(e.err).Message()
+}
+
+func (e graphqlError) Extensions() map[string]any {
+	return map[string]any{"code": status.Code(e.err).String()}
+}
+
+// int64Scalar is the Int64 scalar: GraphQL's Int is only 32 bits
+type int64Scalar int64
+
+func (int64Scalar) ImplementsGraphQLType(name string) bool {
+	return name == "Int64"
+}
+
+func (n *int64Scalar) UnmarshalGraphQL(input any) error {
+	switch v := input.(type) {
+	case int32:
+		*n = int64Scalar(v)
+	case int64:
+		*n = int64Scalar(v)
+	case float64:
+		if v != math.Trunc(v) || math.Abs(v) > math.MaxInt64 {
+			return fmt.Errorf("Int64 cannot represent %v", v)
+		}
+		*n = int64Scalar(v)
+	default:
+		return fmt.Errorf("Int64 cannot represent %T", input)
+	}
+	return nil
+}
+
+func (n int64Scalar) MarshalJSON() ([]byte, error) {
+	return json.Marshal(int64(n))
+}
+
+type queryResolver struct {
+	backend Backend
+}
+
+type searchArgs struct {
+	Input searchInput
+}
+
+type searchInput struct {
+	Query                 string
+	MaxResults            *int32
+	Platforms             *[]string
+	MaxResultsPerPlatform *[]platformValue
+	PageToken             *string
+	Locale                *string
+	MaxSnippetLength      *int32
+	Summarize             *bool
+	AllowSources          *[]string
+	DenySources           *[]string
+	AnsweredOnly          *bool
+	MinStars              *int32
+	MinUpvotes            *int32
+	Filter                *string
+	TimeoutMs             *int32
+	PerPlatformTimeoutMs  *int32
+	AcceptLanguage        *string
+	AcceptLanguageOnly    *bool
+	InterleaveWeights     *[]platformValue
+	Sort                  *string
+	ContentType           *string
+	Since                 *int64Scalar
+	Until                 *int64Scalar
+}
+
+type platformValue struct {
+	Platform string
+	Value    int32
+}
+
+// request converts the input to the gRPC request, which the backend then
+// validates like any other
+func (in *searchInput) request() *pb.SearchRequest {
+	req := &pb.SearchRequest{
+		Query:                 in.Query,
+		MaxResults:            deref(in.MaxResults),
+		Platforms:             deref(in.Platforms),
+		MaxResultsPerPlatform: platformValues(in.MaxResultsPerPlatform),
+		PageToken:             deref(in.PageToken),
+		Locale:                deref(in.Locale),
+		MaxSnippetLength:      deref(in.MaxSnippetLength),
+		Summarize:             deref(in.Summarize),
+		AllowSources:          deref(in.AllowSources),
+		DenySources:           deref(in.DenySources),
+		AnsweredOnly:          deref(in.AnsweredOnly),
+		MinStars:              deref(in.MinStars),
+		MinUpvotes:            deref(in.MinUpvotes),
+		Filter:                deref(in.Filter),
+		TimeoutMs:             deref(in.TimeoutMs),
+		PerPlatformTimeoutMs:  deref(in.PerPlatformTimeoutMs),
+		AcceptLanguage:        deref(in.AcceptLanguage),
+		AcceptLanguageOnly:    deref(in.AcceptLanguageOnly),
+		InterleaveWeights:     platformValues(in.InterleaveWeights),
+		Since:                 int64(deref(in.Since)),
+		Until:                 int64(deref(in.Until)),
+	}
+	// The schema only admits the enums' own values
+	if in.Sort != nil {
+		req.Sort = pb.SortOrder(pb.SortOrder_value["SORT_ORDER_"+*in.Sort])
+	}
+	if in.ContentType != nil {
+		req.ContentType = pb.ContentType(pb.ContentType_value["CONTENT_TYPE_"+*in.ContentType])
+	}
+	return req
+}
+
+func deref[T any](p *T) T {
+	if p == nil {
+		var zero T
+		return zero
+	}
+	return *p
+}
+
+// platformValues turns a list of platform values into the request's map;
+// a platform listed twice keeps its last value
+func platformValues(values *[]platformValue) map[string]int32 {
+	if values == nil {
+		return nil
+	}
+	m := make(map[string]int32, len(*values))
+	for _, v := range *values {
+		m[v.Platform] = v.Value
+	}
+	return m
+}
+
+func (q *queryResolver) Search(ctx context.Context, args searchArgs) (*searchResponseResolver, error) {
+	if budget, ok := ctx.Value(searchBudgetKey{}).(*searchBudget); ok && !budget.take() {
+		return nil, graphqlError{status.Errorf(codes.InvalidArgument, "too many searches in one operation (max %d)", budget.max)}
+	}
+	response, err := q.backend.FederatedSearch(ctx, args.Input.request())
+	if err != nil {
+		return nil, graphqlError{err}
+	}
+	return &searchResponseResolver{response}, nil
+}
+
+func (q *queryResolver) Platforms(ctx context.Context) ([]*platformResolver, error) {
+	response, err := q.backend.ListPlatforms(ctx, &pb.ListPlatformsRequest{})
+	if err != nil {
+		return nil, graphqlError{err}
+	}
+	platforms := make([]*platformResolver, len(response.Platforms))
+	for i, p := range response.Platforms {
+		platforms[i] = &platformResolver{p}
+	}
+	return platforms, nil
+}
+
+// enumName is a protobuf enum value's name without its type prefix, the
+// GraphQL enum value it stands for
+func enumName(name, prefix string) string {
+	return strings.TrimPrefix(name, prefix)
+}
+
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+type searchResponseResolver struct {
+	r *pb.SearchResponse
+}
+
+func (s *searchResponseResolver) Results() []*resultResolver {
+	results := make([]*resultResolver, len(s.r.Results))
+	for i, result := range s.r.Results {
+		results[i] = &resultResolver{result}
+	}
+	return results
+}
+
+func (s *searchResponseResolver) TotalCount() int32          { return s.r.TotalCount }
+func (s *searchResponseResolver) PlatformsSuccess() []string { return nonNil(s.r.PlatformsSuccess) }
+func (s *searchResponseResolver) PlatformsTimeout() []string { return nonNil(s.r.PlatformsTimeout) }
+func (s *searchResponseResolver) PlatformsError() []string   { return nonNil(s.r.PlatformsError) }
+func (s *searchResponseResolver) NextPageToken() *string     { return optional(s.r.NextPageToken) }
+
+func (s *searchResponseResolver) PlatformStatuses() []*platformStatusResolver {
+	statuses := make([]*platformStatusResolver, len(s.r.PlatformStatuses))
+	for i, st := range s.r.PlatformStatuses {
+		statuses[i] = &platformStatusResolver{st}
+	}
+	return statuses
+}
+
+func (s *searchResponseResolver) Metadata() *metadataResolver {
+	if s.r.Metadata == nil {
+		return &metadataResolver{&pb.ResponseMetadata{}}
+	}
+	return &metadataResolver{s.r.Metadata}
+}
+
+// nonNil keeps non-null lists from resolving to null
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+type resultResolver struct {
+	r *pb.Result
+}
+
+func (r *resultResolver) Platform() string         { return r.r.Platform }
+func (r *resultResolver) Title() string            { return r.r.Title }
+func (r *resultResolver) Snippet() string          { return r.r.Snippet }
+func (r *resultResolver) URL() string              { return r.r.Url }
+func (r *resultResolver) CreatedAt() int64Scalar   { return int64Scalar(r.r.CreatedAt) }
+func (r *resultResolver) UpdatedAt() int64Scalar   { return int64Scalar(r.r.UpdatedAt) }
+func (r *resultResolver) AgeDays() int32           { return r.r.AgeDays }
+func (r *resultResolver) ThumbnailURL() *string    { return optional(r.r.ThumbnailUrl) }
+func (r *resultResolver) Summary() *string         { return optional(r.r.Summary) }
+func (r *resultResolver) NormalizedScore() float64 { return r.r.NormalizedScore }
+func (r *resultResolver) ResultType() string {
+	return enumName(r.r.ResultType.String(), "RESULT_TYPE_")
+}
+func (r *resultResolver) Author() *authorResolver   { return newAuthorResolver(r.r.Author) }
+func (r *resultResolver) Details() *detailsResolver { return newDetailsResolver(r.r) }
+
+// Metadata is sorted by key, so responses are stable
+func (r *resultResolver) Metadata() []*metadataEntryResolver {
+	keys := slices.Sorted(maps.Keys(r.r.Metadata))
+	entries := make([]*metadataEntryResolver, len(keys))
+	for i, key := range keys {
+		entries[i] = &metadataEntryResolver{key: key, value: r.r.Metadata[key]}
+	}
+	return entries
+}
+
+type metadataEntryResolver struct {
+	key, value string
+}
+
+func (e *metadataEntryResolver) Key() string   { return e.key }
+func (e *metadataEntryResolver) Value() string { return e.value }
+
+type authorResolver struct {
+	a *pb.Author
+}
+
+func newAuthorResolver(a *pb.Author) *authorResolver {
+	if a == nil {
+		return nil
+	}
+	return &authorResolver{a}
+}
+
+func (a *authorResolver) Name() string       { return a.a.Name }
+func (a *authorResolver) Handle() string     { return a.a.Handle }
+func (a *authorResolver) ProfileURL() string { return a.a.ProfileUrl }
+
+// detailsResolver resolves the PlatformDetails union
+type detailsResolver struct {
+	r *pb.Result
+}
+
+func newDetailsResolver(r *pb.Result) *detailsResolver {
+	if r.PlatformDetails == nil {
+		return nil
+	}
+	return &detailsResolver{r}
+}
+
+func (d *detailsResolver) ToGitHubDetails() (*githubDetailsResolver, bool) {
+	if m := d.r.GetGithub(); m != nil {
+		return &githubDetailsResolver{m}, true
+	}
+	return nil, false
+}
+
+func (d *detailsResolver) ToStackOverflowDetails() (*stackOverflowDetailsResolver, bool) {
+	if m := d.r.GetStackoverflow(); m != nil {
+		return &stackOverflowDetailsResolver{m}, true
+	}
+	return nil, false
+}
+
+func (d *detailsResolver) ToRedditDetails() (*redditDetailsResolver, bool) {
+	if m := d.r.GetReddit(); m != nil {
+		return &redditDetailsResolver{m}, true
+	}
+	return nil, false
+}
+
+func (d *detailsResolver) ToDockerHubDetails() (*dockerHubDetailsResolver, bool) {
+	if m := d.r.GetDockerhub(); m != nil {
+		return &dockerHubDetailsResolver{m}, true
+	}
+	return nil, false
+}
+
+type githubDetailsResolver struct {
+	m *pb.GitHubMeta
+}
+
+func (g *githubDetailsResolver) Stars() int32       { return g.m.Stars }
+func (g *githubDetailsResolver) Forks() int32       { return g.m.Forks }
+func (g *githubDetailsResolver) Language() string   { return g.m.Language }
+func (g *githubDetailsResolver) OpenIssues() int32  { return g.m.OpenIssues }
+func (g *githubDetailsResolver) Repository() string { return g.m.Repository }
+func (g *githubDetailsResolver) Path() string       { return g.m.Path }
+func (g *githubDetailsResolver) Sha() string        { return g.m.Sha }
+func (g *githubDetailsResolver) Number() int32      { return g.m.Number }
+func (g *githubDetailsResolver) Comments() int32    { return g.m.Comments }
+func (g *githubDetailsResolver) State() string      { return g.m.State }
+func (g *githubDetailsResolver) Labels() []string   { return nonNil(g.m.Labels) }
+func (g *githubDetailsResolver) PullRequest() bool  { return g.m.PullRequest }
+func (g *githubDetailsResolver) Upvotes() int32     { return g.m.Upvotes }
+func (g *githubDetailsResolver) IsAnswered() bool   { return g.m.IsAnswered }
+func (g *githubDetailsResolver) Category() string   { return g.m.Category }
+
+type stackOverflowDetailsResolver struct {
+	m *pb.StackOverflowMeta
+}
+
+func (s *stackOverflowDetailsResolver) Score() int32       { return s.m.Score }
+func (s *stackOverflowDetailsResolver) AnswerCount() int32 { return s.m.AnswerCount }
+func (s *stackOverflowDetailsResolver) ViewCount() int32   { return s.m.ViewCount }
+func (s *stackOverflowDetailsResolver) IsAnswered() bool   { return s.m.IsAnswered }
+func (s *stackOverflowDetailsResolver) Tags() []string     { return nonNil(s.m.Tags) }
+
+type redditDetailsResolver struct {
+	m *pb.RedditMeta
+}
+
+func (r *redditDetailsResolver) Score() int32         { return r.m.Score }
+func (r *redditDetailsResolver) NumComments() int32   { return r.m.NumComments }
+func (r *redditDetailsResolver) Subreddit() string    { return r.m.Subreddit }
+func (r *redditDetailsResolver) UpvoteRatio() float64 { return float64(r.m.UpvoteRatio) }
+func (r *redditDetailsResolver) LinkDomain() string   { return r.m.LinkDomain }
+func (r *redditDetailsResolver) LinkURL() string      { return r.m.LinkUrl }
+
+type dockerHubDetailsResolver struct {
+	m *pb.DockerHubMeta
+}
+
+func (d *dockerHubDetailsResolver) Stars() int32       { return d.m.Stars }
+func (d *dockerHubDetailsResolver) Pulls() int64Scalar { return int64Scalar(d.m.Pulls) }
+func (d *dockerHubDetailsResolver) Official() bool     { return d.m.Official }
+func (d *dockerHubDetailsResolver) Verified() bool     { return d.m.Verified }
+func (d *dockerHubDetailsResolver) Namespace() string  { return d.m.Namespace }
+
+type platformStatusResolver struct {
+	s *pb.PlatformStatus
+}
+
+func (p *platformStatusResolver) Platform() string { return p.s.Platform }
+func (p *platformStatusResolver) State() string {
+	return enumName(p.s.State.String(), "PLATFORM_STATE_")
+}
+func (p *platformStatusResolver) ErrorCode() string        { return p.s.ErrorCode }
+func (p *platformStatusResolver) HTTPStatus() int32        { return p.s.HttpStatus }
+func (p *platformStatusResolver) RetryAfterSeconds() int32 { return p.s.RetryAfterSeconds }
+func (p *platformStatusResolver) DurationMs() int32        { return p.s.DurationMs }
+func (p *platformStatusResolver) ServedFromCache() bool    { return p.s.ServedFromCache }
+func (p *platformStatusResolver) CacheAgeMs() int64Scalar  { return int64Scalar(p.s.CacheAgeMs) }
+func (p *platformStatusResolver) DataAsOf() int64Scalar    { return int64Scalar(p.s.DataAsOf) }
+
+type metadataResolver struct {
+	m *pb.ResponseMetadata
+}
+
+func (m *metadataResolver) ResponseTimeMs() int32        { return m.m.ResponseTimeMs }
+func (m *metadataResolver) PlatformsQueried() int32      { return m.m.PlatformsQueried }
+func (m *metadataResolver) ResultsDropped() int32        { return m.m.ResultsDropped }
+func (m *metadataResolver) SnippetsTruncated() int32     { return m.m.SnippetsTruncated }
+func (m *metadataResolver) MetadataTruncated() int32     { return m.m.MetadataTruncated }
+func (m *metadataResolver) PlatformsFromIndex() []string { return nonNil(m.m.PlatformsFromIndex) }
+func (m *metadataResolver) Approximate() bool            { return m.m.Approximate }
+func (m *metadataResolver) ApproximateQuery() string     { return m.m.ApproximateQuery }
+func (m *metadataResolver) Similarity() float64          { return float64(m.m.Similarity) }
+func (m *metadataResolver) RelatedQueries() []string     { return nonNil(m.m.RelatedQueries) }
+func (m *metadataResolver) CacheHit() bool               { return m.m.CacheHit }
+func (m *metadataResolver) RequestID() string            { return m.m.RequestId }
+
+func (m *metadataResolver) ShortenedQueries() []*shortenedQueryResolver {
+	platforms := slices.Sorted(maps.Keys(m.m.ShortenedQueries))
+	queries := make([]*shortenedQueryResolver, len(platforms))
+	for i, platform := range platforms {
+		queries[i] = &shortenedQueryResolver{platform: platform, query: m.m.ShortenedQueries[platform]}
+	}
+	return queries
+}
+
+type shortenedQueryResolver struct {
+	platform, query string
+}
+
+func (s *shortenedQueryResolver) Platform() string { return s.platform }
+func (s *shortenedQueryResolver) Query() string    { return s.query }
+
+type platformResolver struct {
+	p *pb.PlatformInfo
+}
+
+func (p *platformResolver) Name() string                { return p.p.Name }
+func (p *platformResolver) DisplayName() string         { return p.p.DisplayName }
+func (p *platformResolver) IconURL() string             { return p.p.IconUrl }
+func (p *platformResolver) BrandColor() string          { return p.p.BrandColor }
+func (p *platformResolver) Description() string         { return p.p.Description }
+func (p *platformResolver) CredentialsConfigured() bool { return p.p.CredentialsConfigured }
+func (p *platformResolver) SupportedFilters() []string  { return nonNil(p.p.SupportedFilters) }
+func (p *platformResolver) CircuitState() string {
+	return enumName(p.p.CircuitState.String(), "CIRCUIT_STATE_")
+}
+func (p *platformResolver) DefaultMaxResults() int32 { return p.p.DefaultMaxResults }
+func (p *platformResolver) QueryTerms() []string     { return nonNil(p.p.QueryTerms) }
+func (p *platformResolver) Disabled() bool           { return p.p.Disabled }
+
+func (p *platformResolver) Quota() *quotaResolver {
+	if p.p.Quota == nil {
+		return nil
+	}
+	return &quotaResolver{p.p.Quota}
+}
+
+type quotaResolver struct {
+	q *pb.UpstreamQuota
+}
+
+func (q *quotaResolver) Limit() int32         { return q.q.Limit }
+func (q *quotaResolver) Remaining() int32     { return q.q.Remaining }
+func (q *quotaResolver) ResetAt() int64Scalar { return int64Scalar(q.q.ResetAt) }
+func (q *quotaResolver) Throttled() bool      { return q.q.Throttled }
@@ -0,0 +1,182 @@
+// Package httpapi serves the proxy's plain HTTP endpoints: health checks for
+// load balancers, upstream traffic metrics, a JSON gateway to the gRPC
+// search and health operations, and a GraphQL endpoint for search
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/farhapartex/search-proxy/internal/cache"
+	"github.com/farhapartex/search-proxy/internal/fetchers"
+	grpcServer "github.com/farhapartex/search-proxy/internal/grpc"
+	"github.com/farhapartex/search-proxy/internal/httpclient"
+	"github.com/farhapartex/search-proxy/internal/requestid"
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+// Backend is what the HTTP endpoints report on and call into; the gRPC server
+// implements it
+type Backend interface {
+	FederatedSearch(ctx context.Context, req *pb.SearchRequest) (*pb.SearchResponse, error)
+	HealthCheck(ctx context.Context, req *pb.HealthCheckRequest) (*pb.HealthCheckResponse, error)
+	ListPlatforms(ctx context.Context, req *pb.ListPlatformsRequest) (*pb.ListPlatformsResponse, error)
+	HTTPMetrics() map[string]httpclient.HostStats
+	RPCStats() map[string]grpcServer.RPCStats
+	UpstreamQuotas() map[string]fetchers.QuotaStatus
+	CacheStats() (cache.Stats, bool)
+}
+
+// NewHandler returns the HTTP endpoints:
+//
+//	GET /healthz  health as JSON; 503 unless healthy
+//	GET /metrics  per-method gRPC calls, per-host upstream traffic, upstream quotas and response cache counters as JSON
+//	GET /v1/search  FederatedSearch with the request fields as query parameters
+//	POST /v1/search  FederatedSearch with a JSON SearchRequest body
+//	GET /v1/health  HealthCheck as JSON
+//	GET /openapi.json  OpenAPI 3 document for the /v1 endpoints
+//	GET /graphql  GraphQL query with query, variables and operationName as query parameters
+//	POST /graphql  GraphQL query with a JSON body
+//
+// A GraphQL operation may run at most maxSearches searches, the MultiSearch
+// limit. Every request gets a request ID as gRPC calls do, the caller's
+// X-Request-Id if usable or a new one, sent back in the same header.
+func NewHandler(backend Backend, maxSearches int) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("GET /v1/search", search(backend))
+	mux.Handle("POST /v1/search", search(backend))
+	mux.Handle("GET /v1/health", health(backend))
+	graphql := graphqlHandler(backend, maxSearches)
+	mux.Handle("GET /graphql", graphql)
+	mux.Handle("POST /graphql", graphql)
+	mux.HandleFunc("GET /openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, openAPIDocument())
+	})
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		health, err := backend.HealthCheck(r.Context(), &pb.HealthCheckRequest{})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		status := http.StatusOK
+		if health.Status != "healthy" {
+			status = http.StatusServiceUnavailable
+		}
+		writeJSON(w, status, map[string]any{
+			"status":     health.Status,
+			"version":    health.Version,
+			"commit":     health.Commit,
+			"build_date": health.BuildDate,
+			"timestamp":  health.Timestamp,
+		})
+	})
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, r *http.Request) {
+		hosts := make(map[string]hostMetrics)
+		for host, stats := range backend.HTTPMetrics() {
+			hosts[host] = newHostMetrics(stats)
+		}
+		quotas := make(map[string]quotaMetrics)
+		for platform, status := range backend.UpstreamQuotas() {
+			quotas[platform] = newQuotaMetrics(status)
+		}
+		rpcs := make(map[string]rpcMetrics)
+		for method, stats := range backend.RPCStats() {
+			rpcs[method] = newRPCMetrics(stats)
+		}
+		body := map[string]any{"rpcs": rpcs, "upstream_hosts": hosts, "upstream_quotas": quotas}
+		if stats, ok := backend.CacheStats(); ok {
+			body["response_cache"] = map[string]any{
+				"backend":   stats.Backend,
+				"hits":      stats.Hits,
+				"misses":    stats.Misses,
+				"errors":    stats.Errors,
+				"evictions": stats.Evictions,
+				"entries":   stats.Entries,
+			}
+		}
+		writeJSON(w, http.StatusOK, body)
+	})
+	return withRequestID(mux)
+}
+
+// withRequestID stores the request's ID in its context for the logs, spans
+// and responses below, and sets it on the response
+func withRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := requestid.FromRequest(r)
+		w.Header().Set(requestid.MetadataKey, id)
+		next.ServeHTTP(w, r.WithContext(requestid.NewContext(r.Context(), id)))
+	})
+}
+
+// hostMetrics is the JSON form of httpclient.HostStats, in milliseconds
+type hostMetrics struct {
+	Requests     int64         `json:"requests"`
+	Errors       int64         `json:"errors"`
+	StatusCodes  map[int]int64 `json:"status_codes"`
+	ReusedConns  int64         `json:"reused_conns"`
+	AvgLatencyMs float64       `json:"avg_latency_ms"`
+	MaxLatencyMs float64       `json:"max_latency_ms"`
+	DNSTimeMs    float64       `json:"dns_time_ms"`
+	ConnectMs    float64       `json:"connect_time_ms"`
+	TLSTimeMs    float64       `json:"tls_time_ms"`
+}
+
+func newHostMetrics(s httpclient.HostStats) hostMetrics {
+	return hostMetrics{
+		Requests:     s.Requests,
+		Errors:       s.Errors,
+		StatusCodes:  s.StatusCodes,
+		ReusedConns:  s.ReusedConns,
+		AvgLatencyMs: float64(s.AvgLatency().Microseconds()) / 1000,
+		MaxLatencyMs: float64(s.MaxLatency.Microseconds()) / 1000,
+		DNSTimeMs:    float64(s.DNSTime.Microseconds()) / 1000,
+		ConnectMs:    float64(s.ConnectTime.Microseconds()) / 1000,
+		TLSTimeMs:    float64(s.TLSTime.Microseconds()) / 1000,
+	}
+}
+
+// rpcMetrics is the JSON form of grpc.RPCStats, in milliseconds
+type rpcMetrics struct {
+	Calls        int64            `json:"calls"`
+	Errors       int64            `json:"errors"`
+	Codes        map[string]int64 `json:"codes"`
+	AvgLatencyMs float64          `json:"avg_latency_ms"`
+	MaxLatencyMs float64          `json:"max_latency_ms"`
+}
+
+func newRPCMetrics(s grpcServer.RPCStats) rpcMetrics {
+	return rpcMetrics{
+		Calls:        s.Calls,
+		Errors:       s.Errors,
+		Codes:        s.Codes,
+		AvgLatencyMs: float64(s.AvgLatency().Microseconds()) / 1000,
+		MaxLatencyMs: float64(s.MaxLatency.Microseconds()) / 1000,
+	}
+}
+
+// quotaMetrics is the JSON form of fetchers.QuotaStatus
+type quotaMetrics struct {
+	Limit     int   `json:"limit"`
+	Remaining int   `json:"remaining"`
+	ResetAt   int64 `json:"reset_at"`
+	Throttled bool  `json:"throttled"`
+}
+
+func newQuotaMetrics(s fetchers.QuotaStatus) quotaMetrics {
+	m := quotaMetrics{Limit: s.Limit, Remaining: s.Remaining, Throttled: s.Throttled}
+	if !s.Reset.IsZero() {
+		m.ResetAt = s.Reset.Unix()
+	}
+	return m
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Printf("WARNING: Failed to write HTTP response: %v", err)
+	}
+}
@@ -0,0 +1,388 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/farhapartex/search-proxy/internal/cache"
+	"github.com/farhapartex/search-proxy/internal/fetchers"
+	grpcServer "github.com/farhapartex/search-proxy/internal/grpc"
+	"github.com/farhapartex/search-proxy/internal/httpclient"
+	"github.com/farhapartex/search-proxy/internal/requestid"
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+// testMaxSearches is the searches a GraphQL operation may run in tests
+const testMaxSearches = 3
+
+type stubBackend struct {
+	status string
+	// searched receives each search request, searches counts them and
+	// requestID is the last one's request ID; searchErr fails them
+	mu        sync.Mutex
+	searched  *pb.SearchRequest
+	searches  int
+	requestID string
+	searchErr error
+}
+
+func (b *stubBackend) FederatedSearch(ctx context.Context, req *pb.SearchRequest) (*pb.SearchResponse, error) {
+	b.mu.Lock()
+	b.searched = req
+	b.searches++
+	b.requestID = requestid.FromContext(ctx)
+	b.mu.Unlock()
+	if b.searchErr != nil {
+		return nil, b.searchErr
+	}
+	return &pb.SearchResponse{
+		Results: []*pb.Result{{
+			Platform:        "github",
+			Title:           req.Query,
+			Timestamp:       1700000000,
+			CreatedAt:       4102444800,
+			Metadata:        map[string]string{"stars": "42", "language": "Go"},
+			PlatformDetails: &pb.Result_Github{Github: &pb.GitHubMeta{Stars: 42, Language: "Go"}},
+		}},
+		TotalCount: 1,
+	}, nil
+}
+
+func (b *stubBackend) HealthCheck(context.Context, *pb.HealthCheckRequest) (*pb.HealthCheckResponse, error) {
+	return &pb.HealthCheckResponse{Status: b.status, Version: "dev"}, nil
+}
+
+func (b *stubBackend) ListPlatforms(context.Context, *pb.ListPlatformsRequest) (*pb.ListPlatformsResponse, error) {
+	return &pb.ListPlatformsResponse{Platforms: []*pb.PlatformInfo{
+		{Name: "github", DisplayName: "GitHub", CircuitState: pb.CircuitState_CIRCUIT_STATE_HALF_OPEN},
+	}}, nil
+}
+
+func (b *stubBackend) HTTPMetrics() map[string]httpclient.HostStats {
+	return map[string]httpclient.HostStats{
+		"api.github.com": {Requests: 4, TotalLatency: 200 * time.Millisecond, StatusCodes: map[int]int64{200: 4}},
+	}
+}
+
+func (b *stubBackend) RPCStats() map[string]grpcServer.RPCStats {
+	return map[string]grpcServer.RPCStats{
+		"/search.SearchService/FederatedSearch": {Calls: 2, Errors: 1, Codes: map[string]int64{"OK": 1, "Unavailable": 1}, TotalLatency: 30 * time.Millisecond},
+	}
+}
+
+func (b *stubBackend) UpstreamQuotas() map[string]fetchers.QuotaStatus {
+	return map[string]fetchers.QuotaStatus{
+		"github": {Quota: fetchers.Quota{Limit: 30, Remaining: 1, Reset: time.Unix(1700000060, 0)}, Throttled: true},
+	}
+}
+
+func (b *stubBackend) CacheStats() (cache.Stats, bool) {
+	return cache.Stats{Backend: "local", Hits: 3, Misses: 1, Entries: 1}, true
+}
+
+func TestHealthzReportsDrainingAsUnavailable(t *testing.T) {
+	for status, wantCode := range map[string]int{"healthy": http.StatusOK, "draining": http.StatusServiceUnavailable} {
+		rec := httptest.NewRecorder()
+		NewHandler(&stubBackend{status: status}, testMaxSearches).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+		if rec.Code != wantCode {
+			t.Errorf("%s: GET /healthz = %d, want %d", status, rec.Code, wantCode)
+		}
+	}
+}
+
+func TestMetricsReportsUpstreamHosts(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewHandler(&stubBackend{status: "healthy"}, testMaxSearches).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	var body struct {
+		RPCs           map[string]rpcMetrics   `json:"rpcs"`
+		UpstreamHosts  map[string]hostMetrics  `json:"upstream_hosts"`
+		UpstreamQuotas map[string]quotaMetrics `json:"upstream_quotas"`
+		ResponseCache  map[string]any          `json:"response_cache"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("decoding /metrics: %v", err)
+	}
+	if got := body.RPCs["/search.SearchService/FederatedSearch"]; got.Calls != 2 || got.Codes["Unavailable"] != 1 || got.AvgLatencyMs != 15 {
+		t.Errorf("FederatedSearch rpc = %+v, want 2 calls averaging 15ms, one unavailable", got)
+	}
+	if got := body.UpstreamHosts["api.github.com"]; got.Requests != 4 || got.AvgLatencyMs != 50 {
+		t.Errorf("api.github.com = %+v, want 4 requests averaging 50ms", got)
+	}
+	if got := body.UpstreamQuotas["github"]; got != (quotaMetrics{Limit: 30, Remaining: 1, ResetAt: 1700000060, Throttled: true}) {
+		t.Errorf("github quota = %+v, want 1 of 30 left and throttled", got)
+	}
+	if body.ResponseCache["backend"] != "local" || body.ResponseCache["hits"] != 3.0 || body.ResponseCache["misses"] != 1.0 {
+		t.Errorf("response_cache = %v, want 3 hits and 1 miss in the local cache", body.ResponseCache)
+	}
+}
+
+func TestSearchGetMapsQueryParameters(t *testing.T) {
+	backend := &stubBackend{}
+	rec := httptest.NewRecorder()
+	target := "/v1/search?q=golang&max_results=5&platforms=github,reddit&platforms=hn&sort=SORT_ORDER_RELEVANCE"
+	NewHandler(backend, testMaxSearches).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /v1/search = %d: %s", rec.Code, rec.Body)
+	}
+	want := &pb.SearchRequest{
+		Query:      "golang",
+		MaxResults: 5,
+		Platforms:  []string{"github", "reddit", "hn"},
+		Sort:       pb.SortOrder_SORT_ORDER_RELEVANCE,
+	}
+	if !proto.Equal(backend.searched, want) {
+		t.Errorf("searched %v, want %v", backend.searched, want)
+	}
+
+	var response pb.SearchResponse
+	if err := protojson.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if len(response.Results) != 1 || response.Results[0].Title != "golang" {
+		t.Errorf("response = %v, want the backend's response", &response)
+	}
+	if !strings.Contains(rec.Body.String(), `"timestamp":"1700000000"`) {
+		t.Errorf("response %s does not use proto field names", rec.Body)
+	}
+}
+
+func TestSearchPostDecodesJSONBody(t *testing.T) {
+	backend := &stubBackend{}
+	rec := httptest.NewRecorder()
+	body := `{"query": "golang", "interleaveWeights": {"github": 2}}`
+	NewHandler(backend, testMaxSearches).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/search", strings.NewReader(body)))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /v1/search = %d: %s", rec.Code, rec.Body)
+	}
+	if backend.searched.Query != "golang" || backend.searched.InterleaveWeights["github"] != 2 {
+		t.Errorf("searched %v, want the decoded body", backend.searched)
+	}
+}
+
+func TestSearchRejectsBadRequests(t *testing.T) {
+	for name, req := range map[string]*http.Request{
+		"unknown parameter": httptest.NewRequest(http.MethodGet, "/v1/search?q=go&bogus=1", nil),
+		"bad integer":       httptest.NewRequest(http.MethodGet, "/v1/search?q=go&max_results=many", nil),
+		"map parameter":     httptest.NewRequest(http.MethodGet, "/v1/search?q=go&interleave_weights=2", nil),
+		"malformed body":    httptest.NewRequest(http.MethodPost, "/v1/search", strings.NewReader(`{"query":`)),
+		"unknown field":     httptest.NewRequest(http.MethodPost, "/v1/search", strings.NewReader(`{"bogus": 1}`)),
+	} {
+		backend := &stubBackend{}
+		rec := httptest.NewRecorder()
+		NewHandler(backend, testMaxSearches).ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", name, rec.Code)
+		}
+		if backend.searched != nil {
+			t.Errorf("%s: backend was called", name)
+		}
+	}
+}
+
+func TestSearchMapsGRPCErrors(t *testing.T) {
+	for code, want := range map[codes.Code]int{
+		codes.InvalidArgument:  http.StatusBadRequest,
+		codes.Unavailable:      http.StatusServiceUnavailable,
+		codes.DeadlineExceeded: http.StatusGatewayTimeout,
+		codes.Internal:         http.StatusInternalServerError,
+	} {
+		backend := &stubBackend{searchErr: status.Error(code, "failed")}
+		rec := httptest.NewRecorder()
+		NewHandler(backend, testMaxSearches).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/search?q=go", nil))
+
+		var body struct{ Code, Message string }
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+			t.Fatalf("%s: decoding error body: %v", code, err)
+		}
+		if rec.Code != want || body.Code != code.String() || body.Message != "failed" {
+			t.Errorf("%s: got %d %+v, want %d", code, rec.Code, body, want)
+		}
+	}
+}
+
+func TestSearchCarriesRequestID(t *testing.T) {
+	for _, path := range []string{"/v1/search?q=go", "/graphql?query=" + url.QueryEscape(`{ search(input: {query: "go"}) { totalCount } }`)} {
+		backend := &stubBackend{}
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("X-Request-Id", "req-42")
+		rec := httptest.NewRecorder()
+		NewHandler(backend, testMaxSearches).ServeHTTP(rec, req)
+
+		if backend.requestID != "req-42" || rec.Header().Get("X-Request-Id") != "req-42" {
+			t.Errorf("GET %s: backend saw request ID %q, response header %q; want the caller's", path, backend.requestID, rec.Header().Get("X-Request-Id"))
+		}
+	}
+
+	backend := &stubBackend{}
+	req := httptest.NewRequest(http.MethodGet, "/v1/search?q=go", nil)
+	req.Header.Set("X-Request-Id", "not valid")
+	rec := httptest.NewRecorder()
+	NewHandler(backend, testMaxSearches).ServeHTTP(rec, req)
+	if id := rec.Header().Get("X-Request-Id"); id == "" || id == "not valid" || id != backend.requestID {
+		t.Errorf("response header %q, backend %q; want the same new ID for an invalid one", id, backend.requestID)
+	}
+}
+
+func TestHealthReturnsHealthCheckResponse(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewHandler(&stubBackend{status: "healthy"}, testMaxSearches).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/health", nil))
+
+	var response pb.HealthCheckResponse
+	if err := protojson.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decoding /v1/health: %v", err)
+	}
+	if rec.Code != http.StatusOK || response.Status != "healthy" || response.Version != "dev" {
+		t.Errorf("GET /v1/health = %d %v", rec.Code, &response)
+	}
+}
+
+func TestOpenAPIDocumentDescribesMessages(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewHandler(&stubBackend{}, testMaxSearches).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+
+	var doc struct {
+		Paths      map[string]map[string]any `json:"paths"`
+		Components struct {
+			Schemas map[string]struct {
+				Type       string                    `json:"type"`
+				Properties map[string]map[string]any `json:"properties"`
+				Enum       []string                  `json:"enum"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&doc); err != nil {
+		t.Fatalf("decoding /openapi.json: %v", err)
+	}
+	for _, path := range []string{"/v1/search", "/v1/health"} {
+		if doc.Paths[path] == nil {
+			t.Errorf("missing path %s", path)
+		}
+	}
+
+	schemas := doc.Components.Schemas
+	request := schemas["SearchRequest"].Properties
+	if request["query"]["type"] != "string" || request["platforms"]["type"] != "array" {
+		t.Errorf("SearchRequest properties = %v", request)
+	}
+	if request["interleave_weights"]["additionalProperties"] == nil {
+		t.Errorf("interleave_weights = %v, want a map schema", request["interleave_weights"])
+	}
+	if got := schemas["Result"].Properties["timestamp"]; got["type"] != "string" || got["format"] != "int64" {
+		t.Errorf("Result.timestamp = %v, want an int64 string", got)
+	}
+	if got := schemas["SortOrder"].Enum; len(got) == 0 || got[0] != "SORT_ORDER_UNSPECIFIED" {
+		t.Errorf("SortOrder enum = %v", got)
+	}
+	for _, name := range []string{"SearchResponse", "PlatformStatus", "ResponseMetadata", "HealthCheckResponse", "Error"} {
+		if _, ok := schemas[name]; !ok {
+			t.Errorf("missing schema %s", name)
+		}
+	}
+}
+
+// graphqlResponse is the shape of a GraphQL response body
+type graphqlResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string         `json:"message"`
+		Extensions map[string]any `json:"extensions"`
+	} `json:"errors"`
+}
+
+func postGraphQL(t *testing.T, backend Backend, body string) graphqlResponse {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	NewHandler(backend, testMaxSearches).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /graphql = %d: %s", rec.Code, rec.Body)
+	}
+	var response graphqlResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decoding response %s: %v", rec.Body, err)
+	}
+	return response
+}
+
+func TestGraphQLSearchResolvesTypedDetails(t *testing.T) {
+	backend := &stubBackend{}
+	body := `{
+		"query": "query Find($q: String!, $since: Int64) { search(input: {query: $q, sort: RELEVANCE, since: $since, interleaveWeights: [{platform: \"github\", value: 2}]}) { results { title createdAt metadata { key value } details { ... on GitHubDetails { stars language } } } } }",
+		"variables": {"q": "golang", "since": 4102444800}
+	}`
+	response := postGraphQL(t, backend, body)
+
+	if len(response.Errors) > 0 {
+		t.Fatalf("errors = %v", response.Errors)
+	}
+	want := &pb.SearchRequest{
+		Query:             "golang",
+		Sort:              pb.SortOrder_SORT_ORDER_RELEVANCE,
+		Since:             4102444800,
+		InterleaveWeights: map[string]int32{"github": 2},
+	}
+	if !proto.Equal(backend.searched, want) {
+		t.Errorf("searched %v, want %v", backend.searched, want)
+	}
+	wantData := `{"search":{"results":[{"title":"golang","createdAt":4102444800,` +
+		`"metadata":[{"key":"language","value":"Go"},{"key":"stars","value":"42"}],` +
+		`"details":{"stars":42,"language":"Go"}}]}}`
+	if string(response.Data) != wantData {
+		t.Errorf("data = %s, want %s", response.Data, wantData)
+	}
+}
+
+func TestGraphQLListsPlatforms(t *testing.T) {
+	rec := httptest.NewRecorder()
+	target := "/graphql?query=" + url.QueryEscape("{ platforms { name displayName circuitState quota { limit } } }")
+	NewHandler(&stubBackend{}, testMaxSearches).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+
+	want := `{"data":{"platforms":[{"name":"github","displayName":"GitHub","circuitState":"HALF_OPEN","quota":null}]}}`
+	if got := strings.TrimSpace(rec.Body.String()); got != want {
+		t.Errorf("GET /graphql = %d %s, want %s", rec.Code, got, want)
+	}
+}
+
+func TestGraphQLLimitsSearchesPerOperation(t *testing.T) {
+	backend := &stubBackend{}
+	// Aliases and fragments each run search again
+	query := `{ a: search(input: {query: \"a\"}) { totalCount } b: search(input: {query: \"b\"}) { totalCount } ...more } ` +
+		`fragment more on Query { c: search(input: {query: \"c\"}) { totalCount } d: search(input: {query: \"d\"}) { totalCount } }`
+	response := postGraphQL(t, backend, `{"query": "`+query+`"}`)
+
+	if backend.searches != testMaxSearches {
+		t.Errorf("backend searched %d times, want %d", backend.searches, testMaxSearches)
+	}
+	if len(response.Errors) != 1 || response.Errors[0].Extensions["code"] != "InvalidArgument" ||
+		!strings.Contains(response.Errors[0].Message, "too many searches") {
+		t.Errorf("errors = %v, want one InvalidArgument for the search over the limit", response.Errors)
+	}
+}
+
+func TestGraphQLReportsGRPCCodes(t *testing.T) {
+	backend := &stubBackend{searchErr: status.Error(codes.InvalidArgument, "query is required")}
+	response := postGraphQL(t, backend, `{"query": "{ search(input: {query: \"\"}) { totalCount } }"}`)
+
+	if len(response.Errors) != 1 {
+		t.Fatalf("errors = %v, want one", response.Errors)
+	}
+	if got := response.Errors[0]; got.Message != "query is required" || got.Extensions["code"] != "InvalidArgument" {
+		t.Errorf("error = %+v, want the backend's message and code", got)
+	}
+}
@@ -0,0 +1,184 @@
+package httpapi
+
+import (
+	"sync"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/farhapartex/search-proxy/internal/version"
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+// openAPIDocument returns the OpenAPI 3 description of the /v1 endpoints.
+// The schemas are generated from the proto descriptors, so they follow the
+// messages as fields are added.
+var openAPIDocument = sync.OnceValue(func() map[string]any {
+	schemas := make(map[string]any)
+	search := schemaRef(schemas, (&pb.SearchRequest{}).ProtoReflect().Descriptor())
+	searchResponse := schemaRef(schemas, (&pb.SearchResponse{}).ProtoReflect().Descriptor())
+	healthResponse := schemaRef(schemas, (&pb.HealthCheckResponse{}).ProtoReflect().Descriptor())
+	schemas["Error"] = map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"code":    map[string]any{"type": "string", "description": "gRPC status code name"},
+			"message": map[string]any{"type": "string"},
+		},
+	}
+
+	errorResponse := func(description string) map[string]any {
+		return jsonResponse(description, map[string]any{"$ref": "#/components/schemas/Error"})
+	}
+	searchResponses := map[string]any{
+		"200": jsonResponse("Merged results from every platform searched", searchResponse),
+		"400": errorResponse("Invalid request"),
+		"503": errorResponse("Server is draining"),
+		"504": errorResponse("Search timed out"),
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "Search Proxy",
+			"version": version.Get().Version,
+		},
+		"paths": map[string]any{
+			"/v1/search": map[string]any{
+				"get": map[string]any{
+					"operationId": "FederatedSearchGet",
+					"summary":     "Search every requested platform at once",
+					"description": "Takes the scalar and repeated fields of SearchRequest as query parameters; " +
+						"repeated fields accept repeated or comma-separated values. Map and message fields need POST.",
+					"parameters": queryParameters((&pb.SearchRequest{}).ProtoReflect().Descriptor()),
+					"responses":  searchResponses,
+				},
+				"post": map[string]any{
+					"operationId": "FederatedSearch",
+					"summary":     "Search every requested platform at once",
+					"requestBody": map[string]any{
+						"required": true,
+						"content":  map[string]any{"application/json": map[string]any{"schema": search}},
+					},
+					"responses": searchResponses,
+				},
+			},
+			"/v1/health": map[string]any{
+				"get": map[string]any{
+					"operationId": "HealthCheck",
+					"summary":     "Report server health and build information",
+					"parameters": []any{map[string]any{
+						"name": "service", "in": "query", "schema": map[string]any{"type": "string"},
+					}},
+					"responses": map[string]any{
+						"200": jsonResponse("Server health", healthResponse),
+					},
+				},
+			},
+		},
+		"components": map[string]any{"schemas": schemas},
+	}
+})
+
+func jsonResponse(description string, schema map[string]any) map[string]any {
+	return map[string]any{
+		"description": description,
+		"content":     map[string]any{"application/json": map[string]any{"schema": schema}},
+	}
+}
+
+// queryParameters describes the fields fromQuery accepts
+func queryParameters(msg protoreflect.MessageDescriptor) []any {
+	params := []any{}
+	fields := msg.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		if field.IsMap() || field.Kind() == protoreflect.MessageKind {
+			continue
+		}
+		schema := scalarSchema(field)
+		if field.IsList() {
+			schema = map[string]any{"type": "array", "items": schema}
+		}
+		params = append(params, map[string]any{
+			"name":    string(field.Name()),
+			"in":      "query",
+			"schema":  schema,
+			"explode": true,
+		})
+	}
+	return params
+}
+
+// schemaRef adds the schema of msg, and of every message and enum it uses, to
+// schemas, and returns a reference to it
+func schemaRef(schemas map[string]any, msg protoreflect.MessageDescriptor) map[string]any {
+	name := string(msg.Name())
+	ref := map[string]any{"$ref": "#/components/schemas/" + name}
+	if _, ok := schemas[name]; ok {
+		return ref
+	}
+
+	properties := make(map[string]any)
+	schemas[name] = map[string]any{"type": "object", "properties": properties}
+	fields := msg.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		properties[string(field.Name())] = fieldSchema(schemas, field)
+	}
+	return ref
+}
+
+func fieldSchema(schemas map[string]any, field protoreflect.FieldDescriptor) map[string]any {
+	switch {
+	case field.IsMap():
+		return map[string]any{
+			"type":                 "object",
+			"additionalProperties": singleSchema(schemas, field.MapValue()),
+		}
+	case field.IsList():
+		return map[string]any{"type": "array", "items": singleSchema(schemas, field)}
+	}
+	return singleSchema(schemas, field)
+}
+
+func singleSchema(schemas map[string]any, field protoreflect.FieldDescriptor) map[string]any {
+	switch field.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return schemaRef(schemas, field.Message())
+	case protoreflect.EnumKind:
+		name := string(field.Enum().Name())
+		if _, ok := schemas[name]; !ok {
+			schemas[name] = scalarSchema(field)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + name}
+	}
+	return scalarSchema(field)
+}
+
+// scalarSchema follows the protobuf JSON mapping, in which 64-bit integers
+// are strings
+func scalarSchema(field protoreflect.FieldDescriptor) map[string]any {
+	switch field.Kind() {
+	case protoreflect.BoolKind:
+		return map[string]any{"type": "boolean"}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return map[string]any{"type": "integer", "format": "int32"}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return map[string]any{"type": "string", "format": "int64"}
+	case protoreflect.FloatKind:
+		return map[string]any{"type": "number", "format": "float"}
+	case protoreflect.DoubleKind:
+		return map[string]any{"type": "number", "format": "double"}
+	case protoreflect.BytesKind:
+		return map[string]any{"type": "string", "format": "byte"}
+	case protoreflect.EnumKind:
+		values := field.Enum().Values()
+		names := make([]string, values.Len())
+		for i := range names {
+			names[i] = string(values.Get(i).Name())
+		}
+		return map[string]any{"type": "string", "enum": names}
+	}
+	return map[string]any{"type": "string"}
+}
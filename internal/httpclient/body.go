@@ -0,0 +1,46 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrBodyTooLarge is returned when an upstream response body exceeds the
+// configured maximum
+var ErrBodyTooLarge = errors.New("response body too large")
+
+// limitedBody caps how much of an upstream response body can be read. Unlike
+// a bare io.LimitReader it fails loudly instead of silently truncating, so a
+// decoder never mistakes a cut-off payload for a short one.
+type limitedBody struct {
+	body      io.ReadCloser
+	reader    io.Reader
+	remaining int64
+	max       int64
+}
+
+func newLimitedBody(body io.ReadCloser, max int64) *limitedBody {
+	return &limitedBody{
+		body: body,
+		// Read one byte past the limit to tell "exactly max" from "more"
+		reader:    io.LimitReader(body, max+1),
+		remaining: max,
+		max:       max,
+	}
+}
+
+func (l *limitedBody) Read(p []byte) (int, error) {
+	n, err := l.reader.Read(p)
+	if int64(n) > l.remaining {
+		n = int(l.remaining)
+		l.remaining = 0
+		return n, fmt.Errorf("%w (limit %d bytes)", ErrBodyTooLarge, l.max)
+	}
+	l.remaining -= int64(n)
+	return n, err
+}
+
+func (l *limitedBody) Close() error {
+	return l.body.Close()
+}
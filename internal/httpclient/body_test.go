@@ -0,0 +1,83 @@
+package httpclient
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLimitedBody(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		max     int64
+		wantErr bool
+	}{
+		{name: "under the limit", body: "hello", max: 10},
+		{name: "exactly the limit", body: "0123456789", max: 10},
+		{name: "one byte over", body: "0123456789a", max: 10, wantErr: true},
+		{name: "far over", body: strings.Repeat("x", 1<<16), max: 10, wantErr: true},
+		{name: "empty", body: "", max: 10},
+	}
+	for _, tt := range tests {
+		got, err := io.ReadAll(newLimitedBody(io.NopCloser(strings.NewReader(tt.body)), tt.max))
+		if tt.wantErr {
+			if !errors.Is(err, ErrBodyTooLarge) {
+				t.Errorf("%s: ReadAll() error = %v, want ErrBodyTooLarge", tt.name, err)
+			}
+			if int64(len(got)) > tt.max {
+				t.Errorf("%s: read %d bytes, want at most %d", tt.name, len(got), tt.max)
+			}
+			continue
+		}
+		if err != nil || string(got) != tt.body {
+			t.Errorf("%s: ReadAll() = %q, %v; want the whole body", tt.name, got, err)
+		}
+	}
+}
+
+func TestClientLimitsResponseBodies(t *testing.T) {
+	payload, _ := json.Marshal(map[string]string{"data": strings.Repeat("x", 4096)})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(payload)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		max     int64
+		wantErr bool
+	}{
+		{name: "fits", max: 8192},
+		{name: "too large", max: 1024, wantErr: true},
+		{name: "unlimited", max: 0},
+	}
+	for _, tt := range tests {
+		opts := DefaultOptions()
+		opts.MaxResponseBytes = tt.max
+		client, err := New(opts, NewMetrics())
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("%s: Get() error = %v", tt.name, err)
+		}
+		var decoded map[string]string
+		err = json.NewDecoder(resp.Body).Decode(&decoded)
+		resp.Body.Close()
+
+		if tt.wantErr {
+			if !errors.Is(err, ErrBodyTooLarge) {
+				t.Errorf("%s: Decode() error = %v, want ErrBodyTooLarge", tt.name, err)
+			}
+		} else if err != nil || len(decoded["data"]) != 4096 {
+			t.Errorf("%s: Decode() = %d bytes, %v; want the whole payload", tt.name, len(decoded["data"]), err)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package httpclient
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/farhapartex/search-proxy/internal/replay"
+)
+
+// Options configures the shared upstream HTTP client
+type Options struct {
+	// Timeout is the hard upper bound for a single request, including body read.
+	// Per-request deadlines are still driven by the caller's context.
+	Timeout time.Duration
+
+	// ProxyURL routes all upstream traffic through an HTTP(S) proxy when set.
+	// When empty, the standard HTTP_PROXY/HTTPS_PROXY environment is honored.
+	ProxyURL string
+
+	// MaxResponseBytes caps how much of any response body may be read.
+	// Zero means unlimited.
+	MaxResponseBytes int64
+
+	// Resolver, when set, serves DNS lookups from its cache
+	Resolver *Resolver
+
+	// VCRMode records upstream responses to VCRDir ("record") or serves
+	// them from there without network access ("replay"). Empty or "off"
+	// disables it.
+	VCRMode string
+	VCRDir  string
+
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	MaxConnsPerHost     int
+	IdleConnTimeout     time.Duration
+	TLSHandshakeTimeout time.Duration
+	DialTimeout         time.Duration
+	KeepAlive           time.Duration
+}
+
+// DefaultOptions returns options tuned for many short requests to a small set of hosts
+func DefaultOptions() Options {
+	return Options{
+		Timeout:             10 * time.Second,
+		MaxResponseBytes:    2 << 20,
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 20,
+		MaxConnsPerHost:     50,
+		IdleConnTimeout:     90 * time.Second,
+		TLSHandshakeTimeout: 5 * time.Second,
+		DialTimeout:         5 * time.Second,
+		KeepAlive:           30 * time.Second,
+	}
+}
+
+// New builds an instrumented HTTP client. Every request made through it is
+// recorded in metrics, keyed by upstream host.
+func New(opts Options, metrics *Metrics) (*http.Client, error) {
+	transport, err := newTransport(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	next, err := replay.NewTransport(transport, opts.VCRMode, opts.VCRDir, opts.MaxResponseBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Client{
+		Timeout: opts.Timeout,
+		Transport: &instrumentedTransport{
+			next:             next,
+			metrics:          metrics,
+			maxResponseBytes: opts.MaxResponseBytes,
+		},
+	}, nil
+}
+
+func newTransport(opts Options) (*http.Transport, error) {
+	proxy := http.ProxyFromEnvironment
+	if opts.ProxyURL != "" {
+		proxyURL, err := url.Parse(opts.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL %q: %w", opts.ProxyURL, err)
+		}
+		proxy = http.ProxyURL(proxyURL)
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   opts.DialTimeout,
+		KeepAlive: opts.KeepAlive,
+	}
+
+	dial := dialer.DialContext
+	if opts.Resolver != nil {
+		dial = opts.Resolver.dialContext(dial)
+	}
+
+	return &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           dial,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          opts.MaxIdleConns,
+		MaxIdleConnsPerHost:   opts.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       opts.MaxConnsPerHost,
+		IdleConnTimeout:       opts.IdleConnTimeout,
+		TLSHandshakeTimeout:   opts.TLSHandshakeTimeout,
+		ExpectContinueTimeout: 1 * time.Second,
+	}, nil
+}
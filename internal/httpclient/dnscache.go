@@ -0,0 +1,155 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"sync"
+	"time"
+)
+
+// Resolver caches upstream host lookups and refreshes them in the background,
+// so DNS latency stays off the request path and a DNS outage keeps serving
+// the last known addresses
+type Resolver struct {
+	lookupHost func(ctx context.Context, host string) ([]string, error)
+	ttl        time.Duration
+
+	mu      sync.RWMutex
+	entries map[string]*dnsEntry
+
+	stop chan struct{}
+	once sync.Once
+}
+
+type dnsEntry struct {
+	addrs     []string
+	expiresAt time.Time
+}
+
+// NewResolver creates a caching resolver and starts its refresh loop, which
+// re-resolves every cached host each ttl/2
+func NewResolver(ttl time.Duration) *Resolver {
+	return newResolver(net.DefaultResolver.LookupHost, ttl)
+}
+
+func newResolver(lookupHost func(ctx context.Context, host string) ([]string, error), ttl time.Duration) *Resolver {
+	r := &Resolver{
+		lookupHost: lookupHost,
+		ttl:        ttl,
+		entries:    make(map[string]*dnsEntry),
+		stop:       make(chan struct{}),
+	}
+	go r.refreshLoop()
+	return r
+}
+
+// Prewarm resolves hosts ahead of the first request
+func (r *Resolver) Prewarm(ctx context.Context, hosts ...string) {
+	for _, host := range hosts {
+		if _, err := r.lookup(ctx, host); err != nil {
+			log.Printf("WARNING: DNS pre-resolution of %s failed: %v", host, err)
+		}
+	}
+}
+
+// Close stops the background refresh
+func (r *Resolver) Close() {
+	r.once.Do(func() { close(r.stop) })
+}
+
+// LookupHost returns the cached addresses for host, resolving on a miss
+func (r *Resolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	if net.ParseIP(host) != nil {
+		return []string{host}, nil
+	}
+
+	r.mu.RLock()
+	entry, ok := r.entries[host]
+	r.mu.RUnlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.addrs, nil
+	}
+
+	addrs, err := r.lookup(ctx, host)
+	if err != nil && ok {
+		// Serve stale addresses rather than failing during a DNS outage
+		return entry.addrs, nil
+	}
+	return addrs, err
+}
+
+func (r *Resolver) lookup(ctx context.Context, host string) ([]string, error) {
+	addrs, err := r.lookupHost(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("no addresses found for %s", host)
+	}
+
+	r.mu.Lock()
+	r.entries[host] = &dnsEntry{addrs: addrs, expiresAt: time.Now().Add(r.ttl)}
+	r.mu.Unlock()
+
+	return addrs, nil
+}
+
+func (r *Resolver) refreshLoop() {
+	ticker := time.NewTicker(r.ttl / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+		}
+
+		r.mu.RLock()
+		hosts := make([]string, 0, len(r.entries))
+		for host := range r.entries {
+			hosts = append(hosts, host)
+		}
+		r.mu.RUnlock()
+
+		for _, host := range hosts {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			if _, err := r.lookup(ctx, host); err != nil {
+				log.Printf("WARNING: DNS refresh of %s failed, keeping cached addresses: %v", host, err)
+			}
+			cancel()
+		}
+	}
+}
+
+// dialContext wraps dial so host names are resolved through the cache. Each
+// cached address is tried in turn until one connects.
+func (r *Resolver) dialContext(dial func(ctx context.Context, network, address string) (net.Conn, error)) func(ctx context.Context, network, address string) (net.Conn, error) {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, err
+		}
+
+		addrs, err := r.LookupHost(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+
+		var errs []error
+		for _, addr := range addrs {
+			conn, err := dial(ctx, network, net.JoinHostPort(addr, port))
+			if err == nil {
+				return conn, nil
+			}
+			errs = append(errs, err)
+			if ctx.Err() != nil {
+				break
+			}
+		}
+		return nil, errors.Join(errs...)
+	}
+}
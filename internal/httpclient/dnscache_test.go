@@ -0,0 +1,92 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeDNS answers lookups with addrs, counting them
+type fakeDNS struct {
+	mu      sync.Mutex
+	addrs   []string
+	err     error
+	lookups int
+}
+
+func (d *fakeDNS) lookupHost(ctx context.Context, host string) ([]string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.lookups++
+	return d.addrs, d.err
+}
+
+func (d *fakeDNS) set(addrs []string, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.addrs, d.err = addrs, err
+}
+
+func (d *fakeDNS) count() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.lookups
+}
+
+func (r *Resolver) cached(host string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if entry, ok := r.entries[host]; ok {
+		return entry.addrs
+	}
+	return nil
+}
+
+func TestResolverRefreshesUntilClosed(t *testing.T) {
+	dns := &fakeDNS{addrs: []string{"10.0.0.1"}}
+	r := newResolver(dns.lookupHost, 20*time.Millisecond)
+
+	if addrs, err := r.LookupHost(context.Background(), "api.example.com"); err != nil || !slices.Equal(addrs, []string{"10.0.0.1"}) {
+		t.Fatalf("LookupHost() = %v, %v", addrs, err)
+	}
+
+	// The refresh loop picks up the change without any lookup of ours
+	dns.set([]string{"10.0.0.2"}, nil)
+	deadline := time.Now().Add(time.Second)
+	for !slices.Equal(r.cached("api.example.com"), []string{"10.0.0.2"}) {
+		if time.Now().After(deadline) {
+			t.Fatalf("cached addresses = %v, want the refreshed 10.0.0.2", r.cached("api.example.com"))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	r.Close()
+	r.Close()
+	// A refresh already running may still finish
+	time.Sleep(20 * time.Millisecond)
+	stopped := dns.count()
+	time.Sleep(50 * time.Millisecond)
+	if n := dns.count(); n != stopped {
+		t.Errorf("%d lookups after Close, want none", n-stopped)
+	}
+}
+
+func TestResolverServesStaleAddressesDuringOutage(t *testing.T) {
+	dns := &fakeDNS{addrs: []string{"10.0.0.1"}}
+	r := newResolver(dns.lookupHost, time.Millisecond)
+	defer r.Close()
+
+	r.LookupHost(context.Background(), "api.example.com")
+	dns.set(nil, errors.New("no such host"))
+	time.Sleep(5 * time.Millisecond)
+
+	if addrs, err := r.LookupHost(context.Background(), "api.example.com"); err != nil || !slices.Equal(addrs, []string{"10.0.0.1"}) {
+		t.Errorf("LookupHost() during outage = %v, %v; want the last known address", addrs, err)
+	}
+	if _, err := r.LookupHost(context.Background(), "new.example.com"); err == nil {
+		t.Error("LookupHost() of an uncached host during outage succeeded")
+	}
+}
@@ -0,0 +1,211 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/farhapartex/search-proxy/internal/replay"
+	"github.com/farhapartex/search-proxy/internal/requestid"
+	"github.com/farhapartex/search-proxy/internal/tracing"
+)
+
+// HostStats is a point-in-time view of the traffic sent to one upstream host
+type HostStats struct {
+	Requests     int64
+	Errors       int64
+	StatusCodes  map[int]int64
+	ReusedConns  int64
+	TotalLatency time.Duration
+	MaxLatency   time.Duration
+	DNSTime      time.Duration
+	ConnectTime  time.Duration
+	TLSTime      time.Duration
+}
+
+// AvgLatency returns the mean round-trip latency for the host
+func (s HostStats) AvgLatency() time.Duration {
+	if s.Requests == 0 {
+		return 0
+	}
+	return s.TotalLatency / time.Duration(s.Requests)
+}
+
+// Metrics collects per-host request statistics for instrumented clients
+type Metrics struct {
+	mu    sync.Mutex
+	hosts map[string]*HostStats
+}
+
+// NewMetrics creates an empty metrics collector
+func NewMetrics() *Metrics {
+	return &Metrics{
+		hosts: make(map[string]*HostStats),
+	}
+}
+
+// Snapshot returns a copy of the statistics for every host seen so far
+func (m *Metrics) Snapshot() map[string]HostStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot := make(map[string]HostStats, len(m.hosts))
+	for host, stats := range m.hosts {
+		copied := *stats
+		copied.StatusCodes = make(map[int]int64, len(stats.StatusCodes))
+		for code, count := range stats.StatusCodes {
+			copied.StatusCodes[code] = count
+		}
+		snapshot[host] = copied
+	}
+	return snapshot
+}
+
+func (m *Metrics) record(host string, trace *connTrace, latency time.Duration, statusCode int, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats, ok := m.hosts[host]
+	if !ok {
+		stats = &HostStats{StatusCodes: make(map[int]int64)}
+		m.hosts[host] = stats
+	}
+
+	stats.Requests++
+	stats.TotalLatency += latency
+	if latency > stats.MaxLatency {
+		stats.MaxLatency = latency
+	}
+	if err != nil {
+		stats.Errors++
+	} else {
+		stats.StatusCodes[statusCode]++
+	}
+
+	trace.mu.Lock()
+	defer trace.mu.Unlock()
+	if trace.reused {
+		stats.ReusedConns++
+	}
+	stats.DNSTime += trace.dns
+	stats.ConnectTime += trace.connect
+	stats.TLSTime += trace.tls
+}
+
+// connTrace captures connection-level timings for a single request. Dial
+// hooks can fire on the transport's dial goroutine, hence the mutex.
+type connTrace struct {
+	mu                            sync.Mutex
+	reused                        bool
+	dnsStart, connStart, tlsStart time.Time
+	dns, connect, tls             time.Duration
+}
+
+func (c *connTrace) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			c.reused = info.Reused
+		},
+		DNSStart: func(httptrace.DNSStartInfo) {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			c.dnsStart = time.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			c.dns = time.Since(c.dnsStart)
+		},
+		ConnectStart: func(string, string) {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			c.connStart = time.Now()
+		},
+		ConnectDone: func(string, string, error) {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			c.connect = time.Since(c.connStart)
+		},
+		TLSHandshakeStart: func() {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			c.tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			c.tls = time.Since(c.tlsStart)
+		},
+	}
+}
+
+// instrumentedTransport records metrics, connection traces and a client span
+// for each request and bounds the size of every response body
+type instrumentedTransport struct {
+	next             http.RoundTripper
+	metrics          *Metrics
+	maxResponseBytes int64
+}
+
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, span := tracing.Tracer().Start(req.Context(), "HTTP "+req.Method,
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+		oteltrace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(req.Method),
+			semconv.URLFull(replay.RedactURL(req.URL)),
+			semconv.ServerAddress(req.URL.Hostname()),
+			attribute.String("request.id", requestid.FromContext(req.Context())),
+		))
+	defer span.End()
+	req = req.WithContext(ctx)
+
+	if t.metrics == nil {
+		resp, err := t.next.RoundTrip(req)
+		endSpan(span, resp, err)
+		return t.limit(resp, err)
+	}
+
+	trace := &connTrace{}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace.clientTrace()))
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	endSpan(span, resp, err)
+
+	statusCode := 0
+	if resp != nil {
+		statusCode = resp.StatusCode
+	}
+	t.metrics.record(req.URL.Host, trace, time.Since(start), statusCode, err)
+
+	return t.limit(resp, err)
+}
+
+// endSpan records the outcome of a request on its span
+func endSpan(span oteltrace.Span, resp *http.Response, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return
+	}
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+	if resp.StatusCode >= 400 {
+		span.SetStatus(codes.Error, resp.Status)
+	}
+}
+
+func (t *instrumentedTransport) limit(resp *http.Response, err error) (*http.Response, error) {
+	if err == nil && t.maxResponseBytes > 0 {
+		resp.Body = newLimitedBody(resp.Body, t.maxResponseBytes)
+	}
+	return resp, err
+}
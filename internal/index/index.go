@@ -0,0 +1,138 @@
+package index
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/mapping"
+	"github.com/blevesearch/bleve/v2/search/query"
+
+	"github.com/farhapartex/search-proxy/internal/models"
+)
+
+// Index is an embedded full-text index of previously fetched results. It lets
+// repeat and refined queries be answered locally when an upstream is slow or down.
+type Index struct {
+	idx bleve.Index
+}
+
+// document is the indexed form of a search result. Raw holds the full result
+// so it can be rebuilt exactly without storing every field separately.
+type document struct {
+	Platform  string `json:"platform"`
+	Title     string `json:"title"`
+	Snippet   string `json:"snippet"`
+	Timestamp int64  `json:"timestamp"`
+	Raw       string `json:"raw"`
+}
+
+// Open opens the index at path, creating it if needed. An empty path keeps
+// the index in memory only.
+func Open(path string) (*Index, error) {
+	if path == "" {
+		idx, err := bleve.NewMemOnly(newMapping())
+		if err != nil {
+			return nil, fmt.Errorf("failed to create in-memory index: %w", err)
+		}
+		return &Index{idx: idx}, nil
+	}
+
+	idx, err := bleve.Open(path)
+	if err == bleve.ErrorIndexPathDoesNotExist {
+		idx, err = bleve.New(path, newMapping())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open index at %s: %w", path, err)
+	}
+	return &Index{idx: idx}, nil
+}
+
+func newMapping() mapping.IndexMapping {
+	keyword := bleve.NewKeywordFieldMapping()
+
+	text := bleve.NewTextFieldMapping()
+	text.Store = false
+
+	raw := bleve.NewTextFieldMapping()
+	raw.Index = false
+	raw.IncludeInAll = false
+
+	numeric := bleve.NewNumericFieldMapping()
+
+	doc := bleve.NewDocumentMapping()
+	doc.AddFieldMappingsAt("platform", keyword)
+	doc.AddFieldMappingsAt("title", text)
+	doc.AddFieldMappingsAt("snippet", text)
+	doc.AddFieldMappingsAt("timestamp", numeric)
+	doc.AddFieldMappingsAt("raw", raw)
+
+	m := bleve.NewIndexMapping()
+	m.DefaultMapping = doc
+	return m
+}
+
+// Add indexes results, replacing any earlier copy of the same platform and URL
+func (i *Index) Add(results []*models.SearchResult) error {
+	batch := i.idx.NewBatch()
+	for _, result := range results {
+		raw, err := json.Marshal(result)
+		if err != nil {
+			return fmt.Errorf("failed to encode result: %w", err)
+		}
+
+		doc := document{
+			Platform:  result.Platform,
+			Title:     result.Title,
+			Snippet:   result.Snippet,
+			Timestamp: result.Timestamp,
+			Raw:       string(raw),
+		}
+		if err := batch.Index(result.Platform+":"+result.URL, doc); err != nil {
+			return fmt.Errorf("failed to index result: %w", err)
+		}
+	}
+
+	return i.idx.Batch(batch)
+}
+
+// Search returns up to limit indexed results for platform that match query
+func (i *Index) Search(ctx context.Context, queryText, platform string, limit int) ([]*models.SearchResult, error) {
+	match := bleve.NewMatchQuery(queryText)
+
+	platformQuery := bleve.NewTermQuery(platform)
+	platformQuery.SetField("platform")
+
+	req := bleve.NewSearchRequestOptions(
+		bleve.NewConjunctionQuery([]query.Query{match, platformQuery}...),
+		limit, 0, false,
+	)
+	req.Fields = []string{"raw"}
+
+	res, err := i.idx.SearchInContext(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("index search failed: %w", err)
+	}
+
+	results := make([]*models.SearchResult, 0, len(res.Hits))
+	for _, hit := range res.Hits {
+		raw, ok := hit.Fields["raw"].(string)
+		if !ok {
+			continue
+		}
+
+		var result models.SearchResult
+		if err := json.Unmarshal([]byte(raw), &result); err != nil {
+			return nil, fmt.Errorf("failed to decode indexed result: %w", err)
+		}
+		results = append(results, &result)
+	}
+
+	return results, nil
+}
+
+// Close releases the index
+func (i *Index) Close() error {
+	return i.idx.Close()
+}
@@ -0,0 +1,33 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/farhapartex/search-proxy/internal/requestid"
+)
+
+// levelPrefixes are the prefixes Printf keeps at the start of a line, so the
+// filter still sees its level
+var levelPrefixes = []string{"DEBUG: ", "WARNING: ", "ERROR: "}
+
+// Printf logs like log.Printf, adding the request ID ctx carries after the
+// line's level prefix so the lines of one call can be found together
+func Printf(ctx context.Context, format string, args ...any) {
+	log.Output(2, withRequestID(ctx, fmt.Sprintf(format, args...)))
+}
+
+func withRequestID(ctx context.Context, msg string) string {
+	id := requestid.FromContext(ctx)
+	if id == "" {
+		return msg
+	}
+	for _, prefix := range levelPrefixes {
+		if rest, ok := strings.CutPrefix(msg, prefix); ok {
+			return prefix + "request_id=" + id + " " + rest
+		}
+	}
+	return "request_id=" + id + " " + msg
+}
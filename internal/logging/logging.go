@@ -0,0 +1,102 @@
+// Package logging filters the standard logger's output by level, so the
+// level can change while the server runs. The code logs through the log
+// package and marks a line's level with its prefix: "DEBUG: ", "WARNING: "
+// or "ERROR: ", and no prefix for info.
+package logging
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Level is how severe a log line is
+type Level int32
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = [...]string{"debug", "info", "warn", "error"}
+
+func (l Level) String() string {
+	if l < LevelDebug || l > LevelError {
+		return fmt.Sprintf("Level(%d)", int32(l))
+	}
+	return levelNames[l]
+}
+
+// ParseLevel parses "debug", "info", "warn" or "error"
+func ParseLevel(s string) (Level, error) {
+	for i, name := range levelNames {
+		if s == name {
+			return Level(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown log level %q (valid: debug, info, warn, error)", s)
+}
+
+var level atomic.Int32
+
+func init() {
+	level.Store(int32(LevelInfo))
+}
+
+// SetLevel drops lines below l from then on and returns the previous level
+func SetLevel(l Level) Level {
+	return Level(level.Swap(int32(l)))
+}
+
+// CurrentLevel returns the level lines are filtered at
+func CurrentLevel() Level {
+	return Level(level.Load())
+}
+
+// Install routes the standard logger through the level filter, writing the
+// lines it keeps to out with the logger's usual timestamp
+func Install(out io.Writer) {
+	log.SetFlags(0)
+	log.SetOutput(&filter{out: out, now: time.Now})
+}
+
+// filter writes the lines at or above the current level. With the logger's
+// flags cleared each Write is one line starting with its level prefix, and
+// the filter adds the timestamp itself.
+type filter struct {
+	mu  sync.Mutex
+	out io.Writer
+	now func() time.Time
+}
+
+func (f *filter) Write(p []byte) (int, error) {
+	if lineLevel(p) < CurrentLevel() {
+		return len(p), nil
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	line := f.now().AppendFormat(make([]byte, 0, 20+len(p)), "2006/01/02 15:04:05 ")
+	if _, err := f.out.Write(append(line, p...)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func lineLevel(line []byte) Level {
+	switch {
+	case bytes.HasPrefix(line, []byte("DEBUG: ")):
+		return LevelDebug
+	case bytes.HasPrefix(line, []byte("WARNING: ")):
+		return LevelWarn
+	case bytes.HasPrefix(line, []byte("ERROR: ")):
+		return LevelError
+	}
+	return LevelInfo
+}
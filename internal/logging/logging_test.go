@@ -0,0 +1,61 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/farhapartex/search-proxy/internal/requestid"
+)
+
+func TestFilterDropsLinesBelowLevel(t *testing.T) {
+	defer SetLevel(SetLevel(LevelWarn))
+
+	var out bytes.Buffer
+	f := &filter{out: &out, now: func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) }}
+	for _, line := range []string{
+		"DEBUG: cache miss\n",
+		"Platform github returned 5 results\n",
+		"WARNING: Platform reddit timed out\n",
+		"ERROR: Failed to serve\n",
+	} {
+		if n, err := f.Write([]byte(line)); err != nil || n != len(line) {
+			t.Fatalf("Write(%q) = %d, %v", line, n, err)
+		}
+	}
+
+	want := "2026/01/02 03:04:05 WARNING: Platform reddit timed out\n" +
+		"2026/01/02 03:04:05 ERROR: Failed to serve\n"
+	if got := out.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for _, name := range []string{"debug", "info", "warn", "error"} {
+		level, err := ParseLevel(name)
+		if err != nil || level.String() != name {
+			t.Errorf("ParseLevel(%q) = %v, %v", name, level, err)
+		}
+	}
+	if _, err := ParseLevel("verbose"); err == nil || !strings.Contains(err.Error(), "verbose") {
+		t.Errorf("ParseLevel(%q) error = %v, want it rejected", "verbose", err)
+	}
+}
+
+func TestWithRequestIDKeepsLevelPrefix(t *testing.T) {
+	ctx := requestid.NewContext(context.Background(), "abc")
+	for msg, want := range map[string]string{
+		"WARNING: Platform reddit timed out": "WARNING: request_id=abc Platform reddit timed out",
+		"Platform github returned 5 results": "request_id=abc Platform github returned 5 results",
+	} {
+		if got := withRequestID(ctx, msg); got != want {
+			t.Errorf("withRequestID(%q) = %q, want %q", msg, got, want)
+		}
+	}
+	if got := withRequestID(context.Background(), "DEBUG: cache miss"); got != "DEBUG: cache miss" {
+		t.Errorf("withRequestID() without an ID = %q, want the line unchanged", got)
+	}
+}
@@ -1,8 +1,11 @@
 package models
 
 import (
+	"maps"
 	"time"
 
+	"google.golang.org/protobuf/proto"
+
 	pb "github.com/farhapartex/search-proxy/proto"
 )
 
@@ -14,7 +17,37 @@ type SearchResult struct {
 	Snippet   string
 	URL       string
 	Timestamp int64
-	Metadata  map[string]string
+	CreatedAt int64
+	UpdatedAt int64
+	// ThumbnailURL is an optional preview image
+	ThumbnailURL string
+	Author       *Author
+	Type         pb.ResultType
+	Metadata     map[string]string
+	// GitHub, StackOverflow, Reddit and DockerHub are the typed metadata of
+	// the platform the result came from; at most one is set
+	GitHub        *pb.GitHubMeta
+	StackOverflow *pb.StackOverflowMeta
+	Reddit        *pb.RedditMeta
+	DockerHub     *pb.DockerHubMeta
+	// FetchedAt is when the result was fetched from its platform, in Unix
+	// seconds; it travels with the result into the local index
+	FetchedAt int64
+}
+
+// Author identifies who created a result
+type Author struct {
+	Name       string `json:"name"`
+	Handle     string `json:"handle"`
+	ProfileURL string `json:"profile_url"`
+}
+
+// ToProto converts the author, keeping nil as nil
+func (a *Author) ToProto() *pb.Author {
+	if a == nil {
+		return nil
+	}
+	return &pb.Author{Name: a.Name, Handle: a.Handle, ProfileUrl: a.ProfileURL}
 }
 
 func NewSearchResult(platform, title, snippet, url string) *SearchResult {
@@ -28,23 +61,102 @@ func NewSearchResult(platform, title, snippet, url string) *SearchResult {
 	}
 }
 
+// SetTimes records when the resource was created and last updated, both in
+// Unix seconds. A zero updated time means it never changed.
+func (r *SearchResult) SetTimes(created, updated int64) {
+	if updated < created {
+		updated = created
+	}
+	r.Timestamp = created
+	r.CreatedAt = created
+	r.UpdatedAt = updated
+}
+
+// ToProto converts the result. The protobuf result gets its own copies of the
+// metadata and platform details: the search pipeline edits them while the
+// original may still be read elsewhere, such as by the background indexer.
 func (r *SearchResult) ToProto() *pb.Result {
-	return &pb.Result{
-		Platform:  r.Platform,
-		Title:     r.Title,
-		Snippet:   r.Snippet,
-		Url:       r.URL,
-		Timestamp: r.Timestamp,
-		Metadata:  r.Metadata,
+	// Results indexed before created/updated times existed only carry the
+	// timestamp
+	createdAt, updatedAt := r.CreatedAt, r.UpdatedAt
+	if createdAt == 0 {
+		createdAt = r.Timestamp
+	}
+	if updatedAt < createdAt {
+		updatedAt = createdAt
+	}
+
+	result := &pb.Result{
+		Platform:     r.Platform,
+		Title:        r.Title,
+		Snippet:      r.Snippet,
+		Url:          r.URL,
+		Timestamp:    r.Timestamp,
+		Metadata:     maps.Clone(r.Metadata),
+		CreatedAt:    createdAt,
+		UpdatedAt:    updatedAt,
+		ThumbnailUrl: r.ThumbnailURL,
+		Author:       r.Author.ToProto(),
+		ResultType:   r.Type,
+	}
+	switch {
+	case r.GitHub != nil:
+		result.PlatformDetails = &pb.Result_Github{Github: proto.Clone(r.GitHub).(*pb.GitHubMeta)}
+	case r.StackOverflow != nil:
+		result.PlatformDetails = &pb.Result_Stackoverflow{Stackoverflow: proto.Clone(r.StackOverflow).(*pb.StackOverflowMeta)}
+	case r.Reddit != nil:
+		result.PlatformDetails = &pb.Result_Reddit{Reddit: proto.Clone(r.Reddit).(*pb.RedditMeta)}
+	case r.DockerHub != nil:
+		result.PlatformDetails = &pb.Result_Dockerhub{Dockerhub: proto.Clone(r.DockerHub).(*pb.DockerHubMeta)}
+	}
+	return result
+}
+
+// UnixSeconds converts an upstream time to Unix seconds in UTC, mapping the
+// zero time (a missing field) to 0
+func UnixSeconds(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UTC().Unix()
+}
+
+// UnixSecondsFloat converts fractional Unix seconds, as some APIs report
+// them, to whole seconds
+func UnixSecondsFloat(seconds float64) int64 {
+	return int64(seconds)
+}
+
+// AgeDays returns the whole days between created and now, or 0 when the
+// creation time is unknown or in the future
+func AgeDays(created int64, now time.Time) int32 {
+	if created <= 0 {
+		return 0
+	}
+	age := now.Sub(time.Unix(created, 0))
+	if age < 0 {
+		return 0
 	}
+	return int32(age / (24 * time.Hour))
 }
 
 type FetchResult struct {
 	Platform string
 	Results  []*SearchResult
-	Error    error
-	Duration time.Duration
-	TimedOut bool
+	// ProtoResults is set instead of Results when the fetcher converted
+	// straight to protobuf
+	ProtoResults []*pb.Result
+	Error        error
+	Duration     time.Duration
+	TimedOut     bool
+	// Query is set when the platform was sent a shortened query
+	Query string
+	// SnippetFormat is the format of the platform's snippets, so they can
+	// be shortened without breaking markup
+	SnippetFormat string
+	// NextCursor is where the platform's next page starts, or empty when
+	// it has no more results
+	NextCursor string
 }
 
 func NewFetchResult(platform string) *FetchResult {
@@ -0,0 +1,173 @@
+// Package query parses the small search DSL clients can mix into their
+// queries, such as `lang:go stars:>100 tag:concurrency after:2023-01-01`,
+// so each fetcher can translate the terms into its platform's own syntax.
+//
+// A term is a field, a colon and a value, written as one word:
+//
+//	lang:go              language of the code or question
+//	stars:>100           star count: N, >N, >=N, <N, <=N or N..M
+//	tag:concurrency      topic or tag
+//	subreddit:golang     subreddit
+//	after:2023-01-01     created after the date
+//	before:2024-06-30    created before the date
+//
+// Anything else, including words in quotes and qualifiers this package
+// doesn't know such as `org:golang`, is left in the free text. A term
+// whose value doesn't parse is free text too, so the upstream still sees
+// what the client typed.
+package query
+
+import (
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Field is the name of a DSL term
+type Field string
+
+const (
+	Lang      Field = "lang"
+	Stars     Field = "stars"
+	Tag       Field = "tag"
+	Subreddit Field = "subreddit"
+	After     Field = "after"
+	Before    Field = "before"
+)
+
+// DateLayout is how after and before dates are written
+const DateLayout = "2006-01-02"
+
+var (
+	starsPattern = regexp.MustCompile(`^(?:(?:>=|<=|>|<)?\d+|\d+\.\.\d+)$`)
+	namePattern  = regexp.MustCompile(`^[\p{L}\p{N}_.+#-]+$`)
+)
+
+// valid reports whether value is well formed for field
+func (f Field) valid(value string) bool {
+	switch f {
+	case Lang, Tag, Subreddit:
+		return namePattern.MatchString(value)
+	case Stars:
+		return starsPattern.MatchString(value)
+	case After, Before:
+		_, err := time.Parse(DateLayout, value)
+		return err == nil
+	}
+	return false
+}
+
+// Term is one field:value pair of the DSL
+type Term struct {
+	Field Field
+	Value string
+}
+
+// Query is a search split into its free text and its DSL terms
+type Query struct {
+	// Text is the query without the terms. It is the query exactly as
+	// given when it has no terms.
+	Text  string
+	Terms []Term
+}
+
+// Parse splits the DSL terms out of query
+func Parse(query string) Query {
+	var q Query
+	var text []string
+	for _, word := range words(query) {
+		if term, ok := parseTerm(word); ok {
+			q.Terms = append(q.Terms, term)
+		} else {
+			text = append(text, word)
+		}
+	}
+
+	if len(q.Terms) == 0 {
+		q.Text = query
+	} else {
+		q.Text = strings.Join(text, " ")
+	}
+	return q
+}
+
+// parseTerm reads word as a term, if it is one
+func parseTerm(word string) (Term, bool) {
+	name, value, found := strings.Cut(word, ":")
+	if !found || value == "" {
+		return Term{}, false
+	}
+	field := Field(strings.ToLower(name))
+	if !field.valid(value) {
+		return Term{}, false
+	}
+	return Term{Field: field, Value: value}, true
+}
+
+// words splits query at whitespace outside double quotes, so a quoted
+// phrase stays one word and is never read as a term
+func words(query string) []string {
+	var out []string
+	inQuote := false
+	start := -1
+	for i, r := range query {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+			if start < 0 {
+				start = i
+			}
+		case unicode.IsSpace(r) && !inQuote:
+			if start >= 0 {
+				out = append(out, query[start:i])
+				start = -1
+			}
+		case start < 0:
+			start = i
+		}
+	}
+	if start >= 0 {
+		out = append(out, query[start:])
+	}
+	return out
+}
+
+// Values returns the values of every field term, in query order
+func (q Query) Values(field Field) []string {
+	var values []string
+	for _, term := range q.Terms {
+		if term.Field == field {
+			values = append(values, term.Value)
+		}
+	}
+	return values
+}
+
+// CreatedRange returns the creation times the after and before terms allow:
+// from the start of the day after `after` up to, but not including, the
+// start of `before`. A zero time is no bound. With several terms of a
+// kind, the last one counts.
+func (q Query) CreatedRange() (from, to time.Time) {
+	// Parse validated the dates already
+	if after := q.Values(After); len(after) > 0 {
+		date, _ := time.Parse(DateLayout, after[len(after)-1])
+		from = date.AddDate(0, 0, 1)
+	}
+	if before := q.Values(Before); len(before) > 0 {
+		to, _ = time.Parse(DateLayout, before[len(before)-1])
+	}
+	return from, to
+}
+
+// With returns the free text followed by qualifiers, the platform's own
+// spelling of the terms it supports
+func (q Query) With(qualifiers ...string) string {
+	if len(qualifiers) == 0 {
+		return q.Text
+	}
+	if q.Text == "" {
+		return strings.Join(qualifiers, " ")
+	}
+	return q.Text + " " + strings.Join(qualifiers, " ")
+}
@@ -0,0 +1,62 @@
+package query
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseSplitsTermsFromText(t *testing.T) {
+	for _, tc := range []struct {
+		query string
+		text  string
+		terms []Term
+	}{
+		{"worker pool", "worker pool", nil},
+		// Without terms the query is left exactly as typed
+		{"  worker\tpool ", "  worker\tpool ", nil},
+		{
+			"lang:go worker pool stars:>100 Tag:concurrency",
+			"worker pool",
+			[]Term{{Lang, "go"}, {Stars, ">100"}, {Tag, "concurrency"}},
+		},
+		{"subreddit:golang after:2023-01-01", "", []Term{{Subreddit, "golang"}, {After, "2023-01-01"}}},
+		{`"lang:go is fun" lang:c++`, `"lang:go is fun"`, []Term{{Lang, "c++"}}},
+		// Unknown qualifiers and malformed values stay in the text
+		{"org:golang lang:go", "org:golang", []Term{{Lang, "go"}}},
+		{"stars:lots after:yesterday tag: x lang:go", "stars:lots after:yesterday tag: x", []Term{{Lang, "go"}}},
+		{"stars:10..50 stars:<=3", "", []Term{{Stars, "10..50"}, {Stars, "<=3"}}},
+	} {
+		got := Parse(tc.query)
+		if got.Text != tc.text || !reflect.DeepEqual(got.Terms, tc.terms) {
+			t.Errorf("Parse(%q) = %q %v, want %q %v", tc.query, got.Text, got.Terms, tc.text, tc.terms)
+		}
+	}
+}
+
+func TestCreatedRange(t *testing.T) {
+	from, to := Parse("go after:2023-01-01 before:2023-06-01 after:2023-02-01").CreatedRange()
+	if want := time.Date(2023, 2, 2, 0, 0, 0, 0, time.UTC); !from.Equal(want) {
+		t.Errorf("from = %v, want %v", from, want)
+	}
+	if want := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC); !to.Equal(want) {
+		t.Errorf("to = %v, want %v", to, want)
+	}
+
+	if from, to := Parse("go").CreatedRange(); !from.IsZero() || !to.IsZero() {
+		t.Errorf("CreatedRange() without dates = %v, %v; want no bounds", from, to)
+	}
+}
+
+func TestWith(t *testing.T) {
+	q := Parse("worker pool lang:go")
+	if got := q.With("language:go", "stars:>1"); got != "worker pool language:go stars:>1" {
+		t.Errorf("With() = %q", got)
+	}
+	if got := Parse("lang:go").With("language:go"); got != "language:go" {
+		t.Errorf("With() on terms only = %q", got)
+	}
+	if got := q.With(); got != "worker pool" {
+		t.Errorf("With() without qualifiers = %q", got)
+	}
+}
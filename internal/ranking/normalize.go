@@ -0,0 +1,144 @@
+package ranking
+
+import (
+	"math"
+	"slices"
+	"strconv"
+
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+// Normalization is how raw popularity is scaled into NormalizedScore
+type Normalization string
+
+const (
+	// MinMax scales each platform's results so its least popular scores 0
+	// and its most popular 1
+	MinMax Normalization = "minmax"
+	// ZScore scores each result by how many standard deviations it lies
+	// from its platform's mean, mapped onto 0–1 by the normal distribution
+	ZScore Normalization = "zscore"
+)
+
+// popularityKeys are the metadata keys holding a result's popularity, in
+// the order they are tried: GitHub and Docker Hub stars, Stack Overflow and
+// Reddit scores, Hacker News points, GitHub discussion upvotes, Dev.to
+// reactions and, for GitHub issues, comments
+var popularityKeys = []string{"stars", "score", "points", "upvotes", "reactions", "comments"}
+
+// neutralScore is given when a platform's results can't be told apart
+const neutralScore = 0.5
+
+// popularity returns the result's raw popularity, compressed
+// logarithmically since stars and votes are heavy-tailed; false when the
+// result carries none
+func popularity(result *pb.Result) (float64, bool) {
+	for _, key := range popularityKeys {
+		value, ok := result.Metadata[key]
+		if !ok {
+			continue
+		}
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return 0, false
+		}
+		// Stack Overflow scores go negative
+		return math.Copysign(math.Log1p(math.Abs(n)), n), true
+	}
+	return 0, false
+}
+
+// Normalize sets NormalizedScore on results, comparing each result only
+// with the others from its platform, since stars, votes and points aren't
+// comparable. Results without popularity metadata, such as code search
+// hits, score 0; a platform whose results are equally popular scores
+// them all 0.5.
+func Normalize(results []*pb.Result, method Normalization) {
+	byPlatform := make(map[string][]*pb.Result)
+	raw := make(map[*pb.Result]float64, len(results))
+	for _, result := range results {
+		result.NormalizedScore = 0
+		if value, ok := popularity(result); ok {
+			raw[result] = value
+			byPlatform[result.Platform] = append(byPlatform[result.Platform], result)
+		}
+	}
+
+	for _, group := range byPlatform {
+		values := make([]float64, len(group))
+		for i, result := range group {
+			values[i] = raw[result]
+		}
+		scale := minMaxScale(values)
+		if method == ZScore {
+			scale = zScoreScale(values)
+		}
+		for i, result := range group {
+			result.NormalizedScore = scale(values[i])
+		}
+	}
+}
+
+func minMaxScale(values []float64) func(float64) float64 {
+	lo, hi := slices.Min(values), slices.Max(values)
+	return func(v float64) float64 {
+		if hi == lo {
+			return neutralScore
+		}
+		return (v - lo) / (hi - lo)
+	}
+}
+
+func zScoreScale(values []float64) func(float64) float64 {
+	var mean float64
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	var variance float64
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	stddev := math.Sqrt(variance / float64(len(values)))
+
+	return func(v float64) float64 {
+		if stddev == 0 {
+			return neutralScore
+		}
+		z := (v - mean) / stddev
+		return 0.5 * (1 + math.Erf(z/math.Sqrt2))
+	}
+}
+
+// ScoreRanker orders results by NormalizedScore, best first, so the most
+// popular results of each platform lead regardless of scale. Results must
+// have been through Normalize; equal scores keep list order.
+type ScoreRanker struct{}
+
+// Rank implements Ranker
+func (ScoreRanker) Rank(lists []List) []*pb.Result {
+	var all []*pb.Result
+	seen := make(map[string]bool)
+	for _, list := range lists {
+		for _, result := range list.Results {
+			if result.Url != "" {
+				if seen[result.Url] {
+					continue
+				}
+				seen[result.Url] = true
+			}
+			all = append(all, result)
+		}
+	}
+
+	slices.SortStableFunc(all, func(a, b *pb.Result) int {
+		switch {
+		case a.NormalizedScore > b.NormalizedScore:
+			return -1
+		case a.NormalizedScore < b.NormalizedScore:
+			return 1
+		}
+		return 0
+	})
+	return all
+}
@@ -0,0 +1,86 @@
+// Package ranking merges the ordered result lists of several platforms into
+// one list ordered by relevance
+package ranking
+
+import (
+	"slices"
+
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+// DefaultK is the usual reciprocal rank fusion constant; it damps the lead
+// of top-ranked results so agreement between platforms counts for more
+const DefaultK = 60
+
+// List is one platform's results, best first
+type List struct {
+	Platform string
+	Results  []*pb.Result
+}
+
+// Ranker merges per-platform lists into a single ranked list. A result that
+// several lists contain (by URL) appears once, as the copy in the earliest
+// list.
+type Ranker interface {
+	Rank(lists []List) []*pb.Result
+}
+
+// ReciprocalRankFusion scores each result by the sum of 1/(k+rank) over the
+// lists containing it, ranks starting at 1. It needs no comparable scores
+// from the platforms, only their own orderings.
+type ReciprocalRankFusion struct {
+	k int
+}
+
+// NewReciprocalRankFusion creates a ranker with constant k, or DefaultK when
+// k isn't positive
+func NewReciprocalRankFusion(k int) *ReciprocalRankFusion {
+	if k <= 0 {
+		k = DefaultK
+	}
+	return &ReciprocalRankFusion{k: k}
+}
+
+// fused is a result with its accumulated score. order breaks ties by where
+// the result was first seen, keeping the ranking deterministic.
+type fused struct {
+	result *pb.Result
+	score  float64
+	order  int
+}
+
+// Rank implements Ranker
+func (r *ReciprocalRankFusion) Rank(lists []List) []*pb.Result {
+	var all []*fused
+	byURL := make(map[string]*fused)
+	for _, list := range lists {
+		for i, result := range list.Results {
+			score := 1 / float64(r.k+i+1)
+			if f, ok := byURL[result.Url]; ok && result.Url != "" {
+				f.score += score
+				continue
+			}
+			f := &fused{result: result, score: score, order: len(all)}
+			all = append(all, f)
+			if result.Url != "" {
+				byURL[result.Url] = f
+			}
+		}
+	}
+
+	slices.SortStableFunc(all, func(a, b *fused) int {
+		switch {
+		case a.score > b.score:
+			return -1
+		case a.score < b.score:
+			return 1
+		}
+		return a.order - b.order
+	})
+
+	ranked := make([]*pb.Result, len(all))
+	for i, f := range all {
+		ranked[i] = f.result
+	}
+	return ranked
+}
@@ -0,0 +1,171 @@
+package ranking
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+func list(platform string, urls ...string) List {
+	l := List{Platform: platform}
+	for _, url := range urls {
+		l.Results = append(l.Results, &pb.Result{Platform: platform, Url: url})
+	}
+	return l
+}
+
+func urls(results []*pb.Result) []string {
+	out := make([]string, len(results))
+	for i, result := range results {
+		out[i] = result.Platform + ":" + result.Url
+	}
+	return out
+}
+
+func TestReciprocalRankFusion(t *testing.T) {
+	ranked := NewReciprocalRankFusion(0).Rank([]List{
+		list("a", "a1", "a2", "shared"),
+		list("b", "b1", "shared", "b3"),
+	})
+
+	// shared is third and second in the lists, which together beats
+	// anyone's first place; equal scores keep the order seen
+	want := []string{"a:shared", "a:a1", "b:b1", "a:a2", "b:b3"}
+	if got := urls(ranked); !slices.Equal(got, want) {
+		t.Errorf("Rank() = %v, want %v", got, want)
+	}
+}
+
+func TestReciprocalRankFusionKeepsResultsWithoutURLs(t *testing.T) {
+	ranked := NewReciprocalRankFusion(DefaultK).Rank([]List{list("a", "", ""), list("b", "")})
+	if len(ranked) != 3 {
+		t.Errorf("Rank() returned %d results, want 3", len(ranked))
+	}
+}
+
+// popular returns a result from platform with popularity under key
+func popular(platform, url, key, value string) *pb.Result {
+	return &pb.Result{Platform: platform, Url: url, Metadata: map[string]string{key: value}}
+}
+
+func TestNormalizeScalesEachPlatformSeparately(t *testing.T) {
+	results := []*pb.Result{
+		popular("github", "big", "stars", "50000"),
+		popular("github", "small", "stars", "10"),
+		popular("github", "mid", "stars", "2000"),
+		popular("stackoverflow", "top", "score", "40"),
+		popular("stackoverflow", "bad", "score", "-3"),
+		popular("hackernews", "only", "points", "120"),
+		{Platform: "github", Url: "code", Metadata: map[string]string{"path": "main.go"}},
+	}
+	Normalize(results, MinMax)
+
+	for i, want := range []float64{1, 0, -1, 1, 0, 0.5, 0} {
+		got := results[i].NormalizedScore
+		if want < 0 {
+			// Log-scaled, 2000 stars sits past the middle
+			if got < 0.5 || got > 0.9 {
+				t.Errorf("%s score = %v, want between 0.5 and 0.9", results[i].Url, got)
+			}
+			continue
+		}
+		if got != want {
+			t.Errorf("%s score = %v, want %v", results[i].Url, got, want)
+		}
+	}
+}
+
+func TestNormalizeZScore(t *testing.T) {
+	results := []*pb.Result{
+		popular("reddit", "low", "score", "1"),
+		popular("reddit", "avg", "score", "30"),
+		popular("reddit", "high", "score", "900"),
+		popular("devto", "same1", "reactions", "5"),
+		popular("devto", "same2", "reactions", "5"),
+	}
+	Normalize(results, ZScore)
+
+	low, avg, high := results[0].NormalizedScore, results[1].NormalizedScore, results[2].NormalizedScore
+	if !(0 < low && low < avg && avg < high && high < 1) {
+		t.Errorf("scores = %v, %v, %v; want increasing within (0, 1)", low, avg, high)
+	}
+	if results[3].NormalizedScore != 0.5 || results[4].NormalizedScore != 0.5 {
+		t.Errorf("equal reactions scored %v and %v, want 0.5", results[3].NormalizedScore, results[4].NormalizedScore)
+	}
+}
+
+func TestScoreRankerOrdersByNormalizedScore(t *testing.T) {
+	a, b := list("a", "a1", "a2", "shared"), list("b", "b1", "shared")
+	for i, score := range []float64{0.2, 0.9, 0.5} {
+		a.Results[i].NormalizedScore = score
+	}
+	b.Results[0].NormalizedScore = 0.9
+	b.Results[1].NormalizedScore = 1
+
+	// Equal scores keep list order; the shared result is ranked once, as
+	// the first copy
+	want := []string{"a:a2", "b:b1", "a:shared", "a:a1"}
+	if got := urls(ScoreRanker{}.Rank([]List{a, b})); !slices.Equal(got, want) {
+		t.Errorf("Rank() = %v, want %v", got, want)
+	}
+}
+
+// wordEmbedder embeds text as counts of a fixed vocabulary
+type wordEmbedder struct {
+	vocabulary []string
+	calls      int
+}
+
+func (e *wordEmbedder) Embed(_ context.Context, text string) ([]float32, error) {
+	e.calls++
+	vector := make([]float32, len(e.vocabulary))
+	for i, word := range e.vocabulary {
+		vector[i] = float32(strings.Count(text, word))
+	}
+	return vector, nil
+}
+
+func TestSemanticRerankerOrdersBySimilarity(t *testing.T) {
+	embedder := &wordEmbedder{vocabulary: []string{"goroutine", "leak", "python"}}
+	results := []*pb.Result{
+		{Platform: "a", Url: "python", Title: "python packaging"},
+		{Platform: "a", Url: "leak", Title: "finding a goroutine leak", Snippet: "leak"},
+		{Platform: "b", Url: "goroutine", Title: "goroutine basics"},
+		{Platform: "b", Url: "tail", Title: "goroutine leak"},
+	}
+
+	reranked, err := NewSemanticReranker(embedder, 3).Rerank(context.Background(), "goroutine leak", nil, results)
+	if err != nil {
+		t.Fatalf("Rerank() error = %v", err)
+	}
+
+	// Only the top 3 are reordered; the rest keep their place after them
+	want := []string{"a:leak", "b:goroutine", "a:python", "b:tail"}
+	if got := urls(reranked); !slices.Equal(got, want) {
+		t.Errorf("Rerank() = %v, want %v", got, want)
+	}
+	if embedder.calls != 4 {
+		t.Errorf("embedded %d texts, want the query and 3 results", embedder.calls)
+	}
+}
+
+func TestSemanticRerankerKeepsOrderOnError(t *testing.T) {
+	results := []*pb.Result{{Url: "a"}, {Url: "b"}}
+	reranked, err := NewSemanticReranker(failingEmbedder{}, 10).Rerank(context.Background(), "go", nil, results)
+	if err == nil {
+		t.Fatal("Rerank() error = nil, want the embedding error")
+	}
+	if !slices.Equal(reranked, results) {
+		t.Errorf("Rerank() = %v, want the results unchanged", reranked)
+	}
+}
+
+type failingEmbedder struct{}
+
+func (failingEmbedder) Embed(context.Context, string) ([]float32, error) {
+	return nil, errors.New("embedding backend down")
+}
@@ -0,0 +1,93 @@
+package ranking
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/farhapartex/search-proxy/internal/embeddings"
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+// SemanticReranker reorders ranked results by how close their titles and
+// snippets are in meaning to the query, as cosine similarity of embeddings.
+// Only the first topN are embedded and reordered, bounding the cost; the
+// rest keep their place after them.
+type SemanticReranker struct {
+	embedder embeddings.Embedder
+	topN     int
+}
+
+// NewSemanticReranker creates a reranker embedding with embedder
+func NewSemanticReranker(embedder embeddings.Embedder, topN int) *SemanticReranker {
+	return &SemanticReranker{embedder: embedder, topN: topN}
+}
+
+// Rerank returns results reordered by similarity to query. queryVector is
+// the query's embedding if the caller already has one, or nil. On error
+// results are returned as they were, so a caller can fall back to them.
+func (r *SemanticReranker) Rerank(ctx context.Context, query string, queryVector []float32, results []*pb.Result) ([]*pb.Result, error) {
+	head := results[:min(len(results), r.topN)]
+	if len(head) < 2 {
+		return results, nil
+	}
+
+	if queryVector == nil {
+		var err error
+		if queryVector, err = r.embedder.Embed(ctx, query); err != nil {
+			return results, fmt.Errorf("failed to embed query: %w", err)
+		}
+	}
+	texts := make([]string, len(head))
+	for i, result := range head {
+		texts[i] = result.Title + "\n" + result.Snippet
+	}
+	vectors, err := embeddings.EmbedDocuments(ctx, r.embedder, texts)
+	if err != nil {
+		return results, fmt.Errorf("failed to embed results: %w", err)
+	}
+
+	type scored struct {
+		result     *pb.Result
+		similarity float64
+	}
+	ordered := make([]scored, len(head))
+	for i, result := range head {
+		ordered[i] = scored{result: result, similarity: cosine(queryVector, vectors[i])}
+	}
+	// Stable, so equally similar results keep their fused order
+	slices.SortStableFunc(ordered, func(a, b scored) int {
+		switch {
+		case a.similarity > b.similarity:
+			return -1
+		case a.similarity < b.similarity:
+			return 1
+		}
+		return 0
+	})
+
+	reranked := make([]*pb.Result, 0, len(results))
+	for _, s := range ordered {
+		reranked = append(reranked, s.result)
+	}
+	return append(reranked, results[len(head):]...), nil
+}
+
+// cosine is the cosine similarity of a and b, or 0 when they can't be
+// compared
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(normA*normB)
+}
@@ -0,0 +1,93 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// ErrLimited is returned when a platform has exhausted its request budget
+var ErrLimited = errors.New("rate limit exceeded")
+
+// Limiter decides whether a request to a platform may be sent now
+type Limiter interface {
+	// Allow consumes one token for platform. When the request is not allowed
+	// it returns false and how long the caller should wait before retrying.
+	Allow(ctx context.Context, platform string) (bool, time.Duration, error)
+}
+
+// Limit describes a token bucket: Rate tokens per second, up to Burst at once
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// PerMinute builds a limit from a requests-per-minute quota
+func PerMinute(requests, burst int) Limit {
+	if burst < 1 {
+		burst = 1
+	}
+	return Limit{
+		Rate:  float64(requests) / 60,
+		Burst: burst,
+	}
+}
+
+// LimitedError carries the suggested wait before the next attempt
+type LimitedError struct {
+	Platform   string
+	RetryAfter time.Duration
+}
+
+func (e *LimitedError) Error() string {
+	return fmt.Sprintf("%s: %v (retry after %v)", e.Platform, ErrLimited, e.RetryAfter)
+}
+
+func (e *LimitedError) Unwrap() error {
+	return ErrLimited
+}
+
+// LocalLimiter keeps one in-process token bucket per platform. It is only
+// accurate when a single replica talks to the upstreams.
+type LocalLimiter struct {
+	mu       sync.Mutex
+	limits   map[string]Limit
+	limiters map[string]*rate.Limiter
+}
+
+// NewLocalLimiter creates a limiter for the given per-platform limits.
+// Platforms without an entry are never limited.
+func NewLocalLimiter(limits map[string]Limit) *LocalLimiter {
+	return &LocalLimiter{
+		limits:   limits,
+		limiters: make(map[string]*rate.Limiter),
+	}
+}
+
+// Allow implements Limiter
+func (l *LocalLimiter) Allow(ctx context.Context, platform string) (bool, time.Duration, error) {
+	l.mu.Lock()
+	limiter, ok := l.limiters[platform]
+	if !ok {
+		limit, configured := l.limits[platform]
+		if !configured || limit.Rate <= 0 {
+			l.mu.Unlock()
+			return true, 0, nil
+		}
+		limiter = rate.NewLimiter(rate.Limit(limit.Rate), limit.Burst)
+		l.limiters[platform] = limiter
+	}
+	l.mu.Unlock()
+
+	reservation := limiter.Reserve()
+	delay := reservation.Delay()
+	if delay == 0 {
+		return true, 0, nil
+	}
+	reservation.Cancel()
+	return false, delay, nil
+}
@@ -0,0 +1,149 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+var testLimits = map[string]Limit{
+	"github": {Rate: 1, Burst: 2},
+	"reddit": {Rate: 0.5, Burst: 1},
+	"devto":  {Rate: 0, Burst: 5},
+}
+
+func newTestRedisLimiter(t *testing.T) (*RedisLimiter, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { client.Close() })
+	return NewRedisLimiter(client, "test:ratelimit:", testLimits), server
+}
+
+func TestLimitersAllowUpToBurst(t *testing.T) {
+	tests := []struct {
+		name     string
+		platform string
+		calls    int
+		// allowed is how many of the calls get through
+		allowed int
+		// maxRetry bounds the wait suggested for the first refused call
+		maxRetry time.Duration
+	}{
+		{name: "within burst", platform: "github", calls: 2, allowed: 2},
+		{name: "over burst", platform: "github", calls: 4, allowed: 2, maxRetry: time.Second},
+		{name: "burst of one", platform: "reddit", calls: 2, allowed: 1, maxRetry: 2 * time.Second},
+		{name: "zero rate is unlimited", platform: "devto", calls: 20, allowed: 20},
+		{name: "unconfigured is unlimited", platform: "hackernews", calls: 20, allowed: 20},
+	}
+
+	for _, tt := range tests {
+		redisLimiter, _ := newTestRedisLimiter(t)
+		limiters := map[string]Limiter{
+			"local": NewLocalLimiter(testLimits),
+			"redis": redisLimiter,
+		}
+		for kind, limiter := range limiters {
+			allowed := 0
+			var retryAfter time.Duration
+			for range tt.calls {
+				ok, retry, err := limiter.Allow(context.Background(), tt.platform)
+				if err != nil {
+					t.Fatalf("%s %s: Allow() error = %v", kind, tt.name, err)
+				}
+				if ok {
+					allowed++
+				} else if retryAfter == 0 {
+					retryAfter = retry
+				}
+			}
+			if allowed != tt.allowed {
+				t.Errorf("%s %s: allowed %d of %d calls, want %d", kind, tt.name, allowed, tt.calls, tt.allowed)
+			}
+			if tt.allowed < tt.calls && (retryAfter <= 0 || retryAfter > tt.maxRetry) {
+				t.Errorf("%s %s: retry after %v, want within (0, %v]", kind, tt.name, retryAfter, tt.maxRetry)
+			}
+		}
+	}
+}
+
+func TestLimitersKeepPlatformsApart(t *testing.T) {
+	redisLimiter, _ := newTestRedisLimiter(t)
+	for kind, limiter := range map[string]Limiter{"local": NewLocalLimiter(testLimits), "redis": redisLimiter} {
+		limiter.Allow(context.Background(), "reddit")
+		if ok, _, _ := limiter.Allow(context.Background(), "github"); !ok {
+			t.Errorf("%s: github refused after reddit spent its budget", kind)
+		}
+	}
+}
+
+func TestRedisLimiterRefillsWithServerClock(t *testing.T) {
+	limiter, server := newTestRedisLimiter(t)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	server.SetTime(now)
+
+	tests := []struct {
+		advance time.Duration
+		want    bool
+	}{
+		{0, true},
+		{0, false},
+		{time.Second, false},
+		{time.Second, true},
+		{500 * time.Millisecond, false},
+		// Waiting longer than a full refill doesn't bank more than the burst
+		{time.Minute, true},
+		{0, false},
+	}
+	for i, tt := range tests {
+		now = now.Add(tt.advance)
+		server.SetTime(now)
+		if ok, _, err := limiter.Allow(context.Background(), "reddit"); ok != tt.want || err != nil {
+			t.Errorf("call %d after %v: Allow() = %t, %v; want %t", i, tt.advance, ok, err, tt.want)
+		}
+	}
+}
+
+func TestRedisLimiterReportsOutage(t *testing.T) {
+	limiter, server := newTestRedisLimiter(t)
+	server.Close()
+
+	if _, _, err := limiter.Allow(context.Background(), "github"); err == nil {
+		t.Error("Allow() with Redis down succeeded, want an error")
+	}
+	// Unlimited platforms never need Redis
+	if ok, _, err := limiter.Allow(context.Background(), "devto"); !ok || err != nil {
+		t.Errorf("Allow(devto) with Redis down = %t, %v; want allowed", ok, err)
+	}
+}
+
+func TestPerMinute(t *testing.T) {
+	tests := []struct {
+		requests, burst int
+		want            Limit
+	}{
+		{60, 10, Limit{Rate: 1, Burst: 10}},
+		{30, 1, Limit{Rate: 0.5, Burst: 1}},
+		{120, 0, Limit{Rate: 2, Burst: 1}},
+		{0, -3, Limit{Rate: 0, Burst: 1}},
+	}
+	for _, tt := range tests {
+		if got := PerMinute(tt.requests, tt.burst); got != tt.want {
+			t.Errorf("PerMinute(%d, %d) = %+v, want %+v", tt.requests, tt.burst, got, tt.want)
+		}
+	}
+}
+
+func TestLimitedError(t *testing.T) {
+	err := error(&LimitedError{Platform: "github", RetryAfter: 2 * time.Second})
+	if !errors.Is(err, ErrLimited) {
+		t.Error("LimitedError doesn't match ErrLimited")
+	}
+	if msg := err.Error(); !strings.Contains(msg, "github") || !strings.Contains(msg, "2s") {
+		t.Errorf("Error() = %q, want the platform and wait", msg)
+	}
+}
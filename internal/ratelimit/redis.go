@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// tokenBucketScript refills and takes one token atomically. It uses the Redis
+// server clock so replicas with skewed clocks still share one consistent bucket.
+//
+// KEYS[1] bucket key; ARGV[1] tokens per second; ARGV[2] burst.
+// Returns {allowed (0|1), retry_after_ms}.
+var tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(bucket[1]) or burst
+local ts = tonumber(bucket[2]) or now
+
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate / 1000)
+
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+else
+  retry = math.ceil((1 - tokens) * 1000 / rate)
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst * 1000 / rate) + 1000)
+return {allowed, retry}
+`)
+
+// RedisLimiter shares one token bucket per platform across every replica
+// connected to the same Redis
+type RedisLimiter struct {
+	client    redis.UniversalClient
+	keyPrefix string
+	limits    map[string]Limit
+}
+
+// NewRedisLimiter creates a limiter storing its buckets under keyPrefix
+func NewRedisLimiter(client redis.UniversalClient, keyPrefix string, limits map[string]Limit) *RedisLimiter {
+	return &RedisLimiter{
+		client:    client,
+		keyPrefix: keyPrefix,
+		limits:    limits,
+	}
+}
+
+// Allow implements Limiter
+func (l *RedisLimiter) Allow(ctx context.Context, platform string) (bool, time.Duration, error) {
+	limit, ok := l.limits[platform]
+	if !ok || limit.Rate <= 0 {
+		return true, 0, nil
+	}
+
+	res, err := tokenBucketScript.Run(ctx, l.client,
+		[]string{l.keyPrefix + platform},
+		limit.Rate, limit.Burst,
+	).Int64Slice()
+	if err != nil {
+		return false, 0, fmt.Errorf("redis rate limit check failed: %w", err)
+	}
+	if len(res) != 2 {
+		return false, 0, fmt.Errorf("unexpected rate limit script reply: %v", res)
+	}
+
+	return res[0] == 1, time.Duration(res[1]) * time.Millisecond, nil
+}
@@ -0,0 +1,239 @@
+// Package replay records upstream HTTP responses to disk and serves them
+// back later without network access, for deterministic integration tests,
+// offline demos and reproducing a reported query without spending quota.
+// Recordings are keyed by method, URL with its query and request body.
+package replay
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Modes for upstream traffic
+const (
+	Off    = "off"
+	Record = "record"
+	Replay = "replay"
+)
+
+// ErrNoRecording is returned in replay mode for a request that was never
+// recorded
+var ErrNoRecording = errors.New("no recorded response")
+
+// recording is one upstream exchange as stored on disk
+type recording struct {
+	Method     string              `json:"method"`
+	URL        string              `json:"url"`
+	StatusCode int                 `json:"status_code"`
+	Header     map[string][]string `json:"header"`
+	Body       string              `json:"body"`
+	RecordedAt time.Time           `json:"recorded_at"`
+}
+
+// transport records upstream responses to dir, or serves them back from
+// dir without touching the network. Credentials in query parameters are
+// redacted before they reach the disk.
+type transport struct {
+	next         http.RoundTripper
+	mode         string
+	dir          string
+	maxBodyBytes int64
+}
+
+// NewTransport wraps next to record to or replay from dir according to
+// mode. Off, or no mode, returns next itself. Bodies over maxBodyBytes pass
+// through unrecorded; 0 records any size.
+func NewTransport(next http.RoundTripper, mode, dir string, maxBodyBytes int64) (http.RoundTripper, error) {
+	switch mode {
+	case "", Off:
+		return next, nil
+	case Record, Replay:
+		if dir == "" {
+			return nil, fmt.Errorf("a recording directory is required in %s mode", mode)
+		}
+		return &transport{next: next, mode: mode, dir: dir, maxBodyBytes: maxBodyBytes}, nil
+	default:
+		return nil, fmt.Errorf("invalid record/replay mode %q (valid: off, record, replay)", mode)
+	}
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	path, err := t.recordingPath(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.mode == Replay {
+		return t.replay(req, path)
+	}
+	return t.record(req, path)
+}
+
+func (t *transport) replay(req *http.Request, path string) (*http.Response, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w for %s %s", ErrNoRecording, req.Method, RedactURL(req.URL))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recording: %w", err)
+	}
+
+	var rec recording
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("failed to parse recording %s: %w", path, err)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rec.StatusCode, http.StatusText(rec.StatusCode)),
+		StatusCode:    rec.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header(rec.Header),
+		Body:          io.NopCloser(strings.NewReader(rec.Body)),
+		ContentLength: int64(len(rec.Body)),
+		Request:       req,
+	}, nil
+}
+
+func (t *transport) record(req *http.Request, path string) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := io.Reader(resp.Body)
+	if t.maxBodyBytes > 0 {
+		reader = io.LimitReader(resp.Body, t.maxBodyBytes+1)
+	}
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	// Oversized bodies are passed through unrecorded; the caller's body
+	// limit reports them as it would without the recorder
+	if t.maxBodyBytes > 0 && int64(len(body)) > t.maxBodyBytes {
+		resp.Body = readCloser{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return resp, nil
+	}
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	header := resp.Header.Clone()
+	header.Del("Set-Cookie")
+
+	rec := recording{
+		Method:     req.Method,
+		URL:        RedactURL(req.URL),
+		StatusCode: resp.StatusCode,
+		Header:     header,
+		Body:       string(body),
+		RecordedAt: time.Now().UTC(),
+	}
+	if err := writeRecording(path, &rec); err != nil {
+		// Recording is best effort; the live response is still good
+		log.Printf("WARNING: Failed to record %s: %v", rec.URL, err)
+	}
+
+	return resp, nil
+}
+
+// recordingPath derives a stable file name from the request. Credentials are
+// left out so recordings made with different keys still match.
+func (t *transport) recordingPath(req *http.Request) (string, error) {
+	hash := sha256.New()
+	hash.Write([]byte(req.Method + " " + RedactURL(req.URL) + "\n"))
+
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return "", fmt.Errorf("failed to read request body: %w", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		hash.Write([]byte(redactForm(string(body))))
+	}
+
+	name := hex.EncodeToString(hash.Sum(nil))[:16] + ".json"
+	return filepath.Join(t.dir, req.URL.Hostname(), name), nil
+}
+
+func writeRecording(path string, rec *recording) error {
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	// Write through a temp file so concurrent identical requests never leave
+	// a half-written recording behind
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".recording-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// isSecretParam reports whether a query or form parameter carries a credential
+func isSecretParam(name string) bool {
+	name = strings.ToLower(name)
+	for _, marker := range []string{"key", "token", "secret", "password"} {
+		if strings.Contains(name, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactURL returns u without user info or credential parameters, as
+// recordings store it
+func RedactURL(u *url.URL) string {
+	redacted := *u
+	redacted.User = nil
+	redacted.RawQuery = redactForm(u.RawQuery)
+	return redacted.String()
+}
+
+func redactForm(encoded string) string {
+	values, err := url.ParseQuery(encoded)
+	if err != nil {
+		return encoded
+	}
+	for name := range values {
+		if isSecretParam(name) {
+			values.Del(name)
+		}
+	}
+	return values.Encode()
+}
+
+// readCloser pairs a reader with the closer of the body it drains
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
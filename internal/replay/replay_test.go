@@ -0,0 +1,99 @@
+package replay
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func get(t *testing.T, client *http.Client, url string) (int, string, error) {
+	t.Helper()
+	resp, err := client.Get(url)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body), err
+}
+
+func TestRecordThenReplayWithoutNetwork(t *testing.T) {
+	dir := t.TempDir()
+	hits := 0
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Set-Cookie", "session=abc")
+		w.WriteHeader(http.StatusTeapot)
+		io.WriteString(w, "q="+r.URL.Query().Get("q"))
+	}))
+
+	recorder, err := NewTransport(http.DefaultTransport, Record, dir, 0)
+	if err != nil {
+		t.Fatalf("NewTransport(record) error = %v", err)
+	}
+	client := &http.Client{Transport: recorder}
+	if code, body, err := get(t, client, upstream.URL+"/search?q=go&api_key=first"); err != nil || code != http.StatusTeapot || body != "q=go" {
+		t.Fatalf("recorded GET = %d %q, %v", code, body, err)
+	}
+	upstream.Close()
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*", "*.json"))
+	if len(files) != 1 {
+		t.Fatalf("recorded %d files, want 1", len(files))
+	}
+	data, _ := os.ReadFile(files[0])
+	if strings.Contains(string(data), "first") || strings.Contains(string(data), "session=abc") {
+		t.Errorf("recording keeps a credential or cookie:\n%s", data)
+	}
+
+	player, err := NewTransport(http.DefaultTransport, Replay, dir, 0)
+	if err != nil {
+		t.Fatalf("NewTransport(replay) error = %v", err)
+	}
+	client = &http.Client{Transport: player}
+	// A different key still matches, since keys aren't part of the match
+	if code, body, err := get(t, client, upstream.URL+"/search?q=go&api_key=second"); err != nil || code != http.StatusTeapot || body != "q=go" {
+		t.Errorf("replayed GET = %d %q, %v; want the recorded 418", code, body, err)
+	}
+	if _, _, err := get(t, client, upstream.URL+"/search?q=rust"); !errors.Is(err, ErrNoRecording) {
+		t.Errorf("unrecorded GET error = %v, want ErrNoRecording", err)
+	}
+	if hits != 1 {
+		t.Errorf("upstream saw %d requests, want only the recorded one", hits)
+	}
+}
+
+func TestRecordSkipsOversizedBodies(t *testing.T) {
+	dir := t.TempDir()
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, strings.Repeat("x", 64))
+	}))
+	defer upstream.Close()
+
+	recorder, _ := NewTransport(http.DefaultTransport, Record, dir, 16)
+	_, body, err := get(t, &http.Client{Transport: recorder}, upstream.URL)
+	if err != nil || len(body) != 64 {
+		t.Fatalf("GET = %d bytes, %v; want the whole body passed through", len(body), err)
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "*", "*.json")); len(files) != 0 {
+		t.Errorf("recorded %v, want oversized bodies left out", files)
+	}
+}
+
+func TestNewTransportModes(t *testing.T) {
+	next := http.DefaultTransport
+	if rt, err := NewTransport(next, Off, "", 0); err != nil || rt != next {
+		t.Errorf("NewTransport(off) = %v, %v; want next itself", rt, err)
+	}
+	if _, err := NewTransport(next, Replay, "", 0); err == nil {
+		t.Error("NewTransport(replay) without a directory succeeded")
+	}
+	if _, err := NewTransport(next, "rewind", "testdata", 0); err == nil {
+		t.Error("NewTransport(rewind) succeeded, want an invalid mode error")
+	}
+}
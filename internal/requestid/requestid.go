@@ -0,0 +1,73 @@
+// Package requestid correlates the log lines, spans and response of one
+// call. The ID comes from the caller's "x-request-id" metadata or HTTP
+// header when it sends a usable one, and is generated otherwise.
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// MetadataKey is the gRPC metadata key the ID is read from and sent back in;
+// over HTTP it is the header
+const MetadataKey = "x-request-id"
+
+// maxLength bounds a caller's ID, which ends up in every log line of the call
+const maxLength = 128
+
+type contextKey struct{}
+
+// New generates a random ID
+func New() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// NewContext returns ctx carrying id
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the ID ctx carries, or "" for none
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// FromIncoming returns the ID the gRPC caller sent in its metadata, or a
+// new one if it sent none or one that isn't valid
+func FromIncoming(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(MetadataKey); len(values) > 0 && Valid(values[0]) {
+			return values[0]
+		}
+	}
+	return New()
+}
+
+// FromRequest is FromIncoming for an HTTP request's header
+func FromRequest(r *http.Request) string {
+	if id := r.Header.Get(MetadataKey); Valid(id) {
+		return id
+	}
+	return New()
+}
+
+// Valid reports whether id is 1 to 128 printable ASCII characters without
+// spaces, so it can't break up or forge a log line
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,44 @@
+package requestid
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+)
+
+func TestFromIncomingKeepsValidCallerID(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(MetadataKey, "req-123"))
+	if got := FromIncoming(ctx); got != "req-123" {
+		t.Errorf("FromIncoming() = %q, want the caller's ID", got)
+	}
+}
+
+func TestFromIncomingGeneratesIDForMissingOrInvalid(t *testing.T) {
+	for _, md := range []metadata.MD{
+		nil,
+		metadata.Pairs(MetadataKey, ""),
+		metadata.Pairs(MetadataKey, "has space"),
+		metadata.Pairs(MetadataKey, "line\nbreak"),
+		metadata.Pairs(MetadataKey, strings.Repeat("a", maxLength+1)),
+	} {
+		ctx := context.Background()
+		if md != nil {
+			ctx = metadata.NewIncomingContext(ctx, md)
+		}
+		got := FromIncoming(ctx)
+		if len(got) != 32 || !Valid(got) {
+			t.Errorf("FromIncoming(%v) = %q, want a generated ID", md, got)
+		}
+	}
+}
+
+func TestContextRoundTrip(t *testing.T) {
+	if got := FromContext(context.Background()); got != "" {
+		t.Errorf("FromContext(empty) = %q, want none", got)
+	}
+	if got := FromContext(NewContext(context.Background(), "abc")); got != "abc" {
+		t.Errorf("FromContext() = %q, want abc", got)
+	}
+}
@@ -0,0 +1,69 @@
+package sanitize
+
+import "regexp"
+
+var emojiCode = regexp.MustCompile(`:[a-z0-9_+-]+:`)
+
+// emojiCodes are the GitHub emoji codes most common in repository
+// descriptions. Codes missing here are left as written.
+var emojiCodes = map[string]string{
+	":+1:":                       "👍",
+	":-1:":                       "👎",
+	":art:":                      "🎨",
+	":books:":                    "📚",
+	":boom:":                     "💥",
+	":bug:":                      "🐛",
+	":bulb:":                     "💡",
+	":chart_with_upwards_trend:": "📈",
+	":white_check_mark:":         "✅",
+	":closed_lock_with_key:":     "🔐",
+	":computer:":                 "💻",
+	":construction:":             "🚧",
+	":crab:":                     "🦀",
+	":dart:":                     "🎯",
+	":dog:":                      "🐶",
+	":earth_americas:":           "🌎",
+	":electric_plug:":            "🔌",
+	":fire:":                     "🔥",
+	":gear:":                     "⚙️",
+	":globe_with_meridians:":     "🌐",
+	":hammer:":                   "🔨",
+	":hammer_and_wrench:":        "🛠️",
+	":heart:":                    "❤️",
+	":heavy_check_mark:":         "✔️",
+	":hourglass:":                "⌛",
+	":key:":                      "🔑",
+	":lock:":                     "🔒",
+	":mag:":                      "🔍",
+	":memo:":                     "📝",
+	":package:":                  "📦",
+	":penguin:":                  "🐧",
+	":pencil:":                   "📝",
+	":rocket:":                   "🚀",
+	":robot:":                    "🤖",
+	":shield:":                   "🛡️",
+	":snake:":                    "🐍",
+	":sparkles:":                 "✨",
+	":star:":                     "⭐",
+	":tada:":                     "🎉",
+	":test_tube:":                "🧪",
+	":warning:":                  "⚠️",
+	":whale:":                    "🐳",
+	":wrench:":                   "🔧",
+	":x:":                        "❌",
+	":zap:":                      "⚡",
+}
+
+// ReplaceEmojiCodes turns known emoji codes such as :rocket: into the emoji
+// they stand for
+func ReplaceEmojiCodes(s string) string {
+	if !emojiCode.MatchString(s) {
+		return s
+	}
+	return emojiCode.ReplaceAllStringFunc(s, func(code string) string {
+		if emoji, ok := emojiCodes[code]; ok {
+			return emoji
+		}
+		return code
+	})
+}
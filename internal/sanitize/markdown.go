@@ -0,0 +1,168 @@
+package sanitize
+
+import (
+	"html"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/rivo/uniseg"
+	xhtml "golang.org/x/net/html"
+)
+
+var (
+	mdFence        = regexp.MustCompile("(?m)^\\s*(```|~~~).*$")
+	mdImage        = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	mdLink         = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	mdInlineCode   = regexp.MustCompile("`([^`]*)`")
+	mdHeading      = regexp.MustCompile(`(?m)^\s{0,3}#{1,6}\s+`)
+	mdQuote        = regexp.MustCompile(`(?m)^\s{0,3}(>\s?)+`)
+	mdListItem     = regexp.MustCompile(`(?m)^\s*([*+-]|\d+[.)])\s+`)
+	mdRule         = regexp.MustCompile(`(?m)^\s*([*_-]\s*){3,}$`)
+	mdTableDivider = regexp.MustCompile(`(?m)^\s*\|?(\s*:?-+:?\s*\|)+\s*:?-*:?\s*$`)
+	mdStrong       = regexp.MustCompile(`(\*\*|__)(\S(?:.*?\S)?)(\*\*|__)`)
+	mdStrike       = regexp.MustCompile(`~~(\S(?:.*?\S)?)~~`)
+	mdEmphasis     = regexp.MustCompile(`\*(\S(?:[^*]*?\S)?)\*`)
+	// _x_ only counts as emphasis at word boundaries, so snake_case survives
+	mdUnderscore  = regexp.MustCompile(`(^|[^\w])_(\S(?:[^_]*?\S)?)_([^\w]|$)`)
+	mdSpoiler     = regexp.MustCompile(`>!(.*?)!<`)
+	mdSuperscript = regexp.MustCompile(`\^\(([^)]*)\)|\^`)
+	mdWhitespace  = regexp.MustCompile(`\s+`)
+)
+
+// StripMarkdown reduces markdown to plain text on a single line, keeping link
+// and image text but dropping their targets
+func StripMarkdown(markdown string) string {
+	// Reddit escapes &, < and > in its markdown
+	text := html.UnescapeString(markdown)
+
+	text = mdFence.ReplaceAllString(text, "")
+	text = mdTableDivider.ReplaceAllString(text, "")
+	text = mdRule.ReplaceAllString(text, "")
+	text = mdImage.ReplaceAllString(text, "$1")
+	text = mdLink.ReplaceAllString(text, "$1")
+	text = mdInlineCode.ReplaceAllString(text, "$1")
+	text = mdHeading.ReplaceAllString(text, "")
+	text = mdSpoiler.ReplaceAllString(text, "$1")
+	text = mdQuote.ReplaceAllString(text, "")
+	text = mdListItem.ReplaceAllString(text, "")
+	text = mdStrong.ReplaceAllString(text, "$2")
+	text = mdStrike.ReplaceAllString(text, "$1")
+	text = mdEmphasis.ReplaceAllString(text, "$1")
+	text = mdUnderscore.ReplaceAllString(text, "$1$2$3")
+	text = mdSuperscript.ReplaceAllString(text, "$1")
+	text = strings.ReplaceAll(text, "|", " ")
+
+	return strings.TrimSpace(mdWhitespace.ReplaceAllString(text, " "))
+}
+
+// excerptTags are the elements kept in HTML excerpts, with the attributes
+// each may carry. Anything else is dropped but its text is kept.
+var excerptTags = map[string][]string{
+	"a": {"href"}, "p": nil, "br": nil, "em": nil, "strong": nil, "del": nil,
+	"sup": nil, "code": nil, "pre": nil, "blockquote": nil, "ul": nil,
+	"ol": nil, "li": nil, "h1": nil, "h2": nil, "h3": nil, "h4": nil,
+	"h5": nil, "h6": nil,
+}
+
+// HTMLExcerpt cleans rendered HTML down to a small set of formatting tags and
+// cuts it after maxChars characters of text, closing any open tags so the
+// excerpt is always well formed
+func HTMLExcerpt(rendered string, maxChars int) string {
+	var out strings.Builder
+	var open []string
+	var skipping string
+	remaining := maxChars
+
+	tokenizer := xhtml.NewTokenizer(strings.NewReader(rendered))
+tokens:
+	for {
+		switch tokenizer.Next() {
+		case xhtml.ErrorToken:
+			break tokens
+
+		case xhtml.TextToken:
+			if skipping != "" {
+				continue
+			}
+			text := string(tokenizer.Text())
+			length := uniseg.GraphemeClusterCount(text)
+			if length > remaining {
+				out.WriteString(html.EscapeString(truncateGraphemes(text, remaining)))
+				out.WriteString("...")
+				break tokens
+			}
+			remaining -= length
+			out.WriteString(html.EscapeString(text))
+
+		case xhtml.StartTagToken, xhtml.SelfClosingTagToken:
+			token := tokenizer.Token()
+			allowed, ok := excerptTags[token.Data]
+			if !ok {
+				// Script and style bodies are code, not text
+				if token.Data == "script" || token.Data == "style" {
+					skipping = token.Data
+				}
+				continue
+			}
+			out.WriteString("<" + token.Data)
+			for _, attr := range token.Attr {
+				if slices.Contains(allowed, attr.Key) && safeHref(attr.Val) {
+					out.WriteString(" " + attr.Key + `="` + html.EscapeString(attr.Val) + `"`)
+				}
+			}
+			out.WriteString(">")
+			if token.Type == xhtml.StartTagToken && token.Data != "br" {
+				open = append(open, token.Data)
+			}
+
+		case xhtml.EndTagToken:
+			token := tokenizer.Token()
+			if token.Data == skipping {
+				skipping = ""
+			}
+			// Close back to the matching tag; stray end tags are dropped
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i] == token.Data {
+					for len(open) > i {
+						out.WriteString("</" + open[len(open)-1] + ">")
+						open = open[:len(open)-1]
+					}
+					break
+				}
+			}
+		}
+	}
+
+	for i := len(open) - 1; i >= 0; i-- {
+		out.WriteString("</" + open[i] + ">")
+	}
+	return strings.TrimSpace(out.String())
+}
+
+// safeHref rejects link targets that would run script in a browser
+func safeHref(href string) bool {
+	scheme, _, found := strings.Cut(strings.ToLower(strings.TrimSpace(href)), ":")
+	return !found || scheme == "http" || scheme == "https" || scheme == "mailto" || strings.ContainsAny(scheme, "/?#")
+}
+
+// HTMLText reduces rendered HTML to its text on a single line
+func HTMLText(rendered string) string {
+	var out strings.Builder
+	tokenizer := xhtml.NewTokenizer(strings.NewReader(rendered))
+	for {
+		switch tokenizer.Next() {
+		case xhtml.ErrorToken:
+			return strings.TrimSpace(mdWhitespace.ReplaceAllString(out.String(), " "))
+		case xhtml.TextToken:
+			out.Write(tokenizer.Text())
+		case xhtml.StartTagToken, xhtml.SelfClosingTagToken:
+			// Block elements and line breaks separate words; inline ones
+			// such as links may sit inside a word
+			switch name, _ := tokenizer.TagName(); string(name) {
+			case "p", "br", "div", "li", "pre", "blockquote":
+				out.WriteString(" ")
+			}
+		}
+	}
+}
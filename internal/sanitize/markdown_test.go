@@ -0,0 +1,75 @@
+package sanitize
+
+import "testing"
+
+func TestStripMarkdown(t *testing.T) {
+	for _, tc := range []struct {
+		markdown string
+		want     string
+	}{
+		{"**Bold** and *italic* and ~~gone~~", "Bold and italic and gone"},
+		{"See [the docs](https://go.dev/doc) and ![logo](https://x/y.png)", "See the docs and logo"},
+		{"# Title\n\nSome `code` here", "Title Some code here"},
+		{"> quoted\n> more", "quoted more"},
+		{"- one\n- two\n1. three", "one two three"},
+		{"```go\nfmt.Println(1)\n```", "fmt.Println(1)"},
+		{"keep snake_case_names but _drop_ this", "keep snake_case_names but drop this"},
+		{"a &amp; b &lt;3 &gt;!spoiler!&lt;", "a & b <3 spoiler"},
+		{"| a | b |\n|---|---|\n| 1 | 2 |", "a b 1 2"},
+		{"above\n\n***\n\nbelow", "above below"},
+		{"2^(10) is 1024", "210 is 1024"},
+	} {
+		if got := StripMarkdown(tc.markdown); got != tc.want {
+			t.Errorf("StripMarkdown(%q) = %q, want %q", tc.markdown, got, tc.want)
+		}
+	}
+}
+
+func TestHTMLExcerpt(t *testing.T) {
+	for _, tc := range []struct {
+		html     string
+		maxChars int
+		want     string
+	}{
+		{
+			`<!-- SC_OFF --><div class="md"><p>Hello <strong>world</strong></p></div><!-- SC_ON -->`,
+			100,
+			`<p>Hello <strong>world</strong></p>`,
+		},
+		{
+			`<p>Hello <em>wonderful</em> world</p>`,
+			10,
+			`<p>Hello <em>wond...</em></p>`,
+		},
+		{
+			`<p><a href="javascript:alert(1)" onclick="x()">click</a> <a href="https://go.dev">go</a></p>`,
+			100,
+			`<p><a>click</a> <a href="https://go.dev">go</a></p>`,
+		},
+		{
+			`<p>a<script>alert(1)</script> &lt;b&gt;</p>`,
+			100,
+			`<p>a &lt;b&gt;</p>`,
+		},
+	} {
+		if got := HTMLExcerpt(tc.html, tc.maxChars); got != tc.want {
+			t.Errorf("HTMLExcerpt(%q, %d) = %q, want %q", tc.html, tc.maxChars, got, tc.want)
+		}
+	}
+}
+
+func TestHTMLText(t *testing.T) {
+	for _, tc := range []struct {
+		html string
+		want string
+	}{
+		{`<p>Hello <i>world</i></p><p>Second&#x2F;para</p>`, `Hello world Second/para`},
+		{`Line one<br>line <a href="https://go.dev">two</a>`, `Line one line two`},
+		{`plain &amp; simple`, `plain & simple`},
+		{``, ``},
+	} {
+		if got := HTMLText(tc.html); got != tc.want {
+			t.Errorf("HTMLText(%q) = %q, want %q", tc.html, got, tc.want)
+		}
+	}
+}
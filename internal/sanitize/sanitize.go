@@ -0,0 +1,53 @@
+// Package sanitize cleans the titles and snippets upstreams return before
+// they reach clients: HTML entities are decoded, GitHub-style emoji codes
+// replaced, whitespace collapsed, and snippets cut to length. It also holds
+// the markdown and HTML helpers fetchers use to turn a field they know to be
+// markdown or HTML into text; that is left to them, as markdown rules
+// applied to arbitrary text would mangle code such as __init__.
+package sanitize
+
+import (
+	"html"
+	"strings"
+)
+
+// Snippet formats, for platforms whose content is written in markdown or
+// HTML
+const (
+	Plain    = "plain"
+	Markdown = "markdown"
+	HTML     = "html"
+)
+
+// MaxSnippetLength is the longest snippet, in characters, a request may ask
+// for
+const MaxSnippetLength = 5000
+
+// Text decodes HTML entities and emoji codes in s and collapses its
+// whitespace to single spaces on one line
+func Text(s string) string {
+	s = html.UnescapeString(strings.ToValidUTF8(s, "\uFFFD"))
+	return strings.Join(strings.Fields(ReplaceEmojiCodes(s)), " ")
+}
+
+// Title cleans a result title
+func Title(title string) string {
+	return Text(title)
+}
+
+// Snippet cleans a snippet in the given format and cuts it to at most
+// maxLength characters of text. Plain snippets become a single line of
+// text; HTML snippets keep a small set of formatting tags and stay well
+// formed; markdown is left as written, as it may carry raw HTML that
+// decoding entities would let through.
+func Snippet(snippet, format string, maxLength int) string {
+	snippet = strings.ToValidUTF8(snippet, "\uFFFD")
+	switch format {
+	case HTML:
+		return HTMLExcerpt(snippet, maxLength)
+	case Markdown:
+		return TruncateString(strings.TrimSpace(snippet), maxLength)
+	default:
+		return TruncateString(Text(snippet), maxLength)
+	}
+}
@@ -0,0 +1,37 @@
+package sanitize
+
+import "testing"
+
+func TestText(t *testing.T) {
+	for s, want := range map[string]string{
+		"Why &quot;nil&quot; &amp; &#39;zero&#39;?": `Why "nil" & 'zero'?`,
+		"  many\n\n\tspaces  ":                      "many spaces",
+		":sparkles: Fast :rocket:":                  "✨ Fast 🚀",
+		"at 12:30:45 and :not_an_emoji:":            "at 12:30:45 and :not_an_emoji:",
+		"bad \xff byte":                             "bad � byte",
+		"Call __init__ on *args":                    "Call __init__ on *args",
+	} {
+		if got := Text(s); got != want {
+			t.Errorf("Text(%q) = %q, want %q", s, got, want)
+		}
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	for _, tc := range []struct {
+		snippet, format string
+		maxLength       int
+		want            string
+	}{
+		{"a &lt; b\n&amp;&amp; c", Plain, 100, "a < b && c"},
+		{"a < b && c", Plain, 5, "a..."},
+		{"<p>Use <strong>buffered</strong> channels</p>", HTML, 10, "<p>Use <strong>buffer...</strong></p>"},
+		{"<p>&lt;b&gt;</p><script>x()</script>", HTML, 100, "<p>&lt;b&gt;</p>"},
+		// Markdown may carry raw HTML, so its entities stay escaped
+		{"  Use **buffered** &lt;chan&gt;\n", Markdown, 100, "Use **buffered** &lt;chan&gt;"},
+	} {
+		if got := Snippet(tc.snippet, tc.format, tc.maxLength); got != tc.want {
+			t.Errorf("Snippet(%q, %s, %d) = %q, want %q", tc.snippet, tc.format, tc.maxLength, got, tc.want)
+		}
+	}
+}
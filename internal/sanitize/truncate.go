@@ -0,0 +1,30 @@
+package sanitize
+
+import (
+	"strings"
+
+	"github.com/rivo/uniseg"
+)
+
+// TruncateString shortens s to at most maxLength characters, ending it with
+// "..." when anything was cut. Characters are grapheme clusters, so neither
+// multi-byte runes nor combined emoji and accents are ever split.
+func TruncateString(s string, maxLength int) string {
+	if uniseg.GraphemeClusterCount(s) <= maxLength {
+		return s
+	}
+	if maxLength <= 3 {
+		return truncateGraphemes(s, maxLength)
+	}
+	return strings.TrimSpace(truncateGraphemes(s, maxLength-3)) + "..."
+}
+
+// truncateGraphemes returns the first n grapheme clusters of s
+func truncateGraphemes(s string, n int) string {
+	graphemes := uniseg.NewGraphemes(s)
+	end := 0
+	for i := 0; i < n && graphemes.Next(); i++ {
+		_, end = graphemes.Positions()
+	}
+	return s[:end]
+}
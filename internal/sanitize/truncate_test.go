@@ -0,0 +1,47 @@
+package sanitize
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateString(t *testing.T) {
+	for _, tc := range []struct {
+		s         string
+		maxLength int
+		want      string
+	}{
+		{"short", 10, "short"},
+		{"exactly10!", 10, "exactly10!"},
+		{"hello world, again", 10, "hello w..."},
+		{"héllo wörld ünïcode", 8, "héllo..."},
+		{"日本語のテキストです", 6, "日本語..."},
+		// A family emoji is one character made of seven runes
+		{"\U0001F468\u200d\U0001F469\u200d\U0001F467 family trip", 4, "\U0001F468\u200d\U0001F469\u200d\U0001F467..."},
+		// e + combining acute accent stays together
+		{"cafe\u0301 au lait", 7, "cafe\u0301..."},
+		{"abcdef", 2, "ab"},
+	} {
+		got := TruncateString(tc.s, tc.maxLength)
+		if got != tc.want {
+			t.Errorf("TruncateString(%q, %d) = %q, want %q", tc.s, tc.maxLength, got, tc.want)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("TruncateString(%q, %d) = %q is not valid UTF-8", tc.s, tc.maxLength, got)
+		}
+	}
+}
+
+func FuzzTruncateString(f *testing.F) {
+	f.Add("héllo wörld", 5)
+	f.Add("\U0001F468\u200d\U0001F469\u200d\U0001F467", 1)
+
+	f.Fuzz(func(t *testing.T, s string, maxLength int) {
+		if !utf8.ValidString(s) || maxLength < 0 || maxLength > 1000 {
+			t.Skip()
+		}
+		if got := TruncateString(s, maxLength); !utf8.ValidString(got) {
+			t.Errorf("TruncateString(%q, %d) = %q is not valid UTF-8", s, maxLength, got)
+		}
+	})
+}
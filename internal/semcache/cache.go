@@ -0,0 +1,154 @@
+package semcache
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+// Cache serves responses for queries that are semantically close to a query
+// answered earlier. Entries only match requests for the same platforms and
+// result count.
+type Cache struct {
+	mu         sync.Mutex
+	entries    []*entry
+	threshold  float64
+	ttl        time.Duration
+	maxEntries int
+}
+
+type entry struct {
+	query     string
+	scope     string
+	vector    []float32
+	norm      float64
+	response  *pb.SearchResponse
+	storedAt  time.Time
+	expiresAt time.Time
+}
+
+// Hit is a cached response together with how closely it matched
+type Hit struct {
+	Response   *pb.SearchResponse
+	Query      string
+	Similarity float64
+	// Age is how long ago the response was cached
+	Age time.Duration
+}
+
+// New creates a cache matching entries with cosine similarity >= threshold
+func New(threshold float64, ttl time.Duration, maxEntries int) *Cache {
+	return &Cache{
+		threshold:  threshold,
+		ttl:        ttl,
+		maxEntries: maxEntries,
+	}
+}
+
+// Scope identifies the request parameters a cached response is valid for.
+// options encodes any other request options that change the response.
+func Scope(platforms []string, maxResults int, options string) string {
+	sorted := append([]string(nil), platforms...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",") + "|" + strconv.Itoa(maxResults) + "|" + options
+}
+
+// Lookup returns the closest live entry in scope, or nil if none is similar
+// enough. The returned response is a copy the caller may modify.
+func (c *Cache) Lookup(scope string, vector []float32) *Hit {
+	norm := vectorNorm(vector)
+	if norm == 0 {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	var best *entry
+	bestSimilarity := c.threshold
+	live := c.entries[:0]
+	for _, e := range c.entries {
+		if now.After(e.expiresAt) {
+			continue
+		}
+		live = append(live, e)
+
+		if e.scope != scope || len(e.vector) != len(vector) {
+			continue
+		}
+		if similarity := cosine(e.vector, e.norm, vector, norm); similarity >= bestSimilarity {
+			best, bestSimilarity = e, similarity
+		}
+	}
+	c.entries = live
+
+	if best == nil {
+		return nil
+	}
+	return &Hit{
+		Response:   proto.Clone(best.response).(*pb.SearchResponse),
+		Query:      best.query,
+		Similarity: bestSimilarity,
+		Age:        now.Sub(best.storedAt),
+	}
+}
+
+// Store caches response for query, evicting the oldest entry when full
+func (c *Cache) Store(query, scope string, vector []float32, response *pb.SearchResponse) {
+	norm := vectorNorm(vector)
+	if norm == 0 {
+		return
+	}
+
+	e := &entry{
+		query:    query,
+		scope:    scope,
+		vector:   vector,
+		norm:     norm,
+		response: proto.Clone(response).(*pb.SearchResponse),
+		storedAt: time.Now(),
+	}
+	e.expiresAt = e.storedAt.Add(c.ttl)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.maxEntries > 0 && len(c.entries) >= c.maxEntries {
+		c.entries = c.entries[1:]
+	}
+	c.entries = append(c.entries, e)
+}
+
+// Flush removes every entry and returns how many there were
+func (c *Cache) Flush() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := len(c.entries)
+	c.entries = nil
+	return n
+}
+
+func cosine(a []float32, normA float64, b []float32, normB float64) float64 {
+	var dot float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+	}
+	return dot / (normA * normB)
+}
+
+func vectorNorm(v []float32) float64 {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	return math.Sqrt(sum)
+}
@@ -0,0 +1,123 @@
+package semcache
+
+import (
+	"testing"
+	"time"
+
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+func response(title string) *pb.SearchResponse {
+	return &pb.SearchResponse{Results: []*pb.Result{{Title: title}}, TotalCount: 1}
+}
+
+func TestLookupMatchesSimilarQueries(t *testing.T) {
+	c := New(0.9, time.Minute, 0)
+	scope := Scope([]string{"github"}, 10, "")
+	c.Store("golang generics", scope, []float32{1, 0, 0}, response("generics"))
+	c.Store("rust async", scope, []float32{0, 1, 0}, response("async"))
+
+	tests := []struct {
+		name   string
+		scope  string
+		vector []float32
+		// want is the cached query that should answer, or "" for a miss
+		want string
+	}{
+		{name: "same vector", scope: scope, vector: []float32{1, 0, 0}, want: "golang generics"},
+		{name: "scaled vector", scope: scope, vector: []float32{3, 0, 0}, want: "golang generics"},
+		{name: "close vector", scope: scope, vector: []float32{1, 0.2, 0}, want: "golang generics"},
+		{name: "closest of two", scope: scope, vector: []float32{0.1, 1, 0}, want: "rust async"},
+		{name: "below threshold", scope: scope, vector: []float32{1, 1, 0}},
+		{name: "unrelated", scope: scope, vector: []float32{0, 0, 1}},
+		{name: "zero vector", scope: scope, vector: []float32{0, 0, 0}},
+		{name: "other dimensions", scope: scope, vector: []float32{1, 0}},
+		{name: "other scope", scope: Scope([]string{"github"}, 20, ""), vector: []float32{1, 0, 0}},
+	}
+	for _, tt := range tests {
+		hit := c.Lookup(tt.scope, tt.vector)
+		switch {
+		case tt.want == "" && hit != nil:
+			t.Errorf("%s: Lookup() = %q, want a miss", tt.name, hit.Query)
+		case tt.want != "" && hit == nil:
+			t.Errorf("%s: Lookup() missed, want %q", tt.name, tt.want)
+		case tt.want != "" && (hit.Query != tt.want || hit.Similarity < 0.9):
+			t.Errorf("%s: Lookup() = %q at %.2f, want %q", tt.name, hit.Query, hit.Similarity, tt.want)
+		}
+	}
+}
+
+func TestLookupReturnsACopy(t *testing.T) {
+	c := New(0.9, time.Minute, 0)
+	original := response("generics")
+	c.Store("golang generics", "s", []float32{1, 0}, original)
+	original.Results[0].Title = "changed after Store"
+
+	hit := c.Lookup("s", []float32{1, 0})
+	if hit == nil || hit.Response.Results[0].Title != "generics" {
+		t.Fatalf("Lookup() = %+v, want the response as stored", hit)
+	}
+	hit.Response.Results[0].Title = "changed by caller"
+	if again := c.Lookup("s", []float32{1, 0}); again.Response.Results[0].Title != "generics" {
+		t.Errorf("cached title = %q after the caller changed its copy", again.Response.Results[0].Title)
+	}
+}
+
+func TestEntriesExpire(t *testing.T) {
+	c := New(0.9, 20*time.Millisecond, 0)
+	c.Store("golang", "s", []float32{1, 0}, response("go"))
+	if c.Lookup("s", []float32{1, 0}) == nil {
+		t.Fatal("Lookup() missed a fresh entry")
+	}
+	time.Sleep(30 * time.Millisecond)
+	if hit := c.Lookup("s", []float32{1, 0}); hit != nil {
+		t.Errorf("Lookup() = %q after the TTL, want a miss", hit.Query)
+	}
+	if n := c.Flush(); n != 0 {
+		t.Errorf("Flush() = %d, want the expired entry already dropped", n)
+	}
+}
+
+func TestStoreEvictsOldest(t *testing.T) {
+	c := New(0.9, time.Minute, 2)
+	c.Store("first", "s", []float32{1, 0, 0}, response("1"))
+	c.Store("second", "s", []float32{0, 1, 0}, response("2"))
+	c.Store("third", "s", []float32{0, 0, 1}, response("3"))
+
+	if hit := c.Lookup("s", []float32{1, 0, 0}); hit != nil {
+		t.Errorf("Lookup(first) = %q, want it evicted", hit.Query)
+	}
+	for query, vector := range map[string][]float32{"second": {0, 1, 0}, "third": {0, 0, 1}} {
+		if hit := c.Lookup("s", vector); hit == nil || hit.Query != query {
+			t.Errorf("Lookup(%s) = %+v, want it kept", query, hit)
+		}
+	}
+	if n := c.Flush(); n != 2 {
+		t.Errorf("Flush() = %d, want 2", n)
+	}
+	if hit := c.Lookup("s", []float32{0, 0, 1}); hit != nil {
+		t.Errorf("Lookup() = %q after Flush, want a miss", hit.Query)
+	}
+}
+
+func TestScope(t *testing.T) {
+	a := Scope([]string{"reddit", "github"}, 10, "sort=new")
+	if b := Scope([]string{"github", "reddit"}, 10, "sort=new"); a != b {
+		t.Errorf("Scope() depends on platform order: %q != %q", a, b)
+	}
+	for _, other := range []string{
+		Scope([]string{"github"}, 10, "sort=new"),
+		Scope([]string{"reddit", "github"}, 5, "sort=new"),
+		Scope([]string{"reddit", "github"}, 10, ""),
+	} {
+		if other == a {
+			t.Errorf("Scope() = %q for different requests", a)
+		}
+	}
+
+	platforms := []string{"reddit", "github"}
+	Scope(platforms, 10, "")
+	if platforms[0] != "reddit" {
+		t.Error("Scope() reordered the caller's platforms")
+	}
+}
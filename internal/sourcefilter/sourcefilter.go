@@ -0,0 +1,189 @@
+// Package sourcefilter decides which result sources a deployment or a client
+// wants to see, from allow and deny rules such as "subreddit:golang",
+// "org:microsoft", "domain:example.com" or "author:reddit/spez"
+package sourcefilter
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+// Rule kinds
+const (
+	// KindDomain matches the host of the result URL or of the page a Reddit
+	// link post points to, including subdomains. It applies to every result.
+	KindDomain = "domain"
+	// KindSubreddit matches the subreddit of Reddit results
+	KindSubreddit = "subreddit"
+	// KindOrg matches the owning user or organization of GitHub results
+	KindOrg = "org"
+	// KindAuthor matches the handle or display name of a result's author,
+	// on any platform, or on one when written "platform/handle". It applies
+	// to results that name an author.
+	KindAuthor = "author"
+)
+
+// Rule matches results from one source
+type Rule struct {
+	Kind  string
+	Value string
+}
+
+// Parse parses rules written as "kind:value"
+func Parse(entries []string) ([]Rule, error) {
+	rules := make([]Rule, 0, len(entries))
+	for _, entry := range entries {
+		kind, value, _ := strings.Cut(strings.TrimSpace(entry), ":")
+		kind = strings.ToLower(strings.TrimSpace(kind))
+		value = strings.ToLower(strings.TrimSpace(value))
+
+		switch kind {
+		case KindDomain:
+			value = strings.TrimPrefix(value, "www.")
+		case KindSubreddit:
+			value = strings.TrimPrefix(value, "r/")
+		case KindOrg:
+		case KindAuthor:
+			value = strings.TrimPrefix(value, "@")
+		default:
+			return nil, fmt.Errorf("invalid source rule %q (want domain:, subreddit:, org: or author: followed by a name)", entry)
+		}
+		if value == "" {
+			return nil, fmt.Errorf("invalid source rule %q: missing name", entry)
+		}
+		rules = append(rules, Rule{Kind: kind, Value: value})
+	}
+	return rules, nil
+}
+
+// ParseList parses a comma-separated list of rules
+func ParseList(list string) ([]Rule, error) {
+	var entries []string
+	for _, entry := range strings.Split(list, ",") {
+		if strings.TrimSpace(entry) != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return Parse(entries)
+}
+
+// Filter keeps results that match no deny rule and, for every kind that has
+// allow rules and applies to the result, match one of them. Allowing
+// subreddit:golang therefore narrows Reddit results without touching GitHub
+// ones.
+type Filter struct {
+	allow []Rule
+	deny  []Rule
+}
+
+// New creates a filter, or returns nil when there are no rules
+func New(allow, deny []Rule) *Filter {
+	if len(allow) == 0 && len(deny) == 0 {
+		return nil
+	}
+	return &Filter{allow: allow, deny: deny}
+}
+
+// Keep reports whether result passes the filter. A nil filter keeps
+// everything.
+func (f *Filter) Keep(result *pb.Result) bool {
+	if f == nil {
+		return true
+	}
+
+	for _, rule := range f.deny {
+		if applies, matches := match(rule, result); applies && matches {
+			return false
+		}
+	}
+
+	allowed := make(map[string]bool)
+	restricted := make(map[string]bool)
+	for _, rule := range f.allow {
+		applies, matches := match(rule, result)
+		if !applies {
+			continue
+		}
+		restricted[rule.Kind] = true
+		if matches {
+			allowed[rule.Kind] = true
+		}
+	}
+	for kind := range restricted {
+		if !allowed[kind] {
+			return false
+		}
+	}
+	return true
+}
+
+// match reports whether rule's kind applies to result and, if so, whether
+// result is from the rule's source
+func match(rule Rule, result *pb.Result) (applies, matches bool) {
+	switch rule.Kind {
+	case KindDomain:
+		return true, hostMatches(hostOf(result.Url), rule.Value) ||
+			hostMatches(result.Metadata["link_domain"], rule.Value)
+	case KindSubreddit:
+		if result.Platform != "reddit" {
+			return false, false
+		}
+		return true, strings.EqualFold(result.Metadata["subreddit"], rule.Value)
+	case KindOrg:
+		if result.Platform != "github" {
+			return false, false
+		}
+		return true, strings.EqualFold(githubOwner(result), rule.Value)
+	case KindAuthor:
+		author := result.Author
+		if author == nil || (author.Handle == "" && author.Name == "") {
+			return false, false
+		}
+		name := rule.Value
+		if platform, handle, ok := strings.Cut(name, "/"); ok {
+			if !strings.EqualFold(result.Platform, platform) {
+				return false, false
+			}
+			name = handle
+		}
+		return true, strings.EqualFold(author.Handle, name) || strings.EqualFold(author.Name, name)
+	}
+	return false, false
+}
+
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+// hostMatches reports whether host is domain or one of its subdomains
+func hostMatches(host, domain string) bool {
+	host = strings.ToLower(host)
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// githubOwner returns the owner of a GitHub result, from its author or else
+// the first segment of the repository URL
+func githubOwner(result *pb.Result) string {
+	// Code, issue and discussion hits name their repository; their author
+	// may be anyone
+	if repo := result.Metadata["repository"]; repo != "" {
+		owner, _, _ := strings.Cut(repo, "/")
+		return owner
+	}
+	if result.Author != nil && result.Author.Handle != "" {
+		return result.Author.Handle
+	}
+	u, err := url.Parse(result.Url)
+	if err != nil {
+		return ""
+	}
+	owner, _, _ := strings.Cut(strings.TrimPrefix(u.Path, "/"), "/")
+	return owner
+}
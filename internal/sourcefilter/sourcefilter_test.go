@@ -0,0 +1,102 @@
+package sourcefilter
+
+import (
+	"testing"
+
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+var (
+	golangPost = &pb.Result{Platform: "reddit", Url: "https://www.reddit.com/r/golang/comments/1/x/",
+		Metadata: map[string]string{"subreddit": "golang"}, Author: &pb.Author{Handle: "gopher"}}
+	spamLink = &pb.Result{Platform: "reddit", Url: "https://www.reddit.com/r/programming/comments/2/y/",
+		Metadata: map[string]string{"subreddit": "programming", "link_domain": "blog.spam.example"},
+		Author:   &pb.Author{Handle: "promo123", Name: "Spam Bot"}}
+	msRepo = &pb.Result{Platform: "github", Url: "https://github.com/microsoft/vscode",
+		Author: &pb.Author{Handle: "microsoft"}}
+	goRepo = &pb.Result{Platform: "github", Url: "https://github.com/golang/go"}
+)
+
+func mustParse(t *testing.T, list string) []Rule {
+	t.Helper()
+	rules, err := ParseList(list)
+	if err != nil {
+		t.Fatalf("ParseList(%q) error = %v", list, err)
+	}
+	return rules
+}
+
+func TestFilterKeep(t *testing.T) {
+	tests := []struct {
+		name        string
+		allow, deny string
+		want        map[*pb.Result]bool
+	}{
+		{
+			name: "deny domain matches subdomains and link posts",
+			deny: "domain:spam.example",
+			want: map[*pb.Result]bool{golangPost: true, spamLink: false, msRepo: true, goRepo: true},
+		},
+		{
+			name: "deny org",
+			deny: "org:Microsoft",
+			want: map[*pb.Result]bool{golangPost: true, spamLink: true, msRepo: false, goRepo: true},
+		},
+		{
+			name:  "allow subreddit leaves other platforms alone",
+			allow: "subreddit:r/golang",
+			want:  map[*pb.Result]bool{golangPost: true, spamLink: false, msRepo: true, goRepo: true},
+		},
+		{
+			name:  "allow kinds combine",
+			allow: "subreddit:golang, org:golang",
+			want:  map[*pb.Result]bool{golangPost: true, spamLink: false, msRepo: false, goRepo: true},
+		},
+		{
+			name: "deny author by handle or name",
+			deny: "author:@promo123, author:microsoft",
+			want: map[*pb.Result]bool{golangPost: true, spamLink: false, msRepo: false, goRepo: true},
+		},
+		{
+			name: "deny author on one platform",
+			deny: "author:github/gopher, author:reddit/spam bot",
+			want: map[*pb.Result]bool{golangPost: true, spamLink: false, msRepo: true, goRepo: true},
+		},
+		{
+			name:  "allow author leaves authorless and other platforms' results alone",
+			allow: "author:reddit/gopher",
+			want:  map[*pb.Result]bool{golangPost: true, spamLink: false, msRepo: true, goRepo: true},
+		},
+		{
+			name:  "deny wins over allow",
+			allow: "subreddit:golang",
+			deny:  "domain:reddit.com",
+			want:  map[*pb.Result]bool{golangPost: false, spamLink: false, msRepo: true, goRepo: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := New(mustParse(t, tt.allow), mustParse(t, tt.deny))
+			for result, want := range tt.want {
+				if got := filter.Keep(result); got != want {
+					t.Errorf("Keep(%s) = %v, want %v", result.Url, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNilFilterKeepsEverything(t *testing.T) {
+	filter := New(nil, nil)
+	if filter != nil || !filter.Keep(spamLink) {
+		t.Errorf("New(nil, nil) = %v, want a nil filter that keeps results", filter)
+	}
+}
+
+func TestParseRejectsUnknownRules(t *testing.T) {
+	for _, entry := range []string{"spam.example", "user:bob", "subreddit:", "org: "} {
+		if _, err := Parse([]string{entry}); err == nil {
+			t.Errorf("Parse(%q) error = nil, want an error", entry)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	// Database drivers selectable via STORE_DRIVER
+	_ "github.com/jackc/pgx/v5/stdlib"
+	_ "modernc.org/sqlite"
+
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+// Store persists executed searches and summaries of their results for
+// history, trending and offline analytics
+type Store struct {
+	db     *sql.DB
+	driver string
+}
+
+// SearchRecord summarizes one executed search
+type SearchRecord struct {
+	ID               int64
+	Query            string
+	Platforms        []string
+	ResultCount      int
+	ResponseTimeMs   int
+	PlatformsSuccess []string
+	PlatformsTimeout []string
+	PlatformsError   []string
+	CreatedAt        time.Time
+}
+
+// TrendingQuery is a query and how often it was searched in a time window
+type TrendingQuery struct {
+	Query string
+	Count int
+}
+
+// Open connects to the database and creates the schema if it is missing.
+// driver is "sqlite" or "postgres".
+func Open(ctx context.Context, driver, dsn string) (*Store, error) {
+	var sqlDriver string
+	switch driver {
+	case "sqlite":
+		sqlDriver = "sqlite"
+	case "postgres":
+		sqlDriver = "pgx"
+	default:
+		return nil, fmt.Errorf("unsupported store driver %q (valid: sqlite, postgres)", driver)
+	}
+
+	if driver == "sqlite" {
+		dsn = sqliteDSN(dsn)
+	}
+	db, err := sql.Open(sqlDriver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s store: %w", driver, err)
+	}
+	if driver == "sqlite" {
+		// SQLite allows a single writer; serializing avoids SQLITE_BUSY errors
+		db.SetMaxOpenConns(1)
+	}
+
+	s := &Store{db: db, driver: driver}
+	if err := s.migrate(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Close closes the underlying database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func (s *Store) migrate(ctx context.Context) error {
+	idColumn := "INTEGER PRIMARY KEY AUTOINCREMENT"
+	if s.driver == "postgres" {
+		idColumn = "BIGSERIAL PRIMARY KEY"
+	}
+
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS searches (
+			id ` + idColumn + `,
+			query TEXT NOT NULL,
+			platforms TEXT NOT NULL,
+			result_count INTEGER NOT NULL,
+			response_time_ms INTEGER NOT NULL,
+			platforms_success TEXT NOT NULL,
+			platforms_timeout TEXT NOT NULL,
+			platforms_error TEXT NOT NULL,
+			created_at BIGINT NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_searches_created_at ON searches (created_at)`,
+		`CREATE TABLE IF NOT EXISTS search_results (
+			search_id BIGINT NOT NULL REFERENCES searches (id) ON DELETE CASCADE,
+			position INTEGER NOT NULL,
+			platform TEXT NOT NULL,
+			title TEXT NOT NULL,
+			url TEXT NOT NULL,
+			PRIMARY KEY (search_id, position)
+		)`,
+	}
+
+	for _, stmt := range statements {
+		if _, err := s.db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to migrate store schema: %w", err)
+		}
+	}
+	return nil
+}
+
+// RecordSearch stores a search and a summary of its results, returning the
+// new search ID
+func (s *Store) RecordSearch(ctx context.Context, req *pb.SearchRequest, resp *pb.SearchResponse) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var responseTimeMs int32
+	if resp.Metadata != nil {
+		responseTimeMs = resp.Metadata.ResponseTimeMs
+	}
+
+	var searchID int64
+	err = tx.QueryRowContext(ctx, s.rebind(`
+		INSERT INTO searches (query, platforms, result_count, response_time_ms,
+			platforms_success, platforms_timeout, platforms_error, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id`),
+		req.Query,
+		strings.Join(req.Platforms, ","),
+		len(resp.Results),
+		responseTimeMs,
+		strings.Join(resp.PlatformsSuccess, ","),
+		strings.Join(resp.PlatformsTimeout, ","),
+		strings.Join(resp.PlatformsError, ","),
+		time.Now().Unix(),
+	).Scan(&searchID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert search: %w", err)
+	}
+
+	insertResult := s.rebind(`INSERT INTO search_results (search_id, position, platform, title, url) VALUES (?, ?, ?, ?, ?)`)
+	for position, result := range resp.Results {
+		if _, err := tx.ExecContext(ctx, insertResult, searchID, position, result.Platform, result.Title, result.Url); err != nil {
+			return 0, fmt.Errorf("failed to insert search result: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit search: %w", err)
+	}
+	return searchID, nil
+}
+
+// RecentSearches returns the most recent searches, newest first
+func (s *Store) RecentSearches(ctx context.Context, limit int) ([]*SearchRecord, error) {
+	rows, err := s.db.QueryContext(ctx, s.rebind(`
+		SELECT id, query, platforms, result_count, response_time_ms,
+			platforms_success, platforms_timeout, platforms_error, created_at
+		FROM searches
+		ORDER BY created_at DESC, id DESC
+		LIMIT ?`), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query searches: %w", err)
+	}
+	defer rows.Close()
+
+	var records []*SearchRecord
+	for rows.Next() {
+		var (
+			record                            SearchRecord
+			platforms, success, timeout, errs string
+			createdAt                         int64
+		)
+		if err := rows.Scan(&record.ID, &record.Query, &platforms, &record.ResultCount, &record.ResponseTimeMs,
+			&success, &timeout, &errs, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan search: %w", err)
+		}
+		record.Platforms = splitList(platforms)
+		record.PlatformsSuccess = splitList(success)
+		record.PlatformsTimeout = splitList(timeout)
+		record.PlatformsError = splitList(errs)
+		record.CreatedAt = time.Unix(createdAt, 0)
+		records = append(records, &record)
+	}
+	return records, rows.Err()
+}
+
+// TrendingQueries returns the most frequent queries searched since the given
+// time, compared case-insensitively
+func (s *Store) TrendingQueries(ctx context.Context, since time.Time, limit int) ([]TrendingQuery, error) {
+	rows, err := s.db.QueryContext(ctx, s.rebind(`
+		SELECT LOWER(query) AS q, COUNT(*) AS n
+		FROM searches
+		WHERE created_at >= ?
+		GROUP BY q
+		ORDER BY n DESC, q
+		LIMIT ?`), since.Unix(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query trending searches: %w", err)
+	}
+	defer rows.Close()
+
+	var trending []TrendingQuery
+	for rows.Next() {
+		var t TrendingQuery
+		if err := rows.Scan(&t.Query, &t.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan trending query: %w", err)
+		}
+		trending = append(trending, t)
+	}
+	return trending, rows.Err()
+}
+
+// rebind converts '?' placeholders to the driver's native syntax
+func (s *Store) rebind(query string) string {
+	if s.driver != "postgres" {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// sqliteDSN turns foreign keys on for every connection; SQLite leaves them
+// off, which would let results outlive their search
+func sqliteDSN(dsn string) string {
+	separator := "?"
+	if strings.Contains(dsn, "?") {
+		separator = "&"
+	}
+	return dsn + separator + "_pragma=foreign_keys(1)"
+}
+
+func splitList(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
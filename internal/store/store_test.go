@@ -0,0 +1,164 @@
+package store
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+func openTestStore(t *testing.T) *Store {
+	t.Helper()
+	s, err := Open(context.Background(), "sqlite", filepath.Join(t.TempDir(), "searches.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestRecordSearchStoresResults(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+
+	req := &pb.SearchRequest{Query: "golang", Platforms: []string{"github", "reddit"}}
+	resp := &pb.SearchResponse{
+		Results: []*pb.Result{
+			{Platform: "github", Title: "golang/go", Url: "https://github.com/golang/go"},
+			{Platform: "reddit", Title: "r/golang", Url: "https://reddit.com/r/golang"},
+		},
+		PlatformsSuccess: []string{"github"},
+		PlatformsTimeout: []string{"reddit"},
+		Metadata:         &pb.ResponseMetadata{ResponseTimeMs: 120},
+	}
+	id, err := s.RecordSearch(ctx, req, resp)
+	if err != nil {
+		t.Fatalf("RecordSearch() error = %v", err)
+	}
+
+	var query, platforms, success, timeout string
+	var resultCount, responseTime int
+	err = s.db.QueryRowContext(ctx, `SELECT query, platforms, result_count, response_time_ms, platforms_success, platforms_timeout
+		FROM searches WHERE id = ?`, id).Scan(&query, &platforms, &resultCount, &responseTime, &success, &timeout)
+	if err != nil {
+		t.Fatalf("reading search %d: %v", id, err)
+	}
+	if query != "golang" || platforms != "github,reddit" || resultCount != 2 || responseTime != 120 ||
+		success != "github" || timeout != "reddit" {
+		t.Errorf("search = %q %q %d %d %q %q", query, platforms, resultCount, responseTime, success, timeout)
+	}
+
+	var title string
+	if err := s.db.QueryRowContext(ctx, `SELECT title FROM search_results WHERE search_id = ? AND position = 1`, id).Scan(&title); err != nil || title != "r/golang" {
+		t.Errorf("result 1 = %q, %v; want r/golang", title, err)
+	}
+}
+
+func TestRecentSearchesNewestFirst(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+
+	for _, query := range []string{"first", "second", "third"} {
+		resp := &pb.SearchResponse{
+			Results:          []*pb.Result{{Platform: "github"}},
+			PlatformsSuccess: []string{"github"},
+			PlatformsError:   []string{"reddit"},
+		}
+		if _, err := s.RecordSearch(ctx, &pb.SearchRequest{Query: query, Platforms: []string{"github", "reddit"}}, resp); err != nil {
+			t.Fatalf("RecordSearch(%s) error = %v", query, err)
+		}
+	}
+
+	records, err := s.RecentSearches(ctx, 2)
+	if err != nil {
+		t.Fatalf("RecentSearches() error = %v", err)
+	}
+	if len(records) != 2 || records[0].Query != "third" || records[1].Query != "second" {
+		t.Fatalf("RecentSearches(2) = %v, want third then second", records)
+	}
+	got := records[0]
+	if !slices.Equal(got.Platforms, []string{"github", "reddit"}) || got.ResultCount != 1 ||
+		!slices.Equal(got.PlatformsSuccess, []string{"github"}) || got.PlatformsTimeout != nil ||
+		!slices.Equal(got.PlatformsError, []string{"reddit"}) || got.CreatedAt.IsZero() {
+		t.Errorf("record = %+v, want the search as recorded", got)
+	}
+}
+
+func TestTrendingQueriesCountsCaseInsensitively(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+
+	for _, query := range []string{"Golang", "golang", "rust", "GOLANG", "rust", "zig"} {
+		if _, err := s.RecordSearch(ctx, &pb.SearchRequest{Query: query}, &pb.SearchResponse{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A search from before the window doesn't count
+	if _, err := s.db.ExecContext(ctx, `UPDATE searches SET created_at = 0 WHERE query = 'zig'`); err != nil {
+		t.Fatal(err)
+	}
+
+	trending, err := s.TrendingQueries(ctx, time.Now().Add(-time.Hour), 10)
+	if err != nil {
+		t.Fatalf("TrendingQueries() error = %v", err)
+	}
+	want := []TrendingQuery{{"golang", 3}, {"rust", 2}}
+	if !slices.Equal(trending, want) {
+		t.Errorf("TrendingQueries() = %v, want %v", trending, want)
+	}
+	if top, _ := s.TrendingQueries(ctx, time.Now().Add(-time.Hour), 1); len(top) != 1 || top[0].Query != "golang" {
+		t.Errorf("TrendingQueries(limit 1) = %v, want golang only", top)
+	}
+}
+
+func TestSQLiteEnforcesForeignKeys(t *testing.T) {
+	s := openTestStore(t)
+	ctx := context.Background()
+
+	if _, err := s.db.ExecContext(ctx, `INSERT INTO search_results (search_id, position, platform, title, url) VALUES (42, 0, 'github', 't', 'u')`); err == nil {
+		t.Error("inserted a result for a search that doesn't exist")
+	}
+
+	id, err := s.RecordSearch(ctx, &pb.SearchRequest{Query: "go"}, &pb.SearchResponse{Results: []*pb.Result{{Platform: "github"}}})
+	if err != nil {
+		t.Fatalf("RecordSearch() error = %v", err)
+	}
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM searches WHERE id = ?`, id); err != nil {
+		t.Fatalf("deleting search: %v", err)
+	}
+	var left int
+	s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM search_results`).Scan(&left)
+	if left != 0 {
+		t.Errorf("%d results outlived their search, want them deleted with it", left)
+	}
+}
+
+func TestSQLiteDSN(t *testing.T) {
+	for dsn, want := range map[string]string{
+		"searches.db":                   "searches.db?_pragma=foreign_keys(1)",
+		"file:searches.db?cache=shared": "file:searches.db?cache=shared&_pragma=foreign_keys(1)",
+	} {
+		if got := sqliteDSN(dsn); got != want {
+			t.Errorf("sqliteDSN(%q) = %q, want %q", dsn, got, want)
+		}
+	}
+}
+
+func TestRebind(t *testing.T) {
+	query := `INSERT INTO t (a, b) VALUES (?, ?)`
+	if got := (&Store{driver: "sqlite"}).rebind(query); got != query {
+		t.Errorf("sqlite rebind = %q, want it unchanged", got)
+	}
+	if got, want := (&Store{driver: "postgres"}).rebind(query), `INSERT INTO t (a, b) VALUES ($1, $2)`; got != want {
+		t.Errorf("postgres rebind = %q, want %q", got, want)
+	}
+}
+
+func TestOpenRejectsUnknownDriver(t *testing.T) {
+	if _, err := Open(context.Background(), "mysql", ""); err == nil {
+		t.Error("Open(mysql) succeeded, want an error")
+	}
+}
@@ -0,0 +1,175 @@
+// Package suggest derives "people also searched" queries from the tags of a
+// result set and from recent query history
+package suggest
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+// tagKeys are the result metadata fields holding topic tags
+var tagKeys = []string{"tags", "language", "subreddit"}
+
+// minTagResults is how many results must share a tag before it is suggested
+const minTagResults = 2
+
+// History remembers the most recent distinct queries
+type History struct {
+	mu      sync.Mutex
+	queries []string
+	size    int
+}
+
+// NewHistory creates a history holding up to size queries
+func NewHistory(size int) *History {
+	return &History{size: size}
+}
+
+// Add records query as the most recent one
+func (h *History) Add(query string) {
+	query = normalize(query)
+	if query == "" || h.size <= 0 {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for i, q := range h.queries {
+		if q == query {
+			h.queries = append(h.queries[:i], h.queries[i+1:]...)
+			break
+		}
+	}
+	if len(h.queries) >= h.size {
+		h.queries = h.queries[1:]
+	}
+	h.queries = append(h.queries, query)
+}
+
+// recent returns the queries newest first
+func (h *History) recent() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	recent := make([]string, len(h.queries))
+	for i, q := range h.queries {
+		recent[len(h.queries)-1-i] = q
+	}
+	return recent
+}
+
+// Related returns up to limit queries related to query: the query refined by
+// tags that several results share, then recent queries with terms in common.
+// history may be nil.
+func Related(query string, results []*pb.Result, history *History, limit int) []string {
+	if limit <= 0 {
+		return nil
+	}
+	query = normalize(query)
+	queryTerms := terms(query)
+
+	var related []string
+	seen := map[string]bool{query: true}
+	add := func(suggestion string) bool {
+		if !seen[suggestion] {
+			seen[suggestion] = true
+			related = append(related, suggestion)
+		}
+		return len(related) >= limit
+	}
+
+	for _, tag := range sharedTags(results, queryTerms) {
+		if add(query + " " + tag) {
+			return related
+		}
+	}
+
+	if history == nil {
+		return related
+	}
+	type candidate struct {
+		query   string
+		overlap int
+	}
+	var candidates []candidate
+	for _, q := range history.recent() {
+		if overlap := countShared(queryTerms, terms(q)); overlap > 0 {
+			candidates = append(candidates, candidate{q, overlap})
+		}
+	}
+	// Stable, so equally close queries stay newest first
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].overlap > candidates[j].overlap
+	})
+	for _, c := range candidates {
+		if add(c.query) {
+			break
+		}
+	}
+	return related
+}
+
+// sharedTags returns the tags found on at least minTagResults results, most
+// common first, leaving out those already in the query
+func sharedTags(results []*pb.Result, queryTerms map[string]bool) []string {
+	counts := make(map[string]int)
+	for _, result := range results {
+		tags := make(map[string]bool)
+		for _, key := range tagKeys {
+			for _, tag := range strings.Split(result.Metadata[key], ",") {
+				if tag = normalize(tag); tag != "" && !queryTerms[tag] {
+					tags[tag] = true
+				}
+			}
+		}
+		for tag := range tags {
+			counts[tag]++
+		}
+	}
+
+	var shared []string
+	for tag, n := range counts {
+		if n >= minTagResults {
+			shared = append(shared, tag)
+		}
+	}
+	sort.Slice(shared, func(i, j int) bool {
+		if counts[shared[i]] != counts[shared[j]] {
+			return counts[shared[i]] > counts[shared[j]]
+		}
+		return shared[i] < shared[j]
+	})
+	return shared
+}
+
+func normalize(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+// terms splits a query into words, keeping characters like the ones in
+// "c++", "c#" and "node.js"
+func terms(query string) map[string]bool {
+	words := strings.FieldsFunc(query, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("+#.-", r)
+	})
+	set := make(map[string]bool, len(words))
+	for _, w := range words {
+		set[w] = true
+	}
+	return set
+}
+
+func countShared(a, b map[string]bool) int {
+	n := 0
+	for term := range a {
+		if b[term] {
+			n++
+		}
+	}
+	return n
+}
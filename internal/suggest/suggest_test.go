@@ -0,0 +1,68 @@
+package suggest
+
+import (
+	"slices"
+	"testing"
+
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+func result(metadata map[string]string) *pb.Result {
+	return &pb.Result{Metadata: metadata}
+}
+
+func TestRelatedFromSharedTags(t *testing.T) {
+	results := []*pb.Result{
+		result(map[string]string{"tags": "go,concurrency,channels"}),
+		result(map[string]string{"tags": "go,concurrency"}),
+		result(map[string]string{"language": "Go", "stars": "10"}),
+		result(map[string]string{"subreddit": "golang"}),
+		result(map[string]string{"tags": "channels"}),
+	}
+
+	got := Related("Goroutine  Leak", results, nil, 5)
+	want := []string{"goroutine leak go", "goroutine leak channels", "goroutine leak concurrency"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Related() = %q, want %q", got, want)
+	}
+
+	// Tags already in the query add nothing
+	if got := Related("go concurrency", results, nil, 5); !slices.Equal(got, []string{"go concurrency channels"}) {
+		t.Errorf("Related() = %q, want only the channels refinement", got)
+	}
+}
+
+func TestRelatedFromHistory(t *testing.T) {
+	history := NewHistory(3)
+	for _, q := range []string{"rust async", "go generics", "react hooks", "go error wrapping"} {
+		history.Add(q)
+	}
+	history.Add("Go Generics")
+
+	got := Related("go generics tutorial", nil, history, 5)
+	want := []string{"go generics", "go error wrapping"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Related() = %q, want %q", got, want)
+	}
+
+	// The oldest query was evicted, and the query itself is never suggested
+	if got := Related("rust async", nil, history, 5); len(got) != 0 {
+		t.Errorf("Related() = %q, want none", got)
+	}
+}
+
+func TestRelatedRespectsLimit(t *testing.T) {
+	history := NewHistory(10)
+	history.Add("go modules")
+	results := []*pb.Result{
+		result(map[string]string{"tags": "go,modules"}),
+		result(map[string]string{"tags": "go,modules"}),
+	}
+
+	if got := Related("go vendoring", results, history, 1); !slices.Equal(got, []string{"go vendoring modules"}) {
+		t.Errorf("Related() = %q, want just the top tag", got)
+	}
+	if got := Related("go vendoring", results, history, 0); got != nil {
+		t.Errorf("Related(limit=0) = %q, want nil", got)
+	}
+}
@@ -0,0 +1,58 @@
+package summarize
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// answerPrompt asks for an answer grounded in the numbered sources
+const answerPrompt = "Answer the question using only the numbered sources below. " +
+	"Cite every claim with the source number in square brackets, e.g. [2]. " +
+	"If the sources don't answer the question, say so. Reply in Markdown."
+
+// answerMaxTokens bounds the length of an answer
+const answerMaxTokens = 600
+
+// Source is a search result offered to the LLM as evidence
+type Source struct {
+	Title string
+	URL   string
+	Text  string
+}
+
+// Answerer synthesizes an answer to a question from sources. The answer
+// refers to sources[i] as [i+1].
+type Answerer interface {
+	Answer(ctx context.Context, question string, sources []Source) (string, error)
+}
+
+// Answer returns an answer to question citing sources by number
+func (s *HTTPSummarizer) Answer(ctx context.Context, question string, sources []Source) (string, error) {
+	var prompt strings.Builder
+	fmt.Fprintf(&prompt, "Question: %s\n\nSources:\n", question)
+	for i, source := range sources {
+		fmt.Fprintf(&prompt, "[%d] %s (%s)\n%s\n\n", i+1, source.Title, source.URL, source.Text)
+	}
+	return s.complete(ctx, answerPrompt, prompt.String(), answerMaxTokens)
+}
+
+var citationPattern = regexp.MustCompile(`\[(\d+)\]`)
+
+// Citations returns the source numbers cited in answer, in order of first
+// citation, ignoring numbers outside 1..sources
+func Citations(answer string, sources int) []int {
+	var cited []int
+	seen := make(map[int]bool)
+	for _, match := range citationPattern.FindAllStringSubmatch(answer, -1) {
+		n, err := strconv.Atoi(match[1])
+		if err != nil || n < 1 || n > sources || seen[n] {
+			continue
+		}
+		seen[n] = true
+		cited = append(cited, n)
+	}
+	return cited
+}
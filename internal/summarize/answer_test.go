@@ -0,0 +1,24 @@
+package summarize
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCitations(t *testing.T) {
+	tests := []struct {
+		answer  string
+		sources int
+		want    []int
+	}{
+		{"Use channels [2], or a mutex [1][2].", 3, []int{2, 1}},
+		{"Nothing cited.", 3, nil},
+		{"Out of range [0] and [4], array[i] and [12345678901234567890].", 3, nil},
+		{"See [3].", 3, []int{3}},
+	}
+	for _, tt := range tests {
+		if got := Citations(tt.answer, tt.sources); !slices.Equal(got, tt.want) {
+			t.Errorf("Citations(%q, %d) = %v, want %v", tt.answer, tt.sources, got, tt.want)
+		}
+	}
+}
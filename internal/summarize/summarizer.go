@@ -0,0 +1,114 @@
+package summarize
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// systemPrompt asks for a summary that can stand in for a search snippet
+const systemPrompt = "Summarize the following search result in one or two plain sentences. " +
+	"Keep the key technical details. Reply with the summary only."
+
+// summaryMaxTokens bounds the length of a summary
+const summaryMaxTokens = 120
+
+// Summarizer condenses text into a short summary
+type Summarizer interface {
+	Summarize(ctx context.Context, text string) (string, error)
+}
+
+// HTTPSummarizer calls an OpenAI-compatible /chat/completions endpoint.
+// Ollama and most self-hosted model servers expose the same API.
+type HTTPSummarizer struct {
+	url    string
+	model  string
+	apiKey string
+	client *http.Client
+}
+
+// NewHTTPSummarizer creates a summarizer posting to url
+func NewHTTPSummarizer(url, model, apiKey string, client *http.Client) *HTTPSummarizer {
+	return &HTTPSummarizer{
+		url:    url,
+		model:  model,
+		apiKey: apiKey,
+		client: client,
+	}
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model       string        `json:"model"`
+	Messages    []chatMessage `json:"messages"`
+	MaxTokens   int           `json:"max_tokens"`
+	Temperature float64       `json:"temperature"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+// Summarize returns a summary of text
+func (s *HTTPSummarizer) Summarize(ctx context.Context, text string) (string, error) {
+	return s.complete(ctx, systemPrompt, text, summaryMaxTokens)
+}
+
+// complete sends a single-turn chat and returns the reply
+func (s *HTTPSummarizer) complete(ctx context.Context, system, user string, maxTokens int) (string, error) {
+	body, err := json.Marshal(chatRequest{
+		Model: s.model,
+		Messages: []chatMessage{
+			{Role: "system", Content: system},
+			{Role: "user", Content: user},
+		},
+		MaxTokens: maxTokens,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode chat request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("chat API error: status=%d, body=%s", resp.StatusCode, string(body))
+	}
+
+	var chatResp chatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&chatResp); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(chatResp.Choices) == 0 {
+		return "", fmt.Errorf("chat API returned no choices")
+	}
+
+	reply := strings.TrimSpace(chatResp.Choices[0].Message.Content)
+	if reply == "" {
+		return "", fmt.Errorf("chat API returned an empty reply")
+	}
+	return reply, nil
+}
@@ -0,0 +1,94 @@
+// Package testutil provides fake upstream servers that speak just enough of
+// the GitHub, StackOverflow, Reddit, Hacker News, Docker Hub and Dev.to search APIs to exercise the fetchers
+// without network access.
+package testutil
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/farhapartex/search-proxy/internal/fakeupstream"
+)
+
+// Behavior controls how a fake upstream answers
+type Behavior = fakeupstream.Behavior
+
+// Upstream is a fake platform API backed by httptest
+type Upstream struct {
+	*fakeupstream.Handler
+	server *httptest.Server
+}
+
+// NewGitHub starts a fake GitHub search API
+func NewGitHub(t testing.TB) *Upstream {
+	return newUpstream(t, "github")
+}
+
+// NewStackOverflow starts a fake StackExchange search API
+func NewStackOverflow(t testing.TB) *Upstream {
+	return newUpstream(t, "stackoverflow")
+}
+
+// NewReddit starts a fake Reddit search API
+func NewReddit(t testing.TB) *Upstream {
+	return newUpstream(t, "reddit")
+}
+
+// NewHackerNews starts a fake Algolia Hacker News search API
+func NewHackerNews(t testing.TB) *Upstream {
+	return newUpstream(t, "hackernews")
+}
+
+// NewDockerHub starts a fake Docker Hub search API
+func NewDockerHub(t testing.TB) *Upstream {
+	return newUpstream(t, "dockerhub")
+}
+
+// NewDevTo starts a fake Dev.to article search API
+func NewDevTo(t testing.TB) *Upstream {
+	return newUpstream(t, "devto")
+}
+
+func newUpstream(t testing.TB, platform string) *Upstream {
+	handler, err := fakeupstream.NewHandler(platform, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	for _, path := range handler.Paths() {
+		mux.Handle(path, handler)
+	}
+	if platform == "reddit" {
+		mux.HandleFunc(fakeupstream.RedditTokenPath, fakeupstream.RedditToken)
+	}
+
+	u := &Upstream{Handler: handler, server: httptest.NewServer(mux)}
+	t.Cleanup(u.server.Close)
+	return u
+}
+
+// URL returns the base URL to configure the fetcher with
+func (u *Upstream) URL() string {
+	return u.server.URL
+}
+
+// Client returns an HTTP client that sends every request to this server
+// regardless of the host in the URL, for fetchers with a fixed endpoint
+func (u *Upstream) Client() *http.Client {
+	target, _ := url.Parse(u.server.URL)
+	return &http.Client{Transport: rewriteTransport{target: target}}
+}
+
+type rewriteTransport struct {
+	target *url.URL
+}
+
+func (t rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
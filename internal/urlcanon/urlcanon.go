@@ -0,0 +1,126 @@
+package urlcanon
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// trackingParams are query parameters that only identify where a click came
+// from. Parameters starting with utm_ are always dropped as well.
+var trackingParams = map[string]bool{
+	"fbclid":   true,
+	"gclid":    true,
+	"dclid":    true,
+	"msclkid":  true,
+	"mc_cid":   true,
+	"mc_eid":   true,
+	"igshid":   true,
+	"ref_src":  true,
+	"ref_url":  true,
+	"share_id": true,
+	"_ga":      true,
+}
+
+// hostAliases maps alternate hosts to the one each platform links to
+var hostAliases = map[string]string{
+	"reddit.com":            "www.reddit.com",
+	"old.reddit.com":        "www.reddit.com",
+	"new.reddit.com":        "www.reddit.com",
+	"np.reddit.com":         "www.reddit.com",
+	"m.reddit.com":          "www.reddit.com",
+	"www.github.com":        "github.com",
+	"www.stackoverflow.com": "stackoverflow.com",
+}
+
+// stackShareLink matches Stack Exchange share links: /q/<question>/<user>
+// and /a/<answer>/<user>
+var stackShareLink = regexp.MustCompile(`^/(q|a)/(\d+)(/\d+)?/?$`)
+
+// Canonicalize rewrites a result URL into one canonical spelling, so the same
+// page linked in different ways compares equal. URLs that don't parse as
+// absolute http(s) URLs are returned unchanged.
+func Canonicalize(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || !u.IsAbs() {
+		return raw
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return raw
+	}
+
+	host := desktopHost(strings.ToLower(u.Hostname()))
+	if alias, ok := hostAliases[host]; ok {
+		host = alias
+	}
+	if port := u.Port(); port != "" && !isDefaultPort(u.Scheme, port) {
+		host += ":" + port
+	}
+	u.Host = host
+
+	if isStackExchangeHost(host) {
+		resolveStackShareLink(u)
+	}
+	if host == "redd.it" {
+		// Short links carry the post ID as the whole path
+		u.Host, u.Path = "www.reddit.com", "/comments"+u.Path
+	}
+
+	// "/post/" and "/post" are the same page everywhere we link to
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawPath = strings.TrimRight(u.RawPath, "/")
+
+	u.RawQuery = stripTracking(u.Query())
+	return u.String()
+}
+
+// desktopHost drops the "m" or "mobile" label of mobile sites, as in
+// m.youtube.com or en.m.wikipedia.org. The registrable domain is left alone.
+func desktopHost(host string) string {
+	labels := strings.Split(host, ".")
+	kept := labels[:0]
+	for i, label := range labels {
+		if (label == "m" || label == "mobile") && i < len(labels)-2 {
+			continue
+		}
+		kept = append(kept, label)
+	}
+	return strings.Join(kept, ".")
+}
+
+// resolveStackShareLink turns a question share link into the question URL
+// and drops the sharing user's ID from answer share links
+func resolveStackShareLink(u *url.URL) {
+	match := stackShareLink.FindStringSubmatch(u.Path)
+	if match == nil {
+		return
+	}
+	if match[1] == "q" {
+		u.Path = "/questions/" + match[2]
+		return
+	}
+	u.Path = "/a/" + match[2]
+}
+
+func isStackExchangeHost(host string) bool {
+	return host == "stackoverflow.com" || strings.HasSuffix(host, ".stackoverflow.com") ||
+		strings.HasSuffix(host, ".stackexchange.com") || host == "superuser.com" ||
+		host == "serverfault.com" || host == "askubuntu.com"
+}
+
+func isDefaultPort(scheme, port string) bool {
+	return (scheme == "http" && port == "80") || (scheme == "https" && port == "443")
+}
+
+// stripTracking drops tracking parameters and encodes the rest in sorted
+// order
+func stripTracking(query url.Values) string {
+	for name := range query {
+		if trackingParams[strings.ToLower(name)] || strings.HasPrefix(strings.ToLower(name), "utm_") {
+			query.Del(name)
+		}
+	}
+	return query.Encode()
+}
@@ -0,0 +1,39 @@
+package urlcanon
+
+import "testing"
+
+func TestCanonicalize(t *testing.T) {
+	for _, tc := range []struct {
+		raw  string
+		want string
+	}{
+		{"HTTPS://GitHub.com/golang/go", "https://github.com/golang/go"},
+		{"https://www.github.com/golang/go", "https://github.com/golang/go"},
+		{"https://github.com:443/golang/go", "https://github.com/golang/go"},
+		{"http://example.com:8080/a", "http://example.com:8080/a"},
+		{"https://example.com/post?utm_source=hn&utm_medium=x&id=7&fbclid=abc", "https://example.com/post?id=7"},
+		{"https://example.com/search?b=2&a=1", "https://example.com/search?a=1&b=2"},
+		{"https://stackoverflow.com/q/12345/678", "https://stackoverflow.com/questions/12345"},
+		{"https://stackoverflow.com/q/12345", "https://stackoverflow.com/questions/12345"},
+		{"https://stackoverflow.com/a/999/678", "https://stackoverflow.com/a/999"},
+		{"https://unix.stackexchange.com/q/42/7", "https://unix.stackexchange.com/questions/42"},
+		{"https://stackoverflow.com/questions/12345/some-title", "https://stackoverflow.com/questions/12345/some-title"},
+		{"https://old.reddit.com/r/golang/comments/abc/x/?share_id=Q&utm_source=share", "https://www.reddit.com/r/golang/comments/abc/x"},
+		{"https://m.reddit.com/r/golang/", "https://www.reddit.com/r/golang"},
+		{"https://blog.example.com/post/", "https://blog.example.com/post"},
+		{"https://example.com/", "https://example.com"},
+		{"https://m.youtube.com/watch?v=abc", "https://youtube.com/watch?v=abc"},
+		{"https://en.m.wikipedia.org/wiki/Go", "https://en.wikipedia.org/wiki/Go"},
+		{"https://mobile.twitter.com/golang", "https://twitter.com/golang"},
+		{"https://m.com/page", "https://m.com/page"},
+		{"https://redd.it/abc123", "https://www.reddit.com/comments/abc123"},
+		{"https://example.com/page#section", "https://example.com/page#section"},
+		{"not a url", "not a url"},
+		{"/relative/path", "/relative/path"},
+		{"mailto:someone@example.com", "mailto:someone@example.com"},
+	} {
+		if got := Canonicalize(tc.raw); got != tc.want {
+			t.Errorf("Canonicalize(%q) = %q, want %q", tc.raw, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+// Package version reports what build of the server is running. Release
+// builds set the variables with -ldflags; otherwise they fall back to the
+// module and VCS information the Go toolchain embeds.
+package version
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// Set at build time, e.g.
+//
+//	go build -ldflags "-X github.com/farhapartex/search-proxy/internal/version.Version=v1.2.0"
+var (
+	Version   = ""
+	Commit    = ""
+	BuildDate = ""
+)
+
+// Info describes a build
+type Info struct {
+	Version   string
+	Commit    string
+	BuildDate string
+}
+
+// Get returns the running build's information. Fields nothing knows about
+// are "unknown", except Version, which is "dev".
+func Get() Info {
+	info := Info{Version: Version, Commit: Commit, BuildDate: BuildDate}
+
+	if build, ok := debug.ReadBuildInfo(); ok {
+		if info.Version == "" && build.Main.Version != "" && build.Main.Version != "(devel)" {
+			info.Version = build.Main.Version
+		}
+		for _, setting := range build.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = setting.Value
+				}
+			case "vcs.time":
+				if info.BuildDate == "" {
+					info.BuildDate = setting.Value
+				}
+			}
+		}
+	}
+
+	if info.Version == "" {
+		info.Version = "dev"
+	}
+	if info.Commit == "" {
+		info.Commit = "unknown"
+	}
+	if info.BuildDate == "" {
+		info.BuildDate = "unknown"
+	}
+	return info
+}
+
+// String formats the build for --version output and logs
+func (i Info) String() string {
+	return fmt.Sprintf("search-proxy %s (commit %s, built %s)", i.Version, i.Commit, i.BuildDate)
+}
@@ -0,0 +1,20 @@
+package version
+
+import "testing"
+
+func TestGetPrefersLinkerValues(t *testing.T) {
+	defer func(v, c, d string) { Version, Commit, BuildDate = v, c, d }(Version, Commit, BuildDate)
+	Version, Commit, BuildDate = "v1.4.0", "abc123", "2024-01-01T00:00:00Z"
+
+	want := Info{Version: "v1.4.0", Commit: "abc123", BuildDate: "2024-01-01T00:00:00Z"}
+	if got := Get(); got != want {
+		t.Errorf("Get() = %+v, want %+v", got, want)
+	}
+}
+
+func TestGetNeverReturnsEmptyFields(t *testing.T) {
+	got := Get()
+	if got.Version == "" || got.Commit == "" || got.BuildDate == "" {
+		t.Errorf("Get() = %+v, want every field set", got)
+	}
+}
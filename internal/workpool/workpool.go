@@ -0,0 +1,181 @@
+// Package workpool bounds how much upstream work runs at once, overall and
+// per platform. Work past the bounds waits in a queue of limited length;
+// work past the queue is shed.
+package workpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrOverloaded is returned for work shed because the queue is full
+var ErrOverloaded = errors.New("too many upstream requests queued")
+
+// ErrQueueTimeout is returned for work whose context ended while it was
+// still waiting for a slot
+var ErrQueueTimeout = errors.New("timed out waiting for an upstream slot")
+
+// Config sets the pool's bounds. A value of 0 for MaxConcurrent or
+// MaxPerKey disables that bound; with MaxQueued 0, work that can't start
+// right away is shed.
+type Config struct {
+	MaxConcurrent int
+	MaxPerKey     int
+	MaxQueued     int
+}
+
+// Pool hands out slots to run work in. It is safe for concurrent use.
+type Pool struct {
+	global    chan struct{}
+	maxPerKey int
+	maxQueued int
+
+	mu     sync.Mutex
+	keys   map[string]chan struct{}
+	queued int
+}
+
+// New returns a pool with the given bounds
+func New(cfg Config) *Pool {
+	p := &Pool{
+		maxPerKey: cfg.MaxPerKey,
+		maxQueued: cfg.MaxQueued,
+		keys:      make(map[string]chan struct{}),
+	}
+	if cfg.MaxConcurrent > 0 {
+		p.global = make(chan struct{}, cfg.MaxConcurrent)
+	}
+	return p
+}
+
+// Slot is a reservation for one unit of work under key. Call Wait before
+// starting the work and Release once it is done.
+type Slot struct {
+	pool *Pool
+	key  chan struct{}
+	// held is set once the slot's bounds have been acquired
+	held   bool
+	queued bool
+}
+
+// Reserve claims a slot for work under key. The slot is ready at once when
+// the bounds allow; otherwise it joins the queue, or Reserve fails with
+// ErrOverloaded when the queue is full.
+func (p *Pool) Reserve(key string) (*Slot, error) {
+	slot := &Slot{pool: p, key: p.keyChan(key)}
+	if slot.tryAcquire() {
+		slot.held = true
+		return slot, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.queued >= p.maxQueued {
+		return nil, ErrOverloaded
+	}
+	p.queued++
+	slot.queued = true
+	return slot, nil
+}
+
+// keyChan returns key's bound, nil when there is none
+func (p *Pool) keyChan(key string) chan struct{} {
+	if p.maxPerKey <= 0 {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ch, ok := p.keys[key]
+	if !ok {
+		ch = make(chan struct{}, p.maxPerKey)
+		p.keys[key] = ch
+	}
+	return ch
+}
+
+// tryAcquire takes both bounds if both are free right now
+func (s *Slot) tryAcquire() bool {
+	if !tryTake(s.key) {
+		return false
+	}
+	if !tryTake(s.pool.global) {
+		give(s.key)
+		return false
+	}
+	return true
+}
+
+// Wait blocks until the slot may run, failing with ErrQueueTimeout if ctx
+// ends first. The per-key bound is taken before the global one, so work
+// queued behind a busy platform doesn't hold up the others.
+func (s *Slot) Wait(ctx context.Context) error {
+	if s.held {
+		return nil
+	}
+	defer s.dequeue()
+
+	if err := take(ctx, s.key); err != nil {
+		return fmt.Errorf("%w: %w", ErrQueueTimeout, err)
+	}
+	if err := take(ctx, s.pool.global); err != nil {
+		give(s.key)
+		return fmt.Errorf("%w: %w", ErrQueueTimeout, err)
+	}
+	s.held = true
+	return nil
+}
+
+// Release frees the slot's bounds, or its place in the queue if it never
+// ran. It is safe to call more than once.
+func (s *Slot) Release() {
+	s.dequeue()
+	if s.held {
+		give(s.pool.global)
+		give(s.key)
+		s.held = false
+	}
+}
+
+func (s *Slot) dequeue() {
+	if !s.queued {
+		return
+	}
+	s.queued = false
+	s.pool.mu.Lock()
+	s.pool.queued--
+	s.pool.mu.Unlock()
+}
+
+// A nil bound channel means no bound, so taking and giving are no-ops
+
+func tryTake(ch chan struct{}) bool {
+	if ch == nil {
+		return true
+	}
+	select {
+	case ch <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func take(ctx context.Context, ch chan struct{}) error {
+	if ch == nil {
+		return nil
+	}
+	select {
+	case ch <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func give(ch chan struct{}) {
+	if ch != nil {
+		<-ch
+	}
+}
@@ -0,0 +1,88 @@
+package workpool
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestReserveQueuesThenSheds(t *testing.T) {
+	pool := New(Config{MaxConcurrent: 1, MaxQueued: 1})
+
+	running, err := pool.Reserve("github")
+	if err != nil {
+		t.Fatalf("first Reserve: %v", err)
+	}
+	queued, err := pool.Reserve("reddit")
+	if err != nil {
+		t.Fatalf("second Reserve: %v", err)
+	}
+	if _, err := pool.Reserve("hn"); !errors.Is(err, ErrOverloaded) {
+		t.Fatalf("third Reserve = %v, want ErrOverloaded", err)
+	}
+
+	waited := make(chan error, 1)
+	go func() { waited <- queued.Wait(context.Background()) }()
+	select {
+	case err := <-waited:
+		t.Fatalf("queued Wait returned %v while the pool was full", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	running.Release()
+	if err := <-waited; err != nil {
+		t.Fatalf("queued Wait: %v", err)
+	}
+	// The queued slot has left the queue, so there is room again
+	next, err := pool.Reserve("hn")
+	if err != nil {
+		t.Fatalf("Reserve after dequeue: %v", err)
+	}
+	queued.Release()
+	next.Release()
+}
+
+func TestPerKeyBoundLeavesOtherKeysFree(t *testing.T) {
+	pool := New(Config{MaxPerKey: 1})
+
+	held, err := pool.Reserve("github")
+	if err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+	defer held.Release()
+	if _, err := pool.Reserve("github"); !errors.Is(err, ErrOverloaded) {
+		t.Errorf("second github Reserve = %v, want ErrOverloaded", err)
+	}
+	other, err := pool.Reserve("reddit")
+	if err != nil {
+		t.Fatalf("reddit Reserve: %v", err)
+	}
+	if err := other.Wait(context.Background()); err != nil {
+		t.Errorf("reddit Wait: %v", err)
+	}
+	other.Release()
+}
+
+func TestWaitGivesUpWhenContextEnds(t *testing.T) {
+	pool := New(Config{MaxConcurrent: 1, MaxQueued: 1})
+	held, _ := pool.Reserve("github")
+	defer held.Release()
+
+	queued, err := pool.Reserve("github")
+	if err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err = queued.Wait(ctx)
+	if !errors.Is(err, ErrQueueTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Wait = %v, want ErrQueueTimeout wrapping the deadline", err)
+	}
+	queued.Release()
+
+	// Neither the failed wait nor its release gave away the held slot
+	if _, err := pool.Reserve("reddit"); err != nil {
+		t.Errorf("Reserve after timeout = %v, want a queued slot", err)
+	}
+}
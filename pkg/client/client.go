@@ -0,0 +1,252 @@
+// Package client is a Go client for the search proxy. It wraps the
+// generated gRPC stubs so callers search with a query and options, get
+// typed results back, and don't have to handle transient failures
+// themselves:
+//
+//	c, err := client.New("localhost:50051")
+//	if err != nil {
+//		return err
+//	}
+//	defer c.Close()
+//
+//	resp, err := c.Search(ctx, "golang generics",
+//		client.WithPlatforms("github", "stackoverflow"),
+//		client.WithMaxResults(5))
+//
+// Calls the server refuses as UNAVAILABLE, such as during its shutdown or
+// while a connection is being re-established, are retried with backoff.
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"time"
+
+	pb "github.com/farhapartex/search-proxy/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// Client calls a search proxy. It is safe for concurrent use.
+type Client struct {
+	conn   *grpc.ClientConn
+	search pb.SearchServiceClient
+	retry  RetryPolicy
+}
+
+// RetryPolicy is how calls refused as UNAVAILABLE are retried: up to
+// MaxAttempts in total, waiting BaseDelay doubled after each attempt, plus
+// up to as much again in jitter
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+}
+
+// DefaultRetryPolicy tries each call three times, 100ms then 200ms apart
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 3, BaseDelay: 100 * time.Millisecond}
+
+// Option configures a Client
+type Option func(*settings)
+
+type settings struct {
+	dialOptions []grpc.DialOption
+	retry       RetryPolicy
+	conn        grpc.ClientConnInterface
+}
+
+// WithDialOptions adds gRPC dial options, such as transport credentials to
+// replace the default plaintext connection
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return func(s *settings) { s.dialOptions = append(s.dialOptions, opts...) }
+}
+
+// WithRetryPolicy replaces DefaultRetryPolicy. MaxAttempts below 2
+// disables retries.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(s *settings) { s.retry = policy }
+}
+
+// WithConn makes the client use an existing connection instead of dialing
+// target. Close leaves the connection open.
+func WithConn(conn grpc.ClientConnInterface) Option {
+	return func(s *settings) { s.conn = conn }
+}
+
+// New creates a client for the server at target, e.g. "localhost:50051" or
+// "unix:///run/search-proxy.sock". The connection is plaintext unless
+// WithDialOptions supplies credentials.
+func New(target string, opts ...Option) (*Client, error) {
+	s := settings{retry: DefaultRetryPolicy}
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	c := &Client{retry: s.retry}
+	if s.conn != nil {
+		c.search = pb.NewSearchServiceClient(s.conn)
+		return c, nil
+	}
+
+	dialOptions := append([]grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}, s.dialOptions...)
+	conn, err := grpc.NewClient(target, dialOptions...)
+	if err != nil {
+		return nil, err
+	}
+	c.conn = conn
+	c.search = pb.NewSearchServiceClient(conn)
+	return c, nil
+}
+
+// Close closes the connection New dialed
+func (c *Client) Close() error {
+	if c.conn == nil {
+		return nil
+	}
+	return c.conn.Close()
+}
+
+// Search runs a federated search
+func (c *Client) Search(ctx context.Context, query string, opts ...SearchOption) (*Response, error) {
+	req := newSearchRequest(query, opts)
+
+	var resp *pb.SearchResponse
+	err := c.withRetries(ctx, func() error {
+		var err error
+		resp, err = c.search.FederatedSearch(ctx, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newResponse(resp), nil
+}
+
+// NextPage fetches the results that follow resp, or returns nil when there
+// are none. opts must be the options resp was searched with.
+func (c *Client) NextPage(ctx context.Context, query string, resp *Response, opts ...SearchOption) (*Response, error) {
+	if resp.NextPageToken == "" {
+		return nil, nil
+	}
+	return c.Search(ctx, query, append(opts, WithPageToken(resp.NextPageToken))...)
+}
+
+// PlatformResults are one platform's results of a streamed search
+type PlatformResults struct {
+	Platform string
+	Results  []Result
+	// Took is how long the platform took; 0 when its results came from the
+	// server's local index
+	Took time.Duration
+}
+
+// SearchStream runs a streamed search, calling onPlatform with each
+// platform's results as soon as they are ready, then returns the complete
+// response. An error from onPlatform ends the search and is returned. A
+// refused stream is retried only if onPlatform hasn't been called yet.
+func (c *Client) SearchStream(ctx context.Context, query string, onPlatform func(PlatformResults) error, opts ...SearchOption) (*Response, error) {
+	req := newSearchRequest(query, opts)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var final *pb.SearchResponse
+	received := false
+	err := c.withRetries(ctx, func() error {
+		stream, err := c.search.SearchStream(ctx, req)
+		if err != nil {
+			return err
+		}
+		for {
+			msg, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				if received {
+					return permanent{err}
+				}
+				return err
+			}
+			if msg.Response != nil {
+				final = msg.Response
+				continue
+			}
+			received = true
+			if err := onPlatform(PlatformResults{
+				Platform: msg.Platform,
+				Results:  newResults(msg.Results),
+				Took:     time.Duration(msg.DurationMs) * time.Millisecond,
+			}); err != nil {
+				return permanent{err}
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if final == nil {
+		return nil, status.Error(codes.Internal, "search stream ended without a response")
+	}
+	return newResponse(final), nil
+}
+
+// Platforms describes the platforms the server can search
+func (c *Client) Platforms(ctx context.Context) ([]*pb.PlatformInfo, error) {
+	var resp *pb.ListPlatformsResponse
+	err := c.withRetries(ctx, func() error {
+		var err error
+		resp, err = c.search.ListPlatforms(ctx, &pb.ListPlatformsRequest{})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Platforms, nil
+}
+
+// Health returns the server's health status: "healthy", "degraded",
+// "unhealthy" or "draining"
+func (c *Client) Health(ctx context.Context) (string, error) {
+	// A draining server is worth reporting, not retrying
+	resp, err := c.search.HealthCheck(ctx, &pb.HealthCheckRequest{Service: "client"})
+	if err != nil {
+		return "", err
+	}
+	return resp.Status, nil
+}
+
+// permanent marks an error that must not be retried
+type permanent struct{ err error }
+
+func (p permanent) Error() string { return p.err.Error() }
+
+// withRetries calls fn until it succeeds, fails with anything but
+// UNAVAILABLE, runs out of attempts, or ctx ends
+func (c *Client) withRetries(ctx context.Context, fn func() error) error {
+	delay := c.retry.BaseDelay
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		var p permanent
+		if errors.As(err, &p) {
+			return p.err
+		}
+		if err == nil || status.Code(err) != codes.Unavailable || attempt >= c.retry.MaxAttempts {
+			return err
+		}
+
+		wait := delay
+		if delay > 0 {
+			wait += rand.N(delay)
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return err
+		}
+		delay *= 2
+	}
+}
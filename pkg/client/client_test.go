@@ -0,0 +1,146 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	pb "github.com/farhapartex/search-proxy/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// fakeServer refuses the first refusals calls as UNAVAILABLE, then answers
+type fakeServer struct {
+	pb.UnimplementedSearchServiceServer
+	refusals int
+	calls    int
+	searched *pb.SearchRequest
+}
+
+func (s *fakeServer) refuse() error {
+	s.calls++
+	if s.calls <= s.refusals {
+		return status.Error(codes.Unavailable, "server is shutting down")
+	}
+	return nil
+}
+
+func (s *fakeServer) FederatedSearch(_ context.Context, req *pb.SearchRequest) (*pb.SearchResponse, error) {
+	if err := s.refuse(); err != nil {
+		return nil, err
+	}
+	s.searched = req
+	return &pb.SearchResponse{
+		Results: []*pb.Result{{
+			Platform:        "github",
+			Title:           "golang/go",
+			Url:             "https://github.com/golang/go",
+			CreatedAt:       1700000000,
+			Author:          &pb.Author{Handle: "golang"},
+			PlatformDetails: &pb.Result_Github{Github: &pb.GitHubMeta{Stars: 120000}},
+		}},
+		PlatformsSuccess: []string{"github"},
+		NextPageToken:    "next",
+		Metadata:         &pb.ResponseMetadata{ResponseTimeMs: 42},
+	}, nil
+}
+
+func (s *fakeServer) SearchStream(req *pb.SearchRequest, stream pb.SearchService_SearchStreamServer) error {
+	if err := s.refuse(); err != nil {
+		return err
+	}
+	for _, platform := range []string{"github", "reddit"} {
+		msg := &pb.SearchStreamResponse{Platform: platform, Results: []*pb.Result{{Platform: platform}}, DurationMs: 10}
+		if err := stream.Send(msg); err != nil {
+			return err
+		}
+	}
+	return stream.Send(&pb.SearchStreamResponse{Response: &pb.SearchResponse{TotalCount: 2}})
+}
+
+func newTestClient(t *testing.T, srv *fakeServer) *Client {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	pb.RegisterSearchServiceServer(server, srv)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	c, err := New("", WithConn(conn), WithRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestSearchRetriesUnavailableAndReturnsTypedResults(t *testing.T) {
+	srv := &fakeServer{refusals: 2}
+	c := newTestClient(t, srv)
+
+	resp, err := c.Search(context.Background(), "golang", WithPlatforms("github"), WithMaxResults(5), WithTimeout(time.Second))
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if srv.calls != 3 {
+		t.Errorf("calls = %d, want two refusals and a success", srv.calls)
+	}
+	if srv.searched.MaxResults != 5 || srv.searched.TimeoutMs != 1000 || srv.searched.Platforms[0] != "github" {
+		t.Errorf("searched %v, want the options applied", srv.searched)
+	}
+
+	r := resp.Results[0]
+	if r.Title != "golang/go" || !r.CreatedAt.Equal(time.Unix(1700000000, 0)) || r.Author.Handle != "golang" || r.GitHub.GetStars() != 120000 {
+		t.Errorf("results[0] = %+v, want the converted result", r)
+	}
+	if resp.Took != 42*time.Millisecond || resp.NextPageToken != "next" || resp.Succeeded[0] != "github" {
+		t.Errorf("response = %+v, want its timing, token and platforms", resp)
+	}
+}
+
+func TestSearchGivesUpAfterMaxAttempts(t *testing.T) {
+	srv := &fakeServer{refusals: 5}
+	c := newTestClient(t, srv)
+
+	if _, err := c.Search(context.Background(), "golang"); status.Code(err) != codes.Unavailable {
+		t.Errorf("Search() error = %v, want UNAVAILABLE", err)
+	}
+	if srv.calls != 3 {
+		t.Errorf("calls = %d, want 3", srv.calls)
+	}
+}
+
+func TestSearchStreamDeliversEachPlatform(t *testing.T) {
+	srv := &fakeServer{refusals: 1}
+	c := newTestClient(t, srv)
+
+	var platforms []string
+	resp, err := c.SearchStream(context.Background(), "golang", func(p PlatformResults) error {
+		platforms = append(platforms, p.Platform)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("SearchStream() error = %v", err)
+	}
+	if len(platforms) != 2 || platforms[0] != "github" || resp.Raw.TotalCount != 2 {
+		t.Errorf("platforms = %v, total = %d; want github then reddit, then the response", platforms, resp.Raw.TotalCount)
+	}
+
+	stop := errors.New("stop")
+	if _, err := c.SearchStream(context.Background(), "golang", func(PlatformResults) error { return stop }); !errors.Is(err, stop) {
+		t.Errorf("SearchStream() error = %v, want the callback's", err)
+	}
+}
@@ -0,0 +1,159 @@
+package client
+
+import (
+	"time"
+
+	pb "github.com/farhapartex/search-proxy/proto"
+)
+
+// SearchOption sets a field of the search request
+type SearchOption func(*pb.SearchRequest)
+
+// WithPlatforms limits the search to these platforms; the server searches
+// all of them by default
+func WithPlatforms(platforms ...string) SearchOption {
+	return func(req *pb.SearchRequest) { req.Platforms = platforms }
+}
+
+// WithMaxResults sets how many results each platform contributes
+func WithMaxResults(n int) SearchOption {
+	return func(req *pb.SearchRequest) { req.MaxResults = int32(n) }
+}
+
+// WithPageToken continues an earlier search; see Client.NextPage
+func WithPageToken(token string) SearchOption {
+	return func(req *pb.SearchRequest) { req.PageToken = token }
+}
+
+// WithTimeout sets the server's time budget for the whole search, within
+// the server's own maximum
+func WithTimeout(d time.Duration) SearchOption {
+	return func(req *pb.SearchRequest) { req.TimeoutMs = int32(d.Milliseconds()) }
+}
+
+// WithLocale searches localized sites and orders results for a BCP 47
+// locale such as "pt-BR"
+func WithLocale(locale string) SearchOption {
+	return func(req *pb.SearchRequest) { req.Locale = locale }
+}
+
+// WithContentType picks what GitHub is searched for
+func WithContentType(contentType pb.ContentType) SearchOption {
+	return func(req *pb.SearchRequest) { req.ContentType = contentType }
+}
+
+// WithSort sets how merged results are ordered
+func WithSort(sort pb.SortOrder) SearchOption {
+	return func(req *pb.SearchRequest) { req.Sort = sort }
+}
+
+// WithFilter keeps only results matching a CEL filter expression, such as
+// `int(metadata.stars) > 100 && metadata.language == "Go"`
+func WithFilter(expr string) SearchOption {
+	return func(req *pb.SearchRequest) { req.Filter = expr }
+}
+
+// WithRequest edits the request directly, for fields without an option of
+// their own
+func WithRequest(edit func(*pb.SearchRequest)) SearchOption {
+	return SearchOption(edit)
+}
+
+func newSearchRequest(query string, opts []SearchOption) *pb.SearchRequest {
+	req := &pb.SearchRequest{Query: query}
+	for _, opt := range opts {
+		opt(req)
+	}
+	return req
+}
+
+// Response is the outcome of a search
+type Response struct {
+	Results []Result
+	// Succeeded, TimedOut and Failed list the platforms by outcome
+	Succeeded []string
+	TimedOut  []string
+	Failed    []string
+	// NextPageToken is set when more results follow; see Client.NextPage
+	NextPageToken string
+	// Took is the server's own response time
+	Took time.Duration
+	// Raw is the response as the server sent it, with every detail
+	Raw *pb.SearchResponse
+}
+
+// Result is one search result
+type Result struct {
+	Platform string
+	Title    string
+	Snippet  string
+	URL      string
+	Type     pb.ResultType
+	// CreatedAt and UpdatedAt are zero when the platform doesn't say
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	ThumbnailURL string
+	// Author is nil when the platform doesn't say
+	Author *Author
+	// Summary is set when the search asked for summaries
+	Summary  string
+	Metadata map[string]string
+	// GitHub, StackOverflow, Reddit and DockerHub are the typed metadata
+	// of the platform the result came from; at most one is set
+	GitHub        *pb.GitHubMeta
+	StackOverflow *pb.StackOverflowMeta
+	Reddit        *pb.RedditMeta
+	DockerHub     *pb.DockerHubMeta
+}
+
+// Author identifies who created a result
+type Author struct {
+	Name       string
+	Handle     string
+	ProfileURL string
+}
+
+func newResponse(resp *pb.SearchResponse) *Response {
+	return &Response{
+		Results:       newResults(resp.Results),
+		Succeeded:     resp.PlatformsSuccess,
+		TimedOut:      resp.PlatformsTimeout,
+		Failed:        resp.PlatformsError,
+		NextPageToken: resp.NextPageToken,
+		Took:          time.Duration(resp.GetMetadata().GetResponseTimeMs()) * time.Millisecond,
+		Raw:           resp,
+	}
+}
+
+func newResults(results []*pb.Result) []Result {
+	out := make([]Result, len(results))
+	for i, r := range results {
+		out[i] = Result{
+			Platform:      r.Platform,
+			Title:         r.Title,
+			Snippet:       r.Snippet,
+			URL:           r.Url,
+			Type:          r.ResultType,
+			CreatedAt:     unixTime(r.CreatedAt),
+			UpdatedAt:     unixTime(r.UpdatedAt),
+			ThumbnailURL:  r.ThumbnailUrl,
+			Summary:       r.Summary,
+			Metadata:      r.Metadata,
+			GitHub:        r.GetGithub(),
+			StackOverflow: r.GetStackoverflow(),
+			Reddit:        r.GetReddit(),
+			DockerHub:     r.GetDockerhub(),
+		}
+		if a := r.Author; a != nil {
+			out[i].Author = &Author{Name: a.Name, Handle: a.Handle, ProfileURL: a.ProfileUrl}
+		}
+	}
+	return out
+}
+
+func unixTime(seconds int64) time.Time {
+	if seconds == 0 {
+		return time.Time{}
+	}
+	return time.Unix(seconds, 0).UTC()
+}
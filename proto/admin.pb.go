@@ -0,0 +1,1139 @@
+// Protocol Buffer definition for the search proxy's admin API
+// Version: 1.0
+// Runtime operations, kept apart from SearchService so clients of the
+// search API never see them
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v3.12.4
+// source: proto/admin.proto
+
+package proto
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// UpdateCredentialsRequest replaces every credential of one platform
+type UpdateCredentialsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// "github" (tokens), "stackoverflow" (API keys) or "reddit" (one
+	// "client_id:client_secret" pair)
+	Platform string `protobuf:"bytes,1,opt,name=platform,proto3" json:"platform,omitempty"`
+	// New credentials, tried in this order; empty leaves only anonymous
+	// access, where the platform allows it
+	Credentials []string `protobuf:"bytes,2,rep,name=credentials,proto3" json:"credentials,omitempty"`
+	// Swap without checking the credentials with the upstream first
+	SkipValidation bool `protobuf:"varint,3,opt,name=skip_validation,json=skipValidation,proto3" json:"skip_validation,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *UpdateCredentialsRequest) Reset() {
+	*x = UpdateCredentialsRequest{}
+	mi := &file_proto_admin_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateCredentialsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateCredentialsRequest) ProtoMessage() {}
+
+func (x *UpdateCredentialsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_admin_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateCredentialsRequest.ProtoReflect.Descriptor instead.
+func (*UpdateCredentialsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_admin_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *UpdateCredentialsRequest) GetPlatform() string {
+	if x != nil {
+		return x.Platform
+	}
+	return ""
+}
+
+func (x *UpdateCredentialsRequest) GetCredentials() []string {
+	if x != nil {
+		return x.Credentials
+	}
+	return nil
+}
+
+func (x *UpdateCredentialsRequest) GetSkipValidation() bool {
+	if x != nil {
+		return x.SkipValidation
+	}
+	return false
+}
+
+// SetPlatformEnabledRequest enables or disables one platform
+type SetPlatformEnabledRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Platform name or alias (required)
+	Platform string `protobuf:"bytes,1,opt,name=platform,proto3" json:"platform,omitempty"`
+	// false leaves the platform out of searches that don't name it, and
+	// reports it as skipped with error_code "disabled" in those that do
+	Enabled       bool `protobuf:"varint,2,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetPlatformEnabledRequest) Reset() {
+	*x = SetPlatformEnabledRequest{}
+	mi := &file_proto_admin_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetPlatformEnabledRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetPlatformEnabledRequest) ProtoMessage() {}
+
+func (x *SetPlatformEnabledRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_admin_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetPlatformEnabledRequest.ProtoReflect.Descriptor instead.
+func (*SetPlatformEnabledRequest) Descriptor() ([]byte, []int) {
+	return file_proto_admin_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SetPlatformEnabledRequest) GetPlatform() string {
+	if x != nil {
+		return x.Platform
+	}
+	return ""
+}
+
+func (x *SetPlatformEnabledRequest) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+// ResetCircuitBreakersRequest picks the breakers to close
+type ResetCircuitBreakersRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Platform names or aliases; empty resets every platform's breaker
+	Platforms     []string `protobuf:"bytes,1,rep,name=platforms,proto3" json:"platforms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResetCircuitBreakersRequest) Reset() {
+	*x = ResetCircuitBreakersRequest{}
+	mi := &file_proto_admin_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResetCircuitBreakersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResetCircuitBreakersRequest) ProtoMessage() {}
+
+func (x *ResetCircuitBreakersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_admin_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResetCircuitBreakersRequest.ProtoReflect.Descriptor instead.
+func (*ResetCircuitBreakersRequest) Descriptor() ([]byte, []int) {
+	return file_proto_admin_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ResetCircuitBreakersRequest) GetPlatforms() []string {
+	if x != nil {
+		return x.Platforms
+	}
+	return nil
+}
+
+// FlushCacheRequest takes no parameters
+type FlushCacheRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FlushCacheRequest) Reset() {
+	*x = FlushCacheRequest{}
+	mi := &file_proto_admin_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FlushCacheRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FlushCacheRequest) ProtoMessage() {}
+
+func (x *FlushCacheRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_admin_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FlushCacheRequest.ProtoReflect.Descriptor instead.
+func (*FlushCacheRequest) Descriptor() ([]byte, []int) {
+	return file_proto_admin_proto_rawDescGZIP(), []int{3}
+}
+
+// SetLogLevelRequest sets the server's log level
+type SetLogLevelRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// "debug", "info", "warn" or "error" (required)
+	Level         string `protobuf:"bytes,1,opt,name=level,proto3" json:"level,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetLogLevelRequest) Reset() {
+	*x = SetLogLevelRequest{}
+	mi := &file_proto_admin_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetLogLevelRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetLogLevelRequest) ProtoMessage() {}
+
+func (x *SetLogLevelRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_admin_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetLogLevelRequest.ProtoReflect.Descriptor instead.
+func (*SetLogLevelRequest) Descriptor() ([]byte, []int) {
+	return file_proto_admin_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SetLogLevelRequest) GetLevel() string {
+	if x != nil {
+		return x.Level
+	}
+	return ""
+}
+
+// GetConfigRequest takes no parameters
+type GetConfigRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetConfigRequest) Reset() {
+	*x = GetConfigRequest{}
+	mi := &file_proto_admin_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetConfigRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetConfigRequest) ProtoMessage() {}
+
+func (x *GetConfigRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_admin_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetConfigRequest.ProtoReflect.Descriptor instead.
+func (*GetConfigRequest) Descriptor() ([]byte, []int) {
+	return file_proto_admin_proto_rawDescGZIP(), []int{5}
+}
+
+// ListRecentSearchesRequest bounds the history returned
+type ListRecentSearchesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Most searches to return (optional)
+	// Default: 20, Range: 0-100
+	Limit         int32 `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRecentSearchesRequest) Reset() {
+	*x = ListRecentSearchesRequest{}
+	mi := &file_proto_admin_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRecentSearchesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRecentSearchesRequest) ProtoMessage() {}
+
+func (x *ListRecentSearchesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_admin_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRecentSearchesRequest.ProtoReflect.Descriptor instead.
+func (*ListRecentSearchesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_admin_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListRecentSearchesRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+// ListTrendingQueriesRequest picks the window to count searches in
+type ListTrendingQueriesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// How far back to count, in seconds (optional)
+	// Default: 86400 (one day), Range: 0-2592000 (30 days)
+	WindowSeconds int64 `protobuf:"varint,1,opt,name=window_seconds,json=windowSeconds,proto3" json:"window_seconds,omitempty"`
+	// Most queries to return (optional)
+	// Default: 10, Range: 0-100
+	Limit         int32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTrendingQueriesRequest) Reset() {
+	*x = ListTrendingQueriesRequest{}
+	mi := &file_proto_admin_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTrendingQueriesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTrendingQueriesRequest) ProtoMessage() {}
+
+func (x *ListTrendingQueriesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_admin_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTrendingQueriesRequest.ProtoReflect.Descriptor instead.
+func (*ListTrendingQueriesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_admin_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListTrendingQueriesRequest) GetWindowSeconds() int64 {
+	if x != nil {
+		return x.WindowSeconds
+	}
+	return 0
+}
+
+func (x *ListTrendingQueriesRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+// UpdateCredentialsResponse confirms a rotation
+type UpdateCredentialsResponse struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Platform string                 `protobuf:"bytes,1,opt,name=platform,proto3" json:"platform,omitempty"`
+	// Number of credentials now configured
+	Credentials   int32 `protobuf:"varint,2,opt,name=credentials,proto3" json:"credentials,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateCredentialsResponse) Reset() {
+	*x = UpdateCredentialsResponse{}
+	mi := &file_proto_admin_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateCredentialsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateCredentialsResponse) ProtoMessage() {}
+
+func (x *UpdateCredentialsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_admin_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateCredentialsResponse.ProtoReflect.Descriptor instead.
+func (*UpdateCredentialsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_admin_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *UpdateCredentialsResponse) GetPlatform() string {
+	if x != nil {
+		return x.Platform
+	}
+	return ""
+}
+
+func (x *UpdateCredentialsResponse) GetCredentials() int32 {
+	if x != nil {
+		return x.Credentials
+	}
+	return 0
+}
+
+// SetPlatformEnabledResponse confirms the platform's new state
+type SetPlatformEnabledResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Canonical platform name
+	Platform      string `protobuf:"bytes,1,opt,name=platform,proto3" json:"platform,omitempty"`
+	Enabled       bool   `protobuf:"varint,2,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetPlatformEnabledResponse) Reset() {
+	*x = SetPlatformEnabledResponse{}
+	mi := &file_proto_admin_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetPlatformEnabledResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetPlatformEnabledResponse) ProtoMessage() {}
+
+func (x *SetPlatformEnabledResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_admin_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetPlatformEnabledResponse.ProtoReflect.Descriptor instead.
+func (*SetPlatformEnabledResponse) Descriptor() ([]byte, []int) {
+	return file_proto_admin_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *SetPlatformEnabledResponse) GetPlatform() string {
+	if x != nil {
+		return x.Platform
+	}
+	return ""
+}
+
+func (x *SetPlatformEnabledResponse) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+// ResetCircuitBreakersResponse lists the breakers that were reset
+type ResetCircuitBreakersResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Canonical platform names, in name order
+	Platforms     []string `protobuf:"bytes,1,rep,name=platforms,proto3" json:"platforms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResetCircuitBreakersResponse) Reset() {
+	*x = ResetCircuitBreakersResponse{}
+	mi := &file_proto_admin_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResetCircuitBreakersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResetCircuitBreakersResponse) ProtoMessage() {}
+
+func (x *ResetCircuitBreakersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_admin_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResetCircuitBreakersResponse.ProtoReflect.Descriptor instead.
+func (*ResetCircuitBreakersResponse) Descriptor() ([]byte, []int) {
+	return file_proto_admin_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ResetCircuitBreakersResponse) GetPlatforms() []string {
+	if x != nil {
+		return x.Platforms
+	}
+	return nil
+}
+
+// FlushCacheResponse counts what was dropped
+type FlushCacheResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Responses removed from the response cache; with the Redis backend,
+	// the shared entries of every replica
+	Responses int32 `protobuf:"varint,1,opt,name=responses,proto3" json:"responses,omitempty"`
+	// Entries removed from the semantic cache
+	SemanticEntries int32 `protobuf:"varint,2,opt,name=semantic_entries,json=semanticEntries,proto3" json:"semantic_entries,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *FlushCacheResponse) Reset() {
+	*x = FlushCacheResponse{}
+	mi := &file_proto_admin_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FlushCacheResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FlushCacheResponse) ProtoMessage() {}
+
+func (x *FlushCacheResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_admin_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FlushCacheResponse.ProtoReflect.Descriptor instead.
+func (*FlushCacheResponse) Descriptor() ([]byte, []int) {
+	return file_proto_admin_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *FlushCacheResponse) GetResponses() int32 {
+	if x != nil {
+		return x.Responses
+	}
+	return 0
+}
+
+func (x *FlushCacheResponse) GetSemanticEntries() int32 {
+	if x != nil {
+		return x.SemanticEntries
+	}
+	return 0
+}
+
+// SetLogLevelResponse confirms the change
+type SetLogLevelResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Level         string                 `protobuf:"bytes,1,opt,name=level,proto3" json:"level,omitempty"`
+	PreviousLevel string                 `protobuf:"bytes,2,opt,name=previous_level,json=previousLevel,proto3" json:"previous_level,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetLogLevelResponse) Reset() {
+	*x = SetLogLevelResponse{}
+	mi := &file_proto_admin_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetLogLevelResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetLogLevelResponse) ProtoMessage() {}
+
+func (x *SetLogLevelResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_admin_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetLogLevelResponse.ProtoReflect.Descriptor instead.
+func (*SetLogLevelResponse) Descriptor() ([]byte, []int) {
+	return file_proto_admin_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *SetLogLevelResponse) GetLevel() string {
+	if x != nil {
+		return x.Level
+	}
+	return ""
+}
+
+func (x *SetLogLevelResponse) GetPreviousLevel() string {
+	if x != nil {
+		return x.PreviousLevel
+	}
+	return ""
+}
+
+// GetConfigResponse holds the effective configuration
+type GetConfigResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The configuration as JSON, one object per section, e.g.
+	// {"Server": {"GRPCPort": "50051", ...}, ...}. Tokens, keys, passwords and
+	// the passwords in URLs are replaced by "REDACTED"; unset ones stay empty.
+	ConfigJson    string `protobuf:"bytes,1,opt,name=config_json,json=configJson,proto3" json:"config_json,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetConfigResponse) Reset() {
+	*x = GetConfigResponse{}
+	mi := &file_proto_admin_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetConfigResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetConfigResponse) ProtoMessage() {}
+
+func (x *GetConfigResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_admin_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetConfigResponse.ProtoReflect.Descriptor instead.
+func (*GetConfigResponse) Descriptor() ([]byte, []int) {
+	return file_proto_admin_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetConfigResponse) GetConfigJson() string {
+	if x != nil {
+		return x.ConfigJson
+	}
+	return ""
+}
+
+// RecordedSearch is one search from the search store
+type RecordedSearch struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Query string                 `protobuf:"bytes,2,opt,name=query,proto3" json:"query,omitempty"`
+	// Platforms the search asked for; empty when it named none
+	Platforms        []string `protobuf:"bytes,3,rep,name=platforms,proto3" json:"platforms,omitempty"`
+	ResultCount      int32    `protobuf:"varint,4,opt,name=result_count,json=resultCount,proto3" json:"result_count,omitempty"`
+	ResponseTimeMs   int32    `protobuf:"varint,5,opt,name=response_time_ms,json=responseTimeMs,proto3" json:"response_time_ms,omitempty"`
+	PlatformsSuccess []string `protobuf:"bytes,6,rep,name=platforms_success,json=platformsSuccess,proto3" json:"platforms_success,omitempty"`
+	PlatformsTimeout []string `protobuf:"bytes,7,rep,name=platforms_timeout,json=platformsTimeout,proto3" json:"platforms_timeout,omitempty"`
+	PlatformsError   []string `protobuf:"bytes,8,rep,name=platforms_error,json=platformsError,proto3" json:"platforms_error,omitempty"`
+	// When the search ran (Unix seconds, UTC)
+	CreatedAt     int64 `protobuf:"varint,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RecordedSearch) Reset() {
+	*x = RecordedSearch{}
+	mi := &file_proto_admin_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RecordedSearch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecordedSearch) ProtoMessage() {}
+
+func (x *RecordedSearch) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_admin_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecordedSearch.ProtoReflect.Descriptor instead.
+func (*RecordedSearch) Descriptor() ([]byte, []int) {
+	return file_proto_admin_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *RecordedSearch) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *RecordedSearch) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *RecordedSearch) GetPlatforms() []string {
+	if x != nil {
+		return x.Platforms
+	}
+	return nil
+}
+
+func (x *RecordedSearch) GetResultCount() int32 {
+	if x != nil {
+		return x.ResultCount
+	}
+	return 0
+}
+
+func (x *RecordedSearch) GetResponseTimeMs() int32 {
+	if x != nil {
+		return x.ResponseTimeMs
+	}
+	return 0
+}
+
+func (x *RecordedSearch) GetPlatformsSuccess() []string {
+	if x != nil {
+		return x.PlatformsSuccess
+	}
+	return nil
+}
+
+func (x *RecordedSearch) GetPlatformsTimeout() []string {
+	if x != nil {
+		return x.PlatformsTimeout
+	}
+	return nil
+}
+
+func (x *RecordedSearch) GetPlatformsError() []string {
+	if x != nil {
+		return x.PlatformsError
+	}
+	return nil
+}
+
+func (x *RecordedSearch) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+// ListRecentSearchesResponse holds the latest searches
+type ListRecentSearchesResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Newest first
+	Searches      []*RecordedSearch `protobuf:"bytes,1,rep,name=searches,proto3" json:"searches,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRecentSearchesResponse) Reset() {
+	*x = ListRecentSearchesResponse{}
+	mi := &file_proto_admin_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRecentSearchesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRecentSearchesResponse) ProtoMessage() {}
+
+func (x *ListRecentSearchesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_admin_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRecentSearchesResponse.ProtoReflect.Descriptor instead.
+func (*ListRecentSearchesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_admin_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ListRecentSearchesResponse) GetSearches() []*RecordedSearch {
+	if x != nil {
+		return x.Searches
+	}
+	return nil
+}
+
+// TrendingQuery is a query and how often it was searched
+type TrendingQuery struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The query, lowercased; searches differing only in case count together
+	Query         string `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	Count         int32  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TrendingQuery) Reset() {
+	*x = TrendingQuery{}
+	mi := &file_proto_admin_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TrendingQuery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrendingQuery) ProtoMessage() {}
+
+func (x *TrendingQuery) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_admin_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrendingQuery.ProtoReflect.Descriptor instead.
+func (*TrendingQuery) Descriptor() ([]byte, []int) {
+	return file_proto_admin_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *TrendingQuery) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *TrendingQuery) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+// ListTrendingQueriesResponse holds the most searched queries
+type ListTrendingQueriesResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Most searched first, ties in query order
+	Queries       []*TrendingQuery `protobuf:"bytes,1,rep,name=queries,proto3" json:"queries,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTrendingQueriesResponse) Reset() {
+	*x = ListTrendingQueriesResponse{}
+	mi := &file_proto_admin_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTrendingQueriesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTrendingQueriesResponse) ProtoMessage() {}
+
+func (x *ListTrendingQueriesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_admin_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTrendingQueriesResponse.ProtoReflect.Descriptor instead.
+func (*ListTrendingQueriesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_admin_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ListTrendingQueriesResponse) GetQueries() []*TrendingQuery {
+	if x != nil {
+		return x.Queries
+	}
+	return nil
+}
+
+var File_proto_admin_proto protoreflect.FileDescriptor
+
+const file_proto_admin_proto_rawDesc = "" +
+	"\n" +
+	"\x11proto/admin.proto\x12\x06search\"\x81\x01\n" +
+	"\x18UpdateCredentialsRequest\x12\x1a\n" +
+	"\bplatform\x18\x01 \x01(\tR\bplatform\x12 \n" +
+	"\vcredentials\x18\x02 \x03(\tR\vcredentials\x12'\n" +
+	"\x0fskip_validation\x18\x03 \x01(\bR\x0eskipValidation\"Q\n" +
+	"\x19SetPlatformEnabledRequest\x12\x1a\n" +
+	"\bplatform\x18\x01 \x01(\tR\bplatform\x12\x18\n" +
+	"\aenabled\x18\x02 \x01(\bR\aenabled\";\n" +
+	"\x1bResetCircuitBreakersRequest\x12\x1c\n" +
+	"\tplatforms\x18\x01 \x03(\tR\tplatforms\"\x13\n" +
+	"\x11FlushCacheRequest\"*\n" +
+	"\x12SetLogLevelRequest\x12\x14\n" +
+	"\x05level\x18\x01 \x01(\tR\x05level\"\x12\n" +
+	"\x10GetConfigRequest\"1\n" +
+	"\x19ListRecentSearchesRequest\x12\x14\n" +
+	"\x05limit\x18\x01 \x01(\x05R\x05limit\"Y\n" +
+	"\x1aListTrendingQueriesRequest\x12%\n" +
+	"\x0ewindow_seconds\x18\x01 \x01(\x03R\rwindowSeconds\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\"Y\n" +
+	"\x19UpdateCredentialsResponse\x12\x1a\n" +
+	"\bplatform\x18\x01 \x01(\tR\bplatform\x12 \n" +
+	"\vcredentials\x18\x02 \x01(\x05R\vcredentials\"R\n" +
+	"\x1aSetPlatformEnabledResponse\x12\x1a\n" +
+	"\bplatform\x18\x01 \x01(\tR\bplatform\x12\x18\n" +
+	"\aenabled\x18\x02 \x01(\bR\aenabled\"<\n" +
+	"\x1cResetCircuitBreakersResponse\x12\x1c\n" +
+	"\tplatforms\x18\x01 \x03(\tR\tplatforms\"]\n" +
+	"\x12FlushCacheResponse\x12\x1c\n" +
+	"\tresponses\x18\x01 \x01(\x05R\tresponses\x12)\n" +
+	"\x10semantic_entries\x18\x02 \x01(\x05R\x0fsemanticEntries\"R\n" +
+	"\x13SetLogLevelResponse\x12\x14\n" +
+	"\x05level\x18\x01 \x01(\tR\x05level\x12%\n" +
+	"\x0eprevious_level\x18\x02 \x01(\tR\rpreviousLevel\"4\n" +
+	"\x11GetConfigResponse\x12\x1f\n" +
+	"\vconfig_json\x18\x01 \x01(\tR\n" +
+	"configJson\"\xc3\x02\n" +
+	"\x0eRecordedSearch\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x14\n" +
+	"\x05query\x18\x02 \x01(\tR\x05query\x12\x1c\n" +
+	"\tplatforms\x18\x03 \x03(\tR\tplatforms\x12!\n" +
+	"\fresult_count\x18\x04 \x01(\x05R\vresultCount\x12(\n" +
+	"\x10response_time_ms\x18\x05 \x01(\x05R\x0eresponseTimeMs\x12+\n" +
+	"\x11platforms_success\x18\x06 \x03(\tR\x10platformsSuccess\x12+\n" +
+	"\x11platforms_timeout\x18\a \x03(\tR\x10platformsTimeout\x12'\n" +
+	"\x0fplatforms_error\x18\b \x03(\tR\x0eplatformsError\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\t \x01(\x03R\tcreatedAt\"P\n" +
+	"\x1aListRecentSearchesResponse\x122\n" +
+	"\bsearches\x18\x01 \x03(\v2\x16.search.RecordedSearchR\bsearches\";\n" +
+	"\rTrendingQuery\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x05R\x05count\"N\n" +
+	"\x1bListTrendingQueriesResponse\x12/\n" +
+	"\aqueries\x18\x01 \x03(\v2\x15.search.TrendingQueryR\aqueries2\xb4\x05\n" +
+	"\fAdminService\x12X\n" +
+	"\x11UpdateCredentials\x12 .search.UpdateCredentialsRequest\x1a!.search.UpdateCredentialsResponse\x12[\n" +
+	"\x12SetPlatformEnabled\x12!.search.SetPlatformEnabledRequest\x1a\".search.SetPlatformEnabledResponse\x12a\n" +
+	"\x14ResetCircuitBreakers\x12#.search.ResetCircuitBreakersRequest\x1a$.search.ResetCircuitBreakersResponse\x12C\n" +
+	"\n" +
+	"FlushCache\x12\x19.search.FlushCacheRequest\x1a\x1a.search.FlushCacheResponse\x12F\n" +
+	"\vSetLogLevel\x12\x1a.search.SetLogLevelRequest\x1a\x1b.search.SetLogLevelResponse\x12@\n" +
+	"\tGetConfig\x12\x18.search.GetConfigRequest\x1a\x19.search.GetConfigResponse\x12[\n" +
+	"\x12ListRecentSearches\x12!.search.ListRecentSearchesRequest\x1a\".search.ListRecentSearchesResponse\x12^\n" +
+	"\x13ListTrendingQueries\x12\".search.ListTrendingQueriesRequest\x1a#.search.ListTrendingQueriesResponseB+Z)github.com/farhapartex/search-proxy/protob\x06proto3"
+
+var (
+	file_proto_admin_proto_rawDescOnce sync.Once
+	file_proto_admin_proto_rawDescData []byte
+)
+
+func file_proto_admin_proto_rawDescGZIP() []byte {
+	file_proto_admin_proto_rawDescOnce.Do(func() {
+		file_proto_admin_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_admin_proto_rawDesc), len(file_proto_admin_proto_rawDesc)))
+	})
+	return file_proto_admin_proto_rawDescData
+}
+
+var file_proto_admin_proto_msgTypes = make([]protoimpl.MessageInfo, 18)
+var file_proto_admin_proto_goTypes = []any{
+	(*UpdateCredentialsRequest)(nil),     // 0: search.UpdateCredentialsRequest
+	(*SetPlatformEnabledRequest)(nil),    // 1: search.SetPlatformEnabledRequest
+	(*ResetCircuitBreakersRequest)(nil),  // 2: search.ResetCircuitBreakersRequest
+	(*FlushCacheRequest)(nil),            // 3: search.FlushCacheRequest
+	(*SetLogLevelRequest)(nil),           // 4: search.SetLogLevelRequest
+	(*GetConfigRequest)(nil),             // 5: search.GetConfigRequest
+	(*ListRecentSearchesRequest)(nil),    // 6: search.ListRecentSearchesRequest
+	(*ListTrendingQueriesRequest)(nil),   // 7: search.ListTrendingQueriesRequest
+	(*UpdateCredentialsResponse)(nil),    // 8: search.UpdateCredentialsResponse
+	(*SetPlatformEnabledResponse)(nil),   // 9: search.SetPlatformEnabledResponse
+	(*ResetCircuitBreakersResponse)(nil), // 10: search.ResetCircuitBreakersResponse
+	(*FlushCacheResponse)(nil),           // 11: search.FlushCacheResponse
+	(*SetLogLevelResponse)(nil),          // 12: search.SetLogLevelResponse
+	(*GetConfigResponse)(nil),            // 13: search.GetConfigResponse
+	(*RecordedSearch)(nil),               // 14: search.RecordedSearch
+	(*ListRecentSearchesResponse)(nil),   // 15: search.ListRecentSearchesResponse
+	(*TrendingQuery)(nil),                // 16: search.TrendingQuery
+	(*ListTrendingQueriesResponse)(nil),  // 17: search.ListTrendingQueriesResponse
+}
+var file_proto_admin_proto_depIdxs = []int32{
+	14, // 0: search.ListRecentSearchesResponse.searches:type_name -> search.RecordedSearch
+	16, // 1: search.ListTrendingQueriesResponse.queries:type_name -> search.TrendingQuery
+	0,  // 2: search.AdminService.UpdateCredentials:input_type -> search.UpdateCredentialsRequest
+	1,  // 3: search.AdminService.SetPlatformEnabled:input_type -> search.SetPlatformEnabledRequest
+	2,  // 4: search.AdminService.ResetCircuitBreakers:input_type -> search.ResetCircuitBreakersRequest
+	3,  // 5: search.AdminService.FlushCache:input_type -> search.FlushCacheRequest
+	4,  // 6: search.AdminService.SetLogLevel:input_type -> search.SetLogLevelRequest
+	5,  // 7: search.AdminService.GetConfig:input_type -> search.GetConfigRequest
+	6,  // 8: search.AdminService.ListRecentSearches:input_type -> search.ListRecentSearchesRequest
+	7,  // 9: search.AdminService.ListTrendingQueries:input_type -> search.ListTrendingQueriesRequest
+	8,  // 10: search.AdminService.UpdateCredentials:output_type -> search.UpdateCredentialsResponse
+	9,  // 11: search.AdminService.SetPlatformEnabled:output_type -> search.SetPlatformEnabledResponse
+	10, // 12: search.AdminService.ResetCircuitBreakers:output_type -> search.ResetCircuitBreakersResponse
+	11, // 13: search.AdminService.FlushCache:output_type -> search.FlushCacheResponse
+	12, // 14: search.AdminService.SetLogLevel:output_type -> search.SetLogLevelResponse
+	13, // 15: search.AdminService.GetConfig:output_type -> search.GetConfigResponse
+	15, // 16: search.AdminService.ListRecentSearches:output_type -> search.ListRecentSearchesResponse
+	17, // 17: search.AdminService.ListTrendingQueries:output_type -> search.ListTrendingQueriesResponse
+	10, // [10:18] is the sub-list for method output_type
+	2,  // [2:10] is the sub-list for method input_type
+	2,  // [2:2] is the sub-list for extension type_name
+	2,  // [2:2] is the sub-list for extension extendee
+	0,  // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_proto_admin_proto_init() }
+func file_proto_admin_proto_init() {
+	if File_proto_admin_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_admin_proto_rawDesc), len(file_proto_admin_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   18,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_proto_admin_proto_goTypes,
+		DependencyIndexes: file_proto_admin_proto_depIdxs,
+		MessageInfos:      file_proto_admin_proto_msgTypes,
+	}.Build()
+	File_proto_admin_proto = out.File
+	file_proto_admin_proto_goTypes = nil
+	file_proto_admin_proto_depIdxs = nil
+}
@@ -0,0 +1,430 @@
+// Protocol Buffer definition for the search proxy's admin API
+// Version: 1.0
+// Runtime operations, kept apart from SearchService so clients of the
+// search API never see them
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.0
+// - protoc             v3.12.4
+// source: proto/admin.proto
+
+package proto
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	AdminService_UpdateCredentials_FullMethodName    = "/search.AdminService/UpdateCredentials"
+	AdminService_SetPlatformEnabled_FullMethodName   = "/search.AdminService/SetPlatformEnabled"
+	AdminService_ResetCircuitBreakers_FullMethodName = "/search.AdminService/ResetCircuitBreakers"
+	AdminService_FlushCache_FullMethodName           = "/search.AdminService/FlushCache"
+	AdminService_SetLogLevel_FullMethodName          = "/search.AdminService/SetLogLevel"
+	AdminService_GetConfig_FullMethodName            = "/search.AdminService/GetConfig"
+	AdminService_ListRecentSearches_FullMethodName   = "/search.AdminService/ListRecentSearches"
+	AdminService_ListTrendingQueries_FullMethodName  = "/search.AdminService/ListTrendingQueries"
+)
+
+// AdminServiceClient is the client API for AdminService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// AdminService changes the running server without a restart. Every call
+// needs an "authorization: Bearer <ADMIN_TOKEN>" header, checked by an
+// interceptor in front of the whole service; the service refuses all calls
+// when ADMIN_TOKEN is unset. The token is a permission of its own: it grants
+// nothing on SearchService, and nothing else grants access here.
+type AdminServiceClient interface {
+	// UpdateCredentials replaces a platform's API tokens or keys, checking
+	// each with the upstream first
+	UpdateCredentials(ctx context.Context, in *UpdateCredentialsRequest, opts ...grpc.CallOption) (*UpdateCredentialsResponse, error)
+	// SetPlatformEnabled takes a platform out of searches, or puts it back
+	SetPlatformEnabled(ctx context.Context, in *SetPlatformEnabledRequest, opts ...grpc.CallOption) (*SetPlatformEnabledResponse, error)
+	// ResetCircuitBreakers closes platforms' circuit breakers, so the next
+	// searches call the upstreams again at once
+	ResetCircuitBreakers(ctx context.Context, in *ResetCircuitBreakersRequest, opts ...grpc.CallOption) (*ResetCircuitBreakersResponse, error)
+	// FlushCache drops every cached response, exact and semantic
+	FlushCache(ctx context.Context, in *FlushCacheRequest, opts ...grpc.CallOption) (*FlushCacheResponse, error)
+	// SetLogLevel changes which log lines are written
+	SetLogLevel(ctx context.Context, in *SetLogLevelRequest, opts ...grpc.CallOption) (*SetLogLevelResponse, error)
+	// GetConfig returns the configuration the server is running with, with
+	// secrets redacted
+	GetConfig(ctx context.Context, in *GetConfigRequest, opts ...grpc.CallOption) (*GetConfigResponse, error)
+	// ListRecentSearches returns the latest searches recorded in the search
+	// store (STORE_ENABLED)
+	ListRecentSearches(ctx context.Context, in *ListRecentSearchesRequest, opts ...grpc.CallOption) (*ListRecentSearchesResponse, error)
+	// ListTrendingQueries returns the queries searched most often lately, from
+	// the search store
+	ListTrendingQueries(ctx context.Context, in *ListTrendingQueriesRequest, opts ...grpc.CallOption) (*ListTrendingQueriesResponse, error)
+}
+
+type adminServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAdminServiceClient(cc grpc.ClientConnInterface) AdminServiceClient {
+	return &adminServiceClient{cc}
+}
+
+func (c *adminServiceClient) UpdateCredentials(ctx context.Context, in *UpdateCredentialsRequest, opts ...grpc.CallOption) (*UpdateCredentialsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateCredentialsResponse)
+	err := c.cc.Invoke(ctx, AdminService_UpdateCredentials_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) SetPlatformEnabled(ctx context.Context, in *SetPlatformEnabledRequest, opts ...grpc.CallOption) (*SetPlatformEnabledResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetPlatformEnabledResponse)
+	err := c.cc.Invoke(ctx, AdminService_SetPlatformEnabled_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) ResetCircuitBreakers(ctx context.Context, in *ResetCircuitBreakersRequest, opts ...grpc.CallOption) (*ResetCircuitBreakersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ResetCircuitBreakersResponse)
+	err := c.cc.Invoke(ctx, AdminService_ResetCircuitBreakers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) FlushCache(ctx context.Context, in *FlushCacheRequest, opts ...grpc.CallOption) (*FlushCacheResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FlushCacheResponse)
+	err := c.cc.Invoke(ctx, AdminService_FlushCache_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) SetLogLevel(ctx context.Context, in *SetLogLevelRequest, opts ...grpc.CallOption) (*SetLogLevelResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetLogLevelResponse)
+	err := c.cc.Invoke(ctx, AdminService_SetLogLevel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) GetConfig(ctx context.Context, in *GetConfigRequest, opts ...grpc.CallOption) (*GetConfigResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetConfigResponse)
+	err := c.cc.Invoke(ctx, AdminService_GetConfig_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) ListRecentSearches(ctx context.Context, in *ListRecentSearchesRequest, opts ...grpc.CallOption) (*ListRecentSearchesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListRecentSearchesResponse)
+	err := c.cc.Invoke(ctx, AdminService_ListRecentSearches_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminServiceClient) ListTrendingQueries(ctx context.Context, in *ListTrendingQueriesRequest, opts ...grpc.CallOption) (*ListTrendingQueriesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListTrendingQueriesResponse)
+	err := c.cc.Invoke(ctx, AdminService_ListTrendingQueries_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AdminServiceServer is the server API for AdminService service.
+// All implementations must embed UnimplementedAdminServiceServer
+// for forward compatibility.
+//
+// AdminService changes the running server without a restart. Every call
+// needs an "authorization: Bearer <ADMIN_TOKEN>" header, checked by an
+// interceptor in front of the whole service; the service refuses all calls
+// when ADMIN_TOKEN is unset. The token is a permission of its own: it grants
+// nothing on SearchService, and nothing else grants access here.
+type AdminServiceServer interface {
+	// UpdateCredentials replaces a platform's API tokens or keys, checking
+	// each with the upstream first
+	UpdateCredentials(context.Context, *UpdateCredentialsRequest) (*UpdateCredentialsResponse, error)
+	// SetPlatformEnabled takes a platform out of searches, or puts it back
+	SetPlatformEnabled(context.Context, *SetPlatformEnabledRequest) (*SetPlatformEnabledResponse, error)
+	// ResetCircuitBreakers closes platforms' circuit breakers, so the next
+	// searches call the upstreams again at once
+	ResetCircuitBreakers(context.Context, *ResetCircuitBreakersRequest) (*ResetCircuitBreakersResponse, error)
+	// FlushCache drops every cached response, exact and semantic
+	FlushCache(context.Context, *FlushCacheRequest) (*FlushCacheResponse, error)
+	// SetLogLevel changes which log lines are written
+	SetLogLevel(context.Context, *SetLogLevelRequest) (*SetLogLevelResponse, error)
+	// GetConfig returns the configuration the server is running with, with
+	// secrets redacted
+	GetConfig(context.Context, *GetConfigRequest) (*GetConfigResponse, error)
+	// ListRecentSearches returns the latest searches recorded in the search
+	// store (STORE_ENABLED)
+	ListRecentSearches(context.Context, *ListRecentSearchesRequest) (*ListRecentSearchesResponse, error)
+	// ListTrendingQueries returns the queries searched most often lately, from
+	// the search store
+	ListTrendingQueries(context.Context, *ListTrendingQueriesRequest) (*ListTrendingQueriesResponse, error)
+	mustEmbedUnimplementedAdminServiceServer()
+}
+
+// UnimplementedAdminServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedAdminServiceServer struct{}
+
+func (UnimplementedAdminServiceServer) UpdateCredentials(context.Context, *UpdateCredentialsRequest) (*UpdateCredentialsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method UpdateCredentials not implemented")
+}
+func (UnimplementedAdminServiceServer) SetPlatformEnabled(context.Context, *SetPlatformEnabledRequest) (*SetPlatformEnabledResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SetPlatformEnabled not implemented")
+}
+func (UnimplementedAdminServiceServer) ResetCircuitBreakers(context.Context, *ResetCircuitBreakersRequest) (*ResetCircuitBreakersResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ResetCircuitBreakers not implemented")
+}
+func (UnimplementedAdminServiceServer) FlushCache(context.Context, *FlushCacheRequest) (*FlushCacheResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method FlushCache not implemented")
+}
+func (UnimplementedAdminServiceServer) SetLogLevel(context.Context, *SetLogLevelRequest) (*SetLogLevelResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SetLogLevel not implemented")
+}
+func (UnimplementedAdminServiceServer) GetConfig(context.Context, *GetConfigRequest) (*GetConfigResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetConfig not implemented")
+}
+func (UnimplementedAdminServiceServer) ListRecentSearches(context.Context, *ListRecentSearchesRequest) (*ListRecentSearchesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListRecentSearches not implemented")
+}
+func (UnimplementedAdminServiceServer) ListTrendingQueries(context.Context, *ListTrendingQueriesRequest) (*ListTrendingQueriesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListTrendingQueries not implemented")
+}
+func (UnimplementedAdminServiceServer) mustEmbedUnimplementedAdminServiceServer() {}
+func (UnimplementedAdminServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeAdminServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AdminServiceServer will
+// result in compilation errors.
+type UnsafeAdminServiceServer interface {
+	mustEmbedUnimplementedAdminServiceServer()
+}
+
+func RegisterAdminServiceServer(s grpc.ServiceRegistrar, srv AdminServiceServer) {
+	// If the following call panics, it indicates UnimplementedAdminServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&AdminService_ServiceDesc, srv)
+}
+
+func _AdminService_UpdateCredentials_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateCredentialsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).UpdateCredentials(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_UpdateCredentials_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).UpdateCredentials(ctx, req.(*UpdateCredentialsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_SetPlatformEnabled_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetPlatformEnabledRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).SetPlatformEnabled(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_SetPlatformEnabled_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).SetPlatformEnabled(ctx, req.(*SetPlatformEnabledRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_ResetCircuitBreakers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResetCircuitBreakersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).ResetCircuitBreakers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_ResetCircuitBreakers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).ResetCircuitBreakers(ctx, req.(*ResetCircuitBreakersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_FlushCache_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FlushCacheRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).FlushCache(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_FlushCache_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).FlushCache(ctx, req.(*FlushCacheRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_SetLogLevel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetLogLevelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).SetLogLevel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_SetLogLevel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).SetLogLevel(ctx, req.(*SetLogLevelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_GetConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).GetConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_GetConfig_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).GetConfig(ctx, req.(*GetConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_ListRecentSearches_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRecentSearchesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).ListRecentSearches(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_ListRecentSearches_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).ListRecentSearches(ctx, req.(*ListRecentSearchesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminService_ListTrendingQueries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTrendingQueriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServiceServer).ListTrendingQueries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminService_ListTrendingQueries_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServiceServer).ListTrendingQueries(ctx, req.(*ListTrendingQueriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// AdminService_ServiceDesc is the grpc.ServiceDesc for AdminService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AdminService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "search.AdminService",
+	HandlerType: (*AdminServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "UpdateCredentials",
+			Handler:    _AdminService_UpdateCredentials_Handler,
+		},
+		{
+			MethodName: "SetPlatformEnabled",
+			Handler:    _AdminService_SetPlatformEnabled_Handler,
+		},
+		{
+			MethodName: "ResetCircuitBreakers",
+			Handler:    _AdminService_ResetCircuitBreakers_Handler,
+		},
+		{
+			MethodName: "FlushCache",
+			Handler:    _AdminService_FlushCache_Handler,
+		},
+		{
+			MethodName: "SetLogLevel",
+			Handler:    _AdminService_SetLogLevel_Handler,
+		},
+		{
+			MethodName: "GetConfig",
+			Handler:    _AdminService_GetConfig_Handler,
+		},
+		{
+			MethodName: "ListRecentSearches",
+			Handler:    _AdminService_ListRecentSearches_Handler,
+		},
+		{
+			MethodName: "ListTrendingQueries",
+			Handler:    _AdminService_ListTrendingQueries_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "proto/admin.proto",
+}
@@ -0,0 +1,519 @@
+// Protocol Buffer definition for the search proxy's saved searches
+// Version: 1.0
+// Searches the server re-runs on a schedule, posting new results to a
+// webhook. Served only when ALERTS_ENABLED=true, to callers presenting
+// ALERTS_TOKEN as a bearer token.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v3.12.4
+// source: proto/alerts.proto
+
+package proto
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// CreateSavedSearchRequest describes the search to save
+type CreateSavedSearchRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Label for the saved search, echoed in webhook payloads
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// The search to re-run, validated as FederatedSearch would (required)
+	Search *SearchRequest `protobuf:"bytes,2,opt,name=search,proto3" json:"search,omitempty"`
+	// Cron expression in UTC: minute hour day-of-month month day-of-week,
+	// e.g. "0 */6 * * *", or @hourly, @daily, @weekly, @monthly, @yearly
+	// (required)
+	Schedule string `protobuf:"bytes,3,opt,name=schedule,proto3" json:"schedule,omitempty"`
+	// http or https URL the new results are POSTed to (required). Its host
+	// must resolve to public addresses unless ALERTS_ALLOW_PRIVATE_WEBHOOKS
+	// is set.
+	WebhookUrl    string `protobuf:"bytes,4,opt,name=webhook_url,json=webhookUrl,proto3" json:"webhook_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateSavedSearchRequest) Reset() {
+	*x = CreateSavedSearchRequest{}
+	mi := &file_proto_alerts_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateSavedSearchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateSavedSearchRequest) ProtoMessage() {}
+
+func (x *CreateSavedSearchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_alerts_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateSavedSearchRequest.ProtoReflect.Descriptor instead.
+func (*CreateSavedSearchRequest) Descriptor() ([]byte, []int) {
+	return file_proto_alerts_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CreateSavedSearchRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateSavedSearchRequest) GetSearch() *SearchRequest {
+	if x != nil {
+		return x.Search
+	}
+	return nil
+}
+
+func (x *CreateSavedSearchRequest) GetSchedule() string {
+	if x != nil {
+		return x.Schedule
+	}
+	return ""
+}
+
+func (x *CreateSavedSearchRequest) GetWebhookUrl() string {
+	if x != nil {
+		return x.WebhookUrl
+	}
+	return ""
+}
+
+// ListSavedSearchesRequest takes no parameters
+type ListSavedSearchesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSavedSearchesRequest) Reset() {
+	*x = ListSavedSearchesRequest{}
+	mi := &file_proto_alerts_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSavedSearchesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSavedSearchesRequest) ProtoMessage() {}
+
+func (x *ListSavedSearchesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_alerts_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSavedSearchesRequest.ProtoReflect.Descriptor instead.
+func (*ListSavedSearchesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_alerts_proto_rawDescGZIP(), []int{1}
+}
+
+// DeleteSavedSearchRequest picks the saved search to delete
+type DeleteSavedSearchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteSavedSearchRequest) Reset() {
+	*x = DeleteSavedSearchRequest{}
+	mi := &file_proto_alerts_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteSavedSearchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteSavedSearchRequest) ProtoMessage() {}
+
+func (x *DeleteSavedSearchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_alerts_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteSavedSearchRequest.ProtoReflect.Descriptor instead.
+func (*DeleteSavedSearchRequest) Descriptor() ([]byte, []int) {
+	return file_proto_alerts_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *DeleteSavedSearchRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+// SavedSearch is a search the server re-runs on a schedule
+type SavedSearch struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Id       int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name     string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Search   *SearchRequest         `protobuf:"bytes,3,opt,name=search,proto3" json:"search,omitempty"`
+	Schedule string                 `protobuf:"bytes,4,opt,name=schedule,proto3" json:"schedule,omitempty"`
+	// Only the scheme and host; the rest, which commonly holds the
+	// receiver's secret, reads REDACTED
+	WebhookUrl string `protobuf:"bytes,5,opt,name=webhook_url,json=webhookUrl,proto3" json:"webhook_url,omitempty"`
+	// Unix timestamps; last_run_at is 0 until the first successful run
+	CreatedAt     int64 `protobuf:"varint,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	LastRunAt     int64 `protobuf:"varint,7,opt,name=last_run_at,json=lastRunAt,proto3" json:"last_run_at,omitempty"`
+	NextRunAt     int64 `protobuf:"varint,8,opt,name=next_run_at,json=nextRunAt,proto3" json:"next_run_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SavedSearch) Reset() {
+	*x = SavedSearch{}
+	mi := &file_proto_alerts_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SavedSearch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SavedSearch) ProtoMessage() {}
+
+func (x *SavedSearch) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_alerts_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SavedSearch.ProtoReflect.Descriptor instead.
+func (*SavedSearch) Descriptor() ([]byte, []int) {
+	return file_proto_alerts_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SavedSearch) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *SavedSearch) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SavedSearch) GetSearch() *SearchRequest {
+	if x != nil {
+		return x.Search
+	}
+	return nil
+}
+
+func (x *SavedSearch) GetSchedule() string {
+	if x != nil {
+		return x.Schedule
+	}
+	return ""
+}
+
+func (x *SavedSearch) GetWebhookUrl() string {
+	if x != nil {
+		return x.WebhookUrl
+	}
+	return ""
+}
+
+func (x *SavedSearch) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *SavedSearch) GetLastRunAt() int64 {
+	if x != nil {
+		return x.LastRunAt
+	}
+	return 0
+}
+
+func (x *SavedSearch) GetNextRunAt() int64 {
+	if x != nil {
+		return x.NextRunAt
+	}
+	return 0
+}
+
+// CreateSavedSearchResponse holds the new saved search
+type CreateSavedSearchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SavedSearch   *SavedSearch           `protobuf:"bytes,1,opt,name=saved_search,json=savedSearch,proto3" json:"saved_search,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateSavedSearchResponse) Reset() {
+	*x = CreateSavedSearchResponse{}
+	mi := &file_proto_alerts_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateSavedSearchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateSavedSearchResponse) ProtoMessage() {}
+
+func (x *CreateSavedSearchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_alerts_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateSavedSearchResponse.ProtoReflect.Descriptor instead.
+func (*CreateSavedSearchResponse) Descriptor() ([]byte, []int) {
+	return file_proto_alerts_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CreateSavedSearchResponse) GetSavedSearch() *SavedSearch {
+	if x != nil {
+		return x.SavedSearch
+	}
+	return nil
+}
+
+// ListSavedSearchesResponse holds every saved search
+type ListSavedSearchesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SavedSearches []*SavedSearch         `protobuf:"bytes,1,rep,name=saved_searches,json=savedSearches,proto3" json:"saved_searches,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSavedSearchesResponse) Reset() {
+	*x = ListSavedSearchesResponse{}
+	mi := &file_proto_alerts_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSavedSearchesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSavedSearchesResponse) ProtoMessage() {}
+
+func (x *ListSavedSearchesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_alerts_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSavedSearchesResponse.ProtoReflect.Descriptor instead.
+func (*ListSavedSearchesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_alerts_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListSavedSearchesResponse) GetSavedSearches() []*SavedSearch {
+	if x != nil {
+		return x.SavedSearches
+	}
+	return nil
+}
+
+// DeleteSavedSearchResponse confirms the deletion
+type DeleteSavedSearchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteSavedSearchResponse) Reset() {
+	*x = DeleteSavedSearchResponse{}
+	mi := &file_proto_alerts_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteSavedSearchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteSavedSearchResponse) ProtoMessage() {}
+
+func (x *DeleteSavedSearchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_alerts_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteSavedSearchResponse.ProtoReflect.Descriptor instead.
+func (*DeleteSavedSearchResponse) Descriptor() ([]byte, []int) {
+	return file_proto_alerts_proto_rawDescGZIP(), []int{6}
+}
+
+var File_proto_alerts_proto protoreflect.FileDescriptor
+
+const file_proto_alerts_proto_rawDesc = "" +
+	"\n" +
+	"\x12proto/alerts.proto\x12\x06search\x1a\x12proto/search.proto\"\x9a\x01\n" +
+	"\x18CreateSavedSearchRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12-\n" +
+	"\x06search\x18\x02 \x01(\v2\x15.search.SearchRequestR\x06search\x12\x1a\n" +
+	"\bschedule\x18\x03 \x01(\tR\bschedule\x12\x1f\n" +
+	"\vwebhook_url\x18\x04 \x01(\tR\n" +
+	"webhookUrl\"\x1a\n" +
+	"\x18ListSavedSearchesRequest\"*\n" +
+	"\x18DeleteSavedSearchRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"\xfc\x01\n" +
+	"\vSavedSearch\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12-\n" +
+	"\x06search\x18\x03 \x01(\v2\x15.search.SearchRequestR\x06search\x12\x1a\n" +
+	"\bschedule\x18\x04 \x01(\tR\bschedule\x12\x1f\n" +
+	"\vwebhook_url\x18\x05 \x01(\tR\n" +
+	"webhookUrl\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\x03R\tcreatedAt\x12\x1e\n" +
+	"\vlast_run_at\x18\a \x01(\x03R\tlastRunAt\x12\x1e\n" +
+	"\vnext_run_at\x18\b \x01(\x03R\tnextRunAt\"S\n" +
+	"\x19CreateSavedSearchResponse\x126\n" +
+	"\fsaved_search\x18\x01 \x01(\v2\x13.search.SavedSearchR\vsavedSearch\"W\n" +
+	"\x19ListSavedSearchesResponse\x12:\n" +
+	"\x0esaved_searches\x18\x01 \x03(\v2\x13.search.SavedSearchR\rsavedSearches\"\x1b\n" +
+	"\x19DeleteSavedSearchResponse2\x9c\x02\n" +
+	"\fAlertService\x12X\n" +
+	"\x11CreateSavedSearch\x12 .search.CreateSavedSearchRequest\x1a!.search.CreateSavedSearchResponse\x12X\n" +
+	"\x11ListSavedSearches\x12 .search.ListSavedSearchesRequest\x1a!.search.ListSavedSearchesResponse\x12X\n" +
+	"\x11DeleteSavedSearch\x12 .search.DeleteSavedSearchRequest\x1a!.search.DeleteSavedSearchResponseB+Z)github.com/farhapartex/search-proxy/protob\x06proto3"
+
+var (
+	file_proto_alerts_proto_rawDescOnce sync.Once
+	file_proto_alerts_proto_rawDescData []byte
+)
+
+func file_proto_alerts_proto_rawDescGZIP() []byte {
+	file_proto_alerts_proto_rawDescOnce.Do(func() {
+		file_proto_alerts_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_alerts_proto_rawDesc), len(file_proto_alerts_proto_rawDesc)))
+	})
+	return file_proto_alerts_proto_rawDescData
+}
+
+var file_proto_alerts_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_proto_alerts_proto_goTypes = []any{
+	(*CreateSavedSearchRequest)(nil),  // 0: search.CreateSavedSearchRequest
+	(*ListSavedSearchesRequest)(nil),  // 1: search.ListSavedSearchesRequest
+	(*DeleteSavedSearchRequest)(nil),  // 2: search.DeleteSavedSearchRequest
+	(*SavedSearch)(nil),               // 3: search.SavedSearch
+	(*CreateSavedSearchResponse)(nil), // 4: search.CreateSavedSearchResponse
+	(*ListSavedSearchesResponse)(nil), // 5: search.ListSavedSearchesResponse
+	(*DeleteSavedSearchResponse)(nil), // 6: search.DeleteSavedSearchResponse
+	(*SearchRequest)(nil),             // 7: search.SearchRequest
+}
+var file_proto_alerts_proto_depIdxs = []int32{
+	7, // 0: search.CreateSavedSearchRequest.search:type_name -> search.SearchRequest
+	7, // 1: search.SavedSearch.search:type_name -> search.SearchRequest
+	3, // 2: search.CreateSavedSearchResponse.saved_search:type_name -> search.SavedSearch
+	3, // 3: search.ListSavedSearchesResponse.saved_searches:type_name -> search.SavedSearch
+	0, // 4: search.AlertService.CreateSavedSearch:input_type -> search.CreateSavedSearchRequest
+	1, // 5: search.AlertService.ListSavedSearches:input_type -> search.ListSavedSearchesRequest
+	2, // 6: search.AlertService.DeleteSavedSearch:input_type -> search.DeleteSavedSearchRequest
+	4, // 7: search.AlertService.CreateSavedSearch:output_type -> search.CreateSavedSearchResponse
+	5, // 8: search.AlertService.ListSavedSearches:output_type -> search.ListSavedSearchesResponse
+	6, // 9: search.AlertService.DeleteSavedSearch:output_type -> search.DeleteSavedSearchResponse
+	7, // [7:10] is the sub-list for method output_type
+	4, // [4:7] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_proto_alerts_proto_init() }
+func file_proto_alerts_proto_init() {
+	if File_proto_alerts_proto != nil {
+		return
+	}
+	file_proto_search_proto_init()
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_alerts_proto_rawDesc), len(file_proto_alerts_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_proto_alerts_proto_goTypes,
+		DependencyIndexes: file_proto_alerts_proto_depIdxs,
+		MessageInfos:      file_proto_alerts_proto_msgTypes,
+	}.Build()
+	File_proto_alerts_proto = out.File
+	file_proto_alerts_proto_goTypes = nil
+	file_proto_alerts_proto_depIdxs = nil
+}
@@ -25,6 +25,319 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// ContentType is the kind of GitHub content a search looks for
+type ContentType int32
+
+const (
+	// Repositories
+	ContentType_CONTENT_TYPE_UNSPECIFIED  ContentType = 0
+	ContentType_CONTENT_TYPE_REPOSITORIES ContentType = 1
+	// Files, with the matching lines as snippet and the file path in the
+	// path metadata
+	ContentType_CONTENT_TYPE_CODE ContentType = 2
+	// Issues, with state, comments and labels metadata; add "is:pr" to the
+	// query for pull requests
+	ContentType_CONTENT_TYPE_ISSUES ContentType = 3
+	// Discussions, with comments, upvotes, category and is_answered metadata
+	ContentType_CONTENT_TYPE_DISCUSSIONS ContentType = 4
+)
+
+// Enum value maps for ContentType.
+var (
+	ContentType_name = map[int32]string{
+		0: "CONTENT_TYPE_UNSPECIFIED",
+		1: "CONTENT_TYPE_REPOSITORIES",
+		2: "CONTENT_TYPE_CODE",
+		3: "CONTENT_TYPE_ISSUES",
+		4: "CONTENT_TYPE_DISCUSSIONS",
+	}
+	ContentType_value = map[string]int32{
+		"CONTENT_TYPE_UNSPECIFIED":  0,
+		"CONTENT_TYPE_REPOSITORIES": 1,
+		"CONTENT_TYPE_CODE":         2,
+		"CONTENT_TYPE_ISSUES":       3,
+		"CONTENT_TYPE_DISCUSSIONS":  4,
+	}
+)
+
+func (x ContentType) Enum() *ContentType {
+	p := new(ContentType)
+	*p = x
+	return p
+}
+
+func (x ContentType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ContentType) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_search_proto_enumTypes[0].Descriptor()
+}
+
+func (ContentType) Type() protoreflect.EnumType {
+	return &file_proto_search_proto_enumTypes[0]
+}
+
+func (x ContentType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ContentType.Descriptor instead.
+func (ContentType) EnumDescriptor() ([]byte, []int) {
+	return file_proto_search_proto_rawDescGZIP(), []int{0}
+}
+
+// SortOrder is how results from different platforms are merged
+type SortOrder int32
+
+const (
+	// Use the server default
+	SortOrder_SORT_ORDER_UNSPECIFIED SortOrder = 0
+	// Each platform's results in the order the platforms answered
+	SortOrder_SORT_ORDER_ARRIVAL SortOrder = 1
+	// One list ordered by reciprocal rank fusion of the platforms' own
+	// rankings; results several platforms return rank higher
+	SortOrder_SORT_ORDER_RELEVANCE SortOrder = 2
+	// One list ordered by normalized_score, most popular first
+	SortOrder_SORT_ORDER_SCORE SortOrder = 3
+)
+
+// Enum value maps for SortOrder.
+var (
+	SortOrder_name = map[int32]string{
+		0: "SORT_ORDER_UNSPECIFIED",
+		1: "SORT_ORDER_ARRIVAL",
+		2: "SORT_ORDER_RELEVANCE",
+		3: "SORT_ORDER_SCORE",
+	}
+	SortOrder_value = map[string]int32{
+		"SORT_ORDER_UNSPECIFIED": 0,
+		"SORT_ORDER_ARRIVAL":     1,
+		"SORT_ORDER_RELEVANCE":   2,
+		"SORT_ORDER_SCORE":       3,
+	}
+)
+
+func (x SortOrder) Enum() *SortOrder {
+	p := new(SortOrder)
+	*p = x
+	return p
+}
+
+func (x SortOrder) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SortOrder) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_search_proto_enumTypes[1].Descriptor()
+}
+
+func (SortOrder) Type() protoreflect.EnumType {
+	return &file_proto_search_proto_enumTypes[1]
+}
+
+func (x SortOrder) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SortOrder.Descriptor instead.
+func (SortOrder) EnumDescriptor() ([]byte, []int) {
+	return file_proto_search_proto_rawDescGZIP(), []int{1}
+}
+
+// PlatformState is how a platform's part of the search ended
+type PlatformState int32
+
+const (
+	PlatformState_PLATFORM_STATE_UNSPECIFIED PlatformState = 0
+	PlatformState_PLATFORM_STATE_OK          PlatformState = 1
+	PlatformState_PLATFORM_STATE_TIMEOUT     PlatformState = 2
+	PlatformState_PLATFORM_STATE_ERROR       PlatformState = 3
+	// The upstream wasn't called: its circuit breaker is open, or the proxy's
+	// own rate limiter or quota throttle held the request back
+	PlatformState_PLATFORM_STATE_SKIPPED PlatformState = 4
+)
+
+// Enum value maps for PlatformState.
+var (
+	PlatformState_name = map[int32]string{
+		0: "PLATFORM_STATE_UNSPECIFIED",
+		1: "PLATFORM_STATE_OK",
+		2: "PLATFORM_STATE_TIMEOUT",
+		3: "PLATFORM_STATE_ERROR",
+		4: "PLATFORM_STATE_SKIPPED",
+	}
+	PlatformState_value = map[string]int32{
+		"PLATFORM_STATE_UNSPECIFIED": 0,
+		"PLATFORM_STATE_OK":          1,
+		"PLATFORM_STATE_TIMEOUT":     2,
+		"PLATFORM_STATE_ERROR":       3,
+		"PLATFORM_STATE_SKIPPED":     4,
+	}
+)
+
+func (x PlatformState) Enum() *PlatformState {
+	p := new(PlatformState)
+	*p = x
+	return p
+}
+
+func (x PlatformState) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PlatformState) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_search_proto_enumTypes[2].Descriptor()
+}
+
+func (PlatformState) Type() protoreflect.EnumType {
+	return &file_proto_search_proto_enumTypes[2]
+}
+
+func (x PlatformState) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PlatformState.Descriptor instead.
+func (PlatformState) EnumDescriptor() ([]byte, []int) {
+	return file_proto_search_proto_rawDescGZIP(), []int{2}
+}
+
+// ResultType is the kind of content a result points to, independent of the
+// platform it came from
+type ResultType int32
+
+const (
+	ResultType_RESULT_TYPE_UNSPECIFIED     ResultType = 0
+	ResultType_RESULT_TYPE_REPOSITORY      ResultType = 1
+	ResultType_RESULT_TYPE_QUESTION        ResultType = 2
+	ResultType_RESULT_TYPE_POST            ResultType = 3
+	ResultType_RESULT_TYPE_ARTICLE         ResultType = 4
+	ResultType_RESULT_TYPE_PACKAGE         ResultType = 5
+	ResultType_RESULT_TYPE_VIDEO           ResultType = 6
+	ResultType_RESULT_TYPE_ISSUE           ResultType = 7
+	ResultType_RESULT_TYPE_DOC             ResultType = 8
+	ResultType_RESULT_TYPE_CODE            ResultType = 9
+	ResultType_RESULT_TYPE_DISCUSSION      ResultType = 10
+	ResultType_RESULT_TYPE_CONTAINER_IMAGE ResultType = 11
+)
+
+// Enum value maps for ResultType.
+var (
+	ResultType_name = map[int32]string{
+		0:  "RESULT_TYPE_UNSPECIFIED",
+		1:  "RESULT_TYPE_REPOSITORY",
+		2:  "RESULT_TYPE_QUESTION",
+		3:  "RESULT_TYPE_POST",
+		4:  "RESULT_TYPE_ARTICLE",
+		5:  "RESULT_TYPE_PACKAGE",
+		6:  "RESULT_TYPE_VIDEO",
+		7:  "RESULT_TYPE_ISSUE",
+		8:  "RESULT_TYPE_DOC",
+		9:  "RESULT_TYPE_CODE",
+		10: "RESULT_TYPE_DISCUSSION",
+		11: "RESULT_TYPE_CONTAINER_IMAGE",
+	}
+	ResultType_value = map[string]int32{
+		"RESULT_TYPE_UNSPECIFIED":     0,
+		"RESULT_TYPE_REPOSITORY":      1,
+		"RESULT_TYPE_QUESTION":        2,
+		"RESULT_TYPE_POST":            3,
+		"RESULT_TYPE_ARTICLE":         4,
+		"RESULT_TYPE_PACKAGE":         5,
+		"RESULT_TYPE_VIDEO":           6,
+		"RESULT_TYPE_ISSUE":           7,
+		"RESULT_TYPE_DOC":             8,
+		"RESULT_TYPE_CODE":            9,
+		"RESULT_TYPE_DISCUSSION":      10,
+		"RESULT_TYPE_CONTAINER_IMAGE": 11,
+	}
+)
+
+func (x ResultType) Enum() *ResultType {
+	p := new(ResultType)
+	*p = x
+	return p
+}
+
+func (x ResultType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ResultType) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_search_proto_enumTypes[3].Descriptor()
+}
+
+func (ResultType) Type() protoreflect.EnumType {
+	return &file_proto_search_proto_enumTypes[3]
+}
+
+func (x ResultType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ResultType.Descriptor instead.
+func (ResultType) EnumDescriptor() ([]byte, []int) {
+	return file_proto_search_proto_rawDescGZIP(), []int{3}
+}
+
+// CircuitState is where a platform's circuit breaker stands
+type CircuitState int32
+
+const (
+	// The server runs without circuit breakers
+	CircuitState_CIRCUIT_STATE_UNSPECIFIED CircuitState = 0
+	// Searches reach the platform
+	CircuitState_CIRCUIT_STATE_CLOSED CircuitState = 1
+	// The platform failed repeatedly and is skipped until the breaker times out
+	CircuitState_CIRCUIT_STATE_OPEN CircuitState = 2
+	// The next search probes whether the platform has recovered
+	CircuitState_CIRCUIT_STATE_HALF_OPEN CircuitState = 3
+)
+
+// Enum value maps for CircuitState.
+var (
+	CircuitState_name = map[int32]string{
+		0: "CIRCUIT_STATE_UNSPECIFIED",
+		1: "CIRCUIT_STATE_CLOSED",
+		2: "CIRCUIT_STATE_OPEN",
+		3: "CIRCUIT_STATE_HALF_OPEN",
+	}
+	CircuitState_value = map[string]int32{
+		"CIRCUIT_STATE_UNSPECIFIED": 0,
+		"CIRCUIT_STATE_CLOSED":      1,
+		"CIRCUIT_STATE_OPEN":        2,
+		"CIRCUIT_STATE_HALF_OPEN":   3,
+	}
+)
+
+func (x CircuitState) Enum() *CircuitState {
+	p := new(CircuitState)
+	*p = x
+	return p
+}
+
+func (x CircuitState) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CircuitState) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_search_proto_enumTypes[4].Descriptor()
+}
+
+func (CircuitState) Type() protoreflect.EnumType {
+	return &file_proto_search_proto_enumTypes[4]
+}
+
+func (x CircuitState) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CircuitState.Descriptor instead.
+func (CircuitState) EnumDescriptor() ([]byte, []int) {
+	return file_proto_search_proto_rawDescGZIP(), []int{4}
+}
+
 // SearchRequest contains the search query and parameters
 type SearchRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -36,10 +349,92 @@ type SearchRequest struct {
 	MaxResults int32 `protobuf:"varint,2,opt,name=max_results,json=maxResults,proto3" json:"max_results,omitempty"`
 	// List of platforms to search (optional)
 	// If empty, searches all platforms: ["github", "stackoverflow", "reddit"]
+	// Names are case-insensitive and accept aliases ("gh", "so", "hn");
+	// responses always use the canonical names
 	// Valid values: "github", "stackoverflow", "reddit"
-	Platforms     []string `protobuf:"bytes,3,rep,name=platforms,proto3" json:"platforms,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	Platforms []string `protobuf:"bytes,3,rep,name=platforms,proto3" json:"platforms,omitempty"`
+	// Continuation token from a previous response's next_page_token (optional)
+	// Returns the next page of results; send the same query and options as the
+	// first request. Platforms without more results are no longer queried, and
+	// the platforms field is ignored.
+	PageToken string `protobuf:"bytes,4,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	// BCP 47 locale of the user, e.g. "pt-BR" (optional)
+	// Forwarded to platforms that can localize results; others ignore it
+	Locale string `protobuf:"bytes,5,opt,name=locale,proto3" json:"locale,omitempty"`
+	// Longest snippet to return, in characters (optional)
+	// Default: DEFAULT_SNIPPET_LENGTH on the server (500), Range: 1-5000,
+	// capped by MAX_SNIPPET_LENGTH on the server
+	MaxSnippetLength int32 `protobuf:"varint,6,opt,name=max_snippet_length,json=maxSnippetLength,proto3" json:"max_snippet_length,omitempty"`
+	// Summarize long snippets into Result.summary (optional)
+	// Ignored unless summarization is enabled on the server
+	Summarize bool `protobuf:"varint,7,opt,name=summarize,proto3" json:"summarize,omitempty"`
+	// Only return results from these sources (optional), on top of the
+	// server's own allowlist. Entries are "domain:example.com",
+	// "subreddit:golang", "org:microsoft" or "author:spez" ("author:reddit/spez"
+	// for one platform's author); a kind restricts only the results it applies
+	// to (subreddit rules leave GitHub results alone, author rules results
+	// without an author)
+	AllowSources []string `protobuf:"bytes,8,rep,name=allow_sources,json=allowSources,proto3" json:"allow_sources,omitempty"`
+	// Drop results from these sources (optional), on top of the server's own
+	// denylist; same format as allow_sources
+	DenySources []string `protobuf:"bytes,9,rep,name=deny_sources,json=denySources,proto3" json:"deny_sources,omitempty"`
+	// Only Stack Overflow questions with an accepted or upvoted answer
+	AnsweredOnly bool `protobuf:"varint,10,opt,name=answered_only,json=answeredOnly,proto3" json:"answered_only,omitempty"`
+	// Only GitHub repositories with at least this many stars
+	MinStars int32 `protobuf:"varint,11,opt,name=min_stars,json=minStars,proto3" json:"min_stars,omitempty"`
+	// Only Reddit posts with at least this score
+	MinUpvotes int32 `protobuf:"varint,12,opt,name=min_upvotes,json=minUpvotes,proto3" json:"min_upvotes,omitempty"`
+	// CEL expression over result fields and metadata (optional), e.g.
+	// `platform == "github" && int(metadata.stars) > 100`. Variables:
+	// platform, title, snippet, url, author (strings), timestamp, created_at,
+	// updated_at, age_days (ints) and metadata (map of strings). Results the
+	// expression fails to evaluate on, such as for a missing metadata key,
+	// are dropped. Max 512 bytes
+	Filter string `protobuf:"bytes,13,opt,name=filter,proto3" json:"filter,omitempty"`
+	// Overall search budget in milliseconds (optional); platforms that haven't
+	// answered by then are reported as timed out
+	// Default: SERVER_TIMEOUT_MS, capped at MAX_SERVER_TIMEOUT_MS
+	TimeoutMs int32 `protobuf:"varint,14,opt,name=timeout_ms,json=timeoutMs,proto3" json:"timeout_ms,omitempty"`
+	// Budget for each platform in milliseconds (optional)
+	// Default: PER_API_TIMEOUT_MS, capped at MAX_PER_API_TIMEOUT_MS
+	PerPlatformTimeoutMs int32 `protobuf:"varint,15,opt,name=per_platform_timeout_ms,json=perPlatformTimeoutMs,proto3" json:"per_platform_timeout_ms,omitempty"`
+	// Preferred languages in Accept-Language syntax, e.g. "pt-BR, en;q=0.8"
+	// (optional). Each result's detected language is reported in its
+	// content_language metadata, and results in preferred languages come first
+	AcceptLanguage string `protobuf:"bytes,16,opt,name=accept_language,json=acceptLanguage,proto3" json:"accept_language,omitempty"`
+	// Drop results detected as being in a language accept_language doesn't
+	// list (optional); results whose language can't be detected are kept
+	AcceptLanguageOnly bool `protobuf:"varint,17,opt,name=accept_language_only,json=acceptLanguageOnly,proto3" json:"accept_language_only,omitempty"`
+	// Merge weights by platform (optional), e.g. {"github": 3, "reddit": 1}
+	// for three GitHub results to every Reddit one. Results are blended round
+	// by round, each round taking as many results from a platform as its
+	// weight; unlisted platforms weigh 1. Weights must be between 1 and 100.
+	// Without weights results keep the order they were merged in.
+	InterleaveWeights map[string]int32 `protobuf:"bytes,18,rep,name=interleave_weights,json=interleaveWeights,proto3" json:"interleave_weights,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	// How merged results are ordered (optional)
+	// Default: RANKING_DEFAULT_SORT
+	Sort SortOrder `protobuf:"varint,19,opt,name=sort,proto3,enum=search.SortOrder" json:"sort,omitempty"`
+	// What to search GitHub for (optional): repositories, code, issues or
+	// discussions. Code and discussion search need a GitHub token. Other
+	// platforms ignore it.
+	// Default: repositories
+	ContentType ContentType `protobuf:"varint,20,opt,name=content_type,json=contentType,proto3,enum=search.ContentType" json:"content_type,omitempty"`
+	// Only results created at or after this time (Unix seconds, optional).
+	// Platforms filter by it where they can (GitHub created:, Stack Overflow
+	// fromdate, Hacker News, Reddit's t= period) and results outside the
+	// range are dropped either way; results without a creation time are
+	// kept. Combines with after: and before: terms, the tighter bound
+	// winning
+	Since int64 `protobuf:"varint,21,opt,name=since,proto3" json:"since,omitempty"`
+	// Only results created before this time (Unix seconds, optional); must
+	// be after since
+	Until int64 `protobuf:"varint,22,opt,name=until,proto3" json:"until,omitempty"`
+	// Maximum results by platform (optional), e.g. {"github": 50,
+	// "reddit": 10}, overriding max_results for the platforms listed.
+	// Names accept aliases; each value must be between 1 and 100
+	MaxResultsPerPlatform map[string]int32 `protobuf:"bytes,23,rep,name=max_results_per_platform,json=maxResultsPerPlatform,proto3" json:"max_results_per_platform,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
 }
 
 func (x *SearchRequest) Reset() {
@@ -72,51 +467,2045 @@ func (*SearchRequest) Descriptor() ([]byte, []int) {
 	return file_proto_search_proto_rawDescGZIP(), []int{0}
 }
 
-func (x *SearchRequest) GetQuery() string {
+func (x *SearchRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetMaxResults() int32 {
+	if x != nil {
+		return x.MaxResults
+	}
+	return 0
+}
+
+func (x *SearchRequest) GetPlatforms() []string {
+	if x != nil {
+		return x.Platforms
+	}
+	return nil
+}
+
+func (x *SearchRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetLocale() string {
+	if x != nil {
+		return x.Locale
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetMaxSnippetLength() int32 {
+	if x != nil {
+		return x.MaxSnippetLength
+	}
+	return 0
+}
+
+func (x *SearchRequest) GetSummarize() bool {
+	if x != nil {
+		return x.Summarize
+	}
+	return false
+}
+
+func (x *SearchRequest) GetAllowSources() []string {
+	if x != nil {
+		return x.AllowSources
+	}
+	return nil
+}
+
+func (x *SearchRequest) GetDenySources() []string {
+	if x != nil {
+		return x.DenySources
+	}
+	return nil
+}
+
+func (x *SearchRequest) GetAnsweredOnly() bool {
+	if x != nil {
+		return x.AnsweredOnly
+	}
+	return false
+}
+
+func (x *SearchRequest) GetMinStars() int32 {
+	if x != nil {
+		return x.MinStars
+	}
+	return 0
+}
+
+func (x *SearchRequest) GetMinUpvotes() int32 {
+	if x != nil {
+		return x.MinUpvotes
+	}
+	return 0
+}
+
+func (x *SearchRequest) GetFilter() string {
+	if x != nil {
+		return x.Filter
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetTimeoutMs() int32 {
+	if x != nil {
+		return x.TimeoutMs
+	}
+	return 0
+}
+
+func (x *SearchRequest) GetPerPlatformTimeoutMs() int32 {
+	if x != nil {
+		return x.PerPlatformTimeoutMs
+	}
+	return 0
+}
+
+func (x *SearchRequest) GetAcceptLanguage() string {
+	if x != nil {
+		return x.AcceptLanguage
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetAcceptLanguageOnly() bool {
+	if x != nil {
+		return x.AcceptLanguageOnly
+	}
+	return false
+}
+
+func (x *SearchRequest) GetInterleaveWeights() map[string]int32 {
+	if x != nil {
+		return x.InterleaveWeights
+	}
+	return nil
+}
+
+func (x *SearchRequest) GetSort() SortOrder {
+	if x != nil {
+		return x.Sort
+	}
+	return SortOrder_SORT_ORDER_UNSPECIFIED
+}
+
+func (x *SearchRequest) GetContentType() ContentType {
+	if x != nil {
+		return x.ContentType
+	}
+	return ContentType_CONTENT_TYPE_UNSPECIFIED
+}
+
+func (x *SearchRequest) GetSince() int64 {
+	if x != nil {
+		return x.Since
+	}
+	return 0
+}
+
+func (x *SearchRequest) GetUntil() int64 {
+	if x != nil {
+		return x.Until
+	}
+	return 0
+}
+
+func (x *SearchRequest) GetMaxResultsPerPlatform() map[string]int32 {
+	if x != nil {
+		return x.MaxResultsPerPlatform
+	}
+	return nil
+}
+
+// HealthCheckRequest for service health monitoring
+type HealthCheckRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Optional: service name to check
+	Service       string `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HealthCheckRequest) Reset() {
+	*x = HealthCheckRequest{}
+	mi := &file_proto_search_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HealthCheckRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthCheckRequest) ProtoMessage() {}
+
+func (x *HealthCheckRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_search_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthCheckRequest.ProtoReflect.Descriptor instead.
+func (*HealthCheckRequest) Descriptor() ([]byte, []int) {
+	return file_proto_search_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *HealthCheckRequest) GetService() string {
+	if x != nil {
+		return x.Service
+	}
+	return ""
+}
+
+// ListPlatformsRequest is empty; every registered platform is listed
+type ListPlatformsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPlatformsRequest) Reset() {
+	*x = ListPlatformsRequest{}
+	mi := &file_proto_search_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPlatformsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPlatformsRequest) ProtoMessage() {}
+
+func (x *ListPlatformsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_search_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPlatformsRequest.ProtoReflect.Descriptor instead.
+func (*ListPlatformsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_search_proto_rawDescGZIP(), []int{2}
+}
+
+// AnswerRequest asks for an answer synthesized from search results
+type AnswerRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The search to answer from (required); its query is the question
+	Search *SearchRequest `protobuf:"bytes,1,opt,name=search,proto3" json:"search,omitempty"`
+	// Number of top results given to the LLM as sources (optional)
+	// Default: 5, Range: 1-20
+	MaxSources    int32 `protobuf:"varint,2,opt,name=max_sources,json=maxSources,proto3" json:"max_sources,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AnswerRequest) Reset() {
+	*x = AnswerRequest{}
+	mi := &file_proto_search_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnswerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnswerRequest) ProtoMessage() {}
+
+func (x *AnswerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_search_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnswerRequest.ProtoReflect.Descriptor instead.
+func (*AnswerRequest) Descriptor() ([]byte, []int) {
+	return file_proto_search_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *AnswerRequest) GetSearch() *SearchRequest {
+	if x != nil {
+		return x.Search
+	}
+	return nil
+}
+
+func (x *AnswerRequest) GetMaxSources() int32 {
+	if x != nil {
+		return x.MaxSources
+	}
+	return 0
+}
+
+// MultiSearchRequest is a batch of independent searches
+type MultiSearchRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Searches to run (required); each takes the same fields, limits and
+	// timeouts as a FederatedSearch request
+	// Range: 1-MULTI_SEARCH_MAX_QUERIES (default 10)
+	Searches      []*SearchRequest `protobuf:"bytes,1,rep,name=searches,proto3" json:"searches,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MultiSearchRequest) Reset() {
+	*x = MultiSearchRequest{}
+	mi := &file_proto_search_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MultiSearchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MultiSearchRequest) ProtoMessage() {}
+
+func (x *MultiSearchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_search_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MultiSearchRequest.ProtoReflect.Descriptor instead.
+func (*MultiSearchRequest) Descriptor() ([]byte, []int) {
+	return file_proto_search_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *MultiSearchRequest) GetSearches() []*SearchRequest {
+	if x != nil {
+		return x.Searches
+	}
+	return nil
+}
+
+// ResultDetailsRequest names a result whose full content is wanted
+type ResultDetailsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Platform the result came from (required); aliases are accepted
+	// Valid values: "stackoverflow" and the other Stack Exchange sites,
+	// "github", "reddit"
+	Platform string `protobuf:"bytes,1,opt,name=platform,proto3" json:"platform,omitempty"`
+	// The result's url, as returned in Result.url (required). GitHub URLs
+	// must point to a repository
+	Url string `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	// Most answers or comments to return (optional)
+	// Default: 5, Range: 0-50
+	MaxReplies int32 `protobuf:"varint,3,opt,name=max_replies,json=maxReplies,proto3" json:"max_replies,omitempty"`
+	// Longest content to return for the result and for each reply, in
+	// characters (optional)
+	// Default: 20000, Range: 1-100000
+	MaxContentLength int32 `protobuf:"varint,4,opt,name=max_content_length,json=maxContentLength,proto3" json:"max_content_length,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ResultDetailsRequest) Reset() {
+	*x = ResultDetailsRequest{}
+	mi := &file_proto_search_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResultDetailsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResultDetailsRequest) ProtoMessage() {}
+
+func (x *ResultDetailsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_search_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResultDetailsRequest.ProtoReflect.Descriptor instead.
+func (*ResultDetailsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_search_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ResultDetailsRequest) GetPlatform() string {
+	if x != nil {
+		return x.Platform
+	}
+	return ""
+}
+
+func (x *ResultDetailsRequest) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *ResultDetailsRequest) GetMaxReplies() int32 {
+	if x != nil {
+		return x.MaxReplies
+	}
+	return 0
+}
+
+func (x *ResultDetailsRequest) GetMaxContentLength() int32 {
+	if x != nil {
+		return x.MaxContentLength
+	}
+	return 0
+}
+
+// SearchResponse contains the aggregated search results
+type SearchResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// List of search results from all platforms
+	Results []*Result `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	// Total number of results returned
+	TotalCount int32 `protobuf:"varint,2,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
+	// Platforms that successfully returned results
+	PlatformsSuccess []string `protobuf:"bytes,3,rep,name=platforms_success,json=platformsSuccess,proto3" json:"platforms_success,omitempty"`
+	// Platforms that timed out (exceeded 400ms)
+	PlatformsTimeout []string `protobuf:"bytes,4,rep,name=platforms_timeout,json=platformsTimeout,proto3" json:"platforms_timeout,omitempty"`
+	// Platforms that returned errors
+	PlatformsError []string `protobuf:"bytes,5,rep,name=platforms_error,json=platformsError,proto3" json:"platforms_error,omitempty"`
+	// Response metadata
+	Metadata *ResponseMetadata `protobuf:"bytes,6,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	// Set when more results follow: the rest of a response cut to respect the
+	// maximum response size, or the next page of the platforms that have more
+	// Pass it as page_token to fetch them; it is empty on the last page
+	NextPageToken string `protobuf:"bytes,7,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	// Outcome of each platform queried, in the order they finished
+	PlatformStatuses []*PlatformStatus `protobuf:"bytes,8,rep,name=platform_statuses,json=platformStatuses,proto3" json:"platform_statuses,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *SearchResponse) Reset() {
+	*x = SearchResponse{}
+	mi := &file_proto_search_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResponse) ProtoMessage() {}
+
+func (x *SearchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_search_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResponse.ProtoReflect.Descriptor instead.
+func (*SearchResponse) Descriptor() ([]byte, []int) {
+	return file_proto_search_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *SearchResponse) GetResults() []*Result {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *SearchResponse) GetTotalCount() int32 {
+	if x != nil {
+		return x.TotalCount
+	}
+	return 0
+}
+
+func (x *SearchResponse) GetPlatformsSuccess() []string {
+	if x != nil {
+		return x.PlatformsSuccess
+	}
+	return nil
+}
+
+func (x *SearchResponse) GetPlatformsTimeout() []string {
+	if x != nil {
+		return x.PlatformsTimeout
+	}
+	return nil
+}
+
+func (x *SearchResponse) GetPlatformsError() []string {
+	if x != nil {
+		return x.PlatformsError
+	}
+	return nil
+}
+
+func (x *SearchResponse) GetMetadata() *ResponseMetadata {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *SearchResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+func (x *SearchResponse) GetPlatformStatuses() []*PlatformStatus {
+	if x != nil {
+		return x.PlatformStatuses
+	}
+	return nil
+}
+
+// PlatformStatus explains how one platform's search went, so clients can
+// show why a source is missing
+type PlatformStatus struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Canonical platform name
+	Platform string        `protobuf:"bytes,1,opt,name=platform,proto3" json:"platform,omitempty"`
+	State    PlatformState `protobuf:"varint,2,opt,name=state,proto3,enum=search.PlatformState" json:"state,omitempty"`
+	// Machine-readable reason for a timeout, error or skip: "timeout",
+	// "queue_timeout", "rate_limited", "unauthorized", "upstream_unavailable",
+	// "upstream_error", "response_too_large", "circuit_open", "disabled",
+	// "overloaded" or "internal";
+	// empty when ok
+	ErrorCode string `protobuf:"bytes,3,opt,name=error_code,json=errorCode,proto3" json:"error_code,omitempty"`
+	// HTTP status the upstream answered with, when it answered
+	HttpStatus int32 `protobuf:"varint,4,opt,name=http_status,json=httpStatus,proto3" json:"http_status,omitempty"`
+	// Seconds to wait before retrying, when the upstream or the proxy's own
+	// rate limiter said
+	RetryAfterSeconds int32 `protobuf:"varint,5,opt,name=retry_after_seconds,json=retryAfterSeconds,proto3" json:"retry_after_seconds,omitempty"`
+	// How long the platform took, in milliseconds
+	DurationMs int32 `protobuf:"varint,6,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	// True when this platform's results came from a cache (the semantic
+	// cache or the local result index) rather than a live upstream call
+	ServedFromCache bool `protobuf:"varint,7,opt,name=served_from_cache,json=servedFromCache,proto3" json:"served_from_cache,omitempty"`
+	// How long the cached results had been cached, when served_from_cache
+	// and known
+	CacheAgeMs int64 `protobuf:"varint,8,opt,name=cache_age_ms,json=cacheAgeMs,proto3" json:"cache_age_ms,omitempty"`
+	// When the platform's results were fetched from the upstream (Unix
+	// seconds, UTC); 0 when there are none or the time is unknown
+	DataAsOf      int64 `protobuf:"varint,9,opt,name=data_as_of,json=dataAsOf,proto3" json:"data_as_of,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlatformStatus) Reset() {
+	*x = PlatformStatus{}
+	mi := &file_proto_search_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlatformStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlatformStatus) ProtoMessage() {}
+
+func (x *PlatformStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_search_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlatformStatus.ProtoReflect.Descriptor instead.
+func (*PlatformStatus) Descriptor() ([]byte, []int) {
+	return file_proto_search_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *PlatformStatus) GetPlatform() string {
+	if x != nil {
+		return x.Platform
+	}
+	return ""
+}
+
+func (x *PlatformStatus) GetState() PlatformState {
+	if x != nil {
+		return x.State
+	}
+	return PlatformState_PLATFORM_STATE_UNSPECIFIED
+}
+
+func (x *PlatformStatus) GetErrorCode() string {
+	if x != nil {
+		return x.ErrorCode
+	}
+	return ""
+}
+
+func (x *PlatformStatus) GetHttpStatus() int32 {
+	if x != nil {
+		return x.HttpStatus
+	}
+	return 0
+}
+
+func (x *PlatformStatus) GetRetryAfterSeconds() int32 {
+	if x != nil {
+		return x.RetryAfterSeconds
+	}
+	return 0
+}
+
+func (x *PlatformStatus) GetDurationMs() int32 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+func (x *PlatformStatus) GetServedFromCache() bool {
+	if x != nil {
+		return x.ServedFromCache
+	}
+	return false
+}
+
+func (x *PlatformStatus) GetCacheAgeMs() int64 {
+	if x != nil {
+		return x.CacheAgeMs
+	}
+	return 0
+}
+
+func (x *PlatformStatus) GetDataAsOf() int64 {
+	if x != nil {
+		return x.DataAsOf
+	}
+	return 0
+}
+
+// Result represents a single search result from any platform
+type Result struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Platform source: "github", "stackoverflow", "reddit"
+	Platform string `protobuf:"bytes,1,opt,name=platform,proto3" json:"platform,omitempty"`
+	// Result title
+	Title string `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	// Short snippet/description, at most SearchRequest.max_snippet_length
+	// characters of text
+	Snippet string `protobuf:"bytes,3,opt,name=snippet,proto3" json:"snippet,omitempty"`
+	// Direct URL to the resource
+	Url string `protobuf:"bytes,4,opt,name=url,proto3" json:"url,omitempty"`
+	// Unix timestamp (seconds since epoch, UTC); same as created_at
+	Timestamp int64 `protobuf:"varint,5,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// Platform-specific metadata (stars, votes, comments, etc.) as strings.
+	// Kept for older clients; platform_details has the same facts typed
+	Metadata map[string]string `protobuf:"bytes,6,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// When the resource was created (Unix seconds, UTC)
+	CreatedAt int64 `protobuf:"varint,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	// When the resource last changed (Unix seconds, UTC); equals created_at
+	// when the platform reports no later activity
+	UpdatedAt int64 `protobuf:"varint,8,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	// Whole days between created_at and the time of the response
+	AgeDays int32 `protobuf:"varint,9,opt,name=age_days,json=ageDays,proto3" json:"age_days,omitempty"`
+	// Preview image for result cards (Reddit preview, GitHub owner avatar);
+	// empty when the platform has none
+	ThumbnailUrl string `protobuf:"bytes,10,opt,name=thumbnail_url,json=thumbnailUrl,proto3" json:"thumbnail_url,omitempty"`
+	// Who created the resource; unset when the platform doesn't say
+	Author *Author `protobuf:"bytes,11,opt,name=author,proto3" json:"author,omitempty"`
+	// Kind of content the result points to
+	ResultType ResultType `protobuf:"varint,12,opt,name=result_type,json=resultType,proto3,enum=search.ResultType" json:"result_type,omitempty"`
+	// Short summary of a long snippet, when the request asked for one and it
+	// was ready within the summarization timeout; empty otherwise
+	Summary string `protobuf:"bytes,13,opt,name=summary,proto3" json:"summary,omitempty"`
+	// Typed platform-specific metadata; unset for platforms without a
+	// message of their own and for mock results
+	//
+	// Types that are valid to be assigned to PlatformDetails:
+	//
+	//	*Result_Github
+	//	*Result_Stackoverflow
+	//	*Result_Reddit
+	//	*Result_Dockerhub
+	PlatformDetails isResult_PlatformDetails `protobuf_oneof:"platform_details"`
+	// Popularity (stars, votes, points, reactions) scaled to 0–1 against the
+	// other results from the same platform in this response, so platforms
+	// can be compared; 0 when the result carries no popularity
+	NormalizedScore float64 `protobuf:"fixed64,18,opt,name=normalized_score,json=normalizedScore,proto3" json:"normalized_score,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Result) Reset() {
+	*x = Result{}
+	mi := &file_proto_search_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Result) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Result) ProtoMessage() {}
+
+func (x *Result) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_search_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Result.ProtoReflect.Descriptor instead.
+func (*Result) Descriptor() ([]byte, []int) {
+	return file_proto_search_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Result) GetPlatform() string {
+	if x != nil {
+		return x.Platform
+	}
+	return ""
+}
+
+func (x *Result) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Result) GetSnippet() string {
+	if x != nil {
+		return x.Snippet
+	}
+	return ""
+}
+
+func (x *Result) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *Result) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *Result) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *Result) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *Result) GetUpdatedAt() int64 {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return 0
+}
+
+func (x *Result) GetAgeDays() int32 {
+	if x != nil {
+		return x.AgeDays
+	}
+	return 0
+}
+
+func (x *Result) GetThumbnailUrl() string {
+	if x != nil {
+		return x.ThumbnailUrl
+	}
+	return ""
+}
+
+func (x *Result) GetAuthor() *Author {
+	if x != nil {
+		return x.Author
+	}
+	return nil
+}
+
+func (x *Result) GetResultType() ResultType {
+	if x != nil {
+		return x.ResultType
+	}
+	return ResultType_RESULT_TYPE_UNSPECIFIED
+}
+
+func (x *Result) GetSummary() string {
+	if x != nil {
+		return x.Summary
+	}
+	return ""
+}
+
+func (x *Result) GetPlatformDetails() isResult_PlatformDetails {
+	if x != nil {
+		return x.PlatformDetails
+	}
+	return nil
+}
+
+func (x *Result) GetGithub() *GitHubMeta {
+	if x != nil {
+		if x, ok := x.PlatformDetails.(*Result_Github); ok {
+			return x.Github
+		}
+	}
+	return nil
+}
+
+func (x *Result) GetStackoverflow() *StackOverflowMeta {
+	if x != nil {
+		if x, ok := x.PlatformDetails.(*Result_Stackoverflow); ok {
+			return x.Stackoverflow
+		}
+	}
+	return nil
+}
+
+func (x *Result) GetReddit() *RedditMeta {
+	if x != nil {
+		if x, ok := x.PlatformDetails.(*Result_Reddit); ok {
+			return x.Reddit
+		}
+	}
+	return nil
+}
+
+func (x *Result) GetDockerhub() *DockerHubMeta {
+	if x != nil {
+		if x, ok := x.PlatformDetails.(*Result_Dockerhub); ok {
+			return x.Dockerhub
+		}
+	}
+	return nil
+}
+
+func (x *Result) GetNormalizedScore() float64 {
+	if x != nil {
+		return x.NormalizedScore
+	}
+	return 0
+}
+
+type isResult_PlatformDetails interface {
+	isResult_PlatformDetails()
+}
+
+type Result_Github struct {
+	Github *GitHubMeta `protobuf:"bytes,14,opt,name=github,proto3,oneof"`
+}
+
+type Result_Stackoverflow struct {
+	Stackoverflow *StackOverflowMeta `protobuf:"bytes,15,opt,name=stackoverflow,proto3,oneof"`
+}
+
+type Result_Reddit struct {
+	Reddit *RedditMeta `protobuf:"bytes,16,opt,name=reddit,proto3,oneof"`
+}
+
+type Result_Dockerhub struct {
+	Dockerhub *DockerHubMeta `protobuf:"bytes,17,opt,name=dockerhub,proto3,oneof"`
+}
+
+func (*Result_Github) isResult_PlatformDetails() {}
+
+func (*Result_Stackoverflow) isResult_PlatformDetails() {}
+
+func (*Result_Reddit) isResult_PlatformDetails() {}
+
+func (*Result_Dockerhub) isResult_PlatformDetails() {}
+
+// GitHubMeta describes a GitHub result. Which fields are set depends on
+// result_type: repositories have stars, forks, language and open_issues;
+// code has repository, path and sha; issues and discussions have
+// repository, number and comments, plus the fields noted below
+type GitHubMeta struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Stars      int32                  `protobuf:"varint,1,opt,name=stars,proto3" json:"stars,omitempty"`
+	Forks      int32                  `protobuf:"varint,2,opt,name=forks,proto3" json:"forks,omitempty"`
+	Language   string                 `protobuf:"bytes,3,opt,name=language,proto3" json:"language,omitempty"`
+	OpenIssues int32                  `protobuf:"varint,4,opt,name=open_issues,json=openIssues,proto3" json:"open_issues,omitempty"`
+	// Repository as "owner/name"; empty for repositories themselves
+	Repository string `protobuf:"bytes,5,opt,name=repository,proto3" json:"repository,omitempty"`
+	Path       string `protobuf:"bytes,6,opt,name=path,proto3" json:"path,omitempty"`
+	Sha        string `protobuf:"bytes,7,opt,name=sha,proto3" json:"sha,omitempty"`
+	Number     int32  `protobuf:"varint,8,opt,name=number,proto3" json:"number,omitempty"`
+	Comments   int32  `protobuf:"varint,9,opt,name=comments,proto3" json:"comments,omitempty"`
+	// Issues only: "open" or "closed", labels, and whether it is a pull
+	// request
+	State       string   `protobuf:"bytes,10,opt,name=state,proto3" json:"state,omitempty"`
+	Labels      []string `protobuf:"bytes,11,rep,name=labels,proto3" json:"labels,omitempty"`
+	PullRequest bool     `protobuf:"varint,12,opt,name=pull_request,json=pullRequest,proto3" json:"pull_request,omitempty"`
+	// Discussions only
+	Upvotes       int32  `protobuf:"varint,13,opt,name=upvotes,proto3" json:"upvotes,omitempty"`
+	IsAnswered    bool   `protobuf:"varint,14,opt,name=is_answered,json=isAnswered,proto3" json:"is_answered,omitempty"`
+	Category      string `protobuf:"bytes,15,opt,name=category,proto3" json:"category,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GitHubMeta) Reset() {
+	*x = GitHubMeta{}
+	mi := &file_proto_search_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GitHubMeta) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GitHubMeta) ProtoMessage() {}
+
+func (x *GitHubMeta) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_search_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GitHubMeta.ProtoReflect.Descriptor instead.
+func (*GitHubMeta) Descriptor() ([]byte, []int) {
+	return file_proto_search_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GitHubMeta) GetStars() int32 {
+	if x != nil {
+		return x.Stars
+	}
+	return 0
+}
+
+func (x *GitHubMeta) GetForks() int32 {
+	if x != nil {
+		return x.Forks
+	}
+	return 0
+}
+
+func (x *GitHubMeta) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *GitHubMeta) GetOpenIssues() int32 {
+	if x != nil {
+		return x.OpenIssues
+	}
+	return 0
+}
+
+func (x *GitHubMeta) GetRepository() string {
+	if x != nil {
+		return x.Repository
+	}
+	return ""
+}
+
+func (x *GitHubMeta) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *GitHubMeta) GetSha() string {
+	if x != nil {
+		return x.Sha
+	}
+	return ""
+}
+
+func (x *GitHubMeta) GetNumber() int32 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
+}
+
+func (x *GitHubMeta) GetComments() int32 {
+	if x != nil {
+		return x.Comments
+	}
+	return 0
+}
+
+func (x *GitHubMeta) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *GitHubMeta) GetLabels() []string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *GitHubMeta) GetPullRequest() bool {
+	if x != nil {
+		return x.PullRequest
+	}
+	return false
+}
+
+func (x *GitHubMeta) GetUpvotes() int32 {
+	if x != nil {
+		return x.Upvotes
+	}
+	return 0
+}
+
+func (x *GitHubMeta) GetIsAnswered() bool {
+	if x != nil {
+		return x.IsAnswered
+	}
+	return false
+}
+
+func (x *GitHubMeta) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+// StackOverflowMeta describes a Stack Overflow question
+type StackOverflowMeta struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Score         int32                  `protobuf:"varint,1,opt,name=score,proto3" json:"score,omitempty"`
+	AnswerCount   int32                  `protobuf:"varint,2,opt,name=answer_count,json=answerCount,proto3" json:"answer_count,omitempty"`
+	ViewCount     int32                  `protobuf:"varint,3,opt,name=view_count,json=viewCount,proto3" json:"view_count,omitempty"`
+	IsAnswered    bool                   `protobuf:"varint,4,opt,name=is_answered,json=isAnswered,proto3" json:"is_answered,omitempty"`
+	Tags          []string               `protobuf:"bytes,5,rep,name=tags,proto3" json:"tags,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StackOverflowMeta) Reset() {
+	*x = StackOverflowMeta{}
+	mi := &file_proto_search_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StackOverflowMeta) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StackOverflowMeta) ProtoMessage() {}
+
+func (x *StackOverflowMeta) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_search_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StackOverflowMeta.ProtoReflect.Descriptor instead.
+func (*StackOverflowMeta) Descriptor() ([]byte, []int) {
+	return file_proto_search_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *StackOverflowMeta) GetScore() int32 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *StackOverflowMeta) GetAnswerCount() int32 {
+	if x != nil {
+		return x.AnswerCount
+	}
+	return 0
+}
+
+func (x *StackOverflowMeta) GetViewCount() int32 {
+	if x != nil {
+		return x.ViewCount
+	}
+	return 0
+}
+
+func (x *StackOverflowMeta) GetIsAnswered() bool {
+	if x != nil {
+		return x.IsAnswered
+	}
+	return false
+}
+
+func (x *StackOverflowMeta) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+// RedditMeta describes a Reddit post
+type RedditMeta struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Score       int32                  `protobuf:"varint,1,opt,name=score,proto3" json:"score,omitempty"`
+	NumComments int32                  `protobuf:"varint,2,opt,name=num_comments,json=numComments,proto3" json:"num_comments,omitempty"`
+	Subreddit   string                 `protobuf:"bytes,3,opt,name=subreddit,proto3" json:"subreddit,omitempty"`
+	UpvoteRatio float32                `protobuf:"fixed32,4,opt,name=upvote_ratio,json=upvoteRatio,proto3" json:"upvote_ratio,omitempty"`
+	// For link posts, the linked site and page; empty for text posts
+	LinkDomain    string `protobuf:"bytes,5,opt,name=link_domain,json=linkDomain,proto3" json:"link_domain,omitempty"`
+	LinkUrl       string `protobuf:"bytes,6,opt,name=link_url,json=linkUrl,proto3" json:"link_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RedditMeta) Reset() {
+	*x = RedditMeta{}
+	mi := &file_proto_search_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RedditMeta) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RedditMeta) ProtoMessage() {}
+
+func (x *RedditMeta) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_search_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RedditMeta.ProtoReflect.Descriptor instead.
+func (*RedditMeta) Descriptor() ([]byte, []int) {
+	return file_proto_search_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *RedditMeta) GetScore() int32 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *RedditMeta) GetNumComments() int32 {
+	if x != nil {
+		return x.NumComments
+	}
+	return 0
+}
+
+func (x *RedditMeta) GetSubreddit() string {
+	if x != nil {
+		return x.Subreddit
+	}
+	return ""
+}
+
+func (x *RedditMeta) GetUpvoteRatio() float32 {
+	if x != nil {
+		return x.UpvoteRatio
+	}
+	return 0
+}
+
+func (x *RedditMeta) GetLinkDomain() string {
+	if x != nil {
+		return x.LinkDomain
+	}
+	return ""
+}
+
+func (x *RedditMeta) GetLinkUrl() string {
+	if x != nil {
+		return x.LinkUrl
+	}
+	return ""
+}
+
+// DockerHubMeta describes a Docker Hub image repository
+type DockerHubMeta struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Stars int32                  `protobuf:"varint,1,opt,name=stars,proto3" json:"stars,omitempty"`
+	// Pulls can exceed 32 bits for popular images
+	Pulls int64 `protobuf:"varint,2,opt,name=pulls,proto3" json:"pulls,omitempty"`
+	// Docker Official Images are curated by Docker
+	Official bool `protobuf:"varint,3,opt,name=official,proto3" json:"official,omitempty"`
+	// Verified Publisher images come from a commercial publisher Docker
+	// vouches for
+	Verified bool `protobuf:"varint,4,opt,name=verified,proto3" json:"verified,omitempty"`
+	// The account publishing the image; "library" for official images
+	Namespace     string `protobuf:"bytes,5,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DockerHubMeta) Reset() {
+	*x = DockerHubMeta{}
+	mi := &file_proto_search_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DockerHubMeta) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DockerHubMeta) ProtoMessage() {}
+
+func (x *DockerHubMeta) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_search_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DockerHubMeta.ProtoReflect.Descriptor instead.
+func (*DockerHubMeta) Descriptor() ([]byte, []int) {
+	return file_proto_search_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *DockerHubMeta) GetStars() int32 {
+	if x != nil {
+		return x.Stars
+	}
+	return 0
+}
+
+func (x *DockerHubMeta) GetPulls() int64 {
+	if x != nil {
+		return x.Pulls
+	}
+	return 0
+}
+
+func (x *DockerHubMeta) GetOfficial() bool {
+	if x != nil {
+		return x.Official
+	}
+	return false
+}
+
+func (x *DockerHubMeta) GetVerified() bool {
+	if x != nil {
+		return x.Verified
+	}
+	return false
+}
+
+func (x *DockerHubMeta) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+// Author identifies the person or organization behind a result
+type Author struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Display name
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Platform handle, e.g. the GitHub login or Reddit username
+	Handle string `protobuf:"bytes,2,opt,name=handle,proto3" json:"handle,omitempty"`
+	// Profile page on the platform
+	ProfileUrl    string `protobuf:"bytes,3,opt,name=profile_url,json=profileUrl,proto3" json:"profile_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Author) Reset() {
+	*x = Author{}
+	mi := &file_proto_search_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Author) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Author) ProtoMessage() {}
+
+func (x *Author) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_search_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Author.ProtoReflect.Descriptor instead.
+func (*Author) Descriptor() ([]byte, []int) {
+	return file_proto_search_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Author) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Author) GetHandle() string {
+	if x != nil {
+		return x.Handle
+	}
+	return ""
+}
+
+func (x *Author) GetProfileUrl() string {
+	if x != nil {
+		return x.ProfileUrl
+	}
+	return ""
+}
+
+// SearchStreamResponse is one message of a SearchStream
+type SearchStreamResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Platform whose results these are; empty on the final message
+	Platform string `protobuf:"bytes,1,opt,name=platform,proto3" json:"platform,omitempty"`
+	// The platform's results, already filtered, deduplicated and counted
+	// against max_results. Platforms that fail or have nothing left after
+	// filtering send no message of their own
+	Results []*Result `protobuf:"bytes,2,rep,name=results,proto3" json:"results,omitempty"`
+	// How long the platform took, in milliseconds; 0 when its results came
+	// from the local index
+	DurationMs int32 `protobuf:"varint,3,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	// Set on the final message only: the complete response, as
+	// FederatedSearch would have returned it. Its results are in their final
+	// order, which may differ from the order they were streamed in. Responses
+	// served from the cache or a page_token arrive as this message alone
+	Response      *SearchResponse `protobuf:"bytes,4,opt,name=response,proto3" json:"response,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchStreamResponse) Reset() {
+	*x = SearchStreamResponse{}
+	mi := &file_proto_search_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchStreamResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchStreamResponse) ProtoMessage() {}
+
+func (x *SearchStreamResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_search_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchStreamResponse.ProtoReflect.Descriptor instead.
+func (*SearchStreamResponse) Descriptor() ([]byte, []int) {
+	return file_proto_search_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *SearchStreamResponse) GetPlatform() string {
+	if x != nil {
+		return x.Platform
+	}
+	return ""
+}
+
+func (x *SearchStreamResponse) GetResults() []*Result {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *SearchStreamResponse) GetDurationMs() int32 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+func (x *SearchStreamResponse) GetResponse() *SearchResponse {
+	if x != nil {
+		return x.Response
+	}
+	return nil
+}
+
+// ResponseMetadata provides information about the search execution
+type ResponseMetadata struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Total response time in milliseconds
+	ResponseTimeMs int32 `protobuf:"varint,1,opt,name=response_time_ms,json=responseTimeMs,proto3" json:"response_time_ms,omitempty"`
+	// Number of platforms queried
+	PlatformsQueried int32 `protobuf:"varint,2,opt,name=platforms_queried,json=platformsQueried,proto3" json:"platforms_queried,omitempty"`
+	// Results dropped because the per-request result cap was reached
+	ResultsDropped int32 `protobuf:"varint,3,opt,name=results_dropped,json=resultsDropped,proto3" json:"results_dropped,omitempty"`
+	// Results whose snippet was cut to the per-result byte cap
+	SnippetsTruncated int32 `protobuf:"varint,4,opt,name=snippets_truncated,json=snippetsTruncated,proto3" json:"snippets_truncated,omitempty"`
+	// Results that had metadata entries dropped to fit the per-result byte cap
+	MetadataTruncated int32 `protobuf:"varint,5,opt,name=metadata_truncated,json=metadataTruncated,proto3" json:"metadata_truncated,omitempty"`
+	// Platforms that failed upstream and were answered from the local result index
+	PlatformsFromIndex []string `protobuf:"bytes,6,rep,name=platforms_from_index,json=platformsFromIndex,proto3" json:"platforms_from_index,omitempty"`
+	// True when the response was served from the semantic cache for a
+	// similar, but not identical, earlier query
+	Approximate bool `protobuf:"varint,7,opt,name=approximate,proto3" json:"approximate,omitempty"`
+	// The cached query that answered this request (set when approximate)
+	ApproximateQuery string `protobuf:"bytes,8,opt,name=approximate_query,json=approximateQuery,proto3" json:"approximate_query,omitempty"`
+	// Cosine similarity between this query and approximate_query
+	Similarity float32 `protobuf:"fixed32,9,opt,name=similarity,proto3" json:"similarity,omitempty"`
+	// Platforms whose upstream limits query length and were sent a shortened
+	// query instead, mapped to the query they received
+	ShortenedQueries map[string]string `protobuf:"bytes,10,rep,name=shortened_queries,json=shortenedQueries,proto3" json:"shortened_queries,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// "People also searched" queries: this query refined by tags common in
+	// the results, then related recent queries
+	RelatedQueries []string `protobuf:"bytes,11,rep,name=related_queries,json=relatedQueries,proto3" json:"related_queries,omitempty"`
+	// True when the response was served from the response cache for an
+	// identical earlier request
+	CacheHit bool `protobuf:"varint,12,opt,name=cache_hit,json=cacheHit,proto3" json:"cache_hit,omitempty"`
+	// The call's request ID: the caller's x-request-id metadata, or one the
+	// server generated. Also sent back in the x-request-id response header.
+	RequestId     string `protobuf:"bytes,13,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResponseMetadata) Reset() {
+	*x = ResponseMetadata{}
+	mi := &file_proto_search_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResponseMetadata) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResponseMetadata) ProtoMessage() {}
+
+func (x *ResponseMetadata) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_search_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResponseMetadata.ProtoReflect.Descriptor instead.
+func (*ResponseMetadata) Descriptor() ([]byte, []int) {
+	return file_proto_search_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ResponseMetadata) GetResponseTimeMs() int32 {
+	if x != nil {
+		return x.ResponseTimeMs
+	}
+	return 0
+}
+
+func (x *ResponseMetadata) GetPlatformsQueried() int32 {
+	if x != nil {
+		return x.PlatformsQueried
+	}
+	return 0
+}
+
+func (x *ResponseMetadata) GetResultsDropped() int32 {
+	if x != nil {
+		return x.ResultsDropped
+	}
+	return 0
+}
+
+func (x *ResponseMetadata) GetSnippetsTruncated() int32 {
+	if x != nil {
+		return x.SnippetsTruncated
+	}
+	return 0
+}
+
+func (x *ResponseMetadata) GetMetadataTruncated() int32 {
+	if x != nil {
+		return x.MetadataTruncated
+	}
+	return 0
+}
+
+func (x *ResponseMetadata) GetPlatformsFromIndex() []string {
+	if x != nil {
+		return x.PlatformsFromIndex
+	}
+	return nil
+}
+
+func (x *ResponseMetadata) GetApproximate() bool {
+	if x != nil {
+		return x.Approximate
+	}
+	return false
+}
+
+func (x *ResponseMetadata) GetApproximateQuery() string {
+	if x != nil {
+		return x.ApproximateQuery
+	}
+	return ""
+}
+
+func (x *ResponseMetadata) GetSimilarity() float32 {
+	if x != nil {
+		return x.Similarity
+	}
+	return 0
+}
+
+func (x *ResponseMetadata) GetShortenedQueries() map[string]string {
+	if x != nil {
+		return x.ShortenedQueries
+	}
+	return nil
+}
+
+func (x *ResponseMetadata) GetRelatedQueries() []string {
+	if x != nil {
+		return x.RelatedQueries
+	}
+	return nil
+}
+
+func (x *ResponseMetadata) GetCacheHit() bool {
+	if x != nil {
+		return x.CacheHit
+	}
+	return false
+}
+
+func (x *ResponseMetadata) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+// HealthCheckResponse indicates service health
+type HealthCheckResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Health status: "healthy", "degraded", "unhealthy", or "draining" once
+	// shutdown has started and new searches are refused
+	Status string `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	// Service version: the release tag, module version, or "dev"
+	Version string `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	// Timestamp of health check
+	Timestamp int64 `protobuf:"varint,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// VCS revision the server was built from, or "unknown"
+	Commit string `protobuf:"bytes,4,opt,name=commit,proto3" json:"commit,omitempty"`
+	// When the server was built (RFC 3339), or "unknown"
+	BuildDate     string `protobuf:"bytes,5,opt,name=build_date,json=buildDate,proto3" json:"build_date,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HealthCheckResponse) Reset() {
+	*x = HealthCheckResponse{}
+	mi := &file_proto_search_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HealthCheckResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthCheckResponse) ProtoMessage() {}
+
+func (x *HealthCheckResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_search_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthCheckResponse.ProtoReflect.Descriptor instead.
+func (*HealthCheckResponse) Descriptor() ([]byte, []int) {
+	return file_proto_search_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *HealthCheckResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *HealthCheckResponse) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *HealthCheckResponse) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *HealthCheckResponse) GetCommit() string {
+	if x != nil {
+		return x.Commit
+	}
+	return ""
+}
+
+func (x *HealthCheckResponse) GetBuildDate() string {
+	if x != nil {
+		return x.BuildDate
+	}
+	return ""
+}
+
+// AnswerResponse is a synthesized answer together with the search it is
+// based on
+type AnswerResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Answer in Markdown with inline [n] citation markers; empty when no
+	// answer could be produced in time, in which case search still holds the
+	// results
+	Answer string `protobuf:"bytes,1,opt,name=answer,proto3" json:"answer,omitempty"`
+	// Sources cited in the answer, in order of first citation
+	Citations []*Citation `protobuf:"bytes,2,rep,name=citations,proto3" json:"citations,omitempty"`
+	// The underlying federated search response
+	Search        *SearchResponse `protobuf:"bytes,3,opt,name=search,proto3" json:"search,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AnswerResponse) Reset() {
+	*x = AnswerResponse{}
+	mi := &file_proto_search_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnswerResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnswerResponse) ProtoMessage() {}
+
+func (x *AnswerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_search_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnswerResponse.ProtoReflect.Descriptor instead.
+func (*AnswerResponse) Descriptor() ([]byte, []int) {
+	return file_proto_search_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *AnswerResponse) GetAnswer() string {
+	if x != nil {
+		return x.Answer
+	}
+	return ""
+}
+
+func (x *AnswerResponse) GetCitations() []*Citation {
+	if x != nil {
+		return x.Citations
+	}
+	return nil
+}
+
+func (x *AnswerResponse) GetSearch() *SearchResponse {
+	if x != nil {
+		return x.Search
+	}
+	return nil
+}
+
+// MultiSearchResponse holds the outcome of every search in the batch
+type MultiSearchResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// One entry per search, in the order they were requested
+	Results       []*MultiSearchResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MultiSearchResponse) Reset() {
+	*x = MultiSearchResponse{}
+	mi := &file_proto_search_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MultiSearchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MultiSearchResponse) ProtoMessage() {}
+
+func (x *MultiSearchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_search_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MultiSearchResponse.ProtoReflect.Descriptor instead.
+func (*MultiSearchResponse) Descriptor() ([]byte, []int) {
+	return file_proto_search_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *MultiSearchResponse) GetResults() []*MultiSearchResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+// MultiSearchResult is the outcome of one search in a batch: either a
+// response or the error FederatedSearch would have returned
+type MultiSearchResult struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The search response; unset when the search failed
+	Response *SearchResponse `protobuf:"bytes,1,opt,name=response,proto3" json:"response,omitempty"`
+	// gRPC status code of the failure, e.g. 3 (INVALID_ARGUMENT); 0 (OK)
+	// when the search succeeded
+	ErrorCode int32 `protobuf:"varint,2,opt,name=error_code,json=errorCode,proto3" json:"error_code,omitempty"`
+	// Description of the failure
+	ErrorMessage  string `protobuf:"bytes,3,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MultiSearchResult) Reset() {
+	*x = MultiSearchResult{}
+	mi := &file_proto_search_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MultiSearchResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MultiSearchResult) ProtoMessage() {}
+
+func (x *MultiSearchResult) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_search_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MultiSearchResult.ProtoReflect.Descriptor instead.
+func (*MultiSearchResult) Descriptor() ([]byte, []int) {
+	return file_proto_search_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *MultiSearchResult) GetResponse() *SearchResponse {
+	if x != nil {
+		return x.Response
+	}
+	return nil
+}
+
+func (x *MultiSearchResult) GetErrorCode() int32 {
+	if x != nil {
+		return x.ErrorCode
+	}
+	return 0
+}
+
+func (x *MultiSearchResult) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
+// ResultDetailsResponse is the full content behind a result
+type ResultDetailsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Canonical platform name
+	Platform string `protobuf:"bytes,1,opt,name=platform,proto3" json:"platform,omitempty"`
+	Url      string `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	Title    string `protobuf:"bytes,3,opt,name=title,proto3" json:"title,omitempty"`
+	// The question, README or post, in content_format
+	Content string `protobuf:"bytes,4,opt,name=content,proto3" json:"content,omitempty"`
+	// "markdown" for GitHub and Reddit, "html" for Stack Exchange sites,
+	// which is how the platforms store their content
+	ContentFormat string `protobuf:"bytes,5,opt,name=content_format,json=contentFormat,proto3" json:"content_format,omitempty"`
+	// Who created the result; unset when the platform doesn't say
+	Author *Author `protobuf:"bytes,6,opt,name=author,proto3" json:"author,omitempty"`
+	// When the result was created (Unix seconds, UTC); 0 when unknown
+	CreatedAt int64 `protobuf:"varint,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	// Answers (Stack Exchange) or top-level comments (Reddit), best first;
+	// an accepted answer always comes first. Empty for GitHub
+	Replies []*Reply `protobuf:"bytes,8,rep,name=replies,proto3" json:"replies,omitempty"`
+	// True when content or a reply was cut to max_content_length
+	Truncated     bool `protobuf:"varint,9,opt,name=truncated,proto3" json:"truncated,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResultDetailsResponse) Reset() {
+	*x = ResultDetailsResponse{}
+	mi := &file_proto_search_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResultDetailsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResultDetailsResponse) ProtoMessage() {}
+
+func (x *ResultDetailsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_search_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResultDetailsResponse.ProtoReflect.Descriptor instead.
+func (*ResultDetailsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_search_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ResultDetailsResponse) GetPlatform() string {
+	if x != nil {
+		return x.Platform
+	}
+	return ""
+}
+
+func (x *ResultDetailsResponse) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *ResultDetailsResponse) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *ResultDetailsResponse) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *ResultDetailsResponse) GetContentFormat() string {
 	if x != nil {
-		return x.Query
+		return x.ContentFormat
 	}
 	return ""
 }
 
-func (x *SearchRequest) GetMaxResults() int32 {
+func (x *ResultDetailsResponse) GetAuthor() *Author {
 	if x != nil {
-		return x.MaxResults
+		return x.Author
+	}
+	return nil
+}
+
+func (x *ResultDetailsResponse) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
 	}
 	return 0
 }
 
-func (x *SearchRequest) GetPlatforms() []string {
+func (x *ResultDetailsResponse) GetReplies() []*Reply {
 	if x != nil {
-		return x.Platforms
+		return x.Replies
 	}
 	return nil
 }
 
-// HealthCheckRequest for service health monitoring
-type HealthCheckRequest struct {
+func (x *ResultDetailsResponse) GetTruncated() bool {
+	if x != nil {
+		return x.Truncated
+	}
+	return false
+}
+
+// Reply is an answer or comment on a result
+type Reply struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// Optional: service name to check
-	Service       string `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
+	// In the response's content_format
+	Content string  `protobuf:"bytes,1,opt,name=content,proto3" json:"content,omitempty"`
+	Author  *Author `protobuf:"bytes,2,opt,name=author,proto3" json:"author,omitempty"`
+	// Votes or points
+	Score int32 `protobuf:"varint,3,opt,name=score,proto3" json:"score,omitempty"`
+	// Set on the answer the asker accepted
+	Accepted bool `protobuf:"varint,4,opt,name=accepted,proto3" json:"accepted,omitempty"`
+	// Unix seconds, UTC
+	CreatedAt int64 `protobuf:"varint,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	// Link to the reply itself
+	Url           string `protobuf:"bytes,6,opt,name=url,proto3" json:"url,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *HealthCheckRequest) Reset() {
-	*x = HealthCheckRequest{}
-	mi := &file_proto_search_proto_msgTypes[1]
+func (x *Reply) Reset() {
+	*x = Reply{}
+	mi := &file_proto_search_proto_msgTypes[21]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *HealthCheckRequest) String() string {
+func (x *Reply) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HealthCheckRequest) ProtoMessage() {}
+func (*Reply) ProtoMessage() {}
 
-func (x *HealthCheckRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_search_proto_msgTypes[1]
+func (x *Reply) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_search_proto_msgTypes[21]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -127,52 +2516,81 @@ func (x *HealthCheckRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HealthCheckRequest.ProtoReflect.Descriptor instead.
-func (*HealthCheckRequest) Descriptor() ([]byte, []int) {
-	return file_proto_search_proto_rawDescGZIP(), []int{1}
+// Deprecated: Use Reply.ProtoReflect.Descriptor instead.
+func (*Reply) Descriptor() ([]byte, []int) {
+	return file_proto_search_proto_rawDescGZIP(), []int{21}
 }
 
-func (x *HealthCheckRequest) GetService() string {
+func (x *Reply) GetContent() string {
 	if x != nil {
-		return x.Service
+		return x.Content
 	}
 	return ""
 }
 
-// SearchResponse contains the aggregated search results
-type SearchResponse struct {
+func (x *Reply) GetAuthor() *Author {
+	if x != nil {
+		return x.Author
+	}
+	return nil
+}
+
+func (x *Reply) GetScore() int32 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *Reply) GetAccepted() bool {
+	if x != nil {
+		return x.Accepted
+	}
+	return false
+}
+
+func (x *Reply) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *Reply) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+// Citation ties an [n] marker in the answer to a search result
+type Citation struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// List of search results from all platforms
-	Results []*Result `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
-	// Total number of results returned
-	TotalCount int32 `protobuf:"varint,2,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
-	// Platforms that successfully returned results
-	PlatformsSuccess []string `protobuf:"bytes,3,rep,name=platforms_success,json=platformsSuccess,proto3" json:"platforms_success,omitempty"`
-	// Platforms that timed out (exceeded 400ms)
-	PlatformsTimeout []string `protobuf:"bytes,4,rep,name=platforms_timeout,json=platformsTimeout,proto3" json:"platforms_timeout,omitempty"`
-	// Platforms that returned errors
-	PlatformsError []string `protobuf:"bytes,5,rep,name=platforms_error,json=platformsError,proto3" json:"platforms_error,omitempty"`
-	// Response metadata
-	Metadata      *ResponseMetadata `protobuf:"bytes,6,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	// The n in the [n] marker
+	Index int32 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	// Cited result, also present in AnswerResponse.search
+	Url           string `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	Title         string `protobuf:"bytes,3,opt,name=title,proto3" json:"title,omitempty"`
+	Platform      string `protobuf:"bytes,4,opt,name=platform,proto3" json:"platform,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SearchResponse) Reset() {
-	*x = SearchResponse{}
-	mi := &file_proto_search_proto_msgTypes[2]
+func (x *Citation) Reset() {
+	*x = Citation{}
+	mi := &file_proto_search_proto_msgTypes[22]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SearchResponse) String() string {
+func (x *Citation) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SearchResponse) ProtoMessage() {}
+func (*Citation) ProtoMessage() {}
 
-func (x *SearchResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_search_proto_msgTypes[2]
+func (x *Citation) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_search_proto_msgTypes[22]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -183,87 +2601,138 @@ func (x *SearchResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SearchResponse.ProtoReflect.Descriptor instead.
-func (*SearchResponse) Descriptor() ([]byte, []int) {
-	return file_proto_search_proto_rawDescGZIP(), []int{2}
+// Deprecated: Use Citation.ProtoReflect.Descriptor instead.
+func (*Citation) Descriptor() ([]byte, []int) {
+	return file_proto_search_proto_rawDescGZIP(), []int{22}
 }
 
-func (x *SearchResponse) GetResults() []*Result {
+func (x *Citation) GetIndex() int32 {
 	if x != nil {
-		return x.Results
+		return x.Index
 	}
-	return nil
+	return 0
 }
 
-func (x *SearchResponse) GetTotalCount() int32 {
+func (x *Citation) GetUrl() string {
 	if x != nil {
-		return x.TotalCount
+		return x.Url
 	}
-	return 0
+	return ""
 }
 
-func (x *SearchResponse) GetPlatformsSuccess() []string {
+func (x *Citation) GetTitle() string {
 	if x != nil {
-		return x.PlatformsSuccess
+		return x.Title
 	}
-	return nil
+	return ""
 }
 
-func (x *SearchResponse) GetPlatformsTimeout() []string {
+func (x *Citation) GetPlatform() string {
 	if x != nil {
-		return x.PlatformsTimeout
+		return x.Platform
 	}
-	return nil
+	return ""
 }
 
-func (x *SearchResponse) GetPlatformsError() []string {
+// ListPlatformsResponse lists the searchable platforms in name order
+type ListPlatformsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Platforms     []*PlatformInfo        `protobuf:"bytes,1,rep,name=platforms,proto3" json:"platforms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPlatformsResponse) Reset() {
+	*x = ListPlatformsResponse{}
+	mi := &file_proto_search_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPlatformsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPlatformsResponse) ProtoMessage() {}
+
+func (x *ListPlatformsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_search_proto_msgTypes[23]
 	if x != nil {
-		return x.PlatformsError
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return nil
+	return mi.MessageOf(x)
 }
 
-func (x *SearchResponse) GetMetadata() *ResponseMetadata {
+// Deprecated: Use ListPlatformsResponse.ProtoReflect.Descriptor instead.
+func (*ListPlatformsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_search_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ListPlatformsResponse) GetPlatforms() []*PlatformInfo {
 	if x != nil {
-		return x.Metadata
+		return x.Platforms
 	}
 	return nil
 }
 
-// Result represents a single search result from any platform
-type Result struct {
+// PlatformInfo is what a UI needs to render a platform without hardcoding
+// its assets
+type PlatformInfo struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// Platform source: "github", "stackoverflow", "reddit"
-	Platform string `protobuf:"bytes,1,opt,name=platform,proto3" json:"platform,omitempty"`
-	// Result title
-	Title string `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
-	// Short snippet/description (max 500 chars)
-	Snippet string `protobuf:"bytes,3,opt,name=snippet,proto3" json:"snippet,omitempty"`
-	// Direct URL to the resource
-	Url string `protobuf:"bytes,4,opt,name=url,proto3" json:"url,omitempty"`
-	// Unix timestamp (seconds since epoch)
-	Timestamp int64 `protobuf:"varint,5,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
-	// Platform-specific metadata (stars, votes, comments, etc.)
-	Metadata      map[string]string `protobuf:"bytes,6,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Name used in SearchRequest.platforms and Result.platform
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Human-readable name, e.g. "Stack Overflow"
+	DisplayName string `protobuf:"bytes,2,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"`
+	// Square icon suitable for a chip or badge
+	IconUrl string `protobuf:"bytes,3,opt,name=icon_url,json=iconUrl,proto3" json:"icon_url,omitempty"`
+	// Brand color as a #RRGGBB hex string
+	BrandColor string `protobuf:"bytes,4,opt,name=brand_color,json=brandColor,proto3" json:"brand_color,omitempty"`
+	// One-line description of what the platform contributes
+	Description string `protobuf:"bytes,5,opt,name=description,proto3" json:"description,omitempty"`
+	// Whether the server has an API key, token or OAuth client for the
+	// platform; without one it is searched anonymously, with lower rate limits
+	CredentialsConfigured bool `protobuf:"varint,6,opt,name=credentials_configured,json=credentialsConfigured,proto3" json:"credentials_configured,omitempty"`
+	// SearchRequest filters the platform honours, by field name
+	// ("min_stars", "answered_only", ...) or source rule prefix ("org:",
+	// "subreddit:", "domain:"). Other filters leave its results alone.
+	SupportedFilters []string `protobuf:"bytes,7,rep,name=supported_filters,json=supportedFilters,proto3" json:"supported_filters,omitempty"`
+	// State of the platform's circuit breaker
+	CircuitState CircuitState `protobuf:"varint,8,opt,name=circuit_state,json=circuitState,proto3,enum=search.CircuitState" json:"circuit_state,omitempty"`
+	// Results returned when SearchRequest.max_results is unset
+	DefaultMaxResults int32 `protobuf:"varint,9,opt,name=default_max_results,json=defaultMaxResults,proto3" json:"default_max_results,omitempty"`
+	// Query DSL fields the platform translates to its own syntax ("lang",
+	// "stars", "tag", "subreddit", "after", "before"); the others are dropped
+	// from its query
+	QueryTerms []string `protobuf:"bytes,10,rep,name=query_terms,json=queryTerms,proto3" json:"query_terms,omitempty"`
+	// The platform's own request quota as it last reported it; unset until
+	// it has, and for platforms that don't report one
+	Quota *UpstreamQuota `protobuf:"bytes,11,opt,name=quota,proto3" json:"quota,omitempty"`
+	// Set when an operator has taken the platform out of searches through
+	// AdminService.SetPlatformEnabled
+	Disabled      bool `protobuf:"varint,12,opt,name=disabled,proto3" json:"disabled,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Result) Reset() {
-	*x = Result{}
-	mi := &file_proto_search_proto_msgTypes[3]
+func (x *PlatformInfo) Reset() {
+	*x = PlatformInfo{}
+	mi := &file_proto_search_proto_msgTypes[24]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Result) String() string {
+func (x *PlatformInfo) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Result) ProtoMessage() {}
+func (*PlatformInfo) ProtoMessage() {}
 
-func (x *Result) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_search_proto_msgTypes[3]
+func (x *PlatformInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_search_proto_msgTypes[24]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -274,136 +2743,126 @@ func (x *Result) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Result.ProtoReflect.Descriptor instead.
-func (*Result) Descriptor() ([]byte, []int) {
-	return file_proto_search_proto_rawDescGZIP(), []int{3}
+// Deprecated: Use PlatformInfo.ProtoReflect.Descriptor instead.
+func (*PlatformInfo) Descriptor() ([]byte, []int) {
+	return file_proto_search_proto_rawDescGZIP(), []int{24}
 }
 
-func (x *Result) GetPlatform() string {
+func (x *PlatformInfo) GetName() string {
 	if x != nil {
-		return x.Platform
+		return x.Name
 	}
 	return ""
 }
 
-func (x *Result) GetTitle() string {
+func (x *PlatformInfo) GetDisplayName() string {
 	if x != nil {
-		return x.Title
+		return x.DisplayName
 	}
 	return ""
 }
 
-func (x *Result) GetSnippet() string {
+func (x *PlatformInfo) GetIconUrl() string {
 	if x != nil {
-		return x.Snippet
+		return x.IconUrl
 	}
 	return ""
 }
 
-func (x *Result) GetUrl() string {
+func (x *PlatformInfo) GetBrandColor() string {
 	if x != nil {
-		return x.Url
+		return x.BrandColor
 	}
 	return ""
 }
 
-func (x *Result) GetTimestamp() int64 {
+func (x *PlatformInfo) GetDescription() string {
 	if x != nil {
-		return x.Timestamp
+		return x.Description
 	}
-	return 0
+	return ""
 }
 
-func (x *Result) GetMetadata() map[string]string {
+func (x *PlatformInfo) GetCredentialsConfigured() bool {
 	if x != nil {
-		return x.Metadata
+		return x.CredentialsConfigured
 	}
-	return nil
-}
-
-// ResponseMetadata provides information about the search execution
-type ResponseMetadata struct {
-	state protoimpl.MessageState `protogen:"open.v1"`
-	// Total response time in milliseconds
-	ResponseTimeMs int32 `protobuf:"varint,1,opt,name=response_time_ms,json=responseTimeMs,proto3" json:"response_time_ms,omitempty"`
-	// Number of platforms queried
-	PlatformsQueried int32 `protobuf:"varint,2,opt,name=platforms_queried,json=platformsQueried,proto3" json:"platforms_queried,omitempty"`
-	unknownFields    protoimpl.UnknownFields
-	sizeCache        protoimpl.SizeCache
+	return false
 }
 
-func (x *ResponseMetadata) Reset() {
-	*x = ResponseMetadata{}
-	mi := &file_proto_search_proto_msgTypes[4]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *PlatformInfo) GetSupportedFilters() []string {
+	if x != nil {
+		return x.SupportedFilters
+	}
+	return nil
 }
 
-func (x *ResponseMetadata) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *PlatformInfo) GetCircuitState() CircuitState {
+	if x != nil {
+		return x.CircuitState
+	}
+	return CircuitState_CIRCUIT_STATE_UNSPECIFIED
 }
 
-func (*ResponseMetadata) ProtoMessage() {}
-
-func (x *ResponseMetadata) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_search_proto_msgTypes[4]
+func (x *PlatformInfo) GetDefaultMaxResults() int32 {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.DefaultMaxResults
 	}
-	return mi.MessageOf(x)
+	return 0
 }
 
-// Deprecated: Use ResponseMetadata.ProtoReflect.Descriptor instead.
-func (*ResponseMetadata) Descriptor() ([]byte, []int) {
-	return file_proto_search_proto_rawDescGZIP(), []int{4}
+func (x *PlatformInfo) GetQueryTerms() []string {
+	if x != nil {
+		return x.QueryTerms
+	}
+	return nil
 }
 
-func (x *ResponseMetadata) GetResponseTimeMs() int32 {
+func (x *PlatformInfo) GetQuota() *UpstreamQuota {
 	if x != nil {
-		return x.ResponseTimeMs
+		return x.Quota
 	}
-	return 0
+	return nil
 }
 
-func (x *ResponseMetadata) GetPlatformsQueried() int32 {
+func (x *PlatformInfo) GetDisabled() bool {
 	if x != nil {
-		return x.PlatformsQueried
+		return x.Disabled
 	}
-	return 0
+	return false
 }
 
-// HealthCheckResponse indicates service health
-type HealthCheckResponse struct {
+// UpstreamQuota is a platform's request allowance
+type UpstreamQuota struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
-	// Health status: "healthy", "degraded", "unhealthy"
-	Status string `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
-	// Service version
-	Version string `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
-	// Timestamp of health check
-	Timestamp     int64 `protobuf:"varint,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// Requests allowed per period; 0 when the platform doesn't say
+	Limit int32 `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	// Requests left in the current period
+	Remaining int32 `protobuf:"varint,2,opt,name=remaining,proto3" json:"remaining,omitempty"`
+	// When the allowance refills (Unix seconds, UTC); 0 when unknown
+	ResetAt int64 `protobuf:"varint,3,opt,name=reset_at,json=resetAt,proto3" json:"reset_at,omitempty"`
+	// Whether the server is pausing or pacing searches of the platform to
+	// save what's left
+	Throttled     bool `protobuf:"varint,4,opt,name=throttled,proto3" json:"throttled,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *HealthCheckResponse) Reset() {
-	*x = HealthCheckResponse{}
-	mi := &file_proto_search_proto_msgTypes[5]
+func (x *UpstreamQuota) Reset() {
+	*x = UpstreamQuota{}
+	mi := &file_proto_search_proto_msgTypes[25]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *HealthCheckResponse) String() string {
+func (x *UpstreamQuota) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HealthCheckResponse) ProtoMessage() {}
+func (*UpstreamQuota) ProtoMessage() {}
 
-func (x *HealthCheckResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_search_proto_msgTypes[5]
+func (x *UpstreamQuota) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_search_proto_msgTypes[25]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -414,44 +2873,94 @@ func (x *HealthCheckResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HealthCheckResponse.ProtoReflect.Descriptor instead.
-func (*HealthCheckResponse) Descriptor() ([]byte, []int) {
-	return file_proto_search_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use UpstreamQuota.ProtoReflect.Descriptor instead.
+func (*UpstreamQuota) Descriptor() ([]byte, []int) {
+	return file_proto_search_proto_rawDescGZIP(), []int{25}
 }
 
-func (x *HealthCheckResponse) GetStatus() string {
+func (x *UpstreamQuota) GetLimit() int32 {
 	if x != nil {
-		return x.Status
+		return x.Limit
 	}
-	return ""
+	return 0
 }
 
-func (x *HealthCheckResponse) GetVersion() string {
+func (x *UpstreamQuota) GetRemaining() int32 {
 	if x != nil {
-		return x.Version
+		return x.Remaining
 	}
-	return ""
+	return 0
 }
 
-func (x *HealthCheckResponse) GetTimestamp() int64 {
+func (x *UpstreamQuota) GetResetAt() int64 {
 	if x != nil {
-		return x.Timestamp
+		return x.ResetAt
 	}
 	return 0
 }
 
+func (x *UpstreamQuota) GetThrottled() bool {
+	if x != nil {
+		return x.Throttled
+	}
+	return false
+}
+
 var File_proto_search_proto protoreflect.FileDescriptor
 
 const file_proto_search_proto_rawDesc = "" +
 	"\n" +
-	"\x12proto/search.proto\x12\x06search\"d\n" +
+	"\x12proto/search.proto\x12\x06search\"\xbe\b\n" +
 	"\rSearchRequest\x12\x14\n" +
 	"\x05query\x18\x01 \x01(\tR\x05query\x12\x1f\n" +
 	"\vmax_results\x18\x02 \x01(\x05R\n" +
 	"maxResults\x12\x1c\n" +
-	"\tplatforms\x18\x03 \x03(\tR\tplatforms\".\n" +
+	"\tplatforms\x18\x03 \x03(\tR\tplatforms\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x04 \x01(\tR\tpageToken\x12\x16\n" +
+	"\x06locale\x18\x05 \x01(\tR\x06locale\x12,\n" +
+	"\x12max_snippet_length\x18\x06 \x01(\x05R\x10maxSnippetLength\x12\x1c\n" +
+	"\tsummarize\x18\a \x01(\bR\tsummarize\x12#\n" +
+	"\rallow_sources\x18\b \x03(\tR\fallowSources\x12!\n" +
+	"\fdeny_sources\x18\t \x03(\tR\vdenySources\x12#\n" +
+	"\ranswered_only\x18\n" +
+	" \x01(\bR\fansweredOnly\x12\x1b\n" +
+	"\tmin_stars\x18\v \x01(\x05R\bminStars\x12\x1f\n" +
+	"\vmin_upvotes\x18\f \x01(\x05R\n" +
+	"minUpvotes\x12\x16\n" +
+	"\x06filter\x18\r \x01(\tR\x06filter\x12\x1d\n" +
+	"\n" +
+	"timeout_ms\x18\x0e \x01(\x05R\ttimeoutMs\x125\n" +
+	"\x17per_platform_timeout_ms\x18\x0f \x01(\x05R\x14perPlatformTimeoutMs\x12'\n" +
+	"\x0faccept_language\x18\x10 \x01(\tR\x0eacceptLanguage\x120\n" +
+	"\x14accept_language_only\x18\x11 \x01(\bR\x12acceptLanguageOnly\x12[\n" +
+	"\x12interleave_weights\x18\x12 \x03(\v2,.search.SearchRequest.InterleaveWeightsEntryR\x11interleaveWeights\x12%\n" +
+	"\x04sort\x18\x13 \x01(\x0e2\x11.search.SortOrderR\x04sort\x126\n" +
+	"\fcontent_type\x18\x14 \x01(\x0e2\x13.search.ContentTypeR\vcontentType\x12\x14\n" +
+	"\x05since\x18\x15 \x01(\x03R\x05since\x12\x14\n" +
+	"\x05until\x18\x16 \x01(\x03R\x05until\x12i\n" +
+	"\x18max_results_per_platform\x18\x17 \x03(\v20.search.SearchRequest.MaxResultsPerPlatformEntryR\x15maxResultsPerPlatform\x1aD\n" +
+	"\x16InterleaveWeightsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x05R\x05value:\x028\x01\x1aH\n" +
+	"\x1aMaxResultsPerPlatformEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x05R\x05value:\x028\x01\".\n" +
 	"\x12HealthCheckRequest\x12\x18\n" +
-	"\aservice\x18\x01 \x01(\tR\aservice\"\x94\x02\n" +
+	"\aservice\x18\x01 \x01(\tR\aservice\"\x16\n" +
+	"\x14ListPlatformsRequest\"_\n" +
+	"\rAnswerRequest\x12-\n" +
+	"\x06search\x18\x01 \x01(\v2\x15.search.SearchRequestR\x06search\x12\x1f\n" +
+	"\vmax_sources\x18\x02 \x01(\x05R\n" +
+	"maxSources\"G\n" +
+	"\x12MultiSearchRequest\x121\n" +
+	"\bsearches\x18\x01 \x03(\v2\x15.search.SearchRequestR\bsearches\"\x93\x01\n" +
+	"\x14ResultDetailsRequest\x12\x1a\n" +
+	"\bplatform\x18\x01 \x01(\tR\bplatform\x12\x10\n" +
+	"\x03url\x18\x02 \x01(\tR\x03url\x12\x1f\n" +
+	"\vmax_replies\x18\x03 \x01(\x05R\n" +
+	"maxReplies\x12,\n" +
+	"\x12max_content_length\x18\x04 \x01(\x05R\x10maxContentLength\"\x81\x03\n" +
 	"\x0eSearchResponse\x12(\n" +
 	"\aresults\x18\x01 \x03(\v2\x0e.search.ResultR\aresults\x12\x1f\n" +
 	"\vtotal_count\x18\x02 \x01(\x05R\n" +
@@ -459,27 +2968,238 @@ const file_proto_search_proto_rawDesc = "" +
 	"\x11platforms_success\x18\x03 \x03(\tR\x10platformsSuccess\x12+\n" +
 	"\x11platforms_timeout\x18\x04 \x03(\tR\x10platformsTimeout\x12'\n" +
 	"\x0fplatforms_error\x18\x05 \x03(\tR\x0eplatformsError\x124\n" +
-	"\bmetadata\x18\x06 \x01(\v2\x18.search.ResponseMetadataR\bmetadata\"\xfb\x01\n" +
+	"\bmetadata\x18\x06 \x01(\v2\x18.search.ResponseMetadataR\bmetadata\x12&\n" +
+	"\x0fnext_page_token\x18\a \x01(\tR\rnextPageToken\x12C\n" +
+	"\x11platform_statuses\x18\b \x03(\v2\x16.search.PlatformStatusR\x10platformStatuses\"\xd6\x02\n" +
+	"\x0ePlatformStatus\x12\x1a\n" +
+	"\bplatform\x18\x01 \x01(\tR\bplatform\x12+\n" +
+	"\x05state\x18\x02 \x01(\x0e2\x15.search.PlatformStateR\x05state\x12\x1d\n" +
+	"\n" +
+	"error_code\x18\x03 \x01(\tR\terrorCode\x12\x1f\n" +
+	"\vhttp_status\x18\x04 \x01(\x05R\n" +
+	"httpStatus\x12.\n" +
+	"\x13retry_after_seconds\x18\x05 \x01(\x05R\x11retryAfterSeconds\x12\x1f\n" +
+	"\vduration_ms\x18\x06 \x01(\x05R\n" +
+	"durationMs\x12*\n" +
+	"\x11served_from_cache\x18\a \x01(\bR\x0fservedFromCache\x12 \n" +
+	"\fcache_age_ms\x18\b \x01(\x03R\n" +
+	"cacheAgeMs\x12\x1c\n" +
+	"\n" +
+	"data_as_of\x18\t \x01(\x03R\bdataAsOf\"\x85\x06\n" +
 	"\x06Result\x12\x1a\n" +
 	"\bplatform\x18\x01 \x01(\tR\bplatform\x12\x14\n" +
 	"\x05title\x18\x02 \x01(\tR\x05title\x12\x18\n" +
 	"\asnippet\x18\x03 \x01(\tR\asnippet\x12\x10\n" +
 	"\x03url\x18\x04 \x01(\tR\x03url\x12\x1c\n" +
 	"\ttimestamp\x18\x05 \x01(\x03R\ttimestamp\x128\n" +
-	"\bmetadata\x18\x06 \x03(\v2\x1c.search.Result.MetadataEntryR\bmetadata\x1a;\n" +
+	"\bmetadata\x18\x06 \x03(\v2\x1c.search.Result.MetadataEntryR\bmetadata\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\a \x01(\x03R\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\b \x01(\x03R\tupdatedAt\x12\x19\n" +
+	"\bage_days\x18\t \x01(\x05R\aageDays\x12#\n" +
+	"\rthumbnail_url\x18\n" +
+	" \x01(\tR\fthumbnailUrl\x12&\n" +
+	"\x06author\x18\v \x01(\v2\x0e.search.AuthorR\x06author\x123\n" +
+	"\vresult_type\x18\f \x01(\x0e2\x12.search.ResultTypeR\n" +
+	"resultType\x12\x18\n" +
+	"\asummary\x18\r \x01(\tR\asummary\x12,\n" +
+	"\x06github\x18\x0e \x01(\v2\x12.search.GitHubMetaH\x00R\x06github\x12A\n" +
+	"\rstackoverflow\x18\x0f \x01(\v2\x19.search.StackOverflowMetaH\x00R\rstackoverflow\x12,\n" +
+	"\x06reddit\x18\x10 \x01(\v2\x12.search.RedditMetaH\x00R\x06reddit\x125\n" +
+	"\tdockerhub\x18\x11 \x01(\v2\x15.search.DockerHubMetaH\x00R\tdockerhub\x12)\n" +
+	"\x10normalized_score\x18\x12 \x01(\x01R\x0fnormalizedScore\x1a;\n" +
 	"\rMetadataEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"i\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\x12\n" +
+	"\x10platform_details\"\x97\x03\n" +
+	"\n" +
+	"GitHubMeta\x12\x14\n" +
+	"\x05stars\x18\x01 \x01(\x05R\x05stars\x12\x14\n" +
+	"\x05forks\x18\x02 \x01(\x05R\x05forks\x12\x1a\n" +
+	"\blanguage\x18\x03 \x01(\tR\blanguage\x12\x1f\n" +
+	"\vopen_issues\x18\x04 \x01(\x05R\n" +
+	"openIssues\x12\x1e\n" +
+	"\n" +
+	"repository\x18\x05 \x01(\tR\n" +
+	"repository\x12\x12\n" +
+	"\x04path\x18\x06 \x01(\tR\x04path\x12\x10\n" +
+	"\x03sha\x18\a \x01(\tR\x03sha\x12\x16\n" +
+	"\x06number\x18\b \x01(\x05R\x06number\x12\x1a\n" +
+	"\bcomments\x18\t \x01(\x05R\bcomments\x12\x14\n" +
+	"\x05state\x18\n" +
+	" \x01(\tR\x05state\x12\x16\n" +
+	"\x06labels\x18\v \x03(\tR\x06labels\x12!\n" +
+	"\fpull_request\x18\f \x01(\bR\vpullRequest\x12\x18\n" +
+	"\aupvotes\x18\r \x01(\x05R\aupvotes\x12\x1f\n" +
+	"\vis_answered\x18\x0e \x01(\bR\n" +
+	"isAnswered\x12\x1a\n" +
+	"\bcategory\x18\x0f \x01(\tR\bcategory\"\xa0\x01\n" +
+	"\x11StackOverflowMeta\x12\x14\n" +
+	"\x05score\x18\x01 \x01(\x05R\x05score\x12!\n" +
+	"\fanswer_count\x18\x02 \x01(\x05R\vanswerCount\x12\x1d\n" +
+	"\n" +
+	"view_count\x18\x03 \x01(\x05R\tviewCount\x12\x1f\n" +
+	"\vis_answered\x18\x04 \x01(\bR\n" +
+	"isAnswered\x12\x12\n" +
+	"\x04tags\x18\x05 \x03(\tR\x04tags\"\xc2\x01\n" +
+	"\n" +
+	"RedditMeta\x12\x14\n" +
+	"\x05score\x18\x01 \x01(\x05R\x05score\x12!\n" +
+	"\fnum_comments\x18\x02 \x01(\x05R\vnumComments\x12\x1c\n" +
+	"\tsubreddit\x18\x03 \x01(\tR\tsubreddit\x12!\n" +
+	"\fupvote_ratio\x18\x04 \x01(\x02R\vupvoteRatio\x12\x1f\n" +
+	"\vlink_domain\x18\x05 \x01(\tR\n" +
+	"linkDomain\x12\x19\n" +
+	"\blink_url\x18\x06 \x01(\tR\alinkUrl\"\x91\x01\n" +
+	"\rDockerHubMeta\x12\x14\n" +
+	"\x05stars\x18\x01 \x01(\x05R\x05stars\x12\x14\n" +
+	"\x05pulls\x18\x02 \x01(\x03R\x05pulls\x12\x1a\n" +
+	"\bofficial\x18\x03 \x01(\bR\bofficial\x12\x1a\n" +
+	"\bverified\x18\x04 \x01(\bR\bverified\x12\x1c\n" +
+	"\tnamespace\x18\x05 \x01(\tR\tnamespace\"U\n" +
+	"\x06Author\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x16\n" +
+	"\x06handle\x18\x02 \x01(\tR\x06handle\x12\x1f\n" +
+	"\vprofile_url\x18\x03 \x01(\tR\n" +
+	"profileUrl\"\xb1\x01\n" +
+	"\x14SearchStreamResponse\x12\x1a\n" +
+	"\bplatform\x18\x01 \x01(\tR\bplatform\x12(\n" +
+	"\aresults\x18\x02 \x03(\v2\x0e.search.ResultR\aresults\x12\x1f\n" +
+	"\vduration_ms\x18\x03 \x01(\x05R\n" +
+	"durationMs\x122\n" +
+	"\bresponse\x18\x04 \x01(\v2\x16.search.SearchResponseR\bresponse\"\x98\x05\n" +
 	"\x10ResponseMetadata\x12(\n" +
 	"\x10response_time_ms\x18\x01 \x01(\x05R\x0eresponseTimeMs\x12+\n" +
-	"\x11platforms_queried\x18\x02 \x01(\x05R\x10platformsQueried\"e\n" +
+	"\x11platforms_queried\x18\x02 \x01(\x05R\x10platformsQueried\x12'\n" +
+	"\x0fresults_dropped\x18\x03 \x01(\x05R\x0eresultsDropped\x12-\n" +
+	"\x12snippets_truncated\x18\x04 \x01(\x05R\x11snippetsTruncated\x12-\n" +
+	"\x12metadata_truncated\x18\x05 \x01(\x05R\x11metadataTruncated\x120\n" +
+	"\x14platforms_from_index\x18\x06 \x03(\tR\x12platformsFromIndex\x12 \n" +
+	"\vapproximate\x18\a \x01(\bR\vapproximate\x12+\n" +
+	"\x11approximate_query\x18\b \x01(\tR\x10approximateQuery\x12\x1e\n" +
+	"\n" +
+	"similarity\x18\t \x01(\x02R\n" +
+	"similarity\x12[\n" +
+	"\x11shortened_queries\x18\n" +
+	" \x03(\v2..search.ResponseMetadata.ShortenedQueriesEntryR\x10shortenedQueries\x12'\n" +
+	"\x0frelated_queries\x18\v \x03(\tR\x0erelatedQueries\x12\x1b\n" +
+	"\tcache_hit\x18\f \x01(\bR\bcacheHit\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\r \x01(\tR\trequestId\x1aC\n" +
+	"\x15ShortenedQueriesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x9c\x01\n" +
 	"\x13HealthCheckResponse\x12\x16\n" +
 	"\x06status\x18\x01 \x01(\tR\x06status\x12\x18\n" +
 	"\aversion\x18\x02 \x01(\tR\aversion\x12\x1c\n" +
-	"\ttimestamp\x18\x03 \x01(\x03R\ttimestamp2\x99\x01\n" +
+	"\ttimestamp\x18\x03 \x01(\x03R\ttimestamp\x12\x16\n" +
+	"\x06commit\x18\x04 \x01(\tR\x06commit\x12\x1d\n" +
+	"\n" +
+	"build_date\x18\x05 \x01(\tR\tbuildDate\"\x88\x01\n" +
+	"\x0eAnswerResponse\x12\x16\n" +
+	"\x06answer\x18\x01 \x01(\tR\x06answer\x12.\n" +
+	"\tcitations\x18\x02 \x03(\v2\x10.search.CitationR\tcitations\x12.\n" +
+	"\x06search\x18\x03 \x01(\v2\x16.search.SearchResponseR\x06search\"J\n" +
+	"\x13MultiSearchResponse\x123\n" +
+	"\aresults\x18\x01 \x03(\v2\x19.search.MultiSearchResultR\aresults\"\x8b\x01\n" +
+	"\x11MultiSearchResult\x122\n" +
+	"\bresponse\x18\x01 \x01(\v2\x16.search.SearchResponseR\bresponse\x12\x1d\n" +
+	"\n" +
+	"error_code\x18\x02 \x01(\x05R\terrorCode\x12#\n" +
+	"\rerror_message\x18\x03 \x01(\tR\ferrorMessage\"\xaa\x02\n" +
+	"\x15ResultDetailsResponse\x12\x1a\n" +
+	"\bplatform\x18\x01 \x01(\tR\bplatform\x12\x10\n" +
+	"\x03url\x18\x02 \x01(\tR\x03url\x12\x14\n" +
+	"\x05title\x18\x03 \x01(\tR\x05title\x12\x18\n" +
+	"\acontent\x18\x04 \x01(\tR\acontent\x12%\n" +
+	"\x0econtent_format\x18\x05 \x01(\tR\rcontentFormat\x12&\n" +
+	"\x06author\x18\x06 \x01(\v2\x0e.search.AuthorR\x06author\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\a \x01(\x03R\tcreatedAt\x12'\n" +
+	"\areplies\x18\b \x03(\v2\r.search.ReplyR\areplies\x12\x1c\n" +
+	"\ttruncated\x18\t \x01(\bR\ttruncated\"\xac\x01\n" +
+	"\x05Reply\x12\x18\n" +
+	"\acontent\x18\x01 \x01(\tR\acontent\x12&\n" +
+	"\x06author\x18\x02 \x01(\v2\x0e.search.AuthorR\x06author\x12\x14\n" +
+	"\x05score\x18\x03 \x01(\x05R\x05score\x12\x1a\n" +
+	"\baccepted\x18\x04 \x01(\bR\baccepted\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\x03R\tcreatedAt\x12\x10\n" +
+	"\x03url\x18\x06 \x01(\tR\x03url\"d\n" +
+	"\bCitation\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x05R\x05index\x12\x10\n" +
+	"\x03url\x18\x02 \x01(\tR\x03url\x12\x14\n" +
+	"\x05title\x18\x03 \x01(\tR\x05title\x12\x1a\n" +
+	"\bplatform\x18\x04 \x01(\tR\bplatform\"K\n" +
+	"\x15ListPlatformsResponse\x122\n" +
+	"\tplatforms\x18\x01 \x03(\v2\x14.search.PlatformInfoR\tplatforms\"\xdc\x03\n" +
+	"\fPlatformInfo\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12!\n" +
+	"\fdisplay_name\x18\x02 \x01(\tR\vdisplayName\x12\x19\n" +
+	"\bicon_url\x18\x03 \x01(\tR\aiconUrl\x12\x1f\n" +
+	"\vbrand_color\x18\x04 \x01(\tR\n" +
+	"brandColor\x12 \n" +
+	"\vdescription\x18\x05 \x01(\tR\vdescription\x125\n" +
+	"\x16credentials_configured\x18\x06 \x01(\bR\x15credentialsConfigured\x12+\n" +
+	"\x11supported_filters\x18\a \x03(\tR\x10supportedFilters\x129\n" +
+	"\rcircuit_state\x18\b \x01(\x0e2\x14.search.CircuitStateR\fcircuitState\x12.\n" +
+	"\x13default_max_results\x18\t \x01(\x05R\x11defaultMaxResults\x12\x1f\n" +
+	"\vquery_terms\x18\n" +
+	" \x03(\tR\n" +
+	"queryTerms\x12+\n" +
+	"\x05quota\x18\v \x01(\v2\x15.search.UpstreamQuotaR\x05quota\x12\x1a\n" +
+	"\bdisabled\x18\f \x01(\bR\bdisabled\"|\n" +
+	"\rUpstreamQuota\x12\x14\n" +
+	"\x05limit\x18\x01 \x01(\x05R\x05limit\x12\x1c\n" +
+	"\tremaining\x18\x02 \x01(\x05R\tremaining\x12\x19\n" +
+	"\breset_at\x18\x03 \x01(\x03R\aresetAt\x12\x1c\n" +
+	"\tthrottled\x18\x04 \x01(\bR\tthrottled*\x98\x01\n" +
+	"\vContentType\x12\x1c\n" +
+	"\x18CONTENT_TYPE_UNSPECIFIED\x10\x00\x12\x1d\n" +
+	"\x19CONTENT_TYPE_REPOSITORIES\x10\x01\x12\x15\n" +
+	"\x11CONTENT_TYPE_CODE\x10\x02\x12\x17\n" +
+	"\x13CONTENT_TYPE_ISSUES\x10\x03\x12\x1c\n" +
+	"\x18CONTENT_TYPE_DISCUSSIONS\x10\x04*o\n" +
+	"\tSortOrder\x12\x1a\n" +
+	"\x16SORT_ORDER_UNSPECIFIED\x10\x00\x12\x16\n" +
+	"\x12SORT_ORDER_ARRIVAL\x10\x01\x12\x18\n" +
+	"\x14SORT_ORDER_RELEVANCE\x10\x02\x12\x14\n" +
+	"\x10SORT_ORDER_SCORE\x10\x03*\x98\x01\n" +
+	"\rPlatformState\x12\x1e\n" +
+	"\x1aPLATFORM_STATE_UNSPECIFIED\x10\x00\x12\x15\n" +
+	"\x11PLATFORM_STATE_OK\x10\x01\x12\x1a\n" +
+	"\x16PLATFORM_STATE_TIMEOUT\x10\x02\x12\x18\n" +
+	"\x14PLATFORM_STATE_ERROR\x10\x03\x12\x1a\n" +
+	"\x16PLATFORM_STATE_SKIPPED\x10\x04*\xbd\x02\n" +
+	"\n" +
+	"ResultType\x12\x1b\n" +
+	"\x17RESULT_TYPE_UNSPECIFIED\x10\x00\x12\x1a\n" +
+	"\x16RESULT_TYPE_REPOSITORY\x10\x01\x12\x18\n" +
+	"\x14RESULT_TYPE_QUESTION\x10\x02\x12\x14\n" +
+	"\x10RESULT_TYPE_POST\x10\x03\x12\x17\n" +
+	"\x13RESULT_TYPE_ARTICLE\x10\x04\x12\x17\n" +
+	"\x13RESULT_TYPE_PACKAGE\x10\x05\x12\x15\n" +
+	"\x11RESULT_TYPE_VIDEO\x10\x06\x12\x15\n" +
+	"\x11RESULT_TYPE_ISSUE\x10\a\x12\x13\n" +
+	"\x0fRESULT_TYPE_DOC\x10\b\x12\x14\n" +
+	"\x10RESULT_TYPE_CODE\x10\t\x12\x1a\n" +
+	"\x16RESULT_TYPE_DISCUSSION\x10\n" +
+	"\x12\x1f\n" +
+	"\x1bRESULT_TYPE_CONTAINER_IMAGE\x10\v*|\n" +
+	"\fCircuitState\x12\x1d\n" +
+	"\x19CIRCUIT_STATE_UNSPECIFIED\x10\x00\x12\x18\n" +
+	"\x14CIRCUIT_STATE_CLOSED\x10\x01\x12\x16\n" +
+	"\x12CIRCUIT_STATE_OPEN\x10\x02\x12\x1b\n" +
+	"\x17CIRCUIT_STATE_HALF_OPEN\x10\x032\x86\x04\n" +
 	"\rSearchService\x12@\n" +
-	"\x0fFederatedSearch\x12\x15.search.SearchRequest\x1a\x16.search.SearchResponse\x12F\n" +
-	"\vHealthCheck\x12\x1a.search.HealthCheckRequest\x1a\x1b.search.HealthCheckResponseB+Z)github.com/farhapartex/search-proxy/protob\x06proto3"
+	"\x0fFederatedSearch\x12\x15.search.SearchRequest\x1a\x16.search.SearchResponse\x12E\n" +
+	"\fSearchStream\x12\x15.search.SearchRequest\x1a\x1c.search.SearchStreamResponse0\x01\x12F\n" +
+	"\vHealthCheck\x12\x1a.search.HealthCheckRequest\x1a\x1b.search.HealthCheckResponse\x12L\n" +
+	"\rListPlatforms\x12\x1c.search.ListPlatformsRequest\x1a\x1d.search.ListPlatformsResponse\x12=\n" +
+	"\fAnswerSearch\x12\x15.search.AnswerRequest\x1a\x16.search.AnswerResponse\x12F\n" +
+	"\vMultiSearch\x12\x1a.search.MultiSearchRequest\x1a\x1b.search.MultiSearchResponse\x12O\n" +
+	"\x10GetResultDetails\x12\x1c.search.ResultDetailsRequest\x1a\x1d.search.ResultDetailsResponseB+Z)github.com/farhapartex/search-proxy/protob\x06proto3"
 
 var (
 	file_proto_search_proto_rawDescOnce sync.Once
@@ -493,29 +3213,95 @@ func file_proto_search_proto_rawDescGZIP() []byte {
 	return file_proto_search_proto_rawDescData
 }
 
-var file_proto_search_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_proto_search_proto_enumTypes = make([]protoimpl.EnumInfo, 5)
+var file_proto_search_proto_msgTypes = make([]protoimpl.MessageInfo, 30)
 var file_proto_search_proto_goTypes = []any{
-	(*SearchRequest)(nil),       // 0: search.SearchRequest
-	(*HealthCheckRequest)(nil),  // 1: search.HealthCheckRequest
-	(*SearchResponse)(nil),      // 2: search.SearchResponse
-	(*Result)(nil),              // 3: search.Result
-	(*ResponseMetadata)(nil),    // 4: search.ResponseMetadata
-	(*HealthCheckResponse)(nil), // 5: search.HealthCheckResponse
-	nil,                         // 6: search.Result.MetadataEntry
+	(ContentType)(0),              // 0: search.ContentType
+	(SortOrder)(0),                // 1: search.SortOrder
+	(PlatformState)(0),            // 2: search.PlatformState
+	(ResultType)(0),               // 3: search.ResultType
+	(CircuitState)(0),             // 4: search.CircuitState
+	(*SearchRequest)(nil),         // 5: search.SearchRequest
+	(*HealthCheckRequest)(nil),    // 6: search.HealthCheckRequest
+	(*ListPlatformsRequest)(nil),  // 7: search.ListPlatformsRequest
+	(*AnswerRequest)(nil),         // 8: search.AnswerRequest
+	(*MultiSearchRequest)(nil),    // 9: search.MultiSearchRequest
+	(*ResultDetailsRequest)(nil),  // 10: search.ResultDetailsRequest
+	(*SearchResponse)(nil),        // 11: search.SearchResponse
+	(*PlatformStatus)(nil),        // 12: search.PlatformStatus
+	(*Result)(nil),                // 13: search.Result
+	(*GitHubMeta)(nil),            // 14: search.GitHubMeta
+	(*StackOverflowMeta)(nil),     // 15: search.StackOverflowMeta
+	(*RedditMeta)(nil),            // 16: search.RedditMeta
+	(*DockerHubMeta)(nil),         // 17: search.DockerHubMeta
+	(*Author)(nil),                // 18: search.Author
+	(*SearchStreamResponse)(nil),  // 19: search.SearchStreamResponse
+	(*ResponseMetadata)(nil),      // 20: search.ResponseMetadata
+	(*HealthCheckResponse)(nil),   // 21: search.HealthCheckResponse
+	(*AnswerResponse)(nil),        // 22: search.AnswerResponse
+	(*MultiSearchResponse)(nil),   // 23: search.MultiSearchResponse
+	(*MultiSearchResult)(nil),     // 24: search.MultiSearchResult
+	(*ResultDetailsResponse)(nil), // 25: search.ResultDetailsResponse
+	(*Reply)(nil),                 // 26: search.Reply
+	(*Citation)(nil),              // 27: search.Citation
+	(*ListPlatformsResponse)(nil), // 28: search.ListPlatformsResponse
+	(*PlatformInfo)(nil),          // 29: search.PlatformInfo
+	(*UpstreamQuota)(nil),         // 30: search.UpstreamQuota
+	nil,                           // 31: search.SearchRequest.InterleaveWeightsEntry
+	nil,                           // 32: search.SearchRequest.MaxResultsPerPlatformEntry
+	nil,                           // 33: search.Result.MetadataEntry
+	nil,                           // 34: search.ResponseMetadata.ShortenedQueriesEntry
 }
 var file_proto_search_proto_depIdxs = []int32{
-	3, // 0: search.SearchResponse.results:type_name -> search.Result
-	4, // 1: search.SearchResponse.metadata:type_name -> search.ResponseMetadata
-	6, // 2: search.Result.metadata:type_name -> search.Result.MetadataEntry
-	0, // 3: search.SearchService.FederatedSearch:input_type -> search.SearchRequest
-	1, // 4: search.SearchService.HealthCheck:input_type -> search.HealthCheckRequest
-	2, // 5: search.SearchService.FederatedSearch:output_type -> search.SearchResponse
-	5, // 6: search.SearchService.HealthCheck:output_type -> search.HealthCheckResponse
-	5, // [5:7] is the sub-list for method output_type
-	3, // [3:5] is the sub-list for method input_type
-	3, // [3:3] is the sub-list for extension type_name
-	3, // [3:3] is the sub-list for extension extendee
-	0, // [0:3] is the sub-list for field type_name
+	31, // 0: search.SearchRequest.interleave_weights:type_name -> search.SearchRequest.InterleaveWeightsEntry
+	1,  // 1: search.SearchRequest.sort:type_name -> search.SortOrder
+	0,  // 2: search.SearchRequest.content_type:type_name -> search.ContentType
+	32, // 3: search.SearchRequest.max_results_per_platform:type_name -> search.SearchRequest.MaxResultsPerPlatformEntry
+	5,  // 4: search.AnswerRequest.search:type_name -> search.SearchRequest
+	5,  // 5: search.MultiSearchRequest.searches:type_name -> search.SearchRequest
+	13, // 6: search.SearchResponse.results:type_name -> search.Result
+	20, // 7: search.SearchResponse.metadata:type_name -> search.ResponseMetadata
+	12, // 8: search.SearchResponse.platform_statuses:type_name -> search.PlatformStatus
+	2,  // 9: search.PlatformStatus.state:type_name -> search.PlatformState
+	33, // 10: search.Result.metadata:type_name -> search.Result.MetadataEntry
+	18, // 11: search.Result.author:type_name -> search.Author
+	3,  // 12: search.Result.result_type:type_name -> search.ResultType
+	14, // 13: search.Result.github:type_name -> search.GitHubMeta
+	15, // 14: search.Result.stackoverflow:type_name -> search.StackOverflowMeta
+	16, // 15: search.Result.reddit:type_name -> search.RedditMeta
+	17, // 16: search.Result.dockerhub:type_name -> search.DockerHubMeta
+	13, // 17: search.SearchStreamResponse.results:type_name -> search.Result
+	11, // 18: search.SearchStreamResponse.response:type_name -> search.SearchResponse
+	34, // 19: search.ResponseMetadata.shortened_queries:type_name -> search.ResponseMetadata.ShortenedQueriesEntry
+	27, // 20: search.AnswerResponse.citations:type_name -> search.Citation
+	11, // 21: search.AnswerResponse.search:type_name -> search.SearchResponse
+	24, // 22: search.MultiSearchResponse.results:type_name -> search.MultiSearchResult
+	11, // 23: search.MultiSearchResult.response:type_name -> search.SearchResponse
+	18, // 24: search.ResultDetailsResponse.author:type_name -> search.Author
+	26, // 25: search.ResultDetailsResponse.replies:type_name -> search.Reply
+	18, // 26: search.Reply.author:type_name -> search.Author
+	29, // 27: search.ListPlatformsResponse.platforms:type_name -> search.PlatformInfo
+	4,  // 28: search.PlatformInfo.circuit_state:type_name -> search.CircuitState
+	30, // 29: search.PlatformInfo.quota:type_name -> search.UpstreamQuota
+	5,  // 30: search.SearchService.FederatedSearch:input_type -> search.SearchRequest
+	5,  // 31: search.SearchService.SearchStream:input_type -> search.SearchRequest
+	6,  // 32: search.SearchService.HealthCheck:input_type -> search.HealthCheckRequest
+	7,  // 33: search.SearchService.ListPlatforms:input_type -> search.ListPlatformsRequest
+	8,  // 34: search.SearchService.AnswerSearch:input_type -> search.AnswerRequest
+	9,  // 35: search.SearchService.MultiSearch:input_type -> search.MultiSearchRequest
+	10, // 36: search.SearchService.GetResultDetails:input_type -> search.ResultDetailsRequest
+	11, // 37: search.SearchService.FederatedSearch:output_type -> search.SearchResponse
+	19, // 38: search.SearchService.SearchStream:output_type -> search.SearchStreamResponse
+	21, // 39: search.SearchService.HealthCheck:output_type -> search.HealthCheckResponse
+	28, // 40: search.SearchService.ListPlatforms:output_type -> search.ListPlatformsResponse
+	22, // 41: search.SearchService.AnswerSearch:output_type -> search.AnswerResponse
+	23, // 42: search.SearchService.MultiSearch:output_type -> search.MultiSearchResponse
+	25, // 43: search.SearchService.GetResultDetails:output_type -> search.ResultDetailsResponse
+	37, // [37:44] is the sub-list for method output_type
+	30, // [30:37] is the sub-list for method input_type
+	30, // [30:30] is the sub-list for extension type_name
+	30, // [30:30] is the sub-list for extension extendee
+	0,  // [0:30] is the sub-list for field type_name
 }
 
 func init() { file_proto_search_proto_init() }
@@ -523,18 +3309,25 @@ func file_proto_search_proto_init() {
 	if File_proto_search_proto != nil {
 		return
 	}
+	file_proto_search_proto_msgTypes[8].OneofWrappers = []any{
+		(*Result_Github)(nil),
+		(*Result_Stackoverflow)(nil),
+		(*Result_Reddit)(nil),
+		(*Result_Dockerhub)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_search_proto_rawDesc), len(file_proto_search_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   7,
+			NumEnums:      5,
+			NumMessages:   30,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_proto_search_proto_goTypes,
 		DependencyIndexes: file_proto_search_proto_depIdxs,
+		EnumInfos:         file_proto_search_proto_enumTypes,
 		MessageInfos:      file_proto_search_proto_msgTypes,
 	}.Build()
 	File_proto_search_proto = out.File
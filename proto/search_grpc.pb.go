@@ -23,8 +23,13 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	SearchService_FederatedSearch_FullMethodName = "/search.SearchService/FederatedSearch"
-	SearchService_HealthCheck_FullMethodName     = "/search.SearchService/HealthCheck"
+	SearchService_FederatedSearch_FullMethodName  = "/search.SearchService/FederatedSearch"
+	SearchService_SearchStream_FullMethodName     = "/search.SearchService/SearchStream"
+	SearchService_HealthCheck_FullMethodName      = "/search.SearchService/HealthCheck"
+	SearchService_ListPlatforms_FullMethodName    = "/search.SearchService/ListPlatforms"
+	SearchService_AnswerSearch_FullMethodName     = "/search.SearchService/AnswerSearch"
+	SearchService_MultiSearch_FullMethodName      = "/search.SearchService/MultiSearch"
+	SearchService_GetResultDetails_FullMethodName = "/search.SearchService/GetResultDetails"
 )
 
 // SearchServiceClient is the client API for SearchService service.
@@ -36,8 +41,24 @@ type SearchServiceClient interface {
 	// FederatedSearch performs concurrent searches across GitHub, StackOverflow, and Reddit
 	// Returns unified results within 500ms timeout
 	FederatedSearch(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
+	// Same search, streamed: one message per platform as soon as its results
+	// are merged, then a final message with the complete response
+	SearchStream(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SearchStreamResponse], error)
 	// HealthCheck returns the health status of the service
 	HealthCheck(ctx context.Context, in *HealthCheckRequest, opts ...grpc.CallOption) (*HealthCheckResponse, error)
+	// ListPlatforms describes the platforms that can be searched
+	ListPlatforms(ctx context.Context, in *ListPlatformsRequest, opts ...grpc.CallOption) (*ListPlatformsResponse, error)
+	// AnswerSearch runs a federated search, then has an LLM answer the query
+	// from the top results, citing them
+	AnswerSearch(ctx context.Context, in *AnswerRequest, opts ...grpc.CallOption) (*AnswerResponse, error)
+	// MultiSearch runs a batch of searches concurrently in one call and
+	// returns a result per search, in request order. One search failing
+	// doesn't fail the others.
+	MultiSearch(ctx context.Context, in *MultiSearchRequest, opts ...grpc.CallOption) (*MultiSearchResponse, error)
+	// GetResultDetails fetches the full content behind a search result: a
+	// Stack Exchange question with its answers, accepted first, a GitHub
+	// repository's README, or a Reddit post with its top comments
+	GetResultDetails(ctx context.Context, in *ResultDetailsRequest, opts ...grpc.CallOption) (*ResultDetailsResponse, error)
 }
 
 type searchServiceClient struct {
@@ -58,6 +79,25 @@ func (c *searchServiceClient) FederatedSearch(ctx context.Context, in *SearchReq
 	return out, nil
 }
 
+func (c *searchServiceClient) SearchStream(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SearchStreamResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SearchService_ServiceDesc.Streams[0], SearchService_SearchStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SearchRequest, SearchStreamResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SearchService_SearchStreamClient = grpc.ServerStreamingClient[SearchStreamResponse]
+
 func (c *searchServiceClient) HealthCheck(ctx context.Context, in *HealthCheckRequest, opts ...grpc.CallOption) (*HealthCheckResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(HealthCheckResponse)
@@ -68,6 +108,46 @@ func (c *searchServiceClient) HealthCheck(ctx context.Context, in *HealthCheckRe
 	return out, nil
 }
 
+func (c *searchServiceClient) ListPlatforms(ctx context.Context, in *ListPlatformsRequest, opts ...grpc.CallOption) (*ListPlatformsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListPlatformsResponse)
+	err := c.cc.Invoke(ctx, SearchService_ListPlatforms_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *searchServiceClient) AnswerSearch(ctx context.Context, in *AnswerRequest, opts ...grpc.CallOption) (*AnswerResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AnswerResponse)
+	err := c.cc.Invoke(ctx, SearchService_AnswerSearch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *searchServiceClient) MultiSearch(ctx context.Context, in *MultiSearchRequest, opts ...grpc.CallOption) (*MultiSearchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MultiSearchResponse)
+	err := c.cc.Invoke(ctx, SearchService_MultiSearch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *searchServiceClient) GetResultDetails(ctx context.Context, in *ResultDetailsRequest, opts ...grpc.CallOption) (*ResultDetailsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ResultDetailsResponse)
+	err := c.cc.Invoke(ctx, SearchService_GetResultDetails_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // SearchServiceServer is the server API for SearchService service.
 // All implementations must embed UnimplementedSearchServiceServer
 // for forward compatibility.
@@ -77,8 +157,24 @@ type SearchServiceServer interface {
 	// FederatedSearch performs concurrent searches across GitHub, StackOverflow, and Reddit
 	// Returns unified results within 500ms timeout
 	FederatedSearch(context.Context, *SearchRequest) (*SearchResponse, error)
+	// Same search, streamed: one message per platform as soon as its results
+	// are merged, then a final message with the complete response
+	SearchStream(*SearchRequest, grpc.ServerStreamingServer[SearchStreamResponse]) error
 	// HealthCheck returns the health status of the service
 	HealthCheck(context.Context, *HealthCheckRequest) (*HealthCheckResponse, error)
+	// ListPlatforms describes the platforms that can be searched
+	ListPlatforms(context.Context, *ListPlatformsRequest) (*ListPlatformsResponse, error)
+	// AnswerSearch runs a federated search, then has an LLM answer the query
+	// from the top results, citing them
+	AnswerSearch(context.Context, *AnswerRequest) (*AnswerResponse, error)
+	// MultiSearch runs a batch of searches concurrently in one call and
+	// returns a result per search, in request order. One search failing
+	// doesn't fail the others.
+	MultiSearch(context.Context, *MultiSearchRequest) (*MultiSearchResponse, error)
+	// GetResultDetails fetches the full content behind a search result: a
+	// Stack Exchange question with its answers, accepted first, a GitHub
+	// repository's README, or a Reddit post with its top comments
+	GetResultDetails(context.Context, *ResultDetailsRequest) (*ResultDetailsResponse, error)
 	mustEmbedUnimplementedSearchServiceServer()
 }
 
@@ -92,9 +188,24 @@ type UnimplementedSearchServiceServer struct{}
 func (UnimplementedSearchServiceServer) FederatedSearch(context.Context, *SearchRequest) (*SearchResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method FederatedSearch not implemented")
 }
+func (UnimplementedSearchServiceServer) SearchStream(*SearchRequest, grpc.ServerStreamingServer[SearchStreamResponse]) error {
+	return status.Error(codes.Unimplemented, "method SearchStream not implemented")
+}
 func (UnimplementedSearchServiceServer) HealthCheck(context.Context, *HealthCheckRequest) (*HealthCheckResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method HealthCheck not implemented")
 }
+func (UnimplementedSearchServiceServer) ListPlatforms(context.Context, *ListPlatformsRequest) (*ListPlatformsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListPlatforms not implemented")
+}
+func (UnimplementedSearchServiceServer) AnswerSearch(context.Context, *AnswerRequest) (*AnswerResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method AnswerSearch not implemented")
+}
+func (UnimplementedSearchServiceServer) MultiSearch(context.Context, *MultiSearchRequest) (*MultiSearchResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method MultiSearch not implemented")
+}
+func (UnimplementedSearchServiceServer) GetResultDetails(context.Context, *ResultDetailsRequest) (*ResultDetailsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetResultDetails not implemented")
+}
 func (UnimplementedSearchServiceServer) mustEmbedUnimplementedSearchServiceServer() {}
 func (UnimplementedSearchServiceServer) testEmbeddedByValue()                       {}
 
@@ -134,6 +245,17 @@ func _SearchService_FederatedSearch_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SearchService_SearchStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SearchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SearchServiceServer).SearchStream(m, &grpc.GenericServerStream[SearchRequest, SearchStreamResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SearchService_SearchStreamServer = grpc.ServerStreamingServer[SearchStreamResponse]
+
 func _SearchService_HealthCheck_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(HealthCheckRequest)
 	if err := dec(in); err != nil {
@@ -152,6 +274,78 @@ func _SearchService_HealthCheck_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SearchService_ListPlatforms_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPlatformsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SearchServiceServer).ListPlatforms(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SearchService_ListPlatforms_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SearchServiceServer).ListPlatforms(ctx, req.(*ListPlatformsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SearchService_AnswerSearch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AnswerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SearchServiceServer).AnswerSearch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SearchService_AnswerSearch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SearchServiceServer).AnswerSearch(ctx, req.(*AnswerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SearchService_MultiSearch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MultiSearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SearchServiceServer).MultiSearch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SearchService_MultiSearch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SearchServiceServer).MultiSearch(ctx, req.(*MultiSearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SearchService_GetResultDetails_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResultDetailsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SearchServiceServer).GetResultDetails(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SearchService_GetResultDetails_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SearchServiceServer).GetResultDetails(ctx, req.(*ResultDetailsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // SearchService_ServiceDesc is the grpc.ServiceDesc for SearchService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -167,7 +361,29 @@ var SearchService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "HealthCheck",
 			Handler:    _SearchService_HealthCheck_Handler,
 		},
+		{
+			MethodName: "ListPlatforms",
+			Handler:    _SearchService_ListPlatforms_Handler,
+		},
+		{
+			MethodName: "AnswerSearch",
+			Handler:    _SearchService_AnswerSearch_Handler,
+		},
+		{
+			MethodName: "MultiSearch",
+			Handler:    _SearchService_MultiSearch_Handler,
+		},
+		{
+			MethodName: "GetResultDetails",
+			Handler:    _SearchService_GetResultDetails_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SearchStream",
+			Handler:       _SearchService_SearchStream_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/search.proto",
 }